@@ -40,6 +40,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	// migrate 子命令：执行数据库迁移后退出
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(shareddb.MigrateMain(&cfg.Database, os.Getenv("MIGRATIONS_DIR"), os.Args[2:]))
+	}
+
 	// 初始化日志
 	logConfig := logger.Config{
 		Level:  cfg.Log.Level,
@@ -12,12 +12,24 @@ import (
 	"github.com/codetaoist/laojun-admin-api/internal/models"
 	"github.com/codetaoist/laojun-plugins/shared/sync"
 	sharedModels "github.com/codetaoist/laojun-plugins/shared/models"
+	"github.com/codetaoist/laojun-shared/notification"
+)
+
+// 插件审核通知模板与分类
+const (
+	NotificationCategoryPluginAudit = "plugin_audit"
+
+	TemplateAuditorAssigned              = "plugin_audit.assigned"
+	TemplateAuditCompleted               = "plugin_audit.completed"
+	TemplateDeveloperVerificationRequest = "developer.verification_requested"
 )
 
 // PluginAuditService 插件审核服务
 type PluginAuditService struct {
 	logger      *logrus.Logger
 	syncManager *sync.DataSyncManager
+	notifier    notification.Notifier
+	reviewers   []notification.Recipient
 	// TODO: 添加数据库连接和其他依赖
 }
 
@@ -29,6 +41,53 @@ func NewPluginAuditService(logger *logrus.Logger, syncManager *sync.DataSyncMana
 	}
 }
 
+// SetNotifier 设置通知服务，reviewers 接收开发者认证申请通知
+func (s *PluginAuditService) SetNotifier(notifier notification.Notifier, reviewers ...notification.Recipient) {
+	s.notifier = notifier
+	s.reviewers = reviewers
+}
+
+// RegisterPluginAuditTemplates 注册插件审核通知的中英文模板
+func RegisterPluginAuditTemplates(registry *notification.TemplateRegistry) error {
+	templates := []struct {
+		name   string
+		locale string
+		tmpl   notification.Template
+	}{
+		{TemplateAuditorAssigned, "zh-CN", notification.Template{
+			Subject: "新的插件审核任务",
+			Text:    "您有一个新的插件审核任务，审核记录：{{.AuditRecordID}}",
+		}},
+		{TemplateAuditorAssigned, "en", notification.Template{
+			Subject: "New plugin review assigned",
+			Text:    "A plugin review has been assigned to you, audit record: {{.AuditRecordID}}",
+		}},
+		{TemplateAuditCompleted, "zh-CN", notification.Template{
+			Subject: "插件审核结果：{{.Status}}",
+			Text:    "您提交的插件审核已完成，结果：{{.Status}}，审核记录：{{.AuditRecordID}}",
+		}},
+		{TemplateAuditCompleted, "en", notification.Template{
+			Subject: "Plugin review result: {{.Status}}",
+			Text:    "The review of your plugin is complete with result {{.Status}}, audit record: {{.AuditRecordID}}",
+		}},
+		{TemplateDeveloperVerificationRequest, "zh-CN", notification.Template{
+			Subject: "新的开发者认证申请",
+			Text:    "有新的开发者认证申请待处理，档案：{{.ProfileID}}",
+		}},
+		{TemplateDeveloperVerificationRequest, "en", notification.Template{
+			Subject: "New developer verification request",
+			Text:    "A developer verification request is waiting for review, profile: {{.ProfileID}}",
+		}},
+	}
+
+	for _, t := range templates {
+		if err := registry.Register(t.name, t.locale, t.tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SubmitPluginForAudit 提交插件审核
 func (s *PluginAuditService) SubmitPluginForAudit(ctx context.Context, req *models.PluginAuditSubmissionRequest, developerID uuid.UUID) (*models.PluginAuditRecord, error) {
 	s.logger.WithFields(logrus.Fields{
@@ -352,7 +411,13 @@ func (s *PluginAuditService) notifyAuditorAssignment(ctx context.Context, auditR
 		"auditor_id":      auditorID,
 	}).Info("Notifying auditor assignment")
 
-	// TODO: 发送邮件或系统通知
+	s.notify(ctx, &notification.Message{
+		Template:   TemplateAuditorAssigned,
+		Category:   NotificationCategoryPluginAudit,
+		Recipients: []notification.Recipient{{UserID: auditorID.String()}},
+		Data:       map[string]interface{}{"AuditRecordID": auditRecordID.String()},
+		DedupKey:   "assigned:" + auditRecordID.String(),
+	})
 }
 
 // notifyAuditCompletion 通知审核完成
@@ -362,12 +427,59 @@ func (s *PluginAuditService) notifyAuditCompletion(ctx context.Context, auditRec
 		"status":          status,
 	}).Info("Notifying audit completion")
 
-	// TODO: 发送通知给开发者
+	// TODO: 审核记录持久化后从记录中获取开发者
+	// record, err := s.repository.GetAuditRecord(ctx, auditRecordID)
+	var developerID uuid.UUID
+	if developerID == uuid.Nil {
+		s.logger.WithField("audit_record_id", auditRecordID).Debug("Developer of audit record unknown, skipping notification")
+		return
+	}
+
+	s.notify(ctx, &notification.Message{
+		Template:   TemplateAuditCompleted,
+		Category:   NotificationCategoryPluginAudit,
+		Recipients: []notification.Recipient{{UserID: developerID.String()}},
+		Data: map[string]interface{}{
+			"AuditRecordID": auditRecordID.String(),
+			"Status":        string(status),
+		},
+		DedupKey: fmt.Sprintf("completed:%s:%s", auditRecordID, status),
+	})
 }
 
 // notifyDeveloperVerificationRequest 通知开发者认证申请
 func (s *PluginAuditService) notifyDeveloperVerificationRequest(ctx context.Context, profileID uuid.UUID) {
 	s.logger.WithField("profile_id", profileID).Info("Notifying developer verification request")
 
-	// TODO: 发送通知给管理员
+	if len(s.reviewers) == 0 {
+		return
+	}
+	s.notify(ctx, &notification.Message{
+		Template:   TemplateDeveloperVerificationRequest,
+		Category:   NotificationCategoryPluginAudit,
+		Recipients: s.reviewers,
+		Data:       map[string]interface{}{"ProfileID": profileID.String()},
+		DedupKey:   "verification:" + profileID.String(),
+	})
+}
+
+// notify 发送通知，未配置通知服务时忽略
+func (s *PluginAuditService) notify(ctx context.Context, msg *notification.Message) {
+	if s.notifier == nil {
+		return
+	}
+
+	result, err := s.notifier.Send(ctx, msg)
+	if err != nil {
+		s.logger.WithError(err).WithField("template", msg.Template).Error("Failed to send notification")
+		return
+	}
+	for _, failed := range result.Failed() {
+		s.logger.WithError(failed.Error).WithFields(logrus.Fields{
+			"template": msg.Template,
+			"user_id":  failed.UserID,
+			"channel":  failed.Channel,
+			"attempts": failed.Attempts,
+		}).Warn("Notification delivery failed")
+	}
 }
\ No newline at end of file
@@ -18,9 +18,14 @@ import (
 	"github.com/codetaoist/laojun-config-center/internal/config"
 	"github.com/codetaoist/laojun-config-center/internal/handlers"
 	"github.com/codetaoist/laojun-config-center/internal/middleware"
+	"github.com/codetaoist/laojun-config-center/internal/resolver"
+	"github.com/codetaoist/laojun-config-center/internal/services"
 	"github.com/codetaoist/laojun-config-center/internal/storage"
 	"github.com/codetaoist/laojun-config-center/internal/storage/file"
+	"github.com/codetaoist/laojun-config-center/internal/storage/git"
+	"github.com/codetaoist/laojun-config-center/internal/storage/postgres"
 	sharedconfig "github.com/codetaoist/laojun-shared/config"
+	"github.com/codetaoist/laojun-shared/database"
 )
 
 // getVersion 返回应用版本信息
@@ -85,26 +90,56 @@ func main() {
 	var configStorage storage.ConfigStorage
 	switch cfg.Storage.Type {
 	case "file":
-		configStorage, err = file.NewFileStorage(cfg.Storage.File.BasePath)
+		configStorage, err = file.NewFileStorage(cfg.Storage.File.BasePath, logger)
 		if err != nil {
 			logger.Fatal("Failed to initialize file storage", zap.Error(err))
 		}
 
+	case "git":
+		configStorage, err = git.NewGitStorage(&git.Options{
+			RepoPath:          cfg.Storage.Git.RepoPath,
+			Remote:            cfg.Storage.Git.Remote,
+			Branch:            cfg.Storage.Git.Branch,
+			SyncInterval:      cfg.Storage.Git.SyncInterval,
+			AuthorEmailDomain: cfg.Storage.Git.AuthorEmailDomain,
+		}, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize git storage", zap.Error(err))
+		}
+
 	case "redis":
 		// TODO: 实现 Redis 存储
 		logger.Fatal("Redis storage not implemented yet")
 	case "database":
-		// TODO: 实现数据库存储
-		logger.Fatal("Database storage not implemented yet")
+		dbCfg := cfg.Storage.Database
+		dbManager, err := database.NewDatabaseManager(database.DatabaseConfig{
+			Host:     dbCfg.Host,
+			Port:     dbCfg.Port,
+			User:     dbCfg.User,
+			Password: dbCfg.Password,
+			DBName:   dbCfg.DBName,
+			SSLMode:  dbCfg.SSLMode,
+		})
+		if err != nil {
+			logger.Fatal("Failed to connect to database", zap.Error(err))
+		}
+		defer dbManager.Close()
+
+		sqlDB, err := dbManager.GetDB().DB()
+		if err != nil {
+			logger.Fatal("Failed to get database connection", zap.Error(err))
+		}
+
+		configStorage, err = postgres.NewPostgresStorage(sqlDB, dbCfg.DSN(), logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize database storage", zap.Error(err))
+		}
 	default:
 		logger.Fatal("Unknown storage type", zap.String("type", cfg.Storage.Type))
 	}
 
 	defer configStorage.Close()
 
-	// 初始化处理器
-	configHandler := handlers.NewConfigHandler(configStorage, logger)
-	
 	// 创建统一配置管理器
 	configManager := sharedconfig.NewDefaultConfigManager(
 		sharedconfig.NewMemoryConfigStorage(),
@@ -112,6 +147,26 @@ func main() {
 			HistorySize: 100,
 		},
 	)
+
+	// 初始化处理器，环境间发布和导入导出经配置服务进行权限检查和审计
+	configService := services.NewConfigService(configStorage, logger, &services.ConfigServiceOptions{
+		AuthEnabled:   cfg.Security.EnableAuth,
+		AuditEnabled:  true,
+		ConfigManager: configManager,
+	})
+
+	// 初始化配置值引用解析器，被引用的配置需通过配置服务的读取权限检查
+	var configResolver *resolver.Resolver
+	if cfg.References.Enabled {
+		configResolver = resolver.NewResolver(configStorage, &resolver.Options{
+			MaxDepth:    cfg.References.MaxDepth,
+			EnvPrefixes: cfg.References.EnvPrefixes,
+			Authorize:   configService.AuthorizeRead,
+		}, logger)
+		defer configResolver.Close()
+	}
+
+	configHandler := handlers.NewConfigHandler(configStorage, configService, configResolver, logger)
 	
 	// 初始化统一配置处理器
 	unifiedHandler := handlers.NewUnifiedConfigHandler(configManager, logger)
@@ -199,8 +254,14 @@ func main() {
 			configs.GET("/:service/:environment/backup", configHandler.BackupConfigs)
 			configs.POST("/:service/:environment/restore", configHandler.RestoreConfigs)
 			configs.GET("/:service/:environment/watch", configHandler.WatchConfigs)
+			configs.GET("/:service/:environment/export", configHandler.ExportConfigs)
+			configs.POST("/:service/:environment/import", configHandler.ImportConfigs)
 		}
 
+		// 跨环境比较与发布
+		api.GET("/diff/:service", configHandler.DiffConfigs)
+		api.POST("/promote", configHandler.PromoteConfigs)
+
 		// 统一配置管理路由
 		unified := api.Group("/unified")
 		{
@@ -10,11 +10,12 @@ import (
 
 // Config 配置中心配置结构
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Storage  StorageConfig  `yaml:"storage"`
-	Security SecurityConfig `yaml:"security"`
-	Log      LogConfig      `yaml:"log"`
-	Logging  LoggingConfig  `yaml:"logging"` // 兼容性字段
+	Server     ServerConfig     `yaml:"server"`
+	Storage    StorageConfig    `yaml:"storage"`
+	Security   SecurityConfig   `yaml:"security"`
+	References ReferencesConfig `yaml:"references"`
+	Log        LogConfig        `yaml:"log"`
+	Logging    LoggingConfig    `yaml:"logging"` // 兼容性字段
 }
 
 // ServerConfig 服务器配置
@@ -31,6 +32,7 @@ type StorageConfig struct {
 	File     FileStorageConfig     `yaml:"file"`
 	Redis    RedisStorageConfig    `yaml:"redis"`
 	Database DatabaseStorageConfig `yaml:"database"`
+	Git      GitStorageConfig      `yaml:"git"`
 }
 
 // FileStorageConfig 文件存储配置
@@ -39,6 +41,15 @@ type FileStorageConfig struct {
 	WatchDir bool   `yaml:"watchDir"`
 }
 
+// GitStorageConfig Git存储配置
+type GitStorageConfig struct {
+	RepoPath          string        `yaml:"repoPath"`
+	Remote            string        `yaml:"remote"`
+	Branch            string        `yaml:"branch"`
+	SyncInterval      time.Duration `yaml:"syncInterval"`
+	AuthorEmailDomain string        `yaml:"authorEmailDomain"`
+}
+
 // RedisStorageConfig Redis存储配置
 type RedisStorageConfig struct {
 	Host     string `yaml:"host"`
@@ -57,6 +68,16 @@ type DatabaseStorageConfig struct {
 	SSLMode  string `yaml:"sslmode"`
 }
 
+// DSN 构建PostgreSQL连接字符串
+func (c DatabaseStorageConfig) DSN() string {
+	sslmode := c.SSLMode
+	if sslmode == "" {
+		sslmode = "disable"
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.DBName, sslmode)
+}
+
 // SecurityConfig 安全配置
 type SecurityConfig struct {
 	EnableAuth bool     `yaml:"enableAuth"`
@@ -64,6 +85,13 @@ type SecurityConfig struct {
 	AllowedIPs []string `yaml:"allowedIPs"`
 }
 
+// ReferencesConfig 配置值引用配置
+type ReferencesConfig struct {
+	Enabled     bool     `yaml:"enabled"`
+	MaxDepth    int      `yaml:"maxDepth"`
+	EnvPrefixes []string `yaml:"envPrefixes"`
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level  string `yaml:"level"`
@@ -173,6 +201,20 @@ func setDefaults(config *Config) {
 		config.Storage.File.BasePath = "./etc/laojun"
 	}
 
+	if config.Storage.Git.RepoPath == "" {
+		config.Storage.Git.RepoPath = "./data/config-repo"
+	}
+	if config.Storage.Git.Branch == "" {
+		config.Storage.Git.Branch = "main"
+	}
+	if config.Storage.Git.Remote != "" && config.Storage.Git.SyncInterval == 0 {
+		config.Storage.Git.SyncInterval = 30 * time.Second
+	}
+
+	if config.References.MaxDepth == 0 {
+		config.References.MaxDepth = 16
+	}
+
 	if config.Log.Level == "" {
 		config.Log.Level = "info"
 	}
@@ -197,6 +239,13 @@ func validate(config *Config) error {
 		if config.Storage.File.BasePath == "" {
 			return fmt.Errorf("file storage base path is required")
 		}
+	case "git":
+		if config.Storage.Git.RepoPath == "" {
+			return fmt.Errorf("git storage repo path is required")
+		}
+		if config.Storage.Git.SyncInterval < 0 {
+			return fmt.Errorf("invalid git sync interval: %s", config.Storage.Git.SyncInterval)
+		}
 	case "redis":
 		if config.Storage.Redis.Host == "" {
 			return fmt.Errorf("redis host is required")
@@ -11,20 +11,28 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/codetaoist/laojun-config-center/internal/resolver"
+	"github.com/codetaoist/laojun-config-center/internal/services"
 	"github.com/codetaoist/laojun-config-center/internal/storage"
 )
 
 // ConfigHandler 配置处理器
 type ConfigHandler struct {
-	storage storage.ConfigStorage
-	logger  *zap.Logger
+	storage  storage.ConfigStorage
+	service  *services.ConfigService
+	resolver *resolver.Resolver
+	logger   *zap.Logger
 }
 
-// NewConfigHandler 创建配置处理器
-func NewConfigHandler(storage storage.ConfigStorage, logger *zap.Logger) *ConfigHandler {
+// NewConfigHandler 创建配置处理器，resolver 为 nil 时不解析配置值中的引用
+//
+// 环境比较、发布和导入导出经 service 执行，以进行权限检查和审计。
+func NewConfigHandler(storage storage.ConfigStorage, service *services.ConfigService, resolver *resolver.Resolver, logger *zap.Logger) *ConfigHandler {
 	return &ConfigHandler{
-		storage: storage,
-		logger:  logger,
+		storage:  storage,
+		service:  service,
+		resolver: resolver,
+		logger:   logger,
 	}
 }
 
@@ -41,7 +49,7 @@ func (h *ConfigHandler) GetConfig(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(storage.WithOperator(c.Request.Context(), h.getOperator(c)), 5*time.Second)
 	defer cancel()
 
 	item, err := h.storage.Get(ctx, service, environment, key)
@@ -66,6 +74,14 @@ func (h *ConfigHandler) GetConfig(c *gin.Context) {
 		return
 	}
 
+	if h.shouldResolve(c) {
+		item, err = h.resolver.Resolve(ctx, item)
+		if err != nil {
+			h.writeResolveError(c, err)
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, item)
 }
 
@@ -88,6 +104,7 @@ func (h *ConfigHandler) SetConfig(c *gin.Context) {
 		Description string                 `json:"description"`
 		Tags        []string               `json:"tags"`
 		Metadata    map[string]interface{} `json:"metadata"`
+		Version     int64                  `json:"version"` // 期望的当前版本，0表示不检查
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -109,6 +126,7 @@ func (h *ConfigHandler) SetConfig(c *gin.Context) {
 		Description: req.Description,
 		Tags:        req.Tags,
 		Metadata:    req.Metadata,
+		Version:     req.Version,
 		CreatedBy:   operator,
 		UpdatedBy:   operator,
 	}
@@ -128,6 +146,14 @@ func (h *ConfigHandler) SetConfig(c *gin.Context) {
 			})
 			return
 		}
+		if conflictErr, ok := err.(*storage.VersionConflictError); ok {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":           "version conflict",
+				"current_version": conflictErr.CurrentVersion,
+				"request_version": conflictErr.RequestVersion,
+			})
+			return
+		}
 
 		h.logger.Error("Failed to set config",
 			zap.String("service", service),
@@ -168,7 +194,7 @@ func (h *ConfigHandler) DeleteConfig(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(storage.WithOperator(c.Request.Context(), h.getOperator(c)), 5*time.Second)
 	defer cancel()
 
 	if err := h.storage.Delete(ctx, service, environment, key); err != nil {
@@ -217,7 +243,7 @@ func (h *ConfigHandler) ListConfigs(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(storage.WithOperator(c.Request.Context(), h.getOperator(c)), 10*time.Second)
 	defer cancel()
 
 	items, err := h.storage.List(ctx, service, environment)
@@ -234,6 +260,14 @@ func (h *ConfigHandler) ListConfigs(c *gin.Context) {
 		return
 	}
 
+	if h.shouldResolve(c) {
+		items, err = h.resolver.ResolveAll(ctx, items)
+		if err != nil {
+			h.writeResolveError(c, err)
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"configs": items,
 		"count":   len(items),
@@ -550,11 +584,18 @@ func (h *ConfigHandler) WatchConfigs(c *gin.Context) {
 	}
 	defer conn.Close()
 
-	// 开始监听
-	ctx, cancel := context.WithCancel(c.Request.Context())
+	// 开始监听，引用解析按操作者检查被引用配置的读取权限
+	ctx, cancel := context.WithCancel(storage.WithOperator(c.Request.Context(), h.getOperator(c)))
 	defer cancel()
 
-	eventCh, err := h.storage.Watch(ctx, service, environment)
+	// 启用引用解析时通过解析器监听，事件值为解析后的值，
+	// 被引用键变更时依赖键也会收到通知
+	var eventCh <-chan *storage.WatchEvent
+	if h.resolver != nil {
+		eventCh, err = h.resolver.Watch(ctx, service, environment)
+	} else {
+		eventCh, err = h.storage.Watch(ctx, service, environment)
+	}
 	if err != nil {
 		h.logger.Error("Failed to start watching",
 			zap.String("service", service),
@@ -689,7 +730,7 @@ func (h *ConfigHandler) BatchDeleteConfigs(c *gin.Context) {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(storage.WithOperator(c.Request.Context(), h.getOperator(c)), 30*time.Second)
 	defer cancel()
 
 	if err := h.storage.DeleteMultiple(ctx, keys); err != nil {
@@ -720,6 +761,43 @@ func (h *ConfigHandler) BatchDeleteConfigs(c *gin.Context) {
 
 // 辅助方法
 
+// shouldResolve 判断是否需要解析引用，请求 raw=true 时返回原始值
+func (h *ConfigHandler) shouldResolve(c *gin.Context) bool {
+	if h.resolver == nil {
+		return false
+	}
+	raw, _ := strconv.ParseBool(c.Query("raw"))
+	return !raw
+}
+
+// writeResolveError 输出引用解析错误
+func (h *ConfigHandler) writeResolveError(c *gin.Context, err error) {
+	switch e := err.(type) {
+	case *resolver.CycleError:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "cyclic config reference",
+			"chain": e.Chain,
+		})
+	case *resolver.ReferenceError:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":     "unresolvable config reference",
+			"from":      e.From,
+			"reference": e.Reference,
+			"message":   e.Message,
+		})
+	case *storage.PermissionDeniedError:
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":    "permission denied for referenced config",
+			"resource": e.Resource,
+		})
+	default:
+		h.logger.Error("Failed to resolve config references", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+	}
+}
+
 func (h *ConfigHandler) getOperator(c *gin.Context) string {
 	// 从请求头获取操作者信息
 	if operator := c.GetHeader("X-Operator"); operator != "" {
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+	"github.com/codetaoist/laojun-config-center/internal/transfer"
+)
+
+// maxImportSize 导入文件大小上限
+const maxImportSize = 5 * 1024 * 1024
+
+// DiffConfigs 比较两个环境或两个时间点的配置
+//
+// GET /api/v1/diff/:service?left=staging&right=prod&left_at=2024-01-01T00:00:00Z&include_unchanged=true
+// right 为空时与 left 相同，用于比较同一环境的不同时间点。
+func (h *ConfigHandler) DiffConfigs(c *gin.Context) {
+	service := c.Param("service")
+	left := transfer.Source{Service: service, Environment: c.Query("left")}
+	right := transfer.Source{Service: service, Environment: c.DefaultQuery("right", left.Environment)}
+
+	if service == "" || left.Environment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "service and left environment are required",
+		})
+		return
+	}
+
+	var err error
+	if left.At, err = parseTimeQuery(c, "left_at"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if right.At, err = parseTimeQuery(c, "right_at"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	includeUnchanged, _ := strconv.ParseBool(c.Query("include_unchanged"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := h.service.Diff(ctx, left, right, includeUnchanged, h.getOperator(c))
+	if err != nil {
+		h.writeTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PromoteConfigs 将源环境的配置发布到目标环境
+func (h *ConfigHandler) PromoteConfigs(c *gin.Context) {
+	var req transfer.PromoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	result, err := h.service.Promote(ctx, &req, h.getOperator(c))
+	if err != nil {
+		h.writeTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ExportConfigs 导出配置，format 支持 json、yaml、env、properties、toml
+func (h *ConfigHandler) ExportConfigs(c *gin.Context) {
+	service := c.Param("service")
+	environment := c.Param("environment")
+
+	format, err := transfer.ParseFormat(c.DefaultQuery("format", "yaml"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	data, err := h.service.Export(ctx, service, environment, format, h.getOperator(c))
+	if err != nil {
+		h.writeTransferError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s.%s.%s", service, environment, format.Extension())
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, format.ContentType(), data)
+}
+
+// ImportConfigs 导入配置文件
+//
+// 文件通过 multipart 字段 file 上传，或直接作为请求体；format 为空时根据文件名推断。
+// prune=true 删除文件中不存在的键，dry_run=true 只返回差异。
+func (h *ConfigHandler) ImportConfigs(c *gin.Context) {
+	service := c.Param("service")
+	environment := c.Param("environment")
+
+	data, filename, err := readImportData(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var format transfer.Format
+	if name := c.Query("format"); name != "" {
+		format, err = transfer.ParseFormat(name)
+	} else if filename != "" {
+		format, err = transfer.DetectFormat(filename)
+	} else {
+		err = fmt.Errorf("format is required")
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := &transfer.ImportOptions{Reason: c.Query("reason")}
+	opts.Prune, _ = strconv.ParseBool(c.Query("prune"))
+	opts.DryRun, _ = strconv.ParseBool(c.Query("dry_run"))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	result, err := h.service.Import(ctx, service, environment, format, data, opts, h.getOperator(c))
+	if err != nil {
+		h.writeTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// writeTransferError 将错误写为响应，已知的存储错误映射为对应状态码
+//
+// 服务层已记录错误日志，这里不再重复记录。
+func (h *ConfigHandler) writeTransferError(c *gin.Context, err error) {
+	switch e := err.(type) {
+	case *storage.ValidationError:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "validation failed: " + e.Error(),
+		})
+	case *storage.ConfigNotFoundError:
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": e.Error(),
+		})
+	case *storage.VersionConflictError:
+		c.JSON(http.StatusConflict, gin.H{
+			"error":           "version conflict",
+			"key":             e.Key,
+			"current_version": e.CurrentVersion,
+			"request_version": e.RequestVersion,
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "internal server error",
+		})
+	}
+}
+
+// readImportData 读取上传文件或请求体
+func readImportData(c *gin.Context) ([]byte, string, error) {
+	if file, err := c.FormFile("file"); err == nil {
+		if file.Size > maxImportSize {
+			return nil, "", fmt.Errorf("import file exceeds %d bytes", maxImportSize)
+		}
+		src, err := file.Open()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open import file")
+		}
+		defer src.Close()
+
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read import file")
+		}
+		return data, file.Filename, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxImportSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read request body")
+	}
+	if len(data) == 0 {
+		return nil, "", fmt.Errorf("import file is required")
+	}
+	if len(data) > maxImportSize {
+		return nil, "", fmt.Errorf("import file exceeds %d bytes", maxImportSize)
+	}
+	return data, c.Query("filename"), nil
+}
+
+// parseTimeQuery 解析RFC3339格式的时间参数，未设置时返回nil
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return &t, nil
+}
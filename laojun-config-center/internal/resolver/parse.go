@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// refKind 引用类型
+type refKind int
+
+const (
+	refKey     refKind = iota // 同一服务/环境下的键
+	refService                // 其他服务/环境下的键
+	refEnv                    // 环境变量
+)
+
+// reference 解析出的引用
+type reference struct {
+	raw          string
+	kind         refKind
+	name         string
+	defaultValue string
+	hasDefault   bool
+}
+
+// segment 字符串片段，literal 与 ref 二选一
+type segment struct {
+	literal string
+	ref     *reference
+}
+
+// parse 将字符串拆分为字面量和引用片段，未闭合的 ${ 按字面量处理
+func parse(value string) []segment {
+	var segments []segment
+	var literal strings.Builder
+
+	flush := func() {
+		if literal.Len() > 0 {
+			segments = append(segments, segment{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(value); {
+		if strings.HasPrefix(value[i:], "$${") {
+			literal.WriteString("${")
+			i += 3
+			continue
+		}
+		if strings.HasPrefix(value[i:], "${") {
+			end := strings.IndexByte(value[i+2:], '}')
+			if end >= 0 {
+				flush()
+				segments = append(segments, segment{ref: parseReference(value[i : i+2+end+1])})
+				i += 2 + end + 1
+				continue
+			}
+		}
+		literal.WriteByte(value[i])
+		i++
+	}
+	flush()
+	return segments
+}
+
+// parseReference 解析 ${...} 形式的引用
+func parseReference(raw string) *reference {
+	body := strings.TrimSpace(raw[2 : len(raw)-1])
+	ref := &reference{raw: raw, kind: refKey}
+
+	if idx := strings.Index(body, ":-"); idx >= 0 {
+		ref.defaultValue = body[idx+2:]
+		ref.hasDefault = true
+		body = body[:idx]
+	}
+
+	switch {
+	case strings.HasPrefix(body, "env:"):
+		ref.kind = refEnv
+		ref.name = strings.TrimPrefix(body, "env:")
+	case strings.HasPrefix(body, "service:"):
+		ref.kind = refService
+		ref.name = strings.TrimPrefix(body, "service:")
+	default:
+		ref.name = body
+	}
+	return ref
+}
+
+// CycleError 循环引用错误
+type CycleError struct {
+	Chain []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cyclic config reference: %s", strings.Join(e.Chain, " -> "))
+}
+
+// ReferenceError 引用无法解析错误
+type ReferenceError struct {
+	From      string
+	Reference string
+	Message   string
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("cannot resolve %s in %s: %s", e.Reference, e.From, e.Message)
+}
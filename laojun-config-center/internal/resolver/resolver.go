@@ -0,0 +1,328 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// defaultMaxDepth 默认的最大引用深度
+const defaultMaxDepth = 16
+
+// Options 解析器选项
+type Options struct {
+	// MaxDepth 引用链的最大深度
+	MaxDepth int
+	// EnvPrefixes 允许引用的环境变量前缀，为空时禁止引用环境变量，
+	// 避免通过配置读取配置中心进程自身的敏感环境变量
+	EnvPrefixes []string
+	// LookupEnv 环境变量查找函数，默认为 os.LookupEnv
+	LookupEnv func(string) (string, bool)
+	// Authorize 检查调用方能否读取被引用的配置，调用方由 storage.WithOperator 放入 ctx，
+	// 为空时不检查。监听时按服务/环境检查，key 为 "*"
+	Authorize func(ctx context.Context, service, environment, key string) error
+}
+
+// Resolver 配置值引用解析器
+//
+// 支持的引用语法：
+//
+//	${database.host}                  同一服务/环境下的键
+//	${service:discovery/prod/port}    其他服务/环境下的键
+//	${env:LAOJUN_DB_HOST}             环境变量
+//	${database.port:-5432}            引用不存在时使用默认值
+//	$${literal}                       转义，输出 ${literal}
+//
+// 值整体只有一个引用时保留被引用值的类型，否则按字符串拼接。
+type Resolver struct {
+	storage storage.ConfigStorage
+	logger  *zap.Logger
+	options Options
+
+	mu        sync.Mutex
+	scopes    map[string]*watchScope
+	upstreams map[string]context.CancelFunc
+}
+
+// NewResolver 创建解析器
+func NewResolver(st storage.ConfigStorage, opts *Options, logger *zap.Logger) *Resolver {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	options := Options{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = defaultMaxDepth
+	}
+	if options.LookupEnv == nil {
+		options.LookupEnv = os.LookupEnv
+	}
+
+	return &Resolver{
+		storage:   st,
+		logger:    logger,
+		options:   options,
+		scopes:    make(map[string]*watchScope),
+		upstreams: make(map[string]context.CancelFunc),
+	}
+}
+
+// Resolve 返回引用已解析的配置项副本，没有引用时返回原配置项
+func (r *Resolver) Resolve(ctx context.Context, item *storage.ConfigItem) (*storage.ConfigItem, error) {
+	if !HasReferences(item.Value) {
+		return item, nil
+	}
+
+	state := r.newState(ctx)
+	state.authorize = r.options.Authorize
+	value, err := state.resolveItem(item)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := *item
+	resolved.Value = value
+	return &resolved, nil
+}
+
+// ResolveAll 批量解析配置项，同一批次中共享已解析的结果
+func (r *Resolver) ResolveAll(ctx context.Context, items []*storage.ConfigItem) ([]*storage.ConfigItem, error) {
+	state := r.newState(ctx)
+	state.authorize = r.options.Authorize
+
+	result := make([]*storage.ConfigItem, len(items))
+	for i, item := range items {
+		if !HasReferences(item.Value) {
+			result[i] = item
+			continue
+		}
+
+		value, err := state.resolveItem(item)
+		if err != nil {
+			return nil, err
+		}
+		resolved := *item
+		resolved.Value = value
+		result[i] = &resolved
+	}
+	return result, nil
+}
+
+// HasReferences 判断值中是否包含引用
+func HasReferences(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.Contains(v, "${")
+	case []interface{}:
+		for _, item := range v {
+			if HasReferences(item) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			if HasReferences(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveState 一次解析过程的状态
+type resolveState struct {
+	ctx      context.Context
+	resolver *Resolver
+	stack    []string
+	cache    map[string]interface{}
+	// scopes 解析过程中访问过的服务/环境，用于建立监听依赖
+	scopes map[string]bool
+	// authorize 非空时读取被引用的配置前检查调用方权限
+	authorize func(ctx context.Context, service, environment, key string) error
+}
+
+func (r *Resolver) newState(ctx context.Context) *resolveState {
+	return &resolveState{
+		ctx:      ctx,
+		resolver: r,
+		cache:    make(map[string]interface{}),
+		scopes:   make(map[string]bool),
+	}
+}
+
+// resolveItem 解析配置项的值
+func (s *resolveState) resolveItem(item *storage.ConfigItem) (interface{}, error) {
+	ref := qualifiedKey(item.Service, item.Environment, item.Key)
+	if value, ok := s.cache[ref]; ok {
+		return value, nil
+	}
+
+	for i, visiting := range s.stack {
+		if visiting == ref {
+			chain := append(append([]string{}, s.stack[i:]...), ref)
+			return nil, &CycleError{Chain: chain}
+		}
+	}
+	if len(s.stack) >= s.resolver.options.MaxDepth {
+		return nil, &ReferenceError{
+			From:      s.stack[len(s.stack)-1],
+			Reference: ref,
+			Message:   fmt.Sprintf("reference depth exceeds %d", s.resolver.options.MaxDepth),
+		}
+	}
+
+	s.stack = append(s.stack, ref)
+	s.scopes[item.Service+"/"+item.Environment] = true
+	value, err := s.resolveValue(item.Service, item.Environment, item.Value)
+	s.stack = s.stack[:len(s.stack)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache[ref] = value
+	return value, nil
+}
+
+// resolveValue 递归解析值中的字符串
+func (s *resolveState) resolveValue(service, environment string, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return s.resolveString(service, environment, v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := s.resolveValue(service, environment, item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			resolved, err := s.resolveValue(service, environment, item)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		}
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolveString 解析字符串中的引用
+func (s *resolveState) resolveString(service, environment, value string) (interface{}, error) {
+	segments := parse(value)
+
+	// 整体为单个引用时保留被引用值的类型
+	if len(segments) == 1 && segments[0].ref != nil {
+		return s.resolveReference(service, environment, segments[0].ref)
+	}
+
+	var b strings.Builder
+	for _, seg := range segments {
+		if seg.ref == nil {
+			b.WriteString(seg.literal)
+			continue
+		}
+
+		resolved, err := s.resolveReference(service, environment, seg.ref)
+		if err != nil {
+			return nil, err
+		}
+		text, err := stringify(resolved)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(text)
+	}
+	return b.String(), nil
+}
+
+// resolveReference 解析单个引用
+func (s *resolveState) resolveReference(service, environment string, ref *reference) (interface{}, error) {
+	from := s.stack[len(s.stack)-1]
+
+	if ref.kind == refEnv {
+		if !s.resolver.envAllowed(ref.name) {
+			return nil, &ReferenceError{From: from, Reference: ref.raw, Message: "environment variable is not allowed"}
+		}
+		if value, ok := s.resolver.options.LookupEnv(ref.name); ok {
+			return value, nil
+		}
+		if ref.hasDefault {
+			return ref.defaultValue, nil
+		}
+		return nil, &ReferenceError{From: from, Reference: ref.raw, Message: "environment variable not set"}
+	}
+
+	targetService, targetEnvironment, key := service, environment, ref.name
+	if ref.kind == refService {
+		parts := strings.SplitN(ref.name, "/", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			return nil, &ReferenceError{From: from, Reference: ref.raw, Message: "expected service:<service>/<environment>/<key>"}
+		}
+		targetService, targetEnvironment, key = parts[0], parts[1], parts[2]
+	}
+
+	// 被引用的服务/环境即使键不存在也要记录，键创建后才能触发通知
+	s.scopes[targetService+"/"+targetEnvironment] = true
+
+	if s.authorize != nil {
+		if err := s.authorize(s.ctx, targetService, targetEnvironment, key); err != nil {
+			return nil, err
+		}
+	}
+
+	item, err := s.resolver.storage.Get(s.ctx, targetService, targetEnvironment, key)
+	if err != nil {
+		if _, ok := err.(*storage.ConfigNotFoundError); ok {
+			if ref.hasDefault {
+				return ref.defaultValue, nil
+			}
+			return nil, &ReferenceError{From: from, Reference: ref.raw, Message: "referenced config not found"}
+		}
+		return nil, err
+	}
+
+	return s.resolveItem(item)
+}
+
+// envAllowed 检查环境变量是否在允许的前缀内
+func (r *Resolver) envAllowed(name string) bool {
+	for _, prefix := range r.options.EnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringify 将引用值转换为拼接用的字符串
+func stringify(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func qualifiedKey(service, environment, key string) string {
+	return service + "/" + environment + "/" + key
+}
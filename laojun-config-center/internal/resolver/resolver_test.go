@@ -0,0 +1,235 @@
+package resolver
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+	"github.com/codetaoist/laojun-config-center/internal/storage/git"
+)
+
+func newTestStorage(t *testing.T) storage.ConfigStorage {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	gs, err := git.NewGitStorage(&git.Options{RepoPath: filepath.Join(t.TempDir(), "repo")}, nil)
+	if err != nil {
+		t.Fatalf("failed to create git storage: %v", err)
+	}
+	t.Cleanup(func() { gs.Close() })
+	return gs
+}
+
+func set(t *testing.T, st storage.ConfigStorage, service, environment, key string, value interface{}) {
+	t.Helper()
+
+	item := &storage.ConfigItem{Service: service, Environment: environment, Key: key, Value: value, Type: "string", UpdatedBy: "test"}
+	if err := st.Set(context.Background(), item); err != nil {
+		t.Fatalf("Set %s failed: %v", key, err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	st := newTestStorage(t)
+	ctx := context.Background()
+
+	set(t, st, "marketplace-api", "prod", "database.host", "db.internal")
+	set(t, st, "marketplace-api", "prod", "database.port", float64(5432))
+	set(t, st, "marketplace-api", "prod", "database.url", "postgres://${database.host}:${database.port}/app")
+	set(t, st, "marketplace-api", "prod", "database.pool_port", "${database.port}")
+	set(t, st, "marketplace-api", "prod", "discovery.url", "http://${service:discovery/prod/host}:${env:LAOJUN_DISCOVERY_PORT}")
+	set(t, st, "marketplace-api", "prod", "escaped", "$${database.host} ${missing:-fallback}")
+	set(t, st, "marketplace-api", "prod", "secret", "${env:HOME}")
+	set(t, st, "discovery", "prod", "host", "discovery.internal")
+
+	r := NewResolver(st, &Options{
+		EnvPrefixes: []string{"LAOJUN_"},
+		LookupEnv: func(name string) (string, bool) {
+			if name == "LAOJUN_DISCOVERY_PORT" {
+				return "8500", true
+			}
+			return "", false
+		},
+	}, nil)
+
+	cases := map[string]interface{}{
+		"database.url":       "postgres://db.internal:5432/app",
+		"database.pool_port": float64(5432),
+		"discovery.url":      "http://discovery.internal:8500",
+		"escaped":            "${database.host} fallback",
+	}
+	for key, expected := range cases {
+		item, err := st.Get(ctx, "marketplace-api", "prod", key)
+		if err != nil {
+			t.Fatalf("Get %s failed: %v", key, err)
+		}
+		resolved, err := r.Resolve(ctx, item)
+		if err != nil {
+			t.Fatalf("Resolve %s failed: %v", key, err)
+		}
+		if resolved.Value != expected {
+			t.Errorf("%s: expected %#v, got %#v", key, expected, resolved.Value)
+		}
+		if item.Value == resolved.Value && key != "database.pool_port" {
+			t.Errorf("%s: raw item must not be modified", key)
+		}
+	}
+
+	// 不在允许前缀内的环境变量
+	item, _ := st.Get(ctx, "marketplace-api", "prod", "secret")
+	if _, err := r.Resolve(ctx, item); err == nil {
+		t.Error("Expected error for disallowed environment variable")
+	} else if _, ok := err.(*ReferenceError); !ok {
+		t.Errorf("Expected ReferenceError, got %T", err)
+	}
+}
+
+func TestResolveCycle(t *testing.T) {
+	st := newTestStorage(t)
+	ctx := context.Background()
+
+	set(t, st, "svc", "prod", "a", "${b}")
+	set(t, st, "svc", "prod", "b", "x-${service:svc/prod/c}")
+	set(t, st, "svc", "prod", "c", "${a}")
+
+	r := NewResolver(st, nil, nil)
+	item, _ := st.Get(ctx, "svc", "prod", "a")
+	_, err := r.Resolve(ctx, item)
+	cycle, ok := err.(*CycleError)
+	if !ok {
+		t.Fatalf("Expected CycleError, got %v", err)
+	}
+	if len(cycle.Chain) != 4 || cycle.Chain[0] != "svc/prod/a" || cycle.Chain[3] != "svc/prod/a" {
+		t.Errorf("Unexpected cycle chain: %v", cycle.Chain)
+	}
+}
+
+func TestWatchDependentKeys(t *testing.T) {
+	st := newTestStorage(t)
+
+	set(t, st, "discovery", "prod", "host", "10.0.0.1")
+	set(t, st, "gateway", "prod", "upstream", "http://${service:discovery/prod/host}:80")
+	set(t, st, "gateway", "prod", "timeout", "5s")
+
+	r := NewResolver(st, nil, nil)
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := r.Watch(ctx, "gateway", "prod")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// 被引用键变更触发依赖键的事件
+	set(t, st, "discovery", "prod", "host", "10.0.0.2")
+
+	select {
+	case event := <-events:
+		if event.Key != "upstream" || event.NewValue != "http://10.0.0.2:80" || event.OldValue != "http://10.0.0.1:80" {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+		if event.Reference != "discovery/prod/host" {
+			t.Errorf("Expected reference discovery/prod/host, got %s", event.Reference)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected event for dependent key")
+	}
+
+	// 直接变更的事件值为解析后的值
+	set(t, st, "gateway", "prod", "timeout", "${service:discovery/prod/host}")
+
+	select {
+	case event := <-events:
+		if event.Key != "timeout" || event.NewValue != "10.0.0.2" || event.Reference != "" {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected event for direct change")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Expected channel to be closed after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected channel to be closed")
+	}
+}
+
+func TestReferencePermission(t *testing.T) {
+	st := newTestStorage(t)
+
+	set(t, st, "billing", "prod", "stripe.secret", "sk_live_x")
+	set(t, st, "gateway", "prod", "host", "gw.internal")
+	set(t, st, "gateway", "prod", "url", "http://${host}")
+	set(t, st, "gateway", "prod", "leak", "${service:billing/prod/stripe.secret}")
+
+	// 只有 alice 能读取 billing 的配置
+	r := NewResolver(st, &Options{
+		Authorize: func(ctx context.Context, service, environment, key string) error {
+			operator := storage.OperatorFromContext(ctx)
+			if service == "billing" && operator != "alice" {
+				return &storage.PermissionDeniedError{Operation: "read", Resource: service + "/" + environment + "/" + key, User: operator}
+			}
+			return nil
+		},
+	}, nil)
+	defer r.Close()
+
+	bob := storage.WithOperator(context.Background(), "bob")
+	alice := storage.WithOperator(context.Background(), "alice")
+
+	leak, _ := st.Get(bob, "gateway", "prod", "leak")
+	if _, err := r.Resolve(bob, leak); err == nil {
+		t.Fatal("Expected permission error for reference to billing")
+	} else if _, ok := err.(*storage.PermissionDeniedError); !ok {
+		t.Errorf("Expected PermissionDeniedError, got %T", err)
+	}
+	if resolved, err := r.Resolve(alice, leak); err != nil || resolved.Value != "sk_live_x" {
+		t.Errorf("Resolve as alice = %v, %v", resolved, err)
+	}
+
+	url, _ := st.Get(bob, "gateway", "prod", "url")
+	if resolved, err := r.Resolve(bob, url); err != nil || resolved.Value != "http://gw.internal" {
+		t.Errorf("Resolve same-service reference = %v, %v", resolved, err)
+	}
+
+	// 监听含无权引用的服务/环境被拒绝
+	ctx, cancel := context.WithCancel(bob)
+	defer cancel()
+	if _, err := r.Watch(ctx, "gateway", "prod"); err == nil {
+		t.Fatal("Expected Watch to be rejected")
+	}
+
+	// 订阅后新增的无权引用关闭订阅
+	set(t, st, "gateway", "prod", "leak", "none")
+	events, err := r.Watch(ctx, "gateway", "prod")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	set(t, st, "gateway", "prod", "token", "${service:billing/prod/stripe.secret}")
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.NewValue == "sk_live_x" {
+				t.Fatalf("Secret leaked through watch: %+v", event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected watch to be closed")
+		}
+	}
+}
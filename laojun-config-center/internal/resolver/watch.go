@@ -0,0 +1,353 @@
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// watchScope 某个服务/环境的监听状态
+type watchScope struct {
+	service     string
+	environment string
+	// subscribers 订阅通道及订阅方的 ctx，依赖增加时据此检查权限
+	subscribers map[chan *storage.WatchEvent]context.Context
+	// resolved 含引用的键解析后的值
+	resolved map[string]interface{}
+	// deps 解析这些键时访问过的服务/环境
+	deps map[string]bool
+}
+
+// Watch 监听服务/环境的配置变更，事件中的值为解析后的值
+//
+// 被引用的键变更时，所有解析结果随之改变的键都会收到 update 事件，
+// 事件的 Reference 为触发变更的键，Version 为该键自身的版本。
+// ctx 结束时关闭返回的通道。配置了 Authorize 时，订阅方需能读取所有被引用的服务/环境，
+// 之后新增的引用指向无权读取的服务/环境时关闭该订阅。
+func (r *Resolver) Watch(ctx context.Context, service, environment string) (<-chan *storage.WatchEvent, error) {
+	scopeKey := service + "/" + environment
+	ch := make(chan *storage.WatchEvent, 100)
+
+	r.mu.Lock()
+	ws, exists := r.scopes[scopeKey]
+	if !exists {
+		ws = &watchScope{
+			service:     service,
+			environment: environment,
+			subscribers: make(map[chan *storage.WatchEvent]context.Context),
+		}
+		if err := r.loadScope(ctx, ws); err != nil {
+			r.mu.Unlock()
+			return nil, err
+		}
+	}
+	if err := r.authorizeScopes(ctx, ws, ws.deps); err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	r.scopes[scopeKey] = ws
+	ws.subscribers[ch] = ctx
+	err := r.reconcileUpstreams()
+	r.mu.Unlock()
+
+	if err != nil {
+		r.unsubscribe(scopeKey, ch)
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		r.unsubscribe(scopeKey, ch)
+	}()
+
+	return ch, nil
+}
+
+// Close 停止所有监听
+func (r *Resolver) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, ws := range r.scopes {
+		for ch := range ws.subscribers {
+			close(ch)
+		}
+	}
+	r.scopes = make(map[string]*watchScope)
+	r.reconcileUpstreams()
+}
+
+func (r *Resolver) unsubscribe(scopeKey string, ch chan *storage.WatchEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ws, exists := r.scopes[scopeKey]
+	if !exists {
+		return
+	}
+	if _, ok := ws.subscribers[ch]; !ok {
+		return
+	}
+
+	delete(ws.subscribers, ch)
+	close(ch)
+	if len(ws.subscribers) == 0 {
+		delete(r.scopes, scopeKey)
+	}
+	r.reconcileUpstreams()
+}
+
+// loadScope 解析服务/环境下所有含引用的键，记录解析结果和依赖，调用方需持有锁
+func (r *Resolver) loadScope(ctx context.Context, ws *watchScope) error {
+	items, err := r.storage.List(ctx, ws.service, ws.environment)
+	if err != nil {
+		return err
+	}
+
+	ws.resolved = make(map[string]interface{})
+	ws.deps = make(map[string]bool)
+
+	state := r.newState(ctx)
+	for _, item := range items {
+		if !HasReferences(item.Value) {
+			continue
+		}
+		value, err := state.resolveItem(item)
+		if err != nil {
+			// 解析失败的键保留原值，引用修复后会收到通知
+			r.logger.Warn("Failed to resolve config references",
+				zap.String("service", item.Service),
+				zap.String("environment", item.Environment),
+				zap.String("key", item.Key),
+				zap.Error(err),
+			)
+			value = item.Value
+		}
+		ws.resolved[item.Key] = value
+	}
+	for scope := range state.scopes {
+		ws.deps[scope] = true
+	}
+	return nil
+}
+
+// reconcileUpstreams 按当前监听和依赖启停底层存储的监听，调用方需持有锁
+func (r *Resolver) reconcileUpstreams() error {
+	needed := make(map[string]bool)
+	for key, ws := range r.scopes {
+		needed[key] = true
+		for dep := range ws.deps {
+			needed[dep] = true
+		}
+	}
+
+	for key, cancel := range r.upstreams {
+		if !needed[key] {
+			cancel()
+			delete(r.upstreams, key)
+			service, environment := splitScope(key)
+			r.storage.StopWatch(service, environment)
+		}
+	}
+
+	for key := range needed {
+		if _, running := r.upstreams[key]; running {
+			continue
+		}
+
+		service, environment := splitScope(key)
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := r.storage.Watch(ctx, service, environment)
+		if err != nil {
+			cancel()
+			return err
+		}
+		r.upstreams[key] = cancel
+		go r.upstreamLoop(ctx, key, events)
+	}
+	return nil
+}
+
+// upstreamLoop 消费底层存储的变更事件
+func (r *Resolver) upstreamLoop(ctx context.Context, scopeKey string, events <-chan *storage.WatchEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			r.dispatch(ctx, scopeKey, event)
+		}
+	}
+}
+
+// dispatch 转发直接变更，并为解析结果改变的依赖键生成事件
+func (r *Resolver) dispatch(ctx context.Context, scopeKey string, event *storage.WatchEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	trigger := qualifiedKey(event.Service, event.Environment, event.Key)
+	depsChanged := false
+
+	// 直接变更：值中的引用解析后转发
+	if ws, exists := r.scopes[scopeKey]; exists {
+		direct := *event
+		if old, tracked := ws.resolved[event.Key]; tracked {
+			direct.OldValue = old
+			delete(ws.resolved, event.Key)
+		}
+		if event.Type != "delete" && HasReferences(event.NewValue) {
+			state := r.newState(ctx)
+			item := &storage.ConfigItem{Service: event.Service, Environment: event.Environment, Key: event.Key, Value: event.NewValue}
+			if value, err := state.resolveItem(item); err == nil {
+				direct.NewValue = value
+			} else {
+				r.logger.Warn("Failed to resolve config references",
+					zap.String("key", trigger),
+					zap.Error(err),
+				)
+			}
+			ws.resolved[event.Key] = direct.NewValue
+			if r.addDeps(ws, state.scopes) {
+				depsChanged = true
+			}
+		}
+		r.broadcast(ws, &direct)
+	}
+
+	// 依赖变更：重新解析依赖该服务/环境的键
+	for _, ws := range r.scopes {
+		if !ws.deps[scopeKey] {
+			continue
+		}
+
+		state := r.newState(ctx)
+		for key, old := range ws.resolved {
+			if ws.service+"/"+ws.environment == scopeKey && key == event.Key {
+				continue
+			}
+
+			item, err := r.storage.Get(ctx, ws.service, ws.environment, key)
+			if err != nil {
+				continue
+			}
+			value, err := state.resolveItem(item)
+			if err != nil {
+				r.logger.Warn("Failed to resolve config references",
+					zap.String("key", qualifiedKey(ws.service, ws.environment, key)),
+					zap.String("trigger", trigger),
+					zap.Error(err),
+				)
+				continue
+			}
+			if valuesEqual(old, value) {
+				continue
+			}
+
+			ws.resolved[key] = value
+			if r.addDeps(ws, state.scopes) {
+				depsChanged = true
+			}
+			r.broadcast(ws, &storage.WatchEvent{
+				Type:        "update",
+				Service:     ws.service,
+				Environment: ws.environment,
+				Key:         key,
+				OldValue:    old,
+				NewValue:    value,
+				Version:     item.Version,
+				Timestamp:   time.Now(),
+				Reference:   trigger,
+			})
+		}
+		if r.addDeps(ws, state.scopes) {
+			depsChanged = true
+		}
+	}
+
+	if depsChanged {
+		if err := r.reconcileUpstreams(); err != nil {
+			r.logger.Warn("Failed to watch referenced configs", zap.Error(err))
+		}
+	}
+}
+
+// addDeps 记录新增的依赖，关闭无权读取新依赖的订阅，调用方需持有锁
+func (r *Resolver) addDeps(ws *watchScope, scopes map[string]bool) bool {
+	added := make(map[string]bool)
+	for scope := range scopes {
+		if !ws.deps[scope] {
+			ws.deps[scope] = true
+			added[scope] = true
+		}
+	}
+	if len(added) == 0 {
+		return false
+	}
+
+	for ch, ctx := range ws.subscribers {
+		if err := r.authorizeScopes(ctx, ws, added); err != nil {
+			r.logger.Warn("Closing config watch without access to referenced configs",
+				zap.String("service", ws.service),
+				zap.String("environment", ws.environment),
+				zap.Error(err),
+			)
+			delete(ws.subscribers, ch)
+			close(ch)
+		}
+	}
+	if len(ws.subscribers) == 0 {
+		delete(r.scopes, ws.service+"/"+ws.environment)
+	}
+	return true
+}
+
+// authorizeScopes 检查订阅方能否读取所依赖的其他服务/环境
+func (r *Resolver) authorizeScopes(ctx context.Context, ws *watchScope, scopes map[string]bool) error {
+	if r.options.Authorize == nil {
+		return nil
+	}
+	for scope := range scopes {
+		if scope == ws.service+"/"+ws.environment {
+			continue
+		}
+		service, environment := splitScope(scope)
+		if err := r.options.Authorize(ctx, service, environment, "*"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// broadcast 发送事件给所有订阅者，通道满时丢弃
+func (r *Resolver) broadcast(ws *watchScope, event *storage.WatchEvent) {
+	for ch := range ws.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 通道满了，丢弃事件
+		}
+	}
+}
+
+func splitScope(key string) (string, string) {
+	idx := strings.Index(key, "/")
+	return key[:idx], key[idx+1:]
+}
+
+func valuesEqual(a, b interface{}) bool {
+	aj, errA := json.Marshal(a)
+	bj, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aj) == string(bj)
+}
@@ -720,6 +720,14 @@ func (s *ConfigService) validateConfigItem(item *storage.ConfigItem) error {
 	return nil
 }
 
+// AuthorizeRead 检查 ctx 中的操作者能否读取配置，供引用解析检查被引用的配置
+func (s *ConfigService) AuthorizeRead(ctx context.Context, service, environment, key string) error {
+	if !s.authEnabled {
+		return nil
+	}
+	return s.checkPermission(ctx, storage.OperatorFromContext(ctx), "read", service, environment, key)
+}
+
 // checkPermission 检查权限（占位符实现）
 func (s *ConfigService) checkPermission(ctx context.Context, operator, action, service, environment, key string) error {
 	// TODO: 实现实际的权限检查逻辑
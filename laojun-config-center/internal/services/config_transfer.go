@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+	"github.com/codetaoist/laojun-config-center/internal/transfer"
+)
+
+// Diff 比较两个环境或两个时间点的配置
+func (s *ConfigService) Diff(ctx context.Context, left, right transfer.Source, includeUnchanged bool, operator string) (*transfer.DiffResult, error) {
+	if left.Service == "" || left.Environment == "" || right.Service == "" || right.Environment == "" {
+		return nil, &storage.ValidationError{
+			Field:   "service/environment",
+			Message: "service and environment are required on both sides",
+		}
+	}
+
+	// 权限检查
+	if s.authEnabled {
+		if err := s.checkPermission(ctx, operator, "list", left.Service, left.Environment, "*"); err != nil {
+			return nil, err
+		}
+		if err := s.checkPermission(ctx, operator, "list", right.Service, right.Environment, "*"); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := transfer.Diff(ctx, s.storage, left, right, includeUnchanged)
+	if err != nil {
+		s.logger.Error("failed to diff configs",
+			zap.Any("left", left),
+			zap.Any("right", right),
+			zap.String("operator", operator),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Promote 将源环境的配置作为一次变更发布到目标环境
+func (s *ConfigService) Promote(ctx context.Context, req *transfer.PromoteRequest, operator string) (*transfer.PromoteResult, error) {
+	// 权限检查
+	if s.authEnabled {
+		if err := s.checkPermission(ctx, operator, "read", req.Service, req.SourceEnvironment, "*"); err != nil {
+			return nil, err
+		}
+		if err := s.checkPermission(ctx, operator, "write", req.Service, req.TargetEnvironment, "*"); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := transfer.Promote(ctx, s.storage, req, operator)
+	if err != nil {
+		s.logger.Error("failed to promote configs",
+			zap.String("service", req.Service),
+			zap.String("source", req.SourceEnvironment),
+			zap.String("target", req.TargetEnvironment),
+			zap.String("operator", operator),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	if req.DryRun {
+		return result, nil
+	}
+
+	// 审计日志
+	if s.auditEnabled {
+		for _, change := range result.Changes {
+			s.auditLog(ctx, "promote", req.Service, req.TargetEnvironment, change.Key, change.LeftValue, change.RightValue, operator)
+		}
+	}
+
+	s.logger.Info("configs promoted successfully",
+		zap.String("service", req.Service),
+		zap.String("source", req.SourceEnvironment),
+		zap.String("target", req.TargetEnvironment),
+		zap.String("operator", operator),
+		zap.Int("count", len(result.Changes)),
+	)
+
+	return result, nil
+}
+
+// Import 导入配置文件
+func (s *ConfigService) Import(ctx context.Context, service, environment string, format transfer.Format, data []byte, opts *transfer.ImportOptions, operator string) (*transfer.ImportResult, error) {
+	// 参数验证
+	if service == "" || environment == "" {
+		return nil, &storage.ValidationError{
+			Field:   "service/environment",
+			Message: "service and environment are required",
+		}
+	}
+
+	// 权限检查
+	if s.authEnabled {
+		if err := s.checkPermission(ctx, operator, "restore", service, environment, "*"); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := transfer.Import(ctx, s.storage, service, environment, format, data, opts, operator)
+	if err != nil {
+		s.logger.Error("failed to import configs",
+			zap.String("service", service),
+			zap.String("environment", environment),
+			zap.String("format", string(format)),
+			zap.String("operator", operator),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	if result.DryRun {
+		return result, nil
+	}
+
+	// 审计日志
+	if s.auditEnabled {
+		for _, change := range result.Changes {
+			s.auditLog(ctx, "import", service, environment, change.Key, change.LeftValue, change.RightValue, operator)
+		}
+	}
+
+	s.logger.Info("configs imported successfully",
+		zap.String("service", service),
+		zap.String("environment", environment),
+		zap.String("format", string(format)),
+		zap.String("operator", operator),
+		zap.Int("count", len(result.Changes)),
+	)
+
+	return result, nil
+}
+
+// Export 导出配置文件
+func (s *ConfigService) Export(ctx context.Context, service, environment string, format transfer.Format, operator string) ([]byte, error) {
+	// 参数验证
+	if service == "" || environment == "" {
+		return nil, &storage.ValidationError{
+			Field:   "service/environment",
+			Message: "service and environment are required",
+		}
+	}
+
+	// 权限检查
+	if s.authEnabled {
+		if err := s.checkPermission(ctx, operator, "backup", service, environment, "*"); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := transfer.Export(ctx, s.storage, service, environment, format)
+	if err != nil {
+		s.logger.Error("failed to export configs",
+			zap.String("service", service),
+			zap.String("environment", environment),
+			zap.String("format", string(format)),
+			zap.String("operator", operator),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	// 审计日志
+	if s.auditEnabled {
+		s.auditLog(ctx, "export", service, environment, "*", nil, len(data), operator)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,33 @@
+package storage
+
+import "context"
+
+type operatorContextKey struct{}
+
+// WithOperator 在上下文中记录操作者，供无操作者参数的存储方法（如Delete）使用
+func WithOperator(ctx context.Context, operator string) context.Context {
+	return context.WithValue(ctx, operatorContextKey{}, operator)
+}
+
+// OperatorFromContext 从上下文中获取操作者，未设置时返回空字符串
+func OperatorFromContext(ctx context.Context) string {
+	if operator, ok := ctx.Value(operatorContextKey{}).(string); ok {
+		return operator
+	}
+	return ""
+}
+
+type reasonContextKey struct{}
+
+// WithReason 在上下文中记录变更原因，支持历史记录的存储会将其写入历史
+func WithReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, reasonContextKey{}, reason)
+}
+
+// ReasonFromContext 从上下文中获取变更原因，未设置时返回空字符串
+func ReasonFromContext(ctx context.Context) string {
+	if reason, ok := ctx.Value(reasonContextKey{}).(string); ok {
+		return reason
+	}
+	return ""
+}
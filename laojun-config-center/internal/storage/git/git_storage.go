@@ -0,0 +1,1028 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// 提交信息中记录配置元数据的trailer键
+const (
+	trailerService     = "Config-Service"
+	trailerEnvironment = "Config-Environment"
+	trailerKey         = "Config-Key"
+	trailerOperation   = "Config-Operation"
+	trailerVersion     = "Config-Version"
+	trailerReason      = "Config-Reason"
+)
+
+// Options Git存储选项
+type Options struct {
+	// RepoPath 本地仓库路径
+	RepoPath string
+	// Remote 远程仓库地址，为空时不进行同步
+	Remote string
+	// Branch 工作分支
+	Branch string
+	// SyncInterval 与远程仓库的同步间隔，小于等于0时不启动同步循环
+	SyncInterval time.Duration
+	// AuthorEmailDomain 操作者作为提交作者时使用的邮箱域名
+	AuthorEmailDomain string
+	// CommitterName 提交者名称
+	CommitterName string
+	// CommitterEmail 提交者邮箱
+	CommitterEmail string
+}
+
+// GitStorage 基于Git仓库的配置存储实现
+//
+// 每个配置项以JSON文件保存在 <service>/<environment>/<key>.json，
+// 每次写入或删除都会生成一次以操作者为作者的提交，历史和版本均从git log读取。
+type GitStorage struct {
+	options *Options
+	logger  *zap.Logger
+
+	mu       sync.RWMutex
+	channels map[string]chan *storage.WatchEvent
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewGitStorage 创建Git存储
+func NewGitStorage(options *Options, logger *zap.Logger) (*GitStorage, error) {
+	if options == nil || options.RepoPath == "" {
+		return nil, fmt.Errorf("git storage repo path is required")
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	opts := *options
+	if opts.Branch == "" {
+		opts.Branch = "main"
+	}
+	if opts.AuthorEmailDomain == "" {
+		opts.AuthorEmailDomain = "laojun.local"
+	}
+	if opts.CommitterName == "" {
+		opts.CommitterName = "laojun-config-center"
+	}
+	if opts.CommitterEmail == "" {
+		opts.CommitterEmail = "config-center@" + opts.AuthorEmailDomain
+	}
+
+	gs := &GitStorage{
+		options:  &opts,
+		logger:   logger,
+		channels: make(map[string]chan *storage.WatchEvent),
+		stopCh:   make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if err := gs.initRepository(ctx); err != nil {
+		return nil, err
+	}
+
+	if opts.Remote != "" && opts.SyncInterval > 0 {
+		gs.wg.Add(1)
+		go gs.syncLoop()
+	}
+
+	return gs, nil
+}
+
+// Get 获取配置
+func (gs *GitStorage) Get(ctx context.Context, service, environment, key string) (*storage.ConfigItem, error) {
+	if err := validatePath(service, environment, key); err != nil {
+		return nil, err
+	}
+
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	return gs.readItem(service, environment, key)
+}
+
+// Set 设置配置，每次设置生成一次提交
+func (gs *GitStorage) Set(ctx context.Context, item *storage.ConfigItem) error {
+	if err := gs.Validate(ctx, item); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	return gs.commitItems(ctx, []*storage.ConfigItem{item}, item.UpdatedBy, storage.ReasonFromContext(ctx))
+}
+
+// Delete 删除配置，操作者从上下文中获取
+func (gs *GitStorage) Delete(ctx context.Context, service, environment, key string) error {
+	if err := validatePath(service, environment, key); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	return gs.commitDelete(ctx, []storage.ConfigKey{{Service: service, Environment: environment, Key: key}},
+		storage.OperatorFromContext(ctx), false)
+}
+
+// List 列出配置
+func (gs *GitStorage) List(ctx context.Context, service, environment string) ([]*storage.ConfigItem, error) {
+	if err := validatePath(service, environment); err != nil {
+		return nil, err
+	}
+
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	return gs.listItems(filepath.Join(gs.options.RepoPath, service, environment))
+}
+
+// Exists 检查配置是否存在
+func (gs *GitStorage) Exists(ctx context.Context, service, environment, key string) (bool, error) {
+	if err := validatePath(service, environment, key); err != nil {
+		return false, err
+	}
+
+	_, err := os.Stat(gs.itemPath(service, environment, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// GetMultiple 批量获取配置
+func (gs *GitStorage) GetMultiple(ctx context.Context, keys []storage.ConfigKey) ([]*storage.ConfigItem, error) {
+	for _, key := range keys {
+		if err := validatePath(key.Service, key.Environment, key.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	var items []*storage.ConfigItem
+	for _, key := range keys {
+		item, err := gs.readItem(key.Service, key.Environment, key.Key)
+		if err != nil {
+			if _, ok := err.(*storage.ConfigNotFoundError); ok {
+				continue // 跳过不存在的配置
+			}
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// SetMultiple 批量设置配置，所有配置项在同一次提交中写入
+func (gs *GitStorage) SetMultiple(ctx context.Context, items []*storage.ConfigItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	for _, item := range items {
+		if err := gs.Validate(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	return gs.commitItems(ctx, items, items[0].UpdatedBy, storage.ReasonFromContext(ctx))
+}
+
+// DeleteMultiple 批量删除配置，所有删除在同一次提交中完成
+func (gs *GitStorage) DeleteMultiple(ctx context.Context, keys []storage.ConfigKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		if err := validatePath(key.Service, key.Environment, key.Key); err != nil {
+			return err
+		}
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	return gs.commitDelete(ctx, keys, storage.OperatorFromContext(ctx), true)
+}
+
+// ApplyBatch 写入和删除配置，所有修改在同一次提交中完成
+func (gs *GitStorage) ApplyBatch(ctx context.Context, items []*storage.ConfigItem, deletes []storage.ConfigKey) error {
+	if len(items) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	for _, item := range items {
+		if err := gs.Validate(ctx, item); err != nil {
+			return err
+		}
+	}
+	for _, key := range deletes {
+		if err := validatePath(key.Service, key.Environment, key.Key); err != nil {
+			return err
+		}
+	}
+
+	operator := storage.OperatorFromContext(ctx)
+	if operator == "" && len(items) > 0 {
+		operator = items[0].UpdatedBy
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	now := time.Now()
+	written, events, err := gs.writeItems(ctx, items, operator, now)
+	if err != nil {
+		return err
+	}
+	removed, deleted, err := gs.removeItems(deletes, true, now)
+	if err != nil {
+		gs.resetPaths(written)
+		return err
+	}
+
+	subject := fmt.Sprintf("update %d configs, delete %d configs", len(items), len(removed))
+	return gs.commitChanges(ctx, append(written, removed...), append(events, deleted...), operator, subject, storage.ReasonFromContext(ctx))
+}
+
+// Search 搜索配置
+func (gs *GitStorage) Search(ctx context.Context, query *storage.SearchQuery) ([]*storage.ConfigItem, error) {
+	if err := validatePath(query.Service, query.Environment); err != nil {
+		return nil, err
+	}
+
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	root := gs.options.RepoPath
+	if query.Service != "" && query.Environment != "" {
+		root = filepath.Join(root, query.Service, query.Environment)
+	}
+
+	allItems, err := gs.listItems(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*storage.ConfigItem
+	for _, item := range allItems {
+		if matchesQuery(item, query) {
+			results = append(results, item)
+		}
+	}
+
+	// 分页
+	if query.Offset > 0 {
+		if query.Offset >= len(results) {
+			return []*storage.ConfigItem{}, nil
+		}
+		results = results[query.Offset:]
+	}
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// GetHistory 从git log获取配置历史，按时间倒序返回
+func (gs *GitStorage) GetHistory(ctx context.Context, service, environment, key string, limit int) ([]*storage.ConfigHistory, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if err := validatePath(service, environment, key); err != nil {
+		return nil, err
+	}
+
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	// 多取一条用于补全最旧记录的旧值
+	commits, err := gs.logPath(ctx, gs.relPath(service, environment, key), limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]*storage.ConfigHistory, 0, len(commits))
+	for i, c := range commits {
+		if i == limit {
+			break
+		}
+
+		h := &storage.ConfigHistory{
+			ID:          c.time.UnixNano(),
+			Service:     service,
+			Environment: environment,
+			Key:         key,
+			Operation:   c.trailers[trailerOperation],
+			CreatedAt:   c.time,
+			CreatedBy:   c.authorName,
+			Reason:      c.trailers[trailerReason],
+			Metadata: map[string]interface{}{
+				"commit": c.hash,
+			},
+		}
+		if c.item != nil {
+			h.NewValue = c.item.Value
+			h.Version = c.item.Version
+		}
+		if v, err := strconv.ParseInt(c.trailers[trailerVersion], 10, 64); err == nil {
+			h.Version = v
+		}
+		if i+1 < len(commits) && commits[i+1].item != nil {
+			h.OldValue = commits[i+1].item.Value
+		}
+
+		// 直接在仓库中修改（未经配置中心）的提交没有trailer，根据内容推断操作类型
+		if h.Operation == "" {
+			switch {
+			case c.item == nil:
+				h.Operation = "delete"
+			case h.OldValue == nil:
+				h.Operation = "create"
+			default:
+				h.Operation = "update"
+			}
+		}
+
+		history = append(history, h)
+	}
+
+	return history, nil
+}
+
+// GetVersion 获取指定版本的配置
+func (gs *GitStorage) GetVersion(ctx context.Context, service, environment, key string, version int64) (*storage.ConfigItem, error) {
+	if err := validatePath(service, environment, key); err != nil {
+		return nil, err
+	}
+
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	return gs.findVersion(ctx, service, environment, key, version)
+}
+
+// Rollback 回滚到指定版本，生成一次新的提交
+func (gs *GitStorage) Rollback(ctx context.Context, service, environment, key string, version int64, operator string) error {
+	if err := validatePath(service, environment, key); err != nil {
+		return err
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	item, err := gs.findVersion(ctx, service, environment, key, version)
+	if err != nil {
+		return err
+	}
+
+	item.UpdatedBy = operator
+	return gs.commitItems(ctx, []*storage.ConfigItem{item}, operator, fmt.Sprintf("rollback to version %d", version))
+}
+
+// Watch 监听配置变化，包括本地写入和从远程仓库同步的变更
+func (gs *GitStorage) Watch(ctx context.Context, service, environment string) (<-chan *storage.WatchEvent, error) {
+	watchKey := service + "/" + environment
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if ch, exists := gs.channels[watchKey]; exists {
+		return ch, nil
+	}
+
+	ch := make(chan *storage.WatchEvent, 100)
+	gs.channels[watchKey] = ch
+	return ch, nil
+}
+
+// StopWatch 停止监听
+func (gs *GitStorage) StopWatch(service, environment string) {
+	watchKey := service + "/" + environment
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if ch, exists := gs.channels[watchKey]; exists {
+		close(ch)
+		delete(gs.channels, watchKey)
+	}
+}
+
+// Backup 备份配置
+func (gs *GitStorage) Backup(ctx context.Context, service, environment string) ([]byte, error) {
+	items, err := gs.List(ctx, service, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(items)
+}
+
+// Restore 恢复配置，所有配置项在同一次提交中写入
+func (gs *GitStorage) Restore(ctx context.Context, service, environment string, data []byte, operator string) error {
+	var items []*storage.ConfigItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("failed to unmarshal backup data: %w", err)
+	}
+
+	for _, item := range items {
+		item.Service = service
+		item.Environment = environment
+		item.UpdatedBy = operator
+		if err := gs.Validate(ctx, item); err != nil {
+			return err
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	return gs.commitItems(ctx, items, operator, "restore from backup")
+}
+
+// Validate 验证配置
+func (gs *GitStorage) Validate(ctx context.Context, item *storage.ConfigItem) error {
+	if item.Service == "" {
+		return &storage.ValidationError{Field: "service", Message: "service is required"}
+	}
+	if item.Environment == "" {
+		return &storage.ValidationError{Field: "environment", Message: "environment is required"}
+	}
+	if item.Key == "" {
+		return &storage.ValidationError{Field: "key", Message: "key is required"}
+	}
+	if item.Value == nil {
+		return &storage.ValidationError{Field: "value", Message: "value is required"}
+	}
+	return validatePath(item.Service, item.Environment, item.Key)
+}
+
+// validatePath 校验服务、环境和键可以安全地拼接为仓库内的路径，
+// 防止通过 .. 或路径分隔符访问仓库之外或 .git 目录中的文件
+func validatePath(parts ...string) error {
+	for _, part := range parts {
+		if strings.ContainsAny(part, `/\`) || part == "." || part == ".." || strings.HasPrefix(part, ".git") {
+			return &storage.ValidationError{Field: "key", Message: "invalid path component: " + part}
+		}
+	}
+	return nil
+}
+
+// HealthCheck 健康检查
+func (gs *GitStorage) HealthCheck(ctx context.Context) error {
+	if _, err := gs.git(ctx, "rev-parse", "--git-dir"); err != nil {
+		return fmt.Errorf("git repository not accessible: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭存储
+func (gs *GitStorage) Close() error {
+	close(gs.stopCh)
+	gs.wg.Wait()
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	for _, ch := range gs.channels {
+		close(ch)
+	}
+	gs.channels = make(map[string]chan *storage.WatchEvent)
+
+	return nil
+}
+
+// 辅助方法
+
+// initRepository 初始化本地仓库，配置了远程仓库时从远程克隆
+func (gs *GitStorage) initRepository(ctx context.Context) error {
+	if err := os.MkdirAll(gs.options.RepoPath, 0755); err != nil {
+		return fmt.Errorf("failed to create repo path: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(gs.options.RepoPath, ".git")); os.IsNotExist(err) {
+		if gs.options.Remote != "" {
+			if _, err := gs.git(ctx, "clone", gs.options.Remote, "."); err != nil {
+				return err
+			}
+		} else if _, err := gs.git(ctx, "init"); err != nil {
+			return err
+		}
+	}
+
+	// 没有HEAD时（空仓库，或远程默认分支不是工作分支），检出远程工作分支或将HEAD指向它
+	if _, err := gs.git(ctx, "rev-parse", "--verify", "HEAD"); err != nil {
+		remoteRef := "refs/remotes/origin/" + gs.options.Branch
+		if _, err := gs.git(ctx, "rev-parse", "--verify", remoteRef); err == nil {
+			_, err = gs.git(ctx, "checkout", "--quiet", "-B", gs.options.Branch, remoteRef)
+			return err
+		}
+		_, err = gs.git(ctx, "symbolic-ref", "HEAD", "refs/heads/"+gs.options.Branch)
+		return err
+	}
+
+	current, err := gs.git(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+	if current != gs.options.Branch {
+		if _, err := gs.git(ctx, "checkout", gs.options.Branch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commitItems 写入配置文件并提交，调用方需持有写锁
+func (gs *GitStorage) commitItems(ctx context.Context, items []*storage.ConfigItem, operator, reason string) error {
+	now := time.Now()
+	paths, events, err := gs.writeItems(ctx, items, operator, now)
+	if err != nil {
+		return err
+	}
+	return gs.commitChanges(ctx, paths, events, operator, fmt.Sprintf("update %d configs", len(items)), reason)
+}
+
+// commitDelete 删除配置文件并提交，调用方需持有写锁
+func (gs *GitStorage) commitDelete(ctx context.Context, keys []storage.ConfigKey, operator string, skipMissing bool) error {
+	paths, events, err := gs.removeItems(keys, skipMissing, time.Now())
+	if err != nil {
+		return err
+	}
+	return gs.commitChanges(ctx, paths, events, operator, fmt.Sprintf("delete %d configs", len(paths)), "")
+}
+
+// writeItems 写入配置文件，返回写入的路径和待发送的事件
+func (gs *GitStorage) writeItems(ctx context.Context, items []*storage.ConfigItem, operator string, now time.Time) ([]string, []*storage.WatchEvent, error) {
+	paths := make([]string, 0, len(items))
+	events := make([]*storage.WatchEvent, 0, len(items))
+
+	for _, item := range items {
+		oldItem, err := gs.readItem(item.Service, item.Environment, item.Key)
+		if err != nil {
+			if _, ok := err.(*storage.ConfigNotFoundError); !ok {
+				return nil, nil, err
+			}
+			oldItem = nil
+		}
+
+		event := &storage.WatchEvent{
+			Service:     item.Service,
+			Environment: item.Environment,
+			Key:         item.Key,
+			Timestamp:   now,
+		}
+		if oldItem != nil {
+			event.Type = "update"
+			event.OldValue = oldItem.Value
+			item.Version = oldItem.Version + 1
+			item.CreatedAt = oldItem.CreatedAt
+			item.CreatedBy = oldItem.CreatedBy
+		} else {
+			// 删除后重新创建时沿用历史中的版本号，保证版本单调递增
+			event.Type = "create"
+			item.Version = gs.lastVersion(ctx, item.Service, item.Environment, item.Key) + 1
+			item.CreatedAt = now
+			if item.CreatedBy == "" {
+				item.CreatedBy = operator
+			}
+		}
+		item.UpdatedAt = now
+		item.UpdatedBy = operator
+		event.NewValue = item.Value
+		event.Version = item.Version
+
+		data, err := json.MarshalIndent(item, "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal config: %w", err)
+		}
+
+		path := gs.itemPath(item.Service, item.Environment, item.Key)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			return nil, nil, fmt.Errorf("failed to write config file: %w", err)
+		}
+		paths = append(paths, gs.relPath(item.Service, item.Environment, item.Key))
+		events = append(events, event)
+	}
+
+	return paths, events, nil
+}
+
+// removeItems 删除配置文件，返回删除的路径和待发送的事件，失败时恢复已删除的文件
+func (gs *GitStorage) removeItems(keys []storage.ConfigKey, skipMissing bool, now time.Time) ([]string, []*storage.WatchEvent, error) {
+	var (
+		paths  []string
+		events []*storage.WatchEvent
+	)
+
+	for _, key := range keys {
+		oldItem, err := gs.readItem(key.Service, key.Environment, key.Key)
+		if err != nil {
+			if _, ok := err.(*storage.ConfigNotFoundError); ok && skipMissing {
+				continue // 跳过不存在的配置
+			}
+			gs.resetPaths(paths)
+			return nil, nil, err
+		}
+
+		if err := os.Remove(gs.itemPath(key.Service, key.Environment, key.Key)); err != nil {
+			gs.resetPaths(paths)
+			return nil, nil, fmt.Errorf("failed to delete config file: %w", err)
+		}
+		paths = append(paths, gs.relPath(key.Service, key.Environment, key.Key))
+		events = append(events, &storage.WatchEvent{
+			Type:        "delete",
+			Service:     oldItem.Service,
+			Environment: oldItem.Environment,
+			Key:         oldItem.Key,
+			OldValue:    oldItem.Value,
+			Version:     oldItem.Version + 1,
+			Timestamp:   now,
+		})
+	}
+
+	return paths, events, nil
+}
+
+// commitChanges 提交已写入工作区的修改并发送事件，单个修改时在提交中记录配置信息
+func (gs *GitStorage) commitChanges(ctx context.Context, paths []string, events []*storage.WatchEvent, operator, subject, reason string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	trailers := map[string]string{trailerReason: reason}
+	if len(events) == 1 {
+		event := events[0]
+		subject = fmt.Sprintf("%s %s/%s/%s", event.Type, event.Service, event.Environment, event.Key)
+		trailers[trailerService] = event.Service
+		trailers[trailerEnvironment] = event.Environment
+		trailers[trailerKey] = event.Key
+		trailers[trailerOperation] = event.Type
+		trailers[trailerVersion] = strconv.FormatInt(event.Version, 10)
+	}
+
+	if err := gs.commit(ctx, paths, operator, subject, trailers); err != nil {
+		gs.resetPaths(paths)
+		return err
+	}
+
+	for _, event := range events {
+		gs.sendEvent(event)
+	}
+	return nil
+}
+
+// commit 暂存指定路径并以操作者为作者提交
+func (gs *GitStorage) commit(ctx context.Context, paths []string, operator, subject string, trailers map[string]string) error {
+	args := append([]string{"add", "--all", "--"}, paths...)
+	if _, err := gs.git(ctx, args...); err != nil {
+		return err
+	}
+
+	if operator == "" {
+		operator = "system"
+	}
+
+	var message strings.Builder
+	message.WriteString(subject)
+	message.WriteString("\n\n")
+	keys := make([]string, 0, len(trailers))
+	for k, v := range trailers {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&message, "%s: %s\n", k, strings.ReplaceAll(trailers[k], "\n", " "))
+	}
+
+	_, err := gs.git(ctx, "-c", "commit.gpgsign=false", "commit", "--quiet", "--no-verify",
+		"--author", gs.author(operator), "--message", message.String())
+	return err
+}
+
+// resetPaths 提交失败时将工作区恢复到HEAD，避免残留未提交的修改
+func (gs *GitStorage) resetPaths(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := gs.git(ctx, "rev-parse", "--verify", "HEAD"); err != nil {
+		// 仓库中还没有提交，直接删除写入的文件
+		gs.git(ctx, append([]string{"rm", "--cached", "--quiet", "--ignore-unmatch", "--"}, paths...)...)
+		for _, p := range paths {
+			os.Remove(filepath.Join(gs.options.RepoPath, p))
+		}
+		return
+	}
+
+	args := append([]string{"checkout", "HEAD", "--"}, paths...)
+	if _, err := gs.git(ctx, args...); err != nil {
+		gs.logger.Warn("Failed to reset config files after commit failure", zap.Error(err))
+	}
+}
+
+// author 根据操作者生成提交作者
+func (gs *GitStorage) author(operator string) string {
+	name := strings.NewReplacer("<", "", ">", "", "\n", " ").Replace(operator)
+	if strings.Contains(name, "@") {
+		return fmt.Sprintf("%s <%s>", name, name)
+	}
+	email := strings.ReplaceAll(strings.ToLower(name), " ", ".")
+	return fmt.Sprintf("%s <%s@%s>", name, email, gs.options.AuthorEmailDomain)
+}
+
+// gitCommit git log解析出的提交
+type gitCommit struct {
+	hash       string
+	authorName string
+	time       time.Time
+	trailers   map[string]string
+	item       *storage.ConfigItem
+}
+
+// logPath 获取修改过指定路径的提交，按时间倒序，并读取每次提交时的配置内容
+func (gs *GitStorage) logPath(ctx context.Context, relPath string, limit int) ([]*gitCommit, error) {
+	if _, err := gs.git(ctx, "rev-parse", "--verify", "HEAD"); err != nil {
+		return nil, nil
+	}
+
+	args := []string{"log", "--format=%H%x1f%an%x1f%at%x1f%B%x1e"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	args = append(args, "--", relPath)
+
+	out, err := gs.git(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*gitCommit
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+
+		seconds, _ := strconv.ParseInt(fields[2], 10, 64)
+		c := &gitCommit{
+			hash:       fields[0],
+			authorName: fields[1],
+			time:       time.Unix(seconds, 0),
+			trailers:   parseTrailers(fields[3]),
+		}
+
+		if content, err := gs.git(ctx, "show", c.hash+":"+relPath); err == nil {
+			var item storage.ConfigItem
+			if err := json.Unmarshal([]byte(content), &item); err == nil {
+				c.item = &item
+			}
+		}
+
+		commits = append(commits, c)
+	}
+
+	return commits, nil
+}
+
+// findVersion 从提交历史中查找指定版本，调用方需持有锁
+func (gs *GitStorage) findVersion(ctx context.Context, service, environment, key string, version int64) (*storage.ConfigItem, error) {
+	commits, err := gs.logPath(ctx, gs.relPath(service, environment, key), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range commits {
+		if c.item != nil && c.item.Version == version {
+			return c.item, nil
+		}
+	}
+
+	return nil, &storage.ConfigNotFoundError{
+		Service:     service,
+		Environment: environment,
+		Key:         key,
+	}
+}
+
+// lastVersion 返回指定配置在历史中的最新版本号，没有历史时返回0
+func (gs *GitStorage) lastVersion(ctx context.Context, service, environment, key string) int64 {
+	commits, err := gs.logPath(ctx, gs.relPath(service, environment, key), 1)
+	if err != nil || len(commits) == 0 {
+		return 0
+	}
+
+	if v, err := strconv.ParseInt(commits[0].trailers[trailerVersion], 10, 64); err == nil {
+		return v
+	}
+	if commits[0].item != nil {
+		return commits[0].item.Version
+	}
+	return 0
+}
+
+// readItem 从工作区读取配置
+func (gs *GitStorage) readItem(service, environment, key string) (*storage.ConfigItem, error) {
+	data, err := os.ReadFile(gs.itemPath(service, environment, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, &storage.ConfigNotFoundError{
+				Service:     service,
+				Environment: environment,
+				Key:         key,
+			}
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var item storage.ConfigItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return &item, nil
+}
+
+// listItems 递归读取目录下的所有配置，跳过.git目录
+func (gs *GitStorage) listItems(root string) ([]*storage.ConfigItem, error) {
+	var items []*storage.ConfigItem
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // 忽略错误，继续遍历
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		var item storage.ConfigItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			return nil
+		}
+
+		items = append(items, &item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+
+	return items, nil
+}
+
+// sendEvent 向监听者发送事件，调用方需持有锁
+func (gs *GitStorage) sendEvent(event *storage.WatchEvent) {
+	if ch, exists := gs.channels[event.Service+"/"+event.Environment]; exists {
+		select {
+		case ch <- event:
+		default:
+			// 通道满了，丢弃事件
+		}
+	}
+}
+
+func (gs *GitStorage) itemPath(service, environment, key string) string {
+	return filepath.Join(gs.options.RepoPath, service, environment, key+".json")
+}
+
+func (gs *GitStorage) relPath(service, environment, key string) string {
+	return service + "/" + environment + "/" + key + ".json"
+}
+
+// git 在仓库目录执行git命令，返回去除首尾空白的标准输出
+func (gs *GitStorage) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = gs.options.RepoPath
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_COMMITTER_NAME="+gs.options.CommitterName,
+		"GIT_COMMITTER_EMAIL="+gs.options.CommitterEmail,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", &storage.StorageError{
+			Operation: "git " + args[0],
+			Cause:     fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String())),
+		}
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// parseTrailers 解析提交信息中的 "Key: Value" trailer
+func parseTrailers(message string) map[string]string {
+	trailers := make(map[string]string)
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "Config-") {
+			continue
+		}
+		if idx := strings.Index(line, ": "); idx > 0 {
+			trailers[line[:idx]] = strings.TrimSpace(line[idx+2:])
+		}
+	}
+	return trailers
+}
+
+// matchesQuery 检查配置项是否匹配查询条件
+func matchesQuery(item *storage.ConfigItem, query *storage.SearchQuery) bool {
+	if query.Service != "" && !strings.Contains(strings.ToLower(item.Service), strings.ToLower(query.Service)) {
+		return false
+	}
+	if query.Environment != "" && !strings.Contains(strings.ToLower(item.Environment), strings.ToLower(query.Environment)) {
+		return false
+	}
+	if query.Key != "" && !strings.Contains(strings.ToLower(item.Key), strings.ToLower(query.Key)) {
+		return false
+	}
+	if query.Value != "" {
+		valueStr := fmt.Sprintf("%v", item.Value)
+		if !strings.Contains(strings.ToLower(valueStr), strings.ToLower(query.Value)) {
+			return false
+		}
+	}
+
+	for _, queryTag := range query.Tags {
+		found := false
+		for _, itemTag := range item.Tags {
+			if strings.EqualFold(itemTag, queryTag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for key, value := range query.Metadata {
+		itemValue, exists := item.Metadata[key]
+		if !exists {
+			return false
+		}
+		if !strings.Contains(strings.ToLower(fmt.Sprintf("%v", itemValue)), strings.ToLower(value)) {
+			return false
+		}
+	}
+
+	return true
+}
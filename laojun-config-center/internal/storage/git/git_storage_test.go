@@ -0,0 +1,288 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// newTestRemote 创建本地裸仓库作为远程仓库
+func newTestRemote(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("failed to init bare repo: %v: %s", err, out)
+	}
+	return remote
+}
+
+func newTestStorage(t *testing.T, remote string) *GitStorage {
+	t.Helper()
+
+	gs, err := NewGitStorage(&Options{
+		RepoPath: filepath.Join(t.TempDir(), "repo"),
+		Remote:   remote,
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create git storage: %v", err)
+	}
+	t.Cleanup(func() { gs.Close() })
+	return gs
+}
+
+func setValue(t *testing.T, gs *GitStorage, key string, value interface{}, operator string) {
+	t.Helper()
+
+	err := gs.Set(context.Background(), &storage.ConfigItem{
+		Service:     "marketplace-api",
+		Environment: "prod",
+		Key:         key,
+		Value:       value,
+		UpdatedBy:   operator,
+	})
+	if err != nil {
+		t.Fatalf("failed to set %s: %v", key, err)
+	}
+}
+
+func TestGitStorageHistoryAndRollback(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	ctx := context.Background()
+	gs := newTestStorage(t, "")
+
+	setValue(t, gs, "db.host", "10.0.0.1", "alice")
+	setValue(t, gs, "db.host", "10.0.0.2", "bob")
+
+	history, err := gs.GetHistory(ctx, "marketplace-api", "prod", "db.host", 10)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 history records, got %d", len(history))
+	}
+	if history[0].CreatedBy != "bob" || history[0].Operation != "update" || history[0].Version != 2 {
+		t.Errorf("Unexpected latest history record: %+v", history[0])
+	}
+	if history[0].OldValue != "10.0.0.1" || history[0].NewValue != "10.0.0.2" {
+		t.Errorf("Expected 10.0.0.1 -> 10.0.0.2, got %v -> %v", history[0].OldValue, history[0].NewValue)
+	}
+	if history[1].CreatedBy != "alice" || history[1].Operation != "create" {
+		t.Errorf("Unexpected first history record: %+v", history[1])
+	}
+
+	if err := gs.Rollback(ctx, "marketplace-api", "prod", "db.host", 1, "carol"); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	item, err := gs.Get(ctx, "marketplace-api", "prod", "db.host")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if item.Value != "10.0.0.1" || item.Version != 3 || item.UpdatedBy != "carol" {
+		t.Errorf("Unexpected item after rollback: %+v", item)
+	}
+
+	author, err := gs.git(ctx, "log", "-1", "--format=%an <%ae>")
+	if err != nil {
+		t.Fatalf("git log failed: %v", err)
+	}
+	if author != "carol <carol@laojun.local>" {
+		t.Errorf("Expected commit author carol, got %s", author)
+	}
+
+	if err := gs.Delete(storage.WithOperator(ctx, "dave"), "marketplace-api", "prod", "db.host"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	setValue(t, gs, "db.host", "10.0.0.3", "erin")
+
+	version, err := gs.GetVersion(ctx, "marketplace-api", "prod", "db.host", 2)
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if version.Value != "10.0.0.2" {
+		t.Errorf("Expected version 2 value 10.0.0.2, got %v", version.Value)
+	}
+
+	item, _ = gs.Get(ctx, "marketplace-api", "prod", "db.host")
+	if item.Version != 5 {
+		t.Errorf("Expected version to continue after delete, got %d", item.Version)
+	}
+}
+
+func TestGitStorageApplyBatch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	ctx := context.Background()
+	gs := newTestStorage(t, "")
+
+	setValue(t, gs, "db.host", "10.0.0.1", "alice")
+	setValue(t, gs, "db.port", 5432, "alice")
+
+	ctx = storage.WithReason(storage.WithOperator(ctx, "bob"), "import from yaml")
+	err := gs.ApplyBatch(ctx, []*storage.ConfigItem{
+		{Service: "marketplace-api", Environment: "prod", Key: "db.host", Value: "10.0.0.2"},
+		{Service: "marketplace-api", Environment: "prod", Key: "db.name", Value: "market"},
+	}, []storage.ConfigKey{
+		{Service: "marketplace-api", Environment: "prod", Key: "db.port"},
+		{Service: "marketplace-api", Environment: "prod", Key: "missing"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyBatch failed: %v", err)
+	}
+
+	count, err := gs.git(ctx, "rev-list", "--count", "HEAD")
+	if err != nil {
+		t.Fatalf("git rev-list failed: %v", err)
+	}
+	if count != "3" {
+		t.Errorf("Expected one commit for the batch, got %s commits in total", count)
+	}
+	subject, _ := gs.git(ctx, "log", "-1", "--format=%s %an")
+	if subject != "update 2 configs, delete 1 configs bob" {
+		t.Errorf("Unexpected batch commit: %s", subject)
+	}
+
+	items, err := gs.List(ctx, "marketplace-api", "prod")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	values := map[string]interface{}{}
+	for _, item := range items {
+		values[item.Key] = item.Value
+	}
+	if len(values) != 2 || values["db.host"] != "10.0.0.2" || values["db.name"] != "market" {
+		t.Errorf("Unexpected items after batch: %v", values)
+	}
+}
+
+func TestGitStorageSync(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestRemote(t)
+
+	a := newTestStorage(t, remote)
+	b := newTestStorage(t, remote)
+
+	events, err := b.Watch(ctx, "marketplace-api", "prod")
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	setValue(t, a, "cache.ttl", float64(60), "alice")
+	if err := a.Sync(ctx); err != nil {
+		t.Fatalf("Sync a failed: %v", err)
+	}
+	if err := b.Sync(ctx); err != nil {
+		t.Fatalf("Sync b failed: %v", err)
+	}
+
+	item, err := b.Get(ctx, "marketplace-api", "prod", "cache.ttl")
+	if err != nil {
+		t.Fatalf("Get from b failed: %v", err)
+	}
+	if item.Value != float64(60) {
+		t.Errorf("Expected synced value 60, got %v", item.Value)
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != "create" || event.Key != "cache.ttl" {
+			t.Errorf("Unexpected watch event: %+v", event)
+		}
+	default:
+		t.Error("Expected watch event for synced change")
+	}
+
+	// 两端修改同一配置，后同步的一端应得到版本冲突
+	setValue(t, a, "cache.ttl", float64(120), "alice")
+	setValue(t, b, "cache.ttl", float64(300), "bob")
+
+	if err := a.Sync(ctx); err != nil {
+		t.Fatalf("Sync a failed: %v", err)
+	}
+
+	err = b.Sync(ctx)
+	conflict, ok := err.(*storage.VersionConflictError)
+	if !ok {
+		t.Fatalf("Expected VersionConflictError, got %v", err)
+	}
+	if conflict.Key != "cache.ttl" || conflict.CurrentVersion != 2 || conflict.RequestVersion != 2 {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+
+	// 冲突时保留本地状态
+	item, _ = b.Get(ctx, "marketplace-api", "prod", "cache.ttl")
+	if item.Value != float64(300) {
+		t.Errorf("Expected local value to be kept on conflict, got %v", item.Value)
+	}
+
+	// 不同配置的修改可以自动合并
+	setValue(t, a, "cache.size", float64(1024), "alice")
+	if err := a.Sync(ctx); err != nil {
+		t.Fatalf("Sync a failed: %v", err)
+	}
+	c := newTestStorage(t, remote)
+	setValue(t, c, "db.pool", float64(10), "carol")
+	setValue(t, a, "db.timeout", float64(5), "alice")
+	if err := a.Sync(ctx); err != nil {
+		t.Fatalf("Sync a failed: %v", err)
+	}
+	if err := c.Sync(ctx); err != nil {
+		t.Fatalf("Sync c failed: %v", err)
+	}
+	if ok, _ := c.Exists(ctx, "marketplace-api", "prod", "db.timeout"); !ok {
+		t.Error("Expected remote change to be merged into c")
+	}
+}
+
+func TestGitStorageRejectsPathTraversal(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	ctx := context.Background()
+	gs := newTestStorage(t, "")
+
+	// 仓库之外的文件不能通过 .. 读取或删除
+	outside := filepath.Join(filepath.Dir(gs.options.RepoPath), "secret.json")
+	if err := os.WriteFile(outside, []byte(`{"key":"secret","value":"s3cr3t"}`), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	isValidationError := func(err error) bool {
+		_, ok := err.(*storage.ValidationError)
+		return ok
+	}
+
+	if _, err := gs.Get(ctx, "..", "..", "secret"); !isValidationError(err) {
+		t.Errorf("Get: expected ValidationError, got %v", err)
+	}
+	if err := gs.Delete(ctx, "..", "..", "secret"); !isValidationError(err) {
+		t.Errorf("Delete: expected ValidationError, got %v", err)
+	}
+	if _, err := gs.GetHistory(ctx, "marketplace-api", "..", "../secret", 10); !isValidationError(err) {
+		t.Errorf("GetHistory: expected ValidationError, got %v", err)
+	}
+	if _, err := gs.List(ctx, "..", "prod"); !isValidationError(err) {
+		t.Errorf("List: expected ValidationError, got %v", err)
+	}
+	if _, err := gs.Exists(ctx, ".git", "refs", "HEAD"); !isValidationError(err) {
+		t.Errorf("Exists: expected ValidationError, got %v", err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("Expected file outside repository to be untouched: %v", err)
+	}
+}
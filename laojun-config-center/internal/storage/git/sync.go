@@ -0,0 +1,253 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// Sync 与远程仓库同步：拉取远程提交并变基本地提交，然后推送
+//
+// 本地与远程修改了同一配置文件时放弃变基，保持本地状态不变，
+// 并返回 *storage.VersionConflictError，需要人工在仓库中解决冲突。
+func (gs *GitStorage) Sync(ctx context.Context) error {
+	if gs.options.Remote == "" {
+		return nil
+	}
+
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	branch := gs.options.Branch
+	remoteRef := "refs/remotes/origin/" + branch
+
+	heads, err := gs.git(ctx, "ls-remote", "--heads", "origin", branch)
+	if err != nil {
+		return err
+	}
+
+	localHead, _ := gs.git(ctx, "rev-parse", "--verify", "HEAD")
+
+	if heads != "" {
+		if _, err := gs.git(ctx, "fetch", "--quiet", "origin", "+refs/heads/"+branch+":"+remoteRef); err != nil {
+			return err
+		}
+
+		remoteHead, err := gs.git(ctx, "rev-parse", remoteRef)
+		if err != nil {
+			return err
+		}
+
+		if err := gs.integrate(ctx, localHead, remoteHead, remoteRef); err != nil {
+			return err
+		}
+	}
+
+	// 本地没有提交时无需推送
+	if localHead == "" && heads == "" {
+		return nil
+	}
+
+	ahead, err := gs.git(ctx, "rev-list", "--count", remoteRef+"..HEAD")
+	if err != nil && heads != "" {
+		return err
+	}
+	if heads != "" && ahead == "0" {
+		return nil
+	}
+
+	_, err = gs.git(ctx, "push", "--quiet", "origin", "HEAD:refs/heads/"+branch)
+	return err
+}
+
+// integrate 将远程提交合入本地分支，并对远程带来的变更发送监听事件
+func (gs *GitStorage) integrate(ctx context.Context, localHead, remoteHead, remoteRef string) error {
+	switch {
+	case localHead == "":
+		// 本地为空仓库，直接检出远程分支
+		if _, err := gs.git(ctx, "reset", "--hard", remoteHead); err != nil {
+			return err
+		}
+		gs.emitRemoteChanges(ctx, "", remoteHead)
+		return nil
+	case localHead == remoteHead:
+		return nil
+	}
+
+	base, err := gs.git(ctx, "merge-base", localHead, remoteHead)
+	if err != nil {
+		return err
+	}
+
+	switch base {
+	case remoteHead:
+		// 只有本地领先，等待推送
+		return nil
+	case localHead:
+		if _, err := gs.git(ctx, "merge", "--quiet", "--ff-only", remoteRef); err != nil {
+			return err
+		}
+	default:
+		if _, err := gs.git(ctx, "-c", "commit.gpgsign=false", "rebase", "--quiet", remoteRef); err != nil {
+			conflict := gs.conflictError(ctx, localHead, remoteHead)
+			if _, abortErr := gs.git(ctx, "rebase", "--abort"); abortErr != nil {
+				gs.logger.Error("Failed to abort rebase", zap.Error(abortErr))
+			}
+			if conflict != nil {
+				return conflict
+			}
+			return err
+		}
+	}
+
+	gs.emitRemoteChanges(ctx, base, remoteHead)
+	return nil
+}
+
+// conflictError 根据变基中的冲突文件构造版本冲突错误
+func (gs *GitStorage) conflictError(ctx context.Context, localHead, remoteHead string) *storage.VersionConflictError {
+	out, err := gs.git(ctx, "diff", "--name-only", "--diff-filter=U")
+	if err != nil || out == "" {
+		return nil
+	}
+
+	path := strings.Split(out, "\n")[0]
+	service, environment, key, ok := splitItemPath(path)
+	if !ok {
+		return &storage.VersionConflictError{Key: path}
+	}
+
+	return &storage.VersionConflictError{
+		Service:        service,
+		Environment:    environment,
+		Key:            key,
+		CurrentVersion: gs.versionAt(ctx, remoteHead, path),
+		RequestVersion: gs.versionAt(ctx, localHead, path),
+	}
+}
+
+// emitRemoteChanges 对 from..to 之间变更的配置文件发送监听事件
+func (gs *GitStorage) emitRemoteChanges(ctx context.Context, from, to string) {
+	var (
+		out string
+		err error
+	)
+	if from == "" {
+		out, err = gs.git(ctx, "ls-tree", "-r", "--name-only", to)
+	} else {
+		out, err = gs.git(ctx, "diff", "--name-only", from, to)
+	}
+	if err != nil {
+		gs.logger.Warn("Failed to list remote changes", zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	for _, path := range strings.Split(out, "\n") {
+		service, environment, key, ok := splitItemPath(path)
+		if !ok {
+			continue
+		}
+
+		event := &storage.WatchEvent{
+			Service:     service,
+			Environment: environment,
+			Key:         key,
+			Timestamp:   now,
+		}
+
+		var oldItem *storage.ConfigItem
+		if from != "" {
+			oldItem = gs.itemAt(ctx, from, path)
+		}
+		if oldItem != nil {
+			event.OldValue = oldItem.Value
+		}
+
+		newItem, err := gs.readItem(service, environment, key)
+		switch {
+		case err != nil:
+			event.Type = "delete"
+			if oldItem != nil {
+				event.Version = oldItem.Version + 1
+			}
+		case oldItem == nil:
+			event.Type = "create"
+			event.NewValue = newItem.Value
+			event.Version = newItem.Version
+		default:
+			event.Type = "update"
+			event.NewValue = newItem.Value
+			event.Version = newItem.Version
+		}
+
+		gs.sendEvent(event)
+	}
+}
+
+// itemAt 读取指定提交中的配置，不存在时返回nil
+func (gs *GitStorage) itemAt(ctx context.Context, rev, path string) *storage.ConfigItem {
+	content, err := gs.git(ctx, "show", rev+":"+path)
+	if err != nil {
+		return nil
+	}
+
+	var item storage.ConfigItem
+	if err := json.Unmarshal([]byte(content), &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// versionAt 返回指定提交中配置的版本号，不存在时返回0
+func (gs *GitStorage) versionAt(ctx context.Context, rev, path string) int64 {
+	if item := gs.itemAt(ctx, rev, path); item != nil {
+		return item.Version
+	}
+	return 0
+}
+
+// syncLoop 定期与远程仓库同步
+func (gs *GitStorage) syncLoop() {
+	defer gs.wg.Done()
+
+	ticker := time.NewTicker(gs.options.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-gs.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), gs.options.SyncInterval)
+			if err := gs.Sync(ctx); err != nil {
+				if conflict, ok := err.(*storage.VersionConflictError); ok {
+					gs.logger.Error("Config conflict with remote repository, manual resolution required",
+						zap.String("service", conflict.Service),
+						zap.String("environment", conflict.Environment),
+						zap.String("key", conflict.Key),
+						zap.Int64("current_version", conflict.CurrentVersion),
+						zap.Int64("request_version", conflict.RequestVersion),
+					)
+				} else {
+					gs.logger.Warn("Failed to sync config repository", zap.Error(err))
+				}
+			}
+			cancel()
+		}
+	}
+}
+
+// splitItemPath 将 <service>/<environment>/<key>.json 拆分为配置键
+func splitItemPath(path string) (service, environment, key string, ok bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 || !strings.HasSuffix(parts[2], ".json") {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], strings.TrimSuffix(parts[2], ".json"), true
+}
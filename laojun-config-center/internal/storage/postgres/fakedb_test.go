@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDB 脚本化的 database/sql 驱动，按SQL片段匹配预设的结果，
+// 用于在没有PostgreSQL时覆盖比较更新和变更通知的SQL路径
+type fakeDB struct {
+	mu        sync.Mutex
+	handlers  []fakeHandler
+	queries   []fakeQuery
+	commits   int
+	rollbacks int
+}
+
+type fakeHandler struct {
+	match string
+	fn    func(args []driver.Value) (*fakeResult, error)
+}
+
+// fakeQuery 已执行的SQL及参数
+type fakeQuery struct {
+	query string
+	args  []driver.Value
+}
+
+// fakeResult 查询返回的行或执行影响的行数
+type fakeResult struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+}
+
+func newFakeDB(t *testing.T) (*fakeDB, *sql.DB) {
+	t.Helper()
+
+	f := &fakeDB{}
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { db.Close() })
+	return f, db
+}
+
+// on 注册处理器，SQL包含 match 时使用；后注册的处理器优先，便于在用例中覆盖
+func (f *fakeDB) on(match string, fn func(args []driver.Value) (*fakeResult, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers = append([]fakeHandler{{match: match, fn: fn}}, f.handlers...)
+}
+
+// executed 返回包含 match 的已执行SQL
+func (f *fakeDB) executed(match string) []fakeQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result []fakeQuery
+	for _, q := range f.queries {
+		if strings.Contains(q.query, match) {
+			result = append(result, q)
+		}
+	}
+	return result
+}
+
+func (f *fakeDB) run(query string, args []driver.Value) (*fakeResult, error) {
+	f.mu.Lock()
+	f.queries = append(f.queries, fakeQuery{query: query, args: args})
+	var handler *fakeHandler
+	for i := range f.handlers {
+		if strings.Contains(query, f.handlers[i].match) {
+			handler = &f.handlers[i]
+			break
+		}
+	}
+	f.mu.Unlock()
+
+	if handler == nil {
+		return nil, fmt.Errorf("fakedb: unexpected query: %s", query)
+	}
+	return handler.fn(args)
+}
+
+// Connect 实现 driver.Connector
+func (f *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{db: f}, nil
+}
+
+// Driver 实现 driver.Connector
+func (f *fakeDB) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fakedb: use sql.OpenDB")
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{db: c.db}, nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.rollbacks++
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	result, err := s.db.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(result.affected), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	result, err := s.db.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{result: result}, nil
+}
+
+type fakeRows struct {
+	result *fakeResult
+	next   int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.result.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
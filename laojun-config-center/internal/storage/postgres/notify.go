@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// changeNotification NOTIFY负载
+type changeNotification struct {
+	ID          int64  `json:"id"`
+	Service     string `json:"service"`
+	Environment string `json:"environment"`
+}
+
+// notifyLoop 接收数据库变更通知并分发给监听者
+func (ps *PostgresStorage) notifyLoop() {
+	defer ps.wg.Done()
+
+	for {
+		select {
+		case <-ps.stopCh:
+			return
+		case n, ok := <-ps.listener.Notify:
+			if !ok {
+				return
+			}
+			if n == nil {
+				// 监听连接已重建，期间的通知可能丢失
+				ps.logger.Warn("Config change listener reconnected, notifications may have been missed")
+				continue
+			}
+			ps.handleNotification(n)
+		case <-time.After(90 * time.Second):
+			go ps.listener.Ping()
+		}
+	}
+}
+
+// handleNotification 查询通知对应的历史记录并发送监听事件
+func (ps *PostgresStorage) handleNotification(n *pq.Notification) {
+	var change changeNotification
+	if err := json.Unmarshal([]byte(n.Extra), &change); err != nil {
+		ps.logger.Warn("Invalid config change notification", zap.String("payload", n.Extra), zap.Error(err))
+		return
+	}
+
+	// 没有监听者时跳过查询
+	ps.mu.RLock()
+	_, watched := ps.channels[change.Service+"/"+change.Environment]
+	ps.mu.RUnlock()
+	if !watched {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	h, err := scanHistory(ps.db.QueryRowContext(ctx,
+		`SELECT `+historyColumns+` FROM config_history WHERE id = $1`, change.ID))
+	if err != nil {
+		ps.logger.Warn("Failed to load config change", zap.Int64("id", change.ID), zap.Error(err))
+		return
+	}
+
+	event := &storage.WatchEvent{
+		Type:        h.Operation,
+		Service:     h.Service,
+		Environment: h.Environment,
+		Key:         h.Key,
+		OldValue:    h.OldValue,
+		NewValue:    h.NewValue,
+		Version:     h.Version,
+		Timestamp:   h.CreatedAt,
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if ch, exists := ps.channels[h.Service+"/"+h.Environment]; exists {
+		select {
+		case ch <- event:
+		default:
+			// 通道满了，丢弃事件
+		}
+	}
+}
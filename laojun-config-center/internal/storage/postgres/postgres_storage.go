@@ -0,0 +1,816 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// itemColumns 查询配置项时使用的列，标签通过子查询聚合
+const itemColumns = `i.service, i.environment, i.key, i.value, i.type, i.version, i.description, i.metadata,
+	i.created_at, i.updated_at, i.created_by, i.updated_by,
+	COALESCE((SELECT array_agg(t.tag ORDER BY t.tag) FROM config_tags t
+		WHERE t.service = i.service AND t.environment = i.environment AND t.key = i.key), '{}')`
+
+// historyColumns 查询历史记录时使用的列
+const historyColumns = `id, service, environment, key, old_value, new_value, version, operation,
+	created_at, created_by, reason, metadata`
+
+// PostgresStorage PostgreSQL存储实现
+//
+// 写操作使用 ConfigItem.Version 做乐观并发控制：Version 大于0时必须等于当前版本，
+// 否则返回 *storage.VersionConflictError；Version 为0时不做检查。
+// 变更通过 LISTEN/NOTIFY 广播，所有实例的 Watch 都能收到。
+type PostgresStorage struct {
+	db       *sql.DB
+	listener *pq.Listener
+	logger   *zap.Logger
+
+	mu       sync.RWMutex
+	channels map[string]chan *storage.WatchEvent
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewPostgresStorage 创建PostgreSQL存储
+//
+// db 由调用方管理生命周期；dsn 用于建立LISTEN专用连接。
+func NewPostgresStorage(db *sql.DB, dsn string, logger *zap.Logger) (*PostgresStorage, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, schemaSQL); err != nil {
+		return nil, fmt.Errorf("failed to initialize config schema: %w", err)
+	}
+
+	listener := pq.NewListener(dsn, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Config change listener event", zap.Int("event", int(event)), zap.Error(err))
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", notifyChannel, err)
+	}
+
+	ps := &PostgresStorage{
+		db:       db,
+		listener: listener,
+		logger:   logger,
+		channels: make(map[string]chan *storage.WatchEvent),
+		stopCh:   make(chan struct{}),
+	}
+
+	ps.wg.Add(1)
+	go ps.notifyLoop()
+
+	return ps, nil
+}
+
+// Get 获取配置
+func (ps *PostgresStorage) Get(ctx context.Context, service, environment, key string) (*storage.ConfigItem, error) {
+	row := ps.db.QueryRowContext(ctx,
+		`SELECT `+itemColumns+` FROM config_items i WHERE i.service = $1 AND i.environment = $2 AND i.key = $3`,
+		service, environment, key)
+
+	item, err := scanItem(row)
+	if err == sql.ErrNoRows {
+		return nil, &storage.ConfigNotFoundError{Service: service, Environment: environment, Key: key}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	return item, nil
+}
+
+// Set 设置配置，Version 大于0时按版本做比较更新
+func (ps *PostgresStorage) Set(ctx context.Context, item *storage.ConfigItem) error {
+	if err := ps.Validate(ctx, item); err != nil {
+		return err
+	}
+
+	return ps.withTx(ctx, func(tx *sql.Tx) error {
+		return ps.setTx(ctx, tx, item, true, storage.ReasonFromContext(ctx))
+	})
+}
+
+// Delete 删除配置，操作者从上下文中获取
+func (ps *PostgresStorage) Delete(ctx context.Context, service, environment, key string) error {
+	return ps.withTx(ctx, func(tx *sql.Tx) error {
+		return ps.deleteTx(ctx, tx, storage.ConfigKey{Service: service, Environment: environment, Key: key},
+			storage.OperatorFromContext(ctx))
+	})
+}
+
+// List 列出配置
+func (ps *PostgresStorage) List(ctx context.Context, service, environment string) ([]*storage.ConfigItem, error) {
+	rows, err := ps.db.QueryContext(ctx,
+		`SELECT `+itemColumns+` FROM config_items i WHERE i.service = $1 AND i.environment = $2 ORDER BY i.key`,
+		service, environment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanItems(rows)
+}
+
+// Exists 检查配置是否存在
+func (ps *PostgresStorage) Exists(ctx context.Context, service, environment, key string) (bool, error) {
+	var exists bool
+	err := ps.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM config_items WHERE service = $1 AND environment = $2 AND key = $3)`,
+		service, environment, key).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check config existence: %w", err)
+	}
+	return exists, nil
+}
+
+// GetMultiple 批量获取配置，跳过不存在的配置
+func (ps *PostgresStorage) GetMultiple(ctx context.Context, keys []storage.ConfigKey) ([]*storage.ConfigItem, error) {
+	var items []*storage.ConfigItem
+	for _, key := range keys {
+		item, err := ps.Get(ctx, key.Service, key.Environment, key.Key)
+		if err != nil {
+			if _, ok := err.(*storage.ConfigNotFoundError); ok {
+				continue
+			}
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// SetMultiple 在同一事务中批量设置配置，任一配置版本冲突时全部回滚
+func (ps *PostgresStorage) SetMultiple(ctx context.Context, items []*storage.ConfigItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	for _, item := range items {
+		if err := ps.Validate(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	return ps.withTx(ctx, func(tx *sql.Tx) error {
+		for _, item := range items {
+			if err := ps.setTx(ctx, tx, item, true, storage.ReasonFromContext(ctx)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMultiple 在同一事务中批量删除配置，跳过不存在的配置
+func (ps *PostgresStorage) DeleteMultiple(ctx context.Context, keys []storage.ConfigKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	operator := storage.OperatorFromContext(ctx)
+	return ps.withTx(ctx, func(tx *sql.Tx) error {
+		for _, key := range keys {
+			if err := ps.deleteTx(ctx, tx, key, operator); err != nil {
+				if _, ok := err.(*storage.ConfigNotFoundError); ok {
+					continue
+				}
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ApplyBatch 在同一事务中写入和删除配置，任一失败时全部回滚
+func (ps *PostgresStorage) ApplyBatch(ctx context.Context, items []*storage.ConfigItem, deletes []storage.ConfigKey) error {
+	if len(items) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	for _, item := range items {
+		if err := ps.Validate(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	operator := storage.OperatorFromContext(ctx)
+	return ps.withTx(ctx, func(tx *sql.Tx) error {
+		for _, item := range items {
+			if err := ps.setTx(ctx, tx, item, true, storage.ReasonFromContext(ctx)); err != nil {
+				return err
+			}
+		}
+		for _, key := range deletes {
+			if err := ps.deleteTx(ctx, tx, key, operator); err != nil {
+				if _, ok := err.(*storage.ConfigNotFoundError); ok {
+					continue
+				}
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Search 搜索配置
+func (ps *PostgresStorage) Search(ctx context.Context, query *storage.SearchQuery) ([]*storage.ConfigItem, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	addArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if query.Service != "" {
+		conditions = append(conditions, "i.service ILIKE "+addArg("%"+query.Service+"%"))
+	}
+	if query.Environment != "" {
+		conditions = append(conditions, "i.environment ILIKE "+addArg("%"+query.Environment+"%"))
+	}
+	if query.Key != "" {
+		conditions = append(conditions, "i.key ILIKE "+addArg("%"+query.Key+"%"))
+	}
+	if query.Value != "" {
+		conditions = append(conditions, "i.value::text ILIKE "+addArg("%"+query.Value+"%"))
+	}
+	for _, tag := range query.Tags {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM config_tags t
+			WHERE t.service = i.service AND t.environment = i.environment AND t.key = i.key
+			AND LOWER(t.tag) = LOWER(`+addArg(tag)+`))`)
+	}
+	for key, value := range query.Metadata {
+		conditions = append(conditions, "i.metadata->>"+addArg(key)+" ILIKE "+addArg("%"+value+"%"))
+	}
+
+	sqlQuery := `SELECT ` + itemColumns + ` FROM config_items i`
+	if len(conditions) > 0 {
+		sqlQuery += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	sqlQuery += " ORDER BY i.service, i.environment, i.key"
+	if query.Limit > 0 {
+		sqlQuery += " LIMIT " + addArg(query.Limit)
+	}
+	if query.Offset > 0 {
+		sqlQuery += " OFFSET " + addArg(query.Offset)
+	}
+
+	rows, err := ps.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search configs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanItems(rows)
+}
+
+// GetHistory 获取配置历史，按时间倒序返回
+func (ps *PostgresStorage) GetHistory(ctx context.Context, service, environment, key string, limit int) ([]*storage.ConfigHistory, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	rows, err := ps.db.QueryContext(ctx,
+		`SELECT `+historyColumns+` FROM config_history
+		WHERE service = $1 AND environment = $2 AND key = $3 ORDER BY id DESC LIMIT $4`,
+		service, environment, key, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []*storage.ConfigHistory{}
+	for rows.Next() {
+		h, err := scanHistory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan config history: %w", err)
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetVersion 获取指定版本的配置
+func (ps *PostgresStorage) GetVersion(ctx context.Context, service, environment, key string, version int64) (*storage.ConfigItem, error) {
+	current, err := ps.Get(ctx, service, environment, key)
+	if err == nil && current.Version == version {
+		return current, nil
+	}
+
+	h, err := ps.historyVersion(ctx, ps.db, service, environment, key, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storage.ConfigItem{
+		Service:     h.Service,
+		Environment: h.Environment,
+		Key:         h.Key,
+		Value:       h.NewValue,
+		Version:     h.Version,
+		CreatedAt:   h.CreatedAt,
+		UpdatedAt:   h.CreatedAt,
+		CreatedBy:   h.CreatedBy,
+		UpdatedBy:   h.CreatedBy,
+	}, nil
+}
+
+// Rollback 在事务中回滚到指定版本，生成新的版本
+func (ps *PostgresStorage) Rollback(ctx context.Context, service, environment, key string, version int64, operator string) error {
+	return ps.withTx(ctx, func(tx *sql.Tx) error {
+		h, err := ps.historyVersion(ctx, tx, service, environment, key, version)
+		if err != nil {
+			return err
+		}
+
+		item, err := ps.lockItem(ctx, tx, service, environment, key)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			item = &storage.ConfigItem{Service: service, Environment: environment, Key: key, Type: "string"}
+		}
+
+		item.Value = h.NewValue
+		item.UpdatedBy = operator
+		return ps.setTx(ctx, tx, item, false, fmt.Sprintf("rollback to version %d", version))
+	})
+}
+
+// Watch 监听配置变化
+func (ps *PostgresStorage) Watch(ctx context.Context, service, environment string) (<-chan *storage.WatchEvent, error) {
+	watchKey := service + "/" + environment
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ch, exists := ps.channels[watchKey]; exists {
+		return ch, nil
+	}
+
+	ch := make(chan *storage.WatchEvent, 100)
+	ps.channels[watchKey] = ch
+	return ch, nil
+}
+
+// StopWatch 停止监听
+func (ps *PostgresStorage) StopWatch(service, environment string) {
+	watchKey := service + "/" + environment
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ch, exists := ps.channels[watchKey]; exists {
+		close(ch)
+		delete(ps.channels, watchKey)
+	}
+}
+
+// Backup 备份配置
+func (ps *PostgresStorage) Backup(ctx context.Context, service, environment string) ([]byte, error) {
+	items, err := ps.List(ctx, service, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(items)
+}
+
+// Restore 在同一事务中恢复配置，备份中的版本号不参与冲突检查
+func (ps *PostgresStorage) Restore(ctx context.Context, service, environment string, data []byte, operator string) error {
+	var items []*storage.ConfigItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("failed to unmarshal backup data: %w", err)
+	}
+
+	for _, item := range items {
+		item.Service = service
+		item.Environment = environment
+		item.UpdatedBy = operator
+		if err := ps.Validate(ctx, item); err != nil {
+			return err
+		}
+	}
+
+	return ps.withTx(ctx, func(tx *sql.Tx) error {
+		for _, item := range items {
+			if err := ps.setTx(ctx, tx, item, false, "restore from backup"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Validate 验证配置
+func (ps *PostgresStorage) Validate(ctx context.Context, item *storage.ConfigItem) error {
+	if item.Service == "" {
+		return &storage.ValidationError{Field: "service", Message: "service is required"}
+	}
+	if item.Environment == "" {
+		return &storage.ValidationError{Field: "environment", Message: "environment is required"}
+	}
+	if item.Key == "" {
+		return &storage.ValidationError{Field: "key", Message: "key is required"}
+	}
+	if item.Value == nil {
+		return &storage.ValidationError{Field: "value", Message: "value is required"}
+	}
+	if item.Version < 0 {
+		return &storage.ValidationError{Field: "version", Message: "version must not be negative"}
+	}
+	return nil
+}
+
+// HealthCheck 健康检查
+func (ps *PostgresStorage) HealthCheck(ctx context.Context) error {
+	if err := ps.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database not accessible: %w", err)
+	}
+	if err := ps.listener.Ping(); err != nil {
+		return fmt.Errorf("config change listener not connected: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭存储，数据库连接由调用方关闭
+func (ps *PostgresStorage) Close() error {
+	close(ps.stopCh)
+	err := ps.listener.Close()
+	ps.wg.Wait()
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for _, ch := range ps.channels {
+		close(ch)
+	}
+	ps.channels = make(map[string]chan *storage.WatchEvent)
+
+	return err
+}
+
+// 辅助方法
+
+// withTx 在事务中执行fn，fn返回错误时回滚
+func (ps *PostgresStorage) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := ps.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// setTx 在事务中写入配置项，checkVersion 为true且 item.Version 大于0时做比较更新
+func (ps *PostgresStorage) setTx(ctx context.Context, tx *sql.Tx, item *storage.ConfigItem, checkVersion bool, reason string) error {
+	current, err := ps.lockItem(ctx, tx, item.Service, item.Environment, item.Key)
+	if err != nil {
+		return err
+	}
+
+	expected := item.Version
+	if checkVersion && expected > 0 {
+		var currentVersion int64
+		if current != nil {
+			currentVersion = current.Version
+		}
+		if currentVersion != expected {
+			return &storage.VersionConflictError{
+				Service:        item.Service,
+				Environment:    item.Environment,
+				Key:            item.Key,
+				CurrentVersion: currentVersion,
+				RequestVersion: expected,
+			}
+		}
+	}
+
+	value, err := json.Marshal(item.Value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config value: %w", err)
+	}
+	metadata, err := marshalMetadata(item.Metadata)
+	if err != nil {
+		return err
+	}
+	if item.Type == "" {
+		item.Type = "string"
+	}
+
+	now := time.Now()
+	operation := "update"
+	var oldValue []byte
+
+	if current == nil {
+		operation = "create"
+
+		// 删除后重新创建时延续历史版本号，保证版本单调递增
+		var lastVersion int64
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COALESCE(MAX(version), 0) FROM config_history WHERE service = $1 AND environment = $2 AND key = $3`,
+			item.Service, item.Environment, item.Key).Scan(&lastVersion); err != nil {
+			return fmt.Errorf("failed to get last config version: %w", err)
+		}
+
+		item.Version = lastVersion + 1
+		item.CreatedAt = now
+		item.UpdatedAt = now
+		if item.CreatedBy == "" {
+			item.CreatedBy = item.UpdatedBy
+		}
+
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO config_items (service, environment, key, value, type, version, description, metadata,
+				created_at, updated_at, created_by, updated_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (service, environment, key) DO NOTHING`,
+			item.Service, item.Environment, item.Key, value, item.Type, item.Version, item.Description, metadata,
+			item.CreatedAt, item.UpdatedAt, item.CreatedBy, item.UpdatedBy)
+		if err != nil {
+			return fmt.Errorf("failed to insert config: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			// 并发创建了同一配置
+			return &storage.VersionConflictError{
+				Service:        item.Service,
+				Environment:    item.Environment,
+				Key:            item.Key,
+				RequestVersion: expected,
+			}
+		}
+	} else {
+		oldValue, _ = json.Marshal(current.Value)
+
+		item.Version = current.Version + 1
+		item.CreatedAt = current.CreatedAt
+		item.CreatedBy = current.CreatedBy
+		item.UpdatedAt = now
+
+		result, err := tx.ExecContext(ctx,
+			`UPDATE config_items SET value = $4, type = $5, version = $6, description = $7, metadata = $8,
+				updated_at = $9, updated_by = $10
+			WHERE service = $1 AND environment = $2 AND key = $3 AND version = $11`,
+			item.Service, item.Environment, item.Key, value, item.Type, item.Version, item.Description, metadata,
+			item.UpdatedAt, item.UpdatedBy, current.Version)
+		if err != nil {
+			return fmt.Errorf("failed to update config: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return &storage.VersionConflictError{
+				Service:        item.Service,
+				Environment:    item.Environment,
+				Key:            item.Key,
+				CurrentVersion: current.Version,
+				RequestVersion: expected,
+			}
+		}
+	}
+
+	if err := ps.replaceTags(ctx, tx, item); err != nil {
+		return err
+	}
+
+	return ps.recordChange(ctx, tx, &storage.ConfigHistory{
+		Service:     item.Service,
+		Environment: item.Environment,
+		Key:         item.Key,
+		Version:     item.Version,
+		Operation:   operation,
+		CreatedAt:   now,
+		CreatedBy:   item.UpdatedBy,
+		Reason:      reason,
+	}, oldValue, value)
+}
+
+// deleteTx 在事务中删除配置项
+func (ps *PostgresStorage) deleteTx(ctx context.Context, tx *sql.Tx, key storage.ConfigKey, operator string) error {
+	current, err := ps.lockItem(ctx, tx, key.Service, key.Environment, key.Key)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return &storage.ConfigNotFoundError{Service: key.Service, Environment: key.Environment, Key: key.Key}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM config_items WHERE service = $1 AND environment = $2 AND key = $3`,
+		key.Service, key.Environment, key.Key); err != nil {
+		return fmt.Errorf("failed to delete config: %w", err)
+	}
+
+	oldValue, _ := json.Marshal(current.Value)
+	return ps.recordChange(ctx, tx, &storage.ConfigHistory{
+		Service:     key.Service,
+		Environment: key.Environment,
+		Key:         key.Key,
+		Version:     current.Version + 1,
+		Operation:   "delete",
+		CreatedAt:   time.Now(),
+		CreatedBy:   operator,
+	}, oldValue, nil)
+}
+
+// lockItem 锁定并读取当前配置项，不存在时返回nil
+func (ps *PostgresStorage) lockItem(ctx context.Context, tx *sql.Tx, service, environment, key string) (*storage.ConfigItem, error) {
+	row := tx.QueryRowContext(ctx,
+		`SELECT `+itemColumns+` FROM config_items i
+		WHERE i.service = $1 AND i.environment = $2 AND i.key = $3 FOR UPDATE OF i`,
+		service, environment, key)
+
+	item, err := scanItem(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock config: %w", err)
+	}
+	return item, nil
+}
+
+// replaceTags 替换配置项的标签
+func (ps *PostgresStorage) replaceTags(ctx context.Context, tx *sql.Tx, item *storage.ConfigItem) error {
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM config_tags WHERE service = $1 AND environment = $2 AND key = $3`,
+		item.Service, item.Environment, item.Key); err != nil {
+		return fmt.Errorf("failed to clear config tags: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, tag := range item.Tags {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO config_tags (service, environment, key, tag) VALUES ($1, $2, $3, $4)`,
+			item.Service, item.Environment, item.Key, tag); err != nil {
+			return fmt.Errorf("failed to save config tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordChange 写入历史记录并发送变更通知，通知在事务提交后才会送达
+func (ps *PostgresStorage) recordChange(ctx context.Context, tx *sql.Tx, h *storage.ConfigHistory, oldValue, newValue []byte) error {
+	var id int64
+	err := tx.QueryRowContext(ctx,
+		`INSERT INTO config_history (service, environment, key, old_value, new_value, version, operation,
+			created_at, created_by, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+		h.Service, h.Environment, h.Key, nullJSON(oldValue), nullJSON(newValue), h.Version, h.Operation,
+		h.CreatedAt, h.CreatedBy, h.Reason).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to save config history: %w", err)
+	}
+
+	// NOTIFY负载有大小限制，只发送历史记录ID，由监听方查询详情
+	payload, _ := json.Marshal(changeNotification{ID: id, Service: h.Service, Environment: h.Environment})
+	if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to notify config change: %w", err)
+	}
+	return nil
+}
+
+// historyVersion 查询指定版本的历史记录（不包括删除记录）
+func (ps *PostgresStorage) historyVersion(ctx context.Context, q queryer, service, environment, key string, version int64) (*storage.ConfigHistory, error) {
+	row := q.QueryRowContext(ctx,
+		`SELECT `+historyColumns+` FROM config_history
+		WHERE service = $1 AND environment = $2 AND key = $3 AND version = $4 AND operation <> 'delete'
+		ORDER BY id DESC LIMIT 1`,
+		service, environment, key, version)
+
+	h, err := scanHistory(row)
+	if err == sql.ErrNoRows {
+		return nil, &storage.ConfigNotFoundError{Service: service, Environment: environment, Key: key}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config version: %w", err)
+	}
+	return h, nil
+}
+
+// queryer 抽象 *sql.DB 与 *sql.Tx 的查询方法
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// scanner 抽象 *sql.Row 与 *sql.Rows 的Scan方法
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanItem(s scanner) (*storage.ConfigItem, error) {
+	var (
+		item     storage.ConfigItem
+		value    []byte
+		metadata []byte
+		tags     []string
+	)
+
+	err := s.Scan(&item.Service, &item.Environment, &item.Key, &value, &item.Type, &item.Version,
+		&item.Description, &metadata, &item.CreatedAt, &item.UpdatedAt, &item.CreatedBy, &item.UpdatedBy,
+		pq.Array(&tags))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(value, &item.Value); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config value: %w", err)
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &item.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config metadata: %w", err)
+		}
+	}
+	item.Tags = tags
+	sort.Strings(item.Tags)
+
+	return &item, nil
+}
+
+func scanItems(rows *sql.Rows) ([]*storage.ConfigItem, error) {
+	items := []*storage.ConfigItem{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan config: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func scanHistory(s scanner) (*storage.ConfigHistory, error) {
+	var (
+		h                  storage.ConfigHistory
+		oldValue, newValue []byte
+		metadata           []byte
+	)
+
+	err := s.Scan(&h.ID, &h.Service, &h.Environment, &h.Key, &oldValue, &newValue, &h.Version, &h.Operation,
+		&h.CreatedAt, &h.CreatedBy, &h.Reason, &metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(oldValue) > 0 {
+		json.Unmarshal(oldValue, &h.OldValue)
+	}
+	if len(newValue) > 0 {
+		json.Unmarshal(newValue, &h.NewValue)
+	}
+	if len(metadata) > 0 {
+		json.Unmarshal(metadata, &h.Metadata)
+	}
+
+	return &h, nil
+}
+
+func marshalMetadata(metadata map[string]interface{}) ([]byte, error) {
+	if metadata == nil {
+		return []byte("{}"), nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config metadata: %w", err)
+	}
+	return data, nil
+}
+
+// nullJSON 将空值转换为SQL NULL
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
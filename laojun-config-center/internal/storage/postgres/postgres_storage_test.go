@@ -0,0 +1,308 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// 需要可用的PostgreSQL，通过 CONFIG_CENTER_TEST_DSN 指定，例如
+// host=localhost port=5432 user=laojun password=laojun123 dbname=laojun_test sslmode=disable
+func newTestStorage(t *testing.T) *PostgresStorage {
+	t.Helper()
+
+	dsn := os.Getenv("CONFIG_CENTER_TEST_DSN")
+	if dsn == "" {
+		t.Skip("CONFIG_CENTER_TEST_DSN not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ps, err := NewPostgresStorage(db, dsn, nil)
+	if err != nil {
+		t.Fatalf("failed to create postgres storage: %v", err)
+	}
+	t.Cleanup(func() { ps.Close() })
+
+	for _, table := range []string{"config_tags", "config_items", "config_history"} {
+		if _, err := db.Exec("DELETE FROM " + table + " WHERE service = 'test-service'"); err != nil {
+			t.Fatalf("failed to clean %s: %v", table, err)
+		}
+	}
+	return ps
+}
+
+func TestPostgresStorageCompareAndSet(t *testing.T) {
+	ps := newTestStorage(t)
+	ctx := context.Background()
+
+	events, _ := ps.Watch(ctx, "test-service", "test")
+
+	item := &storage.ConfigItem{Service: "test-service", Environment: "test", Key: "db.host", Value: "10.0.0.1", Tags: []string{"db"}}
+	if err := ps.Set(ctx, item); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if item.Version != 1 {
+		t.Fatalf("Expected version 1, got %d", item.Version)
+	}
+
+	// 基于版本1更新成功
+	update := &storage.ConfigItem{Service: "test-service", Environment: "test", Key: "db.host", Value: "10.0.0.2", Version: 1}
+	if err := ps.Set(ctx, update); err != nil {
+		t.Fatalf("Set with current version failed: %v", err)
+	}
+
+	// 再次基于版本1更新应冲突
+	stale := &storage.ConfigItem{Service: "test-service", Environment: "test", Key: "db.host", Value: "10.0.0.3", Version: 1}
+	err := ps.Set(ctx, stale)
+	conflict, ok := err.(*storage.VersionConflictError)
+	if !ok {
+		t.Fatalf("Expected VersionConflictError, got %v", err)
+	}
+	if conflict.CurrentVersion != 2 || conflict.RequestVersion != 1 {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+
+	// 批量设置中任一冲突则整体回滚
+	err = ps.SetMultiple(ctx, []*storage.ConfigItem{
+		{Service: "test-service", Environment: "test", Key: "db.port", Value: float64(5432)},
+		{Service: "test-service", Environment: "test", Key: "db.host", Value: "10.0.0.4", Version: 1},
+	})
+	if _, ok := err.(*storage.VersionConflictError); !ok {
+		t.Fatalf("Expected VersionConflictError from SetMultiple, got %v", err)
+	}
+	if exists, _ := ps.Exists(ctx, "test-service", "test", "db.port"); exists {
+		t.Error("Expected SetMultiple to be rolled back")
+	}
+
+	// 写入冲突时同一批次的删除也回滚
+	err = ps.ApplyBatch(ctx, []*storage.ConfigItem{
+		{Service: "test-service", Environment: "test", Key: "db.host", Value: "10.0.0.4", Version: 1},
+	}, []storage.ConfigKey{{Service: "test-service", Environment: "test", Key: "db.host"}})
+	if _, ok := err.(*storage.VersionConflictError); !ok {
+		t.Fatalf("Expected VersionConflictError from ApplyBatch, got %v", err)
+	}
+	if exists, _ := ps.Exists(ctx, "test-service", "test", "db.host"); !exists {
+		t.Error("Expected ApplyBatch to be rolled back")
+	}
+
+	if err := ps.Rollback(ctx, "test-service", "test", "db.host", 1, "alice"); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	current, err := ps.Get(ctx, "test-service", "test", "db.host")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if current.Value != "10.0.0.1" || current.Version != 3 || len(current.Tags) != 1 {
+		t.Errorf("Unexpected item after rollback: %+v", current)
+	}
+
+	history, err := ps.GetHistory(ctx, "test-service", "test", "db.host", 10)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 3 || history[0].Reason != "rollback to version 1" {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != "create" || event.Key != "db.host" {
+			t.Errorf("Unexpected watch event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Expected watch event from NOTIFY")
+	}
+}
+
+// newFakeStorage 基于 fakeDB 创建存储，不建立LISTEN连接
+func newFakeStorage(t *testing.T) (*PostgresStorage, *fakeDB) {
+	t.Helper()
+
+	fake, db := newFakeDB(t)
+	ps := &PostgresStorage{
+		db:       db,
+		listener: &pq.Listener{Notify: make(chan *pq.Notification, 10)},
+		logger:   zap.NewNop(),
+		channels: make(map[string]chan *storage.WatchEvent),
+		stopCh:   make(chan struct{}),
+	}
+
+	// 写入路径上与断言无关的语句
+	fake.on("DELETE FROM config_tags", func(args []driver.Value) (*fakeResult, error) {
+		return &fakeResult{}, nil
+	})
+	fake.on("INSERT INTO config_tags", func(args []driver.Value) (*fakeResult, error) {
+		return &fakeResult{}, nil
+	})
+	fake.on("INSERT INTO config_history", func(args []driver.Value) (*fakeResult, error) {
+		return &fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(7)}}}, nil
+	})
+	fake.on("pg_notify", func(args []driver.Value) (*fakeResult, error) {
+		return &fakeResult{}, nil
+	})
+	return ps, fake
+}
+
+// itemRow 返回 itemColumns 对应的一行
+func itemRow(key string, value string, version int64) *fakeResult {
+	now := time.Now()
+	return &fakeResult{
+		columns: []string{"service", "environment", "key", "value", "type", "version", "description", "metadata",
+			"created_at", "updated_at", "created_by", "updated_by", "tags"},
+		rows: [][]driver.Value{{"test-service", "test", key, []byte(value), "string", version, "", []byte("{}"),
+			now, now, "alice", "alice", []byte("{db}")}},
+	}
+}
+
+func TestPostgresStorageCompareAndSetFakeDB(t *testing.T) {
+	ps, fake := newFakeStorage(t)
+	ctx := context.Background()
+
+	fake.on("FOR UPDATE OF i", func(args []driver.Value) (*fakeResult, error) {
+		return itemRow("db.host", `"10.0.0.2"`, 2), nil
+	})
+	updated := int64(1)
+	fake.on("UPDATE config_items", func(args []driver.Value) (*fakeResult, error) {
+		return &fakeResult{affected: updated}, nil
+	})
+
+	// 版本与当前版本不一致时不写入并回滚
+	stale := &storage.ConfigItem{Service: "test-service", Environment: "test", Key: "db.host", Value: "10.0.0.3", Version: 1}
+	err := ps.Set(ctx, stale)
+	conflict, ok := err.(*storage.VersionConflictError)
+	if !ok {
+		t.Fatalf("Expected VersionConflictError, got %v", err)
+	}
+	if conflict.CurrentVersion != 2 || conflict.RequestVersion != 1 {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+	if n := len(fake.executed("UPDATE config_items")); n != 0 {
+		t.Errorf("Expected no UPDATE on conflict, got %d", n)
+	}
+	if fake.rollbacks != 1 || fake.commits != 0 {
+		t.Errorf("Expected rollback, got %d commits and %d rollbacks", fake.commits, fake.rollbacks)
+	}
+
+	// 基于当前版本更新：UPDATE 以旧版本为条件，写入历史并在同一事务中发送通知
+	item := &storage.ConfigItem{Service: "test-service", Environment: "test", Key: "db.host", Value: "10.0.0.3", Version: 2}
+	if err := ps.Set(ctx, item); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if item.Version != 3 {
+		t.Errorf("Expected version 3, got %d", item.Version)
+	}
+	updates := fake.executed("UPDATE config_items")
+	if len(updates) != 1 || updates[0].args[5] != int64(3) || updates[0].args[10] != int64(2) {
+		t.Errorf("Unexpected UPDATE args: %+v", updates)
+	}
+	notifies := fake.executed("pg_notify")
+	if len(notifies) != 1 || notifies[0].args[0] != notifyChannel ||
+		notifies[0].args[1] != `{"id":7,"service":"test-service","environment":"test"}` {
+		t.Errorf("Unexpected NOTIFY: %+v", notifies)
+	}
+	if fake.commits != 1 {
+		t.Errorf("Expected commit, got %d", fake.commits)
+	}
+
+	// 并发写入使条件更新未命中时同样返回冲突
+	updated = 0
+	item = &storage.ConfigItem{Service: "test-service", Environment: "test", Key: "db.host", Value: "10.0.0.4", Version: 2}
+	if _, ok := ps.Set(ctx, item).(*storage.VersionConflictError); !ok {
+		t.Errorf("Expected VersionConflictError when UPDATE affects no rows")
+	}
+	if fake.rollbacks != 2 {
+		t.Errorf("Expected rollback, got %d", fake.rollbacks)
+	}
+}
+
+func TestPostgresStorageCreateConflictFakeDB(t *testing.T) {
+	ps, fake := newFakeStorage(t)
+	ctx := context.Background()
+
+	fake.on("FOR UPDATE OF i", func(args []driver.Value) (*fakeResult, error) {
+		return &fakeResult{columns: itemRow("", "", 0).columns}, nil
+	})
+	fake.on("COALESCE(MAX(version), 0)", func(args []driver.Value) (*fakeResult, error) {
+		return &fakeResult{columns: []string{"max"}, rows: [][]driver.Value{{int64(3)}}}, nil
+	})
+	inserted := int64(1)
+	fake.on("INSERT INTO config_items", func(args []driver.Value) (*fakeResult, error) {
+		return &fakeResult{affected: inserted}, nil
+	})
+
+	// 删除后重新创建延续历史版本号
+	item := &storage.ConfigItem{Service: "test-service", Environment: "test", Key: "db.port", Value: float64(5432)}
+	if err := ps.Set(ctx, item); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if item.Version != 4 {
+		t.Errorf("Expected version 4, got %d", item.Version)
+	}
+
+	// 并发创建同一配置时 ON CONFLICT DO NOTHING 未插入
+	inserted = 0
+	item = &storage.ConfigItem{Service: "test-service", Environment: "test", Key: "db.port", Value: float64(5433)}
+	if _, ok := ps.Set(ctx, item).(*storage.VersionConflictError); !ok {
+		t.Errorf("Expected VersionConflictError on concurrent create")
+	}
+}
+
+func TestPostgresStorageNotifyFakeDB(t *testing.T) {
+	ps, fake := newFakeStorage(t)
+
+	now := time.Now()
+	fake.on("FROM config_history WHERE id = $1", func(args []driver.Value) (*fakeResult, error) {
+		if args[0] != int64(7) {
+			return nil, fmt.Errorf("unexpected history id %v", args[0])
+		}
+		return &fakeResult{
+			columns: []string{"id", "service", "environment", "key", "old_value", "new_value", "version", "operation",
+				"created_at", "created_by", "reason", "metadata"},
+			rows: [][]driver.Value{{int64(7), "test-service", "test", "db.host", []byte(`"10.0.0.1"`), []byte(`"10.0.0.2"`),
+				int64(2), "update", now, "bob", "", nil}},
+		}, nil
+	})
+
+	events, _ := ps.Watch(context.Background(), "test-service", "test")
+
+	ps.wg.Add(1)
+	go ps.notifyLoop()
+	defer func() {
+		close(ps.stopCh)
+		ps.wg.Wait()
+	}()
+
+	// 重连信号、无效负载和未监听的服务都不产生事件
+	ps.listener.Notify <- nil
+	ps.listener.Notify <- &pq.Notification{Channel: notifyChannel, Extra: "not json"}
+	ps.listener.Notify <- &pq.Notification{Channel: notifyChannel, Extra: `{"id":8,"service":"other","environment":"test"}`}
+	ps.listener.Notify <- &pq.Notification{Channel: notifyChannel, Extra: `{"id":7,"service":"test-service","environment":"test"}`}
+
+	select {
+	case event := <-events:
+		if event.Type != "update" || event.Key != "db.host" || event.Version != 2 ||
+			event.OldValue != "10.0.0.1" || event.NewValue != "10.0.0.2" {
+			t.Errorf("Unexpected watch event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected watch event from notification")
+	}
+
+	if queries := fake.executed("FROM config_history WHERE id"); len(queries) != 1 {
+		t.Errorf("Expected only the watched notification to be loaded, got %d queries", len(queries))
+	}
+}
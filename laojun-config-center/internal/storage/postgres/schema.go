@@ -0,0 +1,53 @@
+package postgres
+
+// notifyChannel 配置变更通知使用的LISTEN/NOTIFY通道
+const notifyChannel = "config_changes"
+
+// schemaSQL 配置中心数据表结构，启动时幂等创建
+const schemaSQL = `
+CREATE TABLE IF NOT EXISTS config_items (
+	service     VARCHAR(128) NOT NULL,
+	environment VARCHAR(64)  NOT NULL,
+	key         VARCHAR(255) NOT NULL,
+	value       JSONB        NOT NULL,
+	type        VARCHAR(32)  NOT NULL DEFAULT 'string',
+	version     BIGINT       NOT NULL,
+	description TEXT         NOT NULL DEFAULT '',
+	metadata    JSONB        NOT NULL DEFAULT '{}',
+	created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+	updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+	created_by  VARCHAR(128) NOT NULL DEFAULT '',
+	updated_by  VARCHAR(128) NOT NULL DEFAULT '',
+	PRIMARY KEY (service, environment, key)
+);
+
+CREATE TABLE IF NOT EXISTS config_history (
+	id          BIGSERIAL    PRIMARY KEY,
+	service     VARCHAR(128) NOT NULL,
+	environment VARCHAR(64)  NOT NULL,
+	key         VARCHAR(255) NOT NULL,
+	old_value   JSONB,
+	new_value   JSONB,
+	version     BIGINT       NOT NULL,
+	operation   VARCHAR(16)  NOT NULL,
+	created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+	created_by  VARCHAR(128) NOT NULL DEFAULT '',
+	reason      TEXT         NOT NULL DEFAULT '',
+	metadata    JSONB        NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_history_key
+	ON config_history (service, environment, key, id DESC);
+
+CREATE TABLE IF NOT EXISTS config_tags (
+	service     VARCHAR(128) NOT NULL,
+	environment VARCHAR(64)  NOT NULL,
+	key         VARCHAR(255) NOT NULL,
+	tag         VARCHAR(128) NOT NULL,
+	PRIMARY KEY (service, environment, key, tag),
+	FOREIGN KEY (service, environment, key)
+		REFERENCES config_items (service, environment, key) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_tags_tag ON config_tags (tag);
+`
@@ -0,0 +1,187 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// historyScanLimit 按时间点还原配置时每个键最多查找的历史记录数
+const historyScanLimit = 1000
+
+// DiffStatus 差异状态
+type DiffStatus string
+
+const (
+	DiffAdded     DiffStatus = "added"     // 仅右侧存在
+	DiffRemoved   DiffStatus = "removed"   // 仅左侧存在
+	DiffChanged   DiffStatus = "changed"   // 两侧值或类型不同
+	DiffUnchanged DiffStatus = "unchanged" // 两侧相同
+)
+
+// Source 比较的一侧，At 为空表示当前值
+type Source struct {
+	Service     string     `json:"service"`
+	Environment string     `json:"environment"`
+	At          *time.Time `json:"at,omitempty"`
+}
+
+// KeyDiff 单个键的差异
+type KeyDiff struct {
+	Key          string      `json:"key"`
+	Status       DiffStatus  `json:"status"`
+	LeftValue    interface{} `json:"left_value,omitempty"`
+	RightValue   interface{} `json:"right_value,omitempty"`
+	LeftType     string      `json:"left_type,omitempty"`
+	RightType    string      `json:"right_type,omitempty"`
+	LeftVersion  int64       `json:"left_version,omitempty"`
+	RightVersion int64       `json:"right_version,omitempty"`
+}
+
+// DiffResult 差异结果
+type DiffResult struct {
+	Left      Source     `json:"left"`
+	Right     Source     `json:"right"`
+	Added     int        `json:"added"`
+	Removed   int        `json:"removed"`
+	Changed   int        `json:"changed"`
+	Unchanged int        `json:"unchanged"`
+	Diffs     []*KeyDiff `json:"diffs"`
+}
+
+// Diff 比较两侧配置
+//
+// 左右两侧可以是不同环境，也可以是同一环境的不同时间点。includeUnchanged 为 false 时结果中只包含有差异的键。
+func Diff(ctx context.Context, st storage.ConfigStorage, left, right Source, includeUnchanged bool) (*DiffResult, error) {
+	leftItems, err := Load(ctx, st, left)
+	if err != nil {
+		return nil, err
+	}
+	rightItems, err := Load(ctx, st, right)
+	if err != nil {
+		return nil, err
+	}
+
+	result := CompareItems(leftItems, rightItems, includeUnchanged)
+	result.Left = left
+	result.Right = right
+	return result, nil
+}
+
+// CompareItems 逐键比较两组配置项
+func CompareItems(left, right []*storage.ConfigItem, includeUnchanged bool) *DiffResult {
+	leftByKey := indexItems(left)
+	rightByKey := indexItems(right)
+
+	keys := make([]string, 0, len(leftByKey)+len(rightByKey))
+	for k := range leftByKey {
+		keys = append(keys, k)
+	}
+	for k := range rightByKey {
+		if _, exists := leftByKey[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	result := &DiffResult{Diffs: make([]*KeyDiff, 0, len(keys))}
+	for _, key := range keys {
+		l, r := leftByKey[key], rightByKey[key]
+		diff := &KeyDiff{Key: key}
+		if l != nil {
+			diff.LeftValue, diff.LeftType, diff.LeftVersion = l.Value, l.Type, l.Version
+		}
+		if r != nil {
+			diff.RightValue, diff.RightType, diff.RightVersion = r.Value, r.Type, r.Version
+		}
+
+		switch {
+		case l == nil:
+			diff.Status = DiffAdded
+			result.Added++
+		case r == nil:
+			diff.Status = DiffRemoved
+			result.Removed++
+		case l.Type != r.Type || !valuesEqual(l.Value, r.Value):
+			diff.Status = DiffChanged
+			result.Changed++
+		default:
+			diff.Status = DiffUnchanged
+			result.Unchanged++
+			if !includeUnchanged {
+				continue
+			}
+		}
+		result.Diffs = append(result.Diffs, diff)
+	}
+	return result
+}
+
+// Load 加载一侧的配置，指定时间点时根据历史记录还原
+//
+// 时间点还原以当前存在的键为准，之后被删除的键无法还原。
+func Load(ctx context.Context, st storage.ConfigStorage, source Source) ([]*storage.ConfigItem, error) {
+	items, err := st.List(ctx, source.Service, source.Environment)
+	if err != nil {
+		return nil, err
+	}
+	if source.At == nil {
+		return items, nil
+	}
+
+	at := *source.At
+	result := make([]*storage.ConfigItem, 0, len(items))
+	for _, item := range items {
+		if !item.UpdatedAt.After(at) {
+			result = append(result, item)
+			continue
+		}
+
+		history, err := st.GetHistory(ctx, item.Service, item.Environment, item.Key, historyScanLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		// 历史记录按时间倒序，取时间点之前的最后一次变更
+		for _, h := range history {
+			if h.CreatedAt.After(at) {
+				continue
+			}
+			if h.Operation != "delete" {
+				past := *item
+				past.Value = h.NewValue
+				past.Version = h.Version
+				past.UpdatedAt = h.CreatedAt
+				past.UpdatedBy = h.CreatedBy
+				result = append(result, &past)
+			}
+			break
+		}
+	}
+	return result, nil
+}
+
+func indexItems(items []*storage.ConfigItem) map[string]*storage.ConfigItem {
+	index := make(map[string]*storage.ConfigItem, len(items))
+	for _, item := range items {
+		index[item.Key] = item
+	}
+	return index
+}
+
+// valuesEqual 按JSON表示比较两个值，避免 int 与 float64 等表示差异
+func valuesEqual(a, b interface{}) bool {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aj, bj)
+}
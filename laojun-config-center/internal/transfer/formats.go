@@ -0,0 +1,491 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Format 导入导出格式
+type Format string
+
+const (
+	FormatJSON       Format = "json"
+	FormatYAML       Format = "yaml"
+	FormatEnv        Format = "env"
+	FormatProperties Format = "properties"
+	FormatTOML       Format = "toml"
+)
+
+// ParseFormat 解析格式名称
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "env", "dotenv":
+		return FormatEnv, nil
+	case "properties", "props":
+		return FormatProperties, nil
+	case "toml":
+		return FormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", name)
+	}
+}
+
+// DetectFormat 根据文件名推断格式，如 marketplace-api.prod.yaml、.env.prod
+func DetectFormat(filename string) (Format, error) {
+	base := filepath.Base(filename)
+	if base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env") {
+		return FormatEnv, nil
+	}
+	return ParseFormat(filepath.Ext(base))
+}
+
+// ContentType 返回格式对应的MIME类型
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json"
+	case FormatYAML:
+		return "application/x-yaml"
+	case FormatTOML:
+		return "application/toml"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Extension 返回格式对应的文件扩展名
+func (f Format) Extension() string {
+	if f == FormatYAML {
+		return "yaml"
+	}
+	return string(f)
+}
+
+// Decode 解析配置文件为扁平的键值对
+//
+// 嵌套结构按 "." 连接为键，如 database.host；.env 和 properties 的键原样保留，值均为字符串。
+func Decode(format Format, data []byte) (map[string]interface{}, error) {
+	switch format {
+	case FormatJSON:
+		// 保留整数与浮点数的区别
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to parse json: %w", err)
+		}
+		return flattenDocument(doc)
+	case FormatYAML:
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse yaml: %w", err)
+		}
+		return flattenDocument(doc)
+	case FormatTOML:
+		var doc map[string]interface{}
+		if err := toml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse toml: %w", err)
+		}
+		return flattenDocument(doc)
+	case FormatEnv:
+		env, err := godotenv.UnmarshalBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse env: %w", err)
+		}
+		values := make(map[string]interface{}, len(env))
+		for k, v := range env {
+			values[k] = v
+		}
+		return values, nil
+	case FormatProperties:
+		return decodeProperties(data)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// Encode 将扁平的键值对编码为配置文件
+//
+// JSON、YAML、TOML 按 "." 还原嵌套结构，与 Decode 互逆。
+func Encode(format Format, values map[string]interface{}) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(unflatten(values), "", "  ")
+	case FormatYAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(unflatten(values)); err != nil {
+			return nil, fmt.Errorf("failed to encode yaml: %w", err)
+		}
+		encoder.Close()
+		return buf.Bytes(), nil
+	case FormatTOML:
+		data, err := toml.Marshal(unflatten(values))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode toml: %w", err)
+		}
+		return data, nil
+	case FormatEnv:
+		return encodeEnv(values)
+	case FormatProperties:
+		return encodeProperties(values)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// flattenDocument 展开文档根节点，根节点必须是对象
+func flattenDocument(doc interface{}) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if doc == nil {
+		return values, nil
+	}
+
+	root, ok := toStringMap(doc)
+	if !ok {
+		return nil, fmt.Errorf("document root must be an object, got %T", doc)
+	}
+
+	for k, v := range root {
+		flatten(k, v, values)
+	}
+	return values, nil
+}
+
+func flatten(prefix string, value interface{}, values map[string]interface{}) {
+	if m, ok := toStringMap(value); ok && len(m) > 0 {
+		for k, v := range m {
+			flatten(prefix+"."+k, v, values)
+		}
+		return
+	}
+	values[prefix] = normalizeDecoded(value)
+}
+
+// toStringMap 将解析结果中的对象统一为 map[string]interface{}
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			result[fmt.Sprintf("%v", k)] = v
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// normalizeDecoded 将各解析器特有的值类型转换为JSON可表示的类型
+func normalizeDecoded(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case toml.LocalDate, toml.LocalTime, toml.LocalDateTime:
+		return fmt.Sprintf("%v", v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeDecoded(item)
+		}
+		return result
+	case map[string]interface{}, map[interface{}]interface{}:
+		m, _ := toStringMap(v)
+		result := make(map[string]interface{}, len(m))
+		for k, mv := range m {
+			result[k] = normalizeDecoded(mv)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// tree unflatten 生成的中间节点，与值本身是对象的叶子节点区分
+type tree map[string]interface{}
+
+// unflatten 按 "." 还原嵌套结构
+//
+// 同时存在 a 和 a.b 时无法嵌套，a.b 以原样的键保存在上层对象中，再次 Decode 仍得到 a.b。
+func unflatten(values map[string]interface{}) tree {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	// 短键优先，保证叶子节点先于子键写入
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	root := make(tree)
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		node := root
+		for len(parts) > 1 {
+			child, exists := node[parts[0]]
+			if !exists {
+				next := make(tree)
+				node[parts[0]] = next
+				node, parts = next, parts[1:]
+				continue
+			}
+			next, ok := child.(tree)
+			if !ok {
+				// 路径上已是叶子节点，剩余部分作为整体的键
+				parts = []string{strings.Join(parts, ".")}
+				break
+			}
+			node, parts = next, parts[1:]
+		}
+		node[parts[0]] = values[key]
+	}
+	return root
+}
+
+// formatScalar 将值格式化为 .env/properties 使用的字符串
+func formatScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+var envBareValue = regexp.MustCompile(`^[A-Za-z0-9_./:@+,-]*$`)
+
+// encodeEnv 编码为 .env，键原样输出
+func encodeEnv(values map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, key := range sortedKeys(values) {
+		value, err := formatScalar(values[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+
+		switch {
+		case envBareValue.MatchString(value):
+		case !strings.ContainsAny(value, "'\n\r"):
+			// 单引号内不做转义和变量展开
+			value = "'" + value + "'"
+		default:
+			value = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`).Replace(value) + `"`
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", key, value)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeProperties 解析Java properties文件
+func decodeProperties(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	var logical strings.Builder
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical.Len() == 0 && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		// 奇数个结尾反斜杠表示续行
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		if trailing%2 == 1 {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+
+		key, value, err := splitProperty(logical.String())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		values[key] = value
+		logical.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read properties: %w", err)
+	}
+	if logical.Len() > 0 {
+		key, value, err := splitProperty(logical.String())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// splitProperty 拆分一条逻辑行为键和值
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 >= len(s) {
+				return "", fmt.Errorf("malformed \\u escape")
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape: %w", err)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// encodeProperties 编码为Java properties文件（UTF-8）
+func encodeProperties(values map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, key := range sortedKeys(values) {
+		value, err := formatScalar(values[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", escapeProperty(key, true), escapeProperty(value, false))
+	}
+	return buf.Bytes(), nil
+}
+
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':':
+			if isKey {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		case ' ':
+			// 键中的空格和值开头的空格需要转义
+			if isKey || i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		case '#', '!':
+			if isKey && i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,292 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+)
+
+// PromoteRequest 跨环境发布请求
+type PromoteRequest struct {
+	Service           string   `json:"service"`
+	SourceEnvironment string   `json:"source_environment"`
+	TargetEnvironment string   `json:"target_environment"`
+	Keys              []string `json:"keys"` // 为空时发布所有新增和变更的键
+	Reason            string   `json:"reason"`
+	DryRun            bool     `json:"dry_run"`
+}
+
+// PromoteResult 跨环境发布结果，Changes 以目标环境为左侧、源环境为右侧
+type PromoteResult struct {
+	Service           string     `json:"service"`
+	SourceEnvironment string     `json:"source_environment"`
+	TargetEnvironment string     `json:"target_environment"`
+	DryRun            bool       `json:"dry_run"`
+	Changes           []*KeyDiff `json:"changes"`
+}
+
+// Promote 将源环境的配置复制到目标环境
+//
+// 所有键在一次批量写入中完成，git存储产生一次提交，数据库存储在同一事务中提交。
+// 目标环境已有的键按当前版本做比较更新，发布期间目标被修改时返回版本冲突。
+func Promote(ctx context.Context, st storage.ConfigStorage, req *PromoteRequest, operator string) (*PromoteResult, error) {
+	if req.Service == "" || req.SourceEnvironment == "" || req.TargetEnvironment == "" {
+		return nil, &storage.ValidationError{
+			Field:   "service/source_environment/target_environment",
+			Message: "service, source_environment and target_environment are required",
+		}
+	}
+	if req.SourceEnvironment == req.TargetEnvironment {
+		return nil, &storage.ValidationError{
+			Field:   "target_environment",
+			Message: "target environment must differ from source environment",
+		}
+	}
+
+	source, err := st.List(ctx, req.Service, req.SourceEnvironment)
+	if err != nil {
+		return nil, err
+	}
+	target, err := st.List(ctx, req.Service, req.TargetEnvironment)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceByKey := indexItems(source)
+	targetByKey := indexItems(target)
+
+	selected := make(map[string]bool, len(req.Keys))
+	for _, key := range req.Keys {
+		if _, exists := sourceByKey[key]; !exists {
+			return nil, &storage.ConfigNotFoundError{Service: req.Service, Environment: req.SourceEnvironment, Key: key}
+		}
+		selected[key] = true
+	}
+
+	result := &PromoteResult{
+		Service:           req.Service,
+		SourceEnvironment: req.SourceEnvironment,
+		TargetEnvironment: req.TargetEnvironment,
+		DryRun:            req.DryRun,
+		Changes:           []*KeyDiff{},
+	}
+
+	var items []*storage.ConfigItem
+	for _, diff := range CompareItems(target, source, false).Diffs {
+		if diff.Status == DiffRemoved {
+			// 仅目标环境存在的键不受发布影响
+			continue
+		}
+		if len(selected) > 0 && !selected[diff.Key] {
+			continue
+		}
+
+		src := sourceByKey[diff.Key]
+		item := &storage.ConfigItem{
+			Service:     req.Service,
+			Environment: req.TargetEnvironment,
+			Key:         src.Key,
+			Value:       src.Value,
+			Type:        src.Type,
+			Description: src.Description,
+			Tags:        src.Tags,
+			Metadata:    src.Metadata,
+			UpdatedBy:   operator,
+		}
+		if current, exists := targetByKey[diff.Key]; exists {
+			item.Version = current.Version
+		}
+
+		items = append(items, item)
+		result.Changes = append(result.Changes, diff)
+	}
+
+	if req.DryRun || len(items) == 0 {
+		return result, nil
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("promote from %s", req.SourceEnvironment)
+	}
+	ctx = storage.WithReason(storage.WithOperator(ctx, operator), reason)
+
+	if err := st.SetMultiple(ctx, items); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Prune  bool   // 删除文件中不存在的键
+	DryRun bool   // 只计算差异，不写入
+	Reason string // 变更原因
+}
+
+// ImportResult 导入结果，Changes 以当前配置为左侧、文件内容为右侧
+type ImportResult struct {
+	Service     string     `json:"service"`
+	Environment string     `json:"environment"`
+	Format      Format     `json:"format"`
+	DryRun      bool       `json:"dry_run"`
+	Changes     []*KeyDiff `json:"changes"`
+}
+
+// Import 将配置文件导入到服务/环境
+//
+// 新增、变更和删除的键在一次批量操作中完成，git存储产生一次提交，数据库存储在同一事务中提交。
+// 已有键保留描述、标签等元信息。
+// .env 和 properties 中的值都是字符串，已有键为数值或布尔类型时按原类型转换。
+func Import(ctx context.Context, st storage.ConfigStorage, service, environment string, format Format, data []byte, opts *ImportOptions, operator string) (*ImportResult, error) {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+
+	values, err := Decode(format, data)
+	if err != nil {
+		return nil, &storage.ValidationError{Field: "data", Message: err.Error()}
+	}
+
+	current, err := st.List(ctx, service, environment)
+	if err != nil {
+		return nil, err
+	}
+	currentByKey := indexItems(current)
+
+	stringly := format == FormatEnv || format == FormatProperties
+	incoming := make([]*storage.ConfigItem, 0, len(values))
+	for key, value := range values {
+		item := &storage.ConfigItem{
+			Service:     service,
+			Environment: environment,
+			Key:         key,
+			Value:       value,
+			Type:        inferType(value),
+			UpdatedBy:   operator,
+		}
+		if existing, exists := currentByKey[key]; exists {
+			if stringly {
+				if converted, ok := coerceString(value, existing.Type); ok {
+					item.Value, item.Type = converted, existing.Type
+				}
+			}
+			if valuesEqual(item.Value, existing.Value) {
+				item.Type = existing.Type
+			}
+			item.Description = existing.Description
+			item.Tags = existing.Tags
+			item.Metadata = existing.Metadata
+			item.Version = existing.Version
+		}
+		incoming = append(incoming, item)
+	}
+	incomingByKey := indexItems(incoming)
+
+	result := &ImportResult{
+		Service:     service,
+		Environment: environment,
+		Format:      format,
+		DryRun:      opts.DryRun,
+		Changes:     []*KeyDiff{},
+	}
+
+	var items []*storage.ConfigItem
+	var removed []storage.ConfigKey
+	for _, diff := range CompareItems(current, incoming, false).Diffs {
+		switch diff.Status {
+		case DiffRemoved:
+			if !opts.Prune {
+				continue
+			}
+			removed = append(removed, storage.ConfigKey{Service: service, Environment: environment, Key: diff.Key})
+		default:
+			items = append(items, incomingByKey[diff.Key])
+		}
+		result.Changes = append(result.Changes, diff)
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	reason := opts.Reason
+	if reason == "" {
+		reason = fmt.Sprintf("import from %s", format)
+	}
+	ctx = storage.WithReason(storage.WithOperator(ctx, operator), reason)
+
+	if err := st.ApplyBatch(ctx, items, removed); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Export 将服务/环境的配置导出为指定格式
+func Export(ctx context.Context, st storage.ConfigStorage, service, environment string, format Format) ([]byte, error) {
+	items, err := st.List(ctx, service, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		values[item.Key] = exportValue(item)
+	}
+	return Encode(format, values)
+}
+
+// inferType 根据值推断配置类型
+func inferType(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "bool"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "int"
+	case float32, float64:
+		return "float"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "string"
+	}
+}
+
+// coerceString 将字符串值转换为已有配置的类型
+func coerceString(value interface{}, typ string) (interface{}, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, false
+	}
+
+	switch typ {
+	case "int":
+		i, err := strconv.ParseInt(s, 10, 64)
+		return i, err == nil
+	case "float":
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	case "bool":
+		b, err := strconv.ParseBool(s)
+		return b, err == nil
+	case "string", "secret", "":
+		return s, true
+	default:
+		return nil, false
+	}
+}
+
+// exportValue 按配置类型还原存储中被JSON化的值，如 int 类型的 float64
+func exportValue(item *storage.ConfigItem) interface{} {
+	if item.Type == "int" {
+		if f, ok := item.Value.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return int64(f)
+		}
+	}
+	return item.Value
+}
@@ -0,0 +1,263 @@
+package transfer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/codetaoist/laojun-config-center/internal/storage"
+	"github.com/codetaoist/laojun-config-center/internal/storage/file"
+)
+
+const marketplaceYAML = `
+server:
+  port: 8080
+  read_timeout: 30s
+database:
+  host: db.internal
+  pool:
+    max_conns: 20
+    ratio: 0.75
+features:
+  - search
+  - reviews
+debug: false
+`
+
+func TestFormatsRoundTrip(t *testing.T) {
+	values, err := Decode(FormatYAML, []byte(marketplaceYAML))
+	if err != nil {
+		t.Fatalf("Decode yaml failed: %v", err)
+	}
+	if values["database.pool.max_conns"] != 20 || values["server.read_timeout"] != "30s" {
+		t.Fatalf("Unexpected flattened values: %v", values)
+	}
+
+	for _, format := range []Format{FormatJSON, FormatYAML, FormatTOML} {
+		data, err := Encode(format, values)
+		if err != nil {
+			t.Fatalf("Encode %s failed: %v", format, err)
+		}
+		decoded, err := Decode(format, data)
+		if err != nil {
+			t.Fatalf("Decode %s failed: %v\n%s", format, err, data)
+		}
+		if !valuesEqual(decoded, values) {
+			t.Errorf("%s round trip mismatch:\n got %v\nwant %v", format, decoded, values)
+		}
+	}
+
+	// .env 和 properties 只保留字符串
+	strs := map[string]interface{}{
+		"DATABASE_URL": "postgres://u:p@db/app?sslmode=disable",
+		"GREETING":     "it's a \"quoted\" $HOME\nsecond line",
+		"app.name":     "  padded = value: yes",
+		"key with#":    "中文",
+	}
+	for _, format := range []Format{FormatEnv, FormatProperties} {
+		if format == FormatEnv {
+			delete(strs, "key with#")
+		}
+		data, err := Encode(format, strs)
+		if err != nil {
+			t.Fatalf("Encode %s failed: %v", format, err)
+		}
+		decoded, err := Decode(format, data)
+		if err != nil {
+			t.Fatalf("Decode %s failed: %v\n%s", format, err, data)
+		}
+		if !reflect.DeepEqual(decoded, strs) {
+			t.Errorf("%s round trip mismatch:\n got %q\nwant %q\n%s", format, decoded, strs, data)
+		}
+	}
+}
+
+func TestDecodeProperties(t *testing.T) {
+	data := []byte(`# comment
+! another comment
+server.port = 8080
+server.name:marketplace
+message = hello \
+    world
+path=C:\\data\\app
+unicode=\u4e2d\u6587
+`)
+	values, err := Decode(FormatProperties, data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"server.port": "8080",
+		"server.name": "marketplace",
+		"message":     "hello world",
+		"path":        `C:\data\app`,
+		"unicode":     "中文",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Unexpected values: %q", values)
+	}
+}
+
+func TestUnflattenConflict(t *testing.T) {
+	values := map[string]interface{}{"a": "leaf", "a.b": "child", "x.y": 1}
+	data, err := Encode(FormatYAML, values)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	decoded, err := Decode(FormatYAML, data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !valuesEqual(decoded, values) {
+		t.Errorf("Expected %v, got %v", values, decoded)
+	}
+}
+
+func newTestStorage(t *testing.T) storage.ConfigStorage {
+	t.Helper()
+
+	fs, err := file.NewFileStorage(t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+	t.Cleanup(func() { fs.Close() })
+	return fs
+}
+
+func setItem(t *testing.T, st storage.ConfigStorage, env, key string, value interface{}, typ string) {
+	t.Helper()
+
+	item := &storage.ConfigItem{Service: "marketplace-api", Environment: env, Key: key, Value: value, Type: typ}
+	if err := st.Set(context.Background(), item); err != nil {
+		t.Fatalf("Set %s/%s failed: %v", env, key, err)
+	}
+}
+
+func TestDiffAndPromote(t *testing.T) {
+	st := newTestStorage(t)
+	ctx := context.Background()
+
+	setItem(t, st, "staging", "db.host", "staging-db", "string")
+	setItem(t, st, "staging", "pool.size", 20, "int")
+	setItem(t, st, "staging", "feature.search", true, "bool")
+	setItem(t, st, "prod", "db.host", "prod-db", "string")
+	setItem(t, st, "prod", "pool.size", 20, "int")
+	setItem(t, st, "prod", "prod.only", "x", "string")
+
+	diff, err := Diff(ctx, st,
+		Source{Service: "marketplace-api", Environment: "prod"},
+		Source{Service: "marketplace-api", Environment: "staging"}, false)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if diff.Added != 1 || diff.Removed != 1 || diff.Changed != 1 || diff.Unchanged != 1 || len(diff.Diffs) != 3 {
+		t.Errorf("Unexpected diff summary: %+v", diff)
+	}
+
+	req := &PromoteRequest{
+		Service:           "marketplace-api",
+		SourceEnvironment: "staging",
+		TargetEnvironment: "prod",
+		DryRun:            true,
+	}
+	result, err := Promote(ctx, st, req, "alice")
+	if err != nil {
+		t.Fatalf("Promote dry run failed: %v", err)
+	}
+	if len(result.Changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", result.Changes)
+	}
+	if item, _ := st.Get(ctx, "marketplace-api", "prod", "db.host"); item.Value != "prod-db" {
+		t.Fatal("Dry run must not write")
+	}
+
+	req.DryRun = false
+	req.Keys = []string{"db.host"}
+	if _, err := Promote(ctx, st, req, "alice"); err != nil {
+		t.Fatalf("Promote failed: %v", err)
+	}
+
+	item, err := st.Get(ctx, "marketplace-api", "prod", "db.host")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if item.Value != "staging-db" || item.Version != 2 || item.UpdatedBy != "alice" {
+		t.Errorf("Unexpected promoted item: %+v", item)
+	}
+	if exists, _ := st.Exists(ctx, "marketplace-api", "prod", "feature.search"); exists {
+		t.Error("Unselected key must not be promoted")
+	}
+
+	req.Keys = []string{"missing"}
+	if _, err := Promote(ctx, st, req, "alice"); err == nil {
+		t.Error("Expected error for missing source key")
+	}
+}
+
+func TestImportExport(t *testing.T) {
+	st := newTestStorage(t)
+	ctx := context.Background()
+
+	result, err := Import(ctx, st, "marketplace-api", "prod", FormatYAML, []byte(marketplaceYAML), nil, "bob")
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(result.Changes) != 7 {
+		t.Fatalf("Expected 7 changes, got %d", len(result.Changes))
+	}
+
+	item, err := st.Get(ctx, "marketplace-api", "prod", "database.pool.max_conns")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if item.Type != "int" {
+		t.Errorf("Expected int type, got %s", item.Type)
+	}
+
+	// 重新导入同一文件没有变更
+	result, err = Import(ctx, st, "marketplace-api", "prod", FormatYAML, []byte(marketplaceYAML), nil, "bob")
+	if err != nil {
+		t.Fatalf("Reimport failed: %v", err)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("Expected no changes on reimport, got %+v", result.Changes)
+	}
+
+	// 导出为 .env 再导入，数值键保持原类型
+	data, err := Export(ctx, st, "marketplace-api", "prod", FormatEnv)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	result, err = Import(ctx, st, "marketplace-api", "prod", FormatEnv, data, nil, "bob")
+	if err != nil {
+		t.Fatalf("Import env failed: %v", err)
+	}
+	for _, change := range result.Changes {
+		if change.Key != "features" {
+			t.Errorf("Unexpected change after env round trip: %+v", change)
+		}
+	}
+
+	exported, err := Export(ctx, st, "marketplace-api", "prod", FormatYAML)
+	if err != nil {
+		t.Fatalf("Export yaml failed: %v", err)
+	}
+	values, err := Decode(FormatYAML, exported)
+	if err != nil {
+		t.Fatalf("Decode export failed: %v", err)
+	}
+	if values["server.port"] != 8080 {
+		t.Errorf("Expected integer port after export, got %#v", values["server.port"])
+	}
+
+	// prune 删除文件中不存在的键
+	result, err = Import(ctx, st, "marketplace-api", "prod", FormatProperties, []byte("debug=true\n"), &ImportOptions{Prune: true}, "bob")
+	if err != nil {
+		t.Fatalf("Import with prune failed: %v", err)
+	}
+	items, _ := st.List(ctx, "marketplace-api", "prod")
+	if len(items) != 1 || items[0].Value != true {
+		t.Errorf("Expected only debug=true to remain, got %+v", items)
+	}
+}
@@ -106,6 +106,7 @@ func main() {
 		api.POST("/services", registryHandler.RegisterService)
 		api.DELETE("/services/:id", registryHandler.DeregisterService)
 		api.PUT("/services/:id/health", registryHandler.UpdateHealth)
+		api.PUT("/services/:id/heartbeat", registryHandler.Heartbeat)
 		api.GET("/services", registryHandler.ListServices)
 		api.GET("/services/:name", registryHandler.GetService)
 
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/codetaoist/laojun-discovery/internal/registry"
@@ -369,6 +370,52 @@ func (h *RegistryHandler) UpdateHealth(c *gin.Context) {
 	})
 }
 
+// Heartbeat 服务心跳，刷新TTL；服务不存在或已过期时返回404，客户端应重新注册
+func (h *RegistryHandler) Heartbeat(c *gin.Context) {
+	serviceID := c.Param("id")
+	if serviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Service ID is required",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	instance, err := h.registry.GetService(ctx, serviceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Service not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.registry.RefreshTTL(ctx, serviceID, instance.TTL); err != nil {
+		// 缓存中的实例可能已被过期清理
+		if strings.Contains(err.Error(), "service not found") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Service not found",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		h.logger.Error("Failed to refresh service TTL",
+			zap.String("service_id", serviceID),
+			zap.Error(err))
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to refresh TTL",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Heartbeat received",
+	})
+}
+
 // ListServices 列出服务
 func (h *RegistryHandler) ListServices(c *gin.Context) {
 	serviceName := c.Query("name")
@@ -115,6 +115,7 @@ type ChangeEvent struct {
 
 // RegisterRequest 注册请求
 type RegisterRequest struct {
+	ID      string            `json:"id"` // 可选，为空时自动生成；相同ID重复注册视为更新
 	Name    string            `json:"name" binding:"required"`
 	Address string            `json:"address" binding:"required"`
 	Port    int               `json:"port" binding:"required"`
@@ -148,7 +149,10 @@ func (r *ServiceRegistry) Register(ctx context.Context, req *RegisterRequest) (*
 	defer r.mu.Unlock()
 
 	// 生成服务ID
-	serviceID := uuid.New().String()
+	serviceID := req.ID
+	if serviceID == "" {
+		serviceID = uuid.New().String()
+	}
 
 	// 设置默认TTL
 	if req.TTL <= 0 {
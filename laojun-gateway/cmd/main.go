@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	sharedconfig "github.com/codetaoist/laojun-shared/config"
+	"github.com/codetaoist/laojun-shared/tracing"
 	"github.com/joho/godotenv"
 )
 
@@ -58,6 +59,13 @@ func main() {
 		zap.String("port", cfg.Server.Port),
 		zap.String("mode", cfg.Server.Mode))
 
+	// 配置追踪上下文传播格式，代理与共享 HTTP 客户端均使用全局传播器
+	propagator, err := tracing.NewPropagator(cfg.Tracing.Propagation)
+	if err != nil {
+		logger.Fatal("Invalid tracing propagation config", zap.Error(err))
+	}
+	tracing.SetGlobalPropagator(propagator)
+
 	// 初始化服务管理器
 	serviceManager, err := services.NewServiceManager(cfg, logger)
 	if err != nil {
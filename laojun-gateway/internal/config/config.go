@@ -17,6 +17,7 @@ type Config struct {
 	RateLimit     RateLimitConfig             `mapstructure:"ratelimit"`
 	Proxy         ProxyConfig                 `mapstructure:"proxy"`
 	Monitoring    MonitoringConfig            `mapstructure:"monitoring"`
+	Tracing       TracingConfig               `mapstructure:"tracing"`
 	ConfigManager sharedconfig.ConfigManager `json:"-" mapstructure:"-"`
 }
 
@@ -125,6 +126,13 @@ type MonitoringConfig struct {
 	HealthPath  string `mapstructure:"health_path"`
 }
 
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	// Propagation 追踪上下文传播格式: tracecontext, baggage, b3, b3multi, jaeger, ottrace
+	Propagation   []string `mapstructure:"propagation"`
+	ExposeTraceID bool     `mapstructure:"expose_trace_id"`
+}
+
 // Load 加载配置
 func Load() (*Config, error) {
 	// 设置配置文件名和搜索路径
@@ -218,4 +226,8 @@ func setDefaults() {
 	viper.SetDefault("monitoring.enabled", true)
 	viper.SetDefault("monitoring.metrics_path", "/metrics")
 	viper.SetDefault("monitoring.health_path", "/health")
+
+	// 链路追踪默认配置：网关同时识别 Zipkin 与 Jaeger 上游
+	viper.SetDefault("tracing.propagation", []string{"tracecontext", "baggage", "b3multi", "jaeger"})
+	viper.SetDefault("tracing.expose_trace_id", false)
 }
\ No newline at end of file
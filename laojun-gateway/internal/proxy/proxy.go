@@ -10,6 +10,7 @@ import (
 
 	"github.com/codetaoist/laojun-gateway/internal/config"
 	"github.com/codetaoist/laojun-gateway/internal/services/discovery"
+	"github.com/codetaoist/laojun-shared/tracing"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -27,6 +28,8 @@ type Service struct {
 func NewService(cfg config.ProxyConfig, discoveryService discovery.Service, logger *zap.Logger) *Service {
 	client := &http.Client{
 		Timeout: time.Duration(cfg.Timeout) * time.Second,
+		// 向后端注入追踪上下文
+		Transport: tracing.NewTransport(nil, nil),
 	}
 
 	var balancer LoadBalancer
@@ -6,6 +6,7 @@ import (
 	"github.com/codetaoist/laojun-gateway/internal/middleware"
 	"github.com/codetaoist/laojun-gateway/internal/proxy"
 	"github.com/codetaoist/laojun-gateway/internal/services"
+	sharedmiddleware "github.com/codetaoist/laojun-shared/middleware"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -18,6 +19,10 @@ func SetupRoutes(cfg *config.Config, serviceManager *services.ServiceManager, lo
 	router.Use(gin.Recovery())
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.RequestIDMiddleware())
+	router.Use(sharedmiddleware.TracingMiddleware(sharedmiddleware.TracingConfig{
+		SkipPaths:     []string{cfg.Monitoring.HealthPath, cfg.Monitoring.MetricsPath},
+		ExposeTraceID: cfg.Tracing.ExposeTraceID,
+	}))
 	router.Use(middleware.MonitoringMiddleware(logger))
 
 	// 初始化增强中间件
@@ -21,6 +21,7 @@ import (
 	"github.com/codetaoist/laojun-marketplace-api/internal/discovery"
 	"github.com/codetaoist/laojun-marketplace-api/internal/config"
 	sharedconfig "github.com/codetaoist/laojun-shared/config"
+	shareddb "github.com/codetaoist/laojun-shared/database"
 	unifiedconfig "github.com/codetaoist/laojun-shared/config"
 )
 
@@ -69,6 +70,11 @@ func main() {
 		}
 	}
 
+	// migrate 子命令：执行数据库迁移后退出
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(shareddb.MigrateMain(&cfg.Database, os.Getenv("MIGRATIONS_DIR"), os.Args[2:]))
+	}
+
 	// 配置 Gin 日志输出到文件或双写
 	logFile := cfg.Log.File
 	if logFile == "" {
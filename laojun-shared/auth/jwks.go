@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrUnknownKeyID = errors.New("unknown key id")
+
+// minKeySetRefresh 遇到未知 kid 时两次强制刷新的最小间隔，防止伪造 kid 放大请求
+const minKeySetRefresh = 30 * time.Second
+
+// verificationKey 验证公钥
+type verificationKey struct {
+	algorithm string
+	public    crypto.PublicKey
+}
+
+// KeySet 验证令牌用的公钥集合，来源为本地 JWKS 文件或 JWKS URL。
+// 超过刷新间隔或遇到未知 kid 时重新加载；加载失败时继续使用上次的公钥。
+type KeySet struct {
+	fetch    func(ctx context.Context) ([]byte, error)
+	interval time.Duration
+
+	group     singleflight.Group
+	mu        sync.RWMutex
+	keys      map[string]verificationKey
+	fetchedAt time.Time
+}
+
+// NewStaticKeySet 创建固定的公钥集合
+func NewStaticKeySet(jwks JWKS) (*KeySet, error) {
+	keys, err := parseJWKS(jwks)
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{keys: keys, fetchedAt: time.Now()}, nil
+}
+
+// NewFileKeySet 从本地 JWKS 文件加载公钥，interval 为 0 时不自动重新加载
+func NewFileKeySet(path string, interval time.Duration) *KeySet {
+	return &KeySet{
+		interval: interval,
+		fetch: func(ctx context.Context) ([]byte, error) {
+			return os.ReadFile(path)
+		},
+	}
+}
+
+// NewURLKeySet 从 JWKS URL 加载公钥，client 为 nil 时使用 10 秒超时的客户端
+func NewURLKeySet(url string, interval time.Duration, client *http.Client) *KeySet {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &KeySet{
+		interval: interval,
+		fetch: func(ctx context.Context) ([]byte, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Accept", "application/json")
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+			}
+			return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		},
+	}
+}
+
+// Refresh 重新加载公钥，并发调用合并为一次加载
+func (s *KeySet) Refresh(ctx context.Context) error {
+	if s.fetch == nil {
+		return nil
+	}
+	_, err, _ := s.group.Do("refresh", func() (interface{}, error) {
+		return nil, s.refresh(ctx)
+	})
+	return err
+}
+
+func (s *KeySet) refresh(ctx context.Context) error {
+	data, err := s.fetch(ctx)
+	var keys map[string]verificationKey
+	if err == nil {
+		var jwks JWKS
+		if err = json.Unmarshal(data, &jwks); err == nil {
+			keys, err = parseJWKS(jwks)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetchedAt = time.Now()
+	if err != nil {
+		return fmt.Errorf("failed to load JWKS: %w", err)
+	}
+	s.keys = keys
+	return nil
+}
+
+// lookup 按 kid 查找公钥
+func (s *KeySet) lookup(ctx context.Context, kid string) (verificationKey, error) {
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	age := time.Since(s.fetchedAt)
+	loaded := !s.fetchedAt.IsZero()
+	s.mu.RUnlock()
+
+	stale := !loaded || (s.interval > 0 && age > s.interval)
+	if ok && !stale {
+		return key, nil
+	}
+	if stale || age > minKeySetRefresh {
+		if err := s.Refresh(ctx); err != nil && !ok {
+			return key, err
+		}
+		s.mu.RLock()
+		key, ok = s.keys[kid]
+		s.mu.RUnlock()
+	}
+	if !ok {
+		return key, fmt.Errorf("%w: %q", ErrUnknownKeyID, kid)
+	}
+	return key, nil
+}
+
+// algorithms 返回集合中出现的签名算法
+func (s *KeySet) algorithms() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[string]bool)
+	var algorithms []string
+	for _, key := range s.keys {
+		if !seen[key.algorithm] {
+			seen[key.algorithm] = true
+			algorithms = append(algorithms, key.algorithm)
+		}
+	}
+	return algorithms
+}
+
+func parseJWKS(jwks JWKS) (map[string]verificationKey, error) {
+	keys := make(map[string]verificationKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		public, err := jwk.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		algorithm := jwk.Alg
+		if algorithm == "" {
+			algorithm = algorithmForKey(public)
+		}
+		if !keyMatchesAlgorithm(algorithm, public) {
+			return nil, fmt.Errorf("%w: key %q cannot verify %s", ErrUnsupportedKey, jwk.Kid, algorithm)
+		}
+		kid := jwk.Kid
+		if kid == "" {
+			if kid, err = jwk.Thumbprint(); err != nil {
+				return nil, err
+			}
+		}
+		keys[kid] = verificationKey{algorithm: algorithm, public: public}
+	}
+	return keys, nil
+}
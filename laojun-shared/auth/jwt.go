@@ -1,7 +1,11 @@
 package auth
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/codetaoist/laojun-shared/config"
@@ -11,8 +15,12 @@ import (
 )
 
 var (
-	ErrInvalidToken = errors.New("invalid token")
-	ErrExpiredToken = errors.New("token has expired")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrExpiredToken         = errors.New("token has expired")
+	ErrRevokedToken         = errors.New("token has been revoked")
+	ErrTokenNotRefreshable  = errors.New("token is not eligible for refresh")
+	ErrNoSigningKey         = errors.New("no signing key configured")
+	ErrRefreshNotConfigured = errors.New("refresh token store not configured")
 )
 
 // Claims JWT声明
@@ -21,41 +29,144 @@ type Claims struct {
 	Username string    `json:"username"`
 	Email    string    `json:"email"`
 	IsAdmin  bool      `json:"is_admin"`
+	// SessionID 登录会话（刷新令牌族）ID，吊销会话时同时使其访问令牌失效
+	SessionID string `json:"sid,omitempty"`
+	// AuthTime 用户最初登录的时间，刷新时保持不变
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenPair 访问令牌和刷新令牌
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
 // JWTManager JWT管理工具
 type JWTManager struct {
-	config *config.JWTConfig
+	config        *config.JWTConfig
+	algorithm     string
+	signingKey    *SigningKey
+	keySet        *KeySet
+	revocations   RevocationStore
+	refreshTokens RefreshStore
+	err           error
 }
 
-// NewJWTManager 创建JWT管理工具
+// NewJWTManager 创建JWT管理工具，配置错误在生成或验证令牌时返回
 func NewJWTManager(cfg *config.JWTConfig) *JWTManager {
-	return &JWTManager{
-		config: cfg,
+	manager, err := LoadJWTManager(cfg)
+	if err != nil {
+		return &JWTManager{config: cfg, err: err}
 	}
+	return manager
+}
+
+// LoadJWTManager 根据配置创建JWT管理工具：HS256 使用 Secret 签名，
+// 非对称算法从 PrivateKeyFile 加载私钥，JWKSFile/JWKSURL 提供额外的验证公钥
+func LoadJWTManager(cfg *config.JWTConfig) (*JWTManager, error) {
+	j := &JWTManager{config: cfg, algorithm: cfg.Algorithm}
+	if j.algorithm == "" {
+		j.algorithm = AlgorithmHS256
+	}
+	if signingMethod(j.algorithm) == nil {
+		return nil, fmt.Errorf("%w: algorithm %q", ErrUnsupportedKey, j.algorithm)
+	}
+
+	switch {
+	case cfg.JWKSFile != "":
+		j.keySet = NewFileKeySet(cfg.JWKSFile, cfg.JWKSRefresh)
+	case cfg.JWKSURL != "":
+		j.keySet = NewURLKeySet(cfg.JWKSURL, cfg.JWKSRefresh, nil)
+	}
+
+	if j.algorithm != AlgorithmHS256 {
+		if cfg.PrivateKeyFile != "" {
+			key, err := LoadSigningKey(j.algorithm, cfg.KeyID, cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			j.signingKey = key
+		} else if j.keySet == nil {
+			return nil, fmt.Errorf("%w: %s requires private_key_file or a JWKS", ErrNoSigningKey, j.algorithm)
+		}
+	}
+	return j, nil
+}
+
+// SetSigningKey 设置非对称签名密钥，需在使用前调用
+func (j *JWTManager) SetSigningKey(key *SigningKey) {
+	j.signingKey = key
+	j.algorithm = key.Algorithm
+	j.err = nil
+}
+
+// SetKeySet 设置验证公钥集合，需在使用前调用
+func (j *JWTManager) SetKeySet(keySet *KeySet) {
+	j.keySet = keySet
+}
+
+// SetRevocationStore 设置吊销存储，需在使用前调用
+func (j *JWTManager) SetRevocationStore(store RevocationStore) {
+	j.revocations = store
+}
+
+// SetRefreshStore 设置刷新令牌存储，需在使用前调用
+func (j *JWTManager) SetRefreshStore(store RefreshStore) {
+	j.refreshTokens = store
 }
 
 // GenerateToken 生成JWT令牌
 func (j *JWTManager) GenerateToken(user *models.User, isAdmin bool) (string, time.Time, error) {
-	expiresAt := time.Now().Add(j.config.Expiration)
-
-	claims := &Claims{
+	now := time.Now()
+	return j.generateToken(&Claims{
 		UserID:   user.ID,
 		Username: user.Username,
 		Email:    user.Email,
 		IsAdmin:  isAdmin,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    j.config.Issuer,
-			Subject:   user.ID.String(),
-		},
+		AuthTime: jwt.NewNumericDate(now),
+	})
+}
+
+// generateToken 补全注册声明并签名
+func (j *JWTManager) generateToken(claims *Claims) (string, time.Time, error) {
+	if j.err != nil {
+		return "", time.Time{}, j.err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(j.config.Expiration)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    j.config.Issuer,
+		Subject:   claims.UserID.String(),
+		Audience:  j.config.Audience,
+	}
+
+	var token *jwt.Token
+	var key interface{}
+	switch {
+	case j.algorithm == AlgorithmHS256:
+		token = jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		if j.config.KeyID != "" {
+			token.Header["kid"] = j.config.KeyID
+		}
+		key = []byte(j.config.Secret)
+	case j.signingKey != nil:
+		token = jwt.NewWithClaims(signingMethod(j.signingKey.Algorithm), claims)
+		token.Header["kid"] = j.signingKey.ID
+		key = j.signingKey.Private
+	default:
+		return "", time.Time{}, ErrNoSigningKey
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.config.Secret))
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", time.Time{}, err
 	}
@@ -65,12 +176,20 @@ func (j *JWTManager) GenerateToken(user *models.User, isAdmin bool) (string, tim
 
 // ValidateToken 验证JWT令牌
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return []byte(j.config.Secret), nil
-	})
+	return j.ValidateTokenContext(context.Background(), tokenString)
+}
+
+// ValidateTokenContext 验证JWT令牌的签名、有效期、签发者和受众，并检查吊销状态
+func (j *JWTManager) ValidateTokenContext(ctx context.Context, tokenString string) (*Claims, error) {
+	if j.err != nil {
+		return nil, j.err
+	}
+
+	options := []jwt.ParserOption{jwt.WithIssuedAt()}
+	if j.config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(j.config.Issuer))
+	}
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.keyFunc(ctx), options...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -80,33 +199,258 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	}
 
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || !j.audienceAllowed(claims.Audience) {
 		return nil, ErrInvalidToken
 	}
 
+	if j.revocations != nil {
+		revoked, err := j.revocations.IsRevoked(ctx, revocationIDs(claims)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check token revocation: %w", err)
+		}
+		if revoked {
+			return nil, ErrRevokedToken
+		}
+	}
+
 	return claims, nil
 }
 
-// RefreshToken 刷新令牌
+// keyFunc 按令牌头部的 alg 和 kid 选择验证密钥，密钥只能验证其自身的算法
+func (j *JWTManager) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		alg := token.Method.Alg()
+		kid, _ := token.Header["kid"].(string)
+
+		if alg == AlgorithmHS256 {
+			if j.algorithm != AlgorithmHS256 {
+				return nil, ErrInvalidToken
+			}
+			return []byte(j.config.Secret), nil
+		}
+
+		if j.signingKey != nil && kid == j.signingKey.ID {
+			if alg != j.signingKey.Algorithm {
+				return nil, ErrInvalidToken
+			}
+			return j.signingKey.Public(), nil
+		}
+		if j.keySet != nil && kid != "" {
+			key, err := j.keySet.lookup(ctx, kid)
+			if err != nil {
+				return nil, err
+			}
+			if alg != key.algorithm {
+				return nil, ErrInvalidToken
+			}
+			return key.public, nil
+		}
+		return nil, ErrUnknownKeyID
+	}
+}
+
+// audienceAllowed 配置了受众时，令牌须包含其中之一
+func (j *JWTManager) audienceAllowed(audience jwt.ClaimStrings) bool {
+	if len(j.config.Audience) == 0 {
+		return true
+	}
+	for _, expected := range j.config.Audience {
+		for _, actual := range audience {
+			if actual == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func revocationIDs(claims *Claims) []string {
+	var ids []string
+	if claims.ID != "" {
+		ids = append(ids, "jti:"+claims.ID)
+	}
+	if claims.SessionID != "" {
+		ids = append(ids, "sid:"+claims.SessionID)
+	}
+	return ids
+}
+
+// RevokeToken 吊销访问令牌直到其过期
+func (j *JWTManager) RevokeToken(ctx context.Context, claims *Claims) error {
+	if j.revocations == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	return j.revocations.Revoke(ctx, "jti:"+claims.ID, claims.ExpiresAt.Time)
+}
+
+// RevokeSession 吊销登录会话：删除其所有刷新令牌，并使已签发的访问令牌失效
+func (j *JWTManager) RevokeSession(ctx context.Context, sessionID string) error {
+	if j.refreshTokens != nil {
+		if err := j.refreshTokens.RevokeFamily(ctx, sessionID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+	}
+	if j.revocations != nil {
+		until := time.Now().Add(j.config.Expiration)
+		if err := j.revocations.Revoke(ctx, "sid:"+sessionID, until); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	return nil
+}
+
+// GenerateTokenPair 登录时签发访问令牌和刷新令牌，开始新的会话
+func (j *JWTManager) GenerateTokenPair(ctx context.Context, user *models.User, isAdmin bool) (*TokenPair, error) {
+	return j.issueTokenPair(ctx, RefreshSession{
+		FamilyID: uuid.NewString(),
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		IsAdmin:  isAdmin,
+		AuthTime: time.Now(),
+	})
+}
+
+// RotateRefreshToken 用刷新令牌换取新的令牌对，旧刷新令牌随即失效。
+// 已使用过的刷新令牌再次出现说明令牌可能泄露，此时吊销整个会话并返回 ErrRefreshTokenReused。
+func (j *JWTManager) RotateRefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	if j.refreshTokens == nil {
+		return nil, ErrRefreshNotConfigured
+	}
+
+	session, err := j.refreshTokens.Consume(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, ErrRefreshTokenReused) {
+		if revokeErr := j.RevokeSession(ctx, session.FamilyID); revokeErr != nil {
+			return nil, errors.Join(ErrRefreshTokenReused, revokeErr)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return j.issueTokenPair(ctx, *session)
+}
+
+// RevokeRefreshToken 登出：吊销刷新令牌所属的会话
+func (j *JWTManager) RevokeRefreshToken(ctx context.Context, refreshToken string) error {
+	if j.refreshTokens == nil {
+		return ErrRefreshNotConfigured
+	}
+	session, err := j.refreshTokens.Consume(ctx, hashRefreshToken(refreshToken))
+	if err != nil && !errors.Is(err, ErrRefreshTokenReused) {
+		return err
+	}
+	return j.RevokeSession(ctx, session.FamilyID)
+}
+
+func (j *JWTManager) issueTokenPair(ctx context.Context, session RefreshSession) (*TokenPair, error) {
+	if j.refreshTokens == nil {
+		return nil, ErrRefreshNotConfigured
+	}
+
+	accessToken, expiresAt, err := j.generateToken(&Claims{
+		UserID:    session.UserID,
+		Username:  session.Username,
+		Email:     session.Email,
+		IsAdmin:   session.IsAdmin,
+		SessionID: session.FamilyID,
+		AuthTime:  jwt.NewNumericDate(session.AuthTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	session.ExpiresAt = time.Now().Add(j.refreshExpiration())
+	if err := j.refreshTokens.Save(ctx, hash, session); err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (j *JWTManager) refreshExpiration() time.Duration {
+	if j.config.RefreshExpiration > 0 {
+		return j.config.RefreshExpiration
+	}
+	return 7 * 24 * time.Hour
+}
+
+// RefreshToken 刷新令牌。刷新后的令牌保留最初的登录时间，超过刷新令牌有效期后
+// 不能再刷新；配置了吊销存储时旧令牌随即失效。
+//
+// Deprecated: 使用 GenerateTokenPair 和 RotateRefreshToken。
 func (j *JWTManager) RefreshToken(tokenString string) (string, time.Time, error) {
-	claims, err := j.ValidateToken(tokenString)
+	ctx := context.Background()
+	claims, err := j.ValidateTokenContext(ctx, tokenString)
 	if err != nil {
 		return "", time.Time{}, err
 	}
 
 	// 检查令牌是否即将过期（在过期前30分钟内可以刷新）
 	if time.Until(claims.ExpiresAt.Time) > 30*time.Minute {
-		return "", time.Time{}, errors.New("token is not eligible for refresh")
+		return "", time.Time{}, ErrTokenNotRefreshable
+	}
+
+	// 升级前签发的令牌没有 auth_time，以签发时间为准
+	authTime := claims.AuthTime
+	if authTime == nil {
+		authTime = claims.IssuedAt
+	}
+	if authTime == nil || time.Since(authTime.Time) > j.refreshExpiration() {
+		return "", time.Time{}, ErrTokenNotRefreshable
 	}
 
-	// 创建新的用户对象用于生成新令牌
-	user := &models.User{
-		ID:       claims.UserID,
-		Username: claims.Username,
-		Email:    claims.Email,
+	if err := j.RevokeToken(ctx, claims); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to revoke refreshed token: %w", err)
+	}
+
+	return j.generateToken(&Claims{
+		UserID:    claims.UserID,
+		Username:  claims.Username,
+		Email:     claims.Email,
+		IsAdmin:   claims.IsAdmin,
+		SessionID: claims.SessionID,
+		AuthTime:  authTime,
+	})
+}
+
+// JWKS 返回本服务签名公钥的 JWKS，HS256 时为空
+func (j *JWTManager) JWKS() (JWKS, error) {
+	jwks := JWKS{Keys: []JWK{}}
+	if j.signingKey == nil {
+		return jwks, nil
+	}
+	jwk, err := j.signingKey.JWK()
+	if err != nil {
+		return jwks, err
 	}
+	jwks.Keys = append(jwks.Keys, jwk)
+	return jwks, nil
+}
 
-	return j.GenerateToken(user, claims.IsAdmin)
+// JWKSHandler 提供 /.well-known/jwks.json，供其他服务通过 JWKSURL 验证本服务签发的令牌
+func (j *JWTManager) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwks, err := j.JWKS()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(jwks)
+	})
 }
 
 // ExtractTokenFromHeader 从Authorization头中提取令牌
@@ -0,0 +1,290 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/config"
+	"github.com/codetaoist/laojun-shared/internal/redistest"
+	"github.com/codetaoist/laojun-shared/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func testUser() *models.User {
+	return &models.User{ID: uuid.New(), Username: "alice", Email: "alice@example.com"}
+}
+
+func testConfig() *config.JWTConfig {
+	return &config.JWTConfig{
+		Secret:     "test-secret",
+		Expiration: time.Hour,
+		Issuer:     "laojun",
+		Audience:   []string{"laojun-api"},
+	}
+}
+
+func newAsymmetricManager(t *testing.T, algorithm string) *JWTManager {
+	t.Helper()
+	key, err := GenerateSigningKey(algorithm, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig()
+	cfg.Algorithm = algorithm
+	manager := NewJWTManager(cfg)
+	manager.SetSigningKey(key)
+	return manager
+}
+
+func TestHS256Compatibility(t *testing.T) {
+	manager := NewJWTManager(testConfig())
+	token, _, err := manager.GenerateToken(testUser(), true)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	claims, err := manager.ValidateToken(token)
+	if err != nil || claims.Username != "alice" || !claims.IsAdmin || claims.ID == "" {
+		t.Fatalf("ValidateToken() = %+v, %v", claims, err)
+	}
+
+	// 签发者和受众不符的令牌被拒绝
+	other := testConfig()
+	other.Issuer = "someone-else"
+	if _, err := NewJWTManager(other).ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("wrong issuer error = %v", err)
+	}
+	other = testConfig()
+	other.Audience = []string{"billing"}
+	if _, err := NewJWTManager(other).ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("wrong audience error = %v", err)
+	}
+}
+
+func TestAsymmetricSigning(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			issuer := newAsymmetricManager(t, algorithm)
+			token, _, err := issuer.GenerateToken(testUser(), false)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			parsed, _, _ := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if parsed.Header["alg"] != algorithm || parsed.Header["kid"] != issuer.signingKey.ID {
+				t.Errorf("header = %v", parsed.Header)
+			}
+			if _, err := issuer.ValidateToken(token); err != nil {
+				t.Errorf("ValidateToken() error = %v", err)
+			}
+
+			// 其他服务通过 JWKS 端点验证
+			server := httptest.NewServer(issuer.JWKSHandler())
+			defer server.Close()
+			cfg := testConfig()
+			cfg.JWKSURL = server.URL
+			verifier, err := LoadJWTManager(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims, err := verifier.ValidateToken(token); err != nil || claims.Username != "alice" {
+				t.Errorf("verify via JWKS = %+v, %v", claims, err)
+			}
+
+			// 私钥 PEM 往返后 kid（指纹）不变
+			data, _ := issuer.signingKey.MarshalPEM()
+			reloaded, err := ParseSigningKey("", "", data)
+			if err != nil || reloaded.ID != issuer.signingKey.ID || reloaded.Algorithm != algorithm {
+				t.Errorf("ParseSigningKey() = %+v, %v", reloaded, err)
+			}
+		})
+	}
+}
+
+func TestAlgorithmConfusionRejected(t *testing.T) {
+	manager := newAsymmetricManager(t, AlgorithmRS256)
+
+	// 用 HS256 和公开信息伪造的令牌
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		Username:         "mallory",
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "laojun", Audience: jwt.ClaimStrings{"laojun-api"}},
+	})
+	forged.Header["kid"] = manager.signingKey.ID
+	token, _ := forged.SignedString([]byte(manager.config.Secret))
+	if _, err := manager.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("HS256 token accepted by RS256 manager: %v", err)
+	}
+
+	// kid 指向的密钥与令牌 alg 不一致
+	ed, _ := GenerateSigningKey(AlgorithmEdDSA, "")
+	mismatched := jwt.NewWithClaims(jwt.SigningMethodEdDSA, forged.Claims)
+	mismatched.Header["kid"] = manager.signingKey.ID
+	token, _ = mismatched.SignedString(ed.Private)
+	if _, err := manager.ValidateToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("EdDSA token accepted for RS256 kid: %v", err)
+	}
+}
+
+func TestFileKeySetRotation(t *testing.T) {
+	oldKey, _ := GenerateSigningKey(AlgorithmES256, "2024-01")
+	newKey, _ := GenerateSigningKey(AlgorithmEdDSA, "2024-06")
+	oldJWK, _ := oldKey.JWK()
+	newJWK, _ := newKey.JWK()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS := func(keys ...JWK) {
+		data, _ := json.Marshal(JWKS{Keys: keys})
+		os.WriteFile(path, data, 0o600)
+	}
+	writeJWKS(oldJWK)
+
+	cfg := testConfig()
+	cfg.JWKSFile = path
+	verifier := NewJWTManager(cfg)
+
+	signer := NewJWTManager(testConfig())
+	signer.SetSigningKey(oldKey)
+	oldToken, _, _ := signer.GenerateToken(testUser(), false)
+	signer.SetSigningKey(newKey)
+	newToken, _, _ := signer.GenerateToken(testUser(), false)
+
+	if _, err := verifier.ValidateToken(oldToken); err != nil {
+		t.Fatalf("old key: %v", err)
+	}
+	if _, err := verifier.ValidateToken(newToken); err == nil {
+		t.Fatal("token signed with unpublished key accepted")
+	}
+
+	// 发布新公钥后，未知 kid 触发重新加载（受最小刷新间隔限制）
+	writeJWKS(oldJWK, newJWK)
+	verifier.keySet.fetchedAt = time.Now().Add(-time.Minute)
+	if _, err := verifier.ValidateToken(newToken); err != nil {
+		t.Errorf("new key after reload: %v", err)
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	ctx := context.Background()
+	manager := NewJWTManager(testConfig())
+	manager.SetRefreshStore(NewMemoryRefreshStore())
+	manager.SetRevocationStore(NewMemoryRevocationStore())
+
+	if _, err := NewJWTManager(testConfig()).GenerateTokenPair(ctx, testUser(), false); !errors.Is(err, ErrRefreshNotConfigured) {
+		t.Errorf("GenerateTokenPair() without store error = %v", err)
+	}
+
+	first, err := manager.GenerateTokenPair(ctx, testUser(), false)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+	second, err := manager.RotateRefreshToken(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+	firstClaims, _ := manager.ValidateToken(first.AccessToken)
+	secondClaims, err := manager.ValidateToken(second.AccessToken)
+	if err != nil || secondClaims.SessionID != firstClaims.SessionID || !secondClaims.AuthTime.Equal(firstClaims.AuthTime.Time) {
+		t.Fatalf("rotated claims = %+v, %v", secondClaims, err)
+	}
+
+	// 重放已使用的刷新令牌：整个会话被吊销
+	if _, err := manager.RotateRefreshToken(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse error = %v", err)
+	}
+	if _, err := manager.RotateRefreshToken(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("second refresh token after reuse error = %v", err)
+	}
+	if _, err := manager.ValidateToken(second.AccessToken); !errors.Is(err, ErrRevokedToken) {
+		t.Errorf("access token after reuse error = %v", err)
+	}
+
+	if _, err := manager.RotateRefreshToken(ctx, "not-a-token"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("unknown refresh token error = %v", err)
+	}
+}
+
+func TestLegacyRefreshBounded(t *testing.T) {
+	cfg := testConfig()
+	cfg.Expiration = 10 * time.Minute
+	cfg.RefreshExpiration = time.Hour
+	manager := NewJWTManager(cfg)
+	manager.SetRevocationStore(NewMemoryRevocationStore())
+
+	token, _, _ := manager.GenerateToken(testUser(), false)
+	refreshed, _, err := manager.RefreshToken(token)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	if _, err := manager.ValidateToken(token); !errors.Is(err, ErrRevokedToken) {
+		t.Errorf("refreshed token still valid: %v", err)
+	}
+	if _, _, err := manager.RefreshToken(token); err == nil {
+		t.Error("token refreshed twice")
+	}
+
+	// 登录时间超过刷新有效期后不能再刷新
+	claims, _ := manager.ValidateToken(refreshed)
+	claims.AuthTime = jwt.NewNumericDate(time.Now().Add(-2 * time.Hour))
+	old, _, _ := manager.generateToken(claims)
+	if _, _, err := manager.RefreshToken(old); !errors.Is(err, ErrTokenNotRefreshable) {
+		t.Errorf("RefreshToken(old session) error = %v", err)
+	}
+}
+
+func TestRedisStores(t *testing.T) {
+	ctx := context.Background()
+	server := redistest.New(t)
+	client := server.Client(t)
+
+	manager := newAsymmetricManager(t, AlgorithmEdDSA)
+	manager.SetRevocationStore(NewRedisRevocationStore(client, ""))
+	manager.SetRefreshStore(NewRedisRefreshStore(client, ""))
+
+	pair, err := manager.GenerateTokenPair(ctx, testUser(), true)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+	claims, err := manager.ValidateTokenContext(ctx, pair.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateTokenContext() error = %v", err)
+	}
+
+	if err := manager.RevokeToken(ctx, claims); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.ValidateTokenContext(ctx, pair.AccessToken); !errors.Is(err, ErrRevokedToken) {
+		t.Errorf("revoked token error = %v", err)
+	}
+	if ttl := server.TTL("auth:revoked:jti:" + claims.ID); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("revocation TTL = %v", ttl)
+	}
+
+	next, err := manager.RotateRefreshToken(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() error = %v", err)
+	}
+	if nextClaims, err := manager.ValidateTokenContext(ctx, next.AccessToken); err != nil || !nextClaims.IsAdmin {
+		t.Errorf("rotated access token = %+v, %v", nextClaims, err)
+	}
+	if _, err := manager.RotateRefreshToken(ctx, pair.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("reuse error = %v", err)
+	}
+	if _, err := manager.ValidateTokenContext(ctx, next.AccessToken); !errors.Is(err, ErrRevokedToken) {
+		t.Errorf("session access token after reuse error = %v", err)
+	}
+
+	// 登出
+	again, _ := manager.GenerateTokenPair(ctx, testUser(), false)
+	if err := manager.RevokeRefreshToken(ctx, again.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.RotateRefreshToken(ctx, again.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refresh after logout error = %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var ErrUnsupportedKey = errors.New("unsupported signing key")
+
+// SigningKey 非对称签名密钥
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+}
+
+// GenerateSigningKey 生成签名密钥，kid 为空时使用公钥指纹
+func GenerateSigningKey(algorithm, kid string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: algorithm %q", ErrUnsupportedKey, algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newSigningKey(algorithm, kid, private)
+}
+
+// LoadSigningKey 从 PEM 文件加载签名私钥
+func LoadSigningKey(algorithm, kid, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	return ParseSigningKey(algorithm, kid, data)
+}
+
+// ParseSigningKey 解析 PEM 编码的私钥（PKCS#8、PKCS#1 或 SEC 1），
+// algorithm 为空时根据密钥类型推断
+func ParseSigningKey(algorithm, kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM data found", ErrUnsupportedKey)
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedKey, err)
+	}
+
+	private, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+	if algorithm == "" {
+		algorithm = algorithmForKey(private.Public())
+	}
+	return newSigningKey(algorithm, kid, private)
+}
+
+func newSigningKey(algorithm, kid string, private crypto.Signer) (*SigningKey, error) {
+	if !keyMatchesAlgorithm(algorithm, private.Public()) {
+		return nil, fmt.Errorf("%w: %T cannot sign %s", ErrUnsupportedKey, private, algorithm)
+	}
+	if kid == "" {
+		jwk, err := NewJWK("", algorithm, private.Public())
+		if err != nil {
+			return nil, err
+		}
+		if kid, err = jwk.Thumbprint(); err != nil {
+			return nil, err
+		}
+	}
+	return &SigningKey{ID: kid, Algorithm: algorithm, Private: private}, nil
+}
+
+// Public 返回公钥
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// JWK 返回公钥的 JWK 表示
+func (k *SigningKey) JWK() (JWK, error) {
+	return NewJWK(k.ID, k.Algorithm, k.Public())
+}
+
+// MarshalPEM 以 PKCS#8 PEM 编码私钥
+func (k *SigningKey) MarshalPEM() ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	switch algorithm {
+	case AlgorithmHS256:
+		return jwt.SigningMethodHS256
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case AlgorithmES256:
+		return jwt.SigningMethodES256
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+func algorithmForKey(public crypto.PublicKey) string {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRS256
+	case *ecdsa.PublicKey:
+		if key.Curve == elliptic.P256() {
+			return AlgorithmES256
+		}
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA
+	}
+	return ""
+}
+
+func keyMatchesAlgorithm(algorithm string, public crypto.PublicKey) bool {
+	return algorithm != "" && algorithm != AlgorithmHS256 && algorithmForKey(public) == algorithm
+}
+
+// JWK JSON Web Key（RFC 7517），仅包含公钥参数
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC / OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// NewJWK 根据公钥创建 JWK
+func NewJWK(kid, algorithm string, public crypto.PublicKey) (JWK, error) {
+	jwk := JWK{Kid: kid, Use: "sig", Alg: algorithm}
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64.EncodeToString(key.N.Bytes())
+		jwk.E = b64.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return jwk, fmt.Errorf("%w: curve %s", ErrUnsupportedKey, key.Curve.Params().Name)
+		}
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = b64.EncodeToString(key.X.FillBytes(make([]byte, 32)))
+		jwk.Y = b64.EncodeToString(key.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64.EncodeToString(key)
+	default:
+		return jwk, fmt.Errorf("%w: %T", ErrUnsupportedKey, public)
+	}
+	return jwk, nil
+}
+
+// PublicKey 解析 JWK 中的公钥
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err1 := b64.DecodeString(k.N)
+		e, err2 := b64.DecodeString(k.E)
+		if err := errors.Join(err1, err2); err != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%w: invalid RSA key %q", ErrUnsupportedKey, k.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		x, err1 := b64.DecodeString(k.X)
+		y, err2 := b64.DecodeString(k.Y)
+		if err := errors.Join(err1, err2); err != nil || k.Crv != "P-256" || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("%w: invalid EC key %q", ErrUnsupportedKey, k.Kid)
+		}
+		// 校验点在曲线上
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("%w: invalid EC key %q: %v", ErrUnsupportedKey, k.Kid, err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := b64.DecodeString(k.X)
+		if err != nil || k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid OKP key %q", ErrUnsupportedKey, k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("%w: key type %q", ErrUnsupportedKey, k.Kty)
+}
+
+// Thumbprint 计算 RFC 7638 指纹
+func (k JWK) Thumbprint() (string, error) {
+	var members interface{}
+	switch k.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	default:
+		return "", fmt.Errorf("%w: key type %q", ErrUnsupportedKey, k.Kty)
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return b64.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+// RefreshSession 刷新令牌对应的会话。同一次登录轮换出的刷新令牌属于同一个
+// 令牌族（FamilyID），族 ID 同时作为访问令牌的 sid。
+type RefreshSession struct {
+	FamilyID  string    `json:"family_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	AuthTime  time.Time `json:"auth_time"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RefreshStore 刷新令牌存储，键为令牌的 SHA-256 哈希，不保存令牌原文
+type RefreshStore interface {
+	// Save 保存刷新令牌，记录保留到 session.ExpiresAt
+	Save(ctx context.Context, tokenHash string, session RefreshSession) error
+	// Consume 原子地将令牌标记为已使用并返回会话。令牌已被使用过时返回会话和
+	// ErrRefreshTokenReused；不存在或已过期时返回 ErrInvalidRefreshToken。
+	Consume(ctx context.Context, tokenHash string) (*RefreshSession, error)
+	// RevokeFamily 删除令牌族中的所有刷新令牌
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+// newRefreshToken 生成不透明的刷新令牌及其哈希
+func newRefreshToken() (token, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token = b64.EncodeToString(raw)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryRefreshStore 内存刷新令牌存储，仅适用于单实例和测试
+type MemoryRefreshStore struct {
+	mu       sync.Mutex
+	tokens   map[string]*memoryRefreshToken
+	families map[string][]string
+}
+
+type memoryRefreshToken struct {
+	session RefreshSession
+	used    bool
+}
+
+// NewMemoryRefreshStore 创建内存刷新令牌存储
+func NewMemoryRefreshStore() *MemoryRefreshStore {
+	return &MemoryRefreshStore{
+		tokens:   make(map[string]*memoryRefreshToken),
+		families: make(map[string][]string),
+	}
+}
+
+// Save 保存刷新令牌
+func (s *MemoryRefreshStore) Save(ctx context.Context, tokenHash string, session RefreshSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[tokenHash] = &memoryRefreshToken{session: session}
+	s.families[session.FamilyID] = append(s.families[session.FamilyID], tokenHash)
+	return nil
+}
+
+// Consume 将令牌标记为已使用并返回会话
+func (s *MemoryRefreshStore) Consume(ctx context.Context, tokenHash string) (*RefreshSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[tokenHash]
+	if !ok || time.Now().After(token.session.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	session := token.session
+	if token.used {
+		return &session, ErrRefreshTokenReused
+	}
+	token.used = true
+	return &session, nil
+}
+
+// RevokeFamily 删除令牌族中的所有刷新令牌
+func (s *MemoryRefreshStore) RevokeFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, hash := range s.families[familyID] {
+		delete(s.tokens, hash)
+	}
+	delete(s.families, familyID)
+	return nil
+}
+
+// RedisRefreshStore Redis 刷新令牌存储。已使用的令牌保留到过期，用于重用检测。
+type RedisRefreshStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisRefreshStore 创建 Redis 刷新令牌存储，prefix 默认为 "auth:refresh:"
+func NewRedisRefreshStore(client redis.UniversalClient, prefix string) *RedisRefreshStore {
+	if prefix == "" {
+		prefix = "auth:refresh:"
+	}
+	return &RedisRefreshStore{client: client, prefix: prefix}
+}
+
+// consumeScript 标记令牌为已使用，返回 {是否已使用, 会话}
+const consumeScript = `
+local v = redis.call('HMGET', KEYS[1], 'session', 'used')
+if not v[1] then
+	return nil
+end
+if v[2] == '1' then
+	return {1, v[1]}
+end
+redis.call('HSET', KEYS[1], 'used', '1')
+return {0, v[1]}
+`
+
+var consumeLua = redis.NewScript(consumeScript)
+
+func (s *RedisRefreshStore) tokenKey(hash string) string {
+	return s.prefix + "token:" + hash
+}
+
+func (s *RedisRefreshStore) familyKey(familyID string) string {
+	return s.prefix + "family:" + familyID
+}
+
+// Save 保存刷新令牌
+func (s *RedisRefreshStore) Save(ctx context.Context, tokenHash string, session RefreshSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return ErrInvalidRefreshToken
+	}
+
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		key := s.tokenKey(tokenHash)
+		pipe.HSet(ctx, key, "session", data, "used", "0")
+		pipe.Expire(ctx, key, ttl)
+		family := s.familyKey(session.FamilyID)
+		pipe.SAdd(ctx, family, tokenHash)
+		pipe.Expire(ctx, family, ttl)
+		return nil
+	})
+	return err
+}
+
+// Consume 将令牌标记为已使用并返回会话
+func (s *RedisRefreshStore) Consume(ctx context.Context, tokenHash string) (*RefreshSession, error) {
+	result, err := consumeLua.Run(ctx, s.client, []string{s.tokenKey(tokenHash)}).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != 2 {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	data, _ := result[1].(string)
+	var session RefreshSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if used, _ := result[0].(int64); used == 1 {
+		return &session, ErrRefreshTokenReused
+	}
+	return &session, nil
+}
+
+// RevokeFamily 删除令牌族中的所有刷新令牌
+func (s *RedisRefreshStore) RevokeFamily(ctx context.Context, familyID string) error {
+	family := s.familyKey(familyID)
+	hashes, err := s.client.SMembers(ctx, family).Result()
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, hash := range hashes {
+			pipe.Del(ctx, s.tokenKey(hash))
+		}
+		pipe.Del(ctx, family)
+		return nil
+	})
+	return err
+}
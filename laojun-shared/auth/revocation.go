@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationStore 令牌吊销存储，ValidateToken 会检查令牌 ID（jti）和会话 ID（sid）
+type RevocationStore interface {
+	// Revoke 吊销 id，记录保留到 until（令牌本身过期后无需再保留）
+	Revoke(ctx context.Context, id string, until time.Time) error
+	// IsRevoked 检查任一 id 是否已被吊销
+	IsRevoked(ctx context.Context, ids ...string) (bool, error)
+}
+
+// MemoryRevocationStore 内存吊销存储，仅适用于单实例和测试
+type MemoryRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryRevocationStore 创建内存吊销存储
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{revoked: make(map[string]time.Time)}
+}
+
+// Revoke 吊销 id
+func (s *MemoryRevocationStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for revokedID, expiresAt := range s.revoked {
+		if now.After(expiresAt) {
+			delete(s.revoked, revokedID)
+		}
+	}
+	s.revoked[id] = until
+	return nil
+}
+
+// IsRevoked 检查任一 id 是否已被吊销
+func (s *MemoryRevocationStore) IsRevoked(ctx context.Context, ids ...string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, id := range ids {
+		if until, ok := s.revoked[id]; ok && now.Before(until) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RedisRevocationStore Redis 吊销存储，记录以 TTL 自动过期
+type RedisRevocationStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisRevocationStore 创建 Redis 吊销存储，prefix 默认为 "auth:revoked:"
+func NewRedisRevocationStore(client redis.UniversalClient, prefix string) *RedisRevocationStore {
+	if prefix == "" {
+		prefix = "auth:revoked:"
+	}
+	return &RedisRevocationStore{client: client, prefix: prefix}
+}
+
+// Revoke 吊销 id
+func (s *RedisRevocationStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, s.prefix+id, 1, ttl).Err()
+}
+
+// IsRevoked 检查任一 id 是否已被吊销
+func (s *RedisRevocationStore) IsRevoked(ctx context.Context, ids ...string) (bool, error) {
+	// 逐个 EXISTS，避免集群模式下的跨槽错误
+	cmds := make([]*redis.IntCmd, len(ids))
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.Exists(ctx, s.prefix+id)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, cmd := range cmds {
+		if cmd.Val() > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,462 @@
+// Package cache 提供进程内LRU与Redis组成的多级缓存。
+//
+// 读取依次查找本地缓存和Redis；GetOrLoad 在未命中时通过 singleflight 合并同一键的
+// 并发加载，防止缓存击穿；配置了 StaleTTL 的条目过期后在宽限期内继续返回旧值并在
+// 后台刷新。写入和失效通过 Redis pubsub 广播，其他副本据此丢弃本地副本；条目可以
+// 关联标签，按标签批量失效。
+package cache
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/monitoring"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// 缓存错误
+var (
+	// ErrNotFound 键不存在或已过期
+	ErrNotFound = errors.New("cache: key not found")
+	// ErrUnsupportedValue Set 不支持的值类型，结构体等应使用 SetJSON
+	ErrUnsupportedValue = errors.New("cache: unsupported value type")
+)
+
+// CacheType 缓存类型
+type CacheType string
+
+const (
+	// CacheTypeMemory 仅使用进程内缓存
+	CacheTypeMemory CacheType = "memory"
+	// CacheTypeRedis 使用Redis，EnableMultiLevel 时在前面加一层进程内缓存
+	CacheTypeRedis CacheType = "redis"
+)
+
+// 默认值
+const (
+	DefaultExpiration          = 10 * time.Minute
+	DefaultMemoryCleanup       = time.Minute
+	DefaultMemoryMaxEntries    = 10000
+	DefaultLocalTTL            = time.Minute
+	DefaultLoadTimeout         = 10 * time.Second
+	DefaultKeyPrefix           = "laojun:cache:"
+	DefaultInvalidationChannel = "laojun:cache:invalidate"
+)
+
+// CacheConfig 缓存配置
+type CacheConfig struct {
+	// Name 缓存名称，作为指标的 cache 标签
+	Name string    `yaml:"name" json:"name"`
+	Type CacheType `yaml:"type" json:"type"`
+	// EnableMultiLevel Redis 缓存前加一层进程内缓存
+	EnableMultiLevel bool `yaml:"enable_multi_level" json:"enable_multi_level"`
+	// DefaultExpiration ttl<=0 时使用的过期时间
+	DefaultExpiration time.Duration `yaml:"default_expiration" json:"default_expiration"`
+	// MemoryCleanup 清理进程内过期条目的间隔
+	MemoryCleanup time.Duration `yaml:"memory_cleanup" json:"memory_cleanup"`
+	// MemoryMaxEntries 进程内缓存的最大条目数，超出时淘汰最久未使用的条目
+	MemoryMaxEntries int `yaml:"memory_max_entries" json:"memory_max_entries"`
+	// LocalTTL 多级缓存中本地副本的最长保留时间，限制丢失失效消息时读到旧值的时长
+	LocalTTL time.Duration `yaml:"local_ttl" json:"local_ttl"`
+	// LoadTimeout GetOrLoad 加载函数的超时时间，加载不随调用方的 ctx 取消
+	LoadTimeout time.Duration `yaml:"load_timeout" json:"load_timeout"`
+	// KeyPrefix Redis 键前缀
+	KeyPrefix string `yaml:"key_prefix" json:"key_prefix"`
+	// InvalidationChannel 广播失效消息的 Redis 频道
+	InvalidationChannel string `yaml:"invalidation_channel" json:"invalidation_channel"`
+
+	// Monitor 用于上报命中率等指标，可为空
+	Monitor monitoring.Monitor `yaml:"-" json:"-"`
+	// Logger 日志，可为空
+	Logger *zap.Logger `yaml:"-" json:"-"`
+}
+
+// RedisConfig Redis 连接配置
+type RedisConfig struct {
+	Host     string `yaml:"host" json:"host"`
+	Port     int    `yaml:"port" json:"port"`
+	Password string `yaml:"password" json:"password"`
+	DB       int    `yaml:"db" json:"db"`
+}
+
+// entry 缓存条目，freshUntil 之后为旧值，expiresAt 之后删除
+type entry struct {
+	value      []byte
+	freshUntil time.Time
+	expiresAt  time.Time
+}
+
+// Manager 多级缓存
+type Manager struct {
+	config     CacheConfig
+	local      *memoryCache
+	remote     redis.UniversalClient
+	ownsRemote bool
+	logger     *zap.Logger
+	origin     string
+	group      singleflight.Group
+	metrics    *metrics
+
+	pubsub    *redis.PubSub
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewManager 创建缓存，Type 为 redis 时根据 redisConfig 连接Redis
+func NewManager(config *CacheConfig, redisConfig *RedisConfig) (*Manager, error) {
+	if config == nil {
+		config = &CacheConfig{Type: CacheTypeMemory}
+	}
+	if config.Type != CacheTypeRedis {
+		return newManager(*config, nil, false)
+	}
+	if redisConfig == nil {
+		return nil, fmt.Errorf("redis config is required for cache type %q", config.Type)
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", redisConfig.Host, redisConfig.Port),
+		Password: redisConfig.Password,
+		DB:       redisConfig.DB,
+	})
+	manager, err := newManager(*config, client, true)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return manager, nil
+}
+
+// NewManagerWithClient 使用已有的Redis客户端创建缓存，Close 不会关闭 client
+func NewManagerWithClient(config *CacheConfig, client redis.UniversalClient) (*Manager, error) {
+	cfg := CacheConfig{Type: CacheTypeRedis}
+	if config != nil {
+		cfg = *config
+		cfg.Type = CacheTypeRedis
+	}
+	return newManager(cfg, client, false)
+}
+
+func newManager(config CacheConfig, client redis.UniversalClient, ownsClient bool) (*Manager, error) {
+	if config.DefaultExpiration <= 0 {
+		config.DefaultExpiration = DefaultExpiration
+	}
+	if config.MemoryCleanup <= 0 {
+		config.MemoryCleanup = DefaultMemoryCleanup
+	}
+	if config.MemoryMaxEntries <= 0 {
+		config.MemoryMaxEntries = DefaultMemoryMaxEntries
+	}
+	if config.LocalTTL <= 0 {
+		config.LocalTTL = DefaultLocalTTL
+	}
+	if config.LoadTimeout <= 0 {
+		config.LoadTimeout = DefaultLoadTimeout
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = DefaultKeyPrefix
+	}
+	if config.InvalidationChannel == "" {
+		config.InvalidationChannel = DefaultInvalidationChannel
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	m := &Manager{
+		config:     config,
+		remote:     client,
+		ownsRemote: ownsClient,
+		logger:     logger,
+		origin:     uuid.NewString(),
+		metrics:    newMetrics(config.Name, config.Monitor),
+		stop:       make(chan struct{}),
+	}
+
+	if client != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+	}
+
+	if client == nil || config.EnableMultiLevel {
+		m.local = newMemoryCache(config.MemoryMaxEntries, m.metrics.evicted)
+		m.wg.Add(1)
+		go m.cleanupLoop()
+	}
+
+	if client != nil && m.local != nil {
+		if err := m.subscribe(); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Get 获取字符串值，键不存在时返回 ErrNotFound
+func (m *Manager) Get(ctx context.Context, key string) (string, error) {
+	value, err := m.GetBytes(ctx, key)
+	return string(value), err
+}
+
+// GetBytes 获取原始值，键不存在时返回 ErrNotFound
+func (m *Manager) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	e, found, err := m.lookup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		m.metrics.miss(ctx)
+		return nil, ErrNotFound
+	}
+	return e.value, nil
+}
+
+// Set 写入值，value 支持字符串、[]byte、数字、布尔和 encoding.BinaryMarshaler；
+// ttl<=0 时使用 DefaultExpiration
+func (m *Manager) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+	return m.store(ctx, key, data, ttl, 0, nil)
+}
+
+// SetWithTags 写入值并关联标签，可通过 InvalidateTags 批量失效
+func (m *Manager) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+	return m.store(ctx, key, data, ttl, 0, tags)
+}
+
+// SetJSON 以JSON编码写入值
+func (m *Manager) SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+	return m.store(ctx, key, data, ttl, 0, nil)
+}
+
+// GetJSON 获取JSON编码的值并解码到 dest
+func (m *Manager) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	data, err := m.GetBytes(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal cache value: %w", err)
+	}
+	return nil
+}
+
+// Exists 返回存在的键数量
+func (m *Manager) Exists(ctx context.Context, keys ...string) (int64, error) {
+	if m.remote != nil {
+		redisKeys := make([]string, len(keys))
+		for i, key := range keys {
+			redisKeys[i] = m.redisKey(key)
+		}
+		return m.remote.Exists(ctx, redisKeys...).Result()
+	}
+
+	var count int64
+	now := time.Now()
+	for _, key := range keys {
+		if _, ok := m.local.get(key, now); ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Del 删除键并通知其他副本丢弃本地副本
+func (m *Manager) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		m.group.Forget(key)
+	}
+	if m.local != nil {
+		m.local.delete(keys...)
+	}
+	if m.remote == nil {
+		return nil
+	}
+
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = m.redisKey(key)
+	}
+	if err := m.remote.Del(ctx, redisKeys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache keys: %w", err)
+	}
+	m.broadcast(ctx, invalidation{Keys: keys})
+	return nil
+}
+
+// Close 停止后台任务，关闭 NewManager 创建的Redis连接
+func (m *Manager) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.stop)
+		if m.pubsub != nil {
+			m.pubsub.Close()
+		}
+		m.wg.Wait()
+		if m.ownsRemote {
+			err = m.remote.Close()
+		}
+	})
+	return err
+}
+
+// lookup 依次查找本地缓存和Redis，Redis 命中时回填本地缓存
+func (m *Manager) lookup(ctx context.Context, key string) (entry, bool, error) {
+	now := time.Now()
+	if m.local != nil {
+		if e, ok := m.local.get(key, now); ok {
+			m.metrics.hit(ctx, tierLocal)
+			return e, true, nil
+		}
+	}
+	if m.remote == nil {
+		return entry{}, false, nil
+	}
+
+	values, err := m.remote.HMGet(ctx, m.redisKey(key), "v", "f", "e").Result()
+	if err != nil {
+		return entry{}, false, fmt.Errorf("failed to read cache: %w", err)
+	}
+	data, ok := values[0].(string)
+	if !ok {
+		return entry{}, false, nil
+	}
+	e := entry{
+		value:      []byte(data),
+		freshUntil: parseMillis(values[1]),
+		expiresAt:  parseMillis(values[2]),
+	}
+	m.metrics.hit(ctx, tierRedis)
+
+	if m.local != nil {
+		m.local.set(key, m.localEntry(e, now), nil)
+	}
+	return e, true, nil
+}
+
+// store 写入Redis和本地缓存，fresh 之后条目在 stale 时长内作为旧值保留
+func (m *Manager) store(ctx context.Context, key string, data []byte, fresh, stale time.Duration, tags []string) error {
+	if fresh <= 0 {
+		fresh = m.config.DefaultExpiration
+	}
+	now := time.Now()
+	e := entry{value: data, freshUntil: now.Add(fresh), expiresAt: now.Add(fresh + stale)}
+
+	if m.remote != nil {
+		redisKey := m.redisKey(key)
+		ttl := (fresh + stale).Milliseconds()
+		// 先写标签索引再写条目，条目存在时总能通过标签失效
+		for _, tag := range tags {
+			if err := tagScript.Run(ctx, m.remote, []string{m.tagKey(tag)}, redisKey, ttl).Err(); err != nil {
+				return fmt.Errorf("failed to write cache tags: %w", err)
+			}
+		}
+		err := setScript.Run(ctx, m.remote, []string{redisKey},
+			data, e.freshUntil.UnixMilli(), e.expiresAt.UnixMilli(), ttl,
+		).Err()
+		if err != nil {
+			return fmt.Errorf("failed to write cache: %w", err)
+		}
+		m.broadcast(ctx, invalidation{Keys: []string{key}})
+	}
+
+	if m.local != nil {
+		m.local.set(key, m.localEntry(e, now), tags)
+	}
+	return nil
+}
+
+// localEntry 多级缓存中本地副本最多保留 LocalTTL
+func (m *Manager) localEntry(e entry, now time.Time) entry {
+	if m.remote == nil {
+		return e
+	}
+	if limit := now.Add(m.config.LocalTTL); e.expiresAt.IsZero() || e.expiresAt.After(limit) {
+		e.expiresAt = limit
+	}
+	if e.freshUntil.After(e.expiresAt) {
+		e.freshUntil = e.expiresAt
+	}
+	return e
+}
+
+func (m *Manager) cleanupLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.config.MemoryCleanup)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.local.purgeExpired(now)
+		}
+	}
+}
+
+func (m *Manager) redisKey(key string) string {
+	return m.config.KeyPrefix + key
+}
+
+func (m *Manager) tagKey(tag string) string {
+	return m.config.KeyPrefix + "tag:" + tag
+}
+
+func encodeValue(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
+		return []byte(fmt.Sprint(v)), nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedValue, value)
+	}
+}
+
+func parseMillis(value interface{}) time.Time {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}
+	}
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,287 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/internal/redistest"
+	"github.com/codetaoist/laojun-shared/monitoring"
+)
+
+func newMemoryManager(t *testing.T, config CacheConfig) *Manager {
+	t.Helper()
+	config.Type = CacheTypeMemory
+	m, err := NewManager(&config, nil)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func newRedisManager(t *testing.T, server *redistest.Server) *Manager {
+	t.Helper()
+	port, _ := strconv.Atoi(server.Port())
+	m, err := NewManager(&CacheConfig{Type: CacheTypeRedis, EnableMultiLevel: true}, &RedisConfig{Host: server.Host(), Port: port})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+// eventually 在超时前反复检查条件
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	m := newMemoryManager(t, CacheConfig{MemoryMaxEntries: 2})
+	ctx := context.Background()
+
+	if _, err := m.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(missing) error = %v", err)
+	}
+	if err := m.Set(ctx, "user", struct{}{}, time.Minute); !errors.Is(err, ErrUnsupportedValue) {
+		t.Fatalf("Set(struct) error = %v", err)
+	}
+
+	m.Set(ctx, "a", "1", time.Minute)
+	m.Set(ctx, "b", 2, time.Minute)
+	m.Get(ctx, "a")
+	m.Set(ctx, "c", true, time.Minute)
+
+	// b 最久未使用，被淘汰
+	if n, _ := m.Exists(ctx, "a", "b", "c"); n != 2 {
+		t.Errorf("Exists() = %d, want 2", n)
+	}
+	if value, err := m.Get(ctx, "c"); err != nil || value != "true" {
+		t.Errorf("Get(c) = %q, %v", value, err)
+	}
+
+	m.Set(ctx, "short", "x", 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, err := m.Get(ctx, "short"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expired key returned, err = %v", err)
+	}
+
+	stats := m.Stats()
+	if stats.Evictions == 0 || stats.Misses != 2 || stats.LocalEntries > 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTypedHelpers(t *testing.T) {
+	m := newMemoryManager(t, CacheConfig{})
+	ctx := context.Background()
+
+	type plugin struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := Set(ctx, m, "plugin:1", plugin{ID: "1", Name: "markdown"}, time.Minute, "plugins"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Get[plugin](ctx, m, "plugin:1")
+	if err != nil || got.Name != "markdown" {
+		t.Fatalf("Get() = %+v, %v", got, err)
+	}
+
+	loaded, err := GetOrLoad(ctx, m, "plugin:2", LoadOptions{TTL: time.Minute}, func(ctx context.Context) (*plugin, error) {
+		return &plugin{ID: "2", Name: "mermaid"}, nil
+	})
+	if err != nil || loaded.Name != "mermaid" {
+		t.Fatalf("GetOrLoad() = %+v, %v", loaded, err)
+	}
+
+	if err := m.InvalidateTags(ctx, "plugins"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Get[plugin](ctx, m, "plugin:1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("tagged key survived invalidation, err = %v", err)
+	}
+	if _, err := Get[plugin](ctx, m, "plugin:2"); err != nil {
+		t.Errorf("untagged key invalidated, err = %v", err)
+	}
+}
+
+func TestGetOrLoadCoalescesConcurrentLoads(t *testing.T) {
+	m := newMemoryManager(t, CacheConfig{})
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("value"), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan string, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := m.GetOrLoad(ctx, "hot", LoadOptions{TTL: time.Minute}, load)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- string(value)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("loader called %d times, want 1", n)
+	}
+	for value := range results {
+		if value != "value" {
+			t.Errorf("result = %q", value)
+		}
+	}
+
+	// 调用方取消不影响加载
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := m.GetOrLoad(cancelled, "slow", LoadOptions{}, func(ctx context.Context) ([]byte, error) {
+		time.Sleep(10 * time.Millisecond)
+		return []byte("done"), ctx.Err()
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetOrLoad(cancelled) error = %v", err)
+	}
+	eventually(t, "detached load", func() bool {
+		value, _ := m.Get(ctx, "slow")
+		return value == "done"
+	})
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	m := newMemoryManager(t, CacheConfig{})
+	ctx := context.Background()
+	opts := LoadOptions{TTL: 20 * time.Millisecond, StaleTTL: time.Minute}
+
+	var version atomic.Int32
+	load := func(ctx context.Context) ([]byte, error) {
+		if version.Add(1) == 1 {
+			return []byte("v1"), nil
+		}
+		return []byte("v2"), nil
+	}
+
+	if value, _ := m.GetOrLoad(ctx, "k", opts, load); string(value) != "v1" {
+		t.Fatalf("first load = %q", value)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// 过期后立即返回旧值，后台刷新
+	if value, _ := m.GetOrLoad(ctx, "k", opts, load); string(value) != "v1" {
+		t.Fatalf("stale read = %q", value)
+	}
+	eventually(t, "background refresh", func() bool {
+		value, _ := m.GetOrLoad(ctx, "k", opts, load)
+		return string(value) == "v2"
+	})
+	if stats := m.Stats(); stats.StaleHits == 0 || stats.Loads != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// 加载失败时返回错误且不缓存
+	if _, err := m.GetOrLoad(ctx, "broken", opts, func(context.Context) ([]byte, error) {
+		return nil, errors.New("database down")
+	}); err == nil || err.Error() != "database down" {
+		t.Errorf("GetOrLoad(broken) error = %v", err)
+	}
+}
+
+func TestMultiLevelInvalidation(t *testing.T) {
+	server := redistest.New(t)
+	a := newRedisManager(t, server)
+	b := newRedisManager(t, server)
+	ctx := context.Background()
+
+	if err := a.Set(ctx, "config", "v1", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if value, err := b.Get(ctx, "config"); err != nil || value != "v1" {
+		t.Fatalf("b.Get() = %q, %v", value, err)
+	}
+	commands := server.CommandCount()
+	b.Get(ctx, "config")
+	if server.CommandCount() != commands || b.Stats().LocalHits != 1 {
+		t.Fatalf("second read not served locally: %+v", b.Stats())
+	}
+
+	// a 的写入使 b 的本地副本失效
+	a.Set(ctx, "config", "v2", time.Minute)
+	eventually(t, "set invalidation", func() bool {
+		value, _ := b.Get(ctx, "config")
+		return value == "v2"
+	})
+
+	a.SetWithTags(ctx, "plugin:1", "markdown", time.Minute, "plugins")
+	a.SetWithTags(ctx, "plugin:2", "mermaid", time.Minute, "plugins")
+	b.Get(ctx, "plugin:1")
+	if err := b.InvalidateTags(ctx, "plugins"); err != nil {
+		t.Fatalf("InvalidateTags() error = %v", err)
+	}
+	if n, _ := a.Exists(ctx, "plugin:1", "plugin:2", "config"); n != 1 {
+		t.Errorf("Exists() = %d after invalidation", n)
+	}
+	if server.Exists(DefaultKeyPrefix + "tag:plugins") {
+		t.Error("tag set not deleted after invalidation")
+	}
+	eventually(t, "tag invalidation", func() bool {
+		_, err1 := a.Get(ctx, "plugin:1")
+		_, err2 := b.Get(ctx, "plugin:1")
+		return errors.Is(err1, ErrNotFound) && errors.Is(err2, ErrNotFound)
+	})
+
+	b.Get(ctx, "config")
+	if err := a.Del(ctx, "config"); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	eventually(t, "delete invalidation", func() bool {
+		_, err := b.Get(ctx, "config")
+		return errors.Is(err, ErrNotFound)
+	})
+}
+
+func TestMetricsReported(t *testing.T) {
+	monitor, err := monitoring.NewMonitor(monitoring.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer monitor.Close()
+
+	m := newMemoryManager(t, CacheConfig{Name: "permissions", Monitor: monitor})
+	ctx := context.Background()
+	m.GetOrLoad(ctx, "role:admin", LoadOptions{}, func(context.Context) ([]byte, error) { return []byte("*"), nil })
+	m.GetOrLoad(ctx, "role:admin", LoadOptions{}, func(context.Context) ([]byte, error) { return []byte("*"), nil })
+
+	out, err := monitor.Export(ctx, monitoring.ExportFormatPrometheus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`cache_misses_total{cache="permissions"`, `cache_hits_total{cache="permissions"`, `tier="local"`, `cache_loads_total{cache="permissions"`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("exported metrics missing %s:\n%s", want, out)
+		}
+	}
+}
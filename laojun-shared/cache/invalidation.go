@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Lua 脚本保证单个键上读改写的原子性
+//
+// 键布局：
+//
+//	<prefix><key>      哈希 v=值 f=新鲜截止时间 e=过期时间（毫秒时间戳），带过期时间
+//	<prefix>tag:<tag>  关联该标签的Redis键集合
+//
+// 条目和标签集合在集群中通常位于不同槽位，每个脚本只访问一个键。
+const (
+	// setLua 写入条目并设置过期时间
+	setLua = `redis.call("HSET", KEYS[1], "v", ARGV[1], "f", ARGV[2], "e", ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return 1`
+
+	// tagLua 将条目加入标签集合，标签集合的过期时间不短于条目
+	tagLua = `redis.call("SADD", KEYS[1], ARGV[1])
+if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 1`
+
+	// popTagLua 删除标签集合，返回其中的键
+	popTagLua = `local keys = redis.call("SMEMBERS", KEYS[1])
+redis.call("DEL", KEYS[1])
+return keys`
+)
+
+var (
+	setScript    = redis.NewScript(setLua)
+	tagScript    = redis.NewScript(tagLua)
+	popTagScript = redis.NewScript(popTagLua)
+)
+
+// invalidation 广播给其他副本的失效消息
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// InvalidateTags 删除关联了任一标签的条目并通知其他副本
+func (m *Manager) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	var keys []string
+	if m.local != nil {
+		keys = m.local.deleteTags(tags...)
+	}
+
+	if m.remote != nil {
+		var redisKeys []string
+		for _, tag := range tags {
+			members, err := popTagScript.Run(ctx, m.remote, []string{m.tagKey(tag)}).StringSlice()
+			if err != nil && err != redis.Nil {
+				return fmt.Errorf("failed to invalidate cache tags: %w", err)
+			}
+			redisKeys = append(redisKeys, members...)
+		}
+
+		deleted, err := m.deleteRedisKeys(ctx, redisKeys)
+		if err != nil {
+			return fmt.Errorf("failed to invalidate cache tags: %w", err)
+		}
+		// 从Redis回填的本地副本没有标签索引，按Redis删除的键清理
+		keys = keys[:0]
+		for _, redisKey := range deleted {
+			keys = append(keys, strings.TrimPrefix(redisKey, m.config.KeyPrefix))
+		}
+		if m.local != nil {
+			m.local.delete(keys...)
+		}
+		m.broadcast(ctx, invalidation{Keys: keys, Tags: tags})
+	}
+
+	for _, key := range keys {
+		m.group.Forget(key)
+	}
+	m.metrics.invalidated(ctx, len(keys))
+	return nil
+}
+
+// deleteRedisKeys 逐个删除键，返回实际删除的键。键可能位于不同槽位，不能用一条 DEL 删除
+func (m *Manager) deleteRedisKeys(ctx context.Context, redisKeys []string) ([]string, error) {
+	if len(redisKeys) == 0 {
+		return nil, nil
+	}
+
+	cmds := make([]*redis.IntCmd, len(redisKeys))
+	if _, err := m.remote.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range redisKeys {
+			cmds[i] = pipe.Del(ctx, key)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	for i, cmd := range cmds {
+		if cmd.Val() == 1 {
+			deleted = append(deleted, redisKeys[i])
+		}
+	}
+	return deleted, nil
+}
+
+// broadcast 发布失效消息，失败时其他副本的本地副本最多保留 LocalTTL。
+// 未启用多级缓存的副本也发布，使共享同一键空间的多级缓存副本能及时失效
+func (m *Manager) broadcast(ctx context.Context, msg invalidation) {
+	msg.Origin = m.origin
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if err := m.remote.Publish(ctx, m.config.InvalidationChannel, data).Err(); err != nil {
+		m.logger.Warn("Failed to broadcast cache invalidation", zap.Strings("keys", msg.Keys), zap.Error(err))
+	}
+}
+
+// subscribe 订阅失效频道，确认订阅成功后在后台处理消息
+func (m *Manager) subscribe() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	m.pubsub = m.remote.Subscribe(ctx, m.config.InvalidationChannel)
+	if _, err := m.pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", m.config.InvalidationChannel, err)
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for message := range m.pubsub.Channel() {
+			m.handleInvalidation(message.Payload)
+		}
+	}()
+	return nil
+}
+
+func (m *Manager) handleInvalidation(payload string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		m.logger.Warn("Invalid cache invalidation message", zap.Error(err))
+		return
+	}
+	if msg.Origin == m.origin {
+		return
+	}
+
+	m.local.delete(msg.Keys...)
+	if len(msg.Tags) > 0 {
+		m.local.deleteTags(msg.Tags...)
+	}
+	for _, key := range msg.Keys {
+		m.group.Forget(key)
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// LoadFunc 缓存未命中时加载值
+type LoadFunc func(ctx context.Context) ([]byte, error)
+
+// LoadOptions GetOrLoad 选项
+type LoadOptions struct {
+	// TTL 值保持新鲜的时长，<=0 时使用 DefaultExpiration
+	TTL time.Duration
+	// StaleTTL TTL 之后继续返回旧值的时长，期间后台刷新；0 表示过期后同步加载
+	StaleTTL time.Duration
+	// Tags 关联的标签
+	Tags []string
+}
+
+// GetOrLoad 获取值，未命中时调用 load 加载并写入缓存。
+//
+// 同一键的并发加载只执行一次；值过期但仍在 StaleTTL 内时立即返回旧值并在后台刷新。
+// 加载不随 ctx 取消（调用方放弃等待不影响其他等待者），超时由 LoadTimeout 控制。
+// 读取Redis失败时直接加载，写入失败时仍返回加载的值
+func (m *Manager) GetOrLoad(ctx context.Context, key string, opts LoadOptions, load LoadFunc) ([]byte, error) {
+	e, found, err := m.lookup(ctx, key)
+	if err != nil {
+		m.logger.Warn("Cache read failed, loading from source", zap.String("key", key), zap.Error(err))
+	}
+
+	if found {
+		if time.Now().Before(e.freshUntil) {
+			return e.value, nil
+		}
+		m.metrics.stale(ctx)
+		m.load(ctx, key, opts, load)
+		return e.value, nil
+	}
+
+	m.metrics.miss(ctx)
+	select {
+	case result := <-m.load(ctx, key, opts, load):
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load 合并同一键的并发加载，返回的通道在加载完成时收到结果
+func (m *Manager) load(ctx context.Context, key string, opts LoadOptions, load LoadFunc) <-chan singleflight.Result {
+	return m.group.DoChan(key, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.config.LoadTimeout)
+		defer cancel()
+
+		start := time.Now()
+		value, err := load(ctx)
+		m.metrics.loaded(ctx, time.Since(start), err)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.store(ctx, key, value, opts.TTL, opts.StaleTTL, opts.Tags); err != nil {
+			m.logger.Warn("Failed to store loaded value", zap.String("key", key), zap.Error(err))
+		}
+		return value, nil
+	})
+}
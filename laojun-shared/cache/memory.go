@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// memoryCache 带TTL的进程内LRU缓存，维护标签到键的索引
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+	tags       map[string]map[string]struct{}
+	onEvict    func()
+}
+
+type memoryItem struct {
+	key   string
+	entry entry
+	tags  []string
+}
+
+func newMemoryCache(maxEntries int, onEvict func()) *memoryCache {
+	return &memoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		tags:       make(map[string]map[string]struct{}),
+		onEvict:    onEvict,
+	}
+}
+
+// get 返回未过期的条目并将其移到队首
+func (c *memoryCache) get(key string, now time.Time) (entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return entry{}, false
+	}
+	item := elem.Value.(*memoryItem)
+	if !item.entry.expiresAt.IsZero() && !now.Before(item.entry.expiresAt) {
+		c.removeElement(elem)
+		return entry{}, false
+	}
+	c.ll.MoveToFront(elem)
+	return item.entry, true
+}
+
+// set 写入条目，超出容量时淘汰最久未使用的条目
+func (c *memoryCache) set(key string, e entry, tags []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+
+	item := &memoryItem{key: key, entry: e, tags: tags}
+	c.items[key] = c.ll.PushFront(item)
+	for _, tag := range tags {
+		keys := c.tags[tag]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		c.removeElement(c.ll.Back())
+		if c.onEvict != nil {
+			c.onEvict()
+		}
+	}
+}
+
+// delete 删除键，返回删除的数量
+func (c *memoryCache) delete(keys ...string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for _, key := range keys {
+		if elem, ok := c.items[key]; ok {
+			c.removeElement(elem)
+			removed++
+		}
+	}
+	return removed
+}
+
+// deleteTags 删除关联了任一标签的键，返回删除的键
+func (c *memoryCache) deleteTags(tags ...string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var removed []string
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			if elem, ok := c.items[key]; ok {
+				c.removeElement(elem)
+				removed = append(removed, key)
+			}
+		}
+		delete(c.tags, tag)
+	}
+	return removed
+}
+
+// purgeExpired 删除已过期的条目
+func (c *memoryCache) purgeExpired(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for elem := c.ll.Back(); elem != nil; {
+		prev := elem.Prev()
+		item := elem.Value.(*memoryItem)
+		if !item.entry.expiresAt.IsZero() && !now.Before(item.entry.expiresAt) {
+			c.removeElement(elem)
+			removed++
+		}
+		elem = prev
+	}
+	return removed
+}
+
+func (c *memoryCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *memoryCache) removeElement(elem *list.Element) {
+	item := elem.Value.(*memoryItem)
+	c.ll.Remove(elem)
+	delete(c.items, item.key)
+	for _, tag := range item.tags {
+		if keys := c.tags[tag]; keys != nil {
+			delete(keys, item.key)
+			if len(keys) == 0 {
+				delete(c.tags, tag)
+			}
+		}
+	}
+}
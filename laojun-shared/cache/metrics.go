@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/monitoring"
+)
+
+// 指标名称
+const (
+	MetricHits          = "cache_hits_total"
+	MetricMisses        = "cache_misses_total"
+	MetricStaleHits     = "cache_stale_hits_total"
+	MetricLoads         = "cache_loads_total"
+	MetricLoadErrors    = "cache_load_errors_total"
+	MetricLoadDuration  = "cache_load_duration_seconds"
+	MetricEvictions     = "cache_evictions_total"
+	MetricInvalidations = "cache_invalidations_total"
+)
+
+// 命中层级
+const (
+	tierLocal = "local"
+	tierRedis = "redis"
+)
+
+// Stats 缓存统计
+type Stats struct {
+	LocalHits     uint64 `json:"local_hits"`
+	RedisHits     uint64 `json:"redis_hits"`
+	Misses        uint64 `json:"misses"`
+	StaleHits     uint64 `json:"stale_hits"`
+	Loads         uint64 `json:"loads"`
+	LoadErrors    uint64 `json:"load_errors"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+	LocalEntries  int    `json:"local_entries"`
+}
+
+// HitRatio 命中率（旧值命中也计为命中）
+func (s Stats) HitRatio() float64 {
+	hits := s.LocalHits + s.RedisHits
+	if hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+s.Misses)
+}
+
+// Stats 返回缓存统计
+func (m *Manager) Stats() Stats {
+	stats := Stats{
+		LocalHits:     m.metrics.localHits.Load(),
+		RedisHits:     m.metrics.redisHits.Load(),
+		Misses:        m.metrics.misses.Load(),
+		StaleHits:     m.metrics.staleHits.Load(),
+		Loads:         m.metrics.loads.Load(),
+		LoadErrors:    m.metrics.loadErrors.Load(),
+		Evictions:     m.metrics.evictions.Load(),
+		Invalidations: m.metrics.invalidations.Load(),
+	}
+	if m.local != nil {
+		stats.LocalEntries = m.local.len()
+	}
+	return stats
+}
+
+// metrics 统计计数并上报到 monitoring.Monitor
+type metrics struct {
+	name    string
+	monitor monitoring.Monitor
+
+	localHits     atomic.Uint64
+	redisHits     atomic.Uint64
+	misses        atomic.Uint64
+	staleHits     atomic.Uint64
+	loads         atomic.Uint64
+	loadErrors    atomic.Uint64
+	evictions     atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+func newMetrics(name string, monitor monitoring.Monitor) *metrics {
+	if name == "" {
+		name = "default"
+	}
+	return &metrics{name: name, monitor: monitor}
+}
+
+func (m *metrics) hit(ctx context.Context, tier string) {
+	if tier == tierLocal {
+		m.localHits.Add(1)
+	} else {
+		m.redisHits.Add(1)
+	}
+	if m.monitor != nil {
+		m.monitor.IncrementCounter(ctx, MetricHits, map[string]string{"cache": m.name, "tier": tier})
+	}
+}
+
+func (m *metrics) miss(ctx context.Context) {
+	m.misses.Add(1)
+	m.increment(ctx, MetricMisses)
+}
+
+func (m *metrics) stale(ctx context.Context) {
+	m.staleHits.Add(1)
+	m.increment(ctx, MetricStaleHits)
+}
+
+func (m *metrics) loaded(ctx context.Context, duration time.Duration, err error) {
+	m.loads.Add(1)
+	m.increment(ctx, MetricLoads)
+	if err != nil {
+		m.loadErrors.Add(1)
+		m.increment(ctx, MetricLoadErrors)
+	}
+	if m.monitor != nil {
+		m.monitor.RecordHistogram(ctx, MetricLoadDuration, duration.Seconds(), map[string]string{"cache": m.name})
+	}
+}
+
+// evicted 作为 memoryCache 的淘汰回调，在持有缓存锁时调用
+func (m *metrics) evicted() {
+	m.evictions.Add(1)
+	m.increment(context.Background(), MetricEvictions)
+}
+
+func (m *metrics) invalidated(ctx context.Context, n int) {
+	if n == 0 {
+		return
+	}
+	m.invalidations.Add(uint64(n))
+	if m.monitor != nil {
+		m.monitor.AddCounter(ctx, MetricInvalidations, float64(n), map[string]string{"cache": m.name})
+	}
+}
+
+func (m *metrics) increment(ctx context.Context, name string) {
+	if m.monitor != nil {
+		m.monitor.IncrementCounter(ctx, name, map[string]string{"cache": m.name})
+	}
+}
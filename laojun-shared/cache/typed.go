@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Get 获取JSON编码的值并解码为 T，键不存在时返回 ErrNotFound
+func Get[T any](ctx context.Context, m *Manager, key string) (T, error) {
+	var value T
+	data, err := m.GetBytes(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to unmarshal cache value: %w", err)
+	}
+	return value, nil
+}
+
+// Set 以JSON编码写入值，可关联标签
+func Set[T any](ctx context.Context, m *Manager, key string, value T, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache value: %w", err)
+	}
+	return m.store(ctx, key, data, ttl, 0, tags)
+}
+
+// GetOrLoad 类型化的 Manager.GetOrLoad，值以JSON编码缓存
+//
+//	user, err := cache.GetOrLoad(ctx, c, "user:"+id, cache.LoadOptions{TTL: time.Minute, Tags: []string{"users"}},
+//		func(ctx context.Context) (*User, error) { return repo.FindUser(ctx, id) })
+func GetOrLoad[T any](ctx context.Context, m *Manager, key string, opts LoadOptions, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	data, err := m.GetOrLoad(ctx, key, opts, func(ctx context.Context) ([]byte, error) {
+		loaded, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(loaded)
+	})
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to unmarshal cache value: %w", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,103 @@
+package chaos
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrAdminAuthRequired 注册管理接口时既没有传入认证中间件也没有配置 AdminToken
+var ErrAdminAuthRequired = errors.New("chaos: admin api requires authentication")
+
+// RegisterAdminRoutes 在 r 下注册 /chaos 管理接口：
+//
+//	GET    /chaos/rules      列出规则
+//	POST   /chaos/rules      添加规则
+//	DELETE /chaos/rules/:id  删除规则
+//	DELETE /chaos/rules      清空规则
+//
+// auth 为空时使用 Config.AdminToken 进行令牌认证；未启用故障注入时不注册任何路由。
+// 管理接口自身不会被中间件注入故障。
+func (i *Injector) RegisterAdminRoutes(r gin.IRouter, auth ...gin.HandlerFunc) error {
+	if !i.Enabled() {
+		return nil
+	}
+	if len(auth) == 0 {
+		if i.config.AdminToken == "" {
+			return ErrAdminAuthRequired
+		}
+		auth = []gin.HandlerFunc{TokenAuth(i.config.AdminToken)}
+	}
+
+	group := r.Group("/chaos", auth...)
+	i.mu.Lock()
+	i.adminPath = strings.TrimSuffix(group.BasePath(), "/")
+	i.mu.Unlock()
+
+	group.GET("/rules", i.listRules)
+	group.POST("/rules", i.addRule)
+	group.DELETE("/rules/:id", i.removeRule)
+	group.DELETE("/rules", i.clearRules)
+	return nil
+}
+
+// TokenAuth 校验 Authorization: Bearer <token> 或 X-Chaos-Token 请求头
+func TokenAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Chaos-Token")
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			provided = bearer
+		}
+		if provided == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "Invalid chaos admin token",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (i *Injector) listRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"rules": i.Rules()})
+}
+
+func (i *Injector) addRule(c *gin.Context) {
+	var rule Rule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": err.Error(),
+		})
+		return
+	}
+	created, err := i.AddRule(rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_rule",
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (i *Injector) removeRule(c *gin.Context) {
+	if !i.RemoveRule(c.Param("id")) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "Rule not found",
+		})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (i *Injector) clearRules(c *gin.Context) {
+	i.Clear()
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,6 @@
+//go:build !chaos
+
+package chaos
+
+// BuildEnabled 使用 -tags chaos 构建时为 true，此时无需在配置中开启故障注入
+const BuildEnabled = false
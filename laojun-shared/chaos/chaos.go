@@ -0,0 +1,441 @@
+package chaos
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrDisabled 未启用故障注入
+	ErrDisabled = errors.New("chaos: fault injection is disabled")
+	// ErrInvalidRule 规则不合法
+	ErrInvalidRule = errors.New("chaos: invalid rule")
+	// ErrInjected 注入的错误，Redis 和数据库钩子返回的错误都包装了它
+	ErrInjected = errors.New("chaos: injected fault")
+	// ErrAborted 注入的连接中断
+	ErrAborted = errors.New("chaos: connection aborted")
+)
+
+// Kind 故障注入点
+type Kind string
+
+const (
+	KindHTTP   Kind = "http"   // gin 中间件处理的入站请求
+	KindClient Kind = "client" // HTTP 客户端发出的请求
+	KindRedis  Kind = "redis"  // Redis 命令
+	KindDB     Kind = "db"     // 数据库操作
+)
+
+// Config 故障注入配置；未使用 chaos 标签构建且 Enabled 为 false 时所有注入点都不生效
+type Config struct {
+	Enabled    bool          `json:"enabled"`
+	AdminToken string        `json:"admin_token"` // 管理接口的访问令牌
+	DefaultTTL time.Duration `json:"default_ttl"` // 规则未指定有效期时使用，默认 5 分钟
+	MaxTTL     time.Duration `json:"max_ttl"`     // 规则最长有效期，默认 1 小时
+}
+
+// Duration 支持 "200ms" 形式的 JSON 时长
+type Duration time.Duration
+
+// MarshalJSON 输出为字符串
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON 接受字符串或纳秒数
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = Duration(n)
+		return nil
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Window 每日生效时间段（本地时间），To 早于 From 时跨越午夜
+type Window struct {
+	From string `json:"from"` // 15:04
+	To   string `json:"to"`
+}
+
+// Fault 注入的故障，可以组合使用
+type Fault struct {
+	Delay     Duration `json:"delay,omitempty"`     // 固定延迟
+	Jitter    Duration `json:"jitter,omitempty"`    // 额外的随机延迟上限
+	Status    int      `json:"status,omitempty"`    // http/client：直接返回的错误状态码
+	Error     string   `json:"error,omitempty"`     // redis/db：返回的错误信息；http/client：响应中的错误信息
+	Abort     bool     `json:"abort,omitempty"`     // 中断连接
+	Bandwidth int      `json:"bandwidth,omitempty"` // 响应体限速，字节/秒
+}
+
+// Rule 故障注入规则
+type Rule struct {
+	ID         string            `json:"id"`
+	Kind       Kind              `json:"kind"`
+	Host       string            `json:"host,omitempty"`       // client：目标主机，支持 * 通配
+	Route      string            `json:"route,omitempty"`      // http/client：路由或路径，以 * 结尾时按前缀匹配；redis：命令名；db：表名
+	Methods    []string          `json:"methods,omitempty"`    // http/client：HTTP 方法；db：create、query、update、delete、row、raw
+	Headers    map[string]string `json:"headers,omitempty"`    // 请求头须等于给定值，值为 * 时只要求存在
+	Percentage float64           `json:"percentage,omitempty"` // 注入比例 0-100，0 表示全部注入
+	Window     *Window           `json:"window,omitempty"`
+	Fault      Fault             `json:"fault"`
+	TTL        Duration          `json:"ttl,omitempty"`        // 有效期，与 ExpiresAt 二选一
+	StartsAt   time.Time         `json:"starts_at,omitempty"`  // 生效时间，为空时立即生效
+	ExpiresAt  time.Time         `json:"expires_at,omitempty"` // 过期时间，过期后自动删除
+	CreatedAt  time.Time         `json:"created_at"`
+	Hits       uint64            `json:"hits"` // 已注入次数
+}
+
+// target 一次可能被注入故障的调用
+type target struct {
+	kind   Kind
+	host   string
+	route  string
+	path   string
+	method string
+	header http.Header
+}
+
+type ruleState struct {
+	Rule
+	hits atomic.Uint64
+}
+
+// Injector 保存故障规则，供中间件、HTTP 客户端、Redis 和数据库钩子共享
+type Injector struct {
+	config  Config
+	enabled bool
+
+	mu        sync.RWMutex
+	rules     []*ruleState
+	adminPath string
+
+	now    func() time.Time
+	random func() float64
+}
+
+// NewInjector 创建故障注入器
+func NewInjector(config Config) *Injector {
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = 5 * time.Minute
+	}
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = time.Hour
+	}
+	return &Injector{
+		config:  config,
+		enabled: BuildEnabled || config.Enabled,
+		now:     time.Now,
+		random:  mathrand.Float64,
+	}
+}
+
+// Enabled 是否启用故障注入，nil 注入器视为未启用
+func (i *Injector) Enabled() bool {
+	return i != nil && i.enabled
+}
+
+// AddRule 添加规则，返回补全 ID 和过期时间后的规则
+func (i *Injector) AddRule(rule Rule) (Rule, error) {
+	if !i.Enabled() {
+		return Rule{}, ErrDisabled
+	}
+	now := i.now()
+	if err := i.normalize(&rule, now); err != nil {
+		return Rule{}, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.pruneLocked(now)
+	for _, existing := range i.rules {
+		if existing.ID == rule.ID {
+			return Rule{}, fmt.Errorf("%w: duplicate id %q", ErrInvalidRule, rule.ID)
+		}
+	}
+	i.rules = append(i.rules, &ruleState{Rule: rule})
+	return rule, nil
+}
+
+// RemoveRule 删除规则，规则不存在时返回 false
+func (i *Injector) RemoveRule(id string) bool {
+	if !i.Enabled() {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for idx, rule := range i.rules {
+		if rule.ID == id {
+			i.rules = append(i.rules[:idx], i.rules[idx+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear 删除所有规则
+func (i *Injector) Clear() {
+	if !i.Enabled() {
+		return
+	}
+	i.mu.Lock()
+	i.rules = nil
+	i.mu.Unlock()
+}
+
+// Rules 返回未过期的规则
+func (i *Injector) Rules() []Rule {
+	if !i.Enabled() {
+		return nil
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.pruneLocked(i.now())
+	rules := make([]Rule, 0, len(i.rules))
+	for _, state := range i.rules {
+		rule := state.Rule
+		rule.Hits = state.hits.Load()
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func (i *Injector) normalize(rule *Rule, now time.Time) error {
+	switch rule.Kind {
+	case KindHTTP, KindClient, KindRedis, KindDB:
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidRule, rule.Kind)
+	}
+	fault := rule.Fault
+	if fault.Delay < 0 || fault.Jitter < 0 || fault.Bandwidth < 0 {
+		return fmt.Errorf("%w: negative delay, jitter or bandwidth", ErrInvalidRule)
+	}
+	if fault.Delay == 0 && fault.Jitter == 0 && fault.Status == 0 && fault.Error == "" && !fault.Abort && fault.Bandwidth == 0 {
+		return fmt.Errorf("%w: fault has no effect", ErrInvalidRule)
+	}
+	if fault.Status != 0 && (fault.Status < 400 || fault.Status > 599) {
+		return fmt.Errorf("%w: status must be 4xx or 5xx", ErrInvalidRule)
+	}
+	if rule.Percentage < 0 || rule.Percentage > 100 {
+		return fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidRule)
+	}
+	if rule.Window != nil {
+		if _, err := time.Parse("15:04", rule.Window.From); err != nil {
+			return fmt.Errorf("%w: window from: %v", ErrInvalidRule, err)
+		}
+		if _, err := time.Parse("15:04", rule.Window.To); err != nil {
+			return fmt.Errorf("%w: window to: %v", ErrInvalidRule, err)
+		}
+	}
+	for idx, method := range rule.Methods {
+		rule.Methods[idx] = strings.ToUpper(method)
+	}
+
+	switch {
+	case rule.ExpiresAt.IsZero() && rule.TTL > 0:
+		rule.ExpiresAt = now.Add(time.Duration(rule.TTL))
+	case rule.ExpiresAt.IsZero():
+		rule.ExpiresAt = now.Add(i.config.DefaultTTL)
+	}
+	if !rule.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: rule already expired", ErrInvalidRule)
+	}
+	if rule.ExpiresAt.Sub(now) > i.config.MaxTTL {
+		return fmt.Errorf("%w: ttl exceeds maximum %s", ErrInvalidRule, i.config.MaxTTL)
+	}
+	rule.TTL = 0
+	rule.CreatedAt = now
+	rule.Hits = 0
+	if rule.ID == "" {
+		rule.ID = newRuleID()
+	}
+	return nil
+}
+
+// pruneLocked 删除过期规则，调用方需持有写锁
+func (i *Injector) pruneLocked(now time.Time) {
+	kept := i.rules[:0]
+	for _, rule := range i.rules {
+		if now.Before(rule.ExpiresAt) {
+			kept = append(kept, rule)
+		}
+	}
+	for idx := len(kept); idx < len(i.rules); idx++ {
+		i.rules[idx] = nil
+	}
+	i.rules = kept
+}
+
+// match 返回第一条命中的规则，未命中时返回 nil
+func (i *Injector) match(t target) *Rule {
+	if !i.Enabled() {
+		return nil
+	}
+	now := i.now()
+
+	i.mu.RLock()
+	var matched *ruleState
+	expired := false
+	for _, rule := range i.rules {
+		if !now.Before(rule.ExpiresAt) {
+			expired = true
+			continue
+		}
+		if rule.matches(t, now) {
+			matched = rule
+			break
+		}
+	}
+	i.mu.RUnlock()
+
+	if expired {
+		i.mu.Lock()
+		i.pruneLocked(now)
+		i.mu.Unlock()
+	}
+	if matched == nil {
+		return nil
+	}
+	if matched.Percentage > 0 && matched.Percentage < 100 && i.random()*100 >= matched.Percentage {
+		return nil
+	}
+	matched.hits.Add(1)
+	rule := matched.Rule
+	return &rule
+}
+
+func (r *ruleState) matches(t target, now time.Time) bool {
+	if r.Kind != t.kind {
+		return false
+	}
+	if !r.StartsAt.IsZero() && now.Before(r.StartsAt) {
+		return false
+	}
+	if r.Window != nil && !inWindow(r.Window, now) {
+		return false
+	}
+	if r.Host != "" && !matchPattern(r.Host, t.host) {
+		return false
+	}
+	if r.Route != "" && !matchRoute(r.Route, t) {
+		return false
+	}
+	if len(r.Methods) > 0 && !containsFold(r.Methods, t.method) {
+		return false
+	}
+	for key, want := range r.Headers {
+		got := t.header.Values(key)
+		if len(got) == 0 || want != "*" && got[0] != want {
+			return false
+		}
+	}
+	return true
+}
+
+// matchRoute http 规则同时匹配路由模板（如 /users/:id）和实际路径；redis/db 忽略大小写
+func matchRoute(pattern string, t target) bool {
+	if t.kind == KindRedis || t.kind == KindDB {
+		return strings.EqualFold(pattern, t.route)
+	}
+	return matchPattern(pattern, t.route) || t.path != "" && matchPattern(pattern, t.path)
+}
+
+// matchPattern 以 * 结尾时按前缀匹配，否则按 path.Match 匹配
+func matchPattern(pattern, value string) bool {
+	if pattern == value {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok && !strings.ContainsAny(prefix, "*?[") {
+		return strings.HasPrefix(value, prefix)
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+func inWindow(w *Window, now time.Time) bool {
+	from, _ := time.Parse("15:04", w.From)
+	to, _ := time.Parse("15:04", w.To)
+	minute := now.Hour()*60 + now.Minute()
+	start := from.Hour()*60 + from.Minute()
+	end := to.Hour()*60 + to.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// wait 执行延迟故障，ctx 取消时提前返回
+func (i *Injector) wait(ctx context.Context, fault Fault) error {
+	delay := time.Duration(fault.Delay)
+	if fault.Jitter > 0 {
+		delay += time.Duration(i.random() * float64(fault.Jitter))
+	}
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// inject 供 Redis 和数据库钩子使用：执行延迟并返回需要注入的错误
+func (i *Injector) inject(ctx context.Context, t target) error {
+	rule := i.match(t)
+	if rule == nil {
+		return nil
+	}
+	if err := i.wait(ctx, rule.Fault); err != nil {
+		return err
+	}
+	switch {
+	case rule.Fault.Abort:
+		return fmt.Errorf("%w (rule %s)", ErrAborted, rule.ID)
+	case rule.Fault.Error != "":
+		return fmt.Errorf("%w: %s (rule %s)", ErrInjected, rule.Fault.Error, rule.ID)
+	}
+	return nil
+}
+
+func newRuleID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
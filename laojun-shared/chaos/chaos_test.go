@@ -0,0 +1,380 @@
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestInjector(t *testing.T) *Injector {
+	t.Helper()
+	injector := NewInjector(Config{Enabled: true, AdminToken: "secret", MaxTTL: time.Hour})
+	injector.random = func() float64 { return 0.5 }
+	return injector
+}
+
+func mustAdd(t *testing.T, injector *Injector, rule Rule) Rule {
+	t.Helper()
+	added, err := injector.AddRule(rule)
+	if err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	return added
+}
+
+func newTestRouter(injector *Injector) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(injector))
+	router.GET("/api/v1/users/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("x", 300))
+	})
+	router.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	return router
+}
+
+func serve(router http.Handler, method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestDisabled(t *testing.T) {
+	if BuildEnabled {
+		t.Skip("built with chaos tag")
+	}
+	injector := NewInjector(Config{})
+	if injector.Enabled() {
+		t.Fatal("injector should be disabled by default")
+	}
+	if _, err := injector.AddRule(Rule{Kind: KindHTTP, Fault: Fault{Status: 500}}); !errors.Is(err, ErrDisabled) {
+		t.Errorf("AddRule() error = %v, want ErrDisabled", err)
+	}
+	if transport := NewTransport(injector, http.DefaultTransport); transport != http.DefaultTransport {
+		t.Error("disabled transport should return base")
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := injector.RegisterAdminRoutes(router); err != nil {
+		t.Fatal(err)
+	}
+	if len(router.Routes()) != 0 {
+		t.Errorf("admin routes registered while disabled: %v", router.Routes())
+	}
+}
+
+func TestRuleValidationAndExpiry(t *testing.T) {
+	injector := newTestInjector(t)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.Local)
+	injector.now = func() time.Time { return now }
+
+	invalid := []Rule{
+		{Kind: "mq", Fault: Fault{Status: 500}},
+		{Kind: KindHTTP},
+		{Kind: KindHTTP, Fault: Fault{Status: 200}},
+		{Kind: KindHTTP, Fault: Fault{Status: 500}, Percentage: 150},
+		{Kind: KindHTTP, Fault: Fault{Status: 500}, Window: &Window{From: "9am", To: "10:00"}},
+		{Kind: KindHTTP, Fault: Fault{Status: 500}, TTL: Duration(2 * time.Hour)},
+	}
+	for _, rule := range invalid {
+		if _, err := injector.AddRule(rule); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("AddRule(%+v) error = %v, want ErrInvalidRule", rule, err)
+		}
+	}
+
+	short := mustAdd(t, injector, Rule{Kind: KindRedis, Fault: Fault{Error: "boom"}, TTL: Duration(time.Minute)})
+	long := mustAdd(t, injector, Rule{Kind: KindRedis, Fault: Fault{Error: "boom"}})
+	if short.ID == "" || !short.ExpiresAt.Equal(now.Add(time.Minute)) || !long.ExpiresAt.Equal(now.Add(5*time.Minute)) {
+		t.Fatalf("rules = %+v, %+v", short, long)
+	}
+
+	now = now.Add(2 * time.Minute)
+	rules := injector.Rules()
+	if len(rules) != 1 || rules[0].ID != long.ID {
+		t.Errorf("rules after expiry = %+v", rules)
+	}
+	now = now.Add(5 * time.Minute)
+	if err := injector.inject(context.Background(), target{kind: KindRedis, route: "get"}); err != nil {
+		t.Errorf("expired rule still injected: %v", err)
+	}
+}
+
+func TestMatching(t *testing.T) {
+	injector := newTestInjector(t)
+	now := time.Date(2026, 1, 1, 23, 30, 0, 0, time.Local)
+	injector.now = func() time.Time { return now }
+	injector.config.MaxTTL = 24 * time.Hour
+	mustAdd(t, injector, Rule{
+		Kind:    KindHTTP,
+		TTL:     Duration(3 * time.Hour),
+		Route:   "/api/v1/users/:id",
+		Methods: []string{"get"},
+		Headers: map[string]string{"X-Canary": "true"},
+		Window:  &Window{From: "23:00", To: "01:00"},
+		Fault:   Fault{Status: http.StatusServiceUnavailable},
+	})
+	mustAdd(t, injector, Rule{Kind: KindHTTP, Route: "/ping*", Percentage: 40, TTL: Duration(3 * time.Hour), Fault: Fault{Status: http.StatusBadGateway}})
+	router := newTestRouter(injector)
+
+	canary := http.Header{"X-Canary": {"true"}}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header http.Header
+		at     time.Time
+		want   int
+	}{
+		{"matches route template", http.MethodGet, "/api/v1/users/7", canary, now, http.StatusServiceUnavailable},
+		{"missing header", http.MethodGet, "/api/v1/users/7", nil, now, http.StatusOK},
+		{"wrong method", http.MethodPost, "/api/v1/users/7", canary, now, http.StatusNotFound},
+		{"window wraps midnight", http.MethodGet, "/api/v1/users/7", canary, now.Add(time.Hour), http.StatusServiceUnavailable},
+		{"outside window", http.MethodGet, "/api/v1/users/7", canary, now.Add(2 * time.Hour), http.StatusOK},
+		{"percentage not reached", http.MethodGet, "/ping", nil, now, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector.now = func() time.Time { return tt.at }
+			w := serve(router, tt.method, tt.path, tt.header)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+
+	injector.now = func() time.Time { return now }
+	injector.random = func() float64 { return 0.1 }
+	w := serve(router, http.MethodGet, "/ping", nil)
+	if w.Code != http.StatusBadGateway || w.Header().Get(RuleHeader) == "" {
+		t.Errorf("status = %d, rule header = %q", w.Code, w.Header().Get(RuleHeader))
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] != "chaos_injected" {
+		t.Errorf("body = %s", w.Body.String())
+	}
+
+	hits := 0
+	for _, rule := range injector.Rules() {
+		hits += int(rule.Hits)
+	}
+	if hits != 3 {
+		t.Errorf("hits = %d, want 3", hits)
+	}
+}
+
+func TestMiddlewareDelayAbortAndBandwidth(t *testing.T) {
+	injector := newTestInjector(t)
+	server := httptest.NewServer(newTestRouter(injector))
+	defer server.Close()
+
+	delay := mustAdd(t, injector, Rule{Kind: KindHTTP, Route: "/ping", Fault: Fault{Delay: Duration(50 * time.Millisecond)}})
+	start := time.Now()
+	resp, err := http.Get(server.URL + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || resp.StatusCode != http.StatusOK {
+		t.Errorf("delay: status = %d, elapsed = %s", resp.StatusCode, elapsed)
+	}
+	injector.RemoveRule(delay.ID)
+
+	mustAdd(t, injector, Rule{Kind: KindHTTP, Route: "/ping", Fault: Fault{Abort: true}})
+	if resp, err := http.Get(server.URL + "/ping"); err == nil {
+		resp.Body.Close()
+		t.Errorf("abort: expected connection error, got status %d", resp.StatusCode)
+	}
+
+	// 300 字节，每秒 1000 字节，约 300ms
+	mustAdd(t, injector, Rule{Kind: KindHTTP, Route: "/api/v1/users/*", Fault: Fault{Bandwidth: 1000}})
+	start = time.Now()
+	resp, err = http.Get(server.URL + "/api/v1/users/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if elapsed := time.Since(start); len(data) != 300 || elapsed < 250*time.Millisecond {
+		t.Errorf("bandwidth: read %d bytes in %s", len(data), elapsed)
+	}
+}
+
+func TestAdminAPI(t *testing.T) {
+	injector := newTestInjector(t)
+	router := newTestRouter(injector)
+	if err := injector.RegisterAdminRoutes(router.Group("/internal")); err != nil {
+		t.Fatal(err)
+	}
+	// 即使规则匹配所有路径，管理接口也不受影响
+	mustAdd(t, injector, Rule{Kind: KindHTTP, Route: "/*", Fault: Fault{Status: http.StatusInternalServerError}})
+
+	auth := http.Header{"Authorization": {"Bearer secret"}}
+	if w := serve(router, http.MethodGet, "/internal/chaos/rules", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/internal/chaos/rules",
+		strings.NewReader(`{"kind":"http","route":"/ping","fault":{"delay":"10ms","status":503},"ttl":"1m"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Chaos-Token", "secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", w.Code, w.Body.String())
+	}
+	var created Rule
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Fault.Delay != Duration(10*time.Millisecond) || time.Until(created.ExpiresAt) > time.Minute {
+		t.Errorf("created = %+v", created)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/internal/chaos/rules", strings.NewReader(`{"kind":"http","fault":{}}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Chaos-Token", "secret")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid rule status = %d", w.Code)
+	}
+
+	w = serve(router, http.MethodGet, "/internal/chaos/rules", auth)
+	var list struct {
+		Rules []Rule `json:"rules"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list.Rules) != 2 {
+		t.Fatalf("list = %d %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(router, http.MethodDelete, "/internal/chaos/rules/"+created.ID, auth); w.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d", w.Code)
+	}
+	if w := serve(router, http.MethodDelete, "/internal/chaos/rules/"+created.ID, auth); w.Code != http.StatusNotFound {
+		t.Errorf("second delete status = %d", w.Code)
+	}
+	if w := serve(router, http.MethodDelete, "/internal/chaos/rules", auth); w.Code != http.StatusNoContent || len(injector.Rules()) != 0 {
+		t.Errorf("clear status = %d, rules = %d", w.Code, len(injector.Rules()))
+	}
+
+	if err := NewInjector(Config{Enabled: true}).RegisterAdminRoutes(gin.New()); !errors.Is(err, ErrAdminAuthRequired) {
+		t.Errorf("RegisterAdminRoutes() without token error = %v", err)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	injector := newTestInjector(t)
+	server := httptest.NewServer(newTestRouter(injector))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport(injector, nil)}
+
+	mustAdd(t, injector, Rule{Kind: KindClient, Host: "127.0.0.1:*", Route: "/ping", Fault: Fault{Status: http.StatusTooManyRequests, Error: "slow down"}})
+	mustAdd(t, injector, Rule{Kind: KindClient, Route: "/api/v1/users/2", Fault: Fault{Abort: true}})
+
+	resp, err := client.Get(server.URL + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || !strings.Contains(string(body), "slow down") {
+		t.Errorf("status = %d, body = %s", resp.StatusCode, body)
+	}
+
+	if _, err := client.Get(server.URL + "/api/v1/users/2"); !errors.Is(err, ErrAborted) {
+		t.Errorf("abort error = %v", err)
+	}
+
+	resp, err = client.Get(server.URL + "/api/v1/users/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unmatched status = %d", resp.StatusCode)
+	}
+}
+
+func TestRedisHook(t *testing.T) {
+	injector := newTestInjector(t)
+	mustAdd(t, injector, Rule{Kind: KindRedis, Route: "GET", Fault: Fault{Error: "READONLY"}})
+	mustAdd(t, injector, Rule{Kind: KindRedis, Route: "incr", Fault: Fault{Abort: true}})
+
+	// 命中规则的命令不会连接 Redis
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+	client.AddHook(NewRedisHook(injector))
+
+	ctx := context.Background()
+	if err := client.Get(ctx, "key").Err(); !errors.Is(err, ErrInjected) || !strings.Contains(err.Error(), "READONLY") {
+		t.Errorf("GET error = %v", err)
+	}
+	if err := client.Set(ctx, "key", 1, 0).Err(); err == nil || errors.Is(err, ErrInjected) {
+		t.Errorf("SET error = %v, want dial error", err)
+	}
+
+	pipe := client.Pipeline()
+	pipe.Set(ctx, "key", 1, 0)
+	incr := pipe.Incr(ctx, "counter")
+	if _, err := pipe.Exec(ctx); !errors.Is(err, ErrAborted) || !errors.Is(incr.Err(), ErrAborted) {
+		t.Errorf("pipeline error = %v, incr error = %v", err, incr.Err())
+	}
+}
+
+type chaosItem struct {
+	ID   uint
+	Name string
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "chaos.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&chaosItem{}); err != nil {
+		t.Fatal(err)
+	}
+
+	injector := newTestInjector(t)
+	if err := db.Use(NewGormPlugin(injector)); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, injector, Rule{Kind: KindDB, Route: "chaos_items", Methods: []string{"create"}, Fault: Fault{Error: "disk full"}})
+	mustAdd(t, injector, Rule{Kind: KindDB, Route: "chaos_items", Methods: []string{"query"}, Fault: Fault{Abort: true}})
+
+	if err := db.Create(&chaosItem{Name: "a"}).Error; !errors.Is(err, ErrInjected) {
+		t.Errorf("create error = %v", err)
+	}
+	var count int64
+	if err := db.Model(&chaosItem{}).Count(&count).Error; !errors.Is(err, ErrAborted) {
+		t.Errorf("query error = %v", err)
+	}
+	if err := db.Exec("DELETE FROM chaos_items").Error; err != nil {
+		t.Errorf("raw error = %v", err)
+	}
+
+	injector.Clear()
+	if err := db.Create(&chaosItem{Name: "b"}).Error; err != nil {
+		t.Errorf("create after clear error = %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package chaos
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// GormPlugin 为 gorm 注入故障，规则的 Route 为表名，Methods 为操作类型
+//
+//	db.Use(chaos.NewGormPlugin(injector))
+type GormPlugin struct {
+	injector *Injector
+}
+
+// NewGormPlugin 创建 gorm 插件
+func NewGormPlugin(injector *Injector) *GormPlugin {
+	return &GormPlugin{injector: injector}
+}
+
+// Name 实现 gorm.Plugin
+func (p *GormPlugin) Name() string {
+	return "laojun:chaos"
+}
+
+// Initialize 实现 gorm.Plugin，未启用故障注入时不注册回调
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	if !p.injector.Enabled() {
+		return nil
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("*").Register("laojun:chaos", p.callback("create")); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("*").Register("laojun:chaos", p.callback("query")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("*").Register("laojun:chaos", p.callback("update")); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("*").Register("laojun:chaos", p.callback("delete")); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("*").Register("laojun:chaos", p.callback("row")); err != nil {
+		return err
+	}
+	return callbacks.Raw().Before("*").Register("laojun:chaos", p.callback("raw"))
+}
+
+func (p *GormPlugin) callback(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		err := p.injector.inject(db.Statement.Context, target{
+			kind:   KindDB,
+			route:  db.Statement.Table,
+			method: operation,
+		})
+		if err == nil {
+			return
+		}
+		// 中断按连接失效处理，便于调用方验证重试逻辑
+		if errors.Is(err, ErrAborted) {
+			err = fmt.Errorf("%w: %w", err, driver.ErrBadConn)
+		}
+		db.AddError(err)
+	}
+}
@@ -0,0 +1,133 @@
+package chaos
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RuleHeader 注入故障的响应中携带命中的规则 ID，便于排查
+const RuleHeader = "X-Chaos-Rule"
+
+// Middleware gin 故障注入中间件，未启用时直接放行
+func Middleware(injector *Injector) gin.HandlerFunc {
+	if !injector.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		if injector.isAdminPath(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		rule := injector.match(target{
+			kind:   KindHTTP,
+			route:  c.FullPath(),
+			path:   c.Request.URL.Path,
+			method: c.Request.Method,
+			header: c.Request.Header,
+		})
+		if rule == nil {
+			c.Next()
+			return
+		}
+
+		fault := rule.Fault
+		if err := injector.wait(c.Request.Context(), fault); err != nil {
+			c.Abort()
+			return
+		}
+		if fault.Abort {
+			abortConnection(c)
+			return
+		}
+		c.Header(RuleHeader, rule.ID)
+		if fault.Status != 0 {
+			message := fault.Error
+			if message == "" {
+				message = http.StatusText(fault.Status)
+			}
+			c.AbortWithStatusJSON(fault.Status, gin.H{
+				"error":   "chaos_injected",
+				"message": message,
+			})
+			return
+		}
+		if fault.Bandwidth > 0 {
+			c.Writer = &throttledWriter{
+				ResponseWriter: c.Writer,
+				ctx:            c.Request.Context(),
+				bandwidth:      fault.Bandwidth,
+			}
+		}
+		c.Next()
+	}
+}
+
+// abortConnection 不返回任何响应直接关闭连接，无法劫持连接时交给 net/http 中断
+func abortConnection(c *gin.Context) {
+	c.Abort()
+	if conn, _, err := c.Writer.Hijack(); err == nil {
+		conn.Close()
+		return
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// throttledWriter 按带宽限制分块写出响应体
+type throttledWriter struct {
+	gin.ResponseWriter
+	ctx       context.Context
+	bandwidth int
+}
+
+func (w *throttledWriter) Write(data []byte) (int, error) {
+	return throttle(w.ctx, w.bandwidth, data, w.ResponseWriter.Write, w.ResponseWriter.Flush)
+}
+
+func (w *throttledWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// throttle 每次写出约 1/10 秒的数据量，写完后等待相应时间
+func throttle(ctx context.Context, bandwidth int, data []byte, write func([]byte) (int, error), flush func()) (int, error) {
+	chunk := bandwidth / 10
+	if chunk < 1 {
+		chunk = 1
+	}
+	written := 0
+	for written < len(data) {
+		end := written + chunk
+		if end > len(data) {
+			end = len(data)
+		}
+		n, err := write(data[written:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		if flush != nil {
+			flush()
+		}
+		timer := time.NewTimer(time.Duration(n) * time.Second / time.Duration(bandwidth))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return written, ctx.Err()
+		}
+	}
+	return written, nil
+}
+
+func (i *Injector) isAdminPath(path string) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.adminPath != "" && (path == i.adminPath || strings.HasPrefix(path, i.adminPath+"/"))
+}
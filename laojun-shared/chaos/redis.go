@@ -0,0 +1,95 @@
+package chaos
+
+import (
+	"context"
+	"net"
+
+	redisv8 "github.com/go-redis/redis/v8"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook 为 go-redis v9 客户端注入故障，规则的 Route 为命令名
+//
+//	client.AddHook(chaos.NewRedisHook(injector))
+type RedisHook struct {
+	injector *Injector
+}
+
+// NewRedisHook 创建 go-redis v9 钩子
+func NewRedisHook(injector *Injector) *RedisHook {
+	return &RedisHook{injector: injector}
+}
+
+// DialHook 不注入故障
+func (h *RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 为单条命令注入故障
+func (h *RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	if !h.injector.Enabled() {
+		return next
+	}
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.injector.inject(ctx, target{kind: KindRedis, route: cmd.Name()}); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook 管道中任一命令命中规则时整个管道失败
+func (h *RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	if !h.injector.Enabled() {
+		return next
+	}
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := h.injector.inject(ctx, target{kind: KindRedis, route: cmd.Name()}); err != nil {
+				for _, c := range cmds {
+					c.SetErr(err)
+				}
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// RedisV8Hook 是 RedisHook 的 go-redis v8 版本
+type RedisV8Hook struct {
+	injector *Injector
+}
+
+// NewRedisV8Hook 创建 go-redis v8 钩子
+func NewRedisV8Hook(injector *Injector) *RedisV8Hook {
+	return &RedisV8Hook{injector: injector}
+}
+
+// BeforeProcess 返回错误时 go-redis 不再执行命令
+func (h *RedisV8Hook) BeforeProcess(ctx context.Context, cmd redisv8.Cmder) (context.Context, error) {
+	return ctx, h.injector.inject(ctx, target{kind: KindRedis, route: cmd.Name()})
+}
+
+// AfterProcess 无操作
+func (h *RedisV8Hook) AfterProcess(ctx context.Context, cmd redisv8.Cmder) error {
+	return nil
+}
+
+// BeforeProcessPipeline 管道中任一命令命中规则时整个管道失败
+func (h *RedisV8Hook) BeforeProcessPipeline(ctx context.Context, cmds []redisv8.Cmder) (context.Context, error) {
+	for _, cmd := range cmds {
+		if err := h.injector.inject(ctx, target{kind: KindRedis, route: cmd.Name()}); err != nil {
+			return ctx, err
+		}
+	}
+	return ctx, nil
+}
+
+// AfterProcessPipeline 无操作
+func (h *RedisV8Hook) AfterProcessPipeline(ctx context.Context, cmds []redisv8.Cmder) error {
+	return nil
+}
@@ -0,0 +1,123 @@
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Transport 为 HTTP 客户端注入故障的 http.RoundTripper
+type Transport struct {
+	// Base 实际发送请求的传输层，为 nil 时使用 http.DefaultTransport
+	Base     http.RoundTripper
+	Injector *Injector
+}
+
+// NewTransport 包装 base；注入器未启用时直接返回 base，不增加任何开销
+func NewTransport(injector *Injector, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !injector.Enabled() {
+		return base
+	}
+	return &Transport{Base: base, Injector: injector}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	rule := t.Injector.match(target{
+		kind:   KindClient,
+		host:   req.URL.Host,
+		route:  req.URL.Path,
+		method: req.Method,
+		header: req.Header,
+	})
+	if rule == nil {
+		return base.RoundTrip(req)
+	}
+
+	fault := rule.Fault
+	if err := t.Injector.wait(req.Context(), fault); err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	if fault.Abort {
+		closeBody(req)
+		return nil, fmt.Errorf("%w (rule %s)", ErrAborted, rule.ID)
+	}
+	if fault.Status != 0 {
+		closeBody(req)
+		return injectedResponse(req, rule), nil
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || fault.Bandwidth == 0 {
+		return resp, err
+	}
+	resp.Body = &throttledBody{ReadCloser: resp.Body, ctx: req.Context(), bandwidth: fault.Bandwidth}
+	return resp, nil
+}
+
+// injectedResponse 构造与中间件一致的错误响应
+func injectedResponse(req *http.Request, rule *Rule) *http.Response {
+	message := rule.Fault.Error
+	if message == "" {
+		message = http.StatusText(rule.Fault.Status)
+	}
+	body, _ := json.Marshal(map[string]string{"error": "chaos_injected", "message": message})
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set(RuleHeader, rule.ID)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rule.Fault.Status, http.StatusText(rule.Fault.Status)),
+		StatusCode:    rule.Fault.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(string(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// closeBody RoundTripper 即使出错也必须关闭请求体
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// throttledBody 按带宽限制读取响应体
+type throttledBody struct {
+	io.ReadCloser
+	ctx       context.Context
+	bandwidth int
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	limit := b.bandwidth / 10
+	if limit < 1 {
+		limit = 1
+	}
+	if len(p) > limit {
+		p = p[:limit]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if _, waitErr := throttle(b.ctx, b.bandwidth, p[:n], func(chunk []byte) (int, error) { return len(chunk), nil }, nil); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
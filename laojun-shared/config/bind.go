@@ -0,0 +1,512 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// Source 配置值来源，数值越大优先级越高
+type Source int
+
+const (
+	// SourceNone 未被任何来源设置，保持零值
+	SourceNone Source = iota
+	// SourceDefault 结构体 default 标签
+	SourceDefault
+	// SourceFile YAML 配置文件，按 yaml 标签路径匹配
+	SourceFile
+	// SourceDotenv .env 文件，按 env 标签匹配
+	SourceDotenv
+	// SourceEnv 进程环境变量，按 env 标签匹配
+	SourceEnv
+	// SourceCenter 配置中心，按 config 标签匹配
+	SourceCenter
+)
+
+var sourceNames = map[Source]string{
+	SourceNone:    "none",
+	SourceDefault: "default",
+	SourceFile:    "file",
+	SourceDotenv:  "dotenv",
+	SourceEnv:     "env",
+	SourceCenter:  "center",
+}
+
+// String 返回来源名称
+func (s Source) String() string {
+	if name, ok := sourceNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("source(%d)", int(s))
+}
+
+// MarshalText 以名称序列化来源
+func (s Source) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// FieldSource 字段最终取值的来源，Origin 为文件路径、环境变量名或配置中心键
+type FieldSource struct {
+	Source Source `json:"source"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// CenterSource 绑定使用的配置中心，HTTPConfigClient 实现了该接口
+type CenterSource interface {
+	GetConfigs(ctx context.Context, service, environment string) (map[string]*ConfigItem, error)
+}
+
+// centerSubscriber 支持推送变更的配置中心
+type centerSubscriber interface {
+	Subscribe(ctx context.Context, service, environment string, callback ConfigChangeCallback) error
+	Unsubscribe(ctx context.Context, service, environment string) error
+}
+
+// BindOption 配置绑定选项
+type BindOption func(*bindOptions)
+
+type bindOptions struct {
+	files          []string
+	dotenv         []string
+	dotenvSet      bool
+	center         CenterSource
+	service        string
+	environment    string
+	reloadInterval time.Duration
+}
+
+// WithFiles 按顺序读取 YAML 配置文件，后面的文件覆盖前面的，不存在的文件被忽略
+func WithFiles(paths ...string) BindOption {
+	return func(o *bindOptions) {
+		o.files = append(o.files, paths...)
+	}
+}
+
+// WithDotenv 指定读取的 .env 文件，不传路径时不读取 .env
+//
+// 未使用该选项时按 LoadDotenv 的规则查找项目根目录下的 .env、.env.<APP_ENV> 和 .env.local。
+func WithDotenv(paths ...string) BindOption {
+	return func(o *bindOptions) {
+		o.dotenv = paths
+		o.dotenvSet = true
+	}
+}
+
+// WithCenter 从配置中心读取 service/environment 下的配置
+func WithCenter(center CenterSource, service, environment string) BindOption {
+	return func(o *bindOptions) {
+		o.center = center
+		o.service = service
+		o.environment = environment
+	}
+}
+
+// WithReloadInterval 设置 Watch 定期重新读取全部来源的间隔，用于感知文件和环境变量的变化
+func WithReloadInterval(interval time.Duration) BindOption {
+	return func(o *bindOptions) {
+		o.reloadInterval = interval
+	}
+}
+
+// Binding 绑定到结构体 T 的配置
+//
+// 字段值按 default 标签、配置文件、.env、环境变量、配置中心的顺序依次覆盖，
+// 合并后按 validate 标签校验。Reload 重新读取全部来源并原子替换当前配置；
+// 带 reload:"restart" 标签的字段（及其子字段）只在启动时生效，热加载时发生变化会被整体拒绝。
+type Binding[T any] struct {
+	options   bindOptions
+	fields    []*bindField
+	validator *DefaultConfigValidator
+	logger    *logrus.Logger
+
+	reloadMu sync.Mutex
+	state    atomic.Pointer[bindState[T]]
+
+	subMu       sync.RWMutex
+	subscribers map[int]func(old, new *T)
+	nextID      int
+}
+
+type bindState[T any] struct {
+	value   *T
+	sources map[string]FieldSource
+}
+
+// Bind 从全部来源加载配置到 T，T 必须是结构体
+//
+//	type ServerConfig struct {
+//		Port    int           `yaml:"port" env:"SERVER_PORT" config:"server.port" default:"8080" validate:"min=1,max=65535" reload:"restart"`
+//		Timeout time.Duration `yaml:"timeout" env:"SERVER_TIMEOUT" config:"server.timeout" default:"30s"`
+//	}
+//
+//	binding, err := config.Bind[ServerConfig](ctx, config.WithFiles("config.yaml"), config.WithCenter(client, "gateway", "prod"))
+func Bind[T any](ctx context.Context, opts ...BindOption) (*Binding[T], error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: bind target must be a struct, got %s", ErrInvalidConfigType, typ)
+	}
+
+	fields, err := collectFields(typ, nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Binding[T]{
+		fields:      fields,
+		validator:   NewDefaultConfigValidator(),
+		logger:      logrus.New(),
+		subscribers: make(map[int]func(old, new *T)),
+	}
+	for _, opt := range opts {
+		opt(&b.options)
+	}
+
+	// 校验错误由 Bind/Reload 返回，验证器不再记录带字段值的日志
+	b.validator.logger.SetLevel(logrus.ErrorLevel)
+	for _, field := range fields {
+		for _, rule := range field.rules {
+			if err := b.validator.AddRule(ctx, field.path, rule); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	state, err := b.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.state.Store(state)
+	return b, nil
+}
+
+// Get 返回当前配置，返回值在热加载后不会被修改，调用方也不应修改
+func (b *Binding[T]) Get() *T {
+	return b.state.Load().value
+}
+
+// Sources 返回每个字段（以 yaml 路径表示，如 file.max_size）的取值来源
+func (b *Binding[T]) Sources() map[string]FieldSource {
+	sources := b.state.Load().sources
+	result := make(map[string]FieldSource, len(sources))
+	for path, source := range sources {
+		result[path] = source
+	}
+	return result
+}
+
+// Source 返回单个字段的取值来源
+func (b *Binding[T]) Source(path string) FieldSource {
+	return b.state.Load().sources[path]
+}
+
+// Subscribe 注册配置变化回调，返回取消订阅函数
+//
+// 回调在热加载成功且配置发生变化后按 Reload 的顺序同步调用。
+func (b *Binding[T]) Subscribe(callback func(old, new *T)) func() {
+	b.subMu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = callback
+	b.subMu.Unlock()
+
+	return func() {
+		b.subMu.Lock()
+		delete(b.subscribers, id)
+		b.subMu.Unlock()
+	}
+}
+
+// Reload 重新读取全部来源，校验通过后原子替换当前配置并通知订阅者
+//
+// 校验失败或仅重启生效的字段发生变化时返回错误，当前配置保持不变。
+func (b *Binding[T]) Reload(ctx context.Context) error {
+	b.reloadMu.Lock()
+	defer b.reloadMu.Unlock()
+
+	next, err := b.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := b.state.Load()
+	oldRoot := reflect.ValueOf(current.value).Elem()
+	newRoot := reflect.ValueOf(next.value).Elem()
+
+	var restart []string
+	for _, field := range b.fields {
+		if field.restart && !reflect.DeepEqual(field.value(oldRoot).Interface(), field.value(newRoot).Interface()) {
+			restart = append(restart, field.path)
+		}
+	}
+	if len(restart) > 0 {
+		return fmt.Errorf("%w: %s", ErrRestartRequired, strings.Join(restart, ", "))
+	}
+
+	b.state.Store(next)
+	if reflect.DeepEqual(current.value, next.value) {
+		return nil
+	}
+
+	b.subMu.RLock()
+	callbacks := make([]func(old, new *T), 0, len(b.subscribers))
+	for _, callback := range b.subscribers {
+		callbacks = append(callbacks, callback)
+	}
+	b.subMu.RUnlock()
+
+	for _, callback := range callbacks {
+		callback(current.value, next.value)
+	}
+	return nil
+}
+
+// Watch 开始热加载，直到 ctx 结束
+//
+// 配置中心支持订阅时在收到绑定键的变更后重新加载；设置了 WithReloadInterval 时
+// 还会定期重新读取全部来源。热加载失败只记录日志，当前配置保持不变。
+func (b *Binding[T]) Watch(ctx context.Context) error {
+	subscriber, canSubscribe := b.options.center.(centerSubscriber)
+	if !canSubscribe && b.options.reloadInterval <= 0 {
+		return fmt.Errorf("%w: no config source supports watching", ErrUnsupportedOperation)
+	}
+
+	if canSubscribe {
+		keys := make(map[string]bool)
+		for _, field := range b.fields {
+			if field.key != "" {
+				keys[field.key] = true
+			}
+		}
+
+		err := subscriber.Subscribe(ctx, b.options.service, b.options.environment, func(event *ConfigChangeEvent) error {
+			if !keys[event.Key] {
+				return nil
+			}
+			return b.Reload(ctx)
+		})
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			<-ctx.Done()
+			_ = subscriber.Unsubscribe(context.Background(), b.options.service, b.options.environment)
+		}()
+	}
+
+	if b.options.reloadInterval > 0 {
+		go b.reloadLoop(ctx)
+	}
+	return nil
+}
+
+// reloadLoop 定期重新加载，同一个错误只记录一次
+func (b *Binding[T]) reloadLoop(ctx context.Context) {
+	ticker := time.NewTicker(b.options.reloadInterval)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := b.Reload(ctx)
+			if err == nil {
+				lastErr = ""
+				continue
+			}
+			if err.Error() != lastErr {
+				lastErr = err.Error()
+				b.logger.Warnf("Config reload failed: %v", err)
+			}
+		}
+	}
+}
+
+// load 按优先级从低到高依次读取全部来源并校验
+func (b *Binding[T]) load(ctx context.Context) (*bindState[T], error) {
+	value := new(T)
+	root := reflect.ValueOf(value).Elem()
+	sources := make(map[string]FieldSource, len(b.fields))
+
+	apply := func(field *bindField, source FieldSource, set func(reflect.Value) error) error {
+		if err := set(field.value(root)); err != nil {
+			return fmt.Errorf("%w: %s from %s %s: %v", ErrInvalidConfigValue, field.path, source.Source, source.Origin, err)
+		}
+		sources[field.path] = source
+		return nil
+	}
+
+	for _, field := range b.fields {
+		sources[field.path] = FieldSource{}
+		if !field.hasDefault {
+			continue
+		}
+		if err := apply(field, FieldSource{Source: SourceDefault}, func(v reflect.Value) error {
+			return setString(v, field.def)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range b.options.files {
+		doc, err := readYAMLFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		for _, field := range b.fields {
+			node := lookupNode(doc, field.segments)
+			if node == nil {
+				continue
+			}
+			if err := apply(field, FieldSource{Source: SourceFile, Origin: path}, func(v reflect.Value) error {
+				return decodeNode(v, node)
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	dotenv, dotenvOrigins, err := b.readDotenv()
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range b.fields {
+		if raw := dotenv[field.env]; field.env != "" && raw != "" {
+			if err := apply(field, FieldSource{Source: SourceDotenv, Origin: dotenvOrigins[field.env]}, func(v reflect.Value) error {
+				return setString(v, raw)
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 与 getEnv 一致，空值视为未设置
+	for _, field := range b.fields {
+		if raw := os.Getenv(field.env); field.env != "" && raw != "" {
+			if err := apply(field, FieldSource{Source: SourceEnv, Origin: field.env}, func(v reflect.Value) error {
+				return setString(v, raw)
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if b.options.center != nil {
+		items, err := b.options.center.GetConfigs(ctx, b.options.service, b.options.environment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config center %s/%s: %w", b.options.service, b.options.environment, err)
+		}
+		for _, field := range b.fields {
+			item := items[field.key]
+			if field.key == "" || item == nil || item.Value == nil {
+				continue
+			}
+			if err := apply(field, FieldSource{Source: SourceCenter, Origin: field.key}, func(v reflect.Value) error {
+				return setValue(v, item.Value)
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := b.validate(ctx, root); err != nil {
+		return nil, err
+	}
+	return &bindState[T]{value: value, sources: sources}, nil
+}
+
+// validate 用 validate 标签生成的规则校验全部字段
+func (b *Binding[T]) validate(ctx context.Context, root reflect.Value) error {
+	var errs []error
+	for _, field := range b.fields {
+		if len(field.rules) == 0 {
+			continue
+		}
+		if err := b.validator.Validate(ctx, field.path, validationString(field.value(root))); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.path, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrConfigValidationFailed, errors.Join(errs...))
+	}
+	return nil
+}
+
+// readDotenv 读取 .env 文件，返回合并后的变量及每个变量所在的文件
+func (b *Binding[T]) readDotenv() (map[string]string, map[string]string, error) {
+	paths := b.options.dotenv
+	if !b.options.dotenvSet {
+		paths = nil
+		for _, path := range dotenvCandidates() {
+			if _, err := os.Stat(path); err == nil {
+				paths = append(paths, path)
+			}
+		}
+		// 与 LoadDotenv 一致，项目根目录没有 .env 时尝试当前工作目录
+		if len(paths) == 0 {
+			if _, err := os.Stat(".env"); err == nil {
+				paths = []string{".env"}
+			}
+		}
+	}
+
+	vars := make(map[string]string)
+	origins := make(map[string]string)
+	lookup := func(key string) string {
+		if val, ok := vars[key]; ok {
+			return val
+		}
+		return os.Getenv(key)
+	}
+
+	for _, path := range paths {
+		fileVars, err := parseEnvFile(path, lookup)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read env file %s: %w", path, err)
+		}
+		for key, val := range fileVars {
+			vars[key] = val
+			origins[key] = path
+		}
+	}
+	return vars, origins, nil
+}
+
+// readYAMLFile 读取 YAML 文件，文件不存在或为空时返回nil
+func readYAMLFile(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		return nil, nil
+	}
+	return &doc, nil
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindField 绑定结构体中的一个叶子字段
+type bindField struct {
+	path       string
+	segments   []string
+	index      []int
+	env        string
+	key        string
+	def        string
+	hasDefault bool
+	restart    bool
+	rules      []*ValidationRule
+}
+
+// value 返回字段在 root 中的值
+func (f *bindField) value(root reflect.Value) reflect.Value {
+	return root.FieldByIndex(f.index)
+}
+
+// collectFields 递归收集结构体的叶子字段，嵌套结构体按 yaml 标签组成路径
+func collectFields(typ reflect.Type, index []int, segments []string, restart bool) ([]*bindField, error) {
+	var fields []*bindField
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+		fieldSegments := append(append([]string(nil), segments...), name)
+		path := strings.Join(fieldSegments, ".")
+
+		// reload:"restart" 对嵌套结构体的全部子字段生效
+		fieldRestart := restart
+		switch reload := sf.Tag.Get("reload"); reload {
+		case "":
+		case "restart":
+			fieldRestart = true
+		default:
+			return nil, fmt.Errorf("%w: field %s: unknown reload mode %q", ErrInvalidConfiguration, path, reload)
+		}
+
+		if sf.Type.Kind() == reflect.Struct && sf.Type != timeType && !reflect.PointerTo(sf.Type).Implements(textUnmarshalerType) {
+			children, err := collectFields(sf.Type, fieldIndex, fieldSegments, fieldRestart)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, children...)
+			continue
+		}
+
+		rules, err := parseValidateTag(sf.Type, sf.Tag.Get("validate"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %s: %v", ErrInvalidConfiguration, path, err)
+		}
+
+		field := &bindField{
+			path:     path,
+			segments: fieldSegments,
+			index:    fieldIndex,
+			env:      sf.Tag.Get("env"),
+			key:      sf.Tag.Get("config"),
+			restart:  fieldRestart,
+			rules:    rules,
+		}
+		field.def, field.hasDefault = sf.Tag.Lookup("default")
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// parseValidateTag 将 validate 标签转换为 DefaultConfigValidator 规则
+//
+// 支持 required、min=、max=（数值范围或字符串长度，时长字段可写 30s）、
+// oneof=a b c、url、email 和 regex=；regex 必须放在最后，其后的逗号属于表达式。
+func parseValidateTag(typ reflect.Type, tag string) ([]*ValidationRule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	var rules []*ValidationRule
+	bounds := make(map[string]string)
+	parts := strings.Split(tag, ",")
+
+loop:
+	for i, part := range parts {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "required":
+			rules = append(rules, &ValidationRule{Name: name, Type: ValidationTypeRequired})
+		case "min", "max":
+			bound, err := parseBound(typ, arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", name, arg, err)
+			}
+			bounds[name] = bound
+		case "oneof":
+			values := strings.Fields(arg)
+			if len(values) == 0 {
+				return nil, fmt.Errorf("oneof requires values")
+			}
+			rules = append(rules, &ValidationRule{
+				Name:       name,
+				Type:       ValidationTypeEnum,
+				Parameters: map[string]string{"values": strings.Join(values, ",")},
+			})
+		case "url", "email":
+			rules = append(rules, &ValidationRule{Name: name, Type: ValidationTypeCustom})
+		case "regex":
+			pattern := strings.Join(append([]string{arg}, parts[i+1:]...), ",")
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("invalid regex: %w", err)
+			}
+			rules = append(rules, &ValidationRule{
+				Name:       name,
+				Type:       ValidationTypeRegex,
+				Parameters: map[string]string{"pattern": pattern},
+			})
+			break loop
+		default:
+			return nil, fmt.Errorf("unknown validation rule %q", name)
+		}
+	}
+
+	if len(bounds) > 0 {
+		rule := &ValidationRule{Name: "range", Type: ValidationTypeRange, Parameters: bounds}
+		if typ.Kind() == reflect.String {
+			rule.Name, rule.Type = "length", ValidationTypeLength
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseBound 校验 min/max 参数，时长统一换算为纳秒，与 validationString 一致
+func parseBound(typ reflect.Type, arg string) (string, error) {
+	switch {
+	case typ == durationType:
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(int64(d), 10), nil
+	case typ.Kind() == reflect.String:
+		if _, err := strconv.Atoi(arg); err != nil {
+			return "", err
+		}
+	case isNumberKind(typ.Kind()):
+		if _, err := strconv.ParseFloat(arg, 64); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("not supported for %s", typ)
+	}
+	return arg, nil
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setString 从 default 标签、.env 或环境变量的字符串设置字段
+//
+// 标量按类型解析，时长使用 time.ParseDuration，切片按逗号分隔（与 getEnvAsSlice 一致），
+// 以 [ 开头的值及其他类型按 YAML/JSON 解析。
+func setString(v reflect.Value, raw string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch kind := v.Kind(); {
+	case kind == reflect.String:
+		v.SetString(raw)
+		return nil
+	case kind == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+		return nil
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+		return nil
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	case kind == reflect.Slice && !strings.HasPrefix(strings.TrimSpace(raw), "[") && isScalarType(v.Type().Elem()):
+		items := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setString(elem, item); err != nil {
+				return err
+			}
+			items = reflect.Append(items, elem)
+		}
+		v.Set(items)
+		return nil
+	}
+
+	ptr := reflect.New(v.Type())
+	if err := yaml.Unmarshal([]byte(raw), ptr.Interface()); err != nil {
+		return err
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
+
+func isScalarType(typ reflect.Type) bool {
+	kind := typ.Kind()
+	return kind == reflect.String || kind == reflect.Bool || isNumberKind(kind) ||
+		reflect.PointerTo(typ).Implements(textUnmarshalerType)
+}
+
+// setValue 从配置中心的值设置字段，非字符串的结构化值经 JSON 转换
+func setValue(v reflect.Value, value interface{}) error {
+	switch val := value.(type) {
+	case string:
+		return setString(v, val)
+	case float64:
+		return setString(v, strconv.FormatFloat(val, 'f', -1, 64))
+	case bool, int, int64:
+		return setString(v, fmt.Sprint(val))
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	ptr := reflect.New(v.Type())
+	if err := yaml.Unmarshal(data, ptr.Interface()); err != nil {
+		return err
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
+
+// decodeNode 从 YAML 节点设置字段
+func decodeNode(v reflect.Value, node *yaml.Node) error {
+	ptr := reflect.New(v.Type())
+	if err := node.Decode(ptr.Interface()); err != nil {
+		return err
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
+
+// lookupNode 按路径查找 YAML 节点，路径不存在或值为 null 时返回nil
+func lookupNode(doc *yaml.Node, segments []string) *yaml.Node {
+	node := doc
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+
+	for _, segment := range segments {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var found *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == segment {
+				found = node.Content[i+1]
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		node = found
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	return node
+}
+
+// validationString 将字段值转换为 DefaultConfigValidator 校验的字符串形式
+func validationString(v reflect.Value) string {
+	if v.Type() == durationType {
+		return strconv.FormatInt(v.Int(), 10)
+	}
+
+	switch kind := v.Kind(); {
+	case kind == reflect.String:
+		return v.String()
+	case kind == reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case kind == reflect.Slice || kind == reflect.Map:
+		if v.Len() == 0 {
+			return ""
+		}
+	case kind == reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
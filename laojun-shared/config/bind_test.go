@@ -0,0 +1,244 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type bindLogConfig struct {
+	Level string        `yaml:"level" env:"BIND_LOG_LEVEL" config:"log.level" default:"info" validate:"oneof=debug info warn error"`
+	Tick  time.Duration `yaml:"tick" env:"BIND_LOG_TICK" config:"log.tick" default:"1s" validate:"min=100ms,max=1m"`
+}
+
+type bindServerConfig struct {
+	Host  string        `yaml:"host" env:"BIND_SERVER_HOST" config:"server.host" default:"0.0.0.0" validate:"required"`
+	Port  int           `yaml:"port" env:"BIND_SERVER_PORT" config:"server.port" default:"8080" validate:"min=1,max=65535" reload:"restart"`
+	Log   bindLogConfig `yaml:"log"`
+	Tags  []string      `yaml:"tags" env:"BIND_SERVER_TAGS" config:"server.tags"`
+	Token string        `yaml:"token" env:"BIND_SERVER_TOKEN" validate:"regex=^[a-z]{0,8}$"`
+}
+
+// memoryCenter 内存配置中心，支持订阅
+type memoryCenter struct {
+	mu       sync.Mutex
+	items    map[string]*ConfigItem
+	callback ConfigChangeCallback
+}
+
+func (c *memoryCenter) GetConfigs(ctx context.Context, service, environment string) (map[string]*ConfigItem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return copyItems(c.items), nil
+}
+
+func (c *memoryCenter) Subscribe(ctx context.Context, service, environment string, callback ConfigChangeCallback) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callback = callback
+	return nil
+}
+
+func (c *memoryCenter) Unsubscribe(ctx context.Context, service, environment string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callback = nil
+	return nil
+}
+
+func (c *memoryCenter) set(key string, value interface{}) error {
+	c.mu.Lock()
+	c.items[key] = &ConfigItem{Service: "svc", Environment: "prod", Key: key, Value: value}
+	callback := c.callback
+	c.mu.Unlock()
+
+	if callback == nil {
+		return nil
+	}
+	return callback(&ConfigChangeEvent{Type: EventTypeUpdate, Service: "svc", Environment: "prod", Key: key, NewValue: value})
+}
+
+func writeBindFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestBindPrecedence(t *testing.T) {
+	base := writeBindFile(t, "base.yaml", "host: 10.0.0.1\nport: 9000\nlog:\n  level: debug\n  tick: 2s\n")
+	override := writeBindFile(t, "override.yaml", "log:\n  level: warn\ntags: [a, b]\n")
+	dotenv := writeBindFile(t, ".env", "BIND_SERVER_PORT=9100\nBIND_LOG_TICK=3s\n")
+	t.Setenv("BIND_LOG_TICK", "4s")
+
+	center := &memoryCenter{items: map[string]*ConfigItem{
+		"log.tick": {Key: "log.tick", Value: "5s"},
+	}}
+
+	binding, err := Bind[bindServerConfig](context.Background(),
+		WithFiles(base, override, filepath.Join(t.TempDir(), "missing.yaml")),
+		WithDotenv(dotenv),
+		WithCenter(center, "svc", "prod"),
+	)
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+
+	cfg := binding.Get()
+	if cfg.Host != "10.0.0.1" || cfg.Port != 9100 || cfg.Log.Level != "warn" || cfg.Log.Tick != 5*time.Second {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if len(cfg.Tags) != 2 || cfg.Tags[1] != "b" {
+		t.Errorf("Expected tags from file, got %v", cfg.Tags)
+	}
+
+	expected := map[string]FieldSource{
+		"host":      {Source: SourceFile, Origin: base},
+		"port":      {Source: SourceDotenv, Origin: dotenv},
+		"log.level": {Source: SourceFile, Origin: override},
+		"log.tick":  {Source: SourceCenter, Origin: "log.tick"},
+		"tags":      {Source: SourceFile, Origin: override},
+		"token":     {},
+	}
+	sources := binding.Sources()
+	if len(sources) != len(expected) {
+		t.Errorf("Expected %d sources, got %v", len(expected), sources)
+	}
+	for path, want := range expected {
+		if got := sources[path]; got != want {
+			t.Errorf("Source of %s: expected %+v, got %+v", path, want, got)
+		}
+	}
+
+	// 环境变量覆盖 .env，逗号分隔的切片
+	t.Setenv("BIND_SERVER_PORT", "9200")
+	t.Setenv("BIND_SERVER_TAGS", "x, y,")
+	binding, err = Bind[bindServerConfig](context.Background(), WithDotenv(dotenv))
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if cfg := binding.Get(); cfg.Port != 9200 || len(cfg.Tags) != 2 || cfg.Tags[1] != "y" || cfg.Log.Tick != 4*time.Second {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if source := binding.Source("port"); source != (FieldSource{Source: SourceEnv, Origin: "BIND_SERVER_PORT"}) {
+		t.Errorf("Unexpected port source: %+v", source)
+	}
+	if source := binding.Source("host"); source.Source != SourceDefault {
+		t.Errorf("Expected host from default, got %+v", source)
+	}
+}
+
+func TestBindValidation(t *testing.T) {
+	cases := map[string]string{
+		"BIND_SERVER_HOST":  " ",
+		"BIND_SERVER_PORT":  "70000",
+		"BIND_LOG_LEVEL":    "trace",
+		"BIND_LOG_TICK":     "2m",
+		"BIND_SERVER_TOKEN": "UPPER",
+	}
+	for key, value := range cases {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			_, err := Bind[bindServerConfig](context.Background(), WithDotenv())
+			if !errors.Is(err, ErrConfigValidationFailed) {
+				t.Errorf("Expected ErrConfigValidationFailed, got %v", err)
+			}
+		})
+	}
+
+	t.Setenv("BIND_SERVER_PORT", "http")
+	if _, err := Bind[bindServerConfig](context.Background(), WithDotenv()); !errors.Is(err, ErrInvalidConfigValue) {
+		t.Errorf("Expected ErrInvalidConfigValue, got %v", err)
+	}
+
+	type badTag struct {
+		Enabled bool `validate:"max=1"`
+	}
+	if _, err := Bind[badTag](context.Background(), WithDotenv()); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("Expected ErrInvalidConfiguration, got %v", err)
+	}
+	if _, err := Bind[string](context.Background()); !errors.Is(err, ErrInvalidConfigType) {
+		t.Errorf("Expected ErrInvalidConfigType, got %v", err)
+	}
+}
+
+func TestBindReload(t *testing.T) {
+	center := &memoryCenter{items: map[string]*ConfigItem{
+		"server.port": {Key: "server.port", Value: float64(8081)},
+	}}
+	binding, err := Bind[bindServerConfig](context.Background(), WithDotenv(), WithCenter(center, "svc", "prod"))
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if err := binding.Watch(context.Background()); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	var changes []string
+	unsubscribe := binding.Subscribe(func(old, new *bindServerConfig) {
+		changes = append(changes, old.Log.Level+"->"+new.Log.Level)
+	})
+
+	before := binding.Get()
+	if err := center.set("log.level", "debug"); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0] != "info->debug" {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+	if before.Log.Level != "info" || binding.Get().Log.Level != "debug" {
+		t.Errorf("Expected new snapshot without mutating old one, got %s and %s", before.Log.Level, binding.Get().Log.Level)
+	}
+
+	// 值未变化和未绑定的键不通知订阅者
+	if err := binding.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if err := center.set("unrelated", "x"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("Expected no notification, got %v", changes)
+	}
+
+	// 仅重启生效的字段变化时整体拒绝
+	if err := center.set("server.port", float64(9000)); !errors.Is(err, ErrRestartRequired) {
+		t.Errorf("Expected ErrRestartRequired, got %v", err)
+	}
+	if err := center.set("log.level", "warn"); !errors.Is(err, ErrRestartRequired) {
+		t.Errorf("Expected ErrRestartRequired, got %v", err)
+	}
+	if cfg := binding.Get(); cfg.Port != 8081 || cfg.Log.Level != "debug" {
+		t.Errorf("Expected config unchanged, got %+v", cfg)
+	}
+
+	// 端口恢复后，之前被拒绝的变更随下一次热加载生效
+	if err := center.set("server.port", float64(8081)); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if len(changes) != 2 || changes[1] != "debug->warn" {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+
+	// 校验失败时保留当前配置
+	if err := center.set("log.level", "trace"); !errors.Is(err, ErrConfigValidationFailed) {
+		t.Errorf("Expected ErrConfigValidationFailed, got %v", err)
+	}
+	if binding.Get().Log.Level != "warn" {
+		t.Errorf("Expected config unchanged, got %+v", binding.Get())
+	}
+
+	unsubscribe()
+	if err := center.set("log.level", "error"); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if len(changes) != 2 || binding.Get().Log.Level != "error" {
+		t.Errorf("Expected no notification after unsubscribe, got %v", changes)
+	}
+}
@@ -8,12 +8,15 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/codetaoist/laojun-shared/tracing"
 )
 
 // Client 配置中心客户端 (已弃用，请使用 HTTPConfigClient)
@@ -71,6 +74,12 @@ type HTTPConfigClient struct {
 	
 	// 连接池
 	mu sync.RWMutex
+
+	// 本地快照与订阅状态
+	snapshots     *SnapshotStore
+	stateMu       sync.RWMutex
+	states        map[string]*ConfigSnapshot
+	subscriptions map[string]*subscription
 }
 
 // NewHTTPConfigClient 创建HTTP配置中心客户端
@@ -80,14 +89,38 @@ func NewHTTPConfigClient(baseURL string, options *ConfigOptions) *HTTPConfigClie
 		timeout = options.Timeout
 	}
 	
-	return &HTTPConfigClient{
+	if options == nil {
+		options = &ConfigOptions{}
+	}
+
+	client := &HTTPConfigClient{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		httpClient: &http.Client{
 			Timeout: timeout,
+			// 透传调用方的追踪上下文
+			Transport: tracing.NewTransport(nil, nil),
 		},
-		logger:  logrus.New(),
-		options: options,
+		logger:        logrus.New(),
+		options:       options,
+		states:        make(map[string]*ConfigSnapshot),
+		subscriptions: make(map[string]*subscription),
 	}
+
+	if options.SnapshotDir != "" {
+		snapshotKey := options.SnapshotKey
+		if snapshotKey == "" {
+			snapshotKey = os.Getenv("LAOJUN_CONFIG_SNAPSHOT_KEY")
+		}
+
+		snapshots, err := NewSnapshotStore(options.SnapshotDir, snapshotKey)
+		if err != nil {
+			client.logger.Warnf("Config snapshot disabled: %v", err)
+		} else {
+			client.snapshots = snapshots
+		}
+	}
+
+	return client
 }
 
 // SetAuth 设置认证信息
@@ -394,12 +427,12 @@ func (c *HTTPConfigClient) Exists(ctx context.Context, key string) (bool, error)
 
 // List 列出配置键
 func (c *HTTPConfigClient) List(ctx context.Context, prefix string) ([]string, error) {
-	url := fmt.Sprintf("%s/api/v1/configs", c.baseURL)
+	endpoint := fmt.Sprintf("%s/api/v1/configs", c.baseURL)
 	if prefix != "" {
-		url += "?prefix=" + url.QueryEscape(prefix)
+		endpoint += "?prefix=" + url.QueryEscape(prefix)
 	}
 	
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -604,7 +637,8 @@ func (c *HTTPConfigClient) Health(ctx context.Context) (*ConfigHealth, error) {
 
 // Close 关闭客户端
 func (c *HTTPConfigClient) Close() error {
-	// HTTP客户端不需要显式关闭
+	// HTTP客户端不需要显式关闭，只需停止订阅
+	c.stopSubscriptions()
 	return nil
 }
 
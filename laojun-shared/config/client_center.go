@@ -0,0 +1,400 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// centerItem 配置中心返回的配置项
+type centerItem struct {
+	Service     string                 `json:"service"`
+	Environment string                 `json:"environment"`
+	Key         string                 `json:"key"`
+	Value       interface{}            `json:"value"`
+	Type        string                 `json:"type"`
+	Version     int64                  `json:"version"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	CreatedBy   string                 `json:"created_by"`
+	UpdatedBy   string                 `json:"updated_by"`
+	Description string                 `json:"description"`
+	Tags        []string               `json:"tags"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+func (i *centerItem) toConfigItem() *ConfigItem {
+	item := &ConfigItem{
+		Service:     i.Service,
+		Environment: i.Environment,
+		Key:         i.Key,
+		Value:       i.Value,
+		Type:        ConfigType(i.Type),
+		Description: i.Description,
+		Tags:        i.Tags,
+		Version:     i.Version,
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+		CreatedBy:   i.CreatedBy,
+		UpdatedBy:   i.UpdatedBy,
+	}
+	if len(i.Metadata) > 0 {
+		item.Metadata = make(map[string]string, len(i.Metadata))
+		for k, v := range i.Metadata {
+			item.Metadata[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	return item
+}
+
+// centerHistory 配置中心返回的历史记录
+type centerHistory struct {
+	ID          int64       `json:"id"`
+	Service     string      `json:"service"`
+	Environment string      `json:"environment"`
+	Key         string      `json:"key"`
+	NewValue    interface{} `json:"new_value"`
+	Version     int64       `json:"version"`
+	Operation   string      `json:"operation"`
+	CreatedAt   time.Time   `json:"created_at"`
+	CreatedBy   string      `json:"created_by"`
+	Reason      string      `json:"reason"`
+}
+
+// Connect 连接配置中心并加载当前服务的配置
+//
+// 配置中心不可用但存在本地快照时从快照启动，不返回错误。
+func (c *HTTPConfigClient) Connect(ctx context.Context) error {
+	if c.options.ServiceName == "" {
+		return c.Ping(ctx)
+	}
+
+	_, err := c.GetConfigs(ctx, c.options.ServiceName, c.options.Environment)
+	return err
+}
+
+// GetConfig 获取配置，配置中心不可用时回退到本地快照
+func (c *HTTPConfigClient) GetConfig(ctx context.Context, service, environment, key string) (*ConfigItem, error) {
+	var ci centerItem
+	err := c.doCenter(ctx, http.MethodGet, c.configPath(service, environment, key), nil, &ci)
+	if err == nil {
+		item := ci.toConfigItem()
+		c.updateState(service, environment, func(snapshot *ConfigSnapshot) {
+			snapshot.Items[key] = item
+		})
+		return item, nil
+	}
+
+	if !errors.Is(err, ErrServiceUnavailable) {
+		return nil, err
+	}
+
+	if snapshot := c.loadState(service, environment); snapshot != nil {
+		c.stateMu.RLock()
+		item, ok := snapshot.Items[key]
+		c.stateMu.RUnlock()
+		if ok {
+			c.logger.Warnf("Config center unavailable, serving %s/%s/%s from snapshot: %v", service, environment, key, err)
+			return item, nil
+		}
+	}
+	return nil, err
+}
+
+// GetConfigs 获取服务/环境下的全部配置，配置中心不可用时回退到本地快照
+func (c *HTTPConfigClient) GetConfigs(ctx context.Context, service, environment string) (map[string]*ConfigItem, error) {
+	items, err := c.fetchConfigs(ctx, service, environment)
+	if err == nil {
+		c.replaceState(service, environment, items)
+		return copyItems(items), nil
+	}
+
+	if !errors.Is(err, ErrServiceUnavailable) {
+		return nil, err
+	}
+
+	snapshot := c.loadState(service, environment)
+	if snapshot == nil {
+		return nil, err
+	}
+
+	c.stateMu.RLock()
+	items = copyItems(snapshot.Items)
+	savedAt := snapshot.SavedAt
+	c.stateMu.RUnlock()
+
+	c.logger.Warnf("Config center unavailable, serving %s/%s from snapshot saved at %s: %v",
+		service, environment, savedAt.Format(time.RFC3339), err)
+	return items, nil
+}
+
+// SetConfig 设置配置，item.Version 大于0时由配置中心做版本冲突检查
+func (c *HTTPConfigClient) SetConfig(ctx context.Context, item *ConfigItem) error {
+	payload := map[string]interface{}{
+		"value":       item.Value,
+		"type":        item.Type,
+		"description": item.Description,
+		"tags":        item.Tags,
+		"metadata":    item.Metadata,
+		"version":     item.Version,
+	}
+
+	var result struct {
+		Version int64 `json:"version"`
+	}
+	if err := c.doCenter(ctx, http.MethodPut, c.configPath(item.Service, item.Environment, item.Key), payload, &result); err != nil {
+		return err
+	}
+
+	item.Version = result.Version
+	return nil
+}
+
+// UpdateConfig 更新配置值，保留已有的类型和描述
+func (c *HTTPConfigClient) UpdateConfig(ctx context.Context, service, environment, key string, value interface{}) error {
+	item, err := c.GetConfig(ctx, service, environment, key)
+	if err != nil {
+		if !errors.Is(err, ErrConfigNotFound) {
+			return err
+		}
+		item = &ConfigItem{Service: service, Environment: environment, Key: key, Type: ConfigTypeString}
+	}
+
+	updated := *item
+	updated.Value = value
+	updated.Version = 0
+	return c.SetConfig(ctx, &updated)
+}
+
+// DeleteConfig 删除配置
+func (c *HTTPConfigClient) DeleteConfig(ctx context.Context, service, environment, key string) error {
+	return c.doCenter(ctx, http.MethodDelete, c.configPath(service, environment, key), nil, nil)
+}
+
+// GetConfigVersion 获取配置的当前版本
+func (c *HTTPConfigClient) GetConfigVersion(ctx context.Context, service, environment, key string) (int64, error) {
+	item, err := c.GetConfig(ctx, service, environment, key)
+	if err != nil {
+		return 0, err
+	}
+	return item.Version, nil
+}
+
+// GetConfigHistory 获取配置历史
+func (c *HTTPConfigClient) GetConfigHistory(ctx context.Context, service, environment, key string, limit int) ([]ConfigHistory, error) {
+	path := c.configPath(service, environment, key) + "/history"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+
+	var result struct {
+		History []centerHistory `json:"history"`
+	}
+	if err := c.doCenter(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	history := make([]ConfigHistory, 0, len(result.History))
+	for _, h := range result.History {
+		history = append(history, ConfigHistory{
+			ID:          h.ID,
+			Service:     h.Service,
+			Environment: h.Environment,
+			Key:         h.Key,
+			Value:       h.NewValue,
+			Version:     h.Version,
+			Operation:   h.Operation,
+			CreatedAt:   h.CreatedAt,
+			CreatedBy:   h.CreatedBy,
+			Comment:     h.Reason,
+		})
+	}
+	return history, nil
+}
+
+// RollbackConfig 回滚配置到指定版本
+func (c *HTTPConfigClient) RollbackConfig(ctx context.Context, service, environment, key string, version int64) error {
+	return c.doCenter(ctx, http.MethodPost, c.configPath(service, environment, key)+"/rollback",
+		map[string]interface{}{"version": version}, nil)
+}
+
+// PublishConfig 批量发布服务/环境下的配置
+func (c *HTTPConfigClient) PublishConfig(ctx context.Context, service, environment string, configs map[string]interface{}) error {
+	items := make([]map[string]interface{}, 0, len(configs))
+	for key, value := range configs {
+		items = append(items, map[string]interface{}{
+			"service":     service,
+			"environment": environment,
+			"key":         key,
+			"value":       value,
+		})
+	}
+
+	return c.doCenter(ctx, http.MethodPost, "/api/v1/configs/batch", map[string]interface{}{"configs": items}, nil)
+}
+
+// Ping 检查配置中心是否可用
+func (c *HTTPConfigClient) Ping(ctx context.Context) error {
+	return c.doCenter(ctx, http.MethodGet, "/health", nil, nil)
+}
+
+// fetchConfigs 从配置中心获取服务/环境下的全部配置
+func (c *HTTPConfigClient) fetchConfigs(ctx context.Context, service, environment string) (map[string]*ConfigItem, error) {
+	var result struct {
+		Configs []centerItem `json:"configs"`
+	}
+	path := "/api/v1/configs/" + url.PathEscape(service) + "/" + url.PathEscape(environment)
+	if err := c.doCenter(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]*ConfigItem, len(result.Configs))
+	for i := range result.Configs {
+		item := result.Configs[i].toConfigItem()
+		items[item.Key] = item
+	}
+	return items, nil
+}
+
+// doCenter 调用配置中心API
+//
+// 网络错误和5xx响应包装为 ErrServiceUnavailable，调用方据此决定是否回退到快照。
+func (c *HTTPConfigClient) doCenter(ctx context.Context, method, path string, payload, result interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.options.ServiceName != "" {
+		req.Header.Set("X-Operator", c.options.ServiceName)
+	}
+	c.setAuthHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrServiceUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrConfigNotFound
+	case resp.StatusCode == http.StatusConflict:
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: %s", ErrConfigLocked, string(data))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrPermissionDenied
+	case resp.StatusCode >= http.StatusInternalServerError:
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: HTTP %d: %s", ErrServiceUnavailable, resp.StatusCode, string(data))
+	case resp.StatusCode >= http.StatusBadRequest:
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(data))
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func (c *HTTPConfigClient) configPath(service, environment, key string) string {
+	return "/api/v1/configs/" + url.PathEscape(service) + "/" + url.PathEscape(environment) + "/" + url.PathEscape(key)
+}
+
+// loadState 获取内存中的配置状态，不存在时从本地快照加载
+func (c *HTTPConfigClient) loadState(service, environment string) *ConfigSnapshot {
+	stateKey := service + "/" + environment
+
+	c.stateMu.RLock()
+	snapshot := c.states[stateKey]
+	c.stateMu.RUnlock()
+	if snapshot != nil || c.snapshots == nil {
+		return snapshot
+	}
+
+	loaded, err := c.snapshots.Load(service, environment)
+	if err != nil {
+		if !errors.Is(err, ErrConfigNotFound) {
+			c.logger.Warnf("Failed to load config snapshot for %s: %v", stateKey, err)
+		}
+		return nil
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if existing := c.states[stateKey]; existing != nil {
+		return existing
+	}
+	c.states[stateKey] = loaded
+	return loaded
+}
+
+// replaceState 用配置中心的最新结果替换内存状态并保存快照
+//
+// 只有全量结果才落盘，单项读取和推送事件只更新内存，避免每次读取都重写快照文件。
+func (c *HTTPConfigClient) replaceState(service, environment string, items map[string]*ConfigItem) {
+	saved := c.updateState(service, environment, func(snapshot *ConfigSnapshot) {
+		snapshot.Items = copyItems(items)
+	})
+
+	if c.snapshots != nil {
+		if err := c.snapshots.Save(saved); err != nil {
+			c.logger.Warnf("Failed to save config snapshot for %s/%s: %v", service, environment, err)
+		}
+	}
+}
+
+// updateState 修改内存状态，返回修改后状态的副本
+func (c *HTTPConfigClient) updateState(service, environment string, fn func(snapshot *ConfigSnapshot)) *ConfigSnapshot {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	snapshot := c.states[service+"/"+environment]
+	if snapshot == nil {
+		snapshot = &ConfigSnapshot{
+			Service:     service,
+			Environment: environment,
+			Items:       make(map[string]*ConfigItem),
+		}
+		c.states[service+"/"+environment] = snapshot
+	}
+	fn(snapshot)
+	snapshot.SavedAt = time.Now()
+
+	return &ConfigSnapshot{
+		Service:     snapshot.Service,
+		Environment: snapshot.Environment,
+		Items:       copyItems(snapshot.Items),
+		SavedAt:     snapshot.SavedAt,
+	}
+}
+
+func copyItems(items map[string]*ConfigItem) map[string]*ConfigItem {
+	result := make(map[string]*ConfigItem, len(items))
+	for k, v := range items {
+		result[k] = v
+	}
+	return result
+}
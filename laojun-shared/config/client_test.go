@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCenter 模拟配置中心的列表与单项接口
+type fakeCenter struct {
+	mu      sync.Mutex
+	items   map[string]map[string]interface{}
+	healthy bool
+}
+
+func (f *fakeCenter) set(key string, value interface{}, version int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[key] = map[string]interface{}{
+		"service": "svc", "environment": "prod", "key": key,
+		"value": value, "type": "int", "version": version,
+		"metadata": map[string]interface{}{"owner": "ops", "weight": 1},
+	}
+}
+
+func (f *fakeCenter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/v1/configs/svc/prod":
+		configs := make([]interface{}, 0, len(f.items))
+		for _, item := range f.items {
+			configs = append(configs, item)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"configs": configs, "count": len(configs)})
+	case "/api/v1/configs/svc/prod/watch":
+		// 不支持WebSocket，客户端应退化为轮询
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		key := r.URL.Path[len("/api/v1/configs/svc/prod/"):]
+		item, ok := f.items[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(item)
+	}
+}
+
+func TestHTTPConfigClientSnapshotFallback(t *testing.T) {
+	center := &fakeCenter{items: make(map[string]map[string]interface{}), healthy: true}
+	center.set("pool.size", 10, 1)
+	server := httptest.NewServer(center)
+	defer server.Close()
+
+	options := &ConfigOptions{
+		ServiceName: "svc",
+		Environment: "prod",
+		SnapshotDir: t.TempDir(),
+		SnapshotKey: "test-snapshot-key",
+	}
+
+	client := NewHTTPConfigClient(server.URL, options)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+
+	item, err := client.GetConfig(context.Background(), "svc", "prod", "pool.size")
+	if err != nil {
+		t.Fatalf("GetConfig failed: %v", err)
+	}
+	if item.Metadata["weight"] != "1" {
+		t.Errorf("Expected metadata to be stringified, got %v", item.Metadata)
+	}
+
+	// 配置中心宕机后，新客户端从加密快照启动
+	center.mu.Lock()
+	center.healthy = false
+	center.mu.Unlock()
+
+	restarted := NewHTTPConfigClient(server.URL, options)
+	if err := restarted.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect with snapshot failed: %v", err)
+	}
+	configs, err := restarted.GetConfigs(context.Background(), "svc", "prod")
+	if err != nil {
+		t.Fatalf("GetConfigs from snapshot failed: %v", err)
+	}
+	if configs["pool.size"] == nil || configs["pool.size"].Version != 1 {
+		t.Errorf("Unexpected configs from snapshot: %+v", configs)
+	}
+
+	// 没有快照时返回不可用错误
+	other := NewHTTPConfigClient(server.URL, &ConfigOptions{SnapshotDir: t.TempDir(), SnapshotKey: "test-snapshot-key"})
+	if _, err := other.GetConfigs(context.Background(), "svc", "prod"); !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("Expected ErrServiceUnavailable, got %v", err)
+	}
+}
+
+func TestSnapshotStoreDistinctFiles(t *testing.T) {
+	store, err := NewSnapshotStore(t.TempDir(), "test-snapshot-key")
+	if err != nil {
+		t.Fatalf("NewSnapshotStore failed: %v", err)
+	}
+
+	// 拼接后相同的服务/环境对保存到不同文件
+	pairs := [][2]string{{"a.b", "c"}, {"a", "b.c"}, {"a_b", "c"}, {"a/b", "c"}, {"..", "prod"}}
+	for i, pair := range pairs {
+		snapshot := &ConfigSnapshot{
+			Service:     pair[0],
+			Environment: pair[1],
+			Items:       map[string]*ConfigItem{"k": {Key: "k", Value: i, Version: int64(i + 1)}},
+		}
+		if err := store.Save(snapshot); err != nil {
+			t.Fatalf("Save %v failed: %v", pair, err)
+		}
+	}
+	for i, pair := range pairs {
+		loaded, err := store.Load(pair[0], pair[1])
+		if err != nil {
+			t.Fatalf("Load %v failed: %v", pair, err)
+		}
+		if loaded.Service != pair[0] || loaded.Environment != pair[1] || loaded.Version() != int64(i+1) {
+			t.Errorf("Load %v returned %s/%s version %d", pair, loaded.Service, loaded.Environment, loaded.Version())
+		}
+	}
+}
+
+func TestHTTPConfigClientSubscribePolling(t *testing.T) {
+	center := &fakeCenter{items: make(map[string]map[string]interface{}), healthy: true}
+	center.set("pool.size", 10, 1)
+	server := httptest.NewServer(center)
+	defer server.Close()
+
+	client := NewHTTPConfigClient(server.URL, &ConfigOptions{WatchInterval: 20 * time.Millisecond})
+	defer client.Close()
+
+	events := make(chan *ConfigChangeEvent, 10)
+	err := client.Subscribe(context.Background(), "svc", "prod", func(event *ConfigChangeEvent) error {
+		events <- event
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	center.set("pool.size", 20, 2)
+
+	select {
+	case event := <-events:
+		if event.Type != EventTypeUpdate || event.NewValue != int64(20) || event.OldValue != int64(10) {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected update event from polling")
+	}
+
+	if err := client.Unsubscribe(context.Background(), "svc", "prod"); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+}
+
+func TestHTTPConfigClientResyncDetectsResolvedValueChange(t *testing.T) {
+	center := &fakeCenter{items: make(map[string]map[string]interface{}), healthy: true}
+	center.set("pool.size", 10, 1)
+	server := httptest.NewServer(center)
+	defer server.Close()
+
+	client := NewHTTPConfigClient(server.URL, &ConfigOptions{WatchInterval: 20 * time.Millisecond})
+	defer client.Close()
+
+	events := make(chan *ConfigChangeEvent, 10)
+	err := client.Subscribe(context.Background(), "svc", "prod", func(event *ConfigChangeEvent) error {
+		events <- event
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	// 被引用的键变更后，解析值改变但版本不变
+	center.set("pool.size", 30, 1)
+
+	select {
+	case event := <-events:
+		if event.Type != EventTypeUpdate || event.NewValue != int64(30) {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected update event for resolved value change")
+	}
+}
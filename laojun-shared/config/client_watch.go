@@ -0,0 +1,391 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxReconnectDelay 监听重连的最大退避时间
+const maxReconnectDelay = 30 * time.Second
+
+// subscription 服务/环境的变更订阅
+type subscription struct {
+	service     string
+	environment string
+	callback    ConfigChangeCallback
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// centerWatchEvent 配置中心推送的变更事件
+type centerWatchEvent struct {
+	Type        string      `json:"type"`
+	Service     string      `json:"service"`
+	Environment string      `json:"environment"`
+	Key         string      `json:"key"`
+	OldValue    interface{} `json:"old_value"`
+	NewValue    interface{} `json:"new_value"`
+	Version     int64       `json:"version"`
+	Timestamp   time.Time   `json:"timestamp"`
+	// Reference 非空时表示由被引用的键变更触发，配置项自身版本不变
+	Reference string `json:"reference,omitempty"`
+}
+
+// Subscribe 订阅服务/环境的配置变更
+//
+// 优先通过WebSocket接收推送，握手被拒绝时退化为按 WatchInterval 轮询。
+// 连接断开后按指数退避重连，每次重连先与配置中心全量对账，断线期间的变更不会丢失。
+func (c *HTTPConfigClient) Subscribe(ctx context.Context, service, environment string, callback ConfigChangeCallback) error {
+	if callback == nil {
+		return fmt.Errorf("%w: callback is required", ErrInvalidConfiguration)
+	}
+
+	stateKey := service + "/" + environment
+
+	c.mu.Lock()
+	if _, exists := c.subscriptions[stateKey]; exists {
+		c.mu.Unlock()
+		return fmt.Errorf("%w: already subscribed to %s", ErrWatcherAlreadyStarted, stateKey)
+	}
+
+	// 订阅的生命周期与 Unsubscribe/Close 绑定，不随调用方ctx结束
+	watchCtx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{
+		service:     service,
+		environment: environment,
+		callback:    callback,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+	c.subscriptions[stateKey] = sub
+	c.mu.Unlock()
+
+	// 建立初始状态，配置中心不可用时以快照为基线
+	if _, err := c.GetConfigs(ctx, service, environment); err != nil && !errors.Is(err, ErrServiceUnavailable) {
+		c.mu.Lock()
+		delete(c.subscriptions, stateKey)
+		c.mu.Unlock()
+		cancel()
+		return err
+	}
+
+	go c.watchLoop(watchCtx, sub)
+	return nil
+}
+
+// Unsubscribe 取消订阅
+func (c *HTTPConfigClient) Unsubscribe(ctx context.Context, service, environment string) error {
+	c.mu.Lock()
+	sub, exists := c.subscriptions[service+"/"+environment]
+	if exists {
+		delete(c.subscriptions, service+"/"+environment)
+	}
+	c.mu.Unlock()
+
+	if !exists {
+		return ErrWatcherNotStarted
+	}
+
+	sub.cancel()
+	select {
+	case <-sub.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// stopSubscriptions 停止所有订阅
+func (c *HTTPConfigClient) stopSubscriptions() {
+	c.mu.Lock()
+	subs := make([]*subscription, 0, len(c.subscriptions))
+	for key, sub := range c.subscriptions {
+		subs = append(subs, sub)
+		delete(c.subscriptions, key)
+	}
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.cancel()
+		<-sub.done
+	}
+}
+
+// watchLoop 维持订阅连接直到取消
+func (c *HTTPConfigClient) watchLoop(ctx context.Context, sub *subscription) {
+	defer close(sub.done)
+
+	delay := c.options.RetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+	backoff := delay
+
+	for {
+		err := c.watchStream(ctx, sub)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if errors.Is(err, websocket.ErrBadHandshake) {
+			c.logger.Warnf("Config watch not supported for %s/%s, falling back to polling: %v",
+				sub.service, sub.environment, err)
+			c.pollLoop(ctx, sub)
+			return
+		}
+
+		if err == nil {
+			// 连接曾成功建立，重置退避
+			backoff = delay
+		}
+		c.logger.Warnf("Config watch for %s/%s disconnected, reconnecting in %s: %v",
+			sub.service, sub.environment, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxReconnectDelay {
+			backoff = maxReconnectDelay
+		}
+	}
+}
+
+// watchStream 建立WebSocket连接并处理推送事件
+//
+// 连接建立后断开时返回nil，连接失败时返回错误。
+func (c *HTTPConfigClient) watchStream(ctx context.Context, sub *subscription) error {
+	endpoint, err := c.watchURL(sub.service, sub.environment)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	c.mu.RLock()
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	c.mu.RUnlock()
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: HTTP %d", ErrServiceUnavailable, resp.StatusCode)
+		}
+		return err
+	}
+	defer conn.Close()
+
+	// ctx取消时关闭连接以中断阻塞的读取
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	// 连接建立到首个事件之间的变更通过再次对账补齐
+	c.resync(ctx, sub)
+
+	for {
+		var event centerWatchEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			c.logger.Debugf("Config watch read failed for %s/%s: %v", sub.service, sub.environment, err)
+			return nil
+		}
+		c.applyEvent(ctx, sub, &event)
+	}
+}
+
+// pollLoop 按 WatchInterval 轮询配置中心
+func (c *HTTPConfigClient) pollLoop(ctx context.Context, sub *subscription) {
+	interval := c.options.WatchInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.resync(ctx, sub)
+		}
+	}
+}
+
+// resync 全量拉取配置并与本地状态比对，对差异触发回调
+func (c *HTTPConfigClient) resync(ctx context.Context, sub *subscription) {
+	current, err := c.fetchConfigs(ctx, sub.service, sub.environment)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.Warnf("Failed to resync configs for %s/%s: %v", sub.service, sub.environment, err)
+		}
+		return
+	}
+
+	var previous map[string]*ConfigItem
+	if snapshot := c.loadState(sub.service, sub.environment); snapshot != nil {
+		c.stateMu.RLock()
+		previous = copyItems(snapshot.Items)
+		c.stateMu.RUnlock()
+	}
+
+	c.replaceState(sub.service, sub.environment, current)
+
+	now := time.Now()
+	for key, item := range current {
+		old, exists := previous[key]
+		switch {
+		case !exists:
+			c.notify(sub, &ConfigChangeEvent{
+				Type: EventTypeCreate, Service: sub.service, Environment: sub.environment, Key: key,
+				NewValue: convertValue(item), Version: item.Version, Timestamp: now, Operator: item.UpdatedBy,
+			})
+		case item.Version != old.Version || !reflect.DeepEqual(item.Value, old.Value):
+			c.notify(sub, &ConfigChangeEvent{
+				Type: EventTypeUpdate, Service: sub.service, Environment: sub.environment, Key: key,
+				OldValue: convertValue(old), NewValue: convertValue(item), Version: item.Version, Timestamp: now, Operator: item.UpdatedBy,
+			})
+		}
+	}
+	for key, old := range previous {
+		if _, exists := current[key]; !exists {
+			c.notify(sub, &ConfigChangeEvent{
+				Type: EventTypeDelete, Service: sub.service, Environment: sub.environment, Key: key,
+				OldValue: convertValue(old), Version: old.Version, Timestamp: now,
+			})
+		}
+	}
+}
+
+// applyEvent 处理推送事件，跳过本地已有的旧版本，引用触发的事件按值判断
+func (c *HTTPConfigClient) applyEvent(ctx context.Context, sub *subscription, event *centerWatchEvent) {
+	var old *ConfigItem
+	if snapshot := c.loadState(sub.service, sub.environment); snapshot != nil {
+		c.stateMu.RLock()
+		old = snapshot.Items[event.Key]
+		c.stateMu.RUnlock()
+	}
+
+	if event.Type == string(EventTypeDelete) {
+		if old == nil {
+			return
+		}
+		c.updateState(sub.service, sub.environment, func(snapshot *ConfigSnapshot) {
+			delete(snapshot.Items, event.Key)
+		})
+		c.notify(sub, &ConfigChangeEvent{
+			Type: EventTypeDelete, Service: sub.service, Environment: sub.environment, Key: event.Key,
+			OldValue: convertValue(old), Version: event.Version, Timestamp: event.Timestamp,
+		})
+		return
+	}
+
+	if old != nil && event.Version <= old.Version {
+		if event.Reference == "" || reflect.DeepEqual(old.Value, event.NewValue) {
+			return
+		}
+	}
+
+	// 事件中只有值，取完整配置项以获得类型等信息
+	item, err := c.GetConfig(ctx, sub.service, sub.environment, event.Key)
+	if err != nil {
+		c.logger.Warnf("Failed to fetch changed config %s/%s/%s: %v", sub.service, sub.environment, event.Key, err)
+		return
+	}
+
+	eventType := EventTypeUpdate
+	var oldValue interface{}
+	if old == nil {
+		eventType = EventTypeCreate
+	} else {
+		oldValue = convertValue(old)
+	}
+
+	c.notify(sub, &ConfigChangeEvent{
+		Type: eventType, Service: sub.service, Environment: sub.environment, Key: event.Key,
+		OldValue: oldValue, NewValue: convertValue(item), Version: item.Version, Timestamp: event.Timestamp, Operator: item.UpdatedBy,
+	})
+}
+
+// notify 调用订阅回调，回调错误只记录日志
+func (c *HTTPConfigClient) notify(sub *subscription, event *ConfigChangeEvent) {
+	if err := sub.callback(event); err != nil {
+		c.logger.Warnf("Config change callback failed for %s/%s/%s: %v", event.Service, event.Environment, event.Key, err)
+	}
+}
+
+// watchURL 构造WebSocket监听地址
+func (c *HTTPConfigClient) watchURL(service, environment string) (string, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v1/configs/" + url.PathEscape(service) + "/" + url.PathEscape(environment) + "/watch"
+	return u.String(), nil
+}
+
+// convertValue 按配置类型转换配置值
+func convertValue(item *ConfigItem) interface{} {
+	switch item.Type {
+	case ConfigTypeInt:
+		switch v := item.Value.(type) {
+		case float64:
+			return int64(v)
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i
+			}
+		}
+	case ConfigTypeFloat:
+		if s, ok := item.Value.(string); ok {
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f
+			}
+		}
+	case ConfigTypeBool:
+		if s, ok := item.Value.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b
+			}
+		}
+	case ConfigTypeJSON, ConfigTypeObject, ConfigTypeArray:
+		if s, ok := item.Value.(string); ok {
+			var v interface{}
+			if err := json.Unmarshal([]byte(s), &v); err == nil {
+				return v
+			}
+		}
+	}
+	return item.Value
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,7 @@ type Config struct {
 	RateLimit RateLimitConfig `json:"rate_limit"`
 	Log       LogConfig       `json:"log"`
 	Security  SecurityConfig  `json:"security"`
+	Chaos     ChaosConfig     `json:"chaos"`
 }
 
 // ServerConfig 服务器配置
@@ -54,6 +56,27 @@ type JWTConfig struct {
 	Secret     string        `json:"secret"`
 	Expiration time.Duration `json:"expiration"`
 	Issuer     string        `json:"issuer"`
+	// Algorithm 签名算法：HS256（默认，使用 Secret）、RS256、ES256、EdDSA
+	Algorithm string `json:"algorithm"`
+	// PrivateKeyFile 非对称签名私钥（PEM），KeyID 为空时使用公钥指纹
+	PrivateKeyFile string `json:"private_key_file"`
+	KeyID          string `json:"key_id"`
+	// JWKSFile/JWKSURL 验证其他服务签发令牌的公钥集合
+	JWKSFile    string        `json:"jwks_file"`
+	JWKSURL     string        `json:"jwks_url"`
+	JWKSRefresh time.Duration `json:"jwks_refresh"`
+	// Audience 签发令牌的受众，验证时令牌须包含其中之一
+	Audience []string `json:"audience"`
+	// RefreshExpiration 刷新令牌有效期
+	RefreshExpiration time.Duration `json:"refresh_expiration"`
+}
+
+// ChaosConfig 故障注入配置，对应 chaos.Config；生产环境保持关闭
+type ChaosConfig struct {
+	Enabled    bool          `json:"enabled"`
+	AdminToken string        `json:"admin_token"`
+	DefaultTTL time.Duration `json:"default_ttl"`
+	MaxTTL     time.Duration `json:"max_ttl"`
 }
 
 // RateLimitConfig 频率限制配置
@@ -118,9 +141,17 @@ func LoadConfig() (*Config, error) {
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-secret-key"),
-			Expiration: getEnvAsDuration("JWT_EXPIRATION", 24*time.Hour),
-			Issuer:     getEnv("JWT_ISSUER", "laojun"),
+			Secret:            getEnv("JWT_SECRET", "your-secret-key"),
+			Expiration:        getEnvAsDuration("JWT_EXPIRATION", 24*time.Hour),
+			Issuer:            getEnv("JWT_ISSUER", "laojun"),
+			Algorithm:         getEnv("JWT_ALGORITHM", "HS256"),
+			PrivateKeyFile:    getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			KeyID:             getEnv("JWT_KEY_ID", ""),
+			JWKSFile:          getEnv("JWT_JWKS_FILE", ""),
+			JWKSURL:           getEnv("JWT_JWKS_URL", ""),
+			JWKSRefresh:       getEnvAsDuration("JWT_JWKS_REFRESH", 5*time.Minute),
+			Audience:          getEnvAsSlice("JWT_AUDIENCE", nil),
+			RefreshExpiration: getEnvAsDuration("JWT_REFRESH_EXPIRATION", 7*24*time.Hour),
 		},
 		RateLimit: RateLimitConfig{
 			Enabled:              getEnvAsBool("RATE_LIMIT_ENABLED", true),
@@ -149,6 +180,12 @@ func LoadConfig() (*Config, error) {
 			MarketplaceCaptchaEnabled: getEnvAsBool("MARKETPLACE_CAPTCHA_ENABLED", false),
 			CaptchaType:              getEnv("CAPTCHA_TYPE", "image"),
 		},
+		Chaos: ChaosConfig{
+			Enabled:    getEnvAsBool("CHAOS_ENABLED", false),
+			AdminToken: getEnv("CHAOS_ADMIN_TOKEN", ""),
+			DefaultTTL: getEnvAsDuration("CHAOS_DEFAULT_TTL", 5*time.Minute),
+			MaxTTL:     getEnvAsDuration("CHAOS_MAX_TTL", time.Hour),
+		},
 	}
 
 	return config, nil
@@ -199,3 +236,16 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var values []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		return values
+	}
+	return defaultValue
+}
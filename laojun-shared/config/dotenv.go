@@ -16,22 +16,7 @@ import (
 // Fallback：若上述均不存在，尝试当前工作目录/.env
 // 注意：为最佳努力，不会因为 .env 缺失而报错
 func LoadDotenv() {
-	root := findProjectRoot()
-	// 解析环境名
-	env := strings.TrimSpace(os.Getenv("APP_ENV"))
-	if env == "" {
-		env = strings.TrimSpace(os.Getenv("ENV"))
-	}
-	if env != "" {
-		env = strings.ToLower(env)
-	}
-
-	// 构造候选文件（仅项目根目录）
-	candidates := []string{filepath.Join(root, ".env")}
-	if env != "" {
-		candidates = append(candidates, filepath.Join(root, ".env."+env))
-	}
-	candidates = append(candidates, filepath.Join(root, ".env.local"))
+	candidates := dotenvCandidates()
 
 	loaded := make([]string, 0, len(candidates))
 	for _, p := range candidates {
@@ -59,6 +44,26 @@ func LoadDotenv() {
 	fmt.Println("No .env file found; using system environment")
 }
 
+// dotenvCandidates 返回项目根目录下按覆盖顺序排列的候选 .env 文件
+func dotenvCandidates() []string {
+	root := findProjectRoot()
+	// 解析环境名
+	env := strings.TrimSpace(os.Getenv("APP_ENV"))
+	if env == "" {
+		env = strings.TrimSpace(os.Getenv("ENV"))
+	}
+	if env != "" {
+		env = strings.ToLower(env)
+	}
+
+	// 构造候选文件（仅项目根目录）
+	candidates := []string{filepath.Join(root, ".env")}
+	if env != "" {
+		candidates = append(candidates, filepath.Join(root, ".env."+env))
+	}
+	return append(candidates, filepath.Join(root, ".env.local"))
+}
+
 // findProjectRoot 通过向上查找标识文件定位项目根目录
 func findProjectRoot() string {
 	cwd, err := os.Getwd()
@@ -90,12 +95,33 @@ func findProjectRoot() string {
 
 // loadEnvFile 解析简单的 KEY=VALUE 格式并注入到进程环境
 func loadEnvFile(path string) error {
-	f, err := os.Open(path)
+	vars, err := parseEnvFile(path, os.Getenv)
 	if err != nil {
 		return err
 	}
+	for key, val := range vars {
+		_ = os.Setenv(key, val)
+	}
+	return nil
+}
+
+// parseEnvFile 解析简单的 KEY=VALUE 格式，不修改进程环境
+// 值中的 ${VAR} 或 $VAR 先引用本文件中已出现的变量，再通过 lookup 查找
+func parseEnvFile(path string, lookup func(string) string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
 	defer f.Close()
 
+	vars := make(map[string]string)
+	expand := func(key string) string {
+		if val, ok := vars[key]; ok {
+			return val
+		}
+		return lookup(key)
+	}
+
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -121,11 +147,9 @@ func loadEnvFile(path string) error {
 			}
 		}
 
-		// 展开环境变量引用（如 ${VAR} 或 $VAR）
-		val = os.ExpandEnv(val)
-
-		_ = os.Setenv(key, val)
+		// 展开变量引用（如 ${VAR} 或 $VAR）
+		vars[key] = os.Expand(val, expand)
 	}
 
-	return scanner.Err()
-}
\ No newline at end of file
+	return vars, scanner.Err()
+}
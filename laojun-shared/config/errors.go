@@ -72,4 +72,7 @@ var (
 	
 	// ErrManagerClosed 管理器已关闭
 	ErrManagerClosed = errors.New("manager is closed")
+	
+	// ErrRestartRequired 热加载时仅重启生效的字段发生变化
+	ErrRestartRequired = errors.New("config change requires restart")
 )
\ No newline at end of file
@@ -243,7 +243,9 @@ type ConfigChangeEvent struct {
 
 // ValidationRule 验证规则
 type ValidationRule struct {
+	Name        string         `json:"name,omitempty" yaml:"name,omitempty"`
 	Type        ValidationType `json:"type" yaml:"type"`
+	Parameters  map[string]string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 	Required    bool           `json:"required" yaml:"required"`
 	MinLength   int            `json:"min_length,omitempty" yaml:"min_length,omitempty"`
 	MaxLength   int            `json:"max_length,omitempty" yaml:"max_length,omitempty"`
@@ -300,6 +302,10 @@ const (
 	ValidationTypeURL    ValidationType = "url"
 	ValidationTypeRegex  ValidationType = "regex"
 	ValidationTypeCustom ValidationType = "custom"
+	ValidationTypeRequired ValidationType = "required"
+	ValidationTypeRange    ValidationType = "range"
+	ValidationTypeEnum     ValidationType = "enum"
+	ValidationTypeLength   ValidationType = "length"
 )
 
 // ConfigChangeCallback 配置变化回调函数
@@ -328,6 +334,10 @@ type ConfigOptions struct {
 	WatchInterval  time.Duration `json:"watch_interval" yaml:"watch_interval"`
 	WatchBufferSize int          `json:"watch_buffer_size" yaml:"watch_buffer_size"`
 
+	// 本地快照配置，配置中心不可用时从快照启动
+	SnapshotDir string `json:"snapshot_dir,omitempty" yaml:"snapshot_dir,omitempty"`
+	SnapshotKey string `json:"-" yaml:"snapshot_key,omitempty"`
+
 	// 服务信息
 	ServiceName string `json:"service_name" yaml:"service_name"`
 	Environment string `json:"environment" yaml:"environment"`
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/crypto"
+)
+
+// snapshotSalt 快照加密密钥派生使用的盐
+const snapshotSalt = "laojun-config-snapshot"
+
+// ConfigSnapshot 某个服务/环境最后一次成功获取的配置快照
+type ConfigSnapshot struct {
+	Service     string                 `json:"service"`
+	Environment string                 `json:"environment"`
+	Items       map[string]*ConfigItem `json:"items"`
+	SavedAt     time.Time              `json:"saved_at"`
+}
+
+// Version 返回快照中配置项的最大版本号
+func (s *ConfigSnapshot) Version() int64 {
+	var version int64
+	for _, item := range s.Items {
+		if item.Version > version {
+			version = item.Version
+		}
+	}
+	return version
+}
+
+// SnapshotStore 加密的本地配置快照存储
+//
+// 每个服务/环境对应目录下的一个文件，内容为AES-GCM加密的JSON，
+// 写入时先写临时文件再重命名，避免进程中断留下损坏的快照。
+type SnapshotStore struct {
+	dir       string
+	encryptor *crypto.Encryptor
+}
+
+// NewSnapshotStore 创建快照存储
+func NewSnapshotStore(dir, secretKey string) (*SnapshotStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("%w: snapshot dir is required", ErrInvalidConfiguration)
+	}
+	if secretKey == "" {
+		return nil, fmt.Errorf("%w: snapshot key is required", ErrInvalidConfiguration)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot dir: %w", err)
+	}
+
+	encryptor, err := crypto.NewEncryptor(crypto.EncryptionConfig{
+		SecretKey: secretKey,
+		Salt:      snapshotSalt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot encryptor: %w", err)
+	}
+
+	return &SnapshotStore{dir: dir, encryptor: encryptor}, nil
+}
+
+// Save 加密保存快照
+func (s *SnapshotStore) Save(snapshot *ConfigSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	encrypted, err := s.encryptor.EncryptBytes(data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt snapshot: %w", err)
+	}
+
+	path := s.path(snapshot.Service, snapshot.Environment)
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(encrypted); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load 读取并解密快照，不存在时返回 ErrConfigNotFound
+func (s *SnapshotStore) Load(service, environment string) (*ConfigSnapshot, error) {
+	encrypted, err := os.ReadFile(s.path(service, environment))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrConfigNotFound
+		}
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	data, err := s.encryptor.DecryptBytes(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt snapshot: %w", err)
+	}
+
+	var snapshot ConfigSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+	if snapshot.Items == nil {
+		snapshot.Items = make(map[string]*ConfigItem)
+	}
+
+	return &snapshot, nil
+}
+
+// Remove 删除快照
+func (s *SnapshotStore) Remove(service, environment string) error {
+	err := os.Remove(s.path(service, environment))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path 返回快照文件路径。服务名和环境分别转义后以 @ 连接，
+// 转义结果不含 @ 和路径分隔符，不同的服务/环境对不会映射到同一文件
+func (s *SnapshotStore) path(service, environment string) string {
+	name := url.QueryEscape(service) + "@" + url.QueryEscape(environment)
+	return filepath.Join(s.dir, name+".snapshot")
+}
@@ -0,0 +1,215 @@
+package coordination
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/internal/redistest"
+)
+
+func newTestRedisLocker(t *testing.T) (*redistest.Server, *RedisLocker) {
+	t.Helper()
+	server := redistest.New(t)
+	return server, NewRedisLocker(server.Client(t), "test:lock", nil)
+}
+
+func expectLost(t *testing.T, lock Lock, within time.Duration) {
+	t.Helper()
+	select {
+	case <-lock.Lost():
+	case <-time.After(within):
+		t.Fatalf("lock %s not reported lost within %s", lock.Name(), within)
+	}
+}
+
+func expectHeld(t *testing.T, lock Lock) {
+	t.Helper()
+	select {
+	case <-lock.Lost():
+		t.Fatalf("lock %s unexpectedly lost", lock.Name())
+	default:
+	}
+}
+
+func testLockerBasics(t *testing.T, locker Locker) {
+	ctx := context.Background()
+
+	first, err := locker.TryAcquire(ctx, "jobs", time.Second)
+	if err != nil {
+		t.Fatalf("TryAcquire() error = %v", err)
+	}
+	if _, err := locker.TryAcquire(ctx, "jobs", time.Second); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("second TryAcquire() error = %v, want ErrNotAcquired", err)
+	}
+	other, err := locker.TryAcquire(ctx, "other", time.Second)
+	if err != nil {
+		t.Fatalf("TryAcquire(other) error = %v", err)
+	}
+	defer other.Release(ctx)
+
+	// Acquire 等待锁释放
+	acquired := make(chan Lock)
+	go func() {
+		lock, err := locker.Acquire(ctx, "jobs", time.Second)
+		if err != nil {
+			t.Errorf("Acquire() error = %v", err)
+		}
+		acquired <- lock
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	expectLost(t, first, time.Second)
+
+	second := <-acquired
+	if second.Token() <= first.Token() {
+		t.Errorf("fencing token did not increase: %d then %d", first.Token(), second.Token())
+	}
+	if err := second.Release(ctx); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := locker.Acquire(timeoutCtx, "other", time.Second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() on held lock error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestRedisLocker(t *testing.T) {
+	server, locker := newTestRedisLocker(t)
+	testLockerBasics(t, locker)
+
+	// 锁键和围栏键使用相同的哈希标签，集群模式下位于同一槽位
+	if fence, err := server.Get("test:lock:{jobs}:fence"); err != nil || fence != "2" {
+		t.Errorf("fence counter = %q, %v", fence, err)
+	}
+}
+
+func TestRedisLockRenewal(t *testing.T) {
+	server, locker := newTestRedisLocker(t)
+	ctx := context.Background()
+
+	lock, err := locker.TryAcquire(ctx, "renew", 150*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryAcquire() error = %v", err)
+	}
+
+	// 续约使锁在多个租期后仍然有效
+	time.Sleep(500 * time.Millisecond)
+	expectHeld(t, lock)
+	if _, err := locker.TryAcquire(ctx, "renew", time.Second); !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("lock expired despite renewal: %v", err)
+	}
+
+	// 模拟锁过期后被其他持有者获取
+	server.Set("test:lock:{renew}", "someone-else")
+	expectLost(t, lock, time.Second)
+	if err := lock.Release(ctx); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Release() after loss error = %v, want ErrLockLost", err)
+	}
+}
+
+func TestRedisLockExpiresWhenRenewalFails(t *testing.T) {
+	server, locker := newTestRedisLocker(t)
+
+	lock, err := locker.TryAcquire(context.Background(), "flaky", 150*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryAcquire() error = %v", err)
+	}
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	expectLost(t, lock, time.Second)
+}
+
+func TestPostgresLocker(t *testing.T) {
+	_, db := newFakePG(t)
+	testLockerBasics(t, NewPostgresLocker(db, nil))
+}
+
+func TestPostgresLockConnectionLost(t *testing.T) {
+	server, db := newFakePG(t)
+	locker := NewPostgresLocker(db, nil)
+	ctx := context.Background()
+
+	lock, err := locker.TryAcquire(ctx, "session", 90*time.Millisecond)
+	if err != nil {
+		t.Fatalf("TryAcquire() error = %v", err)
+	}
+
+	server.terminate(advisoryLockKey("session"))
+	expectLost(t, lock, time.Second)
+	if err := lock.Release(ctx); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Release() after loss error = %v, want ErrLockLost", err)
+	}
+
+	// 连接断开后锁可被重新获取
+	next, err := locker.TryAcquire(ctx, "session", time.Second)
+	if err != nil {
+		t.Fatalf("TryAcquire() after loss error = %v", err)
+	}
+	next.Release(ctx)
+}
+
+func TestElector(t *testing.T) {
+	_, locker := newTestRedisLocker(t)
+
+	var elected, lost atomic.Int32
+	newElector := func() *Elector {
+		return NewElector(locker, ElectionConfig{
+			Name:          "cleanup",
+			TTL:           300 * time.Millisecond,
+			RetryInterval: 20 * time.Millisecond,
+			OnElected: func(ctx context.Context, token int64) {
+				elected.Add(1)
+				<-ctx.Done()
+			},
+			OnLost: func() { lost.Add(1) },
+		}, nil)
+	}
+
+	first, second := newElector(), newElector()
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+
+	firstDone := make(chan error)
+	go func() { firstDone <- first.Run(firstCtx) }()
+	waitFor(t, first.IsLeader)
+	go second.Run(secondCtx)
+
+	time.Sleep(100 * time.Millisecond)
+	if second.IsLeader() || elected.Load() != 1 {
+		t.Fatalf("second elector became leader while first holds leadership")
+	}
+	firstToken := first.Token()
+
+	// leader 退出时释放领导权，另一个副本接任
+	stopFirst()
+	if err := <-firstDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v", err)
+	}
+	if first.IsLeader() || lost.Load() != 1 {
+		t.Errorf("first still leader after stopping, lost = %d", lost.Load())
+	}
+
+	waitFor(t, second.IsLeader)
+	if second.Token() <= firstToken {
+		t.Errorf("new leader token %d not greater than %d", second.Token(), firstToken)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,137 @@
+package coordination
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ElectionConfig 选主配置
+type ElectionConfig struct {
+	// Name 选举名称，同名的 Elector 竞争同一个领导权
+	Name string
+	// TTL 领导权租期，leader 异常退出后最多经过该时间重新选主，默认 DefaultLockTTL
+	TTL time.Duration
+	// RetryInterval 竞选失败后的重试间隔，默认 TTL/3
+	RetryInterval time.Duration
+	// OnElected 成为 leader 时在新协程中调用，ctx 在失去领导权或 Run 退出时取消
+	OnElected func(ctx context.Context, token int64)
+	// OnLost 失去领导权时调用，此前 OnElected 已返回
+	OnLost func()
+}
+
+// Elector 基于分布式锁的选主
+//
+// 用法：
+//
+//	elector := coordination.NewElector(locker, coordination.ElectionConfig{
+//		Name: "discovery-cleanup",
+//		OnElected: func(ctx context.Context, token int64) {
+//			runCleanupLoop(ctx)
+//		},
+//	}, logger)
+//	go elector.Run(ctx)
+type Elector struct {
+	locker Locker
+	config ElectionConfig
+	logger *zap.Logger
+
+	leader atomic.Bool
+	token  atomic.Int64
+}
+
+// NewElector 创建选主器
+func NewElector(locker Locker, config ElectionConfig, logger *zap.Logger) *Elector {
+	if config.TTL <= 0 {
+		config.TTL = DefaultLockTTL
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = config.TTL / 3
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Elector{
+		locker: locker,
+		config: config,
+		logger: logger.With(zap.String("election", config.Name)),
+	}
+}
+
+// IsLeader 当前是否为 leader
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Token 当前领导权的围栏令牌，非 leader 时为0
+func (e *Elector) Token() int64 {
+	return e.token.Load()
+}
+
+// Run 参与选举直到 ctx 结束，退出前释放领导权，返回 ctx.Err()
+func (e *Elector) Run(ctx context.Context) error {
+	for {
+		lock, err := e.locker.TryAcquire(ctx, e.config.Name, e.config.TTL)
+		switch {
+		case err == nil:
+			e.lead(ctx, lock)
+		case errors.Is(err, ErrNotAcquired):
+		case ctx.Err() == nil:
+			e.logger.Warn("Failed to campaign for leadership", zap.Error(err))
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(e.config.RetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// lead 持有领导权直到锁丢失或 ctx 结束
+func (e *Elector) lead(ctx context.Context, lock Lock) {
+	e.token.Store(lock.Token())
+	e.leader.Store(true)
+	e.logger.Info("Elected as leader", zap.Int64("token", lock.Token()))
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	if e.config.OnElected != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.config.OnElected(leaderCtx, lock.Token())
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-lock.Lost():
+		e.logger.Warn("Leadership lost")
+	}
+	cancel()
+	wg.Wait()
+
+	e.leader.Store(false)
+	e.token.Store(0)
+
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), e.config.TTL/3)
+	if err := lock.Release(releaseCtx); err != nil && !errors.Is(err, ErrLockLost) {
+		e.logger.Warn("Failed to release leadership", zap.Error(err))
+	}
+	releaseCancel()
+
+	if e.config.OnLost != nil {
+		e.config.OnLost()
+	}
+}
@@ -0,0 +1,143 @@
+package coordination
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// fakePG 仅实现咨询锁相关查询的内存PostgreSQL，通过 database/sql 驱动接入
+type fakePG struct {
+	mu      sync.Mutex
+	holders map[int64]*fakePGConn
+	xid     int64
+}
+
+var (
+	fakePGOnce    sync.Once
+	fakePGServers sync.Map
+	fakePGSeq     atomic.Int64
+)
+
+func newFakePG(t *testing.T) (*fakePG, *sql.DB) {
+	t.Helper()
+	fakePGOnce.Do(func() { sql.Register("fakepg", fakePGDriver{}) })
+
+	server := &fakePG{holders: make(map[int64]*fakePGConn), xid: 100}
+	dsn := strconv.FormatInt(fakePGSeq.Add(1), 10)
+	fakePGServers.Store(dsn, server)
+
+	db, err := sql.Open("fakepg", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return server, db
+}
+
+// terminate 模拟 pg_terminate_backend 断开持有锁的连接
+func (s *fakePG) terminate(key int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if conn := s.holders[key]; conn != nil {
+		conn.broken.Store(true)
+		delete(s.holders, key)
+	}
+}
+
+type fakePGDriver struct{}
+
+func (fakePGDriver) Open(dsn string) (driver.Conn, error) {
+	server, ok := fakePGServers.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("unknown fake server %s", dsn)
+	}
+	return &fakePGConn{server: server.(*fakePG)}, nil
+}
+
+type fakePGConn struct {
+	server *fakePG
+	broken atomic.Bool
+}
+
+func (c *fakePGConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *fakePGConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+func (c *fakePGConn) Close() error {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+	for key, holder := range c.server.holders {
+		if holder == c {
+			delete(c.server.holders, key)
+		}
+	}
+	return nil
+}
+
+func (c *fakePGConn) Ping(context.Context) error {
+	if c.broken.Load() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *fakePGConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.broken.Load() {
+		return nil, driver.ErrBadConn
+	}
+
+	s := c.server
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch query {
+	case "SELECT pg_try_advisory_lock($1)":
+		key := args[0].Value.(int64)
+		if holder := s.holders[key]; holder != nil && holder != c {
+			return &fakePGRows{value: false}, nil
+		}
+		s.holders[key] = c
+		return &fakePGRows{value: true}, nil
+	case "SELECT pg_advisory_unlock($1)":
+		key := args[0].Value.(int64)
+		if s.holders[key] != c {
+			return &fakePGRows{value: false}, nil
+		}
+		delete(s.holders, key)
+		return &fakePGRows{value: true}, nil
+	case "SELECT txid_current()":
+		s.xid++
+		return &fakePGRows{value: s.xid}, nil
+	default:
+		return nil, fmt.Errorf("unsupported query %q", query)
+	}
+}
+
+type fakePGRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *fakePGRows) Columns() []string { return []string{"result"} }
+func (r *fakePGRows) Close() error      { return nil }
+
+func (r *fakePGRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
@@ -0,0 +1,73 @@
+// Package coordination 提供多副本部署下的协调原语：分布式锁和选主。
+//
+// 锁有两种实现：基于Redis的租约锁（带围栏令牌和自动续约）和基于PostgreSQL
+// 会话级咨询锁的实现。Elector 在任一锁实现之上提供选主，用于保证后台循环
+// （过期服务清理、告警评估、密钥轮换等）同一时刻只在一个副本上运行。
+package coordination
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 协调错误
+var (
+	// ErrNotAcquired 锁已被其他持有者占用
+	ErrNotAcquired = errors.New("lock not acquired")
+	// ErrLockLost 锁已过期或被其他持有者获取
+	ErrLockLost = errors.New("lock lost")
+)
+
+// 默认值
+const (
+	// DefaultLockTTL 未指定TTL时锁的租期
+	DefaultLockTTL = 15 * time.Second
+	// DefaultRetryInterval Acquire 重试获取锁的间隔
+	DefaultRetryInterval = 100 * time.Millisecond
+)
+
+// Locker 分布式锁
+type Locker interface {
+	// TryAcquire 尝试获取锁，锁被占用时立即返回 ErrNotAcquired。
+	// ttl 为锁的租期，持有期间自动续约；ttl<=0 时使用 DefaultLockTTL
+	TryAcquire(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+	// Acquire 阻塞直到获取锁或 ctx 结束
+	Acquire(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+}
+
+// Lock 已获取的锁
+type Lock interface {
+	// Name 锁名称
+	Name() string
+	// Token 围栏令牌，同一把锁每次被获取时单调递增。
+	// 写外部资源时携带令牌，资源方拒绝比已见令牌更小的请求，
+	// 可避免持有者暂停（GC、网络分区）后锁过期导致的并发写
+	Token() int64
+	// Lost 锁丢失（续约失败、过期或连接断开）时关闭
+	Lost() <-chan struct{}
+	// Release 释放锁，锁已丢失时返回 ErrLockLost
+	Release(ctx context.Context) error
+}
+
+// acquireWithRetry 按间隔重试 tryAcquire 直到成功或 ctx 结束
+func acquireWithRetry(ctx context.Context, interval time.Duration, tryAcquire func() (Lock, error)) (Lock, error) {
+	if interval <= 0 {
+		interval = DefaultRetryInterval
+	}
+
+	for {
+		lock, err := tryAcquire()
+		if !errors.Is(err, ErrNotAcquired) {
+			return lock, err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package coordination
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PostgresLocker 基于PostgreSQL会话级咨询锁的分布式锁
+//
+// 每把锁独占一个数据库连接，连接断开时锁由数据库自动释放，因此不需要续约；
+// 持有期间每 TTL/3 检查一次连接，检查失败视为锁丢失。
+// 围栏令牌取获取锁后的事务ID（txid_current），在整个集群内单调递增。
+type PostgresLocker struct {
+	db            *sql.DB
+	retryInterval time.Duration
+	logger        *zap.Logger
+}
+
+// NewPostgresLocker 创建PostgreSQL分布式锁
+func NewPostgresLocker(db *sql.DB, logger *zap.Logger) *PostgresLocker {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &PostgresLocker{
+		db:            db,
+		retryInterval: DefaultRetryInterval,
+		logger:        logger,
+	}
+}
+
+// TryAcquire 尝试获取锁
+func (l *PostgresLocker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+
+	key := advisoryLockKey(name)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, ErrNotAcquired
+	}
+
+	var token int64
+	if err := conn.QueryRowContext(ctx, "SELECT txid_current()").Scan(&token); err != nil {
+		// 关闭连接即释放咨询锁
+		conn.Close()
+		return nil, fmt.Errorf("failed to get fencing token for lock %s: %w", name, err)
+	}
+
+	lock := &postgresLock{
+		locker:   l,
+		name:     name,
+		key:      key,
+		token:    token,
+		interval: ttl / 3,
+		conn:     conn,
+		lost:     make(chan struct{}),
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go lock.watch()
+	return lock, nil
+}
+
+// Acquire 阻塞直到获取锁或 ctx 结束
+func (l *PostgresLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	return acquireWithRetry(ctx, l.retryInterval, func() (Lock, error) {
+		return l.TryAcquire(ctx, name, ttl)
+	})
+}
+
+// postgresLock 已获取的咨询锁
+type postgresLock struct {
+	locker   *PostgresLocker
+	name     string
+	key      int64
+	token    int64
+	interval time.Duration
+	conn     *sql.Conn
+
+	lost     chan struct{}
+	lostOnce sync.Once
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func (l *postgresLock) Name() string          { return l.name }
+func (l *postgresLock) Token() int64          { return l.token }
+func (l *postgresLock) Lost() <-chan struct{} { return l.lost }
+
+// watch 定期检查持有锁的连接
+func (l *postgresLock) watch() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), l.interval)
+		err := l.conn.PingContext(ctx)
+		cancel()
+		if err != nil {
+			l.locker.logger.Warn("Lock connection lost", zap.String("lock", l.name), zap.Error(err))
+			l.conn.Close()
+			l.lostOnce.Do(func() { close(l.lost) })
+			return
+		}
+	}
+}
+
+// Release 解锁并归还连接
+func (l *postgresLock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stopCh) })
+	<-l.done
+
+	select {
+	case <-l.lost:
+		return ErrLockLost
+	default:
+	}
+	defer l.lostOnce.Do(func() { close(l.lost) })
+	defer l.conn.Close()
+
+	var released bool
+	if err := l.conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.key).Scan(&released); err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.name, err)
+	}
+	if !released {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// advisoryLockKey 将锁名称映射为咨询锁的键
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("laojun:lock:" + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,206 @@
+package coordination
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Lua 脚本保证检查持有者和修改锁的原子性
+const (
+	// acquireLua 获取锁成功时递增并返回围栏令牌，失败返回0
+	acquireLua = `if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0`
+
+	// renewLua 仅当锁仍属于调用者时延长租期
+	renewLua = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`
+
+	// releaseLua 仅当锁仍属于调用者时删除
+	releaseLua = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+)
+
+var (
+	acquireScript = redis.NewScript(acquireLua)
+	renewScript   = redis.NewScript(renewLua)
+	releaseScript = redis.NewScript(releaseLua)
+)
+
+// RedisLocker 基于Redis的分布式锁
+//
+// 锁以带过期时间的键保存，值为持有者的随机ID；持有期间每 TTL/3 续约一次，
+// 续约在租期内一直失败时视为锁丢失。
+//
+// 键布局（锁名作为哈希标签，集群模式下两个键位于同一槽位）：
+//
+//	<prefix>:{<name>}        持有者ID，带过期时间
+//	<prefix>:{<name>}:fence  围栏令牌计数器
+type RedisLocker struct {
+	client        redis.UniversalClient
+	prefix        string
+	retryInterval time.Duration
+	logger        *zap.Logger
+}
+
+// NewRedisLocker 创建Redis分布式锁，prefix 为空时使用 "laojun:lock"
+func NewRedisLocker(client redis.UniversalClient, prefix string, logger *zap.Logger) *RedisLocker {
+	if prefix == "" {
+		prefix = "laojun:lock"
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &RedisLocker{
+		client:        client,
+		prefix:        prefix,
+		retryInterval: DefaultRetryInterval,
+		logger:        logger,
+	}
+}
+
+// TryAcquire 尝试获取锁
+func (l *RedisLocker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	if ttl <= 0 {
+		ttl = DefaultLockTTL
+	}
+
+	owner, err := newOwnerID()
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.prefix + ":{" + name + "}"
+	token, err := acquireScript.Run(ctx, l.client, []string{key, key + ":fence"}, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if token == 0 {
+		return nil, ErrNotAcquired
+	}
+
+	lock := &redisLock{
+		locker: l,
+		name:   name,
+		key:    key,
+		owner:  owner,
+		token:  token,
+		ttl:    ttl,
+		lost:   make(chan struct{}),
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go lock.renew()
+	return lock, nil
+}
+
+// Acquire 阻塞直到获取锁或 ctx 结束
+func (l *RedisLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (Lock, error) {
+	return acquireWithRetry(ctx, l.retryInterval, func() (Lock, error) {
+		return l.TryAcquire(ctx, name, ttl)
+	})
+}
+
+// redisLock 已获取的Redis锁
+type redisLock struct {
+	locker *RedisLocker
+	name   string
+	key    string
+	owner  string
+	token  int64
+	ttl    time.Duration
+
+	lost     chan struct{}
+	lostOnce sync.Once
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func (l *redisLock) Name() string          { return l.name }
+func (l *redisLock) Token() int64          { return l.token }
+func (l *redisLock) Lost() <-chan struct{} { return l.lost }
+
+// renew 定期续约，直到释放或丢失
+func (l *redisLock) renew() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	// 最后一次续约成功后锁的过期时间，续约出错但未过期前继续重试
+	expiresAt := time.Now().Add(l.ttl)
+	for {
+		select {
+		case <-l.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+		renewed, err := renewScript.Run(ctx, l.locker.client, []string{l.key}, l.owner, l.ttl.Milliseconds()).Int64()
+		cancel()
+
+		switch {
+		case err == nil && renewed == 1:
+			expiresAt = time.Now().Add(l.ttl)
+		case err == nil:
+			l.locker.logger.Warn("Lock taken over by another owner", zap.String("lock", l.name))
+			l.markLost()
+			return
+		case time.Now().After(expiresAt):
+			l.locker.logger.Warn("Lock expired after renewal failures", zap.String("lock", l.name), zap.Error(err))
+			l.markLost()
+			return
+		default:
+			l.locker.logger.Debug("Failed to renew lock", zap.String("lock", l.name), zap.Error(err))
+		}
+	}
+}
+
+func (l *redisLock) markLost() {
+	l.lostOnce.Do(func() { close(l.lost) })
+}
+
+// Release 停止续约并删除锁
+func (l *redisLock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stopCh) })
+	<-l.done
+
+	select {
+	case <-l.lost:
+		return ErrLockLost
+	default:
+	}
+	defer l.markLost()
+
+	released, err := releaseScript.Run(ctx, l.locker.client, []string{l.key}, l.owner).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.name, err)
+	}
+	if released == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// newOwnerID 生成锁持有者的随机ID
+func newOwnerID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock owner: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -207,6 +207,8 @@ func SHA256HashBytes(data []byte) string {
 // EncryptionHelper encryption helper
 type EncryptionHelper struct {
 	encryptor      *Encryptor
+	previous       []*Encryptor // retired by RotateKey, newest first, still used for decryption
+	keyring        *Keyring
 	passwordHasher *PasswordHasher
 }
 
@@ -226,14 +228,40 @@ func NewEncryptionHelper(secretKey string) *EncryptionHelper {
 	}
 }
 
+// NewEncryptionHelperWithKeyring creates an encryption helper that encrypts with
+// the keyring's primary key. Key rotation is then managed by the keyring source.
+func NewEncryptionHelperWithKeyring(keyring *Keyring) *EncryptionHelper {
+	return &EncryptionHelper{
+		keyring:        keyring,
+		passwordHasher: NewPasswordHasher(bcrypt.DefaultCost),
+	}
+}
+
 // Encrypt encrypts sensitive data
 func (eh *EncryptionHelper) Encrypt(data string) (string, error) {
+	if eh.keyring != nil {
+		return eh.keyring.EncryptString(data)
+	}
 	return eh.encryptor.Encrypt(data)
 }
 
-// Decrypt decrypts sensitive data
+// Decrypt decrypts sensitive data, trying keys retired by RotateKey when the
+// current key fails
 func (eh *EncryptionHelper) Decrypt(encryptedData string) (string, error) {
-	return eh.encryptor.Decrypt(encryptedData)
+	if eh.keyring != nil {
+		return eh.keyring.DecryptString(encryptedData)
+	}
+
+	plaintext, err := eh.encryptor.Decrypt(encryptedData)
+	if err == nil {
+		return plaintext, nil
+	}
+	for _, previous := range eh.previous {
+		if plaintext, prevErr := previous.Decrypt(encryptedData); prevErr == nil {
+			return plaintext, nil
+		}
+	}
+	return "", err
 }
 
 // HashPassword hashes password
@@ -251,7 +279,7 @@ func (eh *EncryptionHelper) EncryptConfig(value string, shouldEncrypt bool) (str
 	if !shouldEncrypt {
 		return value, nil
 	}
-	return eh.encryptor.Encrypt(value)
+	return eh.Encrypt(value)
 }
 
 // DecryptConfig decrypts configuration value
@@ -259,7 +287,7 @@ func (eh *EncryptionHelper) DecryptConfig(value string, isEncrypted bool) (strin
 	if !isEncrypted {
 		return value, nil
 	}
-	return eh.encryptor.Decrypt(value)
+	return eh.Decrypt(value)
 }
 
 // GenerateAPIKey generates API key
@@ -291,12 +319,17 @@ func (eh *EncryptionHelper) ValidateEncryptedData(encryptedData string) bool {
 	}
 
 	// Try to decrypt data to validate its integrity
-	_, err := eh.encryptor.Decrypt(encryptedData)
+	_, err := eh.Decrypt(encryptedData)
 	return err == nil
 }
 
-// RotateKey rotates encryption key
+// RotateKey rotates encryption key. The old key is kept for decryption, use
+// ReencryptData or a Reencryptor to migrate stored data to the new key
 func (eh *EncryptionHelper) RotateKey(newSecretKey string) error {
+	if eh.keyring != nil {
+		return errors.New("keyring-backed helper rotates keys through its key source")
+	}
+
 	config := EncryptionConfig{
 		SecretKey: newSecretKey,
 		Salt:      "laojun-salt",
@@ -307,6 +340,7 @@ func (eh *EncryptionHelper) RotateKey(newSecretKey string) error {
 		return fmt.Errorf("failed to create new encryptor: %w", err)
 	}
 	
+	eh.previous = append([]*Encryptor{eh.encryptor}, eh.previous...)
 	eh.encryptor = newEncryptor
 	return nil
 }
@@ -320,7 +354,7 @@ func (eh *EncryptionHelper) ReencryptData(oldEncryptedData string, oldEncryptor
 	}
 
 	// Encrypt data with new encryptor
-	newEncryptedData, err := eh.encryptor.Encrypt(plaintext)
+	newEncryptedData, err := eh.Encrypt(plaintext)
 	if err != nil {
 		return "", fmt.Errorf("failed to encrypt with new key: %w", err)
 	}
@@ -0,0 +1,339 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// envelopeMagic prefixes envelope ciphertext: "LE" followed by the format version.
+var envelopeMagic = []byte{'L', 'E', 1}
+
+// DefaultSegmentSize is the plaintext size of each envelope segment.
+const DefaultSegmentSize = 64 * 1024
+
+// Envelope encryption encrypts the payload with a random data key (DEK) and
+// stores the DEK wrapped by a keyring key. Large payloads are streamed in
+// independently authenticated segments, and rotating the keyring only requires
+// rewrapping the DEK in the header (RewrapEnvelope), not re-encrypting the payload.
+//
+// Layout:
+//
+//	"LE" 0x01 | len(keyID) | keyID | uint16 len(wrapped) | wrapped DEK | uint32 segment size | segments...
+//
+// Each segment is AES-GCM sealed with the DEK under a nonce made of the
+// segment counter and a final-segment flag, so reordering, dropping or
+// truncating segments fails authentication.
+
+// EncryptEnvelope encrypts plaintext as a single envelope.
+func (k *Keyring) EncryptEnvelope(plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := k.NewEnvelopeWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecryptEnvelope decrypts an envelope produced by EncryptEnvelope or NewEnvelopeWriter.
+func (k *Keyring) DecryptEnvelope(data []byte) ([]byte, error) {
+	r, err := k.NewEnvelopeReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// NewEnvelopeWriter returns a writer that encrypts everything written to it
+// into dst. Close must be called to write the final segment; it does not close dst.
+func (k *Keyring) NewEnvelopeWriter(dst io.Writer) (io.WriteCloser, error) {
+	dek, err := GenerateRandomBytes(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := k.envelopeHeader(dek, DefaultSegmentSize)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &envelopeWriter{
+		dst:         dst,
+		aead:        aead,
+		segmentSize: DefaultSegmentSize,
+		buf:         make([]byte, 0, DefaultSegmentSize),
+	}, nil
+}
+
+// NewEnvelopeReader returns a reader that decrypts the envelope read from src.
+// Data is only returned after its segment has been authenticated; a truncated
+// envelope yields ErrInvalidCiphertext instead of io.EOF.
+func (k *Keyring) NewEnvelopeReader(src io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(src)
+	header, err := readEnvelopeHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	dek, err := k.unwrapDataKey(header.keyID, header.wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &envelopeReader{
+		src:     br,
+		aead:    aead,
+		segment: make([]byte, int(header.segmentSize)+aead.Overhead()),
+	}, nil
+}
+
+// RewrapEnvelope copies an envelope from src to dst with its data key wrapped
+// by the current primary key. The payload segments are copied unchanged.
+func (k *Keyring) RewrapEnvelope(dst io.Writer, src io.Reader) error {
+	br := bufio.NewReader(src)
+	header, err := readEnvelopeHeader(br)
+	if err != nil {
+		return err
+	}
+	dek, err := k.unwrapDataKey(header.keyID, header.wrapped)
+	if err != nil {
+		return err
+	}
+
+	newHeader, err := k.envelopeHeader(dek, header.segmentSize)
+	if err != nil {
+		return err
+	}
+	if _, err := dst.Write(newHeader); err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, br)
+	return err
+}
+
+// Rewrap is RewrapEnvelope for in-memory envelopes.
+func (k *Keyring) Rewrap(envelope []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(len(envelope))
+	if err := k.RewrapEnvelope(&buf, bytes.NewReader(envelope)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// IsEnvelope reports whether data starts with an envelope header.
+func IsEnvelope(data []byte) bool {
+	return bytes.HasPrefix(data, envelopeMagic)
+}
+
+// envelopeHeader wraps dek with the primary key and builds the header.
+func (k *Keyring) envelopeHeader(dek []byte, segmentSize uint32) ([]byte, error) {
+	keys := k.keys.Load()
+	aead := keys.aeads[keys.primary]
+
+	prefix := keyHeader(envelopeMagic, keys.primary)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	wrapped := aead.Seal(nonce, nonce, dek, prefix)
+
+	header := make([]byte, 0, len(prefix)+2+len(wrapped)+4)
+	header = append(header, prefix...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+	header = append(header, wrapped...)
+	return binary.BigEndian.AppendUint32(header, segmentSize), nil
+}
+
+func (k *Keyring) unwrapDataKey(keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := k.keys.Load().aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: wrapped key too short", ErrInvalidCiphertext)
+	}
+	dek, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], keyHeader(envelopeMagic, keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to unwrap data key: %v", ErrInvalidCiphertext, err)
+	}
+	return dek, nil
+}
+
+type envelopeHeaderFields struct {
+	keyID       string
+	wrapped     []byte
+	segmentSize uint32
+}
+
+func readEnvelopeHeader(r io.Reader) (envelopeHeaderFields, error) {
+	var h envelopeHeaderFields
+	invalid := func(err error) (envelopeHeaderFields, error) {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return h, fmt.Errorf("%w: truncated header", ErrInvalidCiphertext)
+		}
+		return h, err
+	}
+
+	prefix := make([]byte, len(envelopeMagic)+1)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return invalid(err)
+	}
+	if !bytes.Equal(prefix[:len(envelopeMagic)], envelopeMagic) || prefix[len(envelopeMagic)] == 0 {
+		return h, fmt.Errorf("%w: missing header", ErrInvalidCiphertext)
+	}
+
+	keyID := make([]byte, prefix[len(envelopeMagic)])
+	if _, err := io.ReadFull(r, keyID); err != nil {
+		return invalid(err)
+	}
+	h.keyID = string(keyID)
+
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:2]); err != nil {
+		return invalid(err)
+	}
+	h.wrapped = make([]byte, binary.BigEndian.Uint16(size[:2]))
+	if _, err := io.ReadFull(r, h.wrapped); err != nil {
+		return invalid(err)
+	}
+
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return invalid(err)
+	}
+	h.segmentSize = binary.BigEndian.Uint32(size[:])
+	if h.segmentSize == 0 || h.segmentSize > 16*1024*1024 {
+		return h, fmt.Errorf("%w: invalid segment size %d", ErrInvalidCiphertext, h.segmentSize)
+	}
+	return h, nil
+}
+
+// segmentNonce is the big endian segment counter followed by the final-segment flag.
+func segmentNonce(size int, counter uint64, last bool) []byte {
+	nonce := make([]byte, size)
+	binary.BigEndian.PutUint64(nonce[size-9:size-1], counter)
+	if last {
+		nonce[size-1] = 1
+	}
+	return nonce
+}
+
+type envelopeWriter struct {
+	dst         io.Writer
+	aead        cipher.AEAD
+	segmentSize int
+	buf         []byte
+	counter     uint64
+	closed      bool
+}
+
+func (w *envelopeWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed envelope writer")
+	}
+	written := 0
+	for len(p) > 0 {
+		// keep a full segment buffered until more data arrives, so the final
+		// segment is only sealed on Close
+		if len(w.buf) == w.segmentSize {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):w.segmentSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *envelopeWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+func (w *envelopeWriter) flush(last bool) error {
+	nonce := segmentNonce(w.aead.NonceSize(), w.counter, last)
+	w.counter++
+	sealed := w.aead.Seal(nil, nonce, w.buf, nil)
+	w.buf = w.buf[:0]
+	_, err := w.dst.Write(sealed)
+	return err
+}
+
+type envelopeReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	segment []byte
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+func (r *envelopeReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next reads and authenticates the next segment. A segment is final when it
+// is shorter than a full segment or followed by EOF.
+func (r *envelopeReader) next() error {
+	n, err := io.ReadFull(r.src, r.segment)
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+		r.done = true
+	case err != nil:
+		return err
+	default:
+		if _, peekErr := r.src.Peek(1); peekErr == io.EOF {
+			r.done = true
+		}
+	}
+	if n < r.aead.Overhead() {
+		return fmt.Errorf("%w: truncated envelope", ErrInvalidCiphertext)
+	}
+
+	nonce := segmentNonce(r.aead.NonceSize(), r.counter, r.done)
+	r.counter++
+	plain, err := r.aead.Open(r.segment[:0], nonce, r.segment[:n], nil)
+	if err != nil {
+		return fmt.Errorf("%w: segment %d: %v", ErrInvalidCiphertext, r.counter-1, err)
+	}
+	r.plain = plain
+	return nil
+}
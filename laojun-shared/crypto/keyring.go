@@ -0,0 +1,317 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Keyring errors
+var (
+	// ErrUnknownKey is returned when ciphertext references a key that is not in the keyring.
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrInvalidCiphertext is returned when ciphertext is malformed or fails authentication.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	// ErrInvalidKeySet is returned when a key source yields an unusable key set.
+	ErrInvalidKeySet = errors.New("invalid key set")
+)
+
+// keyringMagic prefixes ciphertext produced by a Keyring: "LK" followed by the format version.
+var keyringMagic = []byte{'L', 'K', 1}
+
+// Key is a versioned AES key.
+type Key struct {
+	// ID identifies the key in ciphertext headers. It must be stable for the lifetime of the data.
+	ID string
+	// Material is a 16, 24 or 32 byte AES key.
+	Material []byte
+	// CreatedAt is informational.
+	CreatedAt time.Time
+}
+
+// KeySet is the set of keys a Keyring decrypts with and the key it encrypts with.
+type KeySet struct {
+	// Primary is the ID of the key used for new ciphertext.
+	Primary string
+	// Keys are all keys that may still be referenced by stored ciphertext.
+	Keys []Key
+}
+
+// Validate checks key sizes, ID uniqueness and that the primary key exists.
+func (s KeySet) Validate() error {
+	if len(s.Keys) == 0 {
+		return fmt.Errorf("%w: no keys", ErrInvalidKeySet)
+	}
+	seen := make(map[string]bool, len(s.Keys))
+	for _, key := range s.Keys {
+		if key.ID == "" || len(key.ID) > 255 {
+			return fmt.Errorf("%w: key ID must be 1-255 bytes", ErrInvalidKeySet)
+		}
+		if seen[key.ID] {
+			return fmt.Errorf("%w: duplicate key ID %q", ErrInvalidKeySet, key.ID)
+		}
+		seen[key.ID] = true
+		switch len(key.Material) {
+		case 16, 24, 32:
+		default:
+			return fmt.Errorf("%w: key %q must be 16, 24 or 32 bytes, got %d", ErrInvalidKeySet, key.ID, len(key.Material))
+		}
+	}
+	if !seen[s.Primary] {
+		return fmt.Errorf("%w: primary key %q not found", ErrInvalidKeySet, s.Primary)
+	}
+	return nil
+}
+
+// KeySource supplies the keys of a Keyring. Implementations are read on every Reload.
+type KeySource interface {
+	Keys(ctx context.Context) (KeySet, error)
+}
+
+// KeySourceFunc adapts a function to KeySource.
+type KeySourceFunc func(ctx context.Context) (KeySet, error)
+
+// Keys calls f.
+func (f KeySourceFunc) Keys(ctx context.Context) (KeySet, error) {
+	return f(ctx)
+}
+
+// StaticKeySource returns a fixed key set.
+func StaticKeySource(set KeySet) KeySource {
+	return KeySourceFunc(func(context.Context) (KeySet, error) { return set, nil })
+}
+
+// NewKey generates a random 256-bit key.
+func NewKey(id string) (Key, error) {
+	material, err := GenerateRandomBytes(32)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return Key{ID: id, Material: material, CreatedAt: time.Now()}, nil
+}
+
+// KeyFromSecret derives a key from a secret the same way NewEncryptor does, so
+// secrets configured for an Encryptor can be moved into a keyring unchanged.
+func KeyFromSecret(id string, config EncryptionConfig) (Key, error) {
+	material, err := scrypt.Key([]byte(config.SecretKey), []byte(config.Salt), 32768, 8, 1, 32)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return Key{ID: id, Material: material}, nil
+}
+
+// loadedKeys is an immutable snapshot of the keyring state.
+type loadedKeys struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// Keyring encrypts with one primary key and decrypts with any key it holds.
+//
+// Ciphertext is laid out as
+//
+//	"LK" 0x01 | len(keyID) | keyID | nonce | AES-GCM(plaintext)
+//
+// and the header is authenticated as additional data, so a key ID cannot be
+// swapped without failing decryption. Rotating keys means adding a key to the
+// source, making it primary and calling Reload; old keys stay available for
+// decryption until every record has been re-encrypted (see Reencryptor).
+type Keyring struct {
+	source KeySource
+	keys   atomic.Pointer[loadedKeys]
+	legacy atomic.Pointer[Encryptor]
+}
+
+// NewKeyring loads the keys from source.
+func NewKeyring(ctx context.Context, source KeySource) (*Keyring, error) {
+	k := &Keyring{source: source}
+	if err := k.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload re-reads the key source. The previous keys stay in use if loading fails.
+func (k *Keyring) Reload(ctx context.Context) error {
+	set, err := k.source.Keys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load keys: %w", err)
+	}
+	if err := set.Validate(); err != nil {
+		return err
+	}
+
+	loaded := &loadedKeys{primary: set.Primary, aeads: make(map[string]cipher.AEAD, len(set.Keys))}
+	for _, key := range set.Keys {
+		aead, err := newGCM(key.Material)
+		if err != nil {
+			return err
+		}
+		loaded.aeads[key.ID] = aead
+	}
+	k.keys.Store(loaded)
+	return nil
+}
+
+// Run reloads the keys every interval until ctx is done, so rotated keys are
+// picked up without a restart. Load errors keep the previous keys.
+func (k *Keyring) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Reload(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// SetLegacy lets Decrypt fall back to an Encryptor for ciphertext written before
+// the keyring was introduced. Such ciphertext always needs re-encryption.
+func (k *Keyring) SetLegacy(e *Encryptor) {
+	k.legacy.Store(e)
+}
+
+// PrimaryKeyID returns the ID of the key used for encryption.
+func (k *Keyring) PrimaryKeyID() string {
+	return k.keys.Load().primary
+}
+
+// Encrypt encrypts plaintext with the primary key.
+func (k *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	keys := k.keys.Load()
+	aead := keys.aeads[keys.primary]
+
+	header := keyHeader(keyringMagic, keys.primary)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// Decrypt decrypts ciphertext produced by Encrypt with any key in the keyring,
+// or by the legacy Encryptor when one is set.
+func (k *Keyring) Decrypt(ciphertext []byte) ([]byte, error) {
+	plaintext, err := k.decrypt(ciphertext)
+	if err == nil {
+		return plaintext, nil
+	}
+	if legacy := k.legacy.Load(); legacy != nil {
+		if plaintext, legacyErr := legacy.DecryptBytes(ciphertext); legacyErr == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, err
+}
+
+func (k *Keyring) decrypt(ciphertext []byte) ([]byte, error) {
+	keyID, headerLen, err := parseKeyHeader(keyringMagic, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := k.keys.Load().aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+
+	header, rest := ciphertext[:headerLen], ciphertext[headerLen:]
+	if len(rest) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidCiphertext)
+	}
+	plaintext, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	return plaintext, nil
+}
+
+// EncryptString encrypts s and returns base64 encoded ciphertext.
+func (k *Keyring) EncryptString(s string) (string, error) {
+	ciphertext, err := k.Encrypt([]byte(s))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptString decrypts base64 encoded ciphertext produced by EncryptString.
+func (k *Keyring) DecryptString(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode base64: %w", err)
+	}
+	plaintext, err := k.Decrypt(data)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// KeyID returns the ID of the key ciphertext was encrypted with. Envelopes
+// report the key that wrapped their data key.
+func (k *Keyring) KeyID(ciphertext []byte) (string, error) {
+	if keyID, _, err := parseKeyHeader(keyringMagic, ciphertext); err == nil {
+		return keyID, nil
+	}
+	keyID, _, err := parseKeyHeader(envelopeMagic, ciphertext)
+	return keyID, err
+}
+
+// NeedsReencryption reports whether ciphertext (plain or envelope) was not
+// encrypted with the current primary key.
+func (k *Keyring) NeedsReencryption(ciphertext []byte) bool {
+	keyID, err := k.KeyID(ciphertext)
+	return err != nil || keyID != k.PrimaryKeyID()
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// keyHeader builds magic | len(keyID) | keyID.
+func keyHeader(magic []byte, keyID string) []byte {
+	header := make([]byte, 0, len(magic)+1+len(keyID))
+	header = append(header, magic...)
+	header = append(header, byte(len(keyID)))
+	return append(header, keyID...)
+}
+
+// parseKeyHeader returns the key ID and header length.
+func parseKeyHeader(magic, data []byte) (string, int, error) {
+	if len(data) < len(magic)+1 || string(data[:len(magic)]) != string(magic) {
+		return "", 0, fmt.Errorf("%w: missing header", ErrInvalidCiphertext)
+	}
+	idLen := int(data[len(magic)])
+	end := len(magic) + 1 + idLen
+	if idLen == 0 || len(data) < end {
+		return "", 0, fmt.Errorf("%w: truncated header", ErrInvalidCiphertext)
+	}
+	return string(data[len(magic)+1 : end]), end, nil
+}
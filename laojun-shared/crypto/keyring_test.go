@@ -0,0 +1,331 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func mustKey(t *testing.T, id string) Key {
+	t.Helper()
+	key, err := NewKey(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKeyringRotation(t *testing.T) {
+	ctx := context.Background()
+	k1, k2 := mustKey(t, "k1"), mustKey(t, "k2")
+
+	set := KeySet{Primary: "k1", Keys: []Key{k1}}
+	keyring, err := NewKeyring(ctx, KeySourceFunc(func(context.Context) (KeySet, error) { return set, nil }))
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	old, err := keyring.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set = KeySet{Primary: "k2", Keys: []Key{k1, k2}}
+	if err := keyring.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	fresh, _ := keyring.Encrypt([]byte("secret"))
+
+	for _, ciphertext := range [][]byte{old, fresh} {
+		plaintext, err := keyring.Decrypt(ciphertext)
+		if err != nil || string(plaintext) != "secret" {
+			t.Errorf("Decrypt() = %q, %v", plaintext, err)
+		}
+	}
+	if id, _ := keyring.KeyID(old); id != "k1" || !keyring.NeedsReencryption(old) || keyring.NeedsReencryption(fresh) {
+		t.Errorf("KeyID(old) = %q, NeedsReencryption(old, fresh) = %v, %v", id, keyring.NeedsReencryption(old), keyring.NeedsReencryption(fresh))
+	}
+
+	// 篡改头部的密钥ID导致认证失败
+	tampered := bytes.Replace(append([]byte(nil), fresh...), []byte("k2"), []byte("k1"), 1)
+	if _, err := keyring.Decrypt(tampered); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("Decrypt(tampered) error = %v", err)
+	}
+
+	// 删除旧密钥后旧密文无法解密，且加载失败时保留当前密钥
+	set = KeySet{Primary: "k2", Keys: []Key{k2}}
+	keyring.Reload(ctx)
+	if _, err := keyring.Decrypt(old); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt(old) after removal error = %v", err)
+	}
+	set = KeySet{Primary: "k3", Keys: []Key{k2}}
+	if err := keyring.Reload(ctx); !errors.Is(err, ErrInvalidKeySet) {
+		t.Errorf("Reload(missing primary) error = %v", err)
+	}
+	if keyring.PrimaryKeyID() != "k2" {
+		t.Errorf("PrimaryKeyID() = %q after failed reload", keyring.PrimaryKeyID())
+	}
+}
+
+func TestKeyringLegacyFallback(t *testing.T) {
+	config := EncryptionConfig{SecretKey: "old-secret", Salt: "laojun-salt"}
+	legacy, err := NewEncryptor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyCiphertext, _ := legacy.EncryptString("token")
+
+	keyring, err := NewKeyring(context.Background(), StaticKeySource(KeySet{Primary: "k1", Keys: []Key{mustKey(t, "k1")}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keyring.DecryptString(legacyCiphertext); err == nil {
+		t.Fatal("legacy ciphertext decrypted without legacy encryptor")
+	}
+
+	keyring.SetLegacy(legacy)
+	if plaintext, err := keyring.DecryptString(legacyCiphertext); err != nil || plaintext != "token" {
+		t.Errorf("DecryptString(legacy) = %q, %v", plaintext, err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(legacyCiphertext)
+	if !keyring.NeedsReencryption(data) {
+		t.Error("legacy ciphertext should need re-encryption")
+	}
+
+	// 由同一密钥派生的 keyring 密钥
+	key, err := KeyFromSecret("legacy", config)
+	if err != nil || !bytes.Equal(key.Material, legacy.key) {
+		t.Errorf("KeyFromSecret() did not match NewEncryptor, err = %v", err)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+	k1, k2 := mustKey(t, "k1"), mustKey(t, "k2")
+	set := KeySet{Primary: "k1", Keys: []Key{k1, k2}}
+	keyring, _ := NewKeyring(ctx, KeySourceFunc(func(context.Context) (KeySet, error) { return set, nil }))
+
+	for _, size := range []int{0, 10, DefaultSegmentSize, 2*DefaultSegmentSize + 1} {
+		plaintext, _ := GenerateRandomBytes(size)
+		envelope, err := keyring.EncryptEnvelope(plaintext)
+		if err != nil {
+			t.Fatalf("EncryptEnvelope(%d) error = %v", size, err)
+		}
+		got, err := keyring.DecryptEnvelope(envelope)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Fatalf("DecryptEnvelope(%d) mismatch, err = %v", size, err)
+		}
+
+		// 截断最后一个分段或去掉整段都应失败
+		for _, cut := range []int{1, 16 + size%DefaultSegmentSize} {
+			if cut > len(envelope) {
+				continue
+			}
+			if _, err := keyring.DecryptEnvelope(envelope[:len(envelope)-cut]); !errors.Is(err, ErrInvalidCiphertext) {
+				t.Errorf("size %d truncated by %d: error = %v", size, cut, err)
+			}
+		}
+	}
+
+	plaintext := bytes.Repeat([]byte("x"), DefaultSegmentSize+100)
+	envelope, _ := keyring.EncryptEnvelope(plaintext)
+
+	set.Primary = "k2"
+	keyring.Reload(ctx)
+	if !keyring.NeedsReencryption(envelope) {
+		t.Fatal("envelope wrapped by k1 should need re-encryption")
+	}
+	rewrapped, err := keyring.Rewrap(envelope)
+	if err != nil {
+		t.Fatalf("Rewrap() error = %v", err)
+	}
+	if id, _ := keyring.KeyID(rewrapped); id != "k2" {
+		t.Errorf("rewrapped key ID = %q", id)
+	}
+	// 只替换头部，分段保持不变
+	if !bytes.Equal(rewrapped[len(rewrapped)-100:], envelope[len(envelope)-100:]) {
+		t.Error("rewrap changed payload segments")
+	}
+	if got, err := keyring.DecryptEnvelope(rewrapped); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("DecryptEnvelope(rewrapped) err = %v", err)
+	}
+}
+
+func TestKeySources(t *testing.T) {
+	ctx := context.Background()
+	k1, k2 := mustKey(t, "2024-01"), mustKey(t, "2024-06")
+	encode := func(key Key) string { return base64.StdEncoding.EncodeToString(key.Material) }
+
+	t.Setenv("TEST_KEYS_KEYS", fmt.Sprintf("%s:%s, %s:%s", k1.ID, encode(k1), k2.ID, encode(k2)))
+	set, err := EnvKeySource{Prefix: "TEST_KEYS"}.Keys(ctx)
+	if err != nil || set.Primary != "2024-06" || len(set.Keys) != 2 || set.Validate() != nil {
+		t.Errorf("EnvKeySource = %+v, %v", set, err)
+	}
+	t.Setenv("TEST_KEYS_PRIMARY_KEY", "2024-01")
+	if set, _ := (EnvKeySource{Prefix: "TEST_KEYS"}).Keys(ctx); set.Primary != "2024-01" {
+		t.Errorf("EnvKeySource primary = %q", set.Primary)
+	}
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	content := fmt.Sprintf(`{"primary": "2024-06", "keys": [{"id": "2024-01", "key": %q}, {"id": "2024-06", "key": %q}]}`, encode(k1), encode(k2))
+	os.WriteFile(path, []byte(content), 0o600)
+	keyring, err := NewKeyring(ctx, FileKeySource{Path: path})
+	if err != nil || keyring.PrimaryKeyID() != "2024-06" {
+		t.Fatalf("FileKeySource keyring = %v, %v", keyring, err)
+	}
+
+	os.WriteFile(path, []byte(`{"primary": "2024-06", "keys": [{"id": "2024-06", "key": "short"}]}`), 0o600)
+	if err := keyring.Reload(ctx); !errors.Is(err, ErrInvalidKeySet) {
+		t.Errorf("Reload(invalid file) error = %v", err)
+	}
+}
+
+func TestVaultKeySource(t *testing.T) {
+	ctx := context.Background()
+	transit := NewTransitServer("dev-token")
+	server := httptest.NewServer(transit)
+	defer server.Close()
+
+	source := VaultKeySource{Address: server.URL, Token: "dev-token", KeyName: "plugins"}
+	if err := vaultRequest(ctx, nil, http.MethodPost, source.url("keys/plugins"), "dev-token", map[string]bool{"exportable": true}, nil); err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+
+	keyring, err := NewKeyring(ctx, source)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	old, _ := keyring.Encrypt([]byte("payload"))
+
+	if err := vaultRequest(ctx, nil, http.MethodPost, source.url("keys/plugins/rotate"), "dev-token", nil, nil); err != nil {
+		t.Fatalf("rotate key: %v", err)
+	}
+	keyring.Reload(ctx)
+	if keyring.PrimaryKeyID() != "plugins:v2" || !keyring.NeedsReencryption(old) {
+		t.Errorf("PrimaryKeyID() = %q after rotation", keyring.PrimaryKeyID())
+	}
+	if plaintext, err := keyring.Decrypt(old); err != nil || string(plaintext) != "payload" {
+		t.Errorf("Decrypt(old) = %q, %v", plaintext, err)
+	}
+
+	// 兼容 Vault transit 的加解密接口
+	var encrypted struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	vaultRequest(ctx, nil, http.MethodPost, source.url("encrypt/plugins"), "dev-token",
+		map[string]string{"plaintext": base64.StdEncoding.EncodeToString([]byte("hi"))}, &encrypted)
+	var decrypted struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := vaultRequest(ctx, nil, http.MethodPost, source.url("decrypt/plugins"), "dev-token",
+		map[string]string{"ciphertext": encrypted.Data.Ciphertext}, &decrypted); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if plaintext, _ := base64.StdEncoding.DecodeString(decrypted.Data.Plaintext); string(plaintext) != "hi" || encrypted.Data.Ciphertext[:9] != "vault:v2:" {
+		t.Errorf("transit round trip = %q, ciphertext %q", plaintext, encrypted.Data.Ciphertext)
+	}
+
+	if _, err := (VaultKeySource{Address: server.URL, Token: "wrong", KeyName: "plugins"}).Keys(ctx); err == nil {
+		t.Error("expected permission error")
+	}
+}
+
+func TestReencryptor(t *testing.T) {
+	ctx := context.Background()
+	k1, k2 := mustKey(t, "k1"), mustKey(t, "k2")
+	set := KeySet{Primary: "k1", Keys: []Key{k1, k2}}
+	keyring, _ := NewKeyring(ctx, KeySourceFunc(func(context.Context) (KeySet, error) { return set, nil }))
+
+	store := map[string][]byte{}
+	for i := 0; i < 5; i++ {
+		store[fmt.Sprintf("r%d", i)], _ = keyring.Encrypt([]byte(fmt.Sprintf("value-%d", i)))
+	}
+	store["envelope"], _ = keyring.EncryptEnvelope([]byte("large"))
+	store["corrupt"] = []byte("garbage")
+
+	set.Primary = "k2"
+	keyring.Reload(ctx)
+	store["r4"], _ = keyring.Encrypt([]byte("value-4"))
+
+	ids := make([]string, 0, len(store))
+	for id := range store {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	r := NewReencryptor(keyring, ReencryptConfig{
+		BatchSize: 2,
+		List: func(ctx context.Context, cursor string, limit int) ([]Record, string, error) {
+			start := sort.SearchStrings(ids, cursor)
+			if cursor != "" {
+				start++
+			}
+			var records []Record
+			for _, id := range ids[start:] {
+				if len(records) == limit {
+					break
+				}
+				records = append(records, Record{ID: id, Ciphertext: store[id]})
+			}
+			if len(records) < limit {
+				return records, "", nil
+			}
+			return records, records[len(records)-1].ID, nil
+		},
+		Update: func(ctx context.Context, record Record, ciphertext []byte) error {
+			store[record.ID] = ciphertext
+			return nil
+		},
+	}, nil)
+
+	stats, err := r.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if stats.Scanned != 7 || stats.Reencrypted != 5 || stats.Failed != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// 旧密钥可以移除
+	set = KeySet{Primary: "k2", Keys: []Key{k2}}
+	keyring.Reload(ctx)
+	for id, ciphertext := range store {
+		if id == "corrupt" {
+			continue
+		}
+		if IsEnvelope(ciphertext) {
+			if got, err := keyring.DecryptEnvelope(ciphertext); err != nil || string(got) != "large" {
+				t.Errorf("%s: %q, %v", id, got, err)
+			}
+		} else if _, err := keyring.Decrypt(ciphertext); err != nil {
+			t.Errorf("%s: %v", id, err)
+		}
+	}
+}
+
+func TestEncryptionHelperRotateKeepsOldKey(t *testing.T) {
+	helper := NewEncryptionHelper("first-secret")
+	old, _ := helper.Encrypt("api-token")
+
+	if err := helper.RotateKey("second-secret"); err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := helper.Decrypt(old); err != nil || plaintext != "api-token" {
+		t.Errorf("Decrypt(old) = %q, %v", plaintext, err)
+	}
+	if !helper.ValidateEncryptedData(old) {
+		t.Error("old ciphertext should still validate")
+	}
+}
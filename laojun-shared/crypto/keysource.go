@@ -0,0 +1,212 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultKeyEnvPrefix is the environment variable prefix used by EnvKeySource.
+const DefaultKeyEnvPrefix = "LAOJUN_ENCRYPTION"
+
+// EnvKeySource reads keys from environment variables:
+//
+//	<Prefix>_KEYS         comma separated "id:base64key" pairs
+//	<Prefix>_PRIMARY_KEY  ID of the encryption key, defaults to the last listed key
+type EnvKeySource struct {
+	Prefix string
+}
+
+// Keys implements KeySource.
+func (s EnvKeySource) Keys(ctx context.Context) (KeySet, error) {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = DefaultKeyEnvPrefix
+	}
+
+	var set KeySet
+	for _, pair := range strings.Split(os.Getenv(prefix+"_KEYS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(pair, ":")
+		if !ok {
+			return set, fmt.Errorf("%w: %s_KEYS entry must be id:base64key", ErrInvalidKeySet, prefix)
+		}
+		material, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return set, fmt.Errorf("%w: key %q is not valid base64", ErrInvalidKeySet, id)
+		}
+		set.Keys = append(set.Keys, Key{ID: id, Material: material})
+	}
+
+	set.Primary = os.Getenv(prefix + "_PRIMARY_KEY")
+	if set.Primary == "" && len(set.Keys) > 0 {
+		set.Primary = set.Keys[len(set.Keys)-1].ID
+	}
+	return set, nil
+}
+
+// keyFile is the JSON layout read by FileKeySource.
+type keyFile struct {
+	Primary string `json:"primary"`
+	Keys    []struct {
+		ID        string    `json:"id"`
+		Key       string    `json:"key"` // base64
+		CreatedAt time.Time `json:"created_at,omitempty"`
+	} `json:"keys"`
+}
+
+// FileKeySource reads keys from a JSON file, typically a mounted secret:
+//
+//	{"primary": "2024-06", "keys": [{"id": "2024-01", "key": "<base64>"}, {"id": "2024-06", "key": "<base64>"}]}
+//
+// The file is read on every Reload, so rotating means updating the file.
+type FileKeySource struct {
+	Path string
+}
+
+// Keys implements KeySource.
+func (s FileKeySource) Keys(ctx context.Context) (KeySet, error) {
+	var set KeySet
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return set, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return set, fmt.Errorf("%w: failed to parse %s: %v", ErrInvalidKeySet, s.Path, err)
+	}
+
+	set.Primary = file.Primary
+	for _, key := range file.Keys {
+		material, err := base64.StdEncoding.DecodeString(key.Key)
+		if err != nil {
+			return set, fmt.Errorf("%w: key %q is not valid base64", ErrInvalidKeySet, key.ID)
+		}
+		set.Keys = append(set.Keys, Key{ID: key.ID, Material: material, CreatedAt: key.CreatedAt})
+	}
+	return set, nil
+}
+
+// VaultKeySource reads an exportable key from a Vault transit engine (or the
+// TransitServer stand-in). Every key version becomes a keyring key with ID
+// "<name>:v<version>" and the latest version is primary, so rotating the
+// transit key and reloading the keyring rotates the encryption key.
+type VaultKeySource struct {
+	// Address is the Vault address, e.g. "http://127.0.0.1:8200".
+	Address string
+	// Token is sent as X-Vault-Token.
+	Token string
+	// Mount is the transit mount path, default "transit".
+	Mount string
+	// KeyName is the transit key name.
+	KeyName string
+	// Client defaults to a client with a 10 second timeout.
+	Client *http.Client
+}
+
+// Keys implements KeySource.
+func (s VaultKeySource) Keys(ctx context.Context) (KeySet, error) {
+	var set KeySet
+	var export struct {
+		Data struct {
+			Keys map[string]string `json:"keys"`
+		} `json:"data"`
+	}
+	if err := vaultRequest(ctx, s.Client, http.MethodGet, s.url("export/encryption-key/"+s.KeyName), s.Token, nil, &export); err != nil {
+		return set, err
+	}
+
+	latest := 0
+	versions := make(map[string]int, len(export.Data.Keys))
+	for version, encoded := range export.Data.Keys {
+		n, err := strconv.Atoi(version)
+		if err != nil {
+			return set, fmt.Errorf("%w: invalid key version %q", ErrInvalidKeySet, version)
+		}
+		material, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return set, fmt.Errorf("%w: key version %s is not valid base64", ErrInvalidKeySet, version)
+		}
+		id := vaultKeyID(s.KeyName, n)
+		versions[id] = n
+		set.Keys = append(set.Keys, Key{ID: id, Material: material})
+		if n > latest {
+			latest = n
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return versions[set.Keys[i].ID] < versions[set.Keys[j].ID] })
+	set.Primary = vaultKeyID(s.KeyName, latest)
+	return set, nil
+}
+
+func (s VaultKeySource) url(path string) string {
+	mount := s.Mount
+	if mount == "" {
+		mount = "transit"
+	}
+	return strings.TrimRight(s.Address, "/") + "/v1/" + mount + "/" + path
+}
+
+func vaultKeyID(name string, version int) string {
+	return fmt.Sprintf("%s:v%d", name, version)
+}
+
+// vaultRequest performs a Vault API call and decodes the JSON response into out.
+func vaultRequest(ctx context.Context, client *http.Client, method, url, token string, body, out interface{}) error {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&failure)
+		return fmt.Errorf("vault %s %s: status %d: %s", method, url, resp.StatusCode, strings.Join(failure.Errors, "; "))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package crypto
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Record is a stored ciphertext visited by a Reencryptor.
+type Record struct {
+	ID         string
+	Ciphertext []byte
+}
+
+// ReencryptConfig configures a Reencryptor.
+type ReencryptConfig struct {
+	// List returns up to limit records after cursor ("" for the first page) and
+	// the cursor of the next page, "" when there are no more records.
+	List func(ctx context.Context, cursor string, limit int) (records []Record, next string, err error)
+	// Update stores the re-encrypted ciphertext. Implementations should make it
+	// conditional on the old ciphertext (compare-and-swap) so concurrent writes
+	// are not overwritten.
+	Update func(ctx context.Context, record Record, ciphertext []byte) error
+	// BatchSize is the page size passed to List, default 100.
+	BatchSize int
+	// Pause is the delay between pages to limit load on the store, default none.
+	Pause time.Duration
+}
+
+// ReencryptStats reports the progress of a Reencryptor.
+type ReencryptStats struct {
+	Scanned     uint64 `json:"scanned"`
+	Reencrypted uint64 `json:"reencrypted"`
+	Failed      uint64 `json:"failed"`
+}
+
+// Reencryptor walks stored records and re-encrypts those not encrypted with
+// the keyring's primary key, so old keys can be removed after a rotation.
+// Envelopes only have their data key rewrapped.
+type Reencryptor struct {
+	keyring *Keyring
+	config  ReencryptConfig
+	logger  *zap.Logger
+
+	scanned     atomic.Uint64
+	reencrypted atomic.Uint64
+	failed      atomic.Uint64
+}
+
+// NewReencryptor creates a Reencryptor.
+func NewReencryptor(keyring *Keyring, config ReencryptConfig, logger *zap.Logger) *Reencryptor {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Reencryptor{keyring: keyring, config: config, logger: logger}
+}
+
+// Run makes one pass over all records. Records that fail to decrypt or update
+// are logged, counted and skipped; Run only returns early when List fails or
+// ctx is done. Run it in a goroutine, on a single replica (see the
+// coordination package), and repeat until Stats reports no failures.
+func (r *Reencryptor) Run(ctx context.Context) (ReencryptStats, error) {
+	start := r.Stats()
+	cursor := ""
+	for {
+		records, next, err := r.config.List(ctx, cursor, r.config.BatchSize)
+		if err != nil {
+			return r.since(start), fmt.Errorf("failed to list records: %w", err)
+		}
+
+		for _, record := range records {
+			if err := ctx.Err(); err != nil {
+				return r.since(start), err
+			}
+			r.process(ctx, record)
+		}
+
+		if next == "" || len(records) == 0 {
+			return r.since(start), nil
+		}
+		cursor = next
+
+		if r.config.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return r.since(start), ctx.Err()
+			case <-time.After(r.config.Pause):
+			}
+		}
+	}
+}
+
+// Stats returns the totals across all runs.
+func (r *Reencryptor) Stats() ReencryptStats {
+	return ReencryptStats{
+		Scanned:     r.scanned.Load(),
+		Reencrypted: r.reencrypted.Load(),
+		Failed:      r.failed.Load(),
+	}
+}
+
+func (r *Reencryptor) since(start ReencryptStats) ReencryptStats {
+	now := r.Stats()
+	return ReencryptStats{
+		Scanned:     now.Scanned - start.Scanned,
+		Reencrypted: now.Reencrypted - start.Reencrypted,
+		Failed:      now.Failed - start.Failed,
+	}
+}
+
+func (r *Reencryptor) process(ctx context.Context, record Record) {
+	r.scanned.Add(1)
+	if !r.keyring.NeedsReencryption(record.Ciphertext) {
+		return
+	}
+
+	ciphertext, err := r.reencrypt(record.Ciphertext)
+	if err == nil {
+		err = r.config.Update(ctx, record, ciphertext)
+	}
+	if err != nil {
+		r.failed.Add(1)
+		r.logger.Warn("Failed to re-encrypt record", zap.String("id", record.ID), zap.Error(err))
+		return
+	}
+	r.reencrypted.Add(1)
+}
+
+func (r *Reencryptor) reencrypt(ciphertext []byte) ([]byte, error) {
+	if IsEnvelope(ciphertext) {
+		return r.keyring.Rewrap(ciphertext)
+	}
+	plaintext, err := r.keyring.Decrypt(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return r.keyring.Encrypt(plaintext)
+}
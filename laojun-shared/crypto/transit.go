@@ -0,0 +1,285 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TransitServer is an in-memory stand-in for the subset of the Vault transit
+// secrets engine used in local development and tests. It serves:
+//
+//	POST /v1/transit/keys/:name                 create a key ({"exportable": true})
+//	GET  /v1/transit/keys/:name                 read key metadata
+//	POST /v1/transit/keys/:name/rotate          add a new key version
+//	GET  /v1/transit/export/encryption-key/:name export all key versions (exportable keys only)
+//	POST /v1/transit/encrypt/:name              {"plaintext": base64} -> {"ciphertext": "vault:vN:..."}
+//	POST /v1/transit/decrypt/:name              {"ciphertext": "vault:vN:..."} -> {"plaintext": base64}
+//
+// Keys are lost when the process exits; it is not a substitute for Vault in production.
+type TransitServer struct {
+	token string
+
+	mu   sync.Mutex
+	keys map[string]*transitKey
+}
+
+type transitKey struct {
+	exportable bool
+	versions   [][]byte
+	created    []time.Time
+}
+
+// NewTransitServer creates a stand-in server. Requests must carry token in
+// X-Vault-Token unless token is empty.
+func NewTransitServer(token string) *TransitServer {
+	return &TransitServer{token: token, keys: make(map[string]*transitKey)}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *TransitServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && r.Header.Get("X-Vault-Token") != s.token {
+		writeVaultError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/transit/")
+	if path == r.URL.Path {
+		writeVaultError(w, http.StatusNotFound, "unsupported path")
+		return
+	}
+	parts := strings.Split(path, "/")
+
+	switch {
+	case len(parts) == 2 && parts[0] == "keys" && r.Method == http.MethodPost:
+		s.createKey(w, r, parts[1])
+	case len(parts) == 2 && parts[0] == "keys" && r.Method == http.MethodGet:
+		s.readKey(w, parts[1])
+	case len(parts) == 3 && parts[0] == "keys" && parts[2] == "rotate" && r.Method == http.MethodPost:
+		s.rotateKey(w, parts[1])
+	case len(parts) == 3 && parts[0] == "export" && parts[1] == "encryption-key" && r.Method == http.MethodGet:
+		s.exportKey(w, parts[2])
+	case len(parts) == 2 && parts[0] == "encrypt" && r.Method == http.MethodPost:
+		s.encrypt(w, r, parts[1])
+	case len(parts) == 2 && parts[0] == "decrypt" && r.Method == http.MethodPost:
+		s.decrypt(w, r, parts[1])
+	default:
+		writeVaultError(w, http.StatusNotFound, "unsupported path")
+	}
+}
+
+// CreateKey creates a key directly, without going through HTTP.
+func (s *TransitServer) CreateKey(name string, exportable bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.keys[name]; exists {
+		return nil
+	}
+	key := &transitKey{exportable: exportable}
+	if err := key.rotate(); err != nil {
+		return err
+	}
+	s.keys[name] = key
+	return nil
+}
+
+// RotateKey adds a new version to a key directly, without going through HTTP.
+func (s *TransitServer) RotateKey(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, name)
+	}
+	return key.rotate()
+}
+
+func (k *transitKey) rotate() error {
+	material, err := GenerateRandomBytes(32)
+	if err != nil {
+		return err
+	}
+	k.versions = append(k.versions, material)
+	k.created = append(k.created, time.Now())
+	return nil
+}
+
+func (s *TransitServer) createKey(w http.ResponseWriter, r *http.Request, name string) {
+	var req struct {
+		Exportable bool `json:"exportable"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			writeVaultError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if err := s.CreateKey(name, req.Exportable); err != nil {
+		writeVaultError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *TransitServer) readKey(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[name]
+	if !ok {
+		writeVaultError(w, http.StatusNotFound, "key not found")
+		return
+	}
+
+	versions := make(map[string]int64, len(key.versions))
+	for i, created := range key.created {
+		versions[strconv.Itoa(i+1)] = created.Unix()
+	}
+	writeVaultData(w, map[string]interface{}{
+		"name":           name,
+		"type":           "aes256-gcm96",
+		"exportable":     key.exportable,
+		"latest_version": len(key.versions),
+		"keys":           versions,
+	})
+}
+
+func (s *TransitServer) rotateKey(w http.ResponseWriter, name string) {
+	if err := s.RotateKey(name); err != nil {
+		writeVaultError(w, http.StatusNotFound, "key not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *TransitServer) exportKey(w http.ResponseWriter, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[name]
+	if !ok {
+		writeVaultError(w, http.StatusNotFound, "key not found")
+		return
+	}
+	if !key.exportable {
+		writeVaultError(w, http.StatusBadRequest, "key is not exportable")
+		return
+	}
+
+	versions := make(map[string]string, len(key.versions))
+	for i, material := range key.versions {
+		versions[strconv.Itoa(i+1)] = base64.StdEncoding.EncodeToString(material)
+	}
+	writeVaultData(w, map[string]interface{}{"name": name, "type": "encryption-key", "keys": versions})
+}
+
+func (s *TransitServer) encrypt(w http.ResponseWriter, r *http.Request, name string) {
+	var req struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeVaultError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(req.Plaintext)
+	if err != nil {
+		writeVaultError(w, http.StatusBadRequest, "plaintext must be base64 encoded")
+		return
+	}
+
+	s.mu.Lock()
+	key, ok := s.keys[name]
+	var material []byte
+	version := 0
+	if ok {
+		version = len(key.versions)
+		material = key.versions[version-1]
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeVaultError(w, http.StatusNotFound, "key not found")
+		return
+	}
+
+	aead, err := newGCM(material)
+	if err != nil {
+		writeVaultError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		writeVaultError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	writeVaultData(w, map[string]interface{}{
+		"ciphertext":  fmt.Sprintf("vault:v%d:%s", version, base64.StdEncoding.EncodeToString(sealed)),
+		"key_version": version,
+	})
+}
+
+func (s *TransitServer) decrypt(w http.ResponseWriter, r *http.Request, name string) {
+	var req struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeVaultError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	parts := strings.SplitN(req.Ciphertext, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" || !strings.HasPrefix(parts[1], "v") {
+		writeVaultError(w, http.StatusBadRequest, "invalid ciphertext")
+		return
+	}
+	version, err := strconv.Atoi(parts[1][1:])
+	if err != nil {
+		writeVaultError(w, http.StatusBadRequest, "invalid key version")
+		return
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		writeVaultError(w, http.StatusBadRequest, "invalid ciphertext")
+		return
+	}
+
+	s.mu.Lock()
+	key, ok := s.keys[name]
+	var material []byte
+	if ok && version >= 1 && version <= len(key.versions) {
+		material = key.versions[version-1]
+	}
+	s.mu.Unlock()
+	if material == nil {
+		writeVaultError(w, http.StatusBadRequest, "invalid key version")
+		return
+	}
+
+	aead, err := newGCM(material)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		writeVaultError(w, http.StatusBadRequest, "invalid ciphertext")
+		return
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		writeVaultError(w, http.StatusBadRequest, "cipher: message authentication failed")
+		return
+	}
+	writeVaultData(w, map[string]interface{}{"plaintext": base64.StdEncoding.EncodeToString(plaintext)})
+}
+
+func writeVaultData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func writeVaultError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{message}})
+}
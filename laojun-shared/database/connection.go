@@ -30,7 +30,10 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration // 连接最大生存时间
 	ConnMaxIdleTime time.Duration // 连接最大空闲时间
 	// 读写分离配置
-	ReadReplicas []ReplicaConfig
+	ReadReplicas         []ReplicaConfig
+	MaxReplicaLag        time.Duration // 从库最大复制延迟，超过后不再接收读请求，0表示不限制
+	ReplicaCheckInterval time.Duration // 从库健康和延迟检测间隔
+	ReadYourWritesWindow time.Duration // 写入后同一请求上下文中读主库的时间窗口
 
 	// 日志配置
 	LogLevel      logger.LogLevel
@@ -50,12 +53,14 @@ type ReplicaConfig struct {
 	DBName   string
 	SSLMode  string
 	TimeZone string
+	Weight   int // 选择权重，默认1
 }
 
 // DatabaseManager 数据库管理器
 type DatabaseManager struct {
 	db     *gorm.DB
 	config DatabaseConfig
+	router *ReplicaRouter
 }
 
 // NewDatabaseManager 创建新的数据库管理器
@@ -95,8 +100,10 @@ func NewDatabaseManager(config DatabaseConfig) (*DatabaseManager, error) {
 	configureConnectionPool(sqlDB, config)
 
 	// 配置读写分离
+	var router *ReplicaRouter
 	if len(config.ReadReplicas) > 0 {
-		if err := configureReadWriteSplit(db, config); err != nil {
+		router, err = configureReadWriteSplit(db, config, gormConfig)
+		if err != nil {
 			return nil, fmt.Errorf("failed to configure read-write split: %w", err)
 		}
 	}
@@ -104,6 +111,7 @@ func NewDatabaseManager(config DatabaseConfig) (*DatabaseManager, error) {
 	return &DatabaseManager{
 		db:     db,
 		config: config,
+		router: router,
 	}, nil
 }
 
@@ -112,8 +120,24 @@ func (dm *DatabaseManager) GetDB() *gorm.DB {
 	return dm.db
 }
 
+// Replicas 获取从库路由，未配置从库时返回nil
+func (dm *DatabaseManager) Replicas() *ReplicaRouter {
+	return dm.router
+}
+
+// ConnectionMonitor 获取包含从库状态的连接监控工具
+func (dm *DatabaseManager) ConnectionMonitor() *ConnectionMonitor {
+	return &ConnectionMonitor{db: dm.db, router: dm.router}
+}
+
 // Close 关闭数据库连接
 func (dm *DatabaseManager) Close() error {
+	if dm.router != nil {
+		if err := dm.router.Close(); err != nil {
+			return err
+		}
+	}
+
 	sqlDB, err := dm.db.DB()
 	if err != nil {
 		return err
@@ -216,6 +240,17 @@ func setDefaultConfig(config *DatabaseConfig) {
 	if config.TimeZone == "" {
 		config.TimeZone = "UTC"
 	}
+	if config.ReplicaCheckInterval == 0 {
+		config.ReplicaCheckInterval = 5 * time.Second
+	}
+	if config.ReadYourWritesWindow == 0 {
+		config.ReadYourWritesWindow = 2 * time.Second
+	}
+	for i := range config.ReadReplicas {
+		if config.ReadReplicas[i].Weight <= 0 {
+			config.ReadReplicas[i].Weight = 1
+		}
+	}
 }
 
 // buildDSN 构建数据源名
@@ -232,20 +267,46 @@ func configureConnectionPool(sqlDB *sql.DB, config DatabaseConfig) {
 	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 }
 
-// configureReadWriteSplit 配置读写分离
-func configureReadWriteSplit(db *gorm.DB, config DatabaseConfig) error {
-	var replicas []gorm.Dialector
+// configureReadWriteSplit 配置读写分离，按权重将只读查询路由到健康的从库
+func configureReadWriteSplit(db *gorm.DB, config DatabaseConfig, gormConfig *gorm.Config) (*ReplicaRouter, error) {
+	var replicas []*replica
 
-	for _, replica := range config.ReadReplicas {
-		dsn := buildDSN(replica.Host, replica.Port, replica.User, replica.Password,
-			replica.DBName, replica.SSLMode, replica.TimeZone)
-		replicas = append(replicas, postgres.Open(dsn))
+	closeReplicas := func() {
+		for _, r := range replicas {
+			r.db.Close()
+		}
 	}
 
-	return db.Use(dbresolver.Register(dbresolver.Config{
-		Replicas: replicas,
-		Policy:   dbresolver.RandomPolicy{}, // 随机选择从库
-	}))
+	for _, rc := range config.ReadReplicas {
+		dsn := buildDSN(rc.Host, rc.Port, rc.User, rc.Password,
+			rc.DBName, rc.SSLMode, rc.TimeZone)
+
+		replicaDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormConfig.Logger})
+		if err != nil {
+			closeReplicas()
+			return nil, fmt.Errorf("failed to connect to replica %s:%d: %w", rc.Host, rc.Port, err)
+		}
+		sqlDB, err := replicaDB.DB()
+		if err != nil {
+			closeReplicas()
+			return nil, fmt.Errorf("failed to get replica sql.DB: %w", err)
+		}
+		configureConnectionPool(sqlDB, config)
+
+		replicas = append(replicas, &replica{
+			name:      fmt.Sprintf("%s:%d", rc.Host, rc.Port),
+			weight:    rc.Weight,
+			db:        sqlDB,
+			dialector: postgres.New(postgres.Config{Conn: sqlDB}),
+		})
+	}
+
+	router, err := newReplicaRouter(db, replicas, config, postgresLagQuery)
+	if err != nil {
+		closeReplicas()
+		return nil, err
+	}
+	return router, nil
 }
 
 // QueryOptimizer 查询优化工具
@@ -386,7 +447,8 @@ func joinColumns(columns []string) string {
 
 // ConnectionMonitor 连接监控工具
 type ConnectionMonitor struct {
-	db *gorm.DB
+	db     *gorm.DB
+	router *ReplicaRouter
 }
 
 // NewConnectionMonitor 创建连接监控工具
@@ -412,9 +474,22 @@ func (cm *ConnectionMonitor) MonitorConnections() (map[string]interface{}, error
 		return nil, err
 	}
 
+	if cm.router != nil {
+		result["replicas"] = cm.router.Stats()
+		result["primary_reads"] = cm.router.PrimaryReads()
+	}
+
 	return result, nil
 }
 
+// ReplicaStats 获取从库状态，未配置从库时返回nil
+func (cm *ConnectionMonitor) ReplicaStats() []ReplicaStats {
+	if cm.router == nil {
+		return nil
+	}
+	return cm.router.Stats()
+}
+
 // GetLongRunningQueries 获取长时间运行的查询
 func (cm *ConnectionMonitor) GetLongRunningQueries(threshold time.Duration) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/codetaoist/laojun-shared/config"
 	_ "github.com/lib/pq"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 // DB 数据库连接包装器
@@ -44,6 +46,11 @@ func (db *DB) Close() error {
 	return db.DB.Close()
 }
 
+// Gorm 基于当前连接池创建 gorm.DB，供迁移等工具使用
+func (db *DB) Gorm() (*gorm.DB, error) {
+	return gorm.Open(postgres.New(postgres.Config{Conn: db.DB}), &gorm.Config{})
+}
+
 // Health 检查数据库健康状态
 func (db *DB) Health() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
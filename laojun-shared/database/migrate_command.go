@@ -0,0 +1,174 @@
+package database
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/codetaoist/laojun-shared/config"
+)
+
+const migrateUsage = `Usage: <service> migrate <command> [flags]
+
+Commands:
+  up [-dry-run]                              apply pending migrations
+  down [-steps N | -to VERSION] [-dry-run]   roll back migrations
+  status                                     show migration status
+  verify                                     validate files and detect edited migrations
+  repair                                     accept edited migrations and fix checksums
+  create NAME                                create a new SQL migration file
+`
+
+// MigrateMain 处理服务可执行文件的 migrate 子命令，返回进程退出码。
+// 各服务在 main 中调用：
+//
+//	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+//		os.Exit(database.MigrateMain(&cfg.Database, os.Getenv("MIGRATIONS_DIR"), os.Args[2:]))
+//	}
+func MigrateMain(cfg *config.DatabaseConfig, migrationsDir string, args []string, opts ...MigrationOption) int {
+	if migrationsDir == "" {
+		migrationsDir = "migrations"
+	}
+
+	db, err := NewDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	gormDB, err := db.Gorm()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+
+	mm := NewMigrationManager(gormDB, migrationsDir, opts...)
+	if err := RunMigrateCommand(mm, args, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// RunMigrateCommand 执行 migrate 子命令，args 不包含 "migrate" 本身
+func RunMigrateCommand(mm *MigrationManager, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, migrateUsage)
+		return fmt.Errorf("missing migrate command")
+	}
+
+	command, args := args[0], args[1:]
+	flags := flag.NewFlagSet("migrate "+command, flag.ContinueOnError)
+	flags.SetOutput(out)
+	dryRun := flags.Bool("dry-run", false, "print the plan without changing the database")
+	steps := flags.Int("steps", 1, "number of migrations to roll back")
+	to := flags.String("to", "", "roll back all migrations after this version")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		if *dryRun {
+			plan, err := mm.Plan()
+			if err != nil {
+				return err
+			}
+			return WritePlan(out, plan)
+		}
+		if err := mm.Migrate(); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Migrations applied.")
+		return nil
+
+	case "down":
+		if *dryRun {
+			plan, err := mm.RollbackPlan(*steps)
+			if *to != "" {
+				plan, err = mm.RollbackToPlan(*to)
+			}
+			if err != nil {
+				return err
+			}
+			return WritePlan(out, plan)
+		}
+		var err error
+		if *to != "" {
+			err = mm.RollbackTo(*to)
+		} else {
+			err = mm.Rollback(*steps)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Rollback complete.")
+		return nil
+
+	case "status":
+		status, err := mm.Status()
+		if err != nil {
+			return err
+		}
+		return writeStatus(out, status)
+
+	case "verify":
+		if err := mm.ValidateMigrations(); err != nil {
+			return err
+		}
+		drifted, err := mm.CheckDrift()
+		if err != nil {
+			return err
+		}
+		if len(drifted) > 0 {
+			for _, s := range drifted {
+				fmt.Fprintf(out, "modified after execution: %s\n", s.Version)
+			}
+			return ErrMigrationDrift
+		}
+		fmt.Fprintln(out, "Migrations are valid.")
+		return nil
+
+	case "repair":
+		if err := mm.RepairMigrations(); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Migration checksums repaired.")
+		return nil
+
+	case "create":
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: migrate create NAME")
+		}
+		file, err := mm.CreateMigration(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, file)
+		return nil
+
+	default:
+		fmt.Fprint(out, migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
+
+func writeStatus(out io.Writer, status []MigrationStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tKIND\tSTATUS\tEXECUTED AT")
+	for _, s := range status {
+		state, executedAt := "pending", ""
+		if s.Executed {
+			state = "applied"
+			executedAt = s.ExecutedAt.Format("2006-01-02 15:04:05")
+		}
+		if s.Drifted {
+			state = "modified"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Version, s.Name, s.Kind, state, executedAt)
+	}
+	return w.Flush()
+}
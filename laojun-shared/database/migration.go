@@ -1,6 +1,9 @@
 package database
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
@@ -12,29 +15,92 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrMigrationDrift 已执行的迁移文件被修改
+	ErrMigrationDrift = errors.New("migration checksum mismatch")
+	// ErrDuplicateMigration 迁移版本号重复
+	ErrDuplicateMigration = errors.New("duplicate migration version")
+	// ErrMigrationLocked 在超时时间内未获得迁移锁
+	ErrMigrationLocked = errors.New("migration lock not acquired")
+)
+
 // Migration 迁移结构
 type Migration struct {
-	ID            uint   `gorm:"primaryKey"`
-	Version       string `gorm:"uniqueIndex;not null"`
-	Name          string `gorm:"not null"`
-	UpSQL         string `gorm:"type:text"`
-	DownSQL       string `gorm:"type:text"`
-	ExecutedAt    time.Time
-	ExecutionTime int64 // 执行时间（毫秒）
+	ID                uint   `gorm:"primaryKey"`
+	Version           string `gorm:"uniqueIndex;not null"`
+	Name              string `gorm:"not null"`
+	UpSQL             string `gorm:"type:text"`
+	DownSQL           string `gorm:"type:text"`
+	DownNoTransaction bool   // 回滚是否在事务外执行
+	Checksum          string `gorm:"size:64"` // 迁移文件的SHA-256，Go迁移为空
+	ExecutedAt        time.Time
+	ExecutionTime     int64 // 执行时间（毫秒）
+}
+
+// GoMigration Go函数实现的迁移，与SQL文件按版本号统一排序执行
+type GoMigration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+	// NoTransaction 为true时Up/Down不在事务中执行
+	NoTransaction bool
+}
+
+// MigrationOption 迁移管理工具选项
+type MigrationOption func(*MigrationManager)
+
+// WithLockTimeout 设置等待其他实例释放迁移锁的最长时间
+func WithLockTimeout(timeout time.Duration) MigrationOption {
+	return func(mm *MigrationManager) {
+		mm.lockTimeout = timeout
+	}
+}
+
+// WithGoMigrations 注册Go迁移
+func WithGoMigrations(migrations ...GoMigration) MigrationOption {
+	return func(mm *MigrationManager) {
+		mm.goMigrations = append(mm.goMigrations, migrations...)
+	}
 }
 
 // MigrationManager 迁移管理工具
 type MigrationManager struct {
 	db            *gorm.DB
 	migrationsDir string
+	goMigrations  []GoMigration
+	lockTimeout   time.Duration
 }
 
 // NewMigrationManager 创建迁移管理工具
-func NewMigrationManager(db *gorm.DB, migrationsDir string) *MigrationManager {
-	return &MigrationManager{
+func NewMigrationManager(db *gorm.DB, migrationsDir string, opts ...MigrationOption) *MigrationManager {
+	mm := &MigrationManager{
 		db:            db,
 		migrationsDir: migrationsDir,
+		lockTimeout:   10 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(mm)
 	}
+	return mm
+}
+
+// Register 注册Go迁移
+func (mm *MigrationManager) Register(migrations ...GoMigration) error {
+	for _, m := range migrations {
+		if err := m.validate(); err != nil {
+			return err
+		}
+	}
+	mm.goMigrations = append(mm.goMigrations, migrations...)
+	return nil
+}
+
+func (m *GoMigration) validate() error {
+	if m.Version == "" || m.Up == nil {
+		return fmt.Errorf("go migration %q must have a version and an up function", m.Name)
+	}
+	return nil
 }
 
 // Initialize 初始化迁移表
@@ -42,97 +108,109 @@ func (mm *MigrationManager) Initialize() error {
 	return mm.db.AutoMigrate(&Migration{})
 }
 
-// Migrate 执行迁移
+// Migrate 执行迁移。执行前持有跨实例的迁移锁，并校验已执行迁移的校验和，
+// 已执行的迁移文件被修改时返回 ErrMigrationDrift。
 func (mm *MigrationManager) Migrate() error {
-	// 获取所有迁移文件
-	files, err := mm.getMigrationFiles()
-	if err != nil {
-		return fmt.Errorf("failed to get migration files: %w", err)
-	}
+	return mm.withLock(func(db *gorm.DB) error {
+		sources, err := mm.loadSources()
+		if err != nil {
+			return err
+		}
 
-	// 获取已执行的迁移
-	executed, err := mm.getExecutedMigrations()
-	if err != nil {
-		return fmt.Errorf("failed to get executed migrations: %w", err)
-	}
+		executed, err := mm.getExecutedMigrations(db)
+		if err != nil {
+			return fmt.Errorf("failed to get executed migrations: %w", err)
+		}
 
-	// 执行未执行的迁移
-	for _, file := range files {
-		version := mm.extractVersion(file)
-		if _, exists := executed[version]; !exists {
-			if err := mm.executeMigration(file); err != nil {
-				return fmt.Errorf("failed to execute migration %s: %w", file, err)
+		if err := mm.checkDrift(db, sources, executed); err != nil {
+			return err
+		}
+
+		// 执行未执行的迁移
+		for _, source := range sources {
+			if _, exists := executed[source.version]; !exists {
+				if err := mm.executeMigration(db, source); err != nil {
+					return fmt.Errorf("failed to execute migration %s: %w", source.version, err)
+				}
 			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // Rollback 回滚迁移
 func (mm *MigrationManager) Rollback(steps int) error {
-	// 获取已执行的迁移（按执行时间倒序）
-	var migrations []Migration
-	if err := mm.db.Order("executed_at DESC").Limit(steps).Find(&migrations).Error; err != nil {
-		return fmt.Errorf("failed to get migrations for rollback: %w", err)
-	}
-
-	// 执行回滚
-	for _, migration := range migrations {
-		if err := mm.executeRollback(migration); err != nil {
-			return fmt.Errorf("failed to rollback migration %s: %w", migration.Version, err)
-		}
-	}
-
-	return nil
+	return mm.rollback(steps, "")
 }
 
 // RollbackTo 回滚到指定版本
 func (mm *MigrationManager) RollbackTo(version string) error {
-	// 获取需要回滚的迁移
-	var migrations []Migration
-	if err := mm.db.Where("version > ?", version).Order("executed_at DESC").Find(&migrations).Error; err != nil {
-		return fmt.Errorf("failed to get migrations for rollback: %w", err)
-	}
+	return mm.rollback(0, version)
+}
 
-	// 执行回滚
-	for _, migration := range migrations {
-		if err := mm.executeRollback(migration); err != nil {
-			return fmt.Errorf("failed to rollback migration %s: %w", migration.Version, err)
+func (mm *MigrationManager) rollback(steps int, version string) error {
+	return mm.withLock(func(db *gorm.DB) error {
+		migrations, err := mm.rollbackTargets(db, steps, version)
+		if err != nil {
+			return err
+		}
+
+		// 执行回滚
+		for _, migration := range migrations {
+			if err := mm.executeRollback(db, migration); err != nil {
+				return fmt.Errorf("failed to rollback migration %s: %w", migration.Version, err)
+			}
 		}
+		return nil
+	})
+}
+
+// rollbackTargets 获取需要回滚的迁移（按执行时间倒序），
+// version 非空时回滚到该版本，否则回滚最近 steps 个
+func (mm *MigrationManager) rollbackTargets(db *gorm.DB, steps int, version string) ([]Migration, error) {
+	query := db.Order("executed_at DESC, version DESC")
+	if version != "" {
+		query = query.Where("version > ?", version)
+	} else {
+		query = query.Limit(steps)
 	}
 
-	return nil
+	var migrations []Migration
+	if err := query.Find(&migrations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get migrations for rollback: %w", err)
+	}
+	return migrations, nil
 }
 
 // Status 获取迁移状态
 func (mm *MigrationManager) Status() ([]MigrationStatus, error) {
-	// 获取所有迁移文件
-	files, err := mm.getMigrationFiles()
+	sources, err := mm.loadSources()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get migration files: %w", err)
+		return nil, err
 	}
 
 	// 获取已执行的迁移
-	executed, err := mm.getExecutedMigrations()
+	executed, err := mm.getExecutedMigrations(mm.db)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get executed migrations: %w", err)
 	}
 
 	var status []MigrationStatus
-	for _, file := range files {
-		version := mm.extractVersion(file)
-		migration, exists := executed[version]
+	for _, source := range sources {
+		migration, exists := executed[source.version]
 
 		s := MigrationStatus{
-			Version:  version,
-			Name:     mm.extractName(file),
+			Version:  source.version,
+			Name:     source.name,
+			Kind:     source.kind(),
 			Executed: exists,
 		}
 
 		if exists {
 			s.ExecutedAt = migration.ExecutedAt
 			s.ExecutionTime = migration.ExecutionTime
+			s.Drifted = migration.Checksum != "" && source.checksum != "" && migration.Checksum != source.checksum
 		}
 
 		status = append(status, s)
@@ -153,6 +231,7 @@ func (mm *MigrationManager) CreateMigration(name string) (string, error) {
 
 -- +migrate Up
 -- SQL in section 'Up' is executed when this migration is applied
+-- Use '-- +migrate Up notransaction' for statements such as CREATE INDEX CONCURRENTLY
 
 
 -- +migrate Down
@@ -180,47 +259,197 @@ func (mm *MigrationManager) ValidateMigrations() error {
 		}
 	}
 
-	return nil
+	_, err = mm.loadSources()
+	return err
 }
 
-// RepairMigrations 修复迁移状态
-func (mm *MigrationManager) RepairMigrations() error {
-	// 获取所有迁移文件
-	files, err := mm.getMigrationFiles()
+// CheckDrift 返回已执行但文件内容已被修改的迁移
+func (mm *MigrationManager) CheckDrift() ([]MigrationStatus, error) {
+	status, err := mm.Status()
 	if err != nil {
-		return fmt.Errorf("failed to get migration files: %w", err)
+		return nil, err
 	}
 
-	// 获取已执行的迁移
-	executed, err := mm.getExecutedMigrations()
-	if err != nil {
-		return fmt.Errorf("failed to get executed migrations: %w", err)
+	var drifted []MigrationStatus
+	for _, s := range status {
+		if s.Drifted {
+			drifted = append(drifted, s)
+		}
 	}
+	return drifted, nil
+}
+
+// RepairMigrations 修复迁移状态，并接受已执行迁移文件的当前内容，
+// 用于确认对已执行迁移文件的修改
+func (mm *MigrationManager) RepairMigrations() error {
+	return mm.withLock(func(db *gorm.DB) error {
+		sources, err := mm.loadSources()
+		if err != nil {
+			return err
+		}
+
+		// 获取已执行的迁移
+		executed, err := mm.getExecutedMigrations(db)
+		if err != nil {
+			return fmt.Errorf("failed to get executed migrations: %w", err)
+		}
+
+		// 检查并修复不一致的状态
+		for _, source := range sources {
+			migration, exists := executed[source.version]
+			if exists {
+				if migration.Checksum != source.checksum {
+					if err := db.Model(&migration).Update("checksum", source.checksum).Error; err != nil {
+						return fmt.Errorf("failed to repair checksum of %s: %w", source.version, err)
+					}
+				}
+				continue
+			}
 
-	// 检查并修复不一致的状态
-	for _, file := range files {
-		version := mm.extractVersion(file)
-		if _, exists := executed[version]; !exists {
 			// 检查数据库中是否存在该迁移创建的表/字段
-			if mm.isMigrationApplied(file) {
+			if mm.isMigrationApplied(source.file) {
 				// 标记为已执行
 				migration := Migration{
-					Version:       version,
-					Name:          mm.extractName(file),
+					Version:       source.version,
+					Name:          source.name,
+					Checksum:      source.checksum,
 					ExecutedAt:    time.Now(),
 					ExecutionTime: 0,
 				}
-				if err := mm.db.Create(&migration).Error; err != nil {
-					return fmt.Errorf("failed to repair migration %s: %w", version, err)
+				if err := db.Create(&migration).Error; err != nil {
+					return fmt.Errorf("failed to repair migration %s: %w", source.version, err)
 				}
 			}
 		}
+
+		return nil
+	})
+}
+
+// 私有方法
+
+// migrationSource 一个待执行的迁移，来自SQL文件或Go函数
+type migrationSource struct {
+	version  string
+	name     string
+	file     string
+	checksum string
+	up       sqlSection
+	down     sqlSection
+	goMig    *GoMigration
+}
+
+func (s *migrationSource) kind() string {
+	if s.goMig != nil {
+		return "go"
 	}
+	return "sql"
+}
+
+// sqlSection 迁移文件中的Up或Down部分
+type sqlSection struct {
+	lines         []string
+	noTransaction bool
+}
 
+func (s sqlSection) text() string {
+	return strings.Join(s.lines, "\n")
+}
+
+// loadSources 读取SQL文件并合并Go迁移，按版本号排序
+func (mm *MigrationManager) loadSources() ([]*migrationSource, error) {
+	files, err := mm.getMigrationFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration files: %w", err)
+	}
+
+	byVersion := make(map[string]*migrationSource)
+	var sources []*migrationSource
+	add := func(source *migrationSource) error {
+		if _, exists := byVersion[source.version]; exists {
+			return fmt.Errorf("%w: %s", ErrDuplicateMigration, source.version)
+		}
+		byVersion[source.version] = source
+		sources = append(sources, source)
+		return nil
+	}
+
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file: %w", err)
+		}
+
+		up, down, err := mm.parseMigrationContent(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse migration %s: %w", file, err)
+		}
+
+		if err := add(&migrationSource{
+			version:  mm.extractVersion(file),
+			name:     mm.extractName(file),
+			file:     file,
+			checksum: checksum(content),
+			up:       up,
+			down:     down,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range mm.goMigrations {
+		m := &mm.goMigrations[i]
+		if err := m.validate(); err != nil {
+			return nil, err
+		}
+		if err := add(&migrationSource{version: m.Version, name: m.Name, goMig: m}); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(sources, func(i, j int) bool { return sources[i].version < sources[j].version })
+	return sources, nil
+}
+
+// checksum 计算迁移文件的校验和，忽略换行符差异
+func checksum(content []byte) string {
+	normalized := strings.ReplaceAll(string(content), "\r\n", "\n")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkDrift 校验已执行迁移的校验和，并为没有校验和的旧记录补齐校验和
+func (mm *MigrationManager) checkDrift(db *gorm.DB, sources []*migrationSource, executed map[string]Migration) error {
+	if err := driftError(sources, executed); err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		migration, exists := executed[source.version]
+		if exists && migration.Checksum == "" && source.checksum != "" {
+			if err := db.Model(&migration).Update("checksum", source.checksum).Error; err != nil {
+				return fmt.Errorf("failed to record checksum of %s: %w", source.version, err)
+			}
+		}
+	}
 	return nil
 }
 
-// 私有方法
+// driftError 列出校验和不一致的已执行迁移
+func driftError(sources []*migrationSource, executed map[string]Migration) error {
+	var drifted []string
+	for _, source := range sources {
+		migration, exists := executed[source.version]
+		if exists && migration.Checksum != "" && source.checksum != "" && migration.Checksum != source.checksum {
+			drifted = append(drifted, source.version)
+		}
+	}
+
+	if len(drifted) > 0 {
+		return fmt.Errorf("%w: %s (run repair to accept the changes)", ErrMigrationDrift, strings.Join(drifted, ", "))
+	}
+	return nil
+}
 
 // getMigrationFiles 获取迁移文件列表
 func (mm *MigrationManager) getMigrationFiles() ([]string, error) {
@@ -235,9 +464,14 @@ func (mm *MigrationManager) getMigrationFiles() ([]string, error) {
 }
 
 // getExecutedMigrations 获取已执行的迁移
-func (mm *MigrationManager) getExecutedMigrations() (map[string]Migration, error) {
+func (mm *MigrationManager) getExecutedMigrations(db *gorm.DB) (map[string]Migration, error) {
+	// 迁移表尚未创建时视为没有已执行的迁移，dry-run 不创建迁移表
+	if !db.Migrator().HasTable(&Migration{}) {
+		return map[string]Migration{}, nil
+	}
+
 	var migrations []Migration
-	if err := mm.db.Find(&migrations).Error; err != nil {
+	if err := db.Find(&migrations).Error; err != nil {
 		return nil, err
 	}
 
@@ -250,39 +484,49 @@ func (mm *MigrationManager) getExecutedMigrations() (map[string]Migration, error
 }
 
 // executeMigration 执行迁移
-func (mm *MigrationManager) executeMigration(file string) error {
-	content, err := ioutil.ReadFile(file)
-	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
+func (mm *MigrationManager) executeMigration(db *gorm.DB, source *migrationSource) error {
+	start := time.Now()
+
+	migration := Migration{
+		Version:           source.version,
+		Name:              source.name,
+		UpSQL:             source.up.text(),
+		DownSQL:           source.down.text(),
+		DownNoTransaction: source.down.noTransaction,
+		Checksum:          source.checksum,
 	}
 
-	upSQL, downSQL, err := mm.parseMigrationContent(string(content))
-	if err != nil {
-		return fmt.Errorf("failed to parse migration content: %w", err)
+	up := func(tx *gorm.DB) error {
+		if source.goMig != nil {
+			return source.goMig.Up(tx)
+		}
+		return execStatements(tx, source.up.lines)
+	}
+	record := func(tx *gorm.DB) error {
+		migration.ExecutedAt = time.Now()
+		migration.ExecutionTime = time.Since(start).Milliseconds()
+		return tx.Create(&migration).Error
 	}
 
-	start := time.Now()
+	noTransaction := source.up.noTransaction
+	if source.goMig != nil {
+		noTransaction = source.goMig.NoTransaction
+	}
 
-	// 在事务中执行迁移
-	err = mm.db.Transaction(func(tx *gorm.DB) error {
-		// 执行 UP SQL
-		if upSQL != "" {
-			if err := tx.Exec(upSQL).Error; err != nil {
-				return fmt.Errorf("failed to execute up SQL: %w", err)
-			}
+	// 事务外执行时，失败的迁移可能已部分生效，需要人工处理
+	if noTransaction {
+		if err := up(db); err != nil {
+			return fmt.Errorf("migration failed outside transaction, it may be partially applied: %w", err)
 		}
+		return record(db)
+	}
 
-		// 记录迁移
-		migration := Migration{
-			Version:       mm.extractVersion(file),
-			Name:          mm.extractName(file),
-			UpSQL:         upSQL,
-			DownSQL:       downSQL,
-			ExecutedAt:    time.Now(),
-			ExecutionTime: time.Since(start).Milliseconds(),
+	// 在事务中执行迁移
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := up(tx); err != nil {
+			return err
 		}
-
-		return tx.Create(&migration).Error
+		return record(tx)
 	})
 
 	if err != nil {
@@ -293,16 +537,33 @@ func (mm *MigrationManager) executeMigration(file string) error {
 }
 
 // executeRollback 执行回滚
-func (mm *MigrationManager) executeRollback(migration Migration) error {
-	if migration.DownSQL == "" {
+func (mm *MigrationManager) executeRollback(db *gorm.DB, migration Migration) error {
+	down := func(tx *gorm.DB) error {
+		return execStatements(tx, strings.Split(migration.DownSQL, "\n"))
+	}
+	noTransaction := migration.DownNoTransaction
+
+	if m := mm.goMigration(migration.Version); m != nil {
+		if m.Down == nil {
+			return fmt.Errorf("no down function available for migration %s", migration.Version)
+		}
+		down = m.Down
+		noTransaction = m.NoTransaction
+	} else if migration.DownSQL == "" {
 		return fmt.Errorf("no down SQL available for migration %s", migration.Version)
 	}
 
+	if noTransaction {
+		if err := down(db); err != nil {
+			return fmt.Errorf("rollback failed outside transaction, it may be partially applied: %w", err)
+		}
+		return db.Delete(&migration).Error
+	}
+
 	// 在事务中执行回滚
-	return mm.db.Transaction(func(tx *gorm.DB) error {
-		// 执行 DOWN SQL
-		if err := tx.Exec(migration.DownSQL).Error; err != nil {
-			return fmt.Errorf("failed to execute down SQL: %w", err)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := down(tx); err != nil {
+			return err
 		}
 
 		// 删除迁移记录
@@ -310,40 +571,107 @@ func (mm *MigrationManager) executeRollback(migration Migration) error {
 	})
 }
 
-// parseMigrationContent 解析迁移文件内容
-func (mm *MigrationManager) parseMigrationContent(content string) (upSQL, downSQL string, err error) {
+func (mm *MigrationManager) goMigration(version string) *GoMigration {
+	for i := range mm.goMigrations {
+		if mm.goMigrations[i].Version == version {
+			return &mm.goMigrations[i]
+		}
+	}
+	return nil
+}
+
+// execStatements 逐条执行SQL语句
+func execStatements(db *gorm.DB, lines []string) error {
+	for _, statement := range splitStatements(lines) {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to execute %q: %w", abbreviate(statement), err)
+		}
+	}
+	return nil
+}
+
+// splitStatements 按行尾分号拆分语句，
+// '-- +migrate StatementBegin' 与 '-- +migrate StatementEnd' 之间的内容作为一条语句
+func splitStatements(lines []string) []string {
+	var statements, current []string
+	inBlock := false
+
+	flush := func() {
+		if statement := strings.TrimSpace(strings.Join(current, "\n")); statement != "" {
+			statements = append(statements, statement)
+		}
+		current = nil
+	}
+
+	for _, line := range lines {
+		switch trimmed := strings.TrimSpace(line); {
+		case strings.HasPrefix(trimmed, "-- +migrate StatementBegin"):
+			flush()
+			inBlock = true
+		case strings.HasPrefix(trimmed, "-- +migrate StatementEnd"):
+			flush()
+			inBlock = false
+		case trimmed == "":
+		default:
+			current = append(current, line)
+			if !inBlock && strings.HasSuffix(trimmed, ";") {
+				flush()
+			}
+		}
+	}
+	flush()
+
+	return statements
+}
+
+func abbreviate(statement string) string {
+	statement = strings.Join(strings.Fields(statement), " ")
+	if len(statement) > 80 {
+		return statement[:77] + "..."
+	}
+	return statement
+}
+
+// parseMigrationContent 解析迁移文件内容。
+// 'notransaction' 标记（如 '-- +migrate Up notransaction'）使该部分在事务外执行
+func (mm *MigrationManager) parseMigrationContent(content string) (up, down sqlSection, err error) {
 	lines := strings.Split(content, "\n")
-	var currentSection string
-	var upLines, downLines []string
+	var current *sqlSection
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 
 		if strings.HasPrefix(line, "-- +migrate Up") {
-			currentSection = "up"
+			current = &up
+			current.noTransaction = hasNoTransaction(line)
 			continue
 		} else if strings.HasPrefix(line, "-- +migrate Down") {
-			currentSection = "down"
+			current = &down
+			current.noTransaction = hasNoTransaction(line)
 			continue
 		}
 
-		// 跳过注释行和空行
-		if strings.HasPrefix(line, "--") || line == "" {
+		// 保留语句块标记，跳过其他注释行和空行
+		isBlockMarker := strings.HasPrefix(line, "-- +migrate Statement")
+		if (strings.HasPrefix(line, "--") && !isBlockMarker) || line == "" {
 			continue
 		}
 
-		switch currentSection {
-		case "up":
-			upLines = append(upLines, line)
-		case "down":
-			downLines = append(downLines, line)
+		if current != nil {
+			current.lines = append(current.lines, line)
 		}
 	}
 
-	upSQL = strings.Join(upLines, "\n")
-	downSQL = strings.Join(downLines, "\n")
+	return up, down, nil
+}
 
-	return upSQL, downSQL, nil
+func hasNoTransaction(marker string) bool {
+	for _, field := range strings.Fields(marker)[2:] {
+		if strings.EqualFold(field, "notransaction") {
+			return true
+		}
+	}
+	return false
 }
 
 // extractVersion 从文件名提取版本号
@@ -380,6 +708,10 @@ func (mm *MigrationManager) validateMigrationFile(file string) error {
 		return fmt.Errorf("missing '-- +migrate Down' marker")
 	}
 
+	if strings.Count(contentStr, "-- +migrate StatementBegin") != strings.Count(contentStr, "-- +migrate StatementEnd") {
+		return fmt.Errorf("unbalanced StatementBegin/StatementEnd markers")
+	}
+
 	// 检查版本号格式
 	version := mm.extractVersion(file)
 	if len(version) < 14 {
@@ -407,7 +739,9 @@ func (mm *MigrationManager) isMigrationApplied(file string) bool {
 type MigrationStatus struct {
 	Version       string    `json:"version"`
 	Name          string    `json:"name"`
+	Kind          string    `json:"kind"` // sql 或 go
 	Executed      bool      `json:"executed"`
+	Drifted       bool      `json:"drifted,omitempty"` // 执行后文件被修改
 	ExecutedAt    time.Time `json:"executed_at,omitempty"`
 	ExecutionTime int64     `json:"execution_time,omitempty"`
 }
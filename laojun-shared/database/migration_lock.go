@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockName 迁移锁名称，同一数据库上的所有服务实例共用
+const migrationLockName = "laojun_schema_migrations"
+
+// lockPollInterval 轮询获取 PostgreSQL 咨询锁的间隔
+const lockPollInterval = 500 * time.Millisecond
+
+// withLock 在单个数据库连接上持有迁移锁并执行 fn。
+// PostgreSQL 使用会话级咨询锁，MySQL 使用 GET_LOCK，连接断开时锁自动释放；
+// SQLite 本身串行化写入，不加锁。
+func (mm *MigrationManager) withLock(fn func(db *gorm.DB) error) error {
+	if err := mm.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize migration table: %w", err)
+	}
+
+	return mm.db.Connection(func(conn *gorm.DB) error {
+		// 会话级锁必须在同一连接上加锁和解锁，迁移也在该连接上执行
+		conn = conn.Session(&gorm.Session{})
+
+		unlock, err := mm.lock(conn)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		return fn(conn)
+	})
+}
+
+func (mm *MigrationManager) lock(conn *gorm.DB) (func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mm.lockTimeout)
+	defer cancel()
+
+	switch conn.Dialector.Name() {
+	case "postgres":
+		key := migrationLockKey()
+		for {
+			var acquired bool
+			if err := conn.WithContext(ctx).Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&acquired).Error; err != nil {
+				return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			if acquired {
+				return func() { conn.Exec("SELECT pg_advisory_unlock(?)", key) }, nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%w after %s", ErrMigrationLocked, mm.lockTimeout)
+			case <-time.After(lockPollInterval):
+			}
+		}
+
+	case "mysql":
+		var acquired *int
+		timeout := int(mm.lockTimeout.Seconds())
+		if err := conn.Raw("SELECT GET_LOCK(?, ?)", migrationLockName, timeout).Scan(&acquired).Error; err != nil {
+			return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if acquired == nil || *acquired != 1 {
+			return nil, fmt.Errorf("%w after %s", ErrMigrationLocked, mm.lockTimeout)
+		}
+		return func() { conn.Exec("SELECT RELEASE_LOCK(?)", migrationLockName) }, nil
+
+	default:
+		return func() {}, nil
+	}
+}
+
+// migrationLockKey PostgreSQL 咨询锁的键
+func migrationLockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(migrationLockName))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MigrationPlanStep 迁移计划中的一步，用于 dry-run 输出
+type MigrationPlanStep struct {
+	Version       string   `json:"version"`
+	Name          string   `json:"name"`
+	Direction     string   `json:"direction"` // up 或 down
+	Kind          string   `json:"kind"`      // sql 或 go
+	Transactional bool     `json:"transactional"`
+	Statements    []string `json:"statements,omitempty"` // Go迁移为空
+}
+
+// Plan 返回 Migrate 将执行的迁移，不修改数据库。
+// 已执行的迁移文件被修改时与 Migrate 一样返回 ErrMigrationDrift
+func (mm *MigrationManager) Plan() ([]MigrationPlanStep, error) {
+	sources, err := mm.loadSources()
+	if err != nil {
+		return nil, err
+	}
+
+	executed, err := mm.getExecutedMigrations(mm.db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executed migrations: %w", err)
+	}
+
+	if err := driftError(sources, executed); err != nil {
+		return nil, err
+	}
+
+	var plan []MigrationPlanStep
+	for _, source := range sources {
+		if _, exists := executed[source.version]; exists {
+			continue
+		}
+
+		step := MigrationPlanStep{
+			Version:       source.version,
+			Name:          source.name,
+			Direction:     "up",
+			Kind:          source.kind(),
+			Transactional: !source.up.noTransaction,
+		}
+		if source.goMig != nil {
+			step.Transactional = !source.goMig.NoTransaction
+		} else {
+			step.Statements = splitStatements(source.up.lines)
+		}
+		plan = append(plan, step)
+	}
+
+	return plan, nil
+}
+
+// RollbackPlan 返回 Rollback(steps) 将执行的回滚，不修改数据库
+func (mm *MigrationManager) RollbackPlan(steps int) ([]MigrationPlanStep, error) {
+	return mm.rollbackPlan(steps, "")
+}
+
+// RollbackToPlan 返回 RollbackTo(version) 将执行的回滚，不修改数据库
+func (mm *MigrationManager) RollbackToPlan(version string) ([]MigrationPlanStep, error) {
+	return mm.rollbackPlan(0, version)
+}
+
+func (mm *MigrationManager) rollbackPlan(steps int, version string) ([]MigrationPlanStep, error) {
+	migrations, err := mm.rollbackTargets(mm.db, steps, version)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]MigrationPlanStep, 0, len(migrations))
+	for _, migration := range migrations {
+		step := MigrationPlanStep{
+			Version:       migration.Version,
+			Name:          migration.Name,
+			Direction:     "down",
+			Kind:          "sql",
+			Transactional: !migration.DownNoTransaction,
+		}
+		if m := mm.goMigration(migration.Version); m != nil {
+			step.Kind = "go"
+			step.Transactional = !m.NoTransaction
+		} else {
+			step.Statements = splitStatements(strings.Split(migration.DownSQL, "\n"))
+		}
+		plan = append(plan, step)
+	}
+
+	return plan, nil
+}
+
+// WritePlan 以可读形式输出迁移计划
+func WritePlan(w io.Writer, plan []MigrationPlanStep) error {
+	if len(plan) == 0 {
+		_, err := fmt.Fprintln(w, "Nothing to do.")
+		return err
+	}
+
+	for _, step := range plan {
+		mode := "transaction"
+		if !step.Transactional {
+			mode = "no transaction"
+		}
+		if _, err := fmt.Fprintf(w, "-- %s %s %s (%s, %s)\n", strings.ToUpper(step.Direction), step.Version, step.Name, step.Kind, mode); err != nil {
+			return err
+		}
+
+		if step.Kind == "go" {
+			if _, err := fmt.Fprintln(w, "-- Go function"); err != nil {
+				return err
+			}
+		}
+		for _, statement := range step.Statements {
+			if _, err := fmt.Fprintln(w, statement); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
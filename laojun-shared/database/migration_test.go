@@ -0,0 +1,244 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestMigrationManager(t *testing.T, files map[string]string) (*MigrationManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dir, "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	return NewMigrationManager(db, dir), dir
+}
+
+const createUsers = `-- +migrate Up
+CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);
+-- +migrate StatementBegin
+CREATE TRIGGER users_name AFTER INSERT ON users BEGIN
+  UPDATE users SET name = upper(new.name) WHERE id = new.id;
+END;
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TABLE users;
+`
+
+const indexUsers = `-- +migrate Up notransaction
+CREATE INDEX idx_users_name ON users (name);
+
+-- +migrate Down notransaction
+DROP INDEX idx_users_name;
+`
+
+func TestMigrateSQLAndGoMigrations(t *testing.T) {
+	mm, _ := newTestMigrationManager(t, map[string]string{
+		"20240101000000_create_users.sql": createUsers,
+		"20240103000000_index_users.sql":  indexUsers,
+	})
+
+	var order []string
+	err := mm.Register(GoMigration{
+		Version: "20240102000000_seed_admin",
+		Name:    "seed admin",
+		Up: func(tx *gorm.DB) error {
+			order = append(order, "seed")
+			return tx.Exec("INSERT INTO users (id, name) VALUES (1, 'admin')").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Exec("DELETE FROM users WHERE id = 1").Error
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	plan, err := mm.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan) != 3 || plan[1].Kind != "go" || plan[2].Transactional || len(plan[0].Statements) != 2 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if !strings.Contains(plan[0].Statements[1], "UPDATE users SET name") {
+		t.Errorf("trigger block split: %q", plan[0].Statements)
+	}
+	if mm.db.Migrator().HasTable("users") {
+		t.Fatal("Plan() changed the database")
+	}
+
+	if err := mm.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	var name string
+	mm.db.Raw("SELECT name FROM users WHERE id = 1").Scan(&name)
+	if name != "ADMIN" || len(order) != 1 {
+		t.Errorf("name = %q, go migration runs = %d", name, len(order))
+	}
+
+	// 重复执行不会再次运行迁移
+	if err := mm.Migrate(); err != nil || len(order) != 1 {
+		t.Fatalf("second Migrate() error = %v, runs = %d", err, len(order))
+	}
+
+	plan, err = mm.RollbackPlan(2)
+	if err != nil || len(plan) != 2 || plan[0].Version != "20240103000000_index_users" || plan[1].Kind != "go" {
+		t.Fatalf("RollbackPlan() = %+v, %v", plan, err)
+	}
+	if err := mm.Rollback(2); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	var count int64
+	mm.db.Raw("SELECT count(*) FROM users").Scan(&count)
+	if count != 0 {
+		t.Errorf("go migration not rolled back, %d users", count)
+	}
+
+	status, err := mm.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !status[0].Executed || status[1].Executed || status[2].Executed {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestMigrationDrift(t *testing.T) {
+	mm, dir := newTestMigrationManager(t, map[string]string{
+		"20240101000000_create_users.sql": createUsers,
+	})
+	if err := mm.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	// 仅换行符不同不视为修改
+	file := filepath.Join(dir, "20240101000000_create_users.sql")
+	os.WriteFile(file, []byte(strings.ReplaceAll(createUsers, "\n", "\r\n")), 0644)
+	if err := mm.Migrate(); err != nil {
+		t.Fatalf("Migrate() after CRLF conversion error = %v", err)
+	}
+
+	os.WriteFile(file, []byte(strings.Replace(createUsers, "name TEXT", "name TEXT, email TEXT", 1)), 0644)
+	if err := mm.Migrate(); !errors.Is(err, ErrMigrationDrift) {
+		t.Fatalf("expected ErrMigrationDrift, got %v", err)
+	}
+	if _, err := mm.Plan(); !errors.Is(err, ErrMigrationDrift) {
+		t.Fatalf("expected ErrMigrationDrift from Plan(), got %v", err)
+	}
+	drifted, err := mm.CheckDrift()
+	if err != nil || len(drifted) != 1 {
+		t.Fatalf("CheckDrift() = %v, %v", drifted, err)
+	}
+
+	if err := mm.RepairMigrations(); err != nil {
+		t.Fatalf("RepairMigrations() error = %v", err)
+	}
+	if err := mm.Migrate(); err != nil {
+		t.Fatalf("Migrate() after repair error = %v", err)
+	}
+}
+
+func TestMigrationChecksumBackfill(t *testing.T) {
+	mm, _ := newTestMigrationManager(t, map[string]string{
+		"20240101000000_create_users.sql": createUsers,
+	})
+	if err := mm.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	// 旧版本记录没有校验和
+	mm.db.Model(&Migration{}).Where("1 = 1").Update("checksum", "")
+	if err := mm.Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	var migration Migration
+	mm.db.First(&migration)
+	if len(migration.Checksum) != 64 {
+		t.Errorf("checksum not backfilled: %q", migration.Checksum)
+	}
+}
+
+func TestDuplicateMigrationVersion(t *testing.T) {
+	mm, _ := newTestMigrationManager(t, map[string]string{
+		"20240101000000_create_users.sql": createUsers,
+	})
+	mm.Register(GoMigration{Version: "20240101000000_create_users", Up: func(*gorm.DB) error { return nil }})
+
+	if err := mm.Migrate(); !errors.Is(err, ErrDuplicateMigration) {
+		t.Errorf("expected ErrDuplicateMigration, got %v", err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	lines := []string{
+		"CREATE TABLE a (id INT);",
+		"INSERT INTO a",
+		"VALUES (1);",
+		"-- +migrate StatementBegin",
+		"CREATE FUNCTION f() RETURNS trigger AS $$",
+		"BEGIN RETURN NEW; END;",
+		"$$ LANGUAGE plpgsql;",
+		"-- +migrate StatementEnd",
+		"SELECT 1",
+	}
+	got := splitStatements(lines)
+	if len(got) != 4 || got[1] != "INSERT INTO a\nVALUES (1);" || !strings.HasSuffix(got[2], "plpgsql;") || got[3] != "SELECT 1" {
+		t.Errorf("splitStatements() = %q", got)
+	}
+}
+
+func TestRunMigrateCommand(t *testing.T) {
+	mm, _ := newTestMigrationManager(t, map[string]string{
+		"20240101000000_create_users.sql": createUsers,
+		"20240103000000_index_users.sql":  indexUsers,
+	})
+
+	var out bytes.Buffer
+	if err := RunMigrateCommand(mm, []string{"up", "-dry-run"}, &out); err != nil {
+		t.Fatalf("up -dry-run error = %v", err)
+	}
+	if !strings.Contains(out.String(), "-- UP 20240103000000_index_users index users (sql, no transaction)") ||
+		!strings.Contains(out.String(), "CREATE INDEX idx_users_name ON users (name);") {
+		t.Errorf("unexpected plan output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := RunMigrateCommand(mm, []string{"up"}, &out); err != nil {
+		t.Fatalf("up error = %v", err)
+	}
+	out.Reset()
+	if err := RunMigrateCommand(mm, []string{"down", "-steps", "1"}, &out); err != nil {
+		t.Fatalf("down error = %v", err)
+	}
+
+	out.Reset()
+	if err := RunMigrateCommand(mm, []string{"status"}, &out); err != nil {
+		t.Fatalf("status error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "applied") || !strings.Contains(lines[2], "pending") {
+		t.Errorf("unexpected status output:\n%s", out.String())
+	}
+
+	if err := RunMigrateCommand(mm, []string{"bogus"}, &out); err == nil {
+		t.Error("expected error for unknown command")
+	}
+}
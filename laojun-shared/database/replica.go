@@ -0,0 +1,319 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// postgresLagQuery 查询从库复制延迟（秒），WAL 已全部回放时为0，主库返回NULL
+const postgresLagQuery = `SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END`
+
+type forcePrimaryKey struct{}
+
+type readYourWritesKey struct{}
+
+// writeTracker 记录同一请求上下文中最近一次写入的时间
+type writeTracker struct {
+	lastWrite atomic.Int64
+}
+
+// WithPrimary 返回强制所有查询使用主库的上下文
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+// WithReadYourWrites 返回开启读己之写的上下文：该上下文中发生写入后，
+// ReadYourWritesWindow 时间内的读查询使用主库。一般在每个请求开始时调用
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(readYourWritesKey{}).(*writeTracker); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, readYourWritesKey{}, &writeTracker{})
+}
+
+// ReadYourWritesMiddleware 为每个 HTTP 请求开启读己之写
+func ReadYourWritesMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithReadYourWrites(r.Context())))
+	})
+}
+
+// ReplicaStats 从库状态
+type ReplicaStats struct {
+	Name            string        `json:"name"`
+	Weight          int           `json:"weight"`
+	Healthy         bool          `json:"healthy"`
+	Lag             time.Duration `json:"lag"`
+	Reads           uint64        `json:"reads"`
+	LastError       string        `json:"last_error,omitempty"`
+	LastCheck       time.Time     `json:"last_check"`
+	OpenConnections int           `json:"open_connections"`
+	InUse           int           `json:"in_use"`
+}
+
+// replica 一个从库连接及其健康状态
+type replica struct {
+	name      string
+	weight    int
+	db        *sql.DB
+	dialector gorm.Dialector
+
+	healthy atomic.Bool
+	lag     atomic.Int64
+	reads   atomic.Uint64
+
+	mu        sync.Mutex
+	lastError string
+	lastCheck time.Time
+}
+
+// ReplicaRouter 将只读查询路由到健康的从库。
+// 复制延迟超过 MaxReplicaLag 或无法连接的从库不再接收读请求；
+// 没有可用从库、上下文要求主库或处于读己之写窗口内时读主库
+type ReplicaRouter struct {
+	primary  gorm.ConnPool
+	replicas []*replica
+	byPool   map[gorm.ConnPool]*replica
+
+	maxLag        time.Duration
+	checkInterval time.Duration
+	stickyWindow  time.Duration
+	lagQuery      string
+
+	primaryReads atomic.Uint64
+	stop         chan struct{}
+	stopOnce     sync.Once
+	wg           sync.WaitGroup
+}
+
+// newReplicaRouter 注册 dbresolver 及路由回调，并启动从库健康检测
+func newReplicaRouter(db *gorm.DB, replicas []*replica, config DatabaseConfig, lagQuery string) (*ReplicaRouter, error) {
+	r := &ReplicaRouter{
+		primary:       db.ConnPool,
+		replicas:      replicas,
+		byPool:        make(map[gorm.ConnPool]*replica, len(replicas)),
+		maxLag:        config.MaxReplicaLag,
+		checkInterval: config.ReplicaCheckInterval,
+		stickyWindow:  config.ReadYourWritesWindow,
+		lagQuery:      lagQuery,
+		stop:          make(chan struct{}),
+	}
+	if preparedStmtDB, ok := r.primary.(*gorm.PreparedStmtDB); ok {
+		r.primary = preparedStmtDB.ConnPool
+	}
+
+	dialectors := make([]gorm.Dialector, 0, len(replicas))
+	for _, rep := range replicas {
+		r.byPool[rep.db] = rep
+		dialectors = append(dialectors, rep.dialector)
+	}
+
+	// 路由回调需先于 dbresolver 注册，才能排在它的回调之前
+	if err := r.registerCallbacks(db); err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   r,
+	})); err != nil {
+		return nil, err
+	}
+
+	// 启动前先检测一次，避免把读请求发给不可用的从库
+	r.checkReplicas()
+	r.wg.Add(1)
+	go r.run()
+
+	return r, nil
+}
+
+func (r *ReplicaRouter) registerCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("*").Register("laojun:replica_route", r.route); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("*").Register("laojun:replica_route", r.route); err != nil {
+		return err
+	}
+
+	if err := callbacks.Create().After("*").Register("laojun:record_write", r.recordWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("*").Register("laojun:record_write", r.recordWrite); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().After("*").Register("laojun:record_write", r.recordWrite); err != nil {
+		return err
+	}
+	return callbacks.Raw().After("*").Register("laojun:record_write", r.recordWrite)
+}
+
+// route 在 dbresolver 选择连接之前判断读查询是否必须使用主库
+func (r *ReplicaRouter) route(db *gorm.DB) {
+	if _, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	usePrimary := !r.hasHealthyReplica()
+	if forced, _ := ctx.Value(forcePrimaryKey{}).(bool); forced {
+		usePrimary = true
+	}
+	if tracker, ok := ctx.Value(readYourWritesKey{}).(*writeTracker); ok {
+		if last := tracker.lastWrite.Load(); last > 0 && time.Since(time.Unix(0, last)) < r.stickyWindow {
+			usePrimary = true
+		}
+	}
+
+	if usePrimary {
+		dbresolver.Write.ModifyStatement(db.Statement)
+		r.primaryReads.Add(1)
+	} else if len(r.replicas) == 1 {
+		// 只有一个从库时 dbresolver 不调用 Resolve，在此计数
+		r.replicas[0].reads.Add(1)
+	}
+}
+
+// recordWrite 记录读己之写上下文中的写入
+func (r *ReplicaRouter) recordWrite(db *gorm.DB) {
+	tracker, ok := db.Statement.Context.Value(readYourWritesKey{}).(*writeTracker)
+	if !ok {
+		return
+	}
+	if query := strings.TrimSpace(db.Statement.SQL.String()); len(query) >= 6 && strings.EqualFold(query[:6], "select") {
+		return
+	}
+	tracker.lastWrite.Store(time.Now().UnixNano())
+}
+
+func (r *ReplicaRouter) hasHealthyReplica() bool {
+	for _, rep := range r.replicas {
+		if rep.healthy.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve 按权重在健康的从库中选择连接，实现 dbresolver.Policy
+func (r *ReplicaRouter) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	total := 0
+	candidates := make([]*replica, 0, len(pools))
+	for _, pool := range pools {
+		if rep, ok := r.byPool[pool]; ok && rep.healthy.Load() {
+			candidates = append(candidates, rep)
+			total += rep.weight
+		}
+	}
+
+	if total == 0 {
+		r.primaryReads.Add(1)
+		return r.primary
+	}
+
+	n := rand.Intn(total)
+	for _, rep := range candidates {
+		if n < rep.weight {
+			rep.reads.Add(1)
+			return rep.db
+		}
+		n -= rep.weight
+	}
+	return candidates[len(candidates)-1].db
+}
+
+func (r *ReplicaRouter) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.checkReplicas()
+		}
+	}
+}
+
+// checkReplicas 检测从库连通性和复制延迟
+func (r *ReplicaRouter) checkReplicas() {
+	for _, rep := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), r.checkInterval)
+		var lagSeconds sql.NullFloat64
+		err := rep.db.QueryRowContext(ctx, r.lagQuery).Scan(&lagSeconds)
+		cancel()
+
+		lag := time.Duration(lagSeconds.Float64 * float64(time.Second))
+		healthy := err == nil && (r.maxLag <= 0 || lag <= r.maxLag)
+
+		rep.lag.Store(int64(lag))
+		rep.healthy.Store(healthy)
+
+		rep.mu.Lock()
+		rep.lastCheck = time.Now()
+		switch {
+		case err != nil:
+			rep.lastError = err.Error()
+		case !healthy:
+			rep.lastError = "replication lag " + lag.String() + " exceeds " + r.maxLag.String()
+		default:
+			rep.lastError = ""
+		}
+		rep.mu.Unlock()
+	}
+}
+
+// Stats 返回各从库的状态
+func (r *ReplicaRouter) Stats() []ReplicaStats {
+	stats := make([]ReplicaStats, 0, len(r.replicas))
+	for _, rep := range r.replicas {
+		dbStats := rep.db.Stats()
+		rep.mu.Lock()
+		stats = append(stats, ReplicaStats{
+			Name:            rep.name,
+			Weight:          rep.weight,
+			Healthy:         rep.healthy.Load(),
+			Lag:             time.Duration(rep.lag.Load()),
+			Reads:           rep.reads.Load(),
+			LastError:       rep.lastError,
+			LastCheck:       rep.lastCheck,
+			OpenConnections: dbStats.OpenConnections,
+			InUse:           dbStats.InUse,
+		})
+		rep.mu.Unlock()
+	}
+	return stats
+}
+
+// PrimaryReads 返回因强制主库、读己之写或无可用从库而发往主库的读查询数
+func (r *ReplicaRouter) PrimaryReads() uint64 {
+	return r.primaryReads.Load()
+}
+
+// Close 停止健康检测并关闭从库连接
+func (r *ReplicaRouter) Close() error {
+	r.stopOnce.Do(func() { close(r.stop) })
+	r.wg.Wait()
+
+	var firstErr error
+	for _, rep := range r.replicas {
+		if err := rep.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type replicaItem struct {
+	ID   uint
+	Name string
+}
+
+func openSQLite(t *testing.T, path, name string) *sql.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&replicaItem{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&replicaItem{Name: name})
+	db.Exec("CREATE TABLE replica_lag (seconds REAL)")
+	db.Exec("INSERT INTO replica_lag VALUES (0)")
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sqlDB
+}
+
+func newTestRouter(t *testing.T, config DatabaseConfig, weights ...int) (*gorm.DB, *ReplicaRouter) {
+	t.Helper()
+	dir := t.TempDir()
+
+	primary := openSQLite(t, filepath.Join(dir, "primary.db"), "primary")
+	db, err := gorm.Open(sqlite.Dialector{Conn: primary}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var replicas []*replica
+	for i, weight := range weights {
+		name := "replica" + string(rune('1'+i))
+		sqlDB := openSQLite(t, filepath.Join(dir, name+".db"), name)
+		replicas = append(replicas, &replica{
+			name:      name,
+			weight:    weight,
+			db:        sqlDB,
+			dialector: sqlite.Dialector{Conn: sqlDB},
+		})
+	}
+
+	if config.ReplicaCheckInterval == 0 {
+		config.ReplicaCheckInterval = time.Hour
+	}
+	router, err := newReplicaRouter(db, replicas, config, "SELECT seconds FROM replica_lag")
+	if err != nil {
+		t.Fatalf("newReplicaRouter() error = %v", err)
+	}
+	t.Cleanup(func() { router.Close() })
+	return db, router
+}
+
+func readName(t *testing.T, db *gorm.DB, ctx context.Context) string {
+	t.Helper()
+	var item replicaItem
+	if err := db.WithContext(ctx).First(&item).Error; err != nil {
+		t.Fatalf("query error = %v", err)
+	}
+	return item.Name
+}
+
+func TestReplicaRouterWeightedSelection(t *testing.T) {
+	db, router := newTestRouter(t, DatabaseConfig{}, 1, 3)
+
+	counts := map[string]int{}
+	for i := 0; i < 400; i++ {
+		counts[readName(t, db, context.Background())]++
+	}
+	if counts["primary"] != 0 {
+		t.Errorf("reads went to primary: %v", counts)
+	}
+	if counts["replica2"] < 2*counts["replica1"] {
+		t.Errorf("weights not respected: %v", counts)
+	}
+
+	stats := router.Stats()
+	if stats[0].Reads+stats[1].Reads != 400 {
+		t.Errorf("unexpected read counters: %+v", stats)
+	}
+}
+
+func TestReplicaRouterForcePrimary(t *testing.T) {
+	db, router := newTestRouter(t, DatabaseConfig{}, 1)
+
+	if name := readName(t, db, WithPrimary(context.Background())); name != "primary" {
+		t.Errorf("WithPrimary read from %s", name)
+	}
+	if name := readName(t, db, context.Background()); name != "replica1" {
+		t.Errorf("read from %s, want replica1", name)
+	}
+	if router.PrimaryReads() != 1 || router.Stats()[0].Reads != 1 {
+		t.Errorf("primary reads = %d, stats = %+v", router.PrimaryReads(), router.Stats())
+	}
+}
+
+func TestReplicaRouterLag(t *testing.T) {
+	db, router := newTestRouter(t, DatabaseConfig{MaxReplicaLag: time.Second}, 1, 1)
+
+	router.replicas[0].db.Exec("UPDATE replica_lag SET seconds = 5")
+	router.checkReplicas()
+
+	stats := router.Stats()
+	if stats[0].Healthy || stats[0].Lag != 5*time.Second || stats[0].LastError == "" || !stats[1].Healthy {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	for i := 0; i < 20; i++ {
+		if name := readName(t, db, context.Background()); name != "replica2" {
+			t.Fatalf("read from %s, want replica2", name)
+		}
+	}
+
+	// 所有从库都不可用时回退到主库
+	router.replicas[1].db.Close()
+	router.checkReplicas()
+	if name := readName(t, db, context.Background()); name != "primary" {
+		t.Errorf("read from %s, want primary", name)
+	}
+}
+
+func TestReplicaRouterReadYourWrites(t *testing.T) {
+	db, _ := newTestRouter(t, DatabaseConfig{ReadYourWritesWindow: 100 * time.Millisecond}, 1)
+
+	ctx := WithReadYourWrites(context.Background())
+	if name := readName(t, db, ctx); name != "replica1" {
+		t.Fatalf("read before write from %s", name)
+	}
+
+	if err := db.WithContext(ctx).Create(&replicaItem{Name: "new"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if name := readName(t, db, ctx); name != "primary" {
+		t.Errorf("read after write from %s, want primary", name)
+	}
+	// 其他请求不受影响
+	if name := readName(t, db, context.Background()); name != "replica1" {
+		t.Errorf("unrelated read from %s", name)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if name := readName(t, db, ctx); name != "replica1" {
+		t.Errorf("read after window from %s, want replica1", name)
+	}
+}
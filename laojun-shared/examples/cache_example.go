@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/codetaoist/laojun-shared/cache"
-	"github.com/codetaoist/laojun-shared/config"
 )
 
 func main() {
@@ -139,7 +138,7 @@ func main() {
 	// 8. Redis缓存示例（需要Redis连接）
 	fmt.Println("\n--- Redis缓存示例 ---")
 	
-	redisConfig := &config.RedisConfig{
+	redisConfig := &cache.RedisConfig{
 		Host:     "localhost",
 		Port:     6379,
 		Password: "",
@@ -9,29 +9,56 @@ import (
 	"github.com/codetaoist/laojun-shared/notification"
 )
 
+// consoleSMSProvider 将短信打印到控制台
+type consoleSMSProvider struct{}
+
+func (consoleSMSProvider) Send(ctx context.Context, phone, text string) error {
+	fmt.Printf("发送短信到 %s: %s\n", phone, text)
+	return nil
+}
+
 func main() {
 	fmt.Println("=== NOTIFICATION 示例 ===")
-	
+
 	// 1. 创建配置
 	config := notification.DefaultConfig()
 	config.Debug = true
-	
-	// 2. 创建实例
-	impl := notification.New(config)
+	config.DefaultChannels = []notification.ChannelType{notification.ChannelSMS}
+
+	// 2. 创建通知服务
+	svc, err := notification.New(config,
+		notification.WithChannel(notification.NewSMSChannel(consoleSMSProvider{}, 70)))
+	if err != nil {
+		log.Fatal("创建通知服务失败:", err)
+	}
 	defer func() {
-		if closer, ok := impl.(interface{ Close() error }); ok {
-			if err := closer.Close(); err != nil {
-				log.Printf("关闭失败: %v", err)
-			}
+		if err := svc.Close(); err != nil {
+			log.Printf("关闭失败: %v", err)
 		}
 	}()
-	
-	// 3. 使用示例
+
+	// 3. 注册模板
+	err = svc.Templates().Register("plugin_approved", "zh-CN", notification.Template{
+		Text: "插件 {{.plugin}} 已通过审核",
+	})
+	if err != nil {
+		log.Fatal("注册模板失败:", err)
+	}
+
+	// 4. 发送通知
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	
-	// TODO: Add your usage examples here
-	fmt.Println("TODO: 添加使用示例")
-	
+
+	result, err := svc.Send(ctx, &notification.Message{
+		Template:   "plugin_approved",
+		Category:   "plugin_audit",
+		Recipients: []notification.Recipient{{UserID: "1001", Phone: "13800000000"}},
+		Data:       map[string]interface{}{"plugin": "markdown-preview"},
+	})
+	if err != nil {
+		log.Fatal("发送通知失败:", err)
+	}
+	fmt.Printf("成功发送 %d 条，失败 %d 条\n", result.Sent(), len(result.Failed()))
+
 	fmt.Println("=== 示例完成 ===")
 }
@@ -0,0 +1,686 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// 默认告警参数
+const (
+	defaultThresholdWindow = 20
+	minWindowSamples       = 5
+	defaultFlapWindow      = 10 * time.Minute
+	notifyTimeout          = 10 * time.Second
+)
+
+// defaultTemplate 默认通知模板
+const defaultTemplate = `[{{.Severity}}] {{.Event.Service.Name}}/{{.Checker}}: {{.Event.Previous}} -> {{.Event.Current}}{{if .Event.Details}} ({{.Event.Details}}){{end}}`
+
+// Notification 发送给通知渠道的告警
+type Notification struct {
+	Rule      string      `json:"rule"`
+	Severity  string      `json:"severity"`
+	Checker   string      `json:"checker"`
+	Subject   string      `json:"subject"`
+	Message   string      `json:"message"`
+	Event     HealthEvent `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// checkerState 单个检查器的告警状态
+type checkerState struct {
+	checker     HealthChecker
+	history     []Status    // 最近 Window 次检查结果
+	lastResult  time.Time   // 最近处理的检查结果时间，避免重复处理缓存结果
+	pending     Status      // 最近结果的有效状态
+	streak      int         // pending 连续出现的次数
+	status      Status      // 经阈值确认的状态
+	notified    Status      // 最近一次通知的状态
+	transitions []time.Time // FlapWindow 内的状态变化时间
+	flapping    bool
+	details     string
+}
+
+// channelQueue 按产生顺序发送同一渠道的通知，慢渠道不阻塞检查和其他渠道
+type channelQueue struct {
+	mu      sync.Mutex
+	pending []queuedNotification
+	running bool
+}
+
+type queuedNotification struct {
+	notifier     Notifier
+	notification *Notification
+}
+
+// alerter 根据检查结果维护各检查器状态，评估通知规则
+type alerter struct {
+	config     NotificationConfig
+	thresholds ThresholdConfig
+	service    ServiceConfig
+	rules      []compiledRule
+	templates  map[string]*template.Template
+
+	mu        sync.Mutex
+	notifiers map[string]Notifier
+	states    map[string]*checkerState
+	dependsOn map[string][]string
+	lastSent  map[string]time.Time
+	queues    map[string]*channelQueue
+	listeners func() []HealthEventListener
+	sendWG    sync.WaitGroup
+}
+
+func newAlerter(config HealthConfig, listeners func() []HealthEventListener) *alerter {
+	a := &alerter{
+		config:     config.Notifications,
+		thresholds: config.Thresholds,
+		service:    config.Service,
+		templates:  make(map[string]*template.Template),
+		notifiers:  make(map[string]Notifier),
+		states:     make(map[string]*checkerState),
+		dependsOn:  make(map[string][]string),
+		lastSent:   make(map[string]time.Time),
+		queues:     make(map[string]*channelQueue),
+		listeners:  listeners,
+	}
+	if a.thresholds.Window <= 0 {
+		a.thresholds.Window = defaultThresholdWindow
+	}
+	if a.thresholds.ConsecutiveFailures <= 0 {
+		a.thresholds.ConsecutiveFailures = 1
+	}
+	if a.thresholds.ConsecutiveSuccesses <= 0 {
+		a.thresholds.ConsecutiveSuccesses = 1
+	}
+	if a.config.FlapWindow <= 0 {
+		a.config.FlapWindow = defaultFlapWindow
+	}
+
+	for _, rule := range config.Notifications.Rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			log.Printf("health: ignoring notification rule %q: %v", rule.Name, err)
+			continue
+		}
+		a.rules = append(a.rules, compiled)
+	}
+
+	a.templates["default"] = template.Must(template.New("default").Parse(defaultTemplate))
+	for name, text := range config.Notifications.Templates {
+		tmpl, err := template.New(name).Parse(text)
+		if err != nil {
+			log.Printf("health: ignoring notification template %q: %v", name, err)
+			continue
+		}
+		a.templates[name] = tmpl
+	}
+
+	for _, channel := range config.Notifications.Channels {
+		if !channel.Enabled {
+			continue
+		}
+		notifier, err := newNotifier(channel)
+		if err != nil {
+			log.Printf("health: ignoring notification channel %q: %v", channel.Name, err)
+			continue
+		}
+		a.notifiers[channel.Name] = notifier
+	}
+
+	for _, checker := range config.Checkers {
+		if len(checker.DependsOn) > 0 {
+			a.dependsOn[checker.Name] = checker.DependsOn
+		}
+	}
+
+	return a
+}
+
+func (a *alerter) addNotifier(name string, notifier Notifier) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.notifiers[name] = notifier
+}
+
+// setDependencies 设置依赖，拒绝形成环的依赖
+func (a *alerter) setDependencies(name string, dependsOn []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous := a.dependsOn[name]
+	a.dependsOn[name] = append([]string(nil), dependsOn...)
+	if a.dependsOnTransitively(name, name, map[string]bool{}) {
+		a.dependsOn[name] = previous
+		return fmt.Errorf("dependencies of checker '%s' form a cycle", name)
+	}
+	return nil
+}
+
+func (a *alerter) dependsOnTransitively(from, target string, visited map[string]bool) bool {
+	for _, dep := range a.dependsOn[from] {
+		if dep == target {
+			return true
+		}
+		if visited[dep] {
+			continue
+		}
+		visited[dep] = true
+		if a.dependsOnTransitively(dep, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyResponseTime 按响应时间阈值调整检查结果
+func (a *alerter) applyResponseTime(result CheckResult) CheckResult {
+	threshold := a.thresholds.ResponseTime
+	switch {
+	case threshold.Critical > 0 && result.Duration > threshold.Critical && result.Status != StatusUnhealthy:
+		result.Status = StatusUnhealthy
+		result.Message = fmt.Sprintf("response time %s exceeds %s", result.Duration, threshold.Critical)
+	case threshold.Warning > 0 && result.Duration > threshold.Warning && result.Status == StatusHealthy:
+		result.Status = StatusDegraded
+		result.Message = fmt.Sprintf("response time %s exceeds %s", result.Duration, threshold.Warning)
+	}
+	return result
+}
+
+// observe 处理一轮检查结果，按依赖顺序更新状态并发送通知。
+// 监听器在释放锁后调用，可以回调管理器
+func (a *alerter) observe(checkers map[string]HealthChecker, results map[string]CheckResult) {
+	for _, event := range a.apply(checkers, results) {
+		for _, listener := range a.listeners() {
+			listener.OnHealthChanged(event)
+		}
+	}
+}
+
+// apply 在锁内更新状态，返回已确认的状态变化事件
+func (a *alerter) apply(checkers map[string]HealthChecker, results map[string]CheckResult) []HealthEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var changes []HealthEvent
+	now := time.Now()
+	for _, name := range a.dependencyOrder(results) {
+		result := results[name]
+		state := a.states[name]
+		if state == nil {
+			state = &checkerState{status: StatusUnknown, notified: StatusUnknown}
+			a.states[name] = state
+		}
+		if checker, ok := checkers[name]; ok {
+			state.checker = checker
+		}
+		if !result.Timestamp.IsZero() && !result.Timestamp.After(state.lastResult) {
+			continue
+		}
+		state.lastResult = result.Timestamp
+
+		if event := a.update(name, state, result, now); event != nil {
+			changes = append(changes, *event)
+		}
+		a.reconcile(name, state, now)
+	}
+	return changes
+}
+
+// update 记录结果并在连续次数达到阈值后确认状态变化，返回状态变化事件
+func (a *alerter) update(name string, state *checkerState, result CheckResult, now time.Time) *HealthEvent {
+	state.history = append(state.history, result.Status)
+	if len(state.history) > a.thresholds.Window {
+		state.history = state.history[len(state.history)-a.thresholds.Window:]
+	}
+
+	effective := worse(result.Status, a.windowStatus(state.history))
+	if effective == state.pending {
+		state.streak++
+	} else {
+		state.pending = effective
+		state.streak = 1
+	}
+
+	required := a.thresholds.ConsecutiveFailures
+	if effective == StatusHealthy {
+		required = a.thresholds.ConsecutiveSuccesses
+	}
+	if state.streak < required || effective == state.status {
+		return nil
+	}
+
+	previous := state.status
+	state.status = effective
+	state.details = result.Error
+	if state.details == "" {
+		state.details = result.Message
+	}
+
+	// 首次检查即健康不算状态变化
+	if previous == StatusUnknown && effective == StatusHealthy {
+		state.notified = StatusHealthy
+		return nil
+	}
+
+	event := a.newEvent(name, previous, effective, state.details)
+
+	if a.config.FlapThreshold > 0 {
+		state.transitions = append(pruneBefore(state.transitions, now.Add(-a.config.FlapWindow)), now)
+		if !state.flapping && len(state.transitions) >= a.config.FlapThreshold {
+			state.flapping = true
+			flap := a.newEvent(name, previous, effective, fmt.Sprintf("%d status changes within %s, notifications paused", len(state.transitions), a.config.FlapWindow))
+			flap.Type = EventTypeFlapping
+			a.notify(name, flap)
+		}
+	}
+	return &event
+}
+
+// reconcile 在未抖动、未被依赖抑制时，将已确认状态通知出去
+func (a *alerter) reconcile(name string, state *checkerState, now time.Time) {
+	if state.flapping {
+		state.transitions = pruneBefore(state.transitions, now.Add(-a.config.FlapWindow))
+		if len(state.transitions) > 0 {
+			return
+		}
+		state.flapping = false
+	}
+
+	if state.status == state.notified || state.status == StatusUnknown {
+		return
+	}
+	if state.status == StatusHealthy && state.notified == StatusUnknown {
+		// 从未通知过的故障恢复时无需通知
+		state.notified = StatusHealthy
+		return
+	}
+
+	// 依赖故障时下游告警被抑制；依赖恢复后若本检查器仍异常会再通知
+	if state.status != StatusHealthy && a.failedDependency(name, map[string]bool{}) != "" {
+		return
+	}
+
+	event := a.newEvent(name, state.notified, state.status, state.details)
+	state.notified = state.status
+	a.notify(name, event)
+}
+
+// failedDependency 返回不健康的（间接）依赖名称
+func (a *alerter) failedDependency(name string, visited map[string]bool) string {
+	if visited[name] {
+		return ""
+	}
+	visited[name] = true
+
+	for _, dep := range a.dependsOn[name] {
+		if state := a.states[dep]; state != nil && state.status == StatusUnhealthy {
+			return dep
+		}
+		if cause := a.failedDependency(dep, visited); cause != "" {
+			return cause
+		}
+	}
+	return ""
+}
+
+// windowStatus 按最近检查的错误率和可用性计算状态，样本不足时视为健康
+func (a *alerter) windowStatus(history []Status) Status {
+	if len(history) < minWindowSamples {
+		return StatusHealthy
+	}
+
+	var failed, available int
+	for _, status := range history {
+		switch status {
+		case StatusUnhealthy:
+			failed++
+		case StatusHealthy:
+			available++
+		}
+	}
+	errorRate := float64(failed) / float64(len(history))
+	availability := float64(available) / float64(len(history))
+
+	errorThreshold, availabilityThreshold := a.thresholds.ErrorRate, a.thresholds.Availability
+	switch {
+	case errorThreshold.Critical > 0 && errorRate >= errorThreshold.Critical,
+		availabilityThreshold.Critical > 0 && availability < availabilityThreshold.Critical:
+		return StatusUnhealthy
+	case errorThreshold.Warning > 0 && errorRate >= errorThreshold.Warning,
+		availabilityThreshold.Warning > 0 && availability < availabilityThreshold.Warning:
+		return StatusDegraded
+	}
+	return StatusHealthy
+}
+
+// dependencyOrder 依赖在前的检查器处理顺序，使同一轮中依赖的状态先更新
+func (a *alerter) dependencyOrder(results map[string]CheckResult) []string {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	order := make([]string, 0, len(names))
+	visited := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, dep := range a.dependsOn[name] {
+			visit(dep)
+		}
+		if _, ok := results[name]; ok {
+			order = append(order, name)
+		}
+	}
+	for _, name := range names {
+		visit(name)
+	}
+	return order
+}
+
+func (a *alerter) newEvent(name string, previous, current Status, details string) HealthEvent {
+	eventType := EventTypeHealthy
+	switch current {
+	case StatusUnhealthy:
+		eventType = EventTypeUnhealthy
+	case StatusDegraded:
+		eventType = EventTypeDegraded
+	case StatusHealthy:
+		if previous == StatusUnhealthy || previous == StatusDegraded {
+			eventType = EventTypeRecovered
+		}
+	}
+
+	metadata := map[string]string{"checker": name}
+	if state := a.states[name]; state != nil && state.checker != nil {
+		metadata["type"] = string(state.checker.Type())
+		metadata["priority"] = strconv.Itoa(int(state.checker.Priority()))
+	}
+
+	return HealthEvent{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Service: ServiceInfo{
+			Name:        a.service.Name,
+			Version:     a.service.Version,
+			Environment: a.service.Environment,
+		},
+		Previous: previous,
+		Current:  current,
+		Details:  details,
+		Metadata: metadata,
+	}
+}
+
+// notify 按规则将事件异步发送到通知渠道，同一渠道的通知按产生顺序发送
+func (a *alerter) notify(name string, event HealthEvent) {
+	if !a.config.Enabled {
+		return
+	}
+
+	now := time.Now()
+	for _, rule := range a.rules {
+		if !rule.match(event) {
+			continue
+		}
+
+		key := rule.Name + "|" + name + "|" + string(event.Type)
+		if rule.Cooldown > 0 && now.Sub(a.lastSent[key]) < rule.Cooldown {
+			continue
+		}
+		a.lastSent[key] = now
+
+		notification := a.render(rule, name, event)
+		for _, channelName := range rule.Channels {
+			notifier, ok := a.notifiers[channelName]
+			if !ok {
+				continue
+			}
+			queue := a.queues[channelName]
+			if queue == nil {
+				queue = &channelQueue{}
+				a.queues[channelName] = queue
+			}
+			a.enqueue(channelName, queue, queuedNotification{notifier: notifier, notification: notification})
+		}
+	}
+}
+
+// enqueue 将通知加入渠道队列，队列空闲时启动发送协程
+func (a *alerter) enqueue(channelName string, queue *channelQueue, item queuedNotification) {
+	a.sendWG.Add(1)
+
+	queue.mu.Lock()
+	queue.pending = append(queue.pending, item)
+	if queue.running {
+		queue.mu.Unlock()
+		return
+	}
+	queue.running = true
+	queue.mu.Unlock()
+
+	go a.drain(channelName, queue)
+}
+
+// drain 依次发送队列中的通知，队列清空后退出
+func (a *alerter) drain(channelName string, queue *channelQueue) {
+	for {
+		queue.mu.Lock()
+		if len(queue.pending) == 0 {
+			queue.running = false
+			queue.mu.Unlock()
+			return
+		}
+		item := queue.pending[0]
+		queue.pending = queue.pending[1:]
+		queue.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := item.notifier.Notify(ctx, item.notification); err != nil {
+			log.Printf("health: failed to send notification %q via %s: %v", item.notification.Rule, channelName, err)
+		}
+		cancel()
+		a.sendWG.Done()
+	}
+}
+
+func (a *alerter) render(rule compiledRule, name string, event HealthEvent) *Notification {
+	severity := rule.Severity
+	if severity == "" {
+		severity = defaultSeverity(event)
+	}
+
+	notification := &Notification{
+		Rule:      rule.Name,
+		Severity:  severity,
+		Checker:   name,
+		Event:     event,
+		Timestamp: event.Timestamp,
+	}
+	notification.Subject = fmt.Sprintf("[%s] %s %s is %s", strings.ToUpper(severity), event.Service.Name, name, event.Type)
+
+	tmpl := a.templates[rule.Name]
+	if tmpl == nil {
+		tmpl = a.templates[string(event.Type)]
+	}
+	if tmpl == nil {
+		tmpl = a.templates["default"]
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, notification); err != nil {
+		buf.Reset()
+		a.templates["default"].Execute(&buf, notification)
+	}
+	notification.Message = buf.String()
+	return notification
+}
+
+func defaultSeverity(event HealthEvent) string {
+	switch event.Type {
+	case EventTypeUnhealthy:
+		return "critical"
+	case EventTypeDegraded, EventTypeFlapping:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// compiledRule 解析后的通知规则
+type compiledRule struct {
+	NotificationRule
+	clauses []ruleClause
+}
+
+type ruleClause struct {
+	field string
+	op    string
+	value string
+}
+
+var ruleOperators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+func compileRule(rule NotificationRule) (compiledRule, error) {
+	compiled := compiledRule{NotificationRule: rule}
+	if strings.TrimSpace(rule.Condition) == "" {
+		return compiled, nil
+	}
+
+	for _, part := range strings.Split(rule.Condition, "&&") {
+		part = strings.TrimSpace(part)
+		var clause ruleClause
+		for _, op := range ruleOperators {
+			if i := strings.Index(part, op); i > 0 {
+				clause = ruleClause{
+					field: strings.TrimSpace(part[:i]),
+					op:    op,
+					value: strings.Trim(strings.TrimSpace(part[i+len(op):]), `"'`),
+				}
+				break
+			}
+		}
+
+		switch clause.field {
+		case "status", "previous", "event", "checker", "type":
+			if clause.op != "==" && clause.op != "!=" {
+				return compiled, fmt.Errorf("operator %s not supported for %s", clause.op, clause.field)
+			}
+		case "priority":
+			if _, err := parsePriority(clause.value); err != nil {
+				return compiled, err
+			}
+		default:
+			return compiled, fmt.Errorf("invalid condition %q", part)
+		}
+		compiled.clauses = append(compiled.clauses, clause)
+	}
+	return compiled, nil
+}
+
+func (r compiledRule) match(event HealthEvent) bool {
+	for _, clause := range r.clauses {
+		var actual string
+		switch clause.field {
+		case "status":
+			actual = string(event.Current)
+		case "previous":
+			actual = string(event.Previous)
+		case "event":
+			actual = string(event.Type)
+		case "checker":
+			actual = event.Metadata["checker"]
+		case "type":
+			actual = event.Metadata["type"]
+		case "priority":
+			priority, err := strconv.Atoi(event.Metadata["priority"])
+			if err != nil {
+				return false
+			}
+			want, _ := parsePriority(clause.value)
+			if !compareInts(priority, clause.op, int(want)) {
+				return false
+			}
+			continue
+		}
+
+		if (actual == clause.value) != (clause.op == "==") {
+			return false
+		}
+	}
+	return true
+}
+
+func parsePriority(value string) (Priority, error) {
+	switch strings.ToLower(value) {
+	case "low":
+		return PriorityLow, nil
+	case "medium":
+		return PriorityMedium, nil
+	case "high":
+		return PriorityHigh, nil
+	case "critical":
+		return PriorityCritical, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid priority %q", value)
+	}
+	return Priority(n), nil
+}
+
+func compareInts(a int, op string, b int) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case ">=":
+		return a >= b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	default:
+		return a < b
+	}
+}
+
+// statusRank 状态严重程度
+func statusRank(status Status) int {
+	switch status {
+	case StatusHealthy:
+		return 0
+	case StatusDegraded, StatusUnknown:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func worse(a, b Status) Status {
+	if statusRank(b) > statusRank(a) {
+		return b
+	}
+	return a
+}
+
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
@@ -0,0 +1,328 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubChecker 返回预设状态的检查器
+type stubChecker struct {
+	name     string
+	priority Priority
+	mu       sync.Mutex
+	status   Status
+}
+
+func (c *stubChecker) Name() string       { return c.name }
+func (c *stubChecker) Type() CheckerType  { return CheckerTypeCustom }
+func (c *stubChecker) Priority() Priority { return c.priority }
+
+func (c *stubChecker) set(status Status) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = status
+}
+
+func (c *stubChecker) Check(ctx context.Context) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := CheckResult{Name: c.name, Status: c.status, Timestamp: time.Now()}
+	if c.status == StatusUnhealthy {
+		result.Error = "connection refused"
+	}
+	return result
+}
+
+// recordingNotifier 记录收到的通知
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification *Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, notification.Checker+":"+string(notification.Event.Type))
+	return nil
+}
+
+func (n *recordingNotifier) received() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return strings.Join(n.events, ",")
+}
+
+func newTestManager(t *testing.T, config HealthConfig, checkers ...*stubChecker) (*DefaultHealthManager, *recordingNotifier) {
+	t.Helper()
+	config.Timeout = time.Second
+	config.Notifications.Enabled = true
+	if len(config.Notifications.Rules) == 0 {
+		config.Notifications.Rules = []NotificationRule{{Name: "all", Channels: []string{"test"}}}
+	}
+
+	manager := NewHealthManager(config).(*DefaultHealthManager)
+	for _, checker := range checkers {
+		if err := manager.AddChecker(checker); err != nil {
+			t.Fatal(err)
+		}
+	}
+	notifier := &recordingNotifier{}
+	manager.AddNotifier("test", notifier)
+	return manager, notifier
+}
+
+// run 按顺序设置状态并执行检查，等待通知发送完成
+func run(m *DefaultHealthManager, checker *stubChecker, statuses ...Status) {
+	for _, status := range statuses {
+		checker.set(status)
+		m.Check(context.Background())
+		time.Sleep(time.Millisecond) // 保证结果时间戳递增
+	}
+	m.alerter.sendWG.Wait()
+}
+
+func TestConsecutiveFailureThreshold(t *testing.T) {
+	db := &stubChecker{name: "db", priority: PriorityCritical, status: StatusHealthy}
+	manager, notifier := newTestManager(t, HealthConfig{
+		Thresholds: ThresholdConfig{ConsecutiveFailures: 3, ConsecutiveSuccesses: 2},
+	}, db)
+
+	run(manager, db, StatusHealthy, StatusUnhealthy, StatusUnhealthy, StatusHealthy)
+	if got := notifier.received(); got != "" {
+		t.Fatalf("transient failure notified: %s", got)
+	}
+
+	run(manager, db, StatusUnhealthy, StatusUnhealthy, StatusUnhealthy)
+	if got := notifier.received(); got != "db:unhealthy" {
+		t.Fatalf("received = %q", got)
+	}
+
+	run(manager, db, StatusHealthy)
+	if got := notifier.received(); got != "db:unhealthy" {
+		t.Fatalf("recovery notified before ConsecutiveSuccesses: %q", got)
+	}
+	run(manager, db, StatusHealthy)
+	if got := notifier.received(); got != "db:unhealthy,db:recovered" {
+		t.Fatalf("received = %q", got)
+	}
+}
+
+func TestRuleConditions(t *testing.T) {
+	rule, err := compileRule(NotificationRule{Condition: "status == unhealthy && priority >= high && checker != cache"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := HealthEvent{Current: StatusUnhealthy, Metadata: map[string]string{"checker": "db", "priority": "4"}}
+	if !rule.match(event) {
+		t.Error("expected match")
+	}
+	event.Metadata["priority"] = "2"
+	if rule.match(event) {
+		t.Error("low priority should not match")
+	}
+	event.Metadata = map[string]string{"checker": "cache", "priority": "4"}
+	if rule.match(event) {
+		t.Error("excluded checker should not match")
+	}
+
+	if _, err := compileRule(NotificationRule{Condition: "status > unhealthy"}); err == nil {
+		t.Error("expected error for ordering operator on status")
+	}
+	if _, err := compileRule(NotificationRule{Condition: "latency == 1"}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestRuleCooldown(t *testing.T) {
+	db := &stubChecker{name: "db", priority: PriorityCritical, status: StatusHealthy}
+	manager, notifier := newTestManager(t, HealthConfig{
+		Notifications: NotificationConfig{Rules: []NotificationRule{
+			{Name: "down", Condition: "event == unhealthy", Channels: []string{"test"}, Cooldown: time.Hour},
+		}},
+	}, db)
+
+	run(manager, db, StatusHealthy, StatusUnhealthy, StatusHealthy, StatusUnhealthy)
+	if got := notifier.received(); got != "db:unhealthy" {
+		t.Fatalf("received = %q", got)
+	}
+}
+
+func TestFlapDamping(t *testing.T) {
+	db := &stubChecker{name: "db", priority: PriorityCritical, status: StatusHealthy}
+	manager, notifier := newTestManager(t, HealthConfig{
+		Notifications: NotificationConfig{FlapThreshold: 3, FlapWindow: 50 * time.Millisecond},
+	}, db)
+
+	run(manager, db, StatusHealthy, StatusUnhealthy, StatusHealthy, StatusUnhealthy, StatusHealthy, StatusUnhealthy)
+	if got := notifier.received(); got != "db:unhealthy,db:recovered,db:flapping" {
+		t.Fatalf("received = %q", got)
+	}
+
+	// 窗口内不再变化后恢复通知，发送当前状态
+	time.Sleep(60 * time.Millisecond)
+	run(manager, db, StatusUnhealthy)
+	if got := notifier.received(); got != "db:unhealthy,db:recovered,db:flapping,db:unhealthy" {
+		t.Fatalf("received = %q", got)
+	}
+}
+
+func TestDependencySuppression(t *testing.T) {
+	redis := &stubChecker{name: "redis", priority: PriorityCritical, status: StatusHealthy}
+	sessions := &stubChecker{name: "sessions", priority: PriorityHigh, status: StatusHealthy}
+	manager, notifier := newTestManager(t, HealthConfig{}, redis, sessions)
+
+	if err := manager.SetDependencies("sessions", "redis"); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetDependencies("redis", "sessions"); err == nil {
+		t.Fatal("expected cycle error")
+	}
+	if err := manager.SetDependencies("sessions", "missing"); err == nil {
+		t.Fatal("expected unknown dependency error")
+	}
+
+	redis.set(StatusUnhealthy)
+	run(manager, sessions, StatusHealthy, StatusUnhealthy)
+	if got := notifier.received(); got != "redis:unhealthy" {
+		t.Fatalf("received = %q", got)
+	}
+
+	// 依赖恢复后下游仍异常，发送下游告警
+	redis.set(StatusHealthy)
+	run(manager, sessions, StatusUnhealthy)
+	if got := notifier.received(); got != "redis:unhealthy,redis:recovered,sessions:unhealthy" {
+		t.Fatalf("received = %q", got)
+	}
+}
+
+// reentrantListener 在状态变化时回调管理器
+type reentrantListener struct {
+	manager *DefaultHealthManager
+	events  chan HealthEvent
+}
+
+func (l *reentrantListener) OnHealthChanged(event HealthEvent) {
+	l.manager.AddNotifier("late", &recordingNotifier{})
+	l.manager.SetDependencies(event.Metadata["checker"])
+	l.events <- event
+}
+
+func (l *reentrantListener) OnCheckerAdded(checker HealthChecker) {}
+func (l *reentrantListener) OnCheckerRemoved(name string)         {}
+func (l *reentrantListener) OnCheckCompleted(result CheckResult)  {}
+func (l *reentrantListener) OnCheckFailed(name string, err error) {}
+
+func TestListenerCanCallManager(t *testing.T) {
+	db := &stubChecker{name: "db", priority: PriorityCritical, status: StatusHealthy}
+	manager, _ := newTestManager(t, HealthConfig{}, db)
+	listener := &reentrantListener{manager: manager, events: make(chan HealthEvent, 1)}
+	manager.AddListener(listener)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(manager, db, StatusHealthy, StatusUnhealthy)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("health check blocked by listener")
+	}
+
+	select {
+	case event := <-listener.events:
+		if event.Metadata["checker"] != "db" || event.Current != StatusUnhealthy {
+			t.Fatalf("event = %+v", event)
+		}
+	default:
+		t.Fatal("listener not called")
+	}
+}
+
+func TestResponseTimeThreshold(t *testing.T) {
+	a := newAlerter(HealthConfig{Thresholds: ThresholdConfig{
+		ResponseTime: ResponseTimeThreshold{Warning: 100 * time.Millisecond, Critical: time.Second},
+	}}, func() []HealthEventListener { return nil })
+
+	if got := a.applyResponseTime(CheckResult{Status: StatusHealthy, Duration: 200 * time.Millisecond}); got.Status != StatusDegraded {
+		t.Errorf("status = %s, want degraded", got.Status)
+	}
+	if got := a.applyResponseTime(CheckResult{Status: StatusDegraded, Duration: 2 * time.Second}); got.Status != StatusUnhealthy {
+		t.Errorf("status = %s, want unhealthy", got.Status)
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	if _, err := newNotifier(NotificationChannel{Name: "hook", Type: "webhook"}); err == nil {
+		t.Error("expected error for webhook without url")
+	}
+	if _, err := newNotifier(NotificationChannel{Name: "pager", Type: "pager"}); err == nil {
+		t.Error("expected error for unknown type")
+	}
+
+	RegisterNotifierType("pager", func(channel NotificationChannel) (Notifier, error) {
+		return NotifierFunc(func(context.Context, *Notification) error { return errors.New("unused") }), nil
+	})
+	t.Cleanup(func() {
+		notifierFactoriesMu.Lock()
+		defer notifierFactoriesMu.Unlock()
+		delete(notifierFactories, "pager")
+	})
+	if _, err := newNotifier(NotificationChannel{Name: "pager", Type: "pager"}); err != nil {
+		t.Errorf("registered type: %v", err)
+	}
+}
+
+func TestProbes(t *testing.T) {
+	db := &stubChecker{name: "db", priority: PriorityCritical, status: StatusHealthy}
+	search := &stubChecker{name: "search", priority: PriorityHigh, status: StatusUnhealthy}
+	metrics := &stubChecker{name: "metrics", priority: PriorityLow, status: StatusUnhealthy}
+	manager, _ := newTestManager(t, HealthConfig{}, db, search, metrics)
+
+	mux := http.NewServeMux()
+	manager.RegisterProbes(mux)
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	if code, body := get("/livez"); code != http.StatusOK || body != "ok" {
+		t.Errorf("livez = %d %q", code, body)
+	}
+	if code, _ := get("/startupz"); code != http.StatusServiceUnavailable {
+		t.Errorf("startupz before ready = %d", code)
+	}
+
+	code, body := get("/readyz")
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "[-]search failed: connection refused") || strings.Contains(body, "metrics") {
+		t.Errorf("readyz = %d %q", code, body)
+	}
+
+	code, body = get("/readyz?exclude=search&verbose")
+	if code != http.StatusOK || !strings.Contains(body, "[+]db ok") || !strings.Contains(body, "[+]search excluded: ok") {
+		t.Errorf("readyz with exclude = %d %q", code, body)
+	}
+
+	// 启动探针一旦通过不再回退
+	search.set(StatusHealthy)
+	if code, _ := get("/startupz"); code != http.StatusOK {
+		t.Errorf("startupz = %d", code)
+	}
+	search.set(StatusUnhealthy)
+	if code, _ := get("/startupz"); code != http.StatusOK {
+		t.Errorf("startupz after ready = %d", code)
+	}
+
+	db.set(StatusUnhealthy)
+	if code, _ := get("/livez"); code != http.StatusServiceUnavailable {
+		t.Errorf("livez with failed critical checker = %d", code)
+	}
+}
@@ -73,6 +73,7 @@ type CheckerConfig struct {
 	Metadata    map[string]string `yaml:"metadata" json:"metadata"`
 	Tags        []string          `yaml:"tags" json:"tags"`
 	Description string            `yaml:"description" json:"description"`
+	DependsOn   []string          `yaml:"depends_on" json:"depends_on"` // 依赖的检查器，依赖不健康时不发送本检查器的告警
 }
 
 // HealthConfig 健康检查配置
@@ -87,6 +88,15 @@ type HealthConfig struct {
 	Cache           CacheConfig              `yaml:"cache" json:"cache"`
 	Thresholds      ThresholdConfig          `yaml:"thresholds" json:"thresholds"`
 	ResponseFormats []ResponseFormatConfig   `yaml:"response_formats" json:"response_formats"`
+	Probes          ProbeConfig              `yaml:"probes" json:"probes"`
+}
+
+// ProbeConfig Kubernetes 探针配置
+type ProbeConfig struct {
+	// LivenessPriority /livez 只执行不低于该优先级的检查器，默认 PriorityCritical
+	LivenessPriority Priority `yaml:"liveness_priority" json:"liveness_priority"`
+	// ReadinessPriority /readyz 只执行不低于该优先级的检查器，默认 PriorityHigh
+	ReadinessPriority Priority `yaml:"readiness_priority" json:"readiness_priority"`
 }
 
 // ServiceConfig 服务配置
@@ -104,7 +114,10 @@ type NotificationConfig struct {
 	Enabled   bool                   `yaml:"enabled" json:"enabled"`
 	Channels  []NotificationChannel  `yaml:"channels" json:"channels"`
 	Rules     []NotificationRule     `yaml:"rules" json:"rules"`
-	Templates map[string]string      `yaml:"templates" json:"templates"`
+	Templates map[string]string      `yaml:"templates" json:"templates"` // 按规则名或事件类型选择模板，"default" 为默认模板
+	// FlapThreshold FlapWindow 内状态变化达到该次数时视为抖动，暂停通知直到 FlapWindow 内不再变化；0 表示不检测
+	FlapThreshold int           `yaml:"flap_threshold" json:"flap_threshold"`
+	FlapWindow    time.Duration `yaml:"flap_window" json:"flap_window"`
 }
 
 // NotificationChannel 通知渠道
@@ -116,6 +129,9 @@ type NotificationChannel struct {
 }
 
 // NotificationRule 通知规则
+//
+// Condition 由 "&&" 连接的比较组成，如 "status == unhealthy && priority >= high"，
+// 可用字段为 status、previous、event、checker、type、priority，为空时匹配所有状态变化
 type NotificationRule struct {
 	Name      string      `yaml:"name" json:"name"`
 	Condition string      `yaml:"condition" json:"condition"`
@@ -141,10 +157,17 @@ type CacheConfig struct {
 }
 
 // ThresholdConfig 阈值配置
+//
+// 响应时间超过阈值的检查结果降级为 degraded 或 unhealthy；错误率和可用性按最近
+// Window 次检查计算。状态连续 ConsecutiveFailures 次变差（或 ConsecutiveSuccesses
+// 次恢复）后才确认变化并触发事件
 type ThresholdConfig struct {
-	ResponseTime ResponseTimeThreshold `yaml:"response_time" json:"response_time"`
-	ErrorRate    ErrorRateThreshold    `yaml:"error_rate" json:"error_rate"`
-	Availability AvailabilityThreshold `yaml:"availability" json:"availability"`
+	ResponseTime         ResponseTimeThreshold `yaml:"response_time" json:"response_time"`
+	ErrorRate            ErrorRateThreshold    `yaml:"error_rate" json:"error_rate"`
+	Availability         AvailabilityThreshold `yaml:"availability" json:"availability"`
+	ConsecutiveFailures  int                   `yaml:"consecutive_failures" json:"consecutive_failures"`
+	ConsecutiveSuccesses int                   `yaml:"consecutive_successes" json:"consecutive_successes"`
+	Window               int                   `yaml:"window" json:"window"`
 }
 
 // ResponseTimeThreshold 响应时间阈值
@@ -201,4 +224,5 @@ const (
 	EventTypeUnhealthy EventType = "unhealthy"
 	EventTypeDegraded  EventType = "degraded"
 	EventTypeRecovered EventType = "recovered"
+	EventTypeFlapping  EventType = "flapping"
 )
\ No newline at end of file
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,6 +18,8 @@ type DefaultHealthManager struct {
 	mu        sync.RWMutex
 	cache     *healthCache
 	metrics   *healthMetrics
+	alerter   *alerter
+	started   atomic.Bool
 }
 
 // NewHealthManager 创建健康检查管理器
@@ -38,6 +41,9 @@ func NewHealthManager(config HealthConfig) HealthManager {
 		manager.metrics = newHealthMetrics(config.Metrics)
 	}
 
+	// 初始化告警
+	manager.alerter = newAlerter(config, manager.snapshotListeners)
+
 	return manager
 }
 
@@ -154,7 +160,7 @@ func (m *DefaultHealthManager) checkWithFilter(ctx context.Context, filter func(
 			}
 
 			// 执行检查
-			result := m.executeCheck(ctx, checker)
+			result := m.alerter.applyResponseTime(m.executeCheck(ctx, checker))
 
 			// 缓存结果
 			if m.cache != nil {
@@ -338,10 +344,45 @@ func (m *DefaultHealthManager) calculateSummary(results map[string]CheckResult)
 	return summary
 }
 
-// checkStatusChange 检查状态变化
+// checkStatusChange 检查状态变化，按阈值确认后通知监听器并评估通知规则
 func (m *DefaultHealthManager) checkStatusChange(report HealthReport) {
-	// 这里可以实现状态变化检测和事件发送逻辑
-	// 为了简化，暂时跳过实现
+	m.mu.RLock()
+	checkers := make(map[string]HealthChecker, len(m.checkers))
+	for name, checker := range m.checkers {
+		checkers[name] = checker
+	}
+	m.mu.RUnlock()
+
+	m.alerter.observe(checkers, report.Checks)
+}
+
+// snapshotListeners 返回当前监听器的副本
+func (m *DefaultHealthManager) snapshotListeners() []HealthEventListener {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]HealthEventListener(nil), m.listeners...)
+}
+
+// AddNotifier 添加通知渠道，通知规则通过 name 引用
+func (m *DefaultHealthManager) AddNotifier(name string, notifier Notifier) {
+	m.alerter.addNotifier(name, notifier)
+}
+
+// SetDependencies 设置检查器依赖，依赖不健康时不发送该检查器的告警
+func (m *DefaultHealthManager) SetDependencies(name string, dependsOn ...string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, exists := m.checkers[name]; !exists {
+		return fmt.Errorf("checker with name '%s' not found", name)
+	}
+	for _, dep := range dependsOn {
+		if _, exists := m.checkers[dep]; !exists {
+			return fmt.Errorf("dependency '%s' of checker '%s' not found", dep, name)
+		}
+	}
+
+	return m.alerter.setDependencies(name, dependsOn)
 }
 
 // healthCache 健康检查缓存
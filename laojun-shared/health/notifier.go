@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/codetaoist/laojun-shared/notification"
+	"github.com/google/uuid"
+)
+
+// Notifier 健康告警通知渠道
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// NotifierFunc 函数形式的 Notifier
+type NotifierFunc func(ctx context.Context, n *Notification) error
+
+// Notify 调用 f
+func (f NotifierFunc) Notify(ctx context.Context, n *Notification) error {
+	return f(ctx, n)
+}
+
+// NotifierFactory 根据渠道配置创建 Notifier
+type NotifierFactory func(channel NotificationChannel) (Notifier, error)
+
+var (
+	notifierFactoriesMu sync.RWMutex
+	notifierFactories   = map[string]NotifierFactory{
+		"log":     newLogNotifier,
+		"webhook": newWebhookNotifier,
+		"email":   newEmailNotifier,
+	}
+)
+
+// RegisterNotifierType 注册自定义渠道类型，NotificationChannel.Type 为 typ 的渠道由 factory 创建
+func RegisterNotifierType(typ string, factory NotifierFactory) {
+	notifierFactoriesMu.Lock()
+	defer notifierFactoriesMu.Unlock()
+	notifierFactories[typ] = factory
+}
+
+func newNotifier(channel NotificationChannel) (Notifier, error) {
+	notifierFactoriesMu.RLock()
+	factory, ok := notifierFactories[channel.Type]
+	notifierFactoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported notification channel type %q", channel.Type)
+	}
+	return factory(channel)
+}
+
+// newLogNotifier 将告警写入标准日志
+func newLogNotifier(channel NotificationChannel) (Notifier, error) {
+	prefix := channel.Config["prefix"]
+	if prefix == "" {
+		prefix = "health"
+	}
+	return NotifierFunc(func(ctx context.Context, n *Notification) error {
+		log.Printf("%s: %s", prefix, n.Message)
+		return nil
+	}), nil
+}
+
+// newWebhookNotifier 以JSON POST 告警，配置项 url、secret
+func newWebhookNotifier(channel NotificationChannel) (Notifier, error) {
+	url := channel.Config["url"]
+	if url == "" {
+		return nil, fmt.Errorf("webhook channel %q requires url", channel.Name)
+	}
+	headers := make(map[string]string)
+	for key, value := range channel.Config {
+		if name, ok := strings.CutPrefix(key, "header."); ok {
+			headers[name] = value
+		}
+	}
+
+	webhook := notification.NewWebhookChannel(notification.WebhookConfig{
+		URL:     url,
+		Secret:  channel.Config["secret"],
+		Headers: headers,
+	}, nil)
+	return &channelNotifier{channel: webhook, recipients: []notification.Recipient{{}}}, nil
+}
+
+// newEmailNotifier 通过SMTP发送告警，配置项 host、port、username、password、from、tls、to（逗号分隔）
+func newEmailNotifier(channel NotificationChannel) (Notifier, error) {
+	config := channel.Config
+	port := 0
+	if config["port"] != "" {
+		p, err := strconv.Atoi(config["port"])
+		if err != nil {
+			return nil, fmt.Errorf("email channel %q: invalid port %q", channel.Name, config["port"])
+		}
+		port = p
+	}
+
+	email, err := notification.NewEmailChannel(notification.SMTPConfig{
+		Host:     config["host"],
+		Port:     port,
+		Username: config["username"],
+		Password: config["password"],
+		From:     config["from"],
+		TLS:      config["tls"],
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []notification.Recipient
+	for _, to := range strings.Split(config["to"], ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			recipients = append(recipients, notification.Recipient{Email: to})
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("email channel %q requires to", channel.Name)
+	}
+	return &channelNotifier{channel: email, recipients: recipients}, nil
+}
+
+// channelNotifier 通过 notification 包的渠道发送告警
+type channelNotifier struct {
+	channel    notification.Channel
+	recipients []notification.Recipient
+}
+
+func (c *channelNotifier) Notify(ctx context.Context, n *Notification) error {
+	data := map[string]interface{}{
+		"rule":     n.Rule,
+		"severity": n.Severity,
+		"checker":  n.Checker,
+		"event":    n.Event,
+	}
+
+	var firstErr error
+	for _, recipient := range c.recipients {
+		delivery := &notification.Delivery{
+			ID:        uuid.NewString(),
+			Channel:   c.channel.Type(),
+			Template:  n.Rule,
+			Category:  "health",
+			Recipient: recipient,
+			Subject:   n.Subject,
+			Body:      n.Message,
+			Data:      data,
+			CreatedAt: n.Timestamp,
+		}
+		if err := c.channel.Send(ctx, delivery); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
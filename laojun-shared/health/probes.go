@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 探针路径
+const (
+	LivezPath    = "/livez"
+	ReadyzPath   = "/readyz"
+	StartupzPath = "/startupz"
+)
+
+// LivezHandler 存活探针，只执行不低于 Probes.LivenessPriority 的检查器
+func (m *DefaultHealthManager) LivezHandler() http.HandlerFunc {
+	return m.probeHandler("livez", func(ctx context.Context, exclude map[string]bool) (HealthReport, bool) {
+		return m.probe(ctx, m.livenessPriority(), exclude)
+	})
+}
+
+// ReadyzHandler 就绪探针，只执行不低于 Probes.ReadinessPriority 的检查器，降级不影响就绪
+func (m *DefaultHealthManager) ReadyzHandler() http.HandlerFunc {
+	return m.probeHandler("readyz", func(ctx context.Context, exclude map[string]bool) (HealthReport, bool) {
+		report, ok := m.probe(ctx, m.readinessPriority(), exclude)
+		if ok {
+			m.started.Store(true)
+		}
+		return report, ok
+	})
+}
+
+// StartupzHandler 启动探针，就绪检查首次通过后一直返回成功
+func (m *DefaultHealthManager) StartupzHandler() http.HandlerFunc {
+	return m.probeHandler("startupz", func(ctx context.Context, exclude map[string]bool) (HealthReport, bool) {
+		if m.started.Load() {
+			return HealthReport{}, true
+		}
+		report, ok := m.probe(ctx, m.readinessPriority(), exclude)
+		if ok {
+			m.started.Store(true)
+		}
+		return report, ok
+	})
+}
+
+// RegisterProbes 在 mux 上注册 /livez、/readyz、/startupz
+func (m *DefaultHealthManager) RegisterProbes(mux *http.ServeMux) {
+	mux.HandleFunc(LivezPath, m.LivezHandler())
+	mux.HandleFunc(ReadyzPath, m.ReadyzHandler())
+	mux.HandleFunc(StartupzPath, m.StartupzHandler())
+}
+
+// RegisterGinProbes 在 gin 路由上注册 /livez、/readyz、/startupz
+func (m *DefaultHealthManager) RegisterGinProbes(r gin.IRoutes) {
+	r.GET(LivezPath, gin.WrapH(m.LivezHandler()))
+	r.GET(ReadyzPath, gin.WrapH(m.ReadyzHandler()))
+	r.GET(StartupzPath, gin.WrapH(m.StartupzHandler()))
+}
+
+func (m *DefaultHealthManager) livenessPriority() Priority {
+	if m.config.Probes.LivenessPriority > 0 {
+		return m.config.Probes.LivenessPriority
+	}
+	return PriorityCritical
+}
+
+func (m *DefaultHealthManager) readinessPriority() Priority {
+	if m.config.Probes.ReadinessPriority > 0 {
+		return m.config.Probes.ReadinessPriority
+	}
+	return PriorityHigh
+}
+
+// probe 执行不低于 priority 且未被排除的检查器，任一检查器不健康时失败
+func (m *DefaultHealthManager) probe(ctx context.Context, priority Priority, exclude map[string]bool) (HealthReport, bool) {
+	report := m.checkWithFilter(ctx, func(checker HealthChecker) bool {
+		return checker.Priority() >= priority && !exclude[checker.Name()]
+	})
+	for _, result := range report.Checks {
+		if result.Status == StatusUnhealthy {
+			return report, false
+		}
+	}
+	return report, true
+}
+
+// probeHandler 以 Kubernetes 探针格式输出结果：成功时返回 "ok"，
+// 失败或带 ?verbose 参数时逐项列出检查结果；?exclude=name 可跳过指定检查器
+func (m *DefaultHealthManager) probeHandler(name string, run func(ctx context.Context, exclude map[string]bool) (HealthReport, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		exclude := make(map[string]bool)
+		for _, value := range query["exclude"] {
+			for _, checker := range strings.Split(value, ",") {
+				exclude[strings.TrimSpace(checker)] = true
+			}
+		}
+		_, verbose := query["verbose"]
+
+		report, ok := run(r.Context(), exclude)
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if ok && !verbose {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, "ok")
+			return
+		}
+
+		var b strings.Builder
+		names := make([]string, 0, len(report.Checks))
+		for checker := range report.Checks {
+			names = append(names, checker)
+		}
+		sort.Strings(names)
+		for _, checker := range names {
+			result := report.Checks[checker]
+			if result.Status == StatusUnhealthy {
+				reason := result.Error
+				if reason == "" {
+					reason = result.Message
+				}
+				fmt.Fprintf(&b, "[-]%s failed: %s\n", checker, reason)
+			} else {
+				fmt.Fprintf(&b, "[+]%s ok\n", checker)
+			}
+		}
+		excluded := make([]string, 0, len(exclude))
+		for checker := range exclude {
+			excluded = append(excluded, checker)
+		}
+		sort.Strings(excluded)
+		for _, checker := range excluded {
+			fmt.Fprintf(&b, "[+]%s excluded: ok\n", checker)
+		}
+
+		if ok {
+			fmt.Fprintf(&b, "%s check passed\n", name)
+			w.WriteHeader(http.StatusOK)
+		} else {
+			fmt.Fprintf(&b, "%s check failed\n", name)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		fmt.Fprint(w, b.String())
+	}
+}
@@ -0,0 +1,32 @@
+// Package redistest 为依赖 Redis 的测试提供内存 Redis 服务器。
+//
+// 服务器基于 miniredis，会执行真实的 Lua 脚本，测试因此覆盖脚本本身；
+// 通过 SetError 模拟 Redis 不可用，通过 FastForward 推进过期时间。
+package redistest
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Server 测试用的内存 Redis 服务器
+type Server struct {
+	*miniredis.Miniredis
+}
+
+// New 启动内存 Redis 服务器，测试结束时关闭
+func New(t testing.TB) *Server {
+	t.Helper()
+	return &Server{Miniredis: miniredis.RunT(t)}
+}
+
+// Client 创建连接服务器的客户端，测试结束时关闭。
+// 客户端不重试失败的命令，注入的错误直接返回给调用方
+func (s *Server) Client(t testing.TB) *redis.Client {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: s.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
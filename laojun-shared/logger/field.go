@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// Field 结构化日志字段
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String 字符串字段
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int 整数字段
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 64位整数字段
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Uint64 无符号整数字段
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float64 浮点数字段
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool 布尔字段
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration 时长字段，以字符串形式输出（如 "1.5s"）
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value.String()}
+}
+
+// Time 时间字段，以 RFC3339 格式输出
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value.Format(time.RFC3339Nano)}
+}
+
+// Err 错误字段，键为 "error"
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error", Value: nil}
+	}
+	return Field{Key: "error", Value: err.Error()}
+}
+
+// Stringer 以 String() 结果输出的字段
+func Stringer(key string, value fmt.Stringer) Field {
+	return Field{Key: key, Value: value.String()}
+}
+
+// Any 任意类型字段
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// fieldsFromMap 将 map 转为字段
+func fieldsFromMap(fields map[string]interface{}) []Field {
+	result := make([]Field, 0, len(fields))
+	for key, value := range fields {
+		result = append(result, Field{Key: key, Value: value})
+	}
+	return result
+}
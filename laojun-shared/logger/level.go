@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap/zapcore"
+)
+
+// Level 日志级别
+type Level int8
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+)
+
+// ParseLevel 解析日志级别
+func ParseLevel(level string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug", "trace":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal", "panic":
+		return FatalLevel, nil
+	}
+	return InfoLevel, fmt.Errorf("unknown log level %q", level)
+}
+
+// String 返回级别名称
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	}
+	return fmt.Sprintf("Level(%d)", l)
+}
+
+func (l Level) logrus() logrus.Level {
+	switch l {
+	case DebugLevel:
+		return logrus.DebugLevel
+	case WarnLevel:
+		return logrus.WarnLevel
+	case ErrorLevel:
+		return logrus.ErrorLevel
+	case FatalLevel:
+		return logrus.FatalLevel
+	}
+	return logrus.InfoLevel
+}
+
+func (l Level) zap() zapcore.Level {
+	switch l {
+	case DebugLevel:
+		return zapcore.DebugLevel
+	case WarnLevel:
+		return zapcore.WarnLevel
+	case ErrorLevel:
+		return zapcore.ErrorLevel
+	case FatalLevel:
+		return zapcore.FatalLevel
+	}
+	return zapcore.InfoLevel
+}
+
+// LevelHandler 运行时查看和修改日志级别的管理接口：
+//
+//	GET          返回 {"level": "info"}
+//	PUT / POST   请求体 {"level": "debug"} 或参数 ?level=debug
+//
+// 级别由同一 Logger 派生出的所有实例共享。该接口应只暴露在管理端口上。
+func LevelHandler(l Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			level := r.URL.Query().Get("level")
+			if level == "" {
+				var req struct {
+					Level string `json:"level"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					writeLevelResponse(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+					return
+				}
+				level = req.Level
+			}
+			if err := l.SetLevel(level); err != nil {
+				writeLevelResponse(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelResponse(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		writeLevelResponse(w, http.StatusOK, map[string]string{"level": l.GetLevel()})
+	})
+}
+
+func writeLevelResponse(w http.ResponseWriter, status int, body map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -8,8 +8,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/codetaoist/laojun-shared/tracing"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
@@ -31,6 +33,21 @@ type Logger interface {
 	WithFields(fields map[string]interface{}) Logger
 	WithContext(ctx context.Context) Logger
 	WithError(err error) Logger
+
+	// 结构化日志
+	Debugw(msg string, fields ...Field)
+	Infow(msg string, fields ...Field)
+	Warnw(msg string, fields ...Field)
+	Errorw(msg string, fields ...Field)
+	With(fields ...Field) Logger
+
+	// Every 返回限速的日志实例：同一级别、同一消息在 interval 内最多输出一条，
+	// 其间被抑制的条数随下一条输出的 suppressed 字段给出。用于热循环中的日志。
+	Every(interval time.Duration) Logger
+
+	// SetLevel 运行时修改日志级别，对同一 Logger 派生出的所有实例生效
+	SetLevel(level string) error
+	GetLevel() string
 }
 
 // Config 日志配置
@@ -42,6 +59,10 @@ type Config struct {
 	Service     string     `yaml:"service" env:"SERVICE_NAME" config:"log.service" default:"unknown"`
 	Version     string     `yaml:"version" env:"SERVICE_VERSION" config:"log.version" default:"unknown"`
 	Environment string     `yaml:"environment" env:"ENVIRONMENT" config:"log.environment" default:"development"`
+	// Backend 日志后端：logrus（默认）或 zap
+	Backend   string          `yaml:"backend" env:"LOG_BACKEND" config:"log.backend" default:"logrus"`
+	Redaction RedactionConfig `yaml:"redaction"`
+	Sampling  SamplingConfig  `yaml:"sampling"`
 }
 
 // FileConfig 文件日志配置
@@ -53,23 +74,15 @@ type FileConfig struct {
 	Compress   bool   `yaml:"compress" env:"LOG_FILE_COMPRESS" config:"log.file.compress" default:"true"`
 }
 
-// loggerImpl logrus实现
-type loggerImpl struct {
-	logger *logrus.Logger
-	entry  *logrus.Entry
-	config Config
-}
-
 // New 创建新的日志实例
 func New(config Config) Logger {
-	logger := logrus.New()
-
-	// 设置日志级别
-	level, err := logrus.ParseLevel(config.Level)
-	if err != nil {
-		level = logrus.InfoLevel
+	if strings.ToLower(config.Backend) == "zap" {
+		return NewZap(config)
 	}
-	logger.SetLevel(level)
+
+	logger := logrus.New()
+	// 级别由 loggerImpl 过滤，便于运行时修改
+	logger.SetLevel(logrus.DebugLevel)
 
 	// 设置日志格式
 	switch strings.ToLower(config.Format) {
@@ -96,20 +109,36 @@ func New(config Config) Logger {
 		})
 	}
 
-	// 设置输出
+	output, err := openOutput(config)
+	logger.SetOutput(output)
+	if err != nil {
+		logger.WithError(err).Error("创建日志目录失败")
+	}
+
+	// 创建基础entry，包含服务信息
+	entry := logger.WithFields(logrus.Fields{
+		"service":     config.Service,
+		"version":     config.Version,
+		"environment": config.Environment,
+		"hostname":    getHostname(),
+	})
+
+	return newLogger(logrusBackend{entry: entry}, config, entry.Warn)
+}
+
+// openOutput 根据配置打开日志输出
+func openOutput(config Config) (io.Writer, error) {
 	switch strings.ToLower(config.Output) {
-	case "file":
+	case "file", "both":
 		// 如果没有指定文件名，使用默认路径（相对于当前服务目录）
 		filename := config.File.Filename
 		if filename == "" {
 			filename = fmt.Sprintf("./logs/%s.log", config.Service)
 		}
-		
+
 		// 确保日志目录存在
 		dir := filepath.Dir(filename)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			logger.WithError(err).Error("创建日志目录失败")
-		}
+		err := os.MkdirAll(dir, 0755)
 
 		fileWriter := &lumberjack.Logger{
 			Filename:   filename,
@@ -118,172 +147,313 @@ func New(config Config) Logger {
 			MaxAge:     config.File.MaxAge,
 			Compress:   config.File.Compress,
 		}
-		logger.SetOutput(fileWriter)
-	case "both":
-		// 如果没有指定文件名，使用默认路径（相对于当前服务目录）
-		filename := config.File.Filename
-		if filename == "" {
-			filename = fmt.Sprintf("./logs/%s.log", config.Service)
+		if strings.ToLower(config.Output) == "both" {
+			// 同时输出到文件和控制台
+			return io.MultiWriter(os.Stdout, fileWriter), err
 		}
-		
-		// 同时输出到文件和控制台
-		dir := filepath.Dir(filename)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			logger.WithError(err).Error("创建日志目录失败")
-		}
-
-		fileWriter := &lumberjack.Logger{
-			Filename:   filename,
-			MaxSize:    config.File.MaxSize,
-			MaxBackups: config.File.MaxBackups,
-			MaxAge:     config.File.MaxAge,
-			Compress:   config.File.Compress,
-		}
-		logger.SetOutput(io.MultiWriter(os.Stdout, fileWriter))
+		return fileWriter, err
 	default:
-		logger.SetOutput(os.Stdout)
+		return os.Stdout, nil
 	}
+}
 
-	// 添加调用信息
-	logger.SetReportCaller(true)
+// backend 日志后端
+type backend interface {
+	// write 输出一条日志，fields 已经过脱敏
+	write(level Level, msg string, caller runtime.Frame, fields []Field)
+	// with 返回绑定了字段的后端
+	with(fields []Field) backend
+}
 
-	// 创建基础entry，包含服务信息
-	entry := logger.WithFields(logrus.Fields{
-		"service":     config.Service,
-		"version":     config.Version,
-		"environment": config.Environment,
-		"hostname":    getHostname(),
-	})
+// core 同一 Logger 派生出的实例共享的级别、脱敏、采样和限速状态
+type core struct {
+	level    atomic.Int32
+	redactor *Redactor
+	sampler  *sampler
+	limiter  *limiter
+}
 
-	return &loggerImpl{
-		logger: logger,
-		entry:  entry,
-		config: config,
+// loggerImpl 日志实现，脱敏、采样和级别过滤在此完成，输出交给后端
+type loggerImpl struct {
+	backend backend
+	core    *core
+	config  Config
+	every   time.Duration
+}
+
+// newLogger 创建日志实例，配置错误通过 warn 报告
+func newLogger(b backend, config Config, warn func(args ...interface{})) *loggerImpl {
+	c := &core{sampler: newSampler(config.Sampling), limiter: newLimiter()}
+
+	level, err := ParseLevel(config.Level)
+	if err != nil {
+		warn(err)
+	}
+	c.level.Store(int32(level))
+
+	c.redactor, err = newConfigRedactor(config.Redaction)
+	if err != nil {
+		warn(err)
+	}
+
+	return &loggerImpl{backend: b, core: c, config: config}
+}
+
+func (l *loggerImpl) derive(b backend) *loggerImpl {
+	return &loggerImpl{backend: b, core: l.core, config: l.config, every: l.every}
+}
+
+func (l *loggerImpl) enabled(level Level) bool {
+	return level >= Level(l.core.level.Load())
+}
+
+// log 过滤、采样和脱敏后输出；key 为采样和限速使用的消息标识，格式化日志使用格式串
+func (l *loggerImpl) log(level Level, key, msg string, fields []Field) {
+	if !l.enabled(level) {
+		return
+	}
+	if level < FatalLevel && l.core.sampler != nil && !l.core.sampler.allow(level, key) {
+		return
+	}
+	if l.every > 0 {
+		ok, suppressed := l.core.limiter.allow(level, key, l.every)
+		if !ok {
+			return
+		}
+		if suppressed > 0 {
+			fields = append(fields, Uint64("suppressed", suppressed))
+		}
+	}
+	l.backend.write(level, msg, callerFrame(), l.core.redactor.Redact(fields))
+}
+
+func (l *loggerImpl) print(level Level, args []interface{}) {
+	if l.enabled(level) {
+		msg := fmt.Sprint(args...)
+		l.log(level, msg, msg, nil)
+	}
+}
+
+func (l *loggerImpl) printf(level Level, format string, args []interface{}) {
+	if l.enabled(level) {
+		l.log(level, format, fmt.Sprintf(format, args...), nil)
 	}
 }
 
 // Debug 调试日志
 func (l *loggerImpl) Debug(args ...interface{}) {
-	l.entry.Debug(args...)
+	l.print(DebugLevel, args)
 }
 
 // Debugf 格式化调试日志
 func (l *loggerImpl) Debugf(format string, args ...interface{}) {
-	l.entry.Debugf(format, args...)
+	l.printf(DebugLevel, format, args)
 }
 
 // Info 信息日志
 func (l *loggerImpl) Info(args ...interface{}) {
-	l.entry.Info(args...)
+	l.print(InfoLevel, args)
 }
 
 // Infof 格式化信息日志
 func (l *loggerImpl) Infof(format string, args ...interface{}) {
-	l.entry.Infof(format, args...)
+	l.printf(InfoLevel, format, args)
 }
 
 // Warn 警告日志
 func (l *loggerImpl) Warn(args ...interface{}) {
-	l.entry.Warn(args...)
+	l.print(WarnLevel, args)
 }
 
 // Warnf 格式化警告日志
 func (l *loggerImpl) Warnf(format string, args ...interface{}) {
-	l.entry.Warnf(format, args...)
+	l.printf(WarnLevel, format, args)
 }
 
 // Error 错误日志
 func (l *loggerImpl) Error(args ...interface{}) {
-	l.entry.Error(args...)
+	l.print(ErrorLevel, args)
 }
 
 // Errorf 格式化错误日志
 func (l *loggerImpl) Errorf(format string, args ...interface{}) {
-	l.entry.Errorf(format, args...)
+	l.printf(ErrorLevel, format, args)
 }
 
 // Fatal 致命错误日志
 func (l *loggerImpl) Fatal(args ...interface{}) {
-	l.entry.Fatal(args...)
+	l.print(FatalLevel, args)
 }
 
 // Fatalf 格式化致命错误日志
 func (l *loggerImpl) Fatalf(format string, args ...interface{}) {
-	l.entry.Fatalf(format, args...)
+	l.printf(FatalLevel, format, args)
+}
+
+// Debugw 结构化调试日志
+func (l *loggerImpl) Debugw(msg string, fields ...Field) {
+	l.log(DebugLevel, msg, msg, fields)
+}
+
+// Infow 结构化信息日志
+func (l *loggerImpl) Infow(msg string, fields ...Field) {
+	l.log(InfoLevel, msg, msg, fields)
+}
+
+// Warnw 结构化警告日志
+func (l *loggerImpl) Warnw(msg string, fields ...Field) {
+	l.log(WarnLevel, msg, msg, fields)
+}
+
+// Errorw 结构化错误日志
+func (l *loggerImpl) Errorw(msg string, fields ...Field) {
+	l.log(ErrorLevel, msg, msg, fields)
+}
+
+// With 添加结构化字段
+func (l *loggerImpl) With(fields ...Field) Logger {
+	return l.derive(l.backend.with(l.core.redactor.Redact(fields)))
 }
 
 // WithField 添加字段
 func (l *loggerImpl) WithField(key string, value interface{}) Logger {
-	return &loggerImpl{
-		logger: l.logger,
-		entry:  l.entry.WithField(key, value),
-		config: l.config,
-	}
+	return l.With(Field{Key: key, Value: value})
 }
 
 // WithFields 添加多个字段
 func (l *loggerImpl) WithFields(fields map[string]interface{}) Logger {
-	return &loggerImpl{
-		logger: l.logger,
-		entry:  l.entry.WithFields(fields),
-		config: l.config,
-	}
+	return l.With(fieldsFromMap(fields)...)
 }
 
 // WithContext 添加上下文信息
 func (l *loggerImpl) WithContext(ctx context.Context) Logger {
-	entry := l.entry.WithContext(ctx)
+	var fields []Field
 
 	// 从上下文中提取常用字段
 	if requestID := ctx.Value("request_id"); requestID != nil {
-		entry = entry.WithField("request_id", requestID)
+		fields = append(fields, Any("request_id", requestID))
 	}
 	if userID := ctx.Value("user_id"); userID != nil {
-		entry = entry.WithField("user_id", userID)
-	}
-	if traceID := ctx.Value("trace_id"); traceID != nil {
-		entry = entry.WithField("trace_id", traceID)
-	}
-	if spanID := ctx.Value("span_id"); spanID != nil {
-		entry = entry.WithField("span_id", spanID)
+		fields = append(fields, Any("user_id", userID))
 	}
 
-	return &loggerImpl{
-		logger: l.logger,
-		entry:  entry,
-		config: l.config,
+	// 优先使用追踪上下文中的 trace/span ID
+	if sc := tracing.SpanContextFromContext(ctx); sc != nil && sc.TraceID() != "" {
+		fields = append(fields, String("trace_id", sc.TraceID()), String("span_id", sc.SpanID()))
+	} else {
+		if traceID := ctx.Value("trace_id"); traceID != nil {
+			fields = append(fields, Any("trace_id", traceID))
+		}
+		if spanID := ctx.Value("span_id"); spanID != nil {
+			fields = append(fields, Any("span_id", spanID))
+		}
 	}
+
+	return l.With(fields...)
 }
 
 // WithError 添加错误信息
 func (l *loggerImpl) WithError(err error) Logger {
-	entry := l.entry.WithError(err)
+	fields := []Field{Err(err)}
 
 	// 添加错误堆栈信息
 	if pc, file, line, ok := runtime.Caller(1); ok {
-		entry = entry.WithFields(logrus.Fields{
-			"error_file": file,
-			"error_line": line,
-			"error_func": runtime.FuncForPC(pc).Name(),
-		})
+		fields = append(fields,
+			String("error_file", file),
+			Int("error_line", line),
+			String("error_func", runtime.FuncForPC(pc).Name()),
+		)
 	}
 
 	// 获取调用信息
 	if pc, file, line, ok := runtime.Caller(2); ok {
-		entry = entry.WithFields(logrus.Fields{
-			"caller_file": file,
-			"caller_line": line,
-			"caller_func": runtime.FuncForPC(pc).Name(),
-		})
+		fields = append(fields,
+			String("caller_file", file),
+			Int("caller_line", line),
+			String("caller_func", runtime.FuncForPC(pc).Name()),
+		)
+	}
+
+	return l.With(fields...)
+}
+
+// Every 返回限速的日志实例
+func (l *loggerImpl) Every(interval time.Duration) Logger {
+	derived := l.derive(l.backend)
+	derived.every = interval
+	return derived
+}
+
+// SetLevel 运行时修改日志级别
+func (l *loggerImpl) SetLevel(level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
 	}
+	l.core.level.Store(int32(parsed))
+	return nil
+}
+
+// GetLevel 返回当前日志级别
+func (l *loggerImpl) GetLevel() string {
+	return Level(l.core.level.Load()).String()
+}
 
-	return &loggerImpl{
-		logger: l.logger,
-		entry:  entry,
-		config: l.config,
+// loggerPackage 本包函数名前缀，用于跳过包内调用帧
+const loggerPackage = "github.com/codetaoist/laojun-shared/logger."
+
+// callerFrame 返回本包之外的第一个调用帧
+func callerFrame() runtime.Frame {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, loggerPackage) || strings.HasPrefix(frame.Function, loggerPackage+"Test") {
+			return frame
+		}
+		if !more {
+			return runtime.Frame{}
+		}
 	}
 }
 
+// formatCaller 格式化为 "目录/文件:行号"
+func formatCaller(frame runtime.Frame) string {
+	if frame.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File), frame.Line)
+}
+
+// logrusBackend logrus 后端
+type logrusBackend struct {
+	entry *logrus.Entry
+}
+
+func (b logrusBackend) write(level Level, msg string, caller runtime.Frame, fields []Field) {
+	data := make(logrus.Fields, len(fields)+1)
+	for _, field := range fields {
+		data[field.Key] = field.Value
+	}
+	if c := formatCaller(caller); c != "" {
+		data["caller"] = c
+	}
+	entry := b.entry.WithFields(data)
+	entry.Log(level.logrus(), msg)
+	if level == FatalLevel {
+		entry.Logger.Exit(1)
+	}
+}
+
+func (b logrusBackend) with(fields []Field) backend {
+	data := make(logrus.Fields, len(fields))
+	for _, field := range fields {
+		data[field.Key] = field.Value
+	}
+	return logrusBackend{entry: b.entry.WithFields(data)}
+}
+
 // CustomFormatter 自定义格式化日志
 type CustomFormatter struct{}
 
@@ -292,15 +462,14 @@ func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	timestamp := entry.Time.Format(time.RFC3339)
 
 	// 获取调用信息
-	caller := ""
-	if entry.HasCaller() {
-		caller = fmt.Sprintf("%s:%d", filepath.Base(entry.Caller.File), entry.Caller.Line)
-	}
+	caller, _ := entry.Data["caller"].(string)
 
 	// 构建字段字符串
 	fields := ""
 	for k, v := range entry.Data {
-		fields += fmt.Sprintf(" %s=%v", k, v)
+		if k != "caller" {
+			fields += fmt.Sprintf(" %s=%v", k, v)
+		}
 	}
 
 	log := fmt.Sprintf("[%s] %s %s %s%s\n",
@@ -389,3 +558,23 @@ func WithContext(ctx context.Context) Logger {
 func WithError(err error) Logger {
 	return DefaultLogger.WithError(err)
 }
+
+func Debugw(msg string, fields ...Field) {
+	DefaultLogger.Debugw(msg, fields...)
+}
+
+func Infow(msg string, fields ...Field) {
+	DefaultLogger.Infow(msg, fields...)
+}
+
+func Warnw(msg string, fields ...Field) {
+	DefaultLogger.Warnw(msg, fields...)
+}
+
+func Errorw(msg string, fields ...Field) {
+	DefaultLogger.Errorw(msg, fields...)
+}
+
+func With(fields ...Field) Logger {
+	return DefaultLogger.With(fields...)
+}
@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/tracing"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newTestLogger 创建输出到缓冲区的 logrus 后端日志
+func newTestLogger(t *testing.T, config Config) (Logger, *bytes.Buffer) {
+	t.Helper()
+	config.Format = "json"
+	l := New(config).(*loggerImpl)
+	var buf bytes.Buffer
+	l.backend.(logrusBackend).entry.Logger.SetOutput(&buf)
+	return l, &buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestStructuredFieldsAndRedaction(t *testing.T) {
+	log, buf := newTestLogger(t, Config{
+		Level:   "info",
+		Service: "test",
+		Redaction: RedactionConfig{Rules: []RedactionRule{
+			{Pattern: `^email$`, Mode: RedactRemove},
+		}},
+	})
+
+	log.With(String("password", "hunter2")).Infow("user login",
+		String("user", "alice"),
+		Int("attempt", 2),
+		Duration("latency", 1500*time.Millisecond),
+		String("card_number", "4111 1111 1111 1234"),
+		String("email", "alice@example.com"),
+		Any("headers", map[string]interface{}{"Authorization": "Bearer abc", "Accept": "*/*"}),
+	)
+	log.WithField("access_token", "abc").Info("plain")
+	log.Debugw("hidden")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %s", len(lines), buf.String())
+	}
+	entry := lines[0]
+	if entry["message"] != "user login" || entry["user"] != "alice" || entry["attempt"] != float64(2) || entry["latency"] != "1.5s" {
+		t.Errorf("fields = %v", entry)
+	}
+	if entry["password"] != redactedValue || entry["card_number"] != "************1234" {
+		t.Errorf("redaction = password %v, card %v", entry["password"], entry["card_number"])
+	}
+	if _, ok := entry["email"]; ok {
+		t.Error("email should be removed by custom rule")
+	}
+	headers := entry["headers"].(map[string]interface{})
+	if headers["Authorization"] != redactedValue || headers["Accept"] != "*/*" {
+		t.Errorf("nested redaction = %v", headers)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "logger/logger_test.go:") {
+		t.Errorf("caller = %q", caller)
+	}
+	if lines[1]["access_token"] != redactedValue {
+		t.Errorf("WithField redaction = %v", lines[1]["access_token"])
+	}
+}
+
+func TestWithContextTraceIDs(t *testing.T) {
+	log, buf := newTestLogger(t, Config{Level: "info"})
+
+	ctx := tracing.TraceContextPropagator{}.Extract(context.Background(), tracing.MapCarrier{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	log.WithContext(ctx).Info("traced")
+	log.WithContext(context.WithValue(context.Background(), "trace_id", "legacy")).Info("legacy")
+
+	lines := decodeLines(t, buf)
+	if lines[0]["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || lines[0]["span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("trace fields = %v", lines[0])
+	}
+	if lines[1]["trace_id"] != "legacy" {
+		t.Errorf("legacy trace_id = %v", lines[1]["trace_id"])
+	}
+}
+
+func TestSamplingAndRateLimit(t *testing.T) {
+	log, buf := newTestLogger(t, Config{
+		Level:    "info",
+		Sampling: SamplingConfig{Initial: 2, Thereafter: 5, Tick: time.Minute},
+	})
+	for i := 0; i < 12; i++ {
+		log.Infof("request %d", i)
+	}
+	log.Info("other message")
+	// 12 条同模板消息：前 2 条 + 第 7、12 条
+	if n := len(decodeLines(t, buf)); n != 5 {
+		t.Errorf("sampled lines = %d, want 5", n)
+	}
+
+	limited, limitedBuf := newTestLogger(t, Config{Level: "info"})
+	for i := 0; i < 100; i++ {
+		limited.Every(50*time.Millisecond).Warnw("queue full", Int("i", i))
+	}
+	time.Sleep(60 * time.Millisecond)
+	limited.Every(50 * time.Millisecond).Warnw("queue full")
+
+	lines := decodeLines(t, limitedBuf)
+	if len(lines) != 2 || lines[1]["suppressed"] != float64(99) {
+		t.Errorf("rate limited lines = %v", lines)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	log, buf := newTestLogger(t, Config{Level: "warn"})
+	child := log.WithField("component", "worker")
+	handler := LevelHandler(log)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/log/level", nil))
+	if !strings.Contains(rec.Body.String(), `"warn"`) {
+		t.Errorf("GET = %s", rec.Body.String())
+	}
+
+	child.Info("before")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log/level", strings.NewReader(`{"level":"debug"}`)))
+	if rec.Code != http.StatusOK || log.GetLevel() != "debug" {
+		t.Fatalf("PUT = %d %s", rec.Code, rec.Body.String())
+	}
+	child.Debug("after")
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 1 || lines[0]["message"] != "after" {
+		t.Errorf("lines = %v", lines)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/log/level?level=loud", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid level status = %d", rec.Code)
+	}
+}
+
+func TestZapBackend(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "message", LevelKey: "level", CallerKey: "caller", EncodeLevel: zapcore.LowercaseLevelEncoder, EncodeCaller: zapcore.ShortCallerEncoder})
+	log := FromZap(zap.New(zapcore.NewCore(encoder, zapcore.AddSync(&buf), zapcore.InfoLevel)))
+
+	if log.GetLevel() != "info" {
+		t.Errorf("GetLevel() = %q", log.GetLevel())
+	}
+	log.With(String("secret", "s3cr3t")).Infow("zap", Bool("ok", true))
+	log.Debug("hidden")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 1 || lines[0]["secret"] != redactedValue || lines[0]["ok"] != true {
+		t.Fatalf("lines = %v", lines)
+	}
+	if caller, _ := lines[0]["caller"].(string); !strings.HasPrefix(caller, "logger/logger_test.go:") {
+		t.Errorf("caller = %q", caller)
+	}
+}
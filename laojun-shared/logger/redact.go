@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 脱敏方式
+const (
+	RedactMask   = "mask"   // 替换为 [REDACTED]
+	RedactRemove = "remove" // 删除字段
+	RedactLast4  = "last4"  // 仅保留最后4位，适用于卡号、手机号
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "[REDACTED]"
+
+// RedactionRule 字段脱敏规则，Pattern 为匹配字段名的正则（不区分大小写）
+type RedactionRule struct {
+	Pattern string `yaml:"pattern"`
+	Mode    string `yaml:"mode"`
+}
+
+// RedactionConfig 脱敏配置
+type RedactionConfig struct {
+	// Disabled 关闭脱敏，包括默认规则
+	Disabled bool `yaml:"disabled" env:"LOG_REDACTION_DISABLED" config:"log.redaction.disabled" default:"false"`
+	// Rules 自定义规则，优先于默认规则匹配
+	Rules []RedactionRule `yaml:"rules"`
+}
+
+// DefaultRedactionRules 默认脱敏规则：密码、密钥、令牌和卡号
+var DefaultRedactionRules = []RedactionRule{
+	{Pattern: `(card|pan|cc)[_-]?(number|no|num)$|credit[_-]?card`, Mode: RedactLast4},
+	{Pattern: `password|passwd|(^|[_-])pass$|secret|token|authorization|api[_-]?key|cookie|private[_-]?key|credential`, Mode: RedactMask},
+}
+
+type redactionRule struct {
+	pattern *regexp.Regexp
+	mode    string
+}
+
+// Redactor 按字段名脱敏
+type Redactor struct {
+	rules []redactionRule
+}
+
+// NewRedactor 创建脱敏器，规则按顺序匹配，第一个匹配的规则生效
+func NewRedactor(rules []RedactionRule) (*Redactor, error) {
+	r := &Redactor{}
+	for _, rule := range rules {
+		pattern, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", rule.Pattern, err)
+		}
+		mode := strings.ToLower(rule.Mode)
+		switch mode {
+		case "":
+			mode = RedactMask
+		case RedactMask, RedactRemove, RedactLast4:
+		default:
+			return nil, fmt.Errorf("invalid redaction mode %q", rule.Mode)
+		}
+		r.rules = append(r.rules, redactionRule{pattern: pattern, mode: mode})
+	}
+	return r, nil
+}
+
+// newConfigRedactor 根据配置创建脱敏器，自定义规则无效时退回默认规则
+func newConfigRedactor(config RedactionConfig) (*Redactor, error) {
+	if config.Disabled {
+		return &Redactor{}, nil
+	}
+	rules := append(append([]RedactionRule{}, config.Rules...), DefaultRedactionRules...)
+	redactor, err := NewRedactor(rules)
+	if err != nil {
+		fallback, _ := NewRedactor(DefaultRedactionRules)
+		return fallback, err
+	}
+	return redactor, nil
+}
+
+// Redact 返回脱敏后的字段副本
+func (r *Redactor) Redact(fields []Field) []Field {
+	if r == nil || len(r.rules) == 0 || len(fields) == 0 {
+		return fields
+	}
+	result := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		if value, keep := r.redactValue(field.Key, field.Value); keep {
+			result = append(result, Field{Key: field.Key, Value: value})
+		}
+	}
+	return result
+}
+
+// redactValue 对单个字段脱敏，嵌套的 map 按键递归处理
+func (r *Redactor) redactValue(key string, value interface{}) (interface{}, bool) {
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(key) {
+			continue
+		}
+		switch rule.mode {
+		case RedactRemove:
+			return nil, false
+		case RedactLast4:
+			return lastFour(value), true
+		default:
+			return redactedValue, true
+		}
+	}
+
+	switch nested := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(nested))
+		for k, v := range nested {
+			if redacted, keep := r.redactValue(k, v); keep {
+				result[k] = redacted
+			}
+		}
+		return result, true
+	case map[string]string:
+		result := make(map[string]string, len(nested))
+		for k, v := range nested {
+			if redacted, keep := r.redactValue(k, v); keep {
+				result[k] = fmt.Sprint(redacted)
+			}
+		}
+		return result, true
+	}
+	return value, true
+}
+
+func lastFour(value interface{}) string {
+	var digits []rune
+	for _, c := range fmt.Sprint(value) {
+		if c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits) <= 4 {
+		return strings.Repeat("*", len(digits))
+	}
+	return strings.Repeat("*", len(digits)-4) + string(digits[len(digits)-4:])
+}
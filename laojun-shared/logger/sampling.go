@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingConfig 按消息采样：每个 Tick 周期内同一级别、同一消息的前 Initial 条全部输出，
+// 之后每 Thereafter 条输出一条（为 0 时丢弃）。Initial 为 0 时不采样。Fatal 日志不参与采样。
+type SamplingConfig struct {
+	Initial    int           `yaml:"initial" env:"LOG_SAMPLING_INITIAL" config:"log.sampling.initial" default:"0"`
+	Thereafter int           `yaml:"thereafter" env:"LOG_SAMPLING_THEREAFTER" config:"log.sampling.thereafter" default:"100"`
+	Tick       time.Duration `yaml:"tick" env:"LOG_SAMPLING_TICK" config:"log.sampling.tick" default:"1s"`
+}
+
+// samplerBuckets 计数器槽位数，消息按哈希分配槽位，冲突的消息共享计数
+const samplerBuckets = 4096
+
+type sampler struct {
+	tick       int64
+	initial    uint64
+	thereafter uint64
+	counters   [samplerBuckets]samplerCounter
+}
+
+type samplerCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func newSampler(config SamplingConfig) *sampler {
+	if config.Initial <= 0 {
+		return nil
+	}
+	tick := config.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	thereafter := config.Thereafter
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	return &sampler{tick: int64(tick), initial: uint64(config.Initial), thereafter: uint64(thereafter)}
+}
+
+// allow 判断本条日志是否输出
+func (s *sampler) allow(level Level, key string) bool {
+	h := fnv.New32a()
+	h.Write([]byte{byte(level)})
+	h.Write([]byte(key))
+	counter := &s.counters[h.Sum32()%samplerBuckets]
+
+	now := time.Now().UnixNano()
+	var n uint64
+	if resetAt := counter.resetAt.Load(); resetAt > now {
+		n = counter.count.Add(1)
+	} else if counter.resetAt.CompareAndSwap(resetAt, now+s.tick) {
+		counter.count.Store(1)
+		n = 1
+	} else {
+		n = counter.count.Add(1)
+	}
+
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
+
+// limiterMaxEntries 限速记录上限，超过后清空，避免消息内容不固定时无限增长
+const limiterMaxEntries = 10000
+
+// limiter 为 Logger.Every 记录每条消息上次输出的时间和其间被抑制的次数
+type limiter struct {
+	mu      sync.Mutex
+	entries map[string]*limitEntry
+}
+
+type limitEntry struct {
+	last       time.Time
+	suppressed uint64
+}
+
+func newLimiter() *limiter {
+	return &limiter{entries: make(map[string]*limitEntry)}
+}
+
+// allow 判断本条日志是否输出，输出时返回自上次输出以来被抑制的条数
+func (l *limiter) allow(level Level, key string, interval time.Duration) (bool, uint64) {
+	key = level.String() + "|" + key
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.entries[key]
+	if !ok {
+		if len(l.entries) >= limiterMaxEntries {
+			l.entries = make(map[string]*limitEntry)
+		}
+		l.entries[key] = &limitEntry{last: now}
+		return true, 0
+	}
+	if now.Sub(entry.last) < interval {
+		entry.suppressed++
+		return false, 0
+	}
+	suppressed := entry.suppressed
+	entry.last = now
+	entry.suppressed = 0
+	return true, suppressed
+}
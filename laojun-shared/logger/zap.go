@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewZap 创建 zap 后端的日志实例，格式和输出配置与 logrus 后端一致
+func NewZap(config Config) Logger {
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		LevelKey:       "level",
+		MessageKey:     "message",
+		CallerKey:      "caller",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.TimeEncoderOfLayout(time.RFC3339),
+		EncodeDuration: zapcore.StringDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+
+	var encoder zapcore.Encoder
+	switch strings.ToLower(config.Format) {
+	case "text", "custom":
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	output, outputErr := openOutput(config)
+	// 级别由 loggerImpl 过滤，便于运行时修改
+	zapLogger := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(output), zapcore.DebugLevel)).With(
+		zap.String("service", config.Service),
+		zap.String("version", config.Version),
+		zap.String("environment", config.Environment),
+		zap.String("hostname", getHostname()),
+	)
+
+	warn := func(args ...interface{}) { zapLogger.Sugar().Warn(args...) }
+	if outputErr != nil {
+		zapLogger.Error("创建日志目录失败", zap.Error(outputErr))
+	}
+	return newLogger(zapBackend{logger: zapLogger}, config, warn)
+}
+
+// FromZap 包装已有的 zap.Logger，供已经使用 zap 的服务接入统一的日志接口。
+// 初始级别取 zap 已启用的最低级别，默认脱敏规则生效，不采样。
+func FromZap(zapLogger *zap.Logger) Logger {
+	level := FatalLevel
+	for _, l := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		if zapLogger.Core().Enabled(l.zap()) {
+			level = l
+			break
+		}
+	}
+	warn := func(args ...interface{}) { zapLogger.Sugar().Warn(args...) }
+	return newLogger(zapBackend{logger: zapLogger}, Config{Level: level.String()}, warn)
+}
+
+// zapBackend zap 后端
+type zapBackend struct {
+	logger *zap.Logger
+}
+
+func (b zapBackend) write(level Level, msg string, caller runtime.Frame, fields []Field) {
+	ce := b.logger.Check(level.zap(), msg)
+	if ce == nil {
+		return
+	}
+	if caller.File != "" {
+		ce.Entry.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       caller.PC,
+			File:     caller.File,
+			Line:     caller.Line,
+			Function: caller.Function,
+		}
+	}
+	ce.Write(zapFields(fields)...)
+}
+
+func (b zapBackend) with(fields []Field) backend {
+	return zapBackend{logger: b.logger.With(zapFields(fields)...)}
+}
+
+func zapFields(fields []Field) []zap.Field {
+	result := make([]zap.Field, len(fields))
+	for i, field := range fields {
+		result[i] = zap.Any(field.Key, field.Value)
+	}
+	return result
+}
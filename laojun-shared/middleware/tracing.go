@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/codetaoist/laojun-shared/tracing"
+)
+
+// TracingConfig 链路追踪中间件配置
+type TracingConfig struct {
+	// Tracer 为空时使用全局 tracer，仍未设置则只透传上下文
+	Tracer tracing.Tracer
+	// Propagator 为空时使用 tracer 配置的传播格式
+	Propagator tracing.Propagator
+	SkipPaths  []string `yaml:"skip_paths" env:"TRACING_SKIP_PATHS" config:"tracing.skip_paths"`
+	// ExposeTraceID 在响应头 X-Trace-ID 中返回 trace ID
+	ExposeTraceID bool `yaml:"expose_trace_id" env:"TRACING_EXPOSE_TRACE_ID" config:"tracing.expose_trace_id" default:"false"`
+}
+
+// TracingMiddleware 链路追踪中间件 - 提取上游追踪上下文并创建服务端 span
+func TracingMiddleware(config TracingConfig) gin.HandlerFunc {
+	skipPaths := make(map[string]bool)
+	for _, path := range config.SkipPaths {
+		skipPaths[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skipPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		tracer := config.Tracer
+		if tracer == nil {
+			tracer = tracing.GlobalTracer()
+		}
+		propagator := config.Propagator
+		if propagator == nil {
+			propagator = tracing.PropagatorFor(tracer)
+		}
+
+		// 提取上游上下文，无 tracer 时也保证下游请求继续传播
+		ctx := propagator.Extract(c.Request.Context(), tracing.HeaderCarrier(c.Request.Header))
+		if tracer == nil {
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		span, ctx := tracer.StartSpan(ctx, c.Request.Method+" "+route,
+			tracing.WithSpanKind(tracing.SpanKindServer),
+			tracing.WithTag("http.method", c.Request.Method),
+			tracing.WithTag("http.route", route),
+			tracing.WithTag("http.target", c.Request.URL.Path),
+		)
+		c.Request = c.Request.WithContext(ctx)
+		if span == nil {
+			c.Next()
+			return
+		}
+
+		if config.ExposeTraceID {
+			c.Header("X-Trace-ID", span.TraceID())
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetTag("http.status_code", status)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(tracing.SpanStatusError, fmt.Sprintf("HTTP %d", status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last().Err)
+		}
+		span.Finish()
+	}
+}
@@ -0,0 +1,303 @@
+package monitoring
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PrometheusContentType is the content type of the Prometheus text format.
+	PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	// OpenMetricsContentType is the content type of the OpenMetrics text format.
+	OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+	// DroppedSeriesMetric counts observations dropped by the cardinality limit,
+	// labeled by metric name.
+	DroppedSeriesMetric = "monitoring_dropped_series_total"
+)
+
+// Handler returns an http.Handler serving the metrics in the Prometheus text
+// format, or in OpenMetrics with exemplars when the scraper accepts it.
+func (m *MonitorImpl) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.started {
+			http.Error(w, ErrMonitorNotInitialized.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		format, contentType := ExportFormatPrometheus, PrometheusContentType
+		if acceptsOpenMetrics(r.Header.Get("Accept")) {
+			format, contentType = ExportFormatOpenMetrics, OpenMetricsContentType
+		}
+
+		var buf bytes.Buffer
+		if err := m.writeExposition(&buf, format); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(buf.Bytes())
+	})
+}
+
+// acceptsOpenMetrics reports whether an Accept header allows OpenMetrics.
+func acceptsOpenMetrics(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		params := strings.Split(mediaRange, ";")
+		if strings.TrimSpace(params[0]) != "application/openmetrics-text" {
+			continue
+		}
+		accepted := true
+		for _, param := range params[1:] {
+			if key, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && key == "q" {
+				q, err := strconv.ParseFloat(value, 64)
+				accepted = err == nil && q > 0
+			}
+		}
+		return accepted
+	}
+	return false
+}
+
+type family struct {
+	name       string
+	metricType MetricType
+	series     []*metricImpl
+}
+
+// families groups the series by metric name, sorted by name and labels.
+func (m *MonitorImpl) families() []*family {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byName := make(map[string]*family)
+	keys := make(map[*metricImpl]string, len(m.metrics))
+	for key, metric := range m.metrics {
+		f, ok := byName[metric.name]
+		if !ok {
+			f = &family{name: metric.name, metricType: metric.metricType}
+			byName[metric.name] = f
+		}
+		f.series = append(f.series, metric)
+		keys[metric] = key
+	}
+
+	families := make([]*family, 0, len(byName))
+	for _, f := range byName {
+		sort.Slice(f.series, func(i, j int) bool { return keys[f.series[i]] < keys[f.series[j]] })
+		families = append(families, f)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	if len(m.dropped) > 0 {
+		dropped := &family{name: DroppedSeriesMetric, metricType: MetricTypeCounter}
+		for name, count := range m.dropped {
+			dropped.series = append(dropped.series, &metricImpl{
+				name:       DroppedSeriesMetric,
+				metricType: MetricTypeCounter,
+				value:      float64(count),
+				labels:     map[string]string{"metric": name},
+			})
+		}
+		sort.Slice(dropped.series, func(i, j int) bool {
+			return dropped.series[i].labels["metric"] < dropped.series[j].labels["metric"]
+		})
+		families = append(families, dropped)
+	}
+	return families
+}
+
+// writeExposition writes all metrics in the Prometheus text format or, for
+// ExportFormatOpenMetrics, in OpenMetrics including exemplars.
+func (m *MonitorImpl) writeExposition(w io.Writer, format ExportFormat) error {
+	openMetrics := format == ExportFormatOpenMetrics
+	bw := bufio.NewWriter(w)
+
+	for _, f := range m.families() {
+		name := sanitizeName(f.name)
+		if openMetrics && f.metricType == MetricTypeCounter {
+			name = strings.TrimSuffix(name, "_total")
+		}
+		if help, ok := MetricHelp[f.name]; ok {
+			bw.WriteString("# HELP " + name + " " + escapeHelp(help, openMetrics) + "\n")
+		}
+		bw.WriteString("# TYPE " + name + " " + f.metricType.String() + "\n")
+		for _, metric := range f.series {
+			writeSeries(bw, name, metric, openMetrics)
+		}
+	}
+
+	if openMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+func writeSeries(w *bufio.Writer, name string, metric *metricImpl, openMetrics bool) {
+	metric.mu.Lock()
+	defer metric.mu.Unlock()
+
+	sample := func(suffix string, value string, exemplar *Exemplar, extra ...string) {
+		w.WriteString(name + suffix)
+		writeLabels(w, metric.labels, extra...)
+		w.WriteString(" " + value)
+		if openMetrics && exemplar != nil {
+			w.WriteString(" # ")
+			writeLabels(w, exemplar.Labels)
+			w.WriteString(" " + formatFloat(exemplar.Value))
+			w.WriteString(" " + strconv.FormatFloat(float64(exemplar.Timestamp.UnixMilli())/1000, 'f', 3, 64))
+		}
+		w.WriteString("\n")
+	}
+
+	switch metric.metricType {
+	case MetricTypeCounter:
+		value, ok := numeric(metric.value)
+		if !ok {
+			return
+		}
+		suffix := ""
+		if openMetrics {
+			suffix = "_total"
+		}
+		sample(suffix, formatFloat(value), metric.exemplar)
+
+	case MetricTypeGauge:
+		if value, ok := numeric(metric.value); ok {
+			sample("", formatFloat(value), nil)
+		}
+
+	case MetricTypeHistogram:
+		h := metric.histogram
+		var cumulative uint64
+		for i, upperBound := range h.upperBounds {
+			cumulative += h.counts[i]
+			sample("_bucket", strconv.FormatUint(cumulative, 10), h.exemplars[i], "le", formatFloat(upperBound))
+		}
+		sample("_bucket", strconv.FormatUint(h.count, 10), h.exemplars[len(h.upperBounds)], "le", "+Inf")
+		sample("_sum", formatFloat(h.sum), nil)
+		sample("_count", strconv.FormatUint(h.count, 10), nil)
+
+	case MetricTypeSummary:
+		s := metric.summary
+		value := s.value(time.Now())
+		if len(value.Quantiles) == 0 {
+			for _, q := range s.objectives {
+				sample("", "NaN", nil, "quantile", formatFloat(q))
+			}
+		}
+		for _, q := range value.Quantiles {
+			sample("", formatFloat(q.Value), nil, "quantile", formatFloat(q.Quantile))
+		}
+		sample("_sum", formatFloat(value.Sum), nil)
+		sample("_count", strconv.FormatUint(value.Count, 10), nil)
+	}
+}
+
+// writeLabels writes labels sorted by name, followed by the extra name/value
+// pairs such as le or quantile.
+func writeLabels(w *bufio.Writer, labels map[string]string, extra ...string) {
+	if len(labels) == 0 && len(extra) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names)+len(extra)/2)
+	for _, name := range names {
+		pairs = append(pairs, sanitizeName(name)+`="`+escapeLabelValue(labels[name])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabelValue(extra[i+1])+`"`)
+	}
+	w.WriteString("{" + strings.Join(pairs, ",") + "}")
+}
+
+// sanitizeName replaces characters not allowed in metric and label names.
+func sanitizeName(name string) string {
+	valid := func(i int, r rune) bool {
+		return r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9')
+	}
+	for i, r := range name {
+		if !valid(i, r) {
+			return strings.Map(func(r rune) rune {
+				if valid(1, r) {
+					return r
+				}
+				return '_'
+			}, name)
+		}
+	}
+	return name
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// escapeHelp escapes HELP text; OpenMetrics also escapes double quotes.
+func escapeHelp(help string, openMetrics bool) string {
+	if openMetrics {
+		return labelValueEscaper.Replace(help)
+	}
+	return helpEscaper.Replace(help)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// numeric converts registered metric values to float64.
+func numeric(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func toFloat(v interface{}) float64 {
+	f, _ := numeric(v)
+	return f
+}
@@ -2,8 +2,10 @@ package monitoring
 
 import (
 	"context"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"sort"
 	"strings"
 	"sync"
@@ -17,6 +19,13 @@ type MonitorImpl struct {
 	metrics map[string]*metricImpl
 	timers  map[string]*timerImpl
 	started bool
+
+	types     map[string]MetricType // metric name -> type
+	series    map[string]int        // metric name -> number of label sets
+	dropped   map[string]uint64     // metric name -> dropped observations
+	client    *http.Client
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 // metricImpl implements the Metric interface.
@@ -27,6 +36,10 @@ type metricImpl struct {
 	labels    map[string]string
 	timestamp time.Time
 	mu        sync.RWMutex
+
+	histogram *histogram
+	summary   *summary
+	exemplar  *Exemplar
 }
 
 // timerImpl implements the Timer interface.
@@ -44,11 +57,35 @@ func NewMonitor(config Config) (*MonitorImpl, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 	
+	if len(config.SummaryObjectives) == 0 {
+		config.SummaryObjectives = DefaultSummaryObjectives
+	}
+	if config.SummaryMaxAge == 0 {
+		config.SummaryMaxAge = 10 * time.Minute
+	}
+	if config.SummaryAgeBuckets == 0 {
+		config.SummaryAgeBuckets = 5
+	}
+	if config.MaxSeriesPerMetric == 0 {
+		config.MaxSeriesPerMetric = DefaultMaxSeriesPerMetric
+	}
+	if config.PushJob == "" {
+		config.PushJob = config.ServiceName
+	}
+	if config.PushInterval <= 0 {
+		config.PushInterval = 15 * time.Second
+	}
+	
 	impl := &MonitorImpl{
 		config:  config,
 		metrics: make(map[string]*metricImpl),
 		timers:  make(map[string]*timerImpl),
 		started: true,
+		types:   make(map[string]MetricType),
+		series:  make(map[string]int),
+		dropped: make(map[string]uint64),
+		client:  &http.Client{Timeout: config.ExportTimeout},
+		done:    make(chan struct{}),
 	}
 	
 	return impl, nil
@@ -59,171 +96,90 @@ func (m *MonitorImpl) IncrementCounter(ctx context.Context, name string, labels
 	return m.AddCounter(ctx, name, 1, labels)
 }
 
-// AddCounter adds a value to a counter metric.
+// AddCounter adds a value to a counter metric. A sampled trace in ctx is
+// attached to the series as exemplar.
 func (m *MonitorImpl) AddCounter(ctx context.Context, name string, value float64, labels map[string]string) error {
-	if !m.started {
-		return ErrMonitorNotInitialized
-	}
-	
-	if name == "" {
-		return ErrInvalidMetricName
-	}
-	
 	if value < 0 {
 		return ErrInvalidMetricValue
 	}
 	
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	key := m.buildMetricKey(name, labels)
-	metric, exists := m.metrics[key]
+	metric, err := m.getSeries(name, MetricTypeCounter, labels)
+	if metric == nil {
+		return err
+	}
 	
-	if !exists {
-		metric = &metricImpl{
-			name:       name,
-			metricType: MetricTypeCounter,
-			value:      value,
-			labels:     m.mergeLabels(labels),
-			timestamp:  time.Now(),
-		}
-		m.metrics[key] = metric
-	} else {
-		metric.mu.Lock()
-		if currentValue, ok := metric.value.(float64); ok {
-			metric.value = currentValue + value
-		} else {
-			metric.value = value
-		}
-		metric.timestamp = time.Now()
-		metric.mu.Unlock()
+	now := time.Now()
+	metric.mu.Lock()
+	metric.value = toFloat(metric.value) + value
+	metric.timestamp = now
+	if exemplar := exemplarFromContext(ctx, value, now); exemplar != nil {
+		metric.exemplar = exemplar
 	}
+	metric.mu.Unlock()
 	
 	return nil
 }
 
 // SetGauge sets a gauge metric value.
 func (m *MonitorImpl) SetGauge(ctx context.Context, name string, value float64, labels map[string]string) error {
-	if !m.started {
-		return ErrMonitorNotInitialized
+	metric, err := m.getSeries(name, MetricTypeGauge, labels)
+	if metric == nil {
+		return err
 	}
 	
-	if name == "" {
-		return ErrInvalidMetricName
-	}
-	
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	key := m.buildMetricKey(name, labels)
-	metric, exists := m.metrics[key]
-	
-	if !exists {
-		metric = &metricImpl{
-			name:       name,
-			metricType: MetricTypeGauge,
-			value:      value,
-			labels:     m.mergeLabels(labels),
-			timestamp:  time.Now(),
-		}
-		m.metrics[key] = metric
-	} else {
-		metric.mu.Lock()
-		metric.value = value
-		metric.timestamp = time.Now()
-		metric.mu.Unlock()
-	}
+	metric.mu.Lock()
+	metric.value = value
+	metric.timestamp = time.Now()
+	metric.mu.Unlock()
 	
 	return nil
 }
 
 // AddGauge adds a value to a gauge metric.
 func (m *MonitorImpl) AddGauge(ctx context.Context, name string, value float64, labels map[string]string) error {
-	if !m.started {
-		return ErrMonitorNotInitialized
-	}
-	
-	if name == "" {
-		return ErrInvalidMetricName
+	metric, err := m.getSeries(name, MetricTypeGauge, labels)
+	if metric == nil {
+		return err
 	}
 	
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	key := m.buildMetricKey(name, labels)
-	metric, exists := m.metrics[key]
-	
-	if !exists {
-		metric = &metricImpl{
-			name:       name,
-			metricType: MetricTypeGauge,
-			value:      value,
-			labels:     m.mergeLabels(labels),
-			timestamp:  time.Now(),
-		}
-		m.metrics[key] = metric
-	} else {
-		metric.mu.Lock()
-		if currentValue, ok := metric.value.(float64); ok {
-			metric.value = currentValue + value
-		} else {
-			metric.value = value
-		}
-		metric.timestamp = time.Now()
-		metric.mu.Unlock()
-	}
+	metric.mu.Lock()
+	metric.value = toFloat(metric.value) + value
+	metric.timestamp = time.Now()
+	metric.mu.Unlock()
 	
 	return nil
 }
 
-// RecordHistogram records a histogram metric value.
+// RecordHistogram records a histogram observation. Buckets come from
+// Config.Buckets, DefaultMetricBuckets or Config.HistogramBuckets, in that
+// order. A sampled trace in ctx is attached to the bucket as exemplar.
 func (m *MonitorImpl) RecordHistogram(ctx context.Context, name string, value float64, labels map[string]string) error {
-	if !m.started {
-		return ErrMonitorNotInitialized
-	}
-	
-	if name == "" {
-		return ErrInvalidMetricName
+	metric, err := m.getSeries(name, MetricTypeHistogram, labels)
+	if metric == nil {
+		return err
 	}
 	
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	key := m.buildMetricKey(name, labels)
-	metric := &metricImpl{
-		name:       name,
-		metricType: MetricTypeHistogram,
-		value:      value,
-		labels:     m.mergeLabels(labels),
-		timestamp:  time.Now(),
-	}
-	m.metrics[key+"_"+fmt.Sprintf("%d", time.Now().UnixNano())] = metric
+	now := time.Now()
+	metric.mu.Lock()
+	metric.histogram.observe(value, exemplarFromContext(ctx, value, now))
+	metric.timestamp = now
+	metric.mu.Unlock()
 	
 	return nil
 }
 
-// RecordSummary records a summary metric value.
+// RecordSummary records a summary observation.
 func (m *MonitorImpl) RecordSummary(ctx context.Context, name string, value float64, labels map[string]string) error {
-	if !m.started {
-		return ErrMonitorNotInitialized
+	metric, err := m.getSeries(name, MetricTypeSummary, labels)
+	if metric == nil {
+		return err
 	}
 	
-	if name == "" {
-		return ErrInvalidMetricName
-	}
-	
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	
-	key := m.buildMetricKey(name, labels)
-	metric := &metricImpl{
-		name:       name,
-		metricType: MetricTypeSummary,
-		value:      value,
-		labels:     m.mergeLabels(labels),
-		timestamp:  time.Now(),
-	}
-	m.metrics[key+"_"+fmt.Sprintf("%d", time.Now().UnixNano())] = metric
+	now := time.Now()
+	metric.mu.Lock()
+	metric.summary.observe(value, now)
+	metric.timestamp = now
+	metric.mu.Unlock()
 	
 	return nil
 }
@@ -249,7 +205,8 @@ func (m *MonitorImpl) RecordDuration(ctx context.Context, name string, duration
 	return m.RecordHistogram(ctx, name+"_duration_seconds", duration.Seconds(), labels)
 }
 
-// RegisterMetric registers a custom metric.
+// RegisterMetric registers a custom metric. The value of histograms and
+// summaries is recorded as their first observation.
 func (m *MonitorImpl) RegisterMetric(ctx context.Context, metric Metric) error {
 	if !m.started {
 		return ErrMonitorNotInitialized
@@ -267,19 +224,29 @@ func (m *MonitorImpl) RegisterMetric(ctx context.Context, metric Metric) error {
 		return ErrMetricAlreadyExists
 	}
 	
-	impl := &metricImpl{
-		name:       metric.Name(),
-		metricType: metric.Type(),
-		value:      metric.Value(),
-		labels:     metric.Labels(),
-		timestamp:  metric.Timestamp(),
+	impl, err := m.createSeries(key, metric.Name(), metric.Type(), metric.Labels())
+	if impl == nil {
+		return err
+	}
+	
+	impl.timestamp = metric.Timestamp()
+	switch value := metric.Value(); metric.Type() {
+	case MetricTypeHistogram:
+		if v, ok := value.(float64); ok {
+			impl.histogram.observe(v, nil)
+		}
+	case MetricTypeSummary:
+		if v, ok := value.(float64); ok {
+			impl.summary.observe(v, time.Now())
+		}
+	default:
+		impl.value = value
 	}
-	m.metrics[key] = impl
 	
 	return nil
 }
 
-// UnregisterMetric unregisters a metric.
+// UnregisterMetric removes all series of a metric.
 func (m *MonitorImpl) UnregisterMetric(ctx context.Context, name string) error {
 	if !m.started {
 		return ErrMonitorNotInitialized
@@ -289,8 +256,8 @@ func (m *MonitorImpl) UnregisterMetric(ctx context.Context, name string) error {
 	defer m.mu.Unlock()
 	
 	found := false
-	for key := range m.metrics {
-		if strings.HasPrefix(key, name+"_") || key == name {
+	for key, metric := range m.metrics {
+		if metric.name == name {
 			delete(m.metrics, key)
 			found = true
 		}
@@ -300,6 +267,10 @@ func (m *MonitorImpl) UnregisterMetric(ctx context.Context, name string) error {
 		return ErrMetricNotFound
 	}
 	
+	delete(m.types, name)
+	delete(m.series, name)
+	delete(m.dropped, name)
+	
 	return nil
 }
 
@@ -334,8 +305,12 @@ func (m *MonitorImpl) Export(ctx context.Context, format ExportFormat) ([]byte,
 	switch format {
 	case ExportFormatJSON:
 		return m.exportJSON(metrics)
-	case ExportFormatPrometheus:
-		return m.exportPrometheus(metrics)
+	case ExportFormatPrometheus, ExportFormatOpenMetrics:
+		var buf bytes.Buffer
+		if err := m.writeExposition(&buf, format); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrExportFailed, err)
+		}
+		return buf.Bytes(), nil
 	case ExportFormatInfluxDB:
 		return m.exportInfluxDB(metrics)
 	default:
@@ -356,12 +331,114 @@ func (m *MonitorImpl) Close() error {
 	m.started = false
 	m.metrics = make(map[string]*metricImpl)
 	m.timers = make(map[string]*timerImpl)
+	m.types = make(map[string]MetricType)
+	m.series = make(map[string]int)
+	m.dropped = make(map[string]uint64)
+	m.closeOnce.Do(func() { close(m.done) })
 	
 	return nil
 }
 
 // Helper methods
 
+// getSeries returns the series of name with labels, creating it on first use.
+// It returns nil and no error when the series is dropped because the metric
+// already has Config.MaxSeriesPerMetric label sets.
+func (m *MonitorImpl) getSeries(name string, metricType MetricType, labels map[string]string) (*metricImpl, error) {
+	if !m.started {
+		return nil, ErrMonitorNotInitialized
+	}
+	
+	if name == "" {
+		return nil, ErrInvalidMetricName
+	}
+	
+	key := m.buildMetricKey(name, labels)
+	m.mu.RLock()
+	metric, exists := m.metrics[key]
+	m.mu.RUnlock()
+	if exists {
+		if metric.metricType != metricType {
+			return nil, fmt.Errorf("%w: %s is a %s", ErrInvalidMetricType, name, metric.metricType)
+		}
+		return metric, nil
+	}
+	
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if metric, exists := m.metrics[key]; exists {
+		if metric.metricType != metricType {
+			return nil, fmt.Errorf("%w: %s is a %s", ErrInvalidMetricType, name, metric.metricType)
+		}
+		return metric, nil
+	}
+	return m.createSeries(key, name, metricType, m.mergeLabels(labels))
+}
+
+// createSeries adds a series, enforcing the metric type and the cardinality
+// limit. Callers must hold m.mu.
+func (m *MonitorImpl) createSeries(key, name string, metricType MetricType, labels map[string]string) (*metricImpl, error) {
+	if existing, ok := m.types[name]; ok && existing != metricType {
+		return nil, fmt.Errorf("%w: %s is a %s", ErrInvalidMetricType, name, existing)
+	}
+	
+	if limit := m.config.MaxSeriesPerMetric; limit > 0 && m.series[name] >= limit {
+		m.dropped[name]++
+		return nil, nil
+	}
+	
+	metric := &metricImpl{
+		name:       name,
+		metricType: metricType,
+		labels:     labels,
+		timestamp:  time.Now(),
+	}
+	switch metricType {
+	case MetricTypeCounter, MetricTypeGauge:
+		metric.value = float64(0)
+	case MetricTypeHistogram:
+		metric.histogram = newHistogram(m.bucketsFor(name))
+	case MetricTypeSummary:
+		metric.summary = newSummary(m.config.SummaryObjectives, m.config.SummaryMaxAge, m.config.SummaryAgeBuckets, metric.timestamp)
+	default:
+		return nil, ErrInvalidMetricType
+	}
+	
+	m.metrics[key] = metric
+	m.types[name] = metricType
+	m.series[name]++
+	return metric, nil
+}
+
+func (m *MonitorImpl) bucketsFor(name string) []float64 {
+	if buckets, ok := m.config.Buckets[name]; ok {
+		return buckets
+	}
+	if buckets, ok := DefaultMetricBuckets[name]; ok {
+		return buckets
+	}
+	if buckets, ok := DefaultMetricSizeBuckets[name]; ok {
+		return buckets
+	}
+	if len(m.config.HistogramBuckets) > 0 {
+		return m.config.HistogramBuckets
+	}
+	return DefaultHistogramBuckets
+}
+
+// DroppedSeries returns, per metric name, how many observations were dropped
+// by the cardinality limit.
+func (m *MonitorImpl) DroppedSeries() map[string]uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	
+	dropped := make(map[string]uint64, len(m.dropped))
+	for name, count := range m.dropped {
+		dropped[name] = count
+	}
+	return dropped
+}
+
 func (m *MonitorImpl) buildMetricKey(name string, labels map[string]string) string {
 	if len(labels) == 0 {
 		return name
@@ -410,41 +487,6 @@ func (m *MonitorImpl) exportJSON(metrics []Metric) ([]byte, error) {
 	return json.Marshal(data)
 }
 
-func (m *MonitorImpl) exportPrometheus(metrics []Metric) ([]byte, error) {
-	var lines []string
-	
-	for _, metric := range metrics {
-		var metricType string
-		switch metric.Type() {
-		case MetricTypeCounter:
-			metricType = "counter"
-		case MetricTypeGauge:
-			metricType = "gauge"
-		case MetricTypeHistogram:
-			metricType = "histogram"
-		case MetricTypeSummary:
-			metricType = "summary"
-		}
-		
-		lines = append(lines, fmt.Sprintf("# TYPE %s %s", metric.Name(), metricType))
-		
-		labelStr := ""
-		if len(metric.Labels()) > 0 {
-			var labelParts []string
-			for k, v := range metric.Labels() {
-				labelParts = append(labelParts, fmt.Sprintf(`%s="%s"`, k, v))
-			}
-			sort.Strings(labelParts)
-			labelStr = fmt.Sprintf("{%s}", strings.Join(labelParts, ","))
-		}
-		
-		lines = append(lines, fmt.Sprintf("%s%s %v %d", 
-			metric.Name(), labelStr, metric.Value(), metric.Timestamp().Unix()))
-	}
-	
-	return []byte(strings.Join(lines, "\n")), nil
-}
-
 func (m *MonitorImpl) exportInfluxDB(metrics []Metric) ([]byte, error) {
 	var lines []string
 	
@@ -460,8 +502,16 @@ func (m *MonitorImpl) exportInfluxDB(metrics []Metric) ([]byte, error) {
 			tagStr = "," + strings.Join(tagParts, ",")
 		}
 		
-		lines = append(lines, fmt.Sprintf("%s%s value=%v %d", 
-			metric.Name(), tagStr, metric.Value(), metric.Timestamp().UnixNano()))
+		fields := fmt.Sprintf("value=%v", metric.Value())
+		switch value := metric.Value().(type) {
+		case HistogramValue:
+			fields = fmt.Sprintf("sum=%v,count=%di", value.Sum, value.Count)
+		case SummaryValue:
+			fields = fmt.Sprintf("sum=%v,count=%di", value.Sum, value.Count)
+		}
+		
+		lines = append(lines, fmt.Sprintf("%s%s %s %d", 
+			metric.Name(), tagStr, fields, metric.Timestamp().UnixNano()))
 	}
 	
 	return []byte(strings.Join(lines, "\n")), nil
@@ -481,9 +531,20 @@ func (m *metricImpl) Type() MetricType {
 	return m.metricType
 }
 
+// Value returns a float64 for counters and gauges, a HistogramValue for
+// histograms and a SummaryValue for summaries.
 func (m *metricImpl) Value() interface{} {
+	if m.summary != nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.summary.value(time.Now())
+	}
+	
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	if m.histogram != nil {
+		return m.histogram.value()
+	}
 	return m.value
 }
 
@@ -0,0 +1,267 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/tracing"
+)
+
+func newTestMonitor(t *testing.T, configure func(*Config)) *MonitorImpl {
+	t.Helper()
+	config := DefaultConfig()
+	config.ServiceName = "api"
+	config.ServiceVersion = "1.0"
+	if configure != nil {
+		configure(&config)
+	}
+	m, err := NewMonitor(config)
+	if err != nil {
+		t.Fatalf("NewMonitor() error = %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+func TestPrometheusExposition(t *testing.T) {
+	m := newTestMonitor(t, func(c *Config) {
+		c.Buckets = map[string][]float64{"job_duration_seconds": {0.1, 1}}
+	})
+	ctx := context.Background()
+
+	m.AddCounter(ctx, "jobs_total", 2, map[string]string{"queue": "a"})
+	m.IncrementCounter(ctx, "jobs_total", map[string]string{"queue": "a"})
+	m.SetGauge(ctx, "queue_depth", 7, map[string]string{"queue": "say \"hi\"\n"})
+	for _, v := range []float64{0.05, 0.5, 0.7, 3} {
+		m.RecordHistogram(ctx, "job_duration_seconds", v, nil)
+	}
+
+	out, err := m.Export(ctx, ExportFormatPrometheus)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := `# TYPE job_duration_seconds histogram
+job_duration_seconds_bucket{service="api",version="1.0",le="0.1"} 1
+job_duration_seconds_bucket{service="api",version="1.0",le="1"} 3
+job_duration_seconds_bucket{service="api",version="1.0",le="+Inf"} 4
+job_duration_seconds_sum{service="api",version="1.0"} 4.25
+job_duration_seconds_count{service="api",version="1.0"} 4
+# TYPE jobs_total counter
+jobs_total{queue="a",service="api",version="1.0"} 3
+# TYPE queue_depth gauge
+queue_depth{queue="say \"hi\"\n",service="api",version="1.0"} 7
+`
+	if string(out) != want {
+		t.Errorf("Export() =\n%s\nwant\n%s", out, want)
+	}
+
+	value := m.metrics[m.buildMetricKey("job_duration_seconds", nil)].Value().(HistogramValue)
+	if value.Count != 4 || len(value.Buckets) != 2 || value.Buckets[1].Count != 3 {
+		t.Errorf("histogram value = %+v", value)
+	}
+}
+
+func TestMetricTypeConflict(t *testing.T) {
+	m := newTestMonitor(t, nil)
+	ctx := context.Background()
+
+	m.IncrementCounter(ctx, "requests", map[string]string{"path": "/"})
+	err := m.SetGauge(ctx, "requests", 1, map[string]string{"path": "/other"})
+	if !errors.Is(err, ErrInvalidMetricType) {
+		t.Errorf("expected ErrInvalidMetricType, got %v", err)
+	}
+}
+
+type sampledSpanContext struct{}
+
+func (sampledSpanContext) TraceID() string                { return "4bf92f3577b34da6a3ce929d0e0e4736" }
+func (sampledSpanContext) SpanID() string                 { return "00f067aa0ba902b7" }
+func (sampledSpanContext) IsSampled() bool                { return true }
+func (sampledSpanContext) TraceFlags() byte               { return 1 }
+func (sampledSpanContext) TraceState() tracing.TraceState { return nil }
+
+func TestOpenMetricsExemplars(t *testing.T) {
+	m := newTestMonitor(t, func(c *Config) {
+		c.Buckets = map[string][]float64{"latency_seconds": {0.5}}
+	})
+	ctx := tracing.ContextWithRemoteSpanContext(context.Background(), sampledSpanContext{})
+
+	m.IncrementCounter(ctx, "requests_total", nil)
+	m.RecordHistogram(ctx, "latency_seconds", 0.2, nil)
+	m.RecordHistogram(context.Background(), "latency_seconds", 0.9, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != OpenMetricsContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+	exemplar := `# {span_id="00f067aa0ba902b7",trace_id="4bf92f3577b34da6a3ce929d0e0e4736"}`
+	for _, want := range []string{
+		"# TYPE requests counter\n",
+		`requests_total{service="api",version="1.0"} 1 ` + exemplar + " 1 ",
+		`latency_seconds_bucket{service="api",version="1.0",le="0.5"} 1 ` + exemplar + " 0.2 ",
+		`latency_seconds_bucket{service="api",version="1.0",le="+Inf"} 2` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("OpenMetrics output missing %q:\n%s", want, body)
+		}
+	}
+	if !strings.HasSuffix(body, "# EOF\n") {
+		t.Errorf("OpenMetrics output not terminated by # EOF:\n%s", body)
+	}
+
+	// without OpenMetrics in Accept the text format carries no exemplars
+	rec = httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != PrometheusContentType {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if strings.Contains(rec.Body.String(), "trace_id") || strings.Contains(rec.Body.String(), "# EOF") {
+		t.Errorf("text format contains OpenMetrics syntax:\n%s", rec.Body.String())
+	}
+}
+
+func TestAcceptsOpenMetrics(t *testing.T) {
+	tests := map[string]bool{
+		"":                             false,
+		"text/plain":                   false,
+		"application/openmetrics-text": true,
+		"text/plain;q=0.9, application/openmetrics-text; version=1.0.0; q=0.5": true,
+		"application/openmetrics-text;q=0":                                     false,
+	}
+	for accept, want := range tests {
+		if got := acceptsOpenMetrics(accept); got != want {
+			t.Errorf("acceptsOpenMetrics(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
+
+func TestSummaryQuantileError(t *testing.T) {
+	m := newTestMonitor(t, nil)
+	ctx := context.Background()
+
+	const n = 20000
+	values := make([]float64, n)
+	rng := rand.New(rand.NewSource(1))
+	for i := range values {
+		values[i] = rng.NormFloat64()
+		m.RecordSummary(ctx, "payload", values[i], nil)
+	}
+	sort.Float64s(values)
+
+	value := m.metrics[m.buildMetricKey("payload", nil)].Value().(SummaryValue)
+	if value.Count != n || len(value.Quantiles) != len(DefaultSummaryObjectives) {
+		t.Fatalf("summary value = %+v", value)
+	}
+	for _, q := range value.Quantiles {
+		epsilon := DefaultSummaryObjectives[q.Quantile]
+		rank := sort.SearchFloat64s(values, q.Value)
+		if got := float64(rank) / n; math.Abs(got-q.Quantile) > epsilon {
+			t.Errorf("quantile %v: rank %v exceeds error %v", q.Quantile, got, epsilon)
+		}
+	}
+}
+
+func TestSummaryMaxAge(t *testing.T) {
+	now := time.Now()
+	s := newSummary(map[float64]float64{0.5: 0.05}, time.Minute, 3, now)
+	s.observe(100, now)
+
+	if v := s.value(now.Add(30 * time.Second)); len(v.Quantiles) != 1 || v.Quantiles[0].Value != 100 {
+		t.Errorf("value within max age = %+v", v)
+	}
+	if v := s.value(now.Add(61 * time.Second)); len(v.Quantiles) != 0 || v.Count != 1 {
+		t.Errorf("value after max age = %+v", v)
+	}
+}
+
+func TestCardinalityLimit(t *testing.T) {
+	m := newTestMonitor(t, func(c *Config) { c.MaxSeriesPerMetric = 2 })
+	ctx := context.Background()
+
+	for _, user := range []string{"a", "b", "c", "d", "a"} {
+		if err := m.IncrementCounter(ctx, "logins_total", map[string]string{"user": user}); err != nil {
+			t.Fatalf("IncrementCounter() error = %v", err)
+		}
+	}
+
+	if dropped := m.DroppedSeries()["logins_total"]; dropped != 2 {
+		t.Errorf("dropped = %d, want 2", dropped)
+	}
+	out, _ := m.Export(ctx, ExportFormatPrometheus)
+	if !strings.Contains(string(out), `logins_total{service="api",user="a",version="1.0"} 2`) ||
+		strings.Contains(string(out), `user="c"`) ||
+		!strings.Contains(string(out), DroppedSeriesMetric+`{metric="logins_total"} 2`) {
+		t.Errorf("unexpected exposition:\n%s", out)
+	}
+
+	if err := m.UnregisterMetric(ctx, "logins_total"); err != nil {
+		t.Fatalf("UnregisterMetric() error = %v", err)
+	}
+	m.IncrementCounter(ctx, "logins_total", map[string]string{"user": "c"})
+	if len(m.DroppedSeries()) != 0 {
+		t.Errorf("dropped series not reset: %v", m.DroppedSeries())
+	}
+}
+
+func TestPush(t *testing.T) {
+	var method, path, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, contentType = r.Method, r.URL.EscapedPath(), r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := newTestMonitor(t, func(c *Config) {
+		c.PushgatewayURL = server.URL + "/"
+		c.PushGrouping = map[string]string{"instance": "host-1", "path": "/var/run"}
+	})
+	ctx := context.Background()
+	m.IncrementCounter(ctx, "batches_total", nil)
+
+	if err := m.Push(ctx); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if method != http.MethodPut || contentType != PrometheusContentType {
+		t.Errorf("request = %s %s", method, contentType)
+	}
+	if want := "/metrics/job/api/instance/host-1/path@base64/L3Zhci9ydW4"; path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+	if !strings.Contains(body, `batches_total{service="api",version="1.0"} 1`) {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestPushError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "pushed metrics are invalid", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	m := newTestMonitor(t, func(c *Config) { c.PushgatewayURL = server.URL })
+	if err := m.Push(context.Background()); !errors.Is(err, ErrExportFailed) || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("expected ErrExportFailed, got %v", err)
+	}
+
+	m = newTestMonitor(t, nil)
+	if err := m.Push(context.Background()); !errors.Is(err, ErrExportFailed) {
+		t.Errorf("expected ErrExportFailed without url, got %v", err)
+	}
+}
@@ -3,6 +3,8 @@ package monitoring
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sort"
 	"time"
 )
 
@@ -34,6 +36,8 @@ type Monitor interface {
 	
 	// Export operations
 	Export(ctx context.Context, format ExportFormat) ([]byte, error)
+	Handler() http.Handler
+	Push(ctx context.Context) error
 	
 	// Health check
 	IsHealthy(ctx context.Context) bool
@@ -67,6 +71,22 @@ const (
 	MetricTypeSummary
 )
 
+// String returns the Prometheus name of the metric type.
+func (t MetricType) String() string {
+	switch t {
+	case MetricTypeCounter:
+		return "counter"
+	case MetricTypeGauge:
+		return "gauge"
+	case MetricTypeHistogram:
+		return "histogram"
+	case MetricTypeSummary:
+		return "summary"
+	default:
+		return "unknown"
+	}
+}
+
 // ExportFormat represents the export format
 type ExportFormat int
 
@@ -74,8 +94,21 @@ const (
 	ExportFormatPrometheus ExportFormat = iota
 	ExportFormatJSON
 	ExportFormatInfluxDB
+	ExportFormatOpenMetrics
 )
 
+// DefaultHistogramBuckets are the histogram buckets used when neither
+// Config.Buckets nor DefaultMetricBuckets define buckets for a metric.
+var DefaultHistogramBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultSummaryObjectives maps the quantiles reported by summaries to their
+// allowed absolute rank error.
+var DefaultSummaryObjectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+
+// DefaultMaxSeriesPerMetric is the label set limit applied per metric name
+// when Config.MaxSeriesPerMetric is zero.
+const DefaultMaxSeriesPerMetric = 1000
+
 // Config defines the configuration for monitoring.
 type Config struct {
 	Enabled bool          `yaml:"enabled" env:"MONITORING_ENABLED" default:"true"`
@@ -93,6 +126,24 @@ type Config struct {
 	ExportEndpoint    string        `yaml:"export_endpoint" env:"MONITORING_EXPORT_ENDPOINT" default:""`
 	ExportBatchSize   int           `yaml:"export_batch_size" env:"MONITORING_EXPORT_BATCH_SIZE" default:"100"`
 	ExportTimeout     time.Duration `yaml:"export_timeout" env:"MONITORING_EXPORT_TIMEOUT" default:"10s"`
+
+	// Histogram and summary configuration. Buckets overrides the buckets of
+	// single metrics, HistogramBuckets applies to all other histograms.
+	HistogramBuckets  []float64            `yaml:"histogram_buckets" env:"MONITORING_HISTOGRAM_BUCKETS"`
+	Buckets           map[string][]float64 `yaml:"buckets"`
+	SummaryObjectives map[float64]float64  `yaml:"summary_objectives"`
+	SummaryMaxAge     time.Duration        `yaml:"summary_max_age" env:"MONITORING_SUMMARY_MAX_AGE" default:"10m"`
+	SummaryAgeBuckets int                  `yaml:"summary_age_buckets" env:"MONITORING_SUMMARY_AGE_BUCKETS" default:"5"`
+
+	// Cardinality configuration. Label sets beyond the limit are dropped and
+	// counted; a negative value disables the limit.
+	MaxSeriesPerMetric int `yaml:"max_series_per_metric" env:"MONITORING_MAX_SERIES_PER_METRIC" default:"1000"`
+
+	// Push configuration for a Pushgateway-compatible endpoint
+	PushgatewayURL string            `yaml:"pushgateway_url" env:"MONITORING_PUSHGATEWAY_URL" default:""`
+	PushJob        string            `yaml:"push_job" env:"MONITORING_PUSH_JOB" default:""` // defaults to ServiceName
+	PushGrouping   map[string]string `yaml:"push_grouping"`
+	PushInterval   time.Duration     `yaml:"push_interval" env:"MONITORING_PUSH_INTERVAL" default:"15s"`
 	
 	// Storage configuration
 	StorageEnabled    bool          `yaml:"storage_enabled" env:"MONITORING_STORAGE_ENABLED" default:"false"`
@@ -131,6 +182,24 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("export timeout must be positive")
 	}
 	
+	for name, buckets := range c.Buckets {
+		if err := validateBuckets(buckets); err != nil {
+			return fmt.Errorf("buckets of %s: %w", name, err)
+		}
+	}
+	if err := validateBuckets(c.HistogramBuckets); err != nil {
+		return fmt.Errorf("histogram buckets: %w", err)
+	}
+	
+	for q, e := range c.SummaryObjectives {
+		if q <= 0 || q >= 1 || e < 0 || e >= 1 {
+			return fmt.Errorf("invalid summary objective %v: %v", q, e)
+		}
+	}
+	if c.SummaryMaxAge < 0 || c.SummaryAgeBuckets < 0 {
+		return fmt.Errorf("summary max age and age buckets cannot be negative")
+	}
+	
 	if c.StorageEnabled {
 		if c.StorageRetention <= 0 {
 			return fmt.Errorf("storage retention must be positive")
@@ -165,6 +234,18 @@ func DefaultConfig() Config {
 		ExportEndpoint:  "",
 		ExportBatchSize: 100,
 		ExportTimeout:   10 * time.Second,
+
+		// Histogram and summary configuration
+		HistogramBuckets:  DefaultHistogramBuckets,
+		SummaryObjectives: DefaultSummaryObjectives,
+		SummaryMaxAge:     10 * time.Minute,
+		SummaryAgeBuckets: 5,
+
+		// Cardinality configuration
+		MaxSeriesPerMetric: DefaultMaxSeriesPerMetric,
+
+		// Push configuration
+		PushInterval: 15 * time.Second,
 		
 		// Storage configuration
 		StorageEnabled:   false,
@@ -177,3 +258,16 @@ func DefaultConfig() Config {
 		ServiceVersion: "unknown",
 	}
 }
+
+// validateBuckets checks that bucket upper bounds are strictly increasing.
+func validateBuckets(buckets []float64) error {
+	if !sort.Float64sAreSorted(buckets) {
+		return fmt.Errorf("buckets must be sorted")
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] == buckets[i-1] {
+			return fmt.Errorf("duplicate bucket %v", buckets[i])
+		}
+	}
+	return nil
+}
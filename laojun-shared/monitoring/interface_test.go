@@ -1,20 +1,19 @@
 package monitoring
 
 import (
-	"context"
 	"testing"
-	"time"
 )
 
 func TestNew(t *testing.T) {
 	config := DefaultConfig()
 	
-	impl := New(config)
+	impl, err := NewMonitor(config)
+	if err != nil {
+		t.Fatalf("NewMonitor() error = %v", err)
+	}
 	if impl == nil {
-		t.Fatal("New() returned nil")
+		t.Fatal("NewMonitor() returned nil")
 	}
-	
-	// TODO: Add more tests
 }
 
 func TestConfig_Validate(t *testing.T) {
@@ -36,7 +35,24 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
-		// TODO: Add more test cases
+		{
+			name: "unsorted buckets",
+			config: func() Config {
+				c := DefaultConfig()
+				c.Buckets = map[string][]float64{"latency": {1, 0.5}}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "invalid summary objective",
+			config: func() Config {
+				c := DefaultConfig()
+				c.SummaryObjectives = map[float64]float64{1.5: 0.01}
+				return c
+			}(),
+			wantErr: true,
+		},
 	}
 	
 	for _, tt := range tests {
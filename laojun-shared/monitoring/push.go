@@ -0,0 +1,114 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Push replaces the metrics previously pushed for Config.PushJob and
+// Config.PushGrouping on the Pushgateway at Config.PushgatewayURL with the
+// current metrics.
+func (m *MonitorImpl) Push(ctx context.Context) error {
+	if !m.started {
+		return ErrMonitorNotInitialized
+	}
+	if m.config.PushgatewayURL == "" {
+		return fmt.Errorf("%w: pushgateway url not configured", ErrExportFailed)
+	}
+
+	target, err := pushURL(m.config.PushgatewayURL, m.config.PushJob, m.config.PushGrouping)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrExportFailed, err)
+	}
+
+	var body bytes.Buffer
+	if err := m.writeExposition(&body, ExportFormatPrometheus); err != nil {
+		return fmt.Errorf("%w: %v", ErrExportFailed, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.ExportTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, &body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrExportFailed, err)
+	}
+	req.Header.Set("Content-Type", PrometheusContentType)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrExportFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%w: pushgateway returned %s: %s", ErrExportFailed, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// StartPush pushes the metrics every Config.PushInterval until ctx is done
+// or the monitor is closed. Failed pushes are passed to onError if set.
+func (m *MonitorImpl) StartPush(ctx context.Context, onError func(error)) {
+	go func() {
+		ticker := time.NewTicker(m.config.PushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.done:
+				return
+			case <-ticker.C:
+				if err := m.Push(ctx); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// pushURL builds <base>/metrics/job/<job>{/<label>/<value>}. Values that are
+// empty or contain a slash use the Pushgateway base64 encoding.
+func pushURL(base, job string, grouping map[string]string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid pushgateway url %q", base)
+	}
+	if job == "" {
+		return "", fmt.Errorf("push job cannot be empty")
+	}
+
+	path := strings.TrimSuffix(u.String(), "/") + "/metrics/" + pushSegment("job", job)
+
+	names := make([]string, 0, len(grouping))
+	for name := range grouping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path += "/" + pushSegment(name, grouping[name])
+	}
+	return path, nil
+}
+
+func pushSegment(name, value string) string {
+	if value == "" {
+		return name + "@base64/="
+	}
+	if strings.Contains(value, "/") {
+		return name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+	return name + "/" + url.PathEscape(value)
+}
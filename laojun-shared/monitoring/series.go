@@ -0,0 +1,159 @@
+package monitoring
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/beorn7/perks/quantile"
+
+	"github.com/codetaoist/laojun-shared/tracing"
+)
+
+// Exemplar links an observation to the trace it was recorded in.
+type Exemplar struct {
+	Labels    map[string]string `json:"labels"`
+	Value     float64           `json:"value"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Bucket is a cumulative histogram bucket.
+type Bucket struct {
+	UpperBound float64 `json:"upper_bound"`
+	Count      uint64  `json:"count"`
+}
+
+// HistogramValue is the value of a histogram metric. Buckets do not include
+// the implicit +Inf bucket, whose count equals Count.
+type HistogramValue struct {
+	Buckets []Bucket `json:"buckets"`
+	Sum     float64  `json:"sum"`
+	Count   uint64   `json:"count"`
+}
+
+// Quantile is a quantile estimated by a summary.
+type Quantile struct {
+	Quantile float64 `json:"quantile"`
+	Value    float64 `json:"value"`
+}
+
+// SummaryValue is the value of a summary metric. Quantiles cover the
+// observations of the last Config.SummaryMaxAge, Sum and Count all
+// observations.
+type SummaryValue struct {
+	Quantiles []Quantile `json:"quantiles"`
+	Sum       float64    `json:"sum"`
+	Count     uint64     `json:"count"`
+}
+
+// exemplarFromContext returns an exemplar for the sampled span in ctx, or
+// nil when ctx carries no sampled trace.
+func exemplarFromContext(ctx context.Context, value float64, now time.Time) *Exemplar {
+	if ctx == nil {
+		return nil
+	}
+	sc := tracing.SpanContextFromContext(ctx)
+	if sc == nil || sc.TraceID() == "" || !sc.IsSampled() {
+		return nil
+	}
+	labels := map[string]string{"trace_id": sc.TraceID()}
+	if spanID := sc.SpanID(); spanID != "" {
+		labels["span_id"] = spanID
+	}
+	return &Exemplar{Labels: labels, Value: value, Timestamp: now}
+}
+
+// histogram counts observations in fixed buckets.
+type histogram struct {
+	upperBounds []float64
+	counts      []uint64 // per bucket, the last one is +Inf
+	exemplars   []*Exemplar
+	sum         float64
+	count       uint64
+}
+
+func newHistogram(upperBounds []float64) *histogram {
+	return &histogram{
+		upperBounds: upperBounds,
+		counts:      make([]uint64, len(upperBounds)+1),
+		exemplars:   make([]*Exemplar, len(upperBounds)+1),
+	}
+}
+
+func (h *histogram) observe(value float64, exemplar *Exemplar) {
+	i := sort.SearchFloat64s(h.upperBounds, value)
+	h.counts[i]++
+	h.sum += value
+	h.count++
+	if exemplar != nil {
+		h.exemplars[i] = exemplar
+	}
+}
+
+func (h *histogram) value() HistogramValue {
+	v := HistogramValue{Buckets: make([]Bucket, len(h.upperBounds)), Sum: h.sum, Count: h.count}
+	var cumulative uint64
+	for i, upperBound := range h.upperBounds {
+		cumulative += h.counts[i]
+		v.Buckets[i] = Bucket{UpperBound: upperBound, Count: cumulative}
+	}
+	return v
+}
+
+// summary estimates quantiles over a sliding window using targeted streams
+// with bounded rank error. Each of the age buckets streams covers up to
+// maxAge; the oldest one answers queries and is reset on rotation.
+type summary struct {
+	objectives []float64
+	streams    []*quantile.Stream
+	head       int
+	headExpiry time.Time
+	streamAge  time.Duration
+	sum        float64
+	count      uint64
+}
+
+func newSummary(objectives map[float64]float64, maxAge time.Duration, ageBuckets int, now time.Time) *summary {
+	s := &summary{
+		streams:    make([]*quantile.Stream, ageBuckets),
+		streamAge:  maxAge / time.Duration(ageBuckets),
+		headExpiry: now.Add(maxAge / time.Duration(ageBuckets)),
+	}
+	for q := range objectives {
+		s.objectives = append(s.objectives, q)
+	}
+	sort.Float64s(s.objectives)
+	for i := range s.streams {
+		s.streams[i] = quantile.NewTargeted(objectives)
+	}
+	return s
+}
+
+func (s *summary) rotate(now time.Time) {
+	for !now.Before(s.headExpiry) {
+		s.streams[s.head].Reset()
+		s.head = (s.head + 1) % len(s.streams)
+		s.headExpiry = s.headExpiry.Add(s.streamAge)
+	}
+}
+
+func (s *summary) observe(value float64, now time.Time) {
+	s.rotate(now)
+	for _, stream := range s.streams {
+		stream.Insert(value)
+	}
+	s.sum += value
+	s.count++
+}
+
+// value omits the quantiles when the window holds no observations.
+func (s *summary) value(now time.Time) SummaryValue {
+	s.rotate(now)
+	v := SummaryValue{Sum: s.sum, Count: s.count}
+	if head := s.streams[s.head]; head.Count() > 0 {
+		for _, q := range s.objectives {
+			v.Quantiles = append(v.Quantiles, Quantile{Quantile: q, Value: head.Query(q)})
+		}
+	}
+	return v
+}
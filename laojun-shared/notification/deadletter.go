@@ -0,0 +1,184 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeadLetter is a delivery that failed permanently or exhausted its retries.
+type DeadLetter struct {
+	Delivery *Delivery `json:"delivery"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// DeadLetterStore keeps failed deliveries for inspection and replay.
+type DeadLetterStore interface {
+	Put(ctx context.Context, letter *DeadLetter) error
+	List(ctx context.Context, limit int) ([]*DeadLetter, error)
+	Get(ctx context.Context, id string) (*DeadLetter, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryDeadLetterStore keeps dead letters in memory.
+type MemoryDeadLetterStore struct {
+	mu      sync.RWMutex
+	letters map[string]*DeadLetter
+}
+
+// NewMemoryDeadLetterStore creates an in-memory dead letter store.
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{letters: make(map[string]*DeadLetter)}
+}
+
+// Put implements DeadLetterStore.
+func (s *MemoryDeadLetterStore) Put(ctx context.Context, letter *DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters[letter.Delivery.ID] = letter
+	return nil
+}
+
+// List implements DeadLetterStore, oldest first.
+func (s *MemoryDeadLetterStore) List(ctx context.Context, limit int) ([]*DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	letters := make([]*DeadLetter, 0, len(s.letters))
+	for _, letter := range s.letters {
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+	if limit > 0 && len(letters) > limit {
+		letters = letters[:limit]
+	}
+	return letters, nil
+}
+
+// Get implements DeadLetterStore.
+func (s *MemoryDeadLetterStore) Get(ctx context.Context, id string) (*DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	letter, ok := s.letters[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: dead letter %s", ErrNotFound, id)
+	}
+	return letter, nil
+}
+
+// Delete implements DeadLetterStore.
+func (s *MemoryDeadLetterStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.letters, id)
+	return nil
+}
+
+// deadLetterRecord is the table row of GormDeadLetterStore.
+type deadLetterRecord struct {
+	ID       string `gorm:"primaryKey;size:64"`
+	Channel  string `gorm:"size:32;index"`
+	UserID   string `gorm:"size:64;index"`
+	Payload  string `gorm:"type:text"`
+	Attempts int
+	Error    string    `gorm:"type:text"`
+	FailedAt time.Time `gorm:"index"`
+}
+
+func (deadLetterRecord) TableName() string {
+	return "notification_dead_letters"
+}
+
+// GormDeadLetterStore keeps dead letters in a database table.
+type GormDeadLetterStore struct {
+	db *gorm.DB
+}
+
+// NewGormDeadLetterStore creates a database dead letter store and migrates
+// its table.
+func NewGormDeadLetterStore(db *gorm.DB) (*GormDeadLetterStore, error) {
+	if err := db.AutoMigrate(&deadLetterRecord{}); err != nil {
+		return nil, fmt.Errorf("migrate dead letters: %w", err)
+	}
+	return &GormDeadLetterStore{db: db}, nil
+}
+
+// Put implements DeadLetterStore.
+func (s *GormDeadLetterStore) Put(ctx context.Context, letter *DeadLetter) error {
+	payload, err := json.Marshal(letter.Delivery)
+	if err != nil {
+		return fmt.Errorf("marshal delivery: %w", err)
+	}
+	record := &deadLetterRecord{
+		ID:       letter.Delivery.ID,
+		Channel:  string(letter.Delivery.Channel),
+		UserID:   letter.Delivery.Recipient.UserID,
+		Payload:  string(payload),
+		Attempts: letter.Attempts,
+		Error:    letter.Error,
+		FailedAt: letter.FailedAt,
+	}
+	return s.db.WithContext(ctx).Save(record).Error
+}
+
+// List implements DeadLetterStore, oldest first.
+func (s *GormDeadLetterStore) List(ctx context.Context, limit int) ([]*DeadLetter, error) {
+	var records []deadLetterRecord
+	query := s.db.WithContext(ctx).Order("failed_at")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	letters := make([]*DeadLetter, 0, len(records))
+	for i := range records {
+		letter, err := records[i].deadLetter()
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
+
+// Get implements DeadLetterStore.
+func (s *GormDeadLetterStore) Get(ctx context.Context, id string) (*DeadLetter, error) {
+	var record deadLetterRecord
+	err := s.db.WithContext(ctx).First(&record, "id = ?", id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("%w: dead letter %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record.deadLetter()
+}
+
+// Delete implements DeadLetterStore.
+func (s *GormDeadLetterStore) Delete(ctx context.Context, id string) error {
+	return s.db.WithContext(ctx).Delete(&deadLetterRecord{}, "id = ?", id).Error
+}
+
+func (r *deadLetterRecord) deadLetter() (*DeadLetter, error) {
+	var delivery Delivery
+	if err := json.Unmarshal([]byte(r.Payload), &delivery); err != nil {
+		return nil, fmt.Errorf("unmarshal dead letter %s: %w", r.ID, err)
+	}
+	return &DeadLetter{
+		Delivery: &delivery,
+		Attempts: r.Attempts,
+		Error:    r.Error,
+		FailedAt: r.FailedAt,
+	}, nil
+}
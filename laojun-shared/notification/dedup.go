@@ -0,0 +1,83 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter implements deduplication and throttling keyed by arbitrary strings.
+// A shared implementation (e.g. Redis) deduplicates across instances.
+type Limiter interface {
+	// FirstSeen records key and reports whether it was not seen within window.
+	FirstSeen(ctx context.Context, key string, window time.Duration) (bool, error)
+	// Allow records one event for key and reports whether at most limit
+	// events happened within window.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+}
+
+// MemoryLimiter is an in-process Limiter.
+type MemoryLimiter struct {
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	events map[string][]time.Time
+	now    func() time.Time
+}
+
+// NewMemoryLimiter creates an in-process limiter.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		seen:   make(map[string]time.Time),
+		events: make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+// FirstSeen implements Limiter.
+func (l *MemoryLimiter) FirstSeen(ctx context.Context, key string, window time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.expire(now)
+	if expiry, ok := l.seen[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	l.seen[key] = now.Add(window)
+	return true, nil
+}
+
+// Allow implements Limiter using a sliding window.
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	events := l.events[key]
+	cutoff := now.Add(-window)
+	kept := events[:0]
+	for _, t := range events {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= limit {
+		l.events[key] = kept
+		return false, nil
+	}
+	l.events[key] = append(kept, now)
+	return true, nil
+}
+
+// expire drops dedup keys past their window so memory stays bounded by the
+// notification rate. The caller must hold l.mu.
+func (l *MemoryLimiter) expire(now time.Time) {
+	if len(l.seen) < 1024 {
+		return
+	}
+	for key, expiry := range l.seen {
+		if !now.Before(expiry) {
+			delete(l.seen, key)
+		}
+	}
+}
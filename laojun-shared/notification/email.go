@@ -0,0 +1,242 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// SMTP TLS modes.
+const (
+	// SMTPTLSOpportunistic upgrades with STARTTLS when the server offers it.
+	SMTPTLSOpportunistic = ""
+	// SMTPTLSStartTLS requires STARTTLS.
+	SMTPTLSStartTLS = "starttls"
+	// SMTPTLSImplicit connects with TLS, usually on port 465.
+	SMTPTLSImplicit = "implicit"
+	// SMTPTLSNone never uses TLS, for local relays only.
+	SMTPTLSNone = "none"
+)
+
+// SMTPConfig configures the email channel.
+type SMTPConfig struct {
+	Host     string `yaml:"host" env:"NOTIFICATION_SMTP_HOST"`
+	Port     int    `yaml:"port" env:"NOTIFICATION_SMTP_PORT" default:"587"`
+	Username string `yaml:"username" env:"NOTIFICATION_SMTP_USERNAME"`
+	Password string `yaml:"password" env:"NOTIFICATION_SMTP_PASSWORD"`
+	From     string `yaml:"from" env:"NOTIFICATION_SMTP_FROM"`
+	FromName string `yaml:"from_name" env:"NOTIFICATION_SMTP_FROM_NAME"`
+	TLS      string `yaml:"tls" env:"NOTIFICATION_SMTP_TLS"`
+	// InsecureSkipVerify disables certificate verification, for testing only.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify" env:"NOTIFICATION_SMTP_INSECURE_SKIP_VERIFY"`
+}
+
+// EmailChannel delivers notifications over SMTP.
+type EmailChannel struct {
+	config SMTPConfig
+	now    func() time.Time
+}
+
+// NewEmailChannel creates an SMTP email channel.
+func NewEmailChannel(config SMTPConfig) (*EmailChannel, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, fmt.Errorf("%w: smtp host and from are required", ErrInvalidInput)
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("%w: invalid smtp from address: %v", ErrInvalidInput, err)
+	}
+	switch config.TLS {
+	case SMTPTLSOpportunistic, SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return nil, fmt.Errorf("%w: invalid smtp tls mode %q", ErrInvalidInput, config.TLS)
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	return &EmailChannel{config: config, now: time.Now}, nil
+}
+
+// Type implements Channel.
+func (c *EmailChannel) Type() ChannelType {
+	return ChannelEmail
+}
+
+// Send implements Channel.
+func (c *EmailChannel) Send(ctx context.Context, delivery *Delivery) error {
+	to, err := mail.ParseAddress(delivery.Recipient.Email)
+	if err != nil {
+		return Permanent(fmt.Errorf("%w: invalid email %q", ErrInvalidInput, delivery.Recipient.Email))
+	}
+
+	message, err := c.buildMessage(delivery, to)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	client, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(c.config.From); err != nil {
+		return classifySMTPError(err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return classifySMTPError(err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return classifySMTPError(err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("%w: %v", ErrConnection, err)
+	}
+	if err := w.Close(); err != nil {
+		return classifySMTPError(err)
+	}
+	return client.Quit()
+}
+
+// dial connects, upgrades to TLS and authenticates.
+func (c *EmailChannel) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	tlsConfig := &tls.Config{
+		ServerName:         c.config.Host,
+		InsecureSkipVerify: c.config.InsecureSkipVerify,
+	}
+
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if c.config.TLS == SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConnection, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %v", ErrConnection, err)
+	}
+
+	if c.config.TLS == SMTPTLSOpportunistic || c.config.TLS == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("%w: starttls: %v", ErrConnection, err)
+			}
+		} else if c.config.TLS == SMTPTLSStartTLS {
+			client.Close()
+			return nil, Permanent(fmt.Errorf("%w: server does not support STARTTLS", ErrConnection))
+		}
+	}
+
+	if c.config.Username != "" {
+		auth := smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, Permanent(fmt.Errorf("smtp auth: %w", err))
+		}
+	}
+	return client, nil
+}
+
+// buildMessage renders an RFC 5322 message, multipart/alternative when the
+// delivery has an HTML body.
+func (c *EmailChannel) buildMessage(delivery *Delivery, to *mail.Address) ([]byte, error) {
+	from := &mail.Address{Name: c.config.FromName, Address: c.config.From}
+	if to.Name == "" {
+		to.Name = delivery.Recipient.Name
+	}
+
+	var buf bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", to.String())
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", delivery.Subject))
+	header.Set("Date", c.now().Format(time.RFC1123Z))
+	header.Set("Message-ID", fmt.Sprintf("<%s@%s>", delivery.ID, c.config.Host))
+	header.Set("MIME-Version", "1.0")
+
+	if delivery.HTML == "" {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(&buf, header)
+		if err := writeQuotedPrintable(&buf, delivery.Body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", delivery.Body},
+		{"text/html; charset=utf-8", delivery.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	header.Set("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	writeHeader(&buf, header)
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// classifySMTPError marks 5xx replies as permanent.
+func classifySMTPError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return Permanent(fmt.Errorf("smtp: %w", err))
+	}
+	return fmt.Errorf("%w: smtp: %v", ErrConnection, err)
+}
@@ -9,8 +9,12 @@ var (
 	ErrTimeout      = errors.New("notification: operation timeout")
 	ErrConnection   = errors.New("notification: connection failed")
 	ErrNotSupported = errors.New("notification: operation not supported")
-	
-	// TODO: Add your specific errors here
+
+	ErrTemplateNotFound = errors.New("notification: template not found")
+	ErrChannelNotFound  = errors.New("notification: channel not registered")
+	ErrDuplicate        = errors.New("notification: duplicate notification suppressed")
+	ErrThrottled        = errors.New("notification: notification throttled")
+	ErrDisabled         = errors.New("notification: service disabled")
 )
 
 // IsNotFound checks if the error is a "not found" error.
@@ -23,4 +27,26 @@ func IsTimeout(err error) bool {
 	return errors.Is(err, ErrTimeout)
 }
 
-// TODO: Add more error checking functions as needed
+// permanentError marks a delivery error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that the delivery is not retried, e.g. for an
+// invalid address or a 4xx webhook response.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent checks if the error was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
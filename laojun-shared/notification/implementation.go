@@ -3,40 +3,358 @@ package notification
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
 )
 
-// NotificationImpl implements the NOTIFICATION interface.
-type NotificationImpl struct {
-	config Config
-	mu     sync.RWMutex
-	// TODO: Add your implementation fields here
+// Option configures a Service.
+type Option func(*Service)
+
+// WithChannel registers a delivery channel, replacing a channel of the
+// same type.
+func WithChannel(channel Channel) Option {
+	return func(s *Service) {
+		s.channels[channel.Type()] = channel
+	}
+}
+
+// WithTemplates sets the template registry.
+func WithTemplates(templates *TemplateRegistry) Option {
+	return func(s *Service) {
+		s.templates = templates
+	}
 }
 
-// New creates a new notification instance.
-func New(config Config) NOTIFICATION {
+// WithPreferences sets the user channel preference store.
+func WithPreferences(store PreferenceStore) Option {
+	return func(s *Service) {
+		s.preferences = store
+	}
+}
+
+// WithDeadLetterStore sets the store receiving failed deliveries.
+func WithDeadLetterStore(store DeadLetterStore) Option {
+	return func(s *Service) {
+		s.deadLetters = store
+	}
+}
+
+// WithLimiter sets the deduplication and throttling backend, e.g. a
+// shared store when several instances send notifications.
+func WithLimiter(limiter Limiter) Option {
+	return func(s *Service) {
+		s.limiter = limiter
+	}
+}
+
+// Service is the Notifier implementation. It resolves channels from user
+// preferences, renders localized templates, retries failed deliveries with
+// exponential backoff and moves exhausted deliveries to a dead letter store.
+type Service struct {
+	config      Config
+	mu          sync.RWMutex
+	channels    map[ChannelType]Channel
+	templates   *TemplateRegistry
+	preferences PreferenceStore
+	deadLetters DeadLetterStore
+	limiter     Limiter
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// New creates a notification service. Email and webhook channels are
+// created from Config.SMTP and Config.Webhook unless registered by options.
+func New(config Config, opts ...Option) (*Service, error) {
 	if err := config.Validate(); err != nil {
-		panic(fmt.Sprintf("invalid config: %v", err))
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	s := &Service{
+		config:      config,
+		channels:    make(map[ChannelType]Channel),
+		deadLetters: NewMemoryDeadLetterStore(),
+		limiter:     NewMemoryLimiter(),
+		now:         time.Now,
+		sleep:       sleepContext,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.templates == nil {
+		s.templates = NewTemplateRegistry(config.DefaultLocale)
+	}
+
+	if _, ok := s.channels[ChannelEmail]; !ok && config.SMTP.Host != "" {
+		email, err := NewEmailChannel(config.SMTP)
+		if err != nil {
+			return nil, err
+		}
+		s.channels[ChannelEmail] = email
+	}
+	if _, ok := s.channels[ChannelWebhook]; !ok && config.Webhook.URL != "" {
+		s.channels[ChannelWebhook] = NewWebhookChannel(config.Webhook, nil)
+	}
+	return s, nil
+}
+
+// Templates returns the template registry.
+func (s *Service) Templates() *TemplateRegistry {
+	return s.templates
+}
+
+// RegisterChannel registers a delivery channel at runtime.
+func (s *Service) RegisterChannel(channel Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channel.Type()] = channel
+}
+
+func (s *Service) channel(channelType ChannelType) (Channel, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	channel, ok := s.channels[channelType]
+	return channel, ok
+}
+
+// Send implements Notifier.
+func (s *Service) Send(ctx context.Context, msg *Message) (*Result, error) {
+	if !s.config.Enabled {
+		return nil, ErrDisabled
+	}
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	result := &Result{}
+	for _, recipient := range msg.Recipients {
+		result.Deliveries = append(result.Deliveries, s.sendTo(ctx, msg, recipient)...)
+	}
+	return result, nil
+}
+
+// sendTo delivers a message to one recipient on all resolved channels.
+func (s *Service) sendTo(ctx context.Context, msg *Message, recipient Recipient) []DeliveryResult {
+	skipped := func(status DeliveryStatus, err error) []DeliveryResult {
+		return []DeliveryResult{{UserID: recipient.UserID, Status: status, Error: err}}
+	}
+
+	// limiter failures fail open: a notification is better sent twice than lost
+	if msg.DedupKey != "" && s.config.DedupWindow > 0 {
+		key := "dedup:" + msg.DedupKey + ":" + recipientKey(recipient)
+		if first, err := s.limiter.FirstSeen(ctx, key, s.config.DedupWindow); err == nil && !first {
+			return skipped(StatusDuplicate, ErrDuplicate)
+		}
+	}
+	if msg.ThrottleKey != "" && s.config.ThrottleLimit > 0 && s.config.ThrottleWindow > 0 {
+		key := "throttle:" + msg.ThrottleKey + ":" + recipientKey(recipient)
+		if allowed, err := s.limiter.Allow(ctx, key, s.config.ThrottleLimit, s.config.ThrottleWindow); err == nil && !allowed {
+			return skipped(StatusThrottled, ErrThrottled)
+		}
+	}
+
+	channels, err := s.resolveChannels(ctx, msg, recipient)
+	if err != nil {
+		return skipped(StatusFailed, err)
+	}
+	if len(channels) == 0 {
+		return skipped(StatusNoChannels, nil)
+	}
+
+	data := make(map[string]interface{}, len(msg.Data)+1)
+	for k, v := range msg.Data {
+		data[k] = v
+	}
+	data["Recipient"] = recipient
+	rendered, err := s.templates.Render(msg.Template, recipient.Locale, data)
+	if err != nil {
+		return skipped(StatusFailed, err)
+	}
+
+	results := make([]DeliveryResult, 0, len(channels))
+	for _, channel := range channels {
+		delivery := &Delivery{
+			ID:        uuid.NewString(),
+			Channel:   channel.Type(),
+			Template:  msg.Template,
+			Category:  msg.Category,
+			Recipient: recipient,
+			Subject:   rendered.Subject,
+			Body:      rendered.Body,
+			HTML:      rendered.HTML,
+			Data:      msg.Data,
+			CreatedAt: s.now(),
+		}
+
+		attempts, err := s.deliver(ctx, channel, delivery)
+		res := DeliveryResult{
+			Channel:  channel.Type(),
+			UserID:   recipient.UserID,
+			Status:   StatusSent,
+			Attempts: attempts,
+		}
+		if err != nil {
+			res.Status = StatusFailed
+			res.Error = err
+			s.deadLetter(ctx, delivery, attempts, err)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// resolveChannels returns the registered channels the recipient should be
+// notified on. User preferences for the category win over the defaults;
+// channels named by the message restrict the preferences.
+func (s *Service) resolveChannels(ctx context.Context, msg *Message, recipient Recipient) ([]Channel, error) {
+	candidates := msg.Channels
+	if len(candidates) == 0 {
+		candidates = s.config.DefaultChannels
+	}
+
+	if s.preferences != nil && recipient.UserID != "" {
+		preferred, ok, err := s.preferences.Channels(ctx, recipient.UserID, msg.Category)
+		if err != nil {
+			return nil, fmt.Errorf("load preferences: %w", err)
+		}
+		if ok {
+			if len(msg.Channels) > 0 {
+				preferred = intersectChannels(preferred, msg.Channels)
+			}
+			candidates = preferred
+		}
+	}
+
+	var channels []Channel
+	seen := make(map[ChannelType]bool)
+	for _, channelType := range candidates {
+		if seen[channelType] || !recipient.addressable(channelType) {
+			continue
+		}
+		seen[channelType] = true
+		if channel, ok := s.channel(channelType); ok {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
+
+// deliver sends with retries and returns the number of attempts.
+func (s *Service) deliver(ctx context.Context, channel Channel, delivery *Delivery) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := channel.Send(ctx, delivery)
+		if err == nil {
+			return attempt, nil
+		}
+		if IsPermanent(err) || attempt >= s.config.Retry.MaxAttempts {
+			return attempt, err
+		}
+		if err := s.sleep(ctx, s.config.Retry.backoff(attempt)); err != nil {
+			return attempt, fmt.Errorf("%w: %v", ErrTimeout, err)
+		}
 	}
-	
-	return &NotificationImpl{
-		config: config,
+}
+
+func (s *Service) deadLetter(ctx context.Context, delivery *Delivery, attempts int, err error) {
+	if s.deadLetters == nil {
+		return
 	}
+	// the send context may already be expired
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	s.deadLetters.Put(ctx, &DeadLetter{
+		Delivery: delivery,
+		Attempts: attempts,
+		Error:    err.Error(),
+		FailedAt: s.now(),
+	})
+}
+
+// DeadLetters returns the dead letter store.
+func (s *Service) DeadLetters() DeadLetterStore {
+	return s.deadLetters
 }
 
-// TODO: Implement your interface methods here
-// Example:
-// func (impl *NotificationImpl) Get(ctx context.Context, key string) (interface{}, error) {
-//     impl.mu.RLock()
-//     defer impl.mu.RUnlock()
-//     
-//     // TODO: Implement your logic here
-//     return nil, fmt.Errorf("not implemented")
-// }
+// Redeliver retries a dead letter once more with the regular retry policy
+// and removes it on success.
+func (s *Service) Redeliver(ctx context.Context, id string) error {
+	letter, err := s.deadLetters.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	channel, ok := s.channel(letter.Delivery.Channel)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrChannelNotFound, letter.Delivery.Channel)
+	}
 
-// Close closes the notification instance and releases resources.
-func (impl *NotificationImpl) Close() error {
-	// TODO: Implement cleanup logic here
-	return nil
+	attempts, err := s.deliver(ctx, channel, letter.Delivery)
+	if err != nil {
+		letter.Attempts += attempts
+		letter.Error = err.Error()
+		letter.FailedAt = s.now()
+		if putErr := s.deadLetters.Put(ctx, letter); putErr != nil {
+			return fmt.Errorf("%v; update dead letter: %w", err, putErr)
+		}
+		return err
+	}
+	return s.deadLetters.Delete(ctx, id)
+}
+
+// Close closes channels holding resources.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for _, channel := range s.channels {
+		if closer, ok := channel.(io.Closer); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// recipientKey identifies a recipient for dedup and throttle keys.
+func recipientKey(r Recipient) string {
+	switch {
+	case r.UserID != "":
+		return r.UserID
+	case r.Email != "":
+		return r.Email
+	default:
+		return r.Phone
+	}
+}
+
+func intersectChannels(a, b []ChannelType) []ChannelType {
+	allowed := make(map[ChannelType]bool, len(b))
+	for _, c := range b {
+		allowed[c] = true
+	}
+	var out []ChannelType
+	for _, c := range a {
+		if allowed[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,451 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// smtpStandIn is a minimal local SMTP server recording received mail.
+type smtpStandIn struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []receivedMail
+}
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{listener: listener}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) received() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.messages...)
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP stand-in")
+	var current receivedMail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			current = receivedMail{from: strings.Trim(strings.TrimSpace(line)[10:], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to := strings.Trim(strings.TrimSpace(line)[8:], "<>")
+			if strings.HasPrefix(to, "bounce@") {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			current.to = append(current.to, to)
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			current.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// flakyChannel fails a number of times before succeeding.
+type flakyChannel struct {
+	mu       sync.Mutex
+	typ      ChannelType
+	failures int
+	err      error
+	sent     []*Delivery
+}
+
+func (c *flakyChannel) Type() ChannelType { return c.typ }
+
+func (c *flakyChannel) Send(ctx context.Context, delivery *Delivery) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return c.err
+	}
+	c.sent = append(c.sent, delivery)
+	return nil
+}
+
+func newTestService(t *testing.T, opts ...Option) *Service {
+	t.Helper()
+	config := DefaultConfig()
+	config.Retry.InitialBackoff = time.Millisecond
+	config.Retry.MaxBackoff = 4 * time.Millisecond
+
+	templates := NewTemplateRegistry("zh-CN")
+	mustRegister(t, templates, "audit.completed", "zh-CN", Template{
+		Subject: "插件 {{.Plugin}} 审核完成",
+		Text:    "您好 {{.Recipient.Name}}，插件 {{.Plugin}} 的审核结果：{{.Status}}",
+	})
+	mustRegister(t, templates, "audit.completed", "en", Template{
+		Subject: "Plugin {{.Plugin}} reviewed",
+		Text:    "Hi {{.Recipient.Name}}, review result for {{.Plugin}}: {{.Status}}",
+		HTML:    "<p>Hi {{.Recipient.Name}}, review result for <b>{{.Plugin}}</b>: {{.Status}}</p>",
+	})
+
+	service, err := New(config, append([]Option{WithTemplates(templates)}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return service
+}
+
+func mustRegister(t *testing.T, r *TemplateRegistry, name, locale string, tmpl Template) {
+	t.Helper()
+	if err := r.Register(name, locale, tmpl); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+}
+
+func auditMessage(recipients ...Recipient) *Message {
+	return &Message{
+		Template:   "audit.completed",
+		Category:   "plugin_audit",
+		Recipients: recipients,
+		Data:       map[string]interface{}{"Plugin": "hello-world", "Status": "approved"},
+	}
+}
+
+func TestTemplateLocaleFallback(t *testing.T) {
+	service := newTestService(t)
+	data := map[string]interface{}{"Plugin": "p", "Status": "ok", "Recipient": Recipient{Name: "Ann"}}
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en-US", "Plugin p reviewed"},
+		{"en_us", "Plugin p reviewed"},
+		{"zh-TW", "插件 p 审核完成"},
+		{"", "插件 p 审核完成"},
+		{"fr", "插件 p 审核完成"},
+	}
+	for _, tt := range tests {
+		rendered, err := service.Templates().Render("audit.completed", tt.locale, data)
+		if err != nil {
+			t.Fatalf("Render(%q) error = %v", tt.locale, err)
+		}
+		if rendered.Subject != tt.want {
+			t.Errorf("Render(%q) subject = %q, want %q", tt.locale, rendered.Subject, tt.want)
+		}
+	}
+
+	if _, err := service.Templates().Render("missing", "en", nil); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestTemplateLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/en/welcome.subject.tmpl": {Data: []byte("Welcome {{.Name}}\n")},
+		"templates/en/welcome.txt.tmpl":     {Data: []byte("Hello {{.Name}}")},
+		"templates/en/welcome.html.tmpl":    {Data: []byte("<h1>Hello {{.Name}}</h1>")},
+		"templates/en/README.md":            {Data: []byte("ignored")},
+	}
+	registry := NewTemplateRegistry("en")
+	if err := registry.LoadFS(fsys, "templates"); err != nil {
+		t.Fatalf("LoadFS() error = %v", err)
+	}
+
+	rendered, err := registry.Render("welcome", "en", map[string]string{"Name": "<Bob>"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if rendered.Subject != "Welcome <Bob>" || rendered.HTML != "<h1>Hello &lt;Bob&gt;</h1>" {
+		t.Errorf("unexpected rendering %+v", rendered)
+	}
+}
+
+func TestEmailChannelWithSMTPStandIn(t *testing.T) {
+	server := newSMTPStandIn(t)
+	email, err := NewEmailChannel(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		From:     "noreply@laojun.dev",
+		FromName: "Laojun",
+	})
+	if err != nil {
+		t.Fatalf("NewEmailChannel() error = %v", err)
+	}
+	service := newTestService(t, WithChannel(email))
+
+	msg := auditMessage(
+		Recipient{UserID: "u1", Name: "Ann", Email: "ann@example.com", Locale: "en"},
+		Recipient{UserID: "u2", Email: "bounce@example.com"},
+	)
+	msg.Channels = []ChannelType{ChannelEmail}
+	result, err := service.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if result.Sent() != 1 {
+		t.Fatalf("expected 1 sent email, got %+v", result.Deliveries)
+	}
+	failed := result.Failed()
+	if len(failed) != 1 || failed[0].Attempts != 1 || !IsPermanent(failed[0].Error) {
+		t.Errorf("expected the 550 rejection to fail permanently without retry, got %+v", failed)
+	}
+
+	mails := server.received()
+	if len(mails) != 1 || mails[0].to[0] != "ann@example.com" {
+		t.Fatalf("unexpected mails %+v", mails)
+	}
+	data := mails[0].data
+	for _, want := range []string{"Subject: Plugin hello-world reviewed", "multipart/alternative", "<b>hello-world</b>"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("mail does not contain %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestWebhookChannelSignsAndClassifiesStatus(t *testing.T) {
+	var mu sync.Mutex
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	var signature, timestamp string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		timestamp = r.Header.Get(WebhookTimestampHeader)
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookChannel(WebhookConfig{URL: server.URL, Secret: "s3cret"}, nil)
+	service := newTestService(t, WithChannel(webhook))
+
+	msg := auditMessage(Recipient{UserID: "u1", Locale: "en"})
+	msg.Channels = []ChannelType{ChannelWebhook}
+	result, err := service.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result.Sent() != 1 || result.Deliveries[0].Attempts != 2 {
+		t.Fatalf("expected success on the second attempt, got %+v", result.Deliveries)
+	}
+
+	if signature != "sha256="+SignWebhook("s3cret", timestamp, body) {
+		t.Errorf("invalid signature %q", signature)
+	}
+	var payload Delivery
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Subject != "Plugin hello-world reviewed" {
+		t.Errorf("unexpected payload %s (%v)", body, err)
+	}
+
+	statuses = []int{http.StatusBadRequest}
+	result, _ = service.Send(context.Background(), msg)
+	if failed := result.Failed(); len(failed) != 1 || failed[0].Attempts != 1 {
+		t.Errorf("expected 4xx to fail without retry, got %+v", result.Deliveries)
+	}
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	channel := &flakyChannel{typ: ChannelSMS, failures: 5, err: ErrConnection}
+	deadLetters := NewMemoryDeadLetterStore()
+	service := newTestService(t, WithChannel(channel), WithDeadLetterStore(deadLetters))
+
+	msg := auditMessage(Recipient{UserID: "u1", Phone: "+8613800000000"})
+	msg.Channels = []ChannelType{ChannelSMS}
+	result, err := service.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if failed := result.Failed(); len(failed) != 1 || failed[0].Attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %+v", result.Deliveries)
+	}
+
+	letters, _ := deadLetters.List(context.Background(), 0)
+	if len(letters) != 1 || letters[0].Attempts != 3 {
+		t.Fatalf("expected one dead letter, got %+v", letters)
+	}
+
+	id := letters[0].Delivery.ID
+	if err := service.Redeliver(context.Background(), id); err != nil {
+		t.Fatalf("Redeliver() error = %v", err)
+	}
+	if _, err := deadLetters.Get(context.Background(), id); !IsNotFound(err) {
+		t.Errorf("expected dead letter to be removed, got %v", err)
+	}
+	if len(channel.sent) != 1 || channel.sent[0].ID != id {
+		t.Errorf("expected redelivery of %s, got %+v", id, channel.sent)
+	}
+}
+
+func TestPreferencesSelectChannels(t *testing.T) {
+	inapp := &flakyChannel{typ: ChannelInApp}
+	sms := &flakyChannel{typ: ChannelSMS}
+	prefs := NewMemoryPreferenceStore()
+	prefs.Set("u1", "plugin_audit", ChannelSMS)
+	prefs.Set("u2", AllCategories)
+	service := newTestService(t, WithChannel(inapp), WithChannel(sms), WithPreferences(prefs))
+
+	result, err := service.Send(context.Background(), auditMessage(
+		Recipient{UserID: "u1", Phone: "+8613800000000"},
+		Recipient{UserID: "u2"},
+		Recipient{UserID: "u3"},
+	))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, d := range result.Deliveries {
+		got[d.UserID] = string(d.Status) + ":" + string(d.Channel)
+	}
+	want := map[string]string{
+		"u1": "sent:sms",
+		"u2": "no_channels:",
+		"u3": "sent:inapp",
+	}
+	for user, w := range want {
+		if got[user] != w {
+			t.Errorf("%s: got %q, want %q", user, got[user], w)
+		}
+	}
+}
+
+func TestDedupAndThrottle(t *testing.T) {
+	inapp := &flakyChannel{typ: ChannelInApp}
+	service := newTestService(t, WithChannel(inapp))
+	service.config.ThrottleLimit = 2
+
+	msg := auditMessage(Recipient{UserID: "u1"})
+	msg.DedupKey = "audit:42"
+	first, _ := service.Send(context.Background(), msg)
+	second, _ := service.Send(context.Background(), msg)
+	if first.Sent() != 1 || second.Deliveries[0].Status != StatusDuplicate {
+		t.Errorf("expected duplicate suppression, got %+v then %+v", first.Deliveries, second.Deliveries)
+	}
+
+	var statuses []string
+	for i := 0; i < 3; i++ {
+		msg := auditMessage(Recipient{UserID: "u1"})
+		msg.ThrottleKey = "audit"
+		result, _ := service.Send(context.Background(), msg)
+		statuses = append(statuses, string(result.Deliveries[0].Status))
+	}
+	if strings.Join(statuses, ",") != "sent,sent,throttled" {
+		t.Errorf("unexpected throttling %v", statuses)
+	}
+}
+
+func TestInboxChannel(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	inbox, err := NewInboxChannel(db)
+	if err != nil {
+		t.Fatalf("NewInboxChannel() error = %v", err)
+	}
+	service := newTestService(t, WithChannel(inbox))
+
+	for i := 0; i < 3; i++ {
+		msg := auditMessage(Recipient{UserID: "u1"})
+		msg.Data["Plugin"] = "plugin-" + strconv.Itoa(i)
+		if _, err := service.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	messages, err := inbox.List(ctx, "u1", InboxQuery{UnreadOnly: true})
+	if err != nil || len(messages) != 3 {
+		t.Fatalf("List() = %d messages, %v", len(messages), err)
+	}
+	if err := inbox.MarkRead(ctx, "u1", messages[0].ID); err != nil {
+		t.Fatalf("MarkRead() error = %v", err)
+	}
+	if count, _ := inbox.UnreadCount(ctx, "u1"); count != 2 {
+		t.Errorf("UnreadCount() = %d, want 2", count)
+	}
+
+	// a retried delivery with the same ID is stored once
+	delivery := &Delivery{ID: messages[0].ID, Recipient: Recipient{UserID: "u1"}, CreatedAt: time.Now()}
+	if err := inbox.Send(ctx, delivery); err != nil {
+		t.Fatalf("Send() duplicate error = %v", err)
+	}
+	if all, _ := inbox.List(ctx, "u1", InboxQuery{}); len(all) != 3 {
+		t.Errorf("expected idempotent insert, got %d messages", len(all))
+	}
+}
@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InboxMessage is an in-app notification stored for a user.
+type InboxMessage struct {
+	ID        string     `json:"id" gorm:"primaryKey;size:64"`
+	UserID    string     `json:"user_id" gorm:"size:64;not null;index:idx_notification_inbox_user_created,priority:1"`
+	Category  string     `json:"category" gorm:"size:64;index"`
+	Template  string     `json:"template" gorm:"size:128"`
+	Subject   string     `json:"subject" gorm:"size:512"`
+	Body      string     `json:"body" gorm:"type:text"`
+	Data      string     `json:"data,omitempty" gorm:"type:text"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index:idx_notification_inbox_user_created,priority:2"`
+}
+
+// TableName returns the inbox table name.
+func (InboxMessage) TableName() string {
+	return "notification_inbox"
+}
+
+// InboxQuery filters inbox listings.
+type InboxQuery struct {
+	UnreadOnly bool
+	Category   string
+	Limit      int
+	Offset     int
+}
+
+// InboxChannel stores notifications in a database table read by the
+// in-app notification center.
+type InboxChannel struct {
+	db *gorm.DB
+}
+
+// NewInboxChannel creates an inbox channel and migrates its table.
+func NewInboxChannel(db *gorm.DB) (*InboxChannel, error) {
+	if err := db.AutoMigrate(&InboxMessage{}); err != nil {
+		return nil, fmt.Errorf("migrate inbox: %w", err)
+	}
+	return &InboxChannel{db: db}, nil
+}
+
+// Type implements Channel.
+func (c *InboxChannel) Type() ChannelType {
+	return ChannelInApp
+}
+
+// Send implements Channel. Retried deliveries keep their ID, so storing is
+// idempotent.
+func (c *InboxChannel) Send(ctx context.Context, delivery *Delivery) error {
+	var data string
+	if len(delivery.Data) > 0 {
+		raw, err := json.Marshal(delivery.Data)
+		if err != nil {
+			return Permanent(fmt.Errorf("marshal inbox data: %w", err))
+		}
+		data = string(raw)
+	}
+
+	message := &InboxMessage{
+		ID:        delivery.ID,
+		UserID:    delivery.Recipient.UserID,
+		Category:  delivery.Category,
+		Template:  delivery.Template,
+		Subject:   delivery.Subject,
+		Body:      delivery.Body,
+		Data:      data,
+		CreatedAt: delivery.CreatedAt,
+	}
+	return c.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(message).Error
+}
+
+// List returns a user's messages, newest first.
+func (c *InboxChannel) List(ctx context.Context, userID string, query InboxQuery) ([]InboxMessage, error) {
+	db := c.db.WithContext(ctx).Where("user_id = ?", userID)
+	if query.UnreadOnly {
+		db = db.Where("read_at IS NULL")
+	}
+	if query.Category != "" {
+		db = db.Where("category = ?", query.Category)
+	}
+	if query.Limit <= 0 {
+		query.Limit = 50
+	}
+
+	var messages []InboxMessage
+	err := db.Order("created_at DESC").Limit(query.Limit).Offset(query.Offset).Find(&messages).Error
+	return messages, err
+}
+
+// UnreadCount returns the number of unread messages of a user.
+func (c *InboxChannel) UnreadCount(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := c.db.WithContext(ctx).Model(&InboxMessage{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks messages of a user as read, all unread messages when no
+// IDs are given.
+func (c *InboxChannel) MarkRead(ctx context.Context, userID string, ids ...string) error {
+	db := c.db.WithContext(ctx).Model(&InboxMessage{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		db = db.Where("id IN ?", ids)
+	}
+	return db.Update("read_at", time.Now()).Error
+}
+
+// Delete removes messages of a user.
+func (c *InboxChannel) Delete(ctx context.Context, userID string, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return c.db.WithContext(ctx).Where("user_id = ? AND id IN ?", userID, ids).Delete(&InboxMessage{}).Error
+}
@@ -6,13 +6,175 @@ import (
 	"time"
 )
 
-// NOTIFICATION defines the interface for notification operations.
+// Notifier sends notifications to users over their preferred channels.
 // All implementations must be thread-safe.
-type NOTIFICATION interface {
-	// TODO: Define your interface methods here
-	// Example:
-	// Get(ctx context.Context, key string) (interface{}, error)
-	// Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+type Notifier interface {
+	// Send renders the message for every recipient and delivers it over
+	// the resolved channels. Per-delivery outcomes are reported in Result;
+	// the error is only set when the message itself is invalid.
+	Send(ctx context.Context, msg *Message) (*Result, error)
+	// Close releases the channels.
+	Close() error
+}
+
+// Channel delivers rendered notifications over one transport.
+type Channel interface {
+	// Type returns the channel type the channel is registered under.
+	Type() ChannelType
+	// Send delivers one notification. Errors wrapped with Permanent are
+	// not retried.
+	Send(ctx context.Context, delivery *Delivery) error
+}
+
+// ChannelType identifies a delivery channel.
+type ChannelType string
+
+// Built-in channel types.
+const (
+	ChannelEmail   ChannelType = "email"
+	ChannelWebhook ChannelType = "webhook"
+	ChannelInApp   ChannelType = "inapp"
+	ChannelSMS     ChannelType = "sms"
+)
+
+// Recipient is the addressee of a notification.
+type Recipient struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Phone  string `json:"phone,omitempty"`
+	// Locale selects the template translation, e.g. "zh-CN".
+	Locale string `json:"locale,omitempty"`
+	// WebhookURL overrides the configured webhook endpoint.
+	WebhookURL string `json:"webhook_url,omitempty"`
+}
+
+// addressable reports whether the recipient can be reached on the channel.
+func (r Recipient) addressable(channel ChannelType) bool {
+	switch channel {
+	case ChannelEmail:
+		return r.Email != ""
+	case ChannelSMS:
+		return r.Phone != ""
+	case ChannelInApp:
+		return r.UserID != ""
+	default:
+		return true
+	}
+}
+
+// Message is a notification request.
+type Message struct {
+	// Template is the registered template name.
+	Template string `json:"template"`
+	// Category groups notifications for user preferences, e.g. "plugin_audit".
+	Category   string                 `json:"category"`
+	Recipients []Recipient            `json:"recipients"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	// Channels overrides the default channels for recipients without
+	// preferences for the category.
+	Channels []ChannelType `json:"channels,omitempty"`
+	// DedupKey suppresses identical notifications to the same recipient
+	// within Config.DedupWindow.
+	DedupKey string `json:"dedup_key,omitempty"`
+	// ThrottleKey limits notifications sharing the key to
+	// Config.ThrottleLimit per recipient per Config.ThrottleWindow.
+	ThrottleKey string `json:"throttle_key,omitempty"`
+}
+
+// Validate validates the message.
+func (m *Message) Validate() error {
+	if m == nil {
+		return fmt.Errorf("%w: message is nil", ErrInvalidInput)
+	}
+	if m.Template == "" {
+		return fmt.Errorf("%w: template is required", ErrInvalidInput)
+	}
+	if len(m.Recipients) == 0 {
+		return fmt.Errorf("%w: at least one recipient is required", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Delivery is a rendered notification for one recipient on one channel.
+type Delivery struct {
+	ID        string                 `json:"id"`
+	Channel   ChannelType            `json:"channel"`
+	Template  string                 `json:"template"`
+	Category  string                 `json:"category"`
+	Recipient Recipient              `json:"recipient"`
+	Subject   string                 `json:"subject"`
+	Body      string                 `json:"body"`
+	HTML      string                 `json:"html,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// DeliveryStatus is the outcome of one delivery.
+type DeliveryStatus string
+
+// Delivery outcomes.
+const (
+	StatusSent       DeliveryStatus = "sent"
+	StatusFailed     DeliveryStatus = "failed"
+	StatusDuplicate  DeliveryStatus = "duplicate"
+	StatusThrottled  DeliveryStatus = "throttled"
+	StatusNoChannels DeliveryStatus = "no_channels"
+)
+
+// DeliveryResult reports the outcome of one delivery.
+type DeliveryResult struct {
+	Channel  ChannelType    `json:"channel,omitempty"`
+	UserID   string         `json:"user_id"`
+	Status   DeliveryStatus `json:"status"`
+	Attempts int            `json:"attempts"`
+	Error    error          `json:"-"`
+}
+
+// Result collects the delivery outcomes of a message.
+type Result struct {
+	Deliveries []DeliveryResult `json:"deliveries"`
+}
+
+// Sent returns the number of successful deliveries.
+func (r *Result) Sent() int {
+	n := 0
+	for _, d := range r.Deliveries {
+		if d.Status == StatusSent {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the failed deliveries.
+func (r *Result) Failed() []DeliveryResult {
+	var failed []DeliveryResult
+	for _, d := range r.Deliveries {
+		if d.Status == StatusFailed {
+			failed = append(failed, d)
+		}
+	}
+	return failed
+}
+
+// RetryConfig configures delivery retries with exponential backoff.
+type RetryConfig struct {
+	MaxAttempts    int           `yaml:"max_attempts" env:"NOTIFICATION_RETRY_MAX_ATTEMPTS" default:"3"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" env:"NOTIFICATION_RETRY_INITIAL_BACKOFF" default:"1s"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" env:"NOTIFICATION_RETRY_MAX_BACKOFF" default:"30s"`
+}
+
+// backoff returns the wait before the given retry, attempt starting at 1.
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	wait := c.InitialBackoff
+	for i := 1; i < attempt && wait < c.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if c.MaxBackoff > 0 && wait > c.MaxBackoff {
+		wait = c.MaxBackoff
+	}
+	return wait
 }
 
 // Config defines the configuration for notification.
@@ -20,8 +182,22 @@ type Config struct {
 	Enabled bool          `yaml:"enabled" env:"NOTIFICATION_ENABLED" default:"true"`
 	Debug   bool          `yaml:"debug" env:"NOTIFICATION_DEBUG" default:"false"`
 	Timeout time.Duration `yaml:"timeout" env:"NOTIFICATION_TIMEOUT" default:"30s"`
-	
-	// TODO: Add your specific configuration fields here
+
+	// DefaultLocale is used when a recipient has no locale or no
+	// translation exists for it.
+	DefaultLocale string `yaml:"default_locale" env:"NOTIFICATION_DEFAULT_LOCALE" default:"zh-CN"`
+	// DefaultChannels are used for recipients without preferences when
+	// the message does not name channels.
+	DefaultChannels []ChannelType `yaml:"default_channels" env:"NOTIFICATION_DEFAULT_CHANNELS"`
+
+	Retry RetryConfig `yaml:"retry"`
+
+	DedupWindow    time.Duration `yaml:"dedup_window" env:"NOTIFICATION_DEDUP_WINDOW" default:"10m"`
+	ThrottleLimit  int           `yaml:"throttle_limit" env:"NOTIFICATION_THROTTLE_LIMIT" default:"5"`
+	ThrottleWindow time.Duration `yaml:"throttle_window" env:"NOTIFICATION_THROTTLE_WINDOW" default:"1h"`
+
+	SMTP    SMTPConfig    `yaml:"smtp"`
+	Webhook WebhookConfig `yaml:"webhook"`
 }
 
 // Validate validates the configuration.
@@ -29,15 +205,39 @@ func (c *Config) Validate() error {
 	if c.Timeout <= 0 {
 		return fmt.Errorf("timeout must be positive")
 	}
-	// TODO: Add your validation logic here
+	if c.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("retry.max_attempts must be at least 1")
+	}
+	if c.Retry.InitialBackoff < 0 || c.Retry.MaxBackoff < 0 {
+		return fmt.Errorf("retry backoff must be non-negative")
+	}
+	if c.DedupWindow < 0 || c.ThrottleWindow < 0 || c.ThrottleLimit < 0 {
+		return fmt.Errorf("dedup and throttle settings must be non-negative")
+	}
+	if c.SMTP.Host != "" && c.SMTP.From == "" {
+		return fmt.Errorf("smtp.from is required when smtp.host is set")
+	}
 	return nil
 }
 
 // DefaultConfig returns the default configuration.
 func DefaultConfig() Config {
 	return Config{
-		Enabled: true,
-		Debug:   false,
-		Timeout: 30 * time.Second,
+		Enabled:         true,
+		Debug:           false,
+		Timeout:         30 * time.Second,
+		DefaultLocale:   "zh-CN",
+		DefaultChannels: []ChannelType{ChannelInApp},
+		Retry: RetryConfig{
+			MaxAttempts:    3,
+			InitialBackoff: time.Second,
+			MaxBackoff:     30 * time.Second,
+		},
+		DedupWindow:    10 * time.Minute,
+		ThrottleLimit:  5,
+		ThrottleWindow: time.Hour,
+		SMTP: SMTPConfig{
+			Port: 587,
+		},
 	}
 }
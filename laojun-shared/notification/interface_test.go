@@ -1,52 +1,93 @@
 package notification
 
 import (
-	"context"
 	"testing"
 	"time"
 )
 
 func TestNew(t *testing.T) {
 	config := DefaultConfig()
-	
-	impl := New(config)
+
+	impl, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
 	if impl == nil {
 		t.Fatal("New() returned nil")
 	}
-	
-	// TODO: Add more tests
+	if _, ok := impl.channel(ChannelEmail); ok {
+		t.Error("email channel must not be created without smtp host")
+	}
+
+	config.SMTP = SMTPConfig{Host: "localhost", Port: 2525, From: "noreply@laojun.dev"}
+	config.Webhook = WebhookConfig{URL: "http://localhost/hook"}
+	impl, err = New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, channel := range []ChannelType{ChannelEmail, ChannelWebhook} {
+		if _, ok := impl.channel(channel); !ok {
+			t.Errorf("expected %s channel from config", channel)
+		}
+	}
 }
 
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
-		config  Config
+		config  func() Config
 		wantErr bool
 	}{
 		{
 			name:    "valid config",
-			config:  DefaultConfig(),
+			config:  DefaultConfig,
 			wantErr: false,
 		},
 		{
 			name: "invalid timeout",
-			config: Config{
-				Enabled: true,
-				Timeout: -1,
+			config: func() Config {
+				c := DefaultConfig()
+				c.Timeout = -1
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "no attempts",
+			config: func() Config {
+				c := DefaultConfig()
+				c.Retry.MaxAttempts = 0
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "smtp without from",
+			config: func() Config {
+				c := DefaultConfig()
+				c.SMTP.Host = "smtp.example.com"
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative throttle window",
+			config: func() Config {
+				c := DefaultConfig()
+				c.ThrottleWindow = -time.Second
+				return c
 			},
 			wantErr: true,
 		},
-		// TODO: Add more test cases
 	}
-	
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.config.Validate()
+			config := tt.config()
+			err := config.Validate()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
-
-// TODO: Add more tests for your interface methods
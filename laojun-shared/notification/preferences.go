@@ -0,0 +1,57 @@
+package notification
+
+import (
+	"context"
+	"sync"
+)
+
+// AllCategories is the preference category matching every category without
+// a specific preference.
+const AllCategories = "*"
+
+// PreferenceStore returns the channels a user wants for a category.
+type PreferenceStore interface {
+	// Channels returns the enabled channels and whether the user has a
+	// preference for the category. An empty list with ok=true opts out.
+	Channels(ctx context.Context, userID, category string) (channels []ChannelType, ok bool, err error)
+}
+
+// MemoryPreferenceStore keeps preferences in memory.
+type MemoryPreferenceStore struct {
+	mu    sync.RWMutex
+	prefs map[string]map[string][]ChannelType
+}
+
+// NewMemoryPreferenceStore creates an empty preference store.
+func NewMemoryPreferenceStore() *MemoryPreferenceStore {
+	return &MemoryPreferenceStore{prefs: make(map[string]map[string][]ChannelType)}
+}
+
+// Set stores the channels of a user for a category, AllCategories sets
+// the user's default.
+func (s *MemoryPreferenceStore) Set(userID, category string, channels ...ChannelType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prefs[userID] == nil {
+		s.prefs[userID] = make(map[string][]ChannelType)
+	}
+	s.prefs[userID][category] = append([]ChannelType{}, channels...)
+}
+
+// Channels implements PreferenceStore.
+func (s *MemoryPreferenceStore) Channels(ctx context.Context, userID, category string) ([]ChannelType, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.prefs[userID]
+	if !ok {
+		return nil, false, nil
+	}
+	if channels, ok := user[category]; ok {
+		return append([]ChannelType{}, channels...), true, nil
+	}
+	if channels, ok := user[AllCategories]; ok {
+		return append([]ChannelType{}, channels...), true, nil
+	}
+	return nil, false, nil
+}
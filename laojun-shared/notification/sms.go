@@ -0,0 +1,77 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SMSProvider sends text messages through an SMS gateway. Implementations
+// wrap vendor APIs such as Aliyun or Tencent Cloud SMS.
+type SMSProvider interface {
+	Send(ctx context.Context, phone, text string) error
+}
+
+// SMSChannel delivers notification bodies as text messages.
+type SMSChannel struct {
+	provider SMSProvider
+	// maxLength truncates long bodies, 0 keeps them intact.
+	maxLength int
+}
+
+// NewSMSChannel creates an SMS channel. Bodies longer than maxLength runes
+// are truncated when maxLength is positive.
+func NewSMSChannel(provider SMSProvider, maxLength int) *SMSChannel {
+	return &SMSChannel{provider: provider, maxLength: maxLength}
+}
+
+// Type implements Channel.
+func (c *SMSChannel) Type() ChannelType {
+	return ChannelSMS
+}
+
+// Send implements Channel.
+func (c *SMSChannel) Send(ctx context.Context, delivery *Delivery) error {
+	if delivery.Recipient.Phone == "" {
+		return Permanent(fmt.Errorf("%w: no phone number", ErrInvalidInput))
+	}
+	text := strings.TrimSpace(delivery.Body)
+	if runes := []rune(text); c.maxLength > 0 && len(runes) > c.maxLength {
+		text = string(runes[:c.maxLength])
+	}
+	return c.provider.Send(ctx, delivery.Recipient.Phone, text)
+}
+
+// SentSMS is a message recorded by StubSMSProvider.
+type SentSMS struct {
+	Phone string
+	Text  string
+}
+
+// StubSMSProvider records messages instead of sending them, for development
+// environments and tests until a vendor provider is configured.
+type StubSMSProvider struct {
+	mu   sync.Mutex
+	sent []SentSMS
+	// Err is returned by Send when set.
+	Err error
+}
+
+// Send implements SMSProvider.
+func (p *StubSMSProvider) Send(ctx context.Context, phone, text string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Err != nil {
+		return p.Err
+	}
+	p.sent = append(p.sent, SentSMS{Phone: phone, Text: text})
+	return nil
+}
+
+// Sent returns the recorded messages.
+func (p *StubSMSProvider) Sent() []SentSMS {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]SentSMS(nil), p.sent...)
+}
@@ -0,0 +1,231 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+// Template is the source of a localized notification template. Subject and
+// Text use text/template, HTML uses html/template and is optional.
+type Template struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Rendered is the output of a template.
+type Rendered struct {
+	Subject string
+	Body    string
+	HTML    string
+}
+
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// TemplateRegistry stores templates by name and locale.
+type TemplateRegistry struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	templates     map[string]map[string]*compiledTemplate
+	funcs         texttemplate.FuncMap
+}
+
+// NewTemplateRegistry creates a registry falling back to defaultLocale.
+func NewTemplateRegistry(defaultLocale string) *TemplateRegistry {
+	return &TemplateRegistry{
+		defaultLocale: normalizeLocale(defaultLocale),
+		templates:     make(map[string]map[string]*compiledTemplate),
+		funcs: texttemplate.FuncMap{
+			"default": func(def, value interface{}) interface{} {
+				if value == nil || value == "" {
+					return def
+				}
+				return value
+			},
+			"upper": strings.ToUpper,
+			"lower": strings.ToLower,
+		},
+	}
+}
+
+// Register compiles and stores a template for a locale.
+func (r *TemplateRegistry) Register(name, locale string, tmpl Template) error {
+	if name == "" || tmpl.Text == "" {
+		return fmt.Errorf("%w: template name and text are required", ErrInvalidInput)
+	}
+
+	compiled := &compiledTemplate{}
+	var err error
+	if compiled.subject, err = texttemplate.New(name + ".subject").Funcs(r.funcs).Option("missingkey=zero").Parse(tmpl.Subject); err != nil {
+		return fmt.Errorf("parse %s subject: %w", name, err)
+	}
+	if compiled.text, err = texttemplate.New(name + ".text").Funcs(r.funcs).Option("missingkey=zero").Parse(tmpl.Text); err != nil {
+		return fmt.Errorf("parse %s text: %w", name, err)
+	}
+	if tmpl.HTML != "" {
+		if compiled.html, err = htmltemplate.New(name + ".html").Funcs(htmltemplate.FuncMap(r.funcs)).Parse(tmpl.HTML); err != nil {
+			return fmt.Errorf("parse %s html: %w", name, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.templates[name] == nil {
+		r.templates[name] = make(map[string]*compiledTemplate)
+	}
+	r.templates[name][normalizeLocale(locale)] = compiled
+	return nil
+}
+
+// LoadFS registers templates laid out as <root>/<locale>/<name>.subject.tmpl,
+// <name>.txt.tmpl and optionally <name>.html.tmpl.
+func (r *TemplateRegistry) LoadFS(fsys fs.FS, root string) error {
+	locales, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return fmt.Errorf("read template dir: %w", err)
+	}
+
+	for _, localeDir := range locales {
+		if !localeDir.IsDir() {
+			continue
+		}
+		locale := localeDir.Name()
+		dir := path.Join(root, locale)
+		files, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return fmt.Errorf("read template dir %s: %w", dir, err)
+		}
+
+		templates := make(map[string]*Template)
+		for _, file := range files {
+			name, part, ok := splitTemplateFile(file.Name())
+			if !ok {
+				continue
+			}
+			data, err := fs.ReadFile(fsys, path.Join(dir, file.Name()))
+			if err != nil {
+				return fmt.Errorf("read template %s: %w", file.Name(), err)
+			}
+			tmpl := templates[name]
+			if tmpl == nil {
+				tmpl = &Template{}
+				templates[name] = tmpl
+			}
+			switch part {
+			case "subject":
+				tmpl.Subject = strings.TrimSpace(string(data))
+			case "txt":
+				tmpl.Text = string(data)
+			case "html":
+				tmpl.HTML = string(data)
+			}
+		}
+
+		for name, tmpl := range templates {
+			if err := r.Register(name, locale, *tmpl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitTemplateFile splits "name.part.tmpl" into name and part.
+func splitTemplateFile(file string) (name, part string, ok bool) {
+	base := strings.TrimSuffix(file, ".tmpl")
+	if base == file {
+		return "", "", false
+	}
+	idx := strings.LastIndexByte(base, '.')
+	if idx <= 0 {
+		return "", "", false
+	}
+	name, part = base[:idx], base[idx+1:]
+	switch part {
+	case "subject", "txt", "html":
+		return name, part, true
+	}
+	return "", "", false
+}
+
+// Render renders a template for locale, falling back from "zh-CN" to "zh"
+// and then to the default locale.
+func (r *TemplateRegistry) Render(name, locale string, data interface{}) (*Rendered, error) {
+	compiled, err := r.lookup(name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	var rendered Rendered
+	var buf bytes.Buffer
+	if err := compiled.subject.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	rendered.Subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := compiled.text.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render %s text: %w", name, err)
+	}
+	rendered.Body = buf.String()
+
+	if compiled.html != nil {
+		buf.Reset()
+		if err := compiled.html.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("render %s html: %w", name, err)
+		}
+		rendered.HTML = buf.String()
+	}
+	return &rendered, nil
+}
+
+func (r *TemplateRegistry) lookup(name, locale string) (*compiledTemplate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	locales, ok := r.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	for _, candidate := range localeFallbacks(normalizeLocale(locale), r.defaultLocale) {
+		if compiled, ok := locales[candidate]; ok {
+			return compiled, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s (%s)", ErrTemplateNotFound, name, locale)
+}
+
+// localeFallbacks returns the lookup order for a locale.
+func localeFallbacks(locale, defaultLocale string) []string {
+	var candidates []string
+	for _, l := range []string{locale, defaultLocale} {
+		if l == "" {
+			continue
+		}
+		candidates = append(candidates, l)
+		if idx := strings.IndexByte(l, '-'); idx > 0 {
+			candidates = append(candidates, l[:idx])
+		}
+	}
+	return append(candidates, "")
+}
+
+// normalizeLocale formats locales as "zh-CN".
+func normalizeLocale(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	if idx := strings.IndexByte(locale, '-'); idx > 0 {
+		return strings.ToLower(locale[:idx]) + "-" + strings.ToUpper(locale[idx+1:])
+	}
+	return strings.ToLower(locale)
+}
@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Webhook request headers.
+const (
+	WebhookSignatureHeader = "X-Notification-Signature"
+	WebhookTimestampHeader = "X-Notification-Timestamp"
+	WebhookIDHeader        = "X-Notification-ID"
+)
+
+// WebhookConfig configures the webhook channel.
+type WebhookConfig struct {
+	// URL is the default endpoint, Recipient.WebhookURL overrides it.
+	URL string `yaml:"url" env:"NOTIFICATION_WEBHOOK_URL"`
+	// Secret signs requests with HMAC-SHA256 over "<timestamp>.<body>".
+	Secret  string            `yaml:"secret" env:"NOTIFICATION_WEBHOOK_SECRET"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// WebhookChannel posts notifications as JSON to an HTTP endpoint.
+type WebhookChannel struct {
+	config WebhookConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebhookChannel creates a webhook channel. A nil client uses a client
+// with a 10 second timeout.
+func NewWebhookChannel(config WebhookConfig, client *http.Client) *WebhookChannel {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookChannel{config: config, client: client, now: time.Now}
+}
+
+// Type implements Channel.
+func (c *WebhookChannel) Type() ChannelType {
+	return ChannelWebhook
+}
+
+// Send implements Channel. 2xx responses succeed, 429 and 5xx are retried
+// and other statuses fail permanently.
+func (c *WebhookChannel) Send(ctx context.Context, delivery *Delivery) error {
+	url := delivery.Recipient.WebhookURL
+	if url == "" {
+		url = c.config.URL
+	}
+	if url == "" {
+		return Permanent(fmt.Errorf("%w: no webhook url", ErrInvalidInput))
+	}
+
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return Permanent(fmt.Errorf("marshal webhook payload: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(fmt.Errorf("%w: %v", ErrInvalidInput, err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, delivery.ID)
+	for k, v := range c.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if c.config.Secret != "" {
+		timestamp := strconv.FormatInt(c.now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(c.config.Secret, timestamp, body))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrConnection, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("%w: webhook returned %d", ErrConnection, resp.StatusCode)
+	default:
+		return Permanent(fmt.Errorf("webhook returned %d", resp.StatusCode))
+	}
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>", used by
+// receivers to verify WebhookSignatureHeader.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
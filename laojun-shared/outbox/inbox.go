@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errDuplicate 用于在重复消息时回滚事务
+var errDuplicate = errors.New("duplicate message")
+
+// InboxMessage 消费者已处理的消息
+type InboxMessage struct {
+	Consumer    string    `gorm:"primaryKey;size:128" json:"consumer"`
+	EventID     string    `gorm:"primaryKey;size:64" json:"event_id"`
+	ProcessedAt time.Time `gorm:"index" json:"processed_at"`
+}
+
+// TableName 指定表名
+func (InboxMessage) TableName() string {
+	return "inbox_messages"
+}
+
+// Inbox 幂等消费者：记录已处理的事件ID，重复投递的消息不再处理
+type Inbox struct {
+	db       *gorm.DB
+	consumer string
+}
+
+// NewInbox 创建收件箱，consumer 区分不同的消费者，同一事件可被多个消费者各处理一次
+func NewInbox(db *gorm.DB, consumer string) *Inbox {
+	return &Inbox{db: db, consumer: consumer}
+}
+
+// Handle 在事务中记录事件并执行 fn，事件已处理过时不执行 fn 并返回 false。
+// fn 返回错误时事务回滚，事件可被再次处理
+func (i *Inbox) Handle(ctx context.Context, eventID string, fn func(tx *gorm.DB) error) (bool, error) {
+	err := i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&InboxMessage{
+			Consumer:    i.consumer,
+			EventID:     eventID,
+			ProcessedAt: time.Now(),
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to record inbox message: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errDuplicate
+		}
+		return fn(tx)
+	})
+
+	if errors.Is(err, errDuplicate) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Processed 判断事件是否已处理
+func (i *Inbox) Processed(ctx context.Context, eventID string) (bool, error) {
+	var count int64
+	err := i.db.WithContext(ctx).Model(&InboxMessage{}).
+		Where("consumer = ? AND event_id = ?", i.consumer, eventID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Purge 删除早于 olderThan 的处理记录，olderThan 应大于消息可能重复投递的最长时间
+func (i *Inbox) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result := i.db.WithContext(ctx).
+		Where("consumer = ? AND processed_at < ?", i.consumer, time.Now().Add(-olderThan)).
+		Delete(&InboxMessage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,158 @@
+// Package outbox 实现事务性发件箱和幂等消费者。
+//
+// 业务代码在同一个 gorm 事务中写业务数据和事件（Enqueue），事务提交后由
+// Relay 异步发布，保证事件不因进程在提交和发布之间崩溃而丢失。Relay 提供
+// 至少一次投递，同一 AggregateKey 的消息按写入顺序发布；消费者使用 Inbox
+// 对重复投递去重。
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 发件箱错误
+var (
+	// ErrInvalidMessage 消息缺少主题或载荷不是合法JSON
+	ErrInvalidMessage = errors.New("invalid outbox message")
+	// ErrEmptyReplayFilter 重放条件为空
+	ErrEmptyReplayFilter = errors.New("replay filter must select messages")
+)
+
+// 消息状态
+const (
+	StatusPending   = "pending"
+	StatusPublished = "published"
+	StatusFailed    = "failed" // 超过最大重试次数，需人工重放
+)
+
+// OutboxMessage 发件箱表中的消息
+type OutboxMessage struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventID       string     `gorm:"size:64;uniqueIndex;not null" json:"event_id"`
+	Topic         string     `gorm:"size:255;not null;index" json:"topic"`
+	AggregateKey  string     `gorm:"size:255;not null;default:'';index" json:"aggregate_key"`
+	Payload       []byte     `gorm:"not null" json:"payload"`
+	Headers       string     `gorm:"type:text" json:"headers,omitempty"`
+	Status        string     `gorm:"size:16;not null;index" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+}
+
+// TableName 指定表名
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
+
+// HeaderMap 解析消息头
+func (m *OutboxMessage) HeaderMap() map[string]string {
+	headers := map[string]string{}
+	if m.Headers != "" {
+		json.Unmarshal([]byte(m.Headers), &headers)
+	}
+	return headers
+}
+
+// Message 待写入发件箱的事件
+type Message struct {
+	// EventID 事件ID，为空时自动生成；消费者以此去重
+	EventID string
+	// Topic 发布主题
+	Topic string
+	// AggregateKey 聚合键，如订单ID；同一键的消息按写入顺序发布，为空时不保证顺序
+	AggregateKey string
+	// Payload 载荷，[]byte 和 json.RawMessage 须为合法JSON，其他类型序列化为JSON
+	Payload interface{}
+	// Headers 附加信息，如追踪ID
+	Headers map[string]string
+}
+
+// AutoMigrate 创建发件箱和收件箱表
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(&OutboxMessage{}, &InboxMessage{})
+}
+
+// Enqueue 在事务 tx 中写入事件，事务提交后由 Relay 发布：
+//
+//	err := db.Transaction(func(tx *gorm.DB) error {
+//		if err := tx.Create(&order).Error; err != nil {
+//			return err
+//		}
+//		return outbox.Enqueue(tx, outbox.Message{
+//			Topic:        "payment.order.created",
+//			AggregateKey: order.ID,
+//			Payload:      order,
+//		})
+//	})
+func Enqueue(tx *gorm.DB, messages ...Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]OutboxMessage, 0, len(messages))
+	for _, message := range messages {
+		row, err := newOutboxMessage(message, now)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to enqueue outbox messages: %w", err)
+	}
+	return nil
+}
+
+func newOutboxMessage(message Message, now time.Time) (OutboxMessage, error) {
+	if message.Topic == "" {
+		return OutboxMessage{}, fmt.Errorf("%w: topic is required", ErrInvalidMessage)
+	}
+
+	var payload []byte
+	switch p := message.Payload.(type) {
+	case []byte:
+		payload = p
+	case json.RawMessage:
+		payload = p
+	default:
+		data, err := json.Marshal(p)
+		if err != nil {
+			return OutboxMessage{}, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+		payload = data
+	}
+	if !json.Valid(payload) {
+		return OutboxMessage{}, fmt.Errorf("%w: payload of %s is not valid JSON", ErrInvalidMessage, message.Topic)
+	}
+
+	row := OutboxMessage{
+		EventID:       message.EventID,
+		Topic:         message.Topic,
+		AggregateKey:  message.AggregateKey,
+		Payload:       payload,
+		Status:        StatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if row.EventID == "" {
+		row.EventID = uuid.NewString()
+	}
+	if len(message.Headers) > 0 {
+		headers, err := json.Marshal(message.Headers)
+		if err != nil {
+			return OutboxMessage{}, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+		row.Headers = string(headers)
+	}
+	return row, nil
+}
@@ -0,0 +1,250 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type order struct {
+	ID     string `gorm:"primaryKey"`
+	Amount int
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "outbox.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&order{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	return db
+}
+
+// recordingPublisher 记录发布的消息，可按主题注入失败
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published []string
+	failTopic string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, message *OutboxMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if message.Topic == p.failTopic {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, message.Topic+"/"+message.AggregateKey)
+	return nil
+}
+
+func TestEnqueueIsTransactional(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		tx.Create(&order{ID: "o-1", Amount: 10})
+		if err := Enqueue(tx, Message{Topic: "order.created", AggregateKey: "o-1", Payload: map[string]int{"amount": 10}}); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	if err == nil {
+		t.Fatal("expected rollback")
+	}
+
+	stats, _ := QueryStats(context.Background(), db)
+	if stats.Pending != 0 {
+		t.Fatalf("rolled back event left in outbox: %+v", stats)
+	}
+
+	if err := Enqueue(db, Message{Topic: "order.created", Payload: []byte("not json")}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage, got %v", err)
+	}
+	if err := Enqueue(db, Message{Payload: "x"}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("expected ErrInvalidMessage for missing topic, got %v", err)
+	}
+}
+
+func TestRelayOrderingAndRetry(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	err := Enqueue(db,
+		Message{Topic: "payment.failed", AggregateKey: "o-1", Payload: "1"},
+		Message{Topic: "payment.completed", AggregateKey: "o-1", Payload: "2"},
+		Message{Topic: "payment.completed", AggregateKey: "o-2", Payload: "3", Headers: map[string]string{"trace_id": "abc"}},
+	)
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{failTopic: "payment.failed"}
+	relay := NewRelay(db, publisher, RelayConfig{MaxAttempts: 2, RetryBackoff: time.Millisecond}, nil)
+
+	if _, err := relay.ProcessBatch(ctx); err != nil {
+		t.Fatalf("ProcessBatch() error = %v", err)
+	}
+	// o-1 的第一条失败，后续同键消息不能越过它发布
+	if strings.Join(publisher.published, ",") != "payment.completed/o-2" {
+		t.Fatalf("published = %v", publisher.published)
+	}
+
+	// 第二次失败后标记为 failed，同键后续消息继续发布
+	time.Sleep(5 * time.Millisecond)
+	relay.ProcessBatch(ctx)
+	relay.ProcessBatch(ctx)
+	if strings.Join(publisher.published, ",") != "payment.completed/o-2,payment.completed/o-1" {
+		t.Fatalf("published = %v", publisher.published)
+	}
+
+	stats, err := relay.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.Pending != 0 || stats.Failed != 1 || stats.Published != 2 || stats.PublishFailures != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	var failed OutboxMessage
+	db.Where("status = ?", StatusFailed).First(&failed)
+	if failed.Attempts != 2 || failed.LastError != "broker unavailable" {
+		t.Errorf("unexpected failed message: %+v", failed)
+	}
+
+	// 重放失败消息
+	publisher.failTopic = ""
+	var out bytes.Buffer
+	if err := RunCommand(ctx, db, []string{"replay", "-failed", "-dry-run"}, &out); err != nil {
+		t.Fatalf("replay -dry-run error = %v", err)
+	}
+	if !strings.Contains(out.String(), "1 messages would be replayed") {
+		t.Errorf("unexpected dry-run output: %s", out.String())
+	}
+	if err := RunCommand(ctx, db, []string{"replay", "-failed"}, &out); err != nil {
+		t.Fatalf("replay error = %v", err)
+	}
+	relay.ProcessBatch(ctx)
+	if len(publisher.published) != 3 || publisher.published[2] != "payment.failed/o-1" {
+		t.Errorf("published after replay = %v", publisher.published)
+	}
+
+	if err := RunCommand(ctx, db, []string{"replay"}, &out); !errors.Is(err, ErrEmptyReplayFilter) {
+		t.Errorf("replay without filter error = %v", err)
+	}
+}
+
+func TestRelaySkipsBackedOffMessages(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	err := Enqueue(db,
+		Message{Topic: "payment.failed", AggregateKey: "o-1", Payload: "1"},
+		Message{Topic: "payment.completed", AggregateKey: "o-1", Payload: "2"},
+		Message{Topic: "payment.completed", AggregateKey: "o-2", Payload: "3"},
+	)
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	publisher := &recordingPublisher{failTopic: "payment.failed"}
+	relay := NewRelay(db, publisher, RelayConfig{BatchSize: 2, RetryBackoff: time.Hour}, nil)
+
+	if n, err := relay.ProcessBatch(ctx); err != nil || n != 0 {
+		t.Fatalf("ProcessBatch() = %d, %v", n, err)
+	}
+	// 等待重试的 o-1 及其后续消息不再占满批次，o-2 得以发布
+	if n, err := relay.ProcessBatch(ctx); err != nil || n != 1 {
+		t.Fatalf("ProcessBatch() = %d, %v", n, err)
+	}
+	if n, err := relay.ProcessBatch(ctx); err != nil || n != 0 {
+		t.Fatalf("ProcessBatch() = %d, %v", n, err)
+	}
+	if strings.Join(publisher.published, ",") != "payment.completed/o-2" {
+		t.Fatalf("published = %v", publisher.published)
+	}
+	if stats, _ := relay.Stats(ctx); stats.PublishFailures != 1 {
+		t.Errorf("backed-off message retried early: %+v", stats)
+	}
+}
+
+func TestRelayRun(t *testing.T) {
+	db := newTestDB(t)
+	publisher := &recordingPublisher{}
+	relay := NewRelay(db, publisher, RelayConfig{PollInterval: 10 * time.Millisecond}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	Enqueue(db, Message{Topic: "plugin.created", AggregateKey: "p-1", Payload: "{}"})
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		publisher.mu.Lock()
+		n := len(publisher.published)
+		publisher.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("message not relayed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v", err)
+	}
+}
+
+func TestInboxDeduplicates(t *testing.T) {
+	db := newTestDB(t)
+	inbox := NewInbox(db, "billing")
+	ctx := context.Background()
+
+	calls := 0
+	handle := func(tx *gorm.DB) error {
+		calls++
+		return tx.Create(&order{ID: "o-1", Amount: 10}).Error
+	}
+
+	processed, err := inbox.Handle(ctx, "evt-1", handle)
+	if err != nil || !processed {
+		t.Fatalf("Handle() = %v, %v", processed, err)
+	}
+	processed, err = inbox.Handle(ctx, "evt-1", handle)
+	if err != nil || processed || calls != 1 {
+		t.Fatalf("duplicate Handle() = %v, %v, calls = %d", processed, err, calls)
+	}
+
+	// 其他消费者独立去重
+	if processed, _ := NewInbox(db, "analytics").Handle(ctx, "evt-1", func(*gorm.DB) error { return nil }); !processed {
+		t.Error("analytics consumer should process evt-1")
+	}
+
+	// 处理失败时不记录，可重试
+	if _, err := inbox.Handle(ctx, "evt-2", func(*gorm.DB) error { return errors.New("boom") }); err == nil {
+		t.Fatal("expected handler error")
+	}
+	if done, _ := inbox.Processed(ctx, "evt-2"); done {
+		t.Error("failed event recorded as processed")
+	}
+
+	if n, err := inbox.Purge(ctx, -time.Second); err != nil || n != 1 {
+		t.Errorf("Purge() = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,360 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/coordination"
+	"github.com/codetaoist/laojun-shared/monitoring"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 指标名称
+const (
+	MetricPending    = "outbox_pending_messages"
+	MetricLag        = "outbox_lag_seconds"
+	MetricPublished  = "outbox_published_total"
+	MetricPublishErr = "outbox_publish_errors_total"
+	MetricFailed     = "outbox_failed_total"
+)
+
+// Publisher 将消息发布到消息系统，返回 nil 表示消息已被接收
+type Publisher interface {
+	Publish(ctx context.Context, message *OutboxMessage) error
+}
+
+// PublisherFunc 函数形式的 Publisher
+type PublisherFunc func(ctx context.Context, message *OutboxMessage) error
+
+// Publish 调用 f
+func (f PublisherFunc) Publish(ctx context.Context, message *OutboxMessage) error {
+	return f(ctx, message)
+}
+
+// Envelope 发布到消息系统的消息格式
+type Envelope struct {
+	EventID      string            `json:"event_id"`
+	Topic        string            `json:"topic"`
+	AggregateKey string            `json:"aggregate_key,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Payload      json.RawMessage   `json:"payload"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// NewEnvelope 根据发件箱消息构造消息体
+func NewEnvelope(message *OutboxMessage) Envelope {
+	return Envelope{
+		EventID:      message.EventID,
+		Topic:        message.Topic,
+		AggregateKey: message.AggregateKey,
+		Headers:      message.HeaderMap(),
+		Payload:      message.Payload,
+		CreatedAt:    message.CreatedAt,
+	}
+}
+
+// RedisPublisher 通过 Redis PUBLISH 发布消息，频道为 "<prefix>:<topic>"
+type RedisPublisher struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisPublisher 创建 Redis 发布器，prefix 为空时使用 "events"
+func NewRedisPublisher(client redis.UniversalClient, prefix string) *RedisPublisher {
+	if prefix == "" {
+		prefix = "events"
+	}
+	return &RedisPublisher{client: client, prefix: prefix}
+}
+
+// Publish 发布消息
+func (p *RedisPublisher) Publish(ctx context.Context, message *OutboxMessage) error {
+	data, err := json.Marshal(NewEnvelope(message))
+	if err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	return p.client.Publish(ctx, p.prefix+":"+message.Topic, data).Err()
+}
+
+// RelayConfig 中继配置
+type RelayConfig struct {
+	// PollInterval 轮询间隔，默认1秒
+	PollInterval time.Duration
+	// BatchSize 每次读取的消息数，默认100
+	BatchSize int
+	// MaxAttempts 最大发布次数，超过后标记为 failed，默认10
+	MaxAttempts int
+	// RetryBackoff 首次重试间隔，之后指数增长，最长 MaxRetryBackoff，默认1秒
+	RetryBackoff time.Duration
+	// MaxRetryBackoff 最长重试间隔，默认5分钟
+	MaxRetryBackoff time.Duration
+	// Locker 多副本部署时只有持有锁的副本发布，保证同一聚合键的顺序；为空时不加锁
+	Locker coordination.Locker
+	// LockName 锁名称，默认 "outbox-relay"
+	LockName string
+	// Monitor 用于上报积压和发布指标，可为空
+	Monitor monitoring.Monitor
+}
+
+// Stats 发件箱状态
+type Stats struct {
+	Pending         int64         `json:"pending"`
+	Failed          int64         `json:"failed"`
+	Lag             time.Duration `json:"lag"` // 最早未发布消息的等待时间
+	Published       uint64        `json:"published"`
+	PublishFailures uint64        `json:"publish_failures"`
+}
+
+// Relay 将发件箱中的消息发布到消息系统
+//
+// 发布成功后才标记消息为已发布，进程在两者之间崩溃会导致重复发布（至少一次）。
+// 同一聚合键的消息发布失败时，该键后续的消息等待其重试成功或被标记为 failed。
+type Relay struct {
+	db        *gorm.DB
+	publisher Publisher
+	config    RelayConfig
+	logger    *zap.Logger
+
+	published       atomic.Uint64
+	publishFailures atomic.Uint64
+}
+
+// NewRelay 创建中继
+func NewRelay(db *gorm.DB, publisher Publisher, config RelayConfig, logger *zap.Logger) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 10
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = time.Second
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = 5 * time.Minute
+	}
+	if config.LockName == "" {
+		config.LockName = "outbox-relay"
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Run 持续发布消息直到 ctx 结束。配置了 Locker 时只在当选后发布
+func (r *Relay) Run(ctx context.Context) error {
+	if r.config.Locker == nil {
+		r.loop(ctx)
+		return ctx.Err()
+	}
+
+	elector := coordination.NewElector(r.config.Locker, coordination.ElectionConfig{
+		Name: r.config.LockName,
+		OnElected: func(ctx context.Context, token int64) {
+			r.loop(ctx)
+		},
+	}, r.logger)
+	return elector.Run(ctx)
+}
+
+func (r *Relay) loop(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// 一批全部发布成功时立即继续，追赶积压；否则等待下一次轮询
+		for {
+			n, err := r.ProcessBatch(ctx)
+			if err != nil && ctx.Err() == nil {
+				r.logger.Error("Failed to relay outbox messages", zap.Error(err))
+			}
+			if err != nil || n < r.config.BatchSize {
+				break
+			}
+		}
+		r.reportStats(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch 读取一批可发布的消息并发布，返回成功发布的消息数
+//
+// 等待重试的消息，以及同一聚合键中排在等待重试的消息之后的消息，不会被读取，
+// 避免它们占满批次而使后续消息得不到发布。
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	var messages []OutboxMessage
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+		Where("aggregate_key = '' OR NOT EXISTS (?)", r.db.
+			Table("outbox_messages AS waiting").
+			Select("1").
+			Where("waiting.aggregate_key = outbox_messages.aggregate_key").
+			Where("waiting.status = ? AND waiting.id < outbox_messages.id AND waiting.next_attempt_at > ?", StatusPending, now)).
+		Order("id").
+		Limit(r.config.BatchSize).
+		Find(&messages).Error; err != nil {
+		return 0, fmt.Errorf("failed to load outbox messages: %w", err)
+	}
+
+	published := 0
+	blocked := make(map[string]bool)
+	for i := range messages {
+		message := &messages[i]
+		if message.AggregateKey != "" && blocked[message.AggregateKey] {
+			continue
+		}
+
+		if err := r.publish(ctx, message); err != nil {
+			if ctx.Err() != nil {
+				return published, ctx.Err()
+			}
+			blocked[message.AggregateKey] = true
+			if err := r.recordFailure(ctx, message, err); err != nil {
+				return published, err
+			}
+			continue
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+func (r *Relay) publish(ctx context.Context, message *OutboxMessage) error {
+	if err := r.publisher.Publish(ctx, message); err != nil {
+		return err
+	}
+
+	publishedAt := time.Now()
+	if err := r.db.WithContext(ctx).Model(message).Updates(map[string]interface{}{
+		"status":       StatusPublished,
+		"attempts":     message.Attempts + 1,
+		"published_at": publishedAt,
+		"last_error":   "",
+	}).Error; err != nil {
+		// 消息已发布但未标记，下次会重复发布，由消费者去重
+		return fmt.Errorf("failed to mark message %d published: %w", message.ID, err)
+	}
+
+	r.published.Add(1)
+	r.incrementCounter(ctx, MetricPublished, message.Topic)
+	return nil
+}
+
+// recordFailure 记录发布失败，并安排指数退避重试
+func (r *Relay) recordFailure(ctx context.Context, message *OutboxMessage, publishErr error) error {
+	attempts := message.Attempts + 1
+	updates := map[string]interface{}{
+		"attempts":        attempts,
+		"last_error":      publishErr.Error(),
+		"next_attempt_at": time.Now().Add(r.backoff(attempts)),
+	}
+	if attempts >= r.config.MaxAttempts {
+		updates["status"] = StatusFailed
+		r.incrementCounter(ctx, MetricFailed, message.Topic)
+		r.logger.Error("Outbox message failed permanently",
+			zap.Uint64("id", message.ID),
+			zap.String("event_id", message.EventID),
+			zap.String("topic", message.Topic),
+			zap.Error(publishErr))
+	} else {
+		r.logger.Warn("Failed to publish outbox message",
+			zap.Uint64("id", message.ID),
+			zap.String("topic", message.Topic),
+			zap.Int("attempts", attempts),
+			zap.Error(publishErr))
+	}
+
+	r.publishFailures.Add(1)
+	r.incrementCounter(ctx, MetricPublishErr, message.Topic)
+
+	if err := r.db.WithContext(ctx).Model(message).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record publish failure of message %d: %w", message.ID, err)
+	}
+	return nil
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.config.RetryBackoff
+	for i := 1; i < attempts && backoff < r.config.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.config.MaxRetryBackoff {
+		backoff = r.config.MaxRetryBackoff
+	}
+	return backoff
+}
+
+// Stats 返回积压和发布统计
+func (r *Relay) Stats(ctx context.Context) (Stats, error) {
+	stats, err := QueryStats(ctx, r.db)
+	if err != nil {
+		return stats, err
+	}
+	stats.Published = r.published.Load()
+	stats.PublishFailures = r.publishFailures.Load()
+	return stats, nil
+}
+
+func (r *Relay) reportStats(ctx context.Context) {
+	if r.config.Monitor == nil {
+		return
+	}
+	stats, err := QueryStats(ctx, r.db)
+	if err != nil {
+		return
+	}
+	r.config.Monitor.SetGauge(ctx, MetricPending, float64(stats.Pending), nil)
+	r.config.Monitor.SetGauge(ctx, MetricLag, stats.Lag.Seconds(), nil)
+}
+
+func (r *Relay) incrementCounter(ctx context.Context, name, topic string) {
+	if r.config.Monitor != nil {
+		r.config.Monitor.IncrementCounter(ctx, name, map[string]string{"topic": topic})
+	}
+}
+
+// QueryStats 查询发件箱积压情况
+func QueryStats(ctx context.Context, db *gorm.DB) (Stats, error) {
+	var stats Stats
+	db = db.WithContext(ctx).Model(&OutboxMessage{})
+
+	if err := db.Session(&gorm.Session{}).Where("status = ?", StatusPending).Count(&stats.Pending).Error; err != nil {
+		return stats, fmt.Errorf("failed to count pending messages: %w", err)
+	}
+	if err := db.Session(&gorm.Session{}).Where("status = ?", StatusFailed).Count(&stats.Failed).Error; err != nil {
+		return stats, fmt.Errorf("failed to count failed messages: %w", err)
+	}
+
+	if stats.Pending > 0 {
+		var oldest OutboxMessage
+		if err := db.Session(&gorm.Session{}).Where("status = ?", StatusPending).Order("id").Limit(1).Find(&oldest).Error; err != nil {
+			return stats, fmt.Errorf("failed to query oldest pending message: %w", err)
+		}
+		if !oldest.CreatedAt.IsZero() {
+			stats.Lag = time.Since(oldest.CreatedAt)
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReplayFilter 重放条件，至少指定一项
+type ReplayFilter struct {
+	IDs          []uint64  // 指定消息ID
+	Failed       bool      // 所有 failed 状态的消息
+	Since        time.Time // 该时间之后写入的已发布或失败消息
+	Topic        string    // 限定主题
+	AggregateKey string    // 限定聚合键
+}
+
+func (f ReplayFilter) apply(db *gorm.DB) (*gorm.DB, error) {
+	if len(f.IDs) == 0 && !f.Failed && f.Since.IsZero() {
+		return nil, ErrEmptyReplayFilter
+	}
+
+	db = db.Model(&OutboxMessage{}).Where("status <> ?", StatusPending)
+	if len(f.IDs) > 0 {
+		db = db.Where("id IN ?", f.IDs)
+	}
+	if f.Failed {
+		db = db.Where("status = ?", StatusFailed)
+	}
+	if !f.Since.IsZero() {
+		db = db.Where("created_at >= ?", f.Since)
+	}
+	if f.Topic != "" {
+		db = db.Where("topic = ?", f.Topic)
+	}
+	if f.AggregateKey != "" {
+		db = db.Where("aggregate_key = ?", f.AggregateKey)
+	}
+	return db, nil
+}
+
+// Replay 将匹配的消息重置为待发布，由 Relay 重新发布，返回重置的消息数。
+// 消息按原ID顺序发布，消费者应通过 Inbox 去重
+func Replay(ctx context.Context, db *gorm.DB, filter ReplayFilter) (int64, error) {
+	query, err := filter.apply(db.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"status":          StatusPending,
+		"attempts":        0,
+		"last_error":      "",
+		"next_attempt_at": time.Now(),
+		"published_at":    nil,
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to replay messages: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ReplayPlan 返回 Replay 将重置的消息，不修改数据库
+func ReplayPlan(ctx context.Context, db *gorm.DB, filter ReplayFilter) ([]OutboxMessage, error) {
+	query, err := filter.apply(db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []OutboxMessage
+	if err := query.Order("id").Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	return messages, nil
+}
+
+const commandUsage = `Usage: <service> outbox <command> [flags]
+
+Commands:
+  status                                       show pending and failed messages and relay lag
+  replay [-id N,...] [-failed] [-since RFC3339]
+         [-topic T] [-key K] [-dry-run]        republish published or failed messages
+`
+
+// RunCommand 执行 outbox 子命令，args 不包含 "outbox" 本身。各服务在 main 中调用：
+//
+//	if len(os.Args) > 1 && os.Args[1] == "outbox" {
+//		if err := outbox.RunCommand(ctx, db, os.Args[2:], os.Stdout); err != nil {
+//			fmt.Fprintf(os.Stderr, "outbox: %v\n", err)
+//			os.Exit(1)
+//		}
+//		os.Exit(0)
+//	}
+func RunCommand(ctx context.Context, db *gorm.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, commandUsage)
+		return fmt.Errorf("missing outbox command")
+	}
+
+	command, args := args[0], args[1:]
+	flags := flag.NewFlagSet("outbox "+command, flag.ContinueOnError)
+	flags.SetOutput(out)
+	ids := flags.String("id", "", "comma separated message IDs")
+	failed := flags.Bool("failed", false, "replay all failed messages")
+	since := flags.String("since", "", "replay messages created at or after this time (RFC3339)")
+	topic := flags.String("topic", "", "only replay messages of this topic")
+	key := flags.String("key", "", "only replay messages of this aggregate key")
+	dryRun := flags.Bool("dry-run", false, "list the messages without changing the database")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch command {
+	case "status":
+		stats, err := QueryStats(ctx, db)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "pending: %d\nfailed:  %d\nlag:     %s\n", stats.Pending, stats.Failed, stats.Lag.Round(time.Millisecond))
+		return nil
+
+	case "replay":
+		filter := ReplayFilter{Failed: *failed, Topic: *topic, AggregateKey: *key}
+		if *ids != "" {
+			for _, s := range strings.Split(*ids, ",") {
+				id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid message id %q", s)
+				}
+				filter.IDs = append(filter.IDs, id)
+			}
+		}
+		if *since != "" {
+			t, err := time.Parse(time.RFC3339, *since)
+			if err != nil {
+				return fmt.Errorf("invalid -since: %w", err)
+			}
+			filter.Since = t
+		}
+
+		if *dryRun {
+			messages, err := ReplayPlan(ctx, db, filter)
+			if err != nil {
+				return err
+			}
+			for _, m := range messages {
+				fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\n", m.ID, m.EventID, m.Topic, m.AggregateKey, m.Status)
+			}
+			fmt.Fprintf(out, "%d messages would be replayed.\n", len(messages))
+			return nil
+		}
+
+		n, err := Replay(ctx, db, filter)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%d messages queued for replay.\n", n)
+		return nil
+
+	default:
+		fmt.Fprint(out, commandUsage)
+		return fmt.Errorf("unknown outbox command %q", command)
+	}
+}
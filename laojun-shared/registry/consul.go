@@ -0,0 +1,469 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"go.uber.org/zap"
+)
+
+// ConsulServiceRegistry 基于Consul的服务注册实现，使用TTL检查作为心跳，
+// 通过阻塞查询（blocking query）监听服务变化
+type ConsulServiceRegistry struct {
+	config    *RegistryConfig
+	client    *api.Client
+	logger    *zap.Logger
+	keepalive *keepalive
+	closeCh   chan struct{}
+}
+
+// NewConsulServiceRegistry 创建Consul服务注册实例，地址格式为 consul://host:port 或 http(s)://host:port
+func NewConsulServiceRegistry(config *RegistryConfig, logger *zap.Logger) (*ConsulServiceRegistry, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	consulConfig := api.DefaultConfig()
+	if config.Address != "" {
+		address := config.Address
+		switch {
+		case strings.HasPrefix(address, "consul://"):
+			address = strings.TrimPrefix(address, "consul://")
+		case strings.HasPrefix(address, "https://"):
+			consulConfig.Scheme = "https"
+			address = strings.TrimPrefix(address, "https://")
+		case strings.HasPrefix(address, "http://"):
+			address = strings.TrimPrefix(address, "http://")
+		}
+		consulConfig.Address = strings.TrimSuffix(address, "/")
+	}
+	if config.Auth != nil {
+		consulConfig.Token = config.Auth.Token
+		if config.Auth.Username != "" {
+			consulConfig.HttpAuth = &api.HttpBasicAuth{Username: config.Auth.Username, Password: config.Auth.Password}
+		}
+	}
+	if config.TLS != nil && config.TLS.Enabled {
+		consulConfig.Scheme = "https"
+		consulConfig.TLSConfig = api.TLSConfig{
+			CAFile:             config.TLS.CAFile,
+			CertFile:           config.TLS.CertFile,
+			KeyFile:            config.TLS.KeyFile,
+			InsecureSkipVerify: config.TLS.SkipVerify,
+		}
+	}
+	if config.Namespace != "" && config.Namespace != "laojun" {
+		// 仅Consul企业版支持命名空间，默认命名空间不传递
+		consulConfig.Namespace = config.Namespace
+	}
+
+	client, err := api.NewClient(consulConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client: %w", err)
+	}
+
+	r := &ConsulServiceRegistry{
+		config:  config,
+		client:  client,
+		logger:  logger,
+		closeCh: make(chan struct{}),
+	}
+	r.keepalive = newKeepalive(config, logger, r.Heartbeat, r.register)
+	return r, nil
+}
+
+// RegisterService 注册服务，并为其启动心跳
+func (r *ConsulServiceRegistry) RegisterService(ctx context.Context, service *ServiceInfo) error {
+	if err := prepareService(service, r.config); err != nil {
+		return err
+	}
+	if err := r.register(ctx, service); err != nil {
+		return err
+	}
+	r.keepalive.add(service)
+
+	r.logger.Info("Service registered",
+		zap.String("service_id", service.ID),
+		zap.String("service_name", service.Name),
+		zap.String("address", fmt.Sprintf("%s:%d", service.Address, service.Port)))
+	return nil
+}
+
+func (r *ConsulServiceRegistry) register(ctx context.Context, service *ServiceInfo) error {
+	ttl := ttlOf(service)
+	// Consul 要求自动注销时间不小于1分钟
+	deregisterAfter := 3 * ttl
+	if deregisterAfter < time.Minute {
+		deregisterAfter = time.Minute
+	}
+
+	status := api.HealthPassing
+	if service.Status != ServiceStatusActive {
+		status = api.HealthCritical
+	}
+
+	registration := &api.AgentServiceRegistration{
+		ID:      service.ID,
+		Name:    service.Name,
+		Address: service.Address,
+		Port:    service.Port,
+		Tags:    service.Tags,
+		Meta:    encodeServiceMeta(service),
+		Weights: &api.AgentWeights{Passing: service.Weight, Warning: 1},
+		Check: &api.AgentServiceCheck{
+			CheckID:                        consulCheckID(service.ID),
+			TTL:                            ttl.String(),
+			Status:                         status,
+			DeregisterCriticalServiceAfter: deregisterAfter.String(),
+		},
+	}
+
+	opts := api.ServiceRegisterOpts{ReplaceExistingChecks: true}.WithContext(ctx)
+	if err := r.client.Agent().ServiceRegisterOpts(registration, opts); err != nil {
+		return fmt.Errorf("failed to register service: %w", err)
+	}
+	return nil
+}
+
+// DeregisterService 注销服务
+func (r *ConsulServiceRegistry) DeregisterService(ctx context.Context, serviceID string) error {
+	r.keepalive.remove(serviceID)
+
+	if err := r.client.Agent().ServiceDeregisterOpts(serviceID, r.query(ctx)); err != nil {
+		if isConsulNotFound(err) {
+			return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+		}
+		return fmt.Errorf("failed to deregister service: %w", err)
+	}
+	r.logger.Info("Service deregistered", zap.String("service_id", serviceID))
+	return nil
+}
+
+// UpdateService 更新服务信息
+func (r *ConsulServiceRegistry) UpdateService(ctx context.Context, service *ServiceInfo) error {
+	if _, err := r.GetService(ctx, service.ID); err != nil {
+		return err
+	}
+	if err := prepareService(service, r.config); err != nil {
+		return err
+	}
+	if err := r.register(ctx, service); err != nil {
+		return err
+	}
+	if r.isLocal(service.ID) {
+		r.keepalive.add(service)
+	}
+	return nil
+}
+
+// GetService 获取服务信息，先查询本地agent，再查询整个目录
+func (r *ConsulServiceRegistry) GetService(ctx context.Context, serviceID string) (*ServiceInfo, error) {
+	agentService, _, err := r.client.Agent().Service(serviceID, r.query(ctx))
+	if err == nil && agentService != nil {
+		return decodeConsulService(agentService, nil), nil
+	}
+	if err != nil && !isConsulNotFound(err) {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	all, err := r.ListAllServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, services := range all {
+		for _, service := range services {
+			if service.ID == serviceID {
+				return service, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+}
+
+// ListServices 列出指定名称的服务
+func (r *ConsulServiceRegistry) ListServices(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	services, _, err := r.listServices(r.query(ctx), serviceName, false)
+	return services, err
+}
+
+func (r *ConsulServiceRegistry) listServices(q *api.QueryOptions, serviceName string, passingOnly bool) ([]*ServiceInfo, uint64, error) {
+	entries, meta, err := r.client.Health().Service(serviceName, "", passingOnly, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	services := make([]*ServiceInfo, 0, len(entries))
+	for _, entry := range entries {
+		services = append(services, decodeConsulService(entry.Service, entry.Checks))
+	}
+	return services, meta.LastIndex, nil
+}
+
+// ListAllServices 列出所有服务
+func (r *ConsulServiceRegistry) ListAllServices(ctx context.Context) (map[string][]*ServiceInfo, error) {
+	names, _, err := r.client.Catalog().Services(r.query(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list all services: %w", err)
+	}
+
+	result := make(map[string][]*ServiceInfo)
+	for name := range names {
+		if name == "consul" {
+			continue
+		}
+		services, err := r.ListServices(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(services) > 0 {
+			result[name] = services
+		}
+	}
+	return result, nil
+}
+
+// GetHealthyServices 获取通过健康检查的服务实例
+func (r *ConsulServiceRegistry) GetHealthyServices(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	services, _, err := r.listServices(r.query(ctx), serviceName, true)
+	return services, err
+}
+
+// WatchServices 通过阻塞查询监听服务变化，serviceName 为空时监听所有服务
+func (r *ConsulServiceRegistry) WatchServices(ctx context.Context, serviceName string) (<-chan *ServiceEvent, error) {
+	known, index, err := r.snapshot(r.query(ctx), serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := make(chan *ServiceEvent, 100)
+	go r.watch(ctx, serviceName, known, index, watcher)
+	return watcher, nil
+}
+
+func (r *ConsulServiceRegistry) watch(ctx context.Context, serviceName string, known map[string]*ServiceInfo, index uint64, watcher chan *ServiceEvent) {
+	defer close(watcher)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-r.closeCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	retry := r.config.RetryInterval
+	if retry <= 0 {
+		retry = 5 * time.Second
+	}
+
+	for ctx.Err() == nil {
+		q := r.query(ctx)
+		q.WaitIndex = index
+		q.WaitTime = 5 * time.Minute
+
+		current, newIndex, err := r.snapshot(q, serviceName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			r.logger.Warn("Consul watch failed", zap.String("service_name", serviceName), zap.Error(err))
+			select {
+			case <-time.After(retry):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		// 索引回退时重置，见Consul阻塞查询文档
+		if newIndex < index {
+			newIndex = 0
+		}
+		index = newIndex
+
+		for _, event := range diffServices(known, current) {
+			select {
+			case watcher <- event:
+			default:
+				r.logger.Warn("Watcher channel full, skipping event",
+					zap.String("service_name", event.Service.Name),
+					zap.String("event_type", string(event.Type)))
+			}
+		}
+		known = current
+	}
+}
+
+// snapshot 获取服务快照及Consul索引
+func (r *ConsulServiceRegistry) snapshot(q *api.QueryOptions, serviceName string) (map[string]*ServiceInfo, uint64, error) {
+	var services []*ServiceInfo
+	var index uint64
+	if serviceName != "" {
+		list, lastIndex, err := r.listServices(q, serviceName, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		services, index = list, lastIndex
+	} else {
+		names, meta, err := r.client.Catalog().Services(q)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to list all services: %w", err)
+		}
+		index = meta.LastIndex
+
+		listQuery := &api.QueryOptions{Namespace: q.Namespace}
+		listQuery = listQuery.WithContext(q.Context())
+		for name := range names {
+			if name == "consul" {
+				continue
+			}
+			list, _, err := r.listServices(listQuery, name, false)
+			if err != nil {
+				return nil, 0, err
+			}
+			services = append(services, list...)
+		}
+	}
+
+	result := make(map[string]*ServiceInfo, len(services))
+	for _, service := range services {
+		result[service.ID] = service
+	}
+	return result, index, nil
+}
+
+// Heartbeat 更新TTL检查，服务不存在时返回 ErrServiceNotFound
+func (r *ConsulServiceRegistry) Heartbeat(ctx context.Context, serviceID string) error {
+	err := r.client.Agent().UpdateTTLOpts(consulCheckID(serviceID), "", api.HealthPassing, r.query(ctx))
+	if err == nil {
+		return nil
+	}
+	if isConsulNotFound(err) {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	return fmt.Errorf("failed to send heartbeat: %w", err)
+}
+
+// GetRegistryHealth 获取注册中心健康状态
+func (r *ConsulServiceRegistry) GetRegistryHealth(ctx context.Context) (*RegistryHealth, error) {
+	health := &RegistryHealth{
+		Status:    HealthStatusHealthy,
+		LastCheck: time.Now(),
+		Details: map[string]string{
+			"registry_type": string(RegistryTypeConsul),
+			"namespace":     r.config.Namespace,
+		},
+	}
+
+	leader, err := r.client.Status().LeaderWithQueryOptions(r.query(ctx))
+	if err != nil || leader == "" {
+		health.Status = HealthStatusUnhealthy
+		if err != nil {
+			health.Details["error"] = err.Error()
+		} else {
+			health.Details["error"] = "no cluster leader"
+		}
+		return health, nil
+	}
+	health.Details["leader"] = leader
+
+	all, err := r.ListAllServices(ctx)
+	if err != nil {
+		health.Status = HealthStatusDegraded
+		health.Details["error"] = err.Error()
+		return health, nil
+	}
+	for _, services := range all {
+		for _, service := range services {
+			health.TotalServices++
+			if service.Status == ServiceStatusActive {
+				health.HealthyServices++
+			} else {
+				health.UnhealthyServices++
+			}
+		}
+	}
+	return health, nil
+}
+
+// Close 停止心跳和监听，并注销本实例注册的服务
+func (r *ConsulServiceRegistry) Close() error {
+	select {
+	case <-r.closeCh:
+		return nil
+	default:
+		close(r.closeCh)
+	}
+
+	r.keepalive.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.keepalive.timeout)
+	defer cancel()
+	for _, serviceID := range r.keepalive.list() {
+		if err := r.DeregisterService(ctx, serviceID); err != nil && !IsServiceNotFound(err) {
+			r.logger.Warn("Failed to deregister service on close", zap.String("service_id", serviceID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (r *ConsulServiceRegistry) isLocal(serviceID string) bool {
+	for _, id := range r.keepalive.list() {
+		if id == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ConsulServiceRegistry) query(ctx context.Context) *api.QueryOptions {
+	return (&api.QueryOptions{}).WithContext(ctx)
+}
+
+// consulCheckID 返回服务TTL检查的ID
+func consulCheckID(serviceID string) string {
+	return "service:" + serviceID
+}
+
+// isConsulNotFound 判断Consul返回的服务或检查不存在错误
+func isConsulNotFound(err error) bool {
+	var statusErr api.StatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.Code == 404 {
+			return true
+		}
+		// 旧版本Consul对未知检查返回500
+		return strings.Contains(statusErr.Body, "Unknown check") ||
+			strings.Contains(statusErr.Body, "does not have associated TTL")
+	}
+	return false
+}
+
+// decodeConsulService 将Consul服务转换为服务信息，任一检查为critical时视为不健康
+func decodeConsulService(agentService *api.AgentService, checks api.HealthChecks) *ServiceInfo {
+	instance := &discoveryInstance{
+		ID:      agentService.ID,
+		Name:    agentService.Service,
+		Address: agentService.Address,
+		Port:    agentService.Port,
+		Tags:    agentService.Tags,
+		Meta:    agentService.Meta,
+	}
+	service := decodeInstance(instance)
+	if service.Weight == 1 && agentService.Weights.Passing > 0 {
+		service.Weight = agentService.Weights.Passing
+	}
+
+	for _, check := range checks {
+		if check.Status == api.HealthCritical && service.Status == ServiceStatusActive {
+			service.Status = ServiceStatusInactive
+		}
+	}
+	return service
+}
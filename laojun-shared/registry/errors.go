@@ -0,0 +1,18 @@
+package registry
+
+import "errors"
+
+// 注册中心错误
+var (
+	// ErrServiceNotFound 服务不存在或已过期
+	ErrServiceNotFound = errors.New("service not found")
+	// ErrUnsupportedRegistry 不支持的注册中心类型
+	ErrUnsupportedRegistry = errors.New("unsupported registry type")
+	// ErrRegistryClosed 注册中心已关闭
+	ErrRegistryClosed = errors.New("registry closed")
+)
+
+// IsServiceNotFound 判断是否为服务不存在错误
+func IsServiceNotFound(err error) bool {
+	return errors.Is(err, ErrServiceNotFound)
+}
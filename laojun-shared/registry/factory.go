@@ -26,14 +26,14 @@ func (f *RegistryFactory) CreateRegistry(config *RegistryConfig) (ServiceRegistr
 		return f.createMemoryRegistry(config)
 	case RegistryTypeRedis:
 		return f.createRedisRegistry(config)
+	case RegistryTypeHTTP:
+		return f.createHTTPRegistry(config)
 	case RegistryTypeConsul:
 		return f.createConsulRegistry(config)
-	case RegistryTypeEtcd:
-		return f.createEtcdRegistry(config)
-	case RegistryTypeZookeeper:
-		return f.createZookeeperRegistry(config)
 	default:
-		return nil, fmt.Errorf("unsupported registry type: %s", config.Type)
+		// etcd、zookeeper 尚未实现，返回错误而不是回退到内存注册中心，
+		// 避免多个服务各自使用内存注册中心而无法共享状态
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRegistry, config.Type)
 	}
 }
 
@@ -61,6 +61,8 @@ func (f *RegistryFactory) CreateDefaultConfig(registryType RegistryType) *Regist
 		config.Address = "memory://localhost"
 	case RegistryTypeRedis:
 		config.Address = "redis://localhost:6379"
+	case RegistryTypeHTTP:
+		config.Address = "http://localhost:8084"
 	case RegistryTypeConsul:
 		config.Address = "consul://localhost:8500"
 	case RegistryTypeEtcd:
@@ -82,30 +84,24 @@ func (f *RegistryFactory) createMemoryRegistry(config *RegistryConfig) (ServiceR
 
 // createRedisRegistry 创建Redis注册中心
 func (f *RegistryFactory) createRedisRegistry(config *RegistryConfig) (ServiceRegistry, error) {
-	// TODO: 实现Redis注册中心
-	f.logger.Warn("Redis registry not implemented, using memory registry")
-	return f.createMemoryRegistry(config)
-}
+	client, err := newRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
 
-// createConsulRegistry 创建Consul注册中心
-func (f *RegistryFactory) createConsulRegistry(config *RegistryConfig) (ServiceRegistry, error) {
-	// TODO: 实现Consul注册中心
-	f.logger.Warn("Consul registry not implemented, using memory registry")
-	return f.createMemoryRegistry(config)
+	registry := NewRedisServiceRegistry(client, config, f.logger)
+	registry.ownsClient = true
+	return registry, nil
 }
 
-// createEtcdRegistry 创建Etcd注册中心
-func (f *RegistryFactory) createEtcdRegistry(config *RegistryConfig) (ServiceRegistry, error) {
-	// TODO: 实现Etcd注册中心
-	f.logger.Warn("Etcd registry not implemented, using memory registry")
-	return f.createMemoryRegistry(config)
+// createHTTPRegistry 创建 laojun-discovery HTTP注册中心客户端
+func (f *RegistryFactory) createHTTPRegistry(config *RegistryConfig) (ServiceRegistry, error) {
+	return NewHTTPServiceRegistry(config, nil, f.logger)
 }
 
-// createZookeeperRegistry 创建Zookeeper注册中心
-func (f *RegistryFactory) createZookeeperRegistry(config *RegistryConfig) (ServiceRegistry, error) {
-	// TODO: 实现Zookeeper注册中心
-	f.logger.Warn("Zookeeper registry not implemented, using memory registry")
-	return f.createMemoryRegistry(config)
+// createConsulRegistry 创建Consul注册中心
+func (f *RegistryFactory) createConsulRegistry(config *RegistryConfig) (ServiceRegistry, error) {
+	return NewConsulServiceRegistry(config, f.logger)
 }
 
 // RegistryBuilder 注册中心构建器
@@ -203,6 +199,12 @@ func (b *RegistryBuilder) WithMeta(meta map[string]string) *RegistryBuilder {
 	return b
 }
 
+// WithKeyspaceEvents 设置是否允许自动开启Redis键空间通知
+func (b *RegistryBuilder) WithKeyspaceEvents(enabled bool) *RegistryBuilder {
+	b.config.EnableKeyspaceEvents = enabled
+	return b
+}
+
 // WithLogger 设置日志器
 func (b *RegistryBuilder) WithLogger(logger *zap.Logger) *RegistryBuilder {
 	b.logger = logger
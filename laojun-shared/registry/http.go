@@ -0,0 +1,514 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 通过元数据传递 laojun-discovery 不支持的服务字段
+const (
+	metaVersion = "version"
+	metaWeight  = "weight"
+	metaStatus  = "status"
+)
+
+// HTTPServiceRegistry 基于 laojun-discovery HTTP API 的服务注册客户端
+type HTTPServiceRegistry struct {
+	config    *RegistryConfig
+	baseURL   string
+	client    *http.Client
+	logger    *zap.Logger
+	keepalive *keepalive
+	closeCh   chan struct{}
+}
+
+// discoveryInstance laojun-discovery 的服务实例格式
+type discoveryInstance struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Address  string            `json:"address"`
+	Port     int               `json:"port"`
+	Tags     []string          `json:"tags"`
+	Meta     map[string]string `json:"meta"`
+	TTL      int               `json:"ttl"`
+	LastSeen time.Time         `json:"last_seen"`
+	Health   struct {
+		Status string `json:"status"`
+	} `json:"health"`
+}
+
+// NewHTTPServiceRegistry 创建 laojun-discovery 注册客户端，client 为空时使用默认客户端
+func NewHTTPServiceRegistry(config *RegistryConfig, client *http.Client, logger *zap.Logger) (*HTTPServiceRegistry, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	baseURL, err := discoveryBaseURL(config.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		timeout := config.Timeout
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+		if config.TLS != nil && config.TLS.Enabled && config.TLS.SkipVerify {
+			client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+		}
+	}
+
+	r := &HTTPServiceRegistry{
+		config:  config,
+		baseURL: baseURL,
+		client:  client,
+		logger:  logger,
+		closeCh: make(chan struct{}),
+	}
+	r.keepalive = newKeepalive(config, logger, r.Heartbeat, r.register)
+	return r, nil
+}
+
+// discoveryBaseURL 解析注册中心地址，如 http://discovery:8081 或 discovery:8081
+func discoveryBaseURL(address string) (string, error) {
+	if address == "" {
+		return "", fmt.Errorf("discovery address cannot be empty")
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid discovery address %q: %w", address, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid discovery address %q: unsupported scheme %s", address, u.Scheme)
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(path, "/api/v1") {
+		path += "/api/v1"
+	}
+	u.Path = path
+	return u.String(), nil
+}
+
+// RegisterService 注册服务，并为其启动心跳
+func (r *HTTPServiceRegistry) RegisterService(ctx context.Context, service *ServiceInfo) error {
+	if err := prepareService(service, r.config); err != nil {
+		return err
+	}
+	if err := r.register(ctx, service); err != nil {
+		return err
+	}
+	r.keepalive.add(service)
+
+	r.logger.Info("Service registered",
+		zap.String("service_id", service.ID),
+		zap.String("service_name", service.Name),
+		zap.String("discovery", r.baseURL))
+	return nil
+}
+
+func (r *HTTPServiceRegistry) register(ctx context.Context, service *ServiceInfo) error {
+	body := map[string]interface{}{
+		"id":      service.ID,
+		"name":    service.Name,
+		"address": service.Address,
+		"port":    service.Port,
+		"tags":    service.Tags,
+		"meta":    encodeServiceMeta(service),
+		"ttl":     service.TTL,
+	}
+	if err := r.do(ctx, http.MethodPost, "/services", body, nil); err != nil {
+		return fmt.Errorf("failed to register service: %w", err)
+	}
+	return nil
+}
+
+// DeregisterService 注销服务
+func (r *HTTPServiceRegistry) DeregisterService(ctx context.Context, serviceID string) error {
+	r.keepalive.remove(serviceID)
+
+	if err := r.do(ctx, http.MethodDelete, "/services/"+url.PathEscape(serviceID), nil, nil); err != nil {
+		return fmt.Errorf("failed to deregister service: %w", err)
+	}
+	r.logger.Info("Service deregistered", zap.String("service_id", serviceID))
+	return nil
+}
+
+// UpdateService 更新服务信息，laojun-discovery 以相同ID重新注册覆盖
+func (r *HTTPServiceRegistry) UpdateService(ctx context.Context, service *ServiceInfo) error {
+	existing, err := r.GetService(ctx, service.ID)
+	if err != nil {
+		return err
+	}
+	if service.RegisteredAt.IsZero() {
+		service.RegisteredAt = existing.RegisteredAt
+	}
+	if err := prepareService(service, r.config); err != nil {
+		return err
+	}
+	if err := r.register(ctx, service); err != nil {
+		return err
+	}
+	if r.isLocal(service.ID) {
+		r.keepalive.add(service)
+	}
+	return nil
+}
+
+// GetService 获取服务信息
+func (r *HTTPServiceRegistry) GetService(ctx context.Context, serviceID string) (*ServiceInfo, error) {
+	all, err := r.ListAllServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, services := range all {
+		for _, service := range services {
+			if service.ID == serviceID {
+				return service, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+}
+
+// ListServices 列出指定名称的服务
+func (r *HTTPServiceRegistry) ListServices(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	var resp struct {
+		Instances []*discoveryInstance `json:"instances"`
+	}
+	if err := r.do(ctx, http.MethodGet, "/services?name="+url.QueryEscape(serviceName), nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	return decodeInstances(resp.Instances), nil
+}
+
+// ListAllServices 列出所有服务
+func (r *HTTPServiceRegistry) ListAllServices(ctx context.Context) (map[string][]*ServiceInfo, error) {
+	var resp struct {
+		Services map[string][]*discoveryInstance `json:"services"`
+	}
+	if err := r.do(ctx, http.MethodGet, "/services", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list all services: %w", err)
+	}
+
+	result := make(map[string][]*ServiceInfo, len(resp.Services))
+	for name, instances := range resp.Services {
+		if services := decodeInstances(instances); len(services) > 0 {
+			result[name] = services
+		}
+	}
+	return result, nil
+}
+
+// GetHealthyServices 获取健康的服务实例
+func (r *HTTPServiceRegistry) GetHealthyServices(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	var resp struct {
+		Instances []*discoveryInstance `json:"instances"`
+	}
+	if err := r.do(ctx, http.MethodGet, "/services/"+url.PathEscape(serviceName)+"?healthy=true", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get healthy services: %w", err)
+	}
+
+	var healthyServices []*ServiceInfo
+	for _, service := range decodeInstances(resp.Instances) {
+		if service.Status == ServiceStatusActive {
+			healthyServices = append(healthyServices, service)
+		}
+	}
+	return healthyServices, nil
+}
+
+// WatchServices 轮询 laojun-discovery 监听服务变化，间隔为心跳间隔，serviceName 为空时监听所有服务
+func (r *HTTPServiceRegistry) WatchServices(ctx context.Context, serviceName string) (<-chan *ServiceEvent, error) {
+	known, err := r.snapshot(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := make(chan *ServiceEvent, 100)
+	go r.poll(ctx, serviceName, known, watcher)
+	return watcher, nil
+}
+
+func (r *HTTPServiceRegistry) poll(ctx context.Context, serviceName string, known map[string]*ServiceInfo, watcher chan *ServiceEvent) {
+	defer close(watcher)
+
+	ticker := time.NewTicker(r.keepalive.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.closeCh:
+			return
+		case <-ticker.C:
+			current, err := r.snapshot(ctx, serviceName)
+			if err != nil {
+				r.logger.Warn("Failed to poll services", zap.String("service_name", serviceName), zap.Error(err))
+				continue
+			}
+			for _, event := range diffServices(known, current) {
+				select {
+				case watcher <- event:
+				default:
+					r.logger.Warn("Watcher channel full, skipping event",
+						zap.String("service_name", event.Service.Name),
+						zap.String("event_type", string(event.Type)))
+				}
+			}
+			known = current
+		}
+	}
+}
+
+func (r *HTTPServiceRegistry) snapshot(ctx context.Context, serviceName string) (map[string]*ServiceInfo, error) {
+	var services []*ServiceInfo
+	if serviceName != "" {
+		list, err := r.ListServices(ctx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+		services = list
+	} else {
+		all, err := r.ListAllServices(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, list := range all {
+			services = append(services, list...)
+		}
+	}
+
+	result := make(map[string]*ServiceInfo, len(services))
+	for _, service := range services {
+		result[service.ID] = service
+	}
+	return result, nil
+}
+
+// diffServices 比较两次快照生成服务事件
+func diffServices(previous, current map[string]*ServiceInfo) []*ServiceEvent {
+	now := time.Now()
+	var events []*ServiceEvent
+	for id, service := range current {
+		old, exists := previous[id]
+		switch {
+		case !exists:
+			events = append(events, &ServiceEvent{Type: EventTypeRegister, Service: service, Timestamp: now})
+		case old.Status != service.Status && service.Status == ServiceStatusActive:
+			events = append(events, &ServiceEvent{Type: EventTypeHealthy, Service: service, Timestamp: now})
+		case old.Status != service.Status:
+			events = append(events, &ServiceEvent{Type: EventTypeUnhealthy, Service: service, Timestamp: now})
+		case !sameService(old, service):
+			events = append(events, &ServiceEvent{Type: EventTypeUpdate, Service: service, Timestamp: now})
+		}
+	}
+	for id, service := range previous {
+		if _, exists := current[id]; !exists {
+			events = append(events, &ServiceEvent{Type: EventTypeDeregister, Service: service, Timestamp: now})
+		}
+	}
+	return events
+}
+
+// Heartbeat 发送心跳，服务已过期时返回 ErrServiceNotFound
+func (r *HTTPServiceRegistry) Heartbeat(ctx context.Context, serviceID string) error {
+	err := r.do(ctx, http.MethodPut, "/services/"+url.PathEscape(serviceID)+"/heartbeat", nil, nil)
+	if err != nil && !IsServiceNotFound(err) {
+		return fmt.Errorf("failed to send heartbeat: %w", err)
+	}
+	return err
+}
+
+// GetRegistryHealth 获取注册中心健康状态
+func (r *HTTPServiceRegistry) GetRegistryHealth(ctx context.Context) (*RegistryHealth, error) {
+	health := &RegistryHealth{
+		Status:    HealthStatusHealthy,
+		LastCheck: time.Now(),
+		Details: map[string]string{
+			"registry_type": "http",
+			"address":       r.baseURL,
+		},
+	}
+
+	all, err := r.ListAllServices(ctx)
+	if err != nil {
+		health.Status = HealthStatusUnhealthy
+		health.Details["error"] = err.Error()
+		return health, nil
+	}
+	for _, services := range all {
+		for _, service := range services {
+			health.TotalServices++
+			if service.Status == ServiceStatusActive {
+				health.HealthyServices++
+			} else {
+				health.UnhealthyServices++
+			}
+		}
+	}
+	return health, nil
+}
+
+// Close 停止心跳和监听，并注销本实例注册的服务
+func (r *HTTPServiceRegistry) Close() error {
+	select {
+	case <-r.closeCh:
+		return nil
+	default:
+		close(r.closeCh)
+	}
+
+	r.keepalive.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.keepalive.timeout)
+	defer cancel()
+	for _, serviceID := range r.keepalive.list() {
+		if err := r.DeregisterService(ctx, serviceID); err != nil && !IsServiceNotFound(err) {
+			r.logger.Warn("Failed to deregister service on close", zap.String("service_id", serviceID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (r *HTTPServiceRegistry) isLocal(serviceID string) bool {
+	for _, id := range r.keepalive.list() {
+		if id == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// do 发送请求并解析JSON响应，404 转换为 ErrServiceNotFound
+func (r *HTTPServiceRegistry) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if auth := r.config.Auth; auth != nil {
+		if auth.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+auth.Token)
+		} else if auth.Username != "" {
+			req.SetBasicAuth(auth.Username, auth.Password)
+		}
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s %s", ErrServiceNotFound, method, path)
+	}
+	if resp.StatusCode >= 300 {
+		var errResp struct {
+			Error   string `json:"error"`
+			Details string `json:"details"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("discovery returned %d: %s: %s", resp.StatusCode, errResp.Error, errResp.Details)
+		}
+		return fmt.Errorf("discovery returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode discovery response: %w", err)
+	}
+	return nil
+}
+
+// encodeServiceMeta 将版本、权重和状态写入元数据
+func encodeServiceMeta(service *ServiceInfo) map[string]string {
+	meta := make(map[string]string, len(service.Meta)+3)
+	for k, v := range service.Meta {
+		meta[k] = v
+	}
+	if service.Version != "" {
+		meta[metaVersion] = service.Version
+	}
+	meta[metaWeight] = strconv.Itoa(service.Weight)
+	meta[metaStatus] = string(service.Status)
+	return meta
+}
+
+func decodeInstances(instances []*discoveryInstance) []*ServiceInfo {
+	services := make([]*ServiceInfo, 0, len(instances))
+	for _, instance := range instances {
+		if instance != nil {
+			services = append(services, decodeInstance(instance))
+		}
+	}
+	return services
+}
+
+// decodeInstance 将 laojun-discovery 实例转换为服务信息
+func decodeInstance(instance *discoveryInstance) *ServiceInfo {
+	service := &ServiceInfo{
+		ID:       instance.ID,
+		Name:     instance.Name,
+		Address:  instance.Address,
+		Port:     instance.Port,
+		Tags:     instance.Tags,
+		Meta:     make(map[string]string, len(instance.Meta)),
+		TTL:      instance.TTL,
+		Weight:   1,
+		Status:   ServiceStatusActive,
+		LastSeen: instance.LastSeen,
+	}
+	for k, v := range instance.Meta {
+		switch k {
+		case metaVersion:
+			service.Version = v
+		case metaWeight:
+			if weight, err := strconv.Atoi(v); err == nil && weight > 0 {
+				service.Weight = weight
+			}
+		case metaStatus:
+			if v != "" {
+				service.Status = ServiceStatus(v)
+			}
+		default:
+			service.Meta[k] = v
+		}
+	}
+	if instance.Health.Status == "critical" {
+		service.Status = ServiceStatusInactive
+	}
+	return service
+}
@@ -143,6 +143,10 @@ type RegistryConfig struct {
 	Namespace         string            `json:"namespace"`
 	Tags              []string          `json:"tags"`
 	Meta              map[string]string `json:"meta"`
+
+	// EnableKeyspaceEvents 允许Redis注册中心在监听时通过 CONFIG SET 开启键空间通知。
+	// 该设置影响整个Redis实例，默认关闭，仅在缺少所需标志时告警
+	EnableKeyspaceEvents bool `json:"enable_keyspace_events"`
 }
 
 // AuthConfig 认证配置
@@ -212,6 +216,8 @@ const (
 	RegistryTypeZookeeper RegistryType = "zookeeper"
 	RegistryTypeRedis     RegistryType = "redis"
 	RegistryTypeMemory    RegistryType = "memory"
+	// RegistryTypeHTTP 通过HTTP API使用 laojun-discovery
+	RegistryTypeHTTP RegistryType = "http"
 )
 
 // LoadBalanceStrategy 负载均衡策略
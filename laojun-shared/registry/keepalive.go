@@ -0,0 +1,191 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// keepalive 为本实例注册的服务定期发送心跳，服务在注册中心过期后自动重新注册
+type keepalive struct {
+	interval  time.Duration
+	timeout   time.Duration
+	logger    *zap.Logger
+	heartbeat func(ctx context.Context, serviceID string) error
+	register  func(ctx context.Context, service *ServiceInfo) error
+
+	mu       sync.Mutex
+	services map[string]*ServiceInfo
+	stopCh   chan struct{}
+	done     chan struct{}
+	started  bool
+	stopped  bool
+}
+
+func newKeepalive(config *RegistryConfig, logger *zap.Logger,
+	heartbeat func(ctx context.Context, serviceID string) error,
+	register func(ctx context.Context, service *ServiceInfo) error) *keepalive {
+	interval := config.HeartbeatInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	timeout := config.Timeout
+	if timeout <= 0 || timeout > interval {
+		timeout = interval
+	}
+	return &keepalive{
+		interval:  interval,
+		timeout:   timeout,
+		logger:    logger,
+		heartbeat: heartbeat,
+		register:  register,
+		services:  make(map[string]*ServiceInfo),
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// add 记录本地服务，首次调用时启动心跳协程
+func (k *keepalive) add(service *ServiceInfo) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.stopped {
+		return
+	}
+	serviceCopy := *service
+	k.services[service.ID] = &serviceCopy
+	if !k.started {
+		k.started = true
+		go k.run()
+	}
+}
+
+// remove 停止服务心跳
+func (k *keepalive) remove(serviceID string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.services, serviceID)
+}
+
+// list 返回本地服务ID
+func (k *keepalive) list() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	ids := make([]string, 0, len(k.services))
+	for id := range k.services {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// stop 停止心跳协程
+func (k *keepalive) stop() {
+	k.mu.Lock()
+	if k.stopped {
+		k.mu.Unlock()
+		return
+	}
+	k.stopped = true
+	started := k.started
+	close(k.stopCh)
+	k.mu.Unlock()
+
+	if started {
+		<-k.done
+	}
+}
+
+func (k *keepalive) run() {
+	defer close(k.done)
+
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			k.beat()
+		case <-k.stopCh:
+			return
+		}
+	}
+}
+
+// beat 对所有本地服务发送一次心跳
+func (k *keepalive) beat() {
+	k.mu.Lock()
+	services := make([]*ServiceInfo, 0, len(k.services))
+	for _, service := range k.services {
+		services = append(services, service)
+	}
+	k.mu.Unlock()
+
+	for _, service := range services {
+		if err := k.beatOne(service); err != nil {
+			k.logger.Warn("Service heartbeat failed",
+				zap.String("service_id", service.ID),
+				zap.String("service_name", service.Name),
+				zap.Error(err))
+		}
+	}
+}
+
+func (k *keepalive) beatOne(service *ServiceInfo) error {
+	ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
+	defer cancel()
+
+	err := k.heartbeat(ctx, service.ID)
+	if !IsServiceNotFound(err) {
+		return err
+	}
+
+	// 注册信息已过期（如注册中心重启或网络分区），重新注册
+	k.logger.Info("Service expired in registry, re-registering",
+		zap.String("service_id", service.ID),
+		zap.String("service_name", service.Name))
+	serviceCopy := *service
+	if err := k.register(ctx, &serviceCopy); err != nil {
+		return fmt.Errorf("re-register service: %w", err)
+	}
+	return nil
+}
+
+// prepareService 校验服务信息并填充默认值
+func prepareService(service *ServiceInfo, config *RegistryConfig) error {
+	if service.ID == "" {
+		return fmt.Errorf("service ID cannot be empty")
+	}
+	if service.Name == "" {
+		return fmt.Errorf("service name cannot be empty")
+	}
+	if service.Address == "" {
+		return fmt.Errorf("service address cannot be empty")
+	}
+	if service.Port <= 0 {
+		return fmt.Errorf("service port must be positive")
+	}
+
+	now := time.Now()
+	if service.RegisteredAt.IsZero() {
+		service.RegisteredAt = now
+	}
+	service.LastSeen = now
+	if service.Status == "" {
+		service.Status = ServiceStatusActive
+	}
+	if service.TTL <= 0 {
+		service.TTL = int(config.TTL.Seconds())
+		if service.TTL <= 0 {
+			service.TTL = 30
+		}
+	}
+	if service.Weight <= 0 {
+		service.Weight = 1
+	}
+	return nil
+}
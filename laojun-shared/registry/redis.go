@@ -0,0 +1,589 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// RedisServiceRegistry 基于Redis的服务注册实现
+//
+// 每个服务实例保存为带TTL的键，心跳刷新TTL，实例过期后由Redis自动删除；
+// 服务变化通过键空间通知（keyspace notification）推送给监听者。
+//
+// 键布局（prefix 为 "<namespace>:registry"）：
+//
+//	<prefix>:instance:<id>  服务实例JSON，带TTL
+//	<prefix>:service:<name> 服务实例ID集合
+//	<prefix>:services       服务名集合
+type RedisServiceRegistry struct {
+	config     *RegistryConfig
+	client     redis.UniversalClient
+	logger     *zap.Logger
+	prefix     string
+	db         int
+	ownsClient bool
+	keepalive  *keepalive
+	closeCh    chan struct{}
+}
+
+// NewRedisServiceRegistry 使用已有的Redis客户端创建服务注册实例
+func NewRedisServiceRegistry(client redis.UniversalClient, config *RegistryConfig, logger *zap.Logger) *RedisServiceRegistry {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = "laojun"
+	}
+
+	r := &RedisServiceRegistry{
+		config:  config,
+		client:  client,
+		logger:  logger,
+		prefix:  namespace + ":registry",
+		closeCh: make(chan struct{}),
+	}
+	if c, ok := client.(*redis.Client); ok {
+		r.db = c.Options().DB
+	}
+	r.keepalive = newKeepalive(config, logger, r.Heartbeat, r.register)
+	return r
+}
+
+// newRedisClient 根据配置创建Redis客户端，地址格式为 redis://[user:pass@]host:port/db
+func newRedisClient(config *RegistryConfig) (*redis.Client, error) {
+	address := config.Address
+	if !strings.Contains(address, "://") {
+		address = "redis://" + address
+	}
+
+	options, err := redis.ParseURL(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis address %q: %w", config.Address, err)
+	}
+	if config.Auth != nil {
+		if config.Auth.Username != "" {
+			options.Username = config.Auth.Username
+		}
+		if config.Auth.Password != "" {
+			options.Password = config.Auth.Password
+		}
+	}
+	if config.TLS != nil && config.TLS.Enabled && options.TLSConfig == nil {
+		options.TLSConfig = &tls.Config{InsecureSkipVerify: config.TLS.SkipVerify}
+	}
+	if config.Timeout > 0 {
+		options.DialTimeout = config.Timeout
+	}
+	if config.MaxRetries > 0 {
+		options.MaxRetries = config.MaxRetries
+	}
+	return redis.NewClient(options), nil
+}
+
+// RegisterService 注册服务，并为其启动心跳
+func (r *RedisServiceRegistry) RegisterService(ctx context.Context, service *ServiceInfo) error {
+	if err := prepareService(service, r.config); err != nil {
+		return err
+	}
+	if err := r.register(ctx, service); err != nil {
+		return err
+	}
+	r.keepalive.add(service)
+
+	r.logger.Info("Service registered",
+		zap.String("service_id", service.ID),
+		zap.String("service_name", service.Name),
+		zap.String("address", fmt.Sprintf("%s:%d", service.Address, service.Port)))
+	return nil
+}
+
+// register 写入服务实例及索引
+func (r *RedisServiceRegistry) register(ctx context.Context, service *ServiceInfo) error {
+	data, err := json.Marshal(service)
+	if err != nil {
+		return fmt.Errorf("failed to marshal service: %w", err)
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, r.instanceKey(service.ID), data, ttlOf(service))
+	pipe.SAdd(ctx, r.serviceKey(service.Name), service.ID)
+	pipe.SAdd(ctx, r.servicesKey(), service.Name)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to register service: %w", err)
+	}
+	return nil
+}
+
+// DeregisterService 注销服务
+func (r *RedisServiceRegistry) DeregisterService(ctx context.Context, serviceID string) error {
+	r.keepalive.remove(serviceID)
+
+	service, err := r.GetService(ctx, serviceID)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, r.instanceKey(serviceID))
+	pipe.SRem(ctx, r.serviceKey(service.Name), serviceID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to deregister service: %w", err)
+	}
+
+	r.logger.Info("Service deregistered",
+		zap.String("service_id", serviceID),
+		zap.String("service_name", service.Name))
+	return nil
+}
+
+// UpdateService 更新服务信息，服务必须已注册
+func (r *RedisServiceRegistry) UpdateService(ctx context.Context, service *ServiceInfo) error {
+	existing, err := r.GetService(ctx, service.ID)
+	if err != nil {
+		return err
+	}
+	if service.Name != existing.Name {
+		return fmt.Errorf("service name cannot be changed: %s", service.ID)
+	}
+	if service.RegisteredAt.IsZero() {
+		service.RegisteredAt = existing.RegisteredAt
+	}
+	if err := prepareService(service, r.config); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(service)
+	if err != nil {
+		return fmt.Errorf("failed to marshal service: %w", err)
+	}
+	ok, err := r.client.SetXX(ctx, r.instanceKey(service.ID), data, ttlOf(service)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to update service: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, service.ID)
+	}
+
+	if r.isLocal(service.ID) {
+		r.keepalive.add(service)
+	}
+	r.logger.Info("Service updated",
+		zap.String("service_id", service.ID),
+		zap.String("service_name", service.Name))
+	return nil
+}
+
+// GetService 获取服务信息
+func (r *RedisServiceRegistry) GetService(ctx context.Context, serviceID string) (*ServiceInfo, error) {
+	data, err := r.client.Get(ctx, r.instanceKey(serviceID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	return decodeService(data)
+}
+
+// ListServices 列出指定名称的服务，顺带清理已过期实例的索引
+func (r *RedisServiceRegistry) ListServices(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	ids, err := r.client.SMembers(ctx, r.serviceKey(serviceName)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.instanceKey(id)
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	var services []*ServiceInfo
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+		service, err := decodeService([]byte(data))
+		if err != nil {
+			r.logger.Warn("Skipping invalid service entry", zap.String("service_id", ids[i]), zap.Error(err))
+			continue
+		}
+		services = append(services, service)
+	}
+
+	if len(expired) > 0 {
+		if err := r.client.SRem(ctx, r.serviceKey(serviceName), expired...).Err(); err != nil {
+			r.logger.Debug("Failed to prune expired services", zap.Error(err))
+		}
+	}
+	return services, nil
+}
+
+// ListAllServices 列出所有服务
+func (r *RedisServiceRegistry) ListAllServices(ctx context.Context) (map[string][]*ServiceInfo, error) {
+	names, err := r.client.SMembers(ctx, r.servicesKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list all services: %w", err)
+	}
+
+	result := make(map[string][]*ServiceInfo)
+	for _, name := range names {
+		services, err := r.ListServices(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(services) > 0 {
+			result[name] = services
+		}
+	}
+	return result, nil
+}
+
+// GetHealthyServices 获取健康的服务实例，过期实例已由Redis删除
+func (r *RedisServiceRegistry) GetHealthyServices(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	services, err := r.ListServices(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var healthyServices []*ServiceInfo
+	for _, service := range services {
+		if service.Status == ServiceStatusActive {
+			healthyServices = append(healthyServices, service)
+		}
+	}
+	return healthyServices, nil
+}
+
+// Heartbeat 发送心跳，刷新实例TTL和最后活跃时间
+func (r *RedisServiceRegistry) Heartbeat(ctx context.Context, serviceID string) error {
+	key := r.instanceKey(serviceID)
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceID)
+		}
+		if err != nil {
+			return err
+		}
+
+		service, err := decodeService(data)
+		if err != nil {
+			return err
+		}
+		service.LastSeen = time.Now()
+		data, err = json.Marshal(service)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SetXX(ctx, key, data, ttlOf(service))
+			// 列表清理可能在实例续约前移除了ID，心跳时补回索引
+			pipe.SAdd(ctx, r.serviceKey(service.Name), serviceID)
+			return nil
+		})
+		return err
+	}, key)
+
+	if errors.Is(err, redis.TxFailedErr) {
+		// 并发更新已刷新TTL
+		return nil
+	}
+	if err != nil && !IsServiceNotFound(err) {
+		return fmt.Errorf("failed to send heartbeat: %w", err)
+	}
+	return err
+}
+
+// WatchServices 通过键空间通知监听服务变化，serviceName 为空时监听所有服务。
+// 需要Redis开启 notify-keyspace-events（至少 K$gx）。未开启时仅告警，
+// 配置 EnableKeyspaceEvents 后会尝试通过 CONFIG SET 自动开启。
+func (r *RedisServiceRegistry) WatchServices(ctx context.Context, serviceName string) (<-chan *ServiceEvent, error) {
+	r.ensureKeyspaceEvents(ctx)
+
+	pubsub := r.client.PSubscribe(ctx, r.keyspaceChannel(r.instanceKey("*")))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe keyspace events: %w", err)
+	}
+
+	// 订阅之后再加载快照，避免遗漏期间的变化
+	known := make(map[string]*ServiceInfo)
+	var snapshot []*ServiceInfo
+	if serviceName != "" {
+		services, err := r.ListServices(ctx, serviceName)
+		if err != nil {
+			pubsub.Close()
+			return nil, err
+		}
+		snapshot = services
+	} else {
+		all, err := r.ListAllServices(ctx)
+		if err != nil {
+			pubsub.Close()
+			return nil, err
+		}
+		for _, services := range all {
+			snapshot = append(snapshot, services...)
+		}
+	}
+	for _, service := range snapshot {
+		known[service.ID] = service
+	}
+
+	watcher := make(chan *ServiceEvent, 100)
+	go r.watch(ctx, pubsub, serviceName, known, watcher)
+	return watcher, nil
+}
+
+func (r *RedisServiceRegistry) watch(ctx context.Context, pubsub *redis.PubSub, serviceName string, known map[string]*ServiceInfo, watcher chan *ServiceEvent) {
+	defer close(watcher)
+	defer pubsub.Close()
+
+	channelPrefix := r.keyspaceChannel(r.instanceKey(""))
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.closeCh:
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			serviceID := strings.TrimPrefix(msg.Channel, channelPrefix)
+			event := r.eventFor(ctx, serviceName, serviceID, msg.Payload, known)
+			if event == nil {
+				continue
+			}
+			select {
+			case watcher <- event:
+			default:
+				r.logger.Warn("Watcher channel full, skipping event",
+					zap.String("service_name", event.Service.Name),
+					zap.String("event_type", string(event.Type)))
+			}
+		}
+	}
+}
+
+// eventFor 将键空间事件转换为服务事件，无变化时返回nil
+func (r *RedisServiceRegistry) eventFor(ctx context.Context, serviceName, serviceID, operation string, known map[string]*ServiceInfo) *ServiceEvent {
+	now := time.Now()
+	switch operation {
+	case "set":
+		service, err := r.GetService(ctx, serviceID)
+		if err != nil {
+			return nil
+		}
+		if serviceName != "" && service.Name != serviceName {
+			return nil
+		}
+
+		previous, exists := known[serviceID]
+		known[serviceID] = service
+		if !exists {
+			return &ServiceEvent{Type: EventTypeRegister, Service: service, Timestamp: now}
+		}
+		if sameService(previous, service) {
+			// 心跳只刷新最后活跃时间
+			return nil
+		}
+		eventType := EventTypeUpdate
+		if previous.Status != service.Status {
+			if service.Status == ServiceStatusActive {
+				eventType = EventTypeHealthy
+			} else {
+				eventType = EventTypeUnhealthy
+			}
+		}
+		return &ServiceEvent{Type: eventType, Service: service, Timestamp: now}
+
+	case "del", "expired":
+		service, exists := known[serviceID]
+		if !exists {
+			return nil
+		}
+		delete(known, serviceID)
+		return &ServiceEvent{Type: EventTypeDeregister, Service: service, Timestamp: now}
+	}
+	return nil
+}
+
+// ensureKeyspaceEvents 检查所需的键空间通知，仅在配置允许时尝试开启
+func (r *RedisServiceRegistry) ensureKeyspaceEvents(ctx context.Context) {
+	values, err := r.client.ConfigGet(ctx, "notify-keyspace-events").Result()
+	if err != nil {
+		r.logger.Warn("Unable to check redis notify-keyspace-events, watch may miss events", zap.Error(err))
+		return
+	}
+
+	current := values["notify-keyspace-events"]
+	flags, changed := keyspaceEventFlags(current)
+	if !changed {
+		return
+	}
+	if !r.config.EnableKeyspaceEvents {
+		r.logger.Warn("Redis keyspace notifications are not fully enabled, watch may miss events",
+			zap.String("current", current),
+			zap.String("required", flags))
+		return
+	}
+	if err := r.client.ConfigSet(ctx, "notify-keyspace-events", flags).Err(); err != nil {
+		r.logger.Warn("Unable to enable redis keyspace notifications, watch may miss events",
+			zap.String("current", current),
+			zap.Error(err))
+	}
+}
+
+// keyspaceEventFlags 在现有配置上补齐 K$gx 标志
+func keyspaceEventFlags(current string) (string, bool) {
+	flags := current
+	required := "K$gx"
+	if strings.Contains(flags, "A") {
+		required = "K"
+	}
+	for _, flag := range required {
+		if !strings.ContainsRune(flags, flag) {
+			flags += string(flag)
+		}
+	}
+	return flags, flags != current
+}
+
+// GetRegistryHealth 获取注册中心健康状态
+func (r *RedisServiceRegistry) GetRegistryHealth(ctx context.Context) (*RegistryHealth, error) {
+	health := &RegistryHealth{
+		Status:    HealthStatusHealthy,
+		LastCheck: time.Now(),
+		Details: map[string]string{
+			"registry_type": string(RegistryTypeRedis),
+			"namespace":     r.config.Namespace,
+		},
+	}
+
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		health.Status = HealthStatusUnhealthy
+		health.Details["error"] = err.Error()
+		return health, nil
+	}
+
+	all, err := r.ListAllServices(ctx)
+	if err != nil {
+		health.Status = HealthStatusDegraded
+		health.Details["error"] = err.Error()
+		return health, nil
+	}
+	for _, services := range all {
+		for _, service := range services {
+			health.TotalServices++
+			if service.Status == ServiceStatusActive {
+				health.HealthyServices++
+			} else {
+				health.UnhealthyServices++
+			}
+		}
+	}
+	return health, nil
+}
+
+// Close 停止心跳和监听，并注销本实例注册的服务
+func (r *RedisServiceRegistry) Close() error {
+	select {
+	case <-r.closeCh:
+		return nil
+	default:
+		close(r.closeCh)
+	}
+
+	r.keepalive.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.closeTimeout())
+	defer cancel()
+	for _, serviceID := range r.keepalive.list() {
+		if err := r.DeregisterService(ctx, serviceID); err != nil && !IsServiceNotFound(err) {
+			r.logger.Warn("Failed to deregister service on close", zap.String("service_id", serviceID), zap.Error(err))
+		}
+	}
+
+	if r.ownsClient {
+		return r.client.Close()
+	}
+	return nil
+}
+
+func (r *RedisServiceRegistry) closeTimeout() time.Duration {
+	if r.config.Timeout > 0 {
+		return r.config.Timeout
+	}
+	return 5 * time.Second
+}
+
+func (r *RedisServiceRegistry) isLocal(serviceID string) bool {
+	for _, id := range r.keepalive.list() {
+		if id == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RedisServiceRegistry) instanceKey(serviceID string) string {
+	return r.prefix + ":instance:" + serviceID
+}
+
+func (r *RedisServiceRegistry) serviceKey(serviceName string) string {
+	return r.prefix + ":service:" + serviceName
+}
+
+func (r *RedisServiceRegistry) servicesKey() string {
+	return r.prefix + ":services"
+}
+
+func (r *RedisServiceRegistry) keyspaceChannel(key string) string {
+	return fmt.Sprintf("__keyspace@%d__:%s", r.db, key)
+}
+
+// ttlOf 返回服务实例的TTL
+func ttlOf(service *ServiceInfo) time.Duration {
+	return time.Duration(service.TTL) * time.Second
+}
+
+func decodeService(data []byte) (*ServiceInfo, error) {
+	var service ServiceInfo
+	if err := json.Unmarshal(data, &service); err != nil {
+		return nil, fmt.Errorf("failed to decode service: %w", err)
+	}
+	return &service, nil
+}
+
+// sameService 比较服务信息，忽略最后活跃时间
+func sameService(a, b *ServiceInfo) bool {
+	ac, bc := *a, *b
+	ac.LastSeen, bc.LastSeen = time.Time{}, time.Time{}
+	aj, errA := json.Marshal(&ac)
+	bj, errB := json.Marshal(&bc)
+	return errA == nil && errB == nil && bytes.Equal(aj, bj)
+}
@@ -0,0 +1,357 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/internal/redistest"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+func TestCreateRegistryUnsupportedType(t *testing.T) {
+	factory := NewRegistryFactory(zap.NewNop())
+
+	for _, registryType := range []RegistryType{RegistryTypeEtcd, RegistryTypeZookeeper, "unknown"} {
+		_, err := factory.CreateRegistry(factory.CreateDefaultConfig(registryType))
+		if !errors.Is(err, ErrUnsupportedRegistry) {
+			t.Errorf("%s: expected ErrUnsupportedRegistry, got %v", registryType, err)
+		}
+	}
+
+	for _, registryType := range []RegistryType{RegistryTypeMemory, RegistryTypeRedis, RegistryTypeHTTP, RegistryTypeConsul} {
+		registry, err := factory.CreateRegistry(factory.CreateDefaultConfig(registryType))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", registryType, err)
+		}
+		if closer, ok := registry.(interface{ Close() error }); ok {
+			closer.Close()
+		}
+	}
+}
+
+// fakeDiscovery 模拟 laojun-discovery 的注册接口
+type fakeDiscovery struct {
+	mu         sync.Mutex
+	instances  map[string]*discoveryInstance
+	heartbeats map[string]int
+}
+
+func newFakeDiscovery() *fakeDiscovery {
+	return &fakeDiscovery{
+		instances:  make(map[string]*discoveryInstance),
+		heartbeats: make(map[string]int),
+	}
+}
+
+func (f *fakeDiscovery) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/services")
+	writeJSON := func(status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case r.Method == http.MethodPost && path == "":
+		var instance discoveryInstance
+		json.NewDecoder(r.Body).Decode(&instance)
+		instance.Health.Status = "passing"
+		f.instances[instance.ID] = &instance
+		writeJSON(http.StatusCreated, map[string]interface{}{"service": instance})
+
+	case r.Method == http.MethodGet && path == "":
+		if name := r.URL.Query().Get("name"); name != "" {
+			var instances []*discoveryInstance
+			for _, instance := range f.instances {
+				if instance.Name == name {
+					instances = append(instances, instance)
+				}
+			}
+			writeJSON(http.StatusOK, map[string]interface{}{"instances": instances})
+			return
+		}
+		services := make(map[string][]*discoveryInstance)
+		for _, instance := range f.instances {
+			services[instance.Name] = append(services[instance.Name], instance)
+		}
+		writeJSON(http.StatusOK, map[string]interface{}{"services": services})
+
+	case r.Method == http.MethodPut && strings.HasSuffix(path, "/heartbeat"):
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/heartbeat")
+		if _, ok := f.instances[id]; !ok {
+			writeJSON(http.StatusNotFound, map[string]string{"error": "Service not found"})
+			return
+		}
+		f.heartbeats[id]++
+		writeJSON(http.StatusOK, map[string]string{"message": "ok"})
+
+	case r.Method == http.MethodDelete:
+		id := strings.TrimPrefix(path, "/")
+		if _, ok := f.instances[id]; !ok {
+			writeJSON(http.StatusNotFound, map[string]string{"error": "Service not found"})
+			return
+		}
+		delete(f.instances, id)
+		writeJSON(http.StatusOK, map[string]string{"message": "ok"})
+
+	default:
+		writeJSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+}
+
+func (f *fakeDiscovery) expire(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.instances, id)
+}
+
+func (f *fakeDiscovery) has(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.instances[id]
+	return ok
+}
+
+func TestHTTPServiceRegistry(t *testing.T) {
+	fake := newFakeDiscovery()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	config := NewRegistryBuilder().
+		WithType(RegistryTypeHTTP).
+		WithAddress(server.URL).
+		WithHeartbeatInterval(20 * time.Millisecond).
+		WithTimeout(time.Second).
+		config
+	registry, err := NewHTTPServiceRegistry(config, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewHTTPServiceRegistry() error = %v", err)
+	}
+	defer registry.Close()
+
+	ctx := context.Background()
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer cancelWatch()
+	events, err := registry.WatchServices(watchCtx, "marketplace")
+	if err != nil {
+		t.Fatalf("WatchServices() error = %v", err)
+	}
+
+	service := &ServiceInfo{
+		ID:      "marketplace-1",
+		Name:    "marketplace",
+		Version: "1.2.0",
+		Address: "10.0.0.1",
+		Port:    8082,
+		Weight:  3,
+		Meta:    map[string]string{"zone": "a"},
+	}
+	if err := registry.RegisterService(ctx, service); err != nil {
+		t.Fatalf("RegisterService() error = %v", err)
+	}
+
+	services, err := registry.ListServices(ctx, "marketplace")
+	if err != nil || len(services) != 1 {
+		t.Fatalf("ListServices() = %v, %v", services, err)
+	}
+	got := services[0]
+	if got.Version != "1.2.0" || got.Weight != 3 || got.Meta["zone"] != "a" || got.Status != ServiceStatusActive {
+		t.Errorf("service fields not preserved: %+v", got)
+	}
+	if _, ok := got.Meta[metaWeight]; ok {
+		t.Errorf("internal meta leaked: %v", got.Meta)
+	}
+
+	expectEvent(t, events, EventTypeRegister, "marketplace-1")
+
+	// 注册中心丢失实例后，心跳应重新注册
+	fake.expire("marketplace-1")
+	deadline := time.Now().Add(2 * time.Second)
+	for !fake.has("marketplace-1") {
+		if time.Now().After(deadline) {
+			t.Fatal("service was not re-registered after expiry")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := registry.DeregisterService(ctx, "marketplace-1"); err != nil {
+		t.Fatalf("DeregisterService() error = %v", err)
+	}
+	expectEvent(t, events, EventTypeDeregister, "marketplace-1")
+
+	if _, err := registry.GetService(ctx, "marketplace-1"); !IsServiceNotFound(err) {
+		t.Errorf("expected ErrServiceNotFound, got %v", err)
+	}
+	if err := registry.Heartbeat(ctx, "marketplace-1"); !IsServiceNotFound(err) {
+		t.Errorf("expected ErrServiceNotFound from heartbeat, got %v", err)
+	}
+}
+
+func expectEvent(t *testing.T, events <-chan *ServiceEvent, eventType EventType, serviceID string) {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == eventType && event.Service.ID == serviceID {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s event of %s", eventType, serviceID)
+		}
+	}
+}
+
+func TestDiscoveryBaseURL(t *testing.T) {
+	tests := map[string]string{
+		"discovery:8084":           "http://discovery:8084/api/v1",
+		"http://discovery:8084/":   "http://discovery:8084/api/v1",
+		"https://discovery/api/v1": "https://discovery/api/v1",
+		"http://gateway/discovery": "http://gateway/discovery/api/v1",
+	}
+	for address, want := range tests {
+		got, err := discoveryBaseURL(address)
+		if err != nil || got != want {
+			t.Errorf("discoveryBaseURL(%q) = %q, %v; want %q", address, got, err, want)
+		}
+	}
+	if _, err := discoveryBaseURL("redis://localhost"); err == nil {
+		t.Error("expected error for non-http scheme")
+	}
+}
+
+func TestDiffServices(t *testing.T) {
+	a := &ServiceInfo{ID: "a", Name: "svc", Status: ServiceStatusActive, LastSeen: time.Now()}
+	b := &ServiceInfo{ID: "b", Name: "svc", Status: ServiceStatusActive}
+	aHeartbeat := *a
+	aHeartbeat.LastSeen = a.LastSeen.Add(time.Second)
+	bDown := *b
+	bDown.Status = ServiceStatusInactive
+	c := &ServiceInfo{ID: "c", Name: "svc", Status: ServiceStatusActive}
+
+	events := diffServices(
+		map[string]*ServiceInfo{"a": a, "b": b},
+		map[string]*ServiceInfo{"a": &aHeartbeat, "b": &bDown, "c": c},
+	)
+
+	got := make(map[string]EventType)
+	for _, event := range events {
+		got[event.Service.ID] = event.Type
+	}
+	want := map[string]EventType{"b": EventTypeUnhealthy, "c": EventTypeRegister}
+	if len(got) != len(want) || got["b"] != want["b"] || got["c"] != want["c"] {
+		t.Errorf("diffServices() = %v, want %v", got, want)
+	}
+}
+
+func TestKeyspaceEventFlags(t *testing.T) {
+	tests := []struct {
+		current string
+		want    string
+		changed bool
+	}{
+		{"", "K$gx", true},
+		{"Ex", "ExK$g", true},
+		{"AK", "AK", false},
+		{"KA", "KA", false},
+		{"K$gx", "K$gx", false},
+	}
+	for _, tt := range tests {
+		got, changed := keyspaceEventFlags(tt.current)
+		if got != tt.want || changed != tt.changed {
+			t.Errorf("keyspaceEventFlags(%q) = %q, %v; want %q, %v", tt.current, got, changed, tt.want, tt.changed)
+		}
+	}
+}
+
+// TestRedisServiceRegistry 需要真实Redis，设置 REDIS_ADDR 后运行
+func TestRedisServiceRegistry(t *testing.T) {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+
+	config := NewRegistryBuilder().
+		WithType(RegistryTypeRedis).
+		WithNamespace("registry-test-" + time.Now().Format("150405.000")).
+		WithHeartbeatInterval(200 * time.Millisecond).
+		WithKeyspaceEvents(true).
+		config
+	registry := NewRedisServiceRegistry(client, config, zap.NewNop())
+	defer registry.Close()
+
+	ctx := context.Background()
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer cancelWatch()
+	events, err := registry.WatchServices(watchCtx, "plugins")
+	if err != nil {
+		t.Fatalf("WatchServices() error = %v", err)
+	}
+
+	service := &ServiceInfo{ID: "plugins-1", Name: "plugins", Address: "127.0.0.1", Port: 9000, TTL: 1}
+	if err := registry.RegisterService(ctx, service); err != nil {
+		t.Fatalf("RegisterService() error = %v", err)
+	}
+	expectEvent(t, events, EventTypeRegister, "plugins-1")
+
+	// 心跳使实例在TTL之后依然存在
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := registry.GetService(ctx, "plugins-1"); err != nil {
+		t.Fatalf("service expired despite heartbeats: %v", err)
+	}
+
+	service.Status = ServiceStatusMaintenance
+	if err := registry.UpdateService(ctx, service); err != nil {
+		t.Fatalf("UpdateService() error = %v", err)
+	}
+	expectEvent(t, events, EventTypeUnhealthy, "plugins-1")
+
+	healthy, err := registry.GetHealthyServices(ctx, "plugins")
+	if err != nil || len(healthy) != 0 {
+		t.Errorf("GetHealthyServices() = %v, %v", healthy, err)
+	}
+
+	if err := registry.DeregisterService(ctx, "plugins-1"); err != nil {
+		t.Fatalf("DeregisterService() error = %v", err)
+	}
+	expectEvent(t, events, EventTypeDeregister, "plugins-1")
+}
+
+func TestRedisHeartbeatRestoresIndex(t *testing.T) {
+	server := redistest.New(t)
+	config := NewRegistryBuilder().WithType(RegistryTypeRedis).config
+	registry := NewRedisServiceRegistry(server.Client(t), config, zap.NewNop())
+	defer registry.Close()
+
+	ctx := context.Background()
+	service := &ServiceInfo{ID: "plugins-1", Name: "plugins", Address: "127.0.0.1", Port: 9000, TTL: 30}
+	if err := registry.RegisterService(ctx, service); err != nil {
+		t.Fatalf("RegisterService() error = %v", err)
+	}
+
+	// 模拟列表清理与续约交错，ID被移出索引而实例仍存活
+	server.SRem(registry.serviceKey("plugins"), "plugins-1")
+	if err := registry.Heartbeat(ctx, "plugins-1"); err != nil {
+		t.Fatalf("Heartbeat() error = %v", err)
+	}
+
+	services, err := registry.ListServices(ctx, "plugins")
+	if err != nil || len(services) != 1 || services[0].ID != "plugins-1" {
+		t.Fatalf("ListServices() = %v, %v; want plugins-1", services, err)
+	}
+}
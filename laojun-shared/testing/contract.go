@@ -0,0 +1,220 @@
+package testing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ContractSpecVersion 契约文件遵循的 Pact 规范版本
+const ContractSpecVersion = "2.0.0"
+
+var (
+	// ErrInvalidContract 契约文件内容不合法
+	ErrInvalidContract = errors.New("invalid contract")
+)
+
+// Pacticipant 契约参与方（消费者或提供者）
+type Pacticipant struct {
+	Name string `json:"name"`
+}
+
+// Contract 消费者与提供者之间的契约，文件格式兼容 Pact v2
+type Contract struct {
+	Consumer     Pacticipant            `json:"consumer"`
+	Provider     Pacticipant            `json:"provider"`
+	Interactions []Interaction          `json:"interactions"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Interaction 一次请求/响应交互
+type Interaction struct {
+	Description   string           `json:"description"`
+	ProviderState string           `json:"providerState,omitempty"`
+	Request       ContractRequest  `json:"request"`
+	Response      ContractResponse `json:"response"`
+}
+
+// ContractRequest 消费者发出的请求
+type ContractRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// ContractResponse 消费者期望的响应
+type ContractResponse struct {
+	Status        int                     `json:"status"`
+	Headers       map[string]string       `json:"headers,omitempty"`
+	Body          interface{}             `json:"body,omitempty"`
+	MatchingRules map[string]MatchingRule `json:"matchingRules,omitempty"`
+}
+
+// MatchingRule 响应字段的匹配规则，键为 $.body.items[*].id、$.headers.X-Request-ID 形式的路径
+type MatchingRule struct {
+	Match string `json:"match,omitempty"` // type 或 regex
+	Regex string `json:"regex,omitempty"`
+	Min   int    `json:"min,omitempty"` // 数组最少元素个数
+}
+
+// MatchType 只校验类型，不校验具体值
+func MatchType() MatchingRule {
+	return MatchingRule{Match: "type"}
+}
+
+// MatchRegex 字符串值需匹配正则
+func MatchRegex(pattern string) MatchingRule {
+	return MatchingRule{Match: "regex", Regex: pattern}
+}
+
+// EachLike 数组每个元素都按第一个示例元素的类型匹配，且至少有 min 个元素
+func EachLike(min int) MatchingRule {
+	return MatchingRule{Match: "type", Min: min}
+}
+
+// key 交互在契约内的唯一标识
+func (i Interaction) key() string {
+	return i.ProviderState + "\x00" + i.Description
+}
+
+// Validate 校验契约内容
+func (c *Contract) Validate() error {
+	if c.Consumer.Name == "" || c.Provider.Name == "" {
+		return fmt.Errorf("%w: consumer and provider names are required", ErrInvalidContract)
+	}
+	seen := make(map[string]bool, len(c.Interactions))
+	for _, interaction := range c.Interactions {
+		if interaction.Description == "" {
+			return fmt.Errorf("%w: interaction description is required", ErrInvalidContract)
+		}
+		if interaction.Request.Method == "" || !strings.HasPrefix(interaction.Request.Path, "/") {
+			return fmt.Errorf("%w: interaction %q: request method and absolute path are required", ErrInvalidContract, interaction.Description)
+		}
+		if interaction.Response.Status == 0 {
+			return fmt.Errorf("%w: interaction %q: response status is required", ErrInvalidContract, interaction.Description)
+		}
+		if seen[interaction.key()] {
+			return fmt.Errorf("%w: duplicate interaction %q", ErrInvalidContract, interaction.Description)
+		}
+		seen[interaction.key()] = true
+	}
+	return nil
+}
+
+// FileName 契约文件名：<consumer>-<provider>.json
+func (c *Contract) FileName() string {
+	return c.Consumer.Name + "-" + c.Provider.Name + ".json"
+}
+
+// WriteFile 将契约写入 dir 目录，返回文件路径
+func (c *Contract) WriteFile(dir string) (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+	if c.Metadata == nil {
+		c.Metadata = map[string]interface{}{
+			"pactSpecification": map[string]string{"version": ContractSpecVersion},
+		}
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal contract: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create contract dir: %w", err)
+	}
+	path := filepath.Join(dir, c.FileName())
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("write contract: %w", err)
+	}
+	return path, nil
+}
+
+// LoadContract 从文件加载契约
+func LoadContract(path string) (*Contract, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read contract: %w", err)
+	}
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidContract, path, err)
+	}
+	if err := contract.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &contract, nil
+}
+
+// LoadContracts 加载目录下所有指定提供者的契约，provider 为空时加载全部
+func LoadContracts(dir, provider string) ([]*Contract, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var contracts []*Contract
+	for _, path := range paths {
+		contract, err := LoadContract(path)
+		if err != nil {
+			return nil, err
+		}
+		if provider == "" || contract.Provider.Name == provider {
+			contracts = append(contracts, contract)
+		}
+	}
+	return contracts, nil
+}
+
+// ContractRecorder 在消费者测试中收集交互并写出契约文件
+type ContractRecorder struct {
+	mu       sync.Mutex
+	contract Contract
+}
+
+// NewContractRecorder 创建契约记录器
+func NewContractRecorder(consumer, provider string) *ContractRecorder {
+	return &ContractRecorder{
+		contract: Contract{
+			Consumer: Pacticipant{Name: consumer},
+			Provider: Pacticipant{Name: provider},
+		},
+	}
+}
+
+// AddInteraction 添加交互，描述与提供者状态相同的交互会被覆盖
+func (r *ContractRecorder) AddInteraction(interaction Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.contract.Interactions {
+		if existing.key() == interaction.key() {
+			r.contract.Interactions[i] = interaction
+			return
+		}
+	}
+	r.contract.Interactions = append(r.contract.Interactions, interaction)
+}
+
+// Contract 返回当前已记录的契约副本
+func (r *ContractRecorder) Contract() *Contract {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contract := r.contract
+	contract.Interactions = append([]Interaction(nil), r.contract.Interactions...)
+	return &contract
+}
+
+// WriteFile 将记录的契约写入 dir 目录
+func (r *ContractRecorder) WriteFile(dir string) (string, error) {
+	return r.Contract().WriteFile(dir)
+}
@@ -0,0 +1,215 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// matcher 按匹配规则比较期望值与实际值，收集所有不匹配项
+type matcher struct {
+	rules      map[string]MatchingRule
+	mismatches []string
+}
+
+func newMatcher(rules map[string]MatchingRule) *matcher {
+	return &matcher{rules: rules}
+}
+
+// rule 查找路径对应的规则，先精确匹配，再把数组下标替换为 [*] 匹配
+func (m *matcher) rule(path string) (MatchingRule, bool) {
+	if rule, ok := m.rules[path]; ok {
+		return rule, true
+	}
+	rule, ok := m.rules[indexPattern.ReplaceAllString(path, "[*]")]
+	return rule, ok
+}
+
+func (m *matcher) fail(path, format string, args ...interface{}) {
+	m.mismatches = append(m.mismatches, path+": "+fmt.Sprintf(format, args...))
+}
+
+// match 比较 JSON 值；对象允许实际值包含额外字段
+func (m *matcher) match(path string, expected, actual interface{}, typeOnly bool) {
+	rule, hasRule := m.rule(path)
+	if hasRule {
+		switch rule.Match {
+		case "regex":
+			m.matchRegex(path, rule.Regex, actual)
+			return
+		case "type":
+			typeOnly = true
+		}
+	}
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			m.fail(path, "expected object, got %s", jsonType(actual))
+			return
+		}
+		keys := make([]string, 0, len(exp))
+		for key := range exp {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := act[key]
+			if !ok {
+				m.fail(path, "missing key %q", key)
+				continue
+			}
+			m.match(path+"."+key, exp[key], value, typeOnly)
+		}
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			m.fail(path, "expected array, got %s", jsonType(actual))
+			return
+		}
+		if typeOnly {
+			if hasRule && len(act) < rule.Min {
+				m.fail(path, "expected at least %d elements, got %d", rule.Min, len(act))
+			}
+			if len(exp) == 0 {
+				return
+			}
+			for i, value := range act {
+				m.match(fmt.Sprintf("%s[%d]", path, i), exp[0], value, true)
+			}
+			return
+		}
+		if len(exp) != len(act) {
+			m.fail(path, "expected %d elements, got %d", len(exp), len(act))
+			return
+		}
+		for i := range exp {
+			m.match(fmt.Sprintf("%s[%d]", path, i), exp[i], act[i], false)
+		}
+	default:
+		if typeOnly {
+			if jsonType(expected) != jsonType(actual) {
+				m.fail(path, "expected %s, got %s", jsonType(expected), jsonType(actual))
+			}
+			return
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			m.fail(path, "expected %v, got %v", formatValue(expected), formatValue(actual))
+		}
+	}
+}
+
+func (m *matcher) matchRegex(path, pattern string, actual interface{}) {
+	value, ok := actual.(string)
+	if !ok {
+		m.fail(path, "expected string matching %q, got %s", pattern, jsonType(actual))
+		return
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		m.fail(path, "invalid regex %q: %v", pattern, err)
+		return
+	}
+	if !re.MatchString(value) {
+		m.fail(path, "%q does not match %q", value, pattern)
+	}
+}
+
+// matchHeaders 期望的响应头必须全部存在，实际可多出其他头
+func (m *matcher) matchHeaders(expected map[string]string, actual http.Header) {
+	for key, want := range expected {
+		path := "$.headers." + key
+		got := actual.Get(key)
+		if rule, ok := m.rule(path); ok && rule.Match == "regex" {
+			m.matchRegex(path, rule.Regex, got)
+			continue
+		}
+		if got != want {
+			m.fail(path, "expected %q, got %q", want, got)
+		}
+	}
+}
+
+// matchBody 比较请求或响应体，期望为空时不校验
+func (m *matcher) matchBody(expected interface{}, body []byte) {
+	if expected == nil {
+		return
+	}
+	if want, ok := expected.(string); ok {
+		// 非 JSON 响应按原文比较，也兼容 JSON 字符串值
+		var decoded interface{}
+		if string(body) != want && (json.Unmarshal(body, &decoded) != nil || decoded != want) {
+			m.fail("$.body", "expected %q, got %q", want, string(body))
+		}
+		return
+	}
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		m.fail("$.body", "expected JSON body: %v", err)
+		return
+	}
+	m.match("$.body", normalizeJSON(expected), actual, false)
+}
+
+// queryMatches 比较查询参数，忽略参数顺序
+func queryMatches(expected string, actual url.Values) bool {
+	want, err := url.ParseQuery(expected)
+	if err != nil {
+		return false
+	}
+	if len(want) == 0 && len(actual) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(want, actual)
+}
+
+// normalizeJSON 将任意 Go 值转换为 encoding/json 解码后的通用形式
+func normalizeJSON(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, string, bool, float64:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(data))
+}
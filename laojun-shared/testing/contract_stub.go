@@ -0,0 +1,94 @@
+package testing
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ContractStateHeader 桩服务按此请求头选择提供者状态，同一请求有多个交互时使用
+const ContractStateHeader = "X-Contract-State"
+
+// NewContractStubServer 根据契约启动桩服务，测试结束时自动关闭
+func NewContractStubServer(t *testing.T, contracts ...*Contract) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(ContractStubHandler(t, contracts...))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// ContractStubHandler 按契约回放响应；没有匹配的交互时返回 500，t 不为 nil 时同时标记测试失败
+func ContractStubHandler(t *testing.T, contracts ...*Contract) http.Handler {
+	var interactions []Interaction
+	for _, contract := range contracts {
+		interactions = append(interactions, contract.Interactions...)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		state := r.Header.Get(ContractStateHeader)
+
+		var matched *Interaction
+		for i := range interactions {
+			interaction := &interactions[i]
+			if !stubRequestMatches(interaction.Request, r, body) {
+				continue
+			}
+			if matched == nil || state != "" && interaction.ProviderState == state && matched.ProviderState != state {
+				matched = interaction
+			}
+		}
+
+		if matched == nil {
+			if t != nil {
+				t.Errorf("contract stub: no interaction matches %s %s", r.Method, r.URL.RequestURI())
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":  "no interaction matches request",
+				"method": r.Method,
+				"path":   r.URL.RequestURI(),
+			})
+			return
+		}
+		writeStubResponse(w, matched.Response)
+	})
+}
+
+// stubRequestMatches 方法、路径、查询参数须一致，契约中的请求头和请求体须匹配
+func stubRequestMatches(expected ContractRequest, r *http.Request, body []byte) bool {
+	if !strings.EqualFold(expected.Method, r.Method) || expected.Path != r.URL.Path {
+		return false
+	}
+	if !queryMatches(expected.Query, r.URL.Query()) {
+		return false
+	}
+	m := newMatcher(nil)
+	m.matchHeaders(expected.Headers, r.Header)
+	m.matchBody(expected.Body, body)
+	return len(m.mismatches) == 0
+}
+
+func writeStubResponse(w http.ResponseWriter, response ContractResponse) {
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+
+	var body []byte
+	switch v := response.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(v)
+	default:
+		body, _ = json.Marshal(v)
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		}
+	}
+	w.WriteHeader(response.Status)
+	w.Write(body)
+}
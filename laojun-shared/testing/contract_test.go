@@ -0,0 +1,249 @@
+package testing
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// userRouter 模拟 admin-api 的用户接口，users 为当前数据
+func userRouter(users map[string]user) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/users/:id", func(c *gin.Context) {
+		u, ok := users[c.Param("id")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.JSON(http.StatusOK, u)
+	})
+	router.GET("/api/v1/users", func(c *gin.Context) {
+		list := make([]user, 0, len(users))
+		for _, u := range users {
+			list = append(list, u)
+		}
+		c.JSON(http.StatusOK, gin.H{"items": list, "page": c.Query("page")})
+	})
+	router.POST("/api/v1/users", func(c *gin.Context) {
+		var u user
+		if err := c.ShouldBindJSON(&u); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		u.ID = len(users) + 100
+		c.JSON(http.StatusCreated, u)
+	})
+	return router
+}
+
+func recordUserContract(t *testing.T) *Contract {
+	recorder := NewContractRecorder("laojun-gateway", "laojun-admin-api")
+	helper := NewHTTPTestHelper(userRouter(map[string]user{
+		"1": {ID: 1, Name: "alice", Email: "alice@example.com"},
+	}), t).WithContract(recorder)
+
+	resp := helper.Given("user 1 exists").
+		UponReceiving("get user by id").
+		WithMatchingRules(map[string]MatchingRule{
+			"$.body.name":  MatchType(),
+			"$.body.email": MatchRegex(`^[^@]+@[^@]+$`),
+		}).
+		GET("/api/v1/users/1", map[string]string{"Authorization": "Bearer consumer"})
+	helper.AssertStatus(resp, http.StatusOK)
+
+	helper.Given("no users").UponReceiving("get missing user").GET("/api/v1/users/2")
+	helper.Given("user 1 exists").UponReceiving("list users").
+		WithMatchingRules(map[string]MatchingRule{"$.body.items": EachLike(1)}).
+		GET("/api/v1/users?page=1")
+	helper.UponReceiving("create user").
+		WithMatchingRules(map[string]MatchingRule{"$.body.id": MatchType()}).
+		POST("/api/v1/users", map[string]string{"name": "bob", "email": "bob@example.com"})
+
+	// 未设置描述的请求不会被记录
+	helper.GET("/api/v1/users/1")
+	return recorder.Contract()
+}
+
+func TestContractRecordAndVerify(t *testing.T) {
+	contract := recordUserContract(t)
+	require.Len(t, contract.Interactions, 4)
+
+	get := contract.Interactions[0]
+	assert.Equal(t, "user 1 exists", get.ProviderState)
+	assert.Equal(t, "Bearer consumer", get.Request.Headers["Authorization"])
+	assert.Equal(t, "application/json; charset=utf-8", get.Response.Headers["Content-Type"])
+	assert.Equal(t, "page=1", contract.Interactions[2].Request.Query)
+	assert.Equal(t, "application/json", contract.Interactions[3].Request.Headers["Content-Type"])
+
+	dir := t.TempDir()
+	path, err := contract.WriteFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "laojun-gateway-laojun-admin-api.json"), path)
+
+	loaded, err := LoadContract(path)
+	require.NoError(t, err)
+	assert.Equal(t, ContractSpecVersion, loaded.Metadata["pactSpecification"].(map[string]interface{})["version"])
+
+	// 提供者数据与消费者记录时不同，但满足匹配规则
+	users := make(map[string]user)
+	states := make([]string, 0)
+	verifier := NewContractVerifier(userRouter(users)).
+		State("user 1 exists", func(t *testing.T) {
+			states = append(states, "user 1 exists")
+			users["1"] = user{ID: 1, Name: "carol", Email: "carol@laojun.dev"}
+			t.Cleanup(func() { delete(users, "1") })
+		}).
+		State("no users", func(t *testing.T) {
+			states = append(states, "no users")
+		}).
+		WithHeader("Authorization", "Bearer provider")
+	verifier.VerifyDir(t, dir, "laojun-admin-api")
+
+	assert.Equal(t, []string{"user 1 exists", "no users", "user 1 exists"}, states)
+	assert.Empty(t, users)
+}
+
+func TestContractMatcher(t *testing.T) {
+	expected := map[string]interface{}{
+		"id":    1,
+		"name":  "alice",
+		"tags":  []string{"a"},
+		"owner": map[string]interface{}{"id": 7, "email": "a@b.c"},
+	}
+	tests := []struct {
+		name   string
+		rules  map[string]MatchingRule
+		actual string
+		errors []string
+	}{
+		{
+			name:   "exact with extra fields",
+			actual: `{"id":1,"name":"alice","tags":["a"],"owner":{"id":7,"email":"a@b.c"},"extra":true}`,
+		},
+		{
+			name:   "value mismatch and missing key",
+			actual: `{"id":2,"name":"alice","tags":["a"]}`,
+			errors: []string{`$.body: missing key "owner"`, "$.body.id: expected 1, got 2"},
+		},
+		{
+			name:   "type rule cascades",
+			rules:  map[string]MatchingRule{"$.body.owner": MatchType()},
+			actual: `{"id":1,"name":"alice","tags":["a"],"owner":{"id":9,"email":"x"}}`,
+		},
+		{
+			name:   "type mismatch",
+			rules:  map[string]MatchingRule{"$.body.id": MatchType()},
+			actual: `{"id":"1","name":"alice","tags":["a"],"owner":{"id":7,"email":"a@b.c"}}`,
+			errors: []string{"$.body.id: expected number, got string"},
+		},
+		{
+			name: "each like with min",
+			rules: map[string]MatchingRule{
+				"$.body.tags":        EachLike(2),
+				"$.body.owner.email": MatchRegex(`^\S+@\S+$`),
+			},
+			actual: `{"id":1,"name":"alice","tags":["x",3],"owner":{"id":7,"email":"bad"}}`,
+			errors: []string{`$.body.owner.email: "bad" does not match "^\\S+@\\S+$"`, "$.body.tags[1]: expected string, got number"},
+		},
+		{
+			name:   "array length",
+			rules:  map[string]MatchingRule{"$.body.tags": EachLike(2)},
+			actual: `{"id":1,"name":"alice","tags":["x"],"owner":{"id":7,"email":"a@b.c"}}`,
+			errors: []string{"$.body.tags: expected at least 2 elements, got 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMatcher(tt.rules)
+			m.matchBody(expected, []byte(tt.actual))
+			assert.ElementsMatch(t, tt.errors, m.mismatches)
+		})
+	}
+}
+
+func TestContractValidate(t *testing.T) {
+	valid := Interaction{
+		Description: "ping",
+		Request:     ContractRequest{Method: "GET", Path: "/ping"},
+		Response:    ContractResponse{Status: 200},
+	}
+	tests := []struct {
+		name     string
+		contract Contract
+	}{
+		{"missing provider", Contract{Consumer: Pacticipant{Name: "a"}}},
+		{"relative path", Contract{Consumer: Pacticipant{Name: "a"}, Provider: Pacticipant{Name: "b"}, Interactions: []Interaction{{
+			Description: "x", Request: ContractRequest{Method: "GET", Path: "ping"}, Response: ContractResponse{Status: 200},
+		}}}},
+		{"duplicate", Contract{Consumer: Pacticipant{Name: "a"}, Provider: Pacticipant{Name: "b"}, Interactions: []Interaction{valid, valid}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.contract.Validate(), ErrInvalidContract)
+		})
+	}
+}
+
+func TestContractStubServer(t *testing.T) {
+	contract := recordUserContract(t)
+	contract.Interactions = append(contract.Interactions, Interaction{
+		Description:   "get user while disabled",
+		ProviderState: "user 1 disabled",
+		Request:       ContractRequest{Method: "GET", Path: "/api/v1/users/1"},
+		Response:      ContractResponse{Status: http.StatusForbidden, Body: map[string]string{"error": "disabled"}},
+	})
+	server := NewContractStubServer(t, contract)
+
+	get := func(path string, headers map[string]string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	auth := map[string]string{"Authorization": "Bearer consumer"}
+	status, body := get("/api/v1/users/1", auth)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"id":1,"name":"alice","email":"alice@example.com"}`, body)
+
+	status, _ = get("/api/v1/users/1", map[string]string{"Authorization": "Bearer consumer", ContractStateHeader: "user 1 disabled"})
+	assert.Equal(t, http.StatusForbidden, status)
+
+	status, body = get("/api/v1/users?page=1", nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"items"`)
+
+	resp, err := http.Post(server.URL+"/api/v1/users", "application/json", strings.NewReader(`{"email":"bob@example.com","name":"bob"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// 未匹配的请求返回 500
+	recorder := httptest.NewRecorder()
+	ContractStubHandler(nil, contract).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/users?page=2", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &payload))
+	assert.Equal(t, "/api/v1/users?page=2", payload["path"])
+}
@@ -0,0 +1,157 @@
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderStateHandler 为交互准备提供者状态，清理逻辑通过 t.Cleanup 注册
+type ProviderStateHandler func(t *testing.T)
+
+// ContractVerifier 在提供者测试中验证 gin 路由是否满足消费者契约
+type ContractVerifier struct {
+	Router  *gin.Engine
+	states  map[string]ProviderStateHandler
+	headers map[string]string
+}
+
+// NewContractVerifier 创建契约验证器
+func NewContractVerifier(router *gin.Engine) *ContractVerifier {
+	return &ContractVerifier{
+		Router:  router,
+		states:  make(map[string]ProviderStateHandler),
+		headers: make(map[string]string),
+	}
+}
+
+// State 注册提供者状态钩子，name 对应交互的 providerState
+func (v *ContractVerifier) State(name string, handler ProviderStateHandler) *ContractVerifier {
+	v.states[name] = handler
+	return v
+}
+
+// WithHeader 为每个请求设置请求头，覆盖契约中的同名请求头（如测试用的 Authorization）
+func (v *ContractVerifier) WithHeader(key, value string) *ContractVerifier {
+	v.headers[key] = value
+	return v
+}
+
+// Verify 逐个交互验证契约，每个交互是一个子测试
+func (v *ContractVerifier) Verify(t *testing.T, contract *Contract) {
+	t.Helper()
+	t.Run(contract.Consumer.Name, func(t *testing.T) {
+		for _, interaction := range contract.Interactions {
+			interaction := interaction
+			name := interaction.Description
+			if interaction.ProviderState != "" {
+				name = interaction.ProviderState + "/" + name
+			}
+			t.Run(name, func(t *testing.T) {
+				v.verifyInteraction(t, interaction)
+			})
+		}
+	})
+}
+
+// VerifyFile 加载并验证契约文件
+func (v *ContractVerifier) VerifyFile(t *testing.T, path string) {
+	t.Helper()
+	contract, err := LoadContract(path)
+	if err != nil {
+		t.Fatalf("load contract: %v", err)
+	}
+	v.Verify(t, contract)
+}
+
+// VerifyDir 验证目录下所有以 provider 为提供者的契约
+func (v *ContractVerifier) VerifyDir(t *testing.T, dir, provider string) {
+	t.Helper()
+	contracts, err := LoadContracts(dir, provider)
+	if err != nil {
+		t.Fatalf("load contracts: %v", err)
+	}
+	if len(contracts) == 0 {
+		t.Skipf("no contracts for provider %s in %s", provider, dir)
+	}
+	for _, contract := range contracts {
+		v.Verify(t, contract)
+	}
+}
+
+func (v *ContractVerifier) verifyInteraction(t *testing.T, interaction Interaction) {
+	if state := interaction.ProviderState; state != "" {
+		handler, ok := v.states[state]
+		if !ok {
+			t.Fatalf("no handler registered for provider state %q", state)
+		}
+		handler(t)
+	}
+
+	req, err := newContractRequest(interaction.Request)
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	for key, value := range v.headers {
+		req.Header.Set(key, value)
+	}
+
+	recorder := httptest.NewRecorder()
+	v.Router.ServeHTTP(recorder, req)
+
+	expected := interaction.Response
+	m := newMatcher(expected.MatchingRules)
+	if recorder.Code != expected.Status {
+		m.fail("$.status", "expected %d, got %d", expected.Status, recorder.Code)
+	}
+	m.matchHeaders(expected.Headers, recorder.Header())
+	m.matchBody(expected.Body, recorder.Body.Bytes())
+
+	if len(m.mismatches) > 0 {
+		t.Errorf("%s %s does not satisfy contract:\n  %s\nresponse body: %s",
+			req.Method, req.URL.RequestURI(), strings.Join(m.mismatches, "\n  "), recorder.Body.String())
+	}
+}
+
+// newContractRequest 根据契约请求构建 HTTP 请求
+func newContractRequest(contractReq ContractRequest) (*http.Request, error) {
+	body, contentType, err := encodeContractBody(contractReq.Body)
+	if err != nil {
+		return nil, err
+	}
+	target := contractReq.Path
+	if contractReq.Query != "" {
+		target += "?" + contractReq.Query
+	}
+	req := httptest.NewRequest(strings.ToUpper(contractReq.Method), target, body)
+	for key, value := range contractReq.Headers {
+		req.Header.Set(key, value)
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// encodeContractBody 字符串按原文发送，其他值编码为 JSON
+func encodeContractBody(body interface{}) (io.Reader, string, error) {
+	switch v := body.(type) {
+	case nil:
+		return nil, "", nil
+	case string:
+		return strings.NewReader(v), "", nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, "", fmt.Errorf("marshal body: %w", err)
+		}
+		return bytes.NewReader(data), "application/json", nil
+	}
+}
@@ -203,6 +203,12 @@ func (s *TestSuite) CleanupRedis() {
 type HTTPTestHelper struct {
 	Router *gin.Engine
 	T      *testing.T
+
+	// 契约记录：设置 description 后，下一次请求会作为交互写入 contract
+	contract    *ContractRecorder
+	state       string
+	description string
+	rules       map[string]MatchingRule
 }
 
 // NewHTTPTestHelper 创建 HTTP 测试辅助工具
@@ -287,9 +293,99 @@ func (h *HTTPTestHelper) DoRequest(req Request) *Response {
 		}
 	}
 
+	if h.contract != nil && h.description != "" {
+		h.recordInteraction(httpReq, req, response)
+	}
+
 	return response
 }
 
+// WithContract 返回记录契约的副本，配合 Given/UponReceiving 使用
+func (h *HTTPTestHelper) WithContract(recorder *ContractRecorder) *HTTPTestHelper {
+	clone := *h
+	clone.contract = recorder
+	return &clone
+}
+
+// Given 设置下一次交互的提供者状态
+func (h *HTTPTestHelper) Given(state string) *HTTPTestHelper {
+	clone := *h
+	clone.state = state
+	return &clone
+}
+
+// UponReceiving 设置下一次交互的描述，请求完成后记录到契约
+func (h *HTTPTestHelper) UponReceiving(description string) *HTTPTestHelper {
+	clone := *h
+	clone.description = description
+	return &clone
+}
+
+// WithMatchingRules 设置下一次交互响应的匹配规则
+func (h *HTTPTestHelper) WithMatchingRules(rules map[string]MatchingRule) *HTTPTestHelper {
+	clone := *h
+	clone.rules = rules
+	return &clone
+}
+
+// recordInteraction 将请求与实际响应记录为契约交互
+func (h *HTTPTestHelper) recordInteraction(httpReq *http.Request, req Request, response *Response) {
+	interaction := Interaction{
+		Description:   h.description,
+		ProviderState: h.state,
+		Request: ContractRequest{
+			Method: httpReq.Method,
+			Path:   httpReq.URL.Path,
+			Query:  httpReq.URL.RawQuery,
+			Body:   contractBody(req.Body),
+		},
+		Response: ContractResponse{
+			Status:        response.StatusCode,
+			Body:          contractBody(response.Body),
+			MatchingRules: h.rules,
+		},
+	}
+
+	// 只记录显式设置的请求头，避免把测试环境细节写进契约
+	if len(req.Headers) > 0 || req.Body != nil {
+		interaction.Request.Headers = make(map[string]string)
+		for key := range req.Headers {
+			interaction.Request.Headers[key] = httpReq.Header.Get(key)
+		}
+		if req.Body != nil {
+			interaction.Request.Headers["Content-Type"] = httpReq.Header.Get("Content-Type")
+		}
+	}
+	if contentType := response.Headers["Content-Type"]; contentType != "" {
+		interaction.Response.Headers = map[string]string{"Content-Type": contentType}
+	}
+
+	h.contract.AddInteraction(interaction)
+}
+
+// contractBody 将请求/响应体转换为契约中的 JSON 值，非 JSON 内容保留为字符串
+func contractBody(body interface{}) interface{} {
+	var raw []byte
+	switch v := body.(type) {
+	case nil:
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return normalizeJSON(v)
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return string(raw)
+	}
+	return decoded
+}
+
 // GET 执行 GET 请求
 func (h *HTTPTestHelper) GET(url string, headers ...map[string]string) *Response {
 	req := Request{Method: "GET", URL: url}
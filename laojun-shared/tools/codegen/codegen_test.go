@@ -0,0 +1,185 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNaming(t *testing.T) {
+	tests := []struct {
+		in                     string
+		snake, lower, exported string
+	}{
+		{in: "OrderItem", snake: "order_item", lower: "orderItem", exported: "OrderItem"},
+		{in: "APIKey", snake: "api_key", lower: "apiKey", exported: "APIKey"},
+		{in: "ID", snake: "id", lower: "id", exported: "ID"},
+		{in: "User", snake: "user", lower: "user", exported: "User"},
+	}
+	for _, tt := range tests {
+		if got := snakeCase(tt.in); got != tt.snake {
+			t.Errorf("snakeCase(%q) = %q, want %q", tt.in, got, tt.snake)
+		}
+		if got := lowerFirst(tt.in); got != tt.lower {
+			t.Errorf("lowerFirst(%q) = %q, want %q", tt.in, got, tt.lower)
+		}
+		if got := exportedName(tt.in); got != tt.exported {
+			t.Errorf("exportedName(%q) = %q, want %q", tt.in, got, tt.exported)
+		}
+	}
+
+	if got := exportedName("order-items"); got != "OrderItems" {
+		t.Errorf("exportedName(order-items) = %q", got)
+	}
+	for word, want := range map[string]string{"category": "categories", "key": "keys", "box": "boxes", "user": "users"} {
+		if got := pluralize(word); got != want {
+			t.Errorf("pluralize(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+const testModel = `package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+type Product struct {
+	ID        uuid.UUID ` + "`" + `gorm:"type:uuid;primaryKey" json:"id"` + "`" + `
+	Name      string    ` + "`" + `json:"name" validate:"required,min=2"` + "`" + `
+	Price     float64   ` + "`" + `json:"price" binding:"required,gt=0"` + "`" + `
+	Status    Status    ` + "`" + `json:"status" binding:"oneof=draft published"` + "`" + `
+	ExpiresAt time.Time ` + "`" + `json:"expires_at"` + "`" + `
+	Internal  string    ` + "`" + `json:"-"` + "`" + `
+	Category  *Category
+	CreatedAt time.Time
+}
+
+type Category struct {
+	ID uint
+}
+`
+
+func setupService(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	data := newServiceTemplate("shop", "", 8088)
+	files := map[string]string{
+		"go.mod":                      serviceGoModTemplate,
+		"cmd/main.go":                 serviceMainTemplate,
+		"internal/config/config.go":   serviceConfigTemplate,
+		"internal/handlers/router.go": serviceRouterTemplate,
+	}
+	if err := generateFiles(dir, files, data, false); err != nil {
+		t.Fatal(err)
+	}
+	modelFile := filepath.Join(dir, "internal", "models", "product.go")
+	if err := os.MkdirAll(filepath.Dir(modelFile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(modelFile, []byte(testModel), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLoadCRUDModel(t *testing.T) {
+	dir := setupService(t)
+
+	data, err := loadCRUDModel(filepath.Join(dir, "internal", "models", "product.go"), "Product", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Module != "github.com/codetaoist/laojun-shop" || data.ModelImport != data.Module+"/internal/models" {
+		t.Errorf("module = %s, model import = %s", data.Module, data.ModelImport)
+	}
+	if data.IDField != "ID" || data.IDKind != "uuid" || data.Route != "/products" {
+		t.Errorf("id = %s %s, route = %s", data.IDField, data.IDKind, data.Route)
+	}
+
+	var names []string
+	for _, field := range data.Fields {
+		names = append(names, field.Name)
+	}
+	// 跳过主键、json:"-"、关联模型和时间戳字段
+	if got := strings.Join(names, ","); got != "Name,Price,Status,ExpiresAt" {
+		t.Fatalf("fields = %s", got)
+	}
+	name, status := data.Fields[0], data.Fields[2]
+	if name.CreateTag != "`json:\"name\" binding:\"required,min=2\"`" || name.UpdateTag != "`json:\"name\" binding:\"omitempty,min=2\"`" {
+		t.Errorf("name tags = %s / %s", name.CreateTag, name.UpdateTag)
+	}
+	if status.Type != "models.Status" || status.SampleJSON != `"draft"` {
+		t.Errorf("status = %+v", status)
+	}
+	if len(data.HandlerTypes) != 1 || data.HandlerTypes[0] != "time" {
+		t.Errorf("handler imports = %v", data.HandlerTypes)
+	}
+
+	if _, err := loadCRUDModel(filepath.Join(dir, "internal", "models", "product.go"), "Missing", dir); err == nil {
+		t.Error("expected error for missing type")
+	}
+}
+
+func TestGenerateCRUD(t *testing.T) {
+	dir := setupService(t)
+	data, err := loadCRUDModel(filepath.Join(dir, "internal", "models", "product.go"), "Product", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 所有模板都能渲染并通过 gofmt
+	internal := filepath.Join(dir, "internal")
+	files := map[string]string{
+		"repository/errors.go":             crudRepositoryErrorsTemplate,
+		"repository/product_repository.go": crudRepositoryTemplate,
+		"services/pagination.go":           crudPaginationTemplate,
+		"services/product_service.go":      crudServiceTemplate,
+		"handlers/errors.go":               crudHandlerErrorsTemplate,
+		"handlers/product_handler.go":      crudHandlerTemplate,
+		"handlers/product_handler_test.go": crudHandlerTestTemplate,
+	}
+	if err := generateFiles(internal, files, data, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := generateFiles(internal, files, data, false); err == nil {
+		t.Error("expected error when files exist without -force")
+	}
+
+	router := filepath.Join(internal, "handlers", "router.go")
+	for i := 0; i < 2; i++ {
+		ok, err := registerRoutes(router, "registerProductRoutes(api, deps.DB)")
+		if err != nil || !ok {
+			t.Fatalf("registerRoutes() = %v, %v", ok, err)
+		}
+	}
+	src, err := os.ReadFile(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(src), "registerProductRoutes"); n != 1 {
+		t.Errorf("route registered %d times", n)
+	}
+	if !strings.Contains(string(src), "\tregisterProductRoutes(api, deps.DB)\n\t"+routesMarker) {
+		t.Errorf("router.go:\n%s", src)
+	}
+
+	handler, err := os.ReadFile(filepath.Join(internal, "handlers", "product_handler.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"ExpiresAt *time.Time",
+		"id, err := uuid.Parse(c.Param(\"id\"))",
+		"group := r.Group(\"/products\")",
+	} {
+		if !strings.Contains(string(handler), want) {
+			t.Errorf("handler missing %q", want)
+		}
+	}
+}
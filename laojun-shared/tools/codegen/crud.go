@@ -0,0 +1,1110 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CRUDTemplate CRUD 模板数据
+type CRUDTemplate struct {
+	Module       string // 服务模块路径
+	ModelImport  string // 模型包导入路径
+	ModelPkg     string // 模型包名
+	Name         string // 模型名，如 OrderItem
+	Var          string // 变量名，如 orderItem
+	Snake        string // 文件名，如 order_item
+	Route        string // 路由，如 /order-items
+	Label        string // 日志和错误信息中的名称，如 order item
+	IDField      string
+	IDType       string // 含包名的主键类型
+	IDKind       string // uint、int、string 或 uuid
+	IDColumn     string
+	Fields       []CRUDField
+	HandlerTypes []string // 请求结构体中用到的包，如 time
+}
+
+// CRUDField 请求中的模型字段
+type CRUDField struct {
+	Name       string
+	Type       string // 含包名的字段类型
+	Pointer    bool   // 模型字段本身是指针
+	JSON       string
+	CreateTag  string
+	UpdateTag  string
+	SampleJSON string // 测试请求中的示例值（Go 表达式）
+}
+
+// 仓储错误模板，同一服务只生成一次
+var crudRepositoryErrorsTemplate = `package repository
+
+import "errors"
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+`
+
+// 仓储模板
+var crudRepositoryTemplate = `package repository
+
+import (
+	"context"
+	"errors"
+
+{{- if eq .IDKind "uuid"}}
+
+	"github.com/google/uuid"
+{{- end}}
+	"gorm.io/gorm"
+
+	"{{.ModelImport}}"
+)
+
+// {{.Name}}Repository {{.Label}} 数据访问接口
+type {{.Name}}Repository interface {
+	Create(ctx context.Context, {{.Var}} *{{.ModelPkg}}.{{.Name}}) error
+	Get(ctx context.Context, id {{.IDType}}) (*{{.ModelPkg}}.{{.Name}}, error)
+	List(ctx context.Context, offset, limit int) ([]{{.ModelPkg}}.{{.Name}}, int64, error)
+	Update(ctx context.Context, {{.Var}} *{{.ModelPkg}}.{{.Name}}) error
+	Delete(ctx context.Context, id {{.IDType}}) error
+}
+
+type gorm{{.Name}}Repository struct {
+	db *gorm.DB
+}
+
+// New{{.Name}}Repository 创建基于 gorm 的仓储
+func New{{.Name}}Repository(db *gorm.DB) {{.Name}}Repository {
+	return &gorm{{.Name}}Repository{db: db}
+}
+
+func (r *gorm{{.Name}}Repository) Create(ctx context.Context, {{.Var}} *{{.ModelPkg}}.{{.Name}}) error {
+	return r.db.WithContext(ctx).Create({{.Var}}).Error
+}
+
+func (r *gorm{{.Name}}Repository) Get(ctx context.Context, id {{.IDType}}) (*{{.ModelPkg}}.{{.Name}}, error) {
+	var {{.Var}} {{.ModelPkg}}.{{.Name}}
+	err := r.db.WithContext(ctx).First(&{{.Var}}, "{{.IDColumn}} = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &{{.Var}}, nil
+}
+
+func (r *gorm{{.Name}}Repository) List(ctx context.Context, offset, limit int) ([]{{.ModelPkg}}.{{.Name}}, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&{{.ModelPkg}}.{{.Name}}{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []{{.ModelPkg}}.{{.Name}}
+	err := r.db.WithContext(ctx).Order("{{.IDColumn}}").Offset(offset).Limit(limit).Find(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *gorm{{.Name}}Repository) Update(ctx context.Context, {{.Var}} *{{.ModelPkg}}.{{.Name}}) error {
+	return r.db.WithContext(ctx).Save({{.Var}}).Error
+}
+
+func (r *gorm{{.Name}}Repository) Delete(ctx context.Context, id {{.IDType}}) error {
+	result := r.db.WithContext(ctx).Delete(&{{.ModelPkg}}.{{.Name}}{}, "{{.IDColumn}} = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+`
+
+// 分页模板，同一服务只生成一次
+var crudPaginationTemplate = `package services
+
+// 分页默认值
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// normalizePage 修正分页参数，返回数据库查询的 offset 和 limit
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	return (page - 1) * pageSize, pageSize
+}
+`
+
+// 业务服务模板
+var crudServiceTemplate = `package services
+
+import (
+	"context"
+
+{{- if eq .IDKind "uuid"}}
+
+	"github.com/google/uuid"
+{{- end}}
+
+	"{{.ModelImport}}"
+	"{{.Module}}/internal/repository"
+)
+
+// {{.Name}}Service {{.Label}} 业务服务
+type {{.Name}}Service struct {
+	repo repository.{{.Name}}Repository
+}
+
+// New{{.Name}}Service 创建业务服务
+func New{{.Name}}Service(repo repository.{{.Name}}Repository) *{{.Name}}Service {
+	return &{{.Name}}Service{repo: repo}
+}
+
+// Create 创建记录
+func (s *{{.Name}}Service) Create(ctx context.Context, {{.Var}} *{{.ModelPkg}}.{{.Name}}) error {
+	return s.repo.Create(ctx, {{.Var}})
+}
+
+// Get 按主键查询，不存在时返回 repository.ErrNotFound
+func (s *{{.Name}}Service) Get(ctx context.Context, id {{.IDType}}) (*{{.ModelPkg}}.{{.Name}}, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List 分页查询，page 从 1 开始
+func (s *{{.Name}}Service) List(ctx context.Context, page, pageSize int) ([]{{.ModelPkg}}.{{.Name}}, int64, error) {
+	offset, limit := normalizePage(page, pageSize)
+	return s.repo.List(ctx, offset, limit)
+}
+
+// Update 查询记录并通过 apply 修改后保存
+func (s *{{.Name}}Service) Update(ctx context.Context, id {{.IDType}}, apply func(*{{.ModelPkg}}.{{.Name}})) (*{{.ModelPkg}}.{{.Name}}, error) {
+	{{.Var}}, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	apply({{.Var}})
+	if err := s.repo.Update(ctx, {{.Var}}); err != nil {
+		return nil, err
+	}
+	return {{.Var}}, nil
+}
+
+// Delete 删除记录
+func (s *{{.Name}}Service) Delete(ctx context.Context, id {{.IDType}}) error {
+	return s.repo.Delete(ctx, id)
+}
+`
+
+// 处理器错误映射模板，同一服务只生成一次
+var crudHandlerErrorsTemplate = `package handlers
+
+import (
+	"errors"
+
+	"github.com/codetaoist/laojun-shared/utils"
+	"github.com/gin-gonic/gin"
+
+	"{{.Module}}/internal/repository"
+)
+
+// respondError 将业务错误映射为 HTTP 响应
+func respondError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		utils.NotFoundResponse(c, err.Error())
+		return
+	}
+	_ = c.Error(err)
+	utils.InternalServerErrorResponse(c, "internal error")
+}
+`
+
+// 处理器模板
+var crudHandlerTemplate = `package handlers
+
+import (
+	"net/http"
+	"strconv"
+{{- range .HandlerTypes}}
+	"{{.}}"
+{{- end}}
+
+	sharedmodels "github.com/codetaoist/laojun-shared/models"
+	"github.com/codetaoist/laojun-shared/utils"
+	"github.com/gin-gonic/gin"
+{{- if eq .IDKind "uuid"}}
+	"github.com/google/uuid"
+{{- end}}
+	"gorm.io/gorm"
+
+	"{{.ModelImport}}"
+	"{{.Module}}/internal/repository"
+	"{{.Module}}/internal/services"
+)
+
+// Create{{.Name}}Request 创建 {{.Label}} 请求
+type Create{{.Name}}Request struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} {{.CreateTag}}
+{{- end}}
+}
+
+func (r *Create{{.Name}}Request) toModel() *{{.ModelPkg}}.{{.Name}} {
+	return &{{.ModelPkg}}.{{.Name}}{
+{{- range .Fields}}
+		{{.Name}}: r.{{.Name}},
+{{- end}}
+	}
+}
+
+// Update{{.Name}}Request 更新 {{.Label}} 请求，只修改请求中出现的字段
+type Update{{.Name}}Request struct {
+{{- range .Fields}}
+	{{.Name}} {{if not .Pointer}}*{{end}}{{.Type}} {{.UpdateTag}}
+{{- end}}
+}
+
+func (r *Update{{.Name}}Request) apply({{.Var}} *{{.ModelPkg}}.{{.Name}}) {
+{{- range .Fields}}
+	if r.{{.Name}} != nil {
+		{{$.Var}}.{{.Name}} = {{if not .Pointer}}*{{end}}r.{{.Name}}
+	}
+{{- end}}
+}
+
+// {{.Name}}Handler {{.Label}} 处理器
+type {{.Name}}Handler struct {
+	service *services.{{.Name}}Service
+}
+
+// New{{.Name}}Handler 创建处理器
+func New{{.Name}}Handler(service *services.{{.Name}}Service) *{{.Name}}Handler {
+	return &{{.Name}}Handler{service: service}
+}
+
+// register{{.Name}}Routes 组装仓储、服务和处理器并注册路由
+func register{{.Name}}Routes(r *gin.RouterGroup, db *gorm.DB) {
+	New{{.Name}}Handler(services.New{{.Name}}Service(repository.New{{.Name}}Repository(db))).Register(r)
+}
+
+// Register 注册路由
+func (h *{{.Name}}Handler) Register(r *gin.RouterGroup) {
+	group := r.Group("{{.Route}}")
+	group.POST("", h.Create)
+	group.GET("", h.List)
+	group.GET("/:id", h.Get)
+	group.PUT("/:id", h.Update)
+	group.DELETE("/:id", h.Delete)
+}
+
+// Create 创建 {{.Label}}
+func (h *{{.Name}}Handler) Create(c *gin.Context) {
+	var req Create{{.Name}}Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	{{.Var}} := req.toModel()
+	if err := h.service.Create(c.Request.Context(), {{.Var}}); err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.CreatedResponse(c, {{.Var}})
+}
+
+// Get 查询 {{.Label}}
+func (h *{{.Name}}Handler) Get(c *gin.Context) {
+	id, ok := parse{{.Name}}ID(c)
+	if !ok {
+		return
+	}
+
+	{{.Var}}, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.SuccessResponse(c, {{.Var}})
+}
+
+// List 分页查询 {{.Label}}
+func (h *{{.Name}}Handler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(services.DefaultPageSize)))
+
+	items, total, err := h.service.List(c.Request.Context(), page, pageSize)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > services.MaxPageSize {
+		pageSize = services.DefaultPageSize
+	}
+	utils.PaginatedResponse(c, items, sharedmodels.PaginationMeta{
+		Page:       page,
+		Limit:      pageSize,
+		Total:      int(total),
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	})
+}
+
+// Update 更新 {{.Label}}
+func (h *{{.Name}}Handler) Update(c *gin.Context) {
+	id, ok := parse{{.Name}}ID(c)
+	if !ok {
+		return
+	}
+
+	var req Update{{.Name}}Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, err.Error())
+		return
+	}
+
+	{{.Var}}, err := h.service.Update(c.Request.Context(), id, req.apply)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	utils.SuccessResponse(c, {{.Var}})
+}
+
+// Delete 删除 {{.Label}}
+func (h *{{.Name}}Handler) Delete(c *gin.Context) {
+	id, ok := parse{{.Name}}ID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// parse{{.Name}}ID 解析路径中的主键，失败时返回 400
+func parse{{.Name}}ID(c *gin.Context) ({{.IDType}}, bool) {
+{{- if eq .IDKind "uuid"}}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "invalid id")
+		return uuid.Nil, false
+	}
+	return id, true
+{{- else if eq .IDKind "string"}}
+	id := c.Param("id")
+	if id == "" {
+		utils.BadRequestResponse(c, "invalid id")
+		return "", false
+	}
+	return {{.IDType}}(id), true
+{{- else if eq .IDKind "uint"}}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequestResponse(c, "invalid id")
+		return 0, false
+	}
+	return {{.IDType}}(id), true
+{{- else}}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.BadRequestResponse(c, "invalid id")
+		return 0, false
+	}
+	return {{.IDType}}(id), true
+{{- end}}
+}
+`
+
+// 处理器测试模板
+var crudHandlerTestTemplate = `package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+{{- if eq .IDKind "uuid"}}
+	"github.com/google/uuid"
+{{- end}}
+
+	"{{.ModelImport}}"
+	"{{.Module}}/internal/repository"
+	"{{.Module}}/internal/services"
+)
+
+// fake{{.Name}}Repository 内存仓储
+type fake{{.Name}}Repository struct {
+	mu     sync.Mutex
+	nextID int
+	items  map[{{.IDType}}]{{.ModelPkg}}.{{.Name}}
+	order  []{{.IDType}}
+}
+
+func newFake{{.Name}}Repository() *fake{{.Name}}Repository {
+	return &fake{{.Name}}Repository{items: make(map[{{.IDType}}]{{.ModelPkg}}.{{.Name}})}
+}
+
+func (r *fake{{.Name}}Repository) Create(ctx context.Context, {{.Var}} *{{.ModelPkg}}.{{.Name}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+{{- if eq .IDKind "uuid"}}
+	{{.Var}}.{{.IDField}} = uuid.New()
+{{- else if eq .IDKind "string"}}
+	{{.Var}}.{{.IDField}} = {{.IDType}}(fmt.Sprint(r.nextID))
+{{- else}}
+	{{.Var}}.{{.IDField}} = {{.IDType}}(r.nextID)
+{{- end}}
+	r.items[{{.Var}}.{{.IDField}}] = *{{.Var}}
+	r.order = append(r.order, {{.Var}}.{{.IDField}})
+	return nil
+}
+
+func (r *fake{{.Name}}Repository) Get(ctx context.Context, id {{.IDType}}) (*{{.ModelPkg}}.{{.Name}}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	{{.Var}}, ok := r.items[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &{{.Var}}, nil
+}
+
+func (r *fake{{.Name}}Repository) List(ctx context.Context, offset, limit int) ([]{{.ModelPkg}}.{{.Name}}, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []{{.ModelPkg}}.{{.Name}}
+	for i := offset; i < len(r.order) && len(items) < limit; i++ {
+		items = append(items, r.items[r.order[i]])
+	}
+	return items, int64(len(r.order)), nil
+}
+
+func (r *fake{{.Name}}Repository) Update(ctx context.Context, {{.Var}} *{{.ModelPkg}}.{{.Name}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[{{.Var}}.{{.IDField}}]; !ok {
+		return repository.ErrNotFound
+	}
+	r.items[{{.Var}}.{{.IDField}}] = *{{.Var}}
+	return nil
+}
+
+func (r *fake{{.Name}}Repository) Delete(ctx context.Context, id {{.IDType}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.items, id)
+	for i, existing := range r.order {
+		if existing == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func newTest{{.Name}}Router(t *testing.T) (*gin.Engine, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo := newFake{{.Name}}Repository()
+	seed := &{{.ModelPkg}}.{{.Name}}{}
+	if err := repo.Create(context.Background(), seed); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	New{{.Name}}Handler(services.New{{.Name}}Service(repo)).Register(router.Group("/api/v1"))
+	return router, fmt.Sprint(seed.{{.IDField}})
+}
+
+func Test{{.Name}}Handler(t *testing.T) {
+	router, id := newTest{{.Name}}Router(t)
+
+	body, err := json.Marshal(map[string]interface{}{
+{{- range .Fields}}
+		"{{.JSON}}": {{.SampleJSON}},
+{{- end}}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := string(body)
+{{- if eq .IDKind "uuid"}}
+	missing := uuid.New().String()
+{{- else if eq .IDKind "string"}}
+	missing := "missing"
+{{- else}}
+	missing := "999999"
+{{- end}}
+	base := "/api/v1{{.Route}}"
+
+	// 按顺序执行，后面的用例依赖前面的删除
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{name: "create", method: http.MethodPost, path: base, body: valid, want: http.StatusCreated},
+		{name: "create invalid json", method: http.MethodPost, path: base, body: "{", want: http.StatusBadRequest},
+		{name: "list", method: http.MethodGet, path: base + "?page=1&page_size=10", want: http.StatusOK},
+		{name: "get", method: http.MethodGet, path: base + "/" + id, want: http.StatusOK},
+		{name: "get missing", method: http.MethodGet, path: base + "/" + missing, want: http.StatusNotFound},
+{{- if ne .IDKind "string"}}
+		{name: "get invalid id", method: http.MethodGet, path: base + "/invalid", want: http.StatusBadRequest},
+{{- end}}
+		{name: "update", method: http.MethodPut, path: base + "/" + id, body: valid, want: http.StatusOK},
+		{name: "update missing", method: http.MethodPut, path: base + "/" + missing, body: valid, want: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, path: base + "/" + id, want: http.StatusNoContent},
+		{name: "delete again", method: http.MethodDelete, path: base + "/" + id, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.path, rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+`
+
+// routesMarker 服务骨架路由中注册生成路由的位置
+const routesMarker = "// codegen:routes"
+
+// runCRUD 根据模型生成仓储、服务、处理器和测试
+func runCRUD(args []string) {
+	flags := flag.NewFlagSet("crud", flag.ExitOnError)
+	var (
+		modelFile = flags.String("model", "", "Go file containing the model struct (required)")
+		typeName  = flags.String("type", "", "Model struct name (required)")
+		outputDir = flags.String("output", ".", "Service root directory containing go.mod")
+		force     = flags.Bool("force", false, "Overwrite existing files")
+	)
+	flags.Parse(args)
+
+	if *modelFile == "" || *typeName == "" {
+		log.Fatal("Model file and type are required")
+	}
+
+	data, err := loadCRUDModel(*modelFile, *typeName, *outputDir)
+	if err != nil {
+		log.Fatalf("解析模型失败: %v", err)
+	}
+
+	internal := filepath.Join(*outputDir, "internal")
+	files := map[string]string{
+		filepath.Join("repository", data.Snake+"_repository.go"): crudRepositoryTemplate,
+		filepath.Join("services", data.Snake+"_service.go"):      crudServiceTemplate,
+		filepath.Join("handlers", data.Snake+"_handler.go"):      crudHandlerTemplate,
+		filepath.Join("handlers", data.Snake+"_handler_test.go"): crudHandlerTestTemplate,
+	}
+	// 共享文件已存在时保留
+	shared := map[string]string{
+		filepath.Join("repository", "errors.go"):   crudRepositoryErrorsTemplate,
+		filepath.Join("services", "pagination.go"): crudPaginationTemplate,
+		filepath.Join("handlers", "errors.go"):     crudHandlerErrorsTemplate,
+	}
+	for name, tmpl := range shared {
+		if _, err := os.Stat(filepath.Join(internal, name)); errors.Is(err, os.ErrNotExist) {
+			files[name] = tmpl
+		}
+	}
+
+	if err := generateFiles(internal, files, data, *force); err != nil {
+		log.Fatal(err)
+	}
+
+	router := filepath.Join(internal, "handlers", "router.go")
+	registered, err := registerRoutes(router, fmt.Sprintf("register%sRoutes(api, deps.DB)", data.Name))
+	if err != nil {
+		log.Fatalf("注册路由失败: %v", err)
+	}
+
+	fmt.Printf("\n✅ 成功生成 %s 的 CRUD 代码\n", data.Name)
+	if registered {
+		fmt.Printf("已在 %s 注册路由 %s\n", router, data.Route)
+	} else {
+		fmt.Printf("请在路由中调用 register%sRoutes(api, db) 注册 %s\n", data.Name, data.Route)
+	}
+	fmt.Println("运行 go mod tidy && go test ./... 验证生成的代码")
+}
+
+// registerRoutes 在路由文件的 codegen:routes 标记前插入注册语句，没有标记时返回 false
+func registerRoutes(filename, statement string) (bool, error) {
+	src, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	content := string(src)
+	if strings.Contains(content, statement) {
+		return true, nil
+	}
+	index := strings.Index(content, routesMarker)
+	if index < 0 {
+		return false, nil
+	}
+
+	// 保持与标记相同的缩进
+	lineStart := strings.LastIndex(content[:index], "\n") + 1
+	indent := content[lineStart:index]
+	content = content[:lineStart] + indent + statement + "\n" + content[lineStart:]
+	return true, os.WriteFile(filename, []byte(content), 0644)
+}
+
+// loadCRUDModel 解析模型所在的包，收集主键和请求字段
+func loadCRUDModel(modelFile, typeName, serviceDir string) (*CRUDTemplate, error) {
+	module, err := readModulePath(filepath.Join(serviceDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	modelDir := filepath.Dir(modelFile)
+	modelImport, err := importPathFor(modelDir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, modelDir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		pkgName  string
+		model    *ast.StructType
+		imports  = map[string]string{}
+		localTyp = map[string]bool{} // 包内的非结构体类型，如 type Status string
+	)
+	for name, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, spec := range file.Imports {
+				path, _ := strconv.Unquote(spec.Path.Value)
+				alias := filepath.Base(path)
+				if spec.Name != nil {
+					alias = spec.Name.Name
+				}
+				imports[alias] = path
+			}
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					structType, isStruct := typeSpec.Type.(*ast.StructType)
+					if typeSpec.Name.Name == typeName && isStruct {
+						model = structType
+						pkgName = name
+					}
+					if !isStruct {
+						localTyp[typeSpec.Name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	if model == nil {
+		return nil, fmt.Errorf("在 %s 中找不到结构体 %s", modelDir, typeName)
+	}
+
+	snake := snakeCase(typeName)
+	data := &CRUDTemplate{
+		Module:      module,
+		ModelImport: modelImport,
+		ModelPkg:    pkgName,
+		Name:        typeName,
+		Var:         varName(typeName),
+		Snake:       snake,
+		Route:       "/" + strings.ReplaceAll(pluralize(snake), "_", "-"),
+		Label:       strings.ReplaceAll(snake, "_", " "),
+	}
+
+	usedPkgs := map[string]bool{}
+	for _, field := range model.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			value, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(value)
+		}
+
+		// 嵌入的 gorm.Model 提供 uint 主键
+		if len(field.Names) == 0 {
+			if sel, ok := field.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "Model" && imports[identName(sel.X)] == "gorm.io/gorm" {
+				data.IDField, data.IDType, data.IDKind, data.IDColumn = "ID", "uint", "uint", "id"
+			}
+			continue
+		}
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			gormTag := strings.ToLower(tag.Get("gorm"))
+			if name.Name == "ID" || strings.Contains(gormTag, "primarykey") || strings.Contains(gormTag, "primary_key") {
+				if err := data.setID(name.Name, field.Type, gormTag, pkgName, localTyp, imports); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if gormTag == "-" || tag.Get("json") == "-" || isTimestampField(name.Name) {
+				continue
+			}
+
+			fieldType, pkgs, ok := qualifiedType(field.Type, pkgName, localTyp)
+			if !ok {
+				// 关联模型等无法直接从请求绑定的字段
+				continue
+			}
+			for _, pkg := range pkgs {
+				usedPkgs[pkg] = true
+			}
+			data.Fields = append(data.Fields, newCRUDField(name.Name, fieldType, field.Type, tag))
+		}
+	}
+	if data.IDField == "" {
+		return nil, fmt.Errorf("结构体 %s 没有主键（ID 字段、gorm primaryKey 或嵌入 gorm.Model）", typeName)
+	}
+
+	for pkg := range usedPkgs {
+		path, ok := imports[pkg]
+		if !ok {
+			return nil, fmt.Errorf("找不到包 %s 的导入路径", pkg)
+		}
+		if path == "github.com/google/uuid" && data.IDKind == "uuid" {
+			// 主键为 uuid 时处理器模板已经导入
+			continue
+		}
+		data.HandlerTypes = append(data.HandlerTypes, path)
+	}
+	return data, nil
+}
+
+// setID 记录主键字段
+func (t *CRUDTemplate) setID(name string, expr ast.Expr, gormTag, pkgName string, localTypes map[string]bool, imports map[string]string) error {
+	t.IDField = name
+	t.IDColumn = snakeCase(name)
+	for _, part := range strings.Split(gormTag, ";") {
+		if strings.HasPrefix(part, "column:") {
+			t.IDColumn = strings.TrimPrefix(part, "column:")
+		}
+	}
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		switch {
+		case strings.HasPrefix(e.Name, "uint"):
+			t.IDType, t.IDKind = e.Name, "uint"
+		case strings.HasPrefix(e.Name, "int"):
+			t.IDType, t.IDKind = e.Name, "int"
+		case e.Name == "string":
+			t.IDType, t.IDKind = e.Name, "string"
+		case localTypes[e.Name]:
+			// 如 type OrderID string，按字符串处理
+			t.IDType, t.IDKind = pkgName+"."+e.Name, "string"
+		}
+	case *ast.SelectorExpr:
+		if e.Sel.Name == "UUID" && imports[identName(e.X)] == "github.com/google/uuid" {
+			t.IDType, t.IDKind = "uuid.UUID", "uuid"
+		}
+	}
+	if t.IDType == "" {
+		return fmt.Errorf("不支持的主键类型: %s.%s", t.Name, name)
+	}
+	return nil
+}
+
+func newCRUDField(name, fieldType string, expr ast.Expr, tag reflect.StructTag) CRUDField {
+	jsonName := strings.Split(tag.Get("json"), ",")[0]
+	if jsonName == "" {
+		jsonName = snakeCase(name)
+	}
+	binding := tag.Get("binding")
+	if binding == "" {
+		binding = tag.Get("validate")
+	}
+
+	_, pointer := expr.(*ast.StarExpr)
+	field := CRUDField{
+		Name:       name,
+		Type:       fieldType,
+		Pointer:    pointer,
+		JSON:       jsonName,
+		CreateTag:  structTag(jsonName, binding),
+		UpdateTag:  structTag(jsonName, updateBinding(binding)),
+		SampleJSON: sampleValue(expr, binding),
+	}
+	return field
+}
+
+func structTag(jsonName, binding string) string {
+	tag := fmt.Sprintf(`json:"%s"`, jsonName)
+	if binding != "" {
+		tag += fmt.Sprintf(` binding:"%s"`, binding)
+	}
+	return "`" + tag + "`"
+}
+
+// updateBinding 更新请求的字段可选：去掉 required，其余规则只在字段出现时校验
+func updateBinding(binding string) string {
+	var rules []string
+	for _, rule := range strings.Split(binding, ",") {
+		if rule == "" || rule == "required" || rule == "omitempty" {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	return "omitempty," + strings.Join(rules, ",")
+}
+
+// qualifiedType 返回在其他包中引用字段类型的写法和用到的包；结构体类型（关联模型）返回 false
+func qualifiedType(expr ast.Expr, pkgName string, localTypes map[string]bool) (string, []string, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if isBuiltinType(e.Name) {
+			return e.Name, nil, true
+		}
+		if localTypes[e.Name] {
+			return pkgName + "." + e.Name, nil, true
+		}
+		return "", nil, false
+	case *ast.SelectorExpr:
+		pkg := identName(e.X)
+		return pkg + "." + e.Sel.Name, []string{pkg}, true
+	case *ast.StarExpr:
+		inner, pkgs, ok := qualifiedType(e.X, pkgName, localTypes)
+		return "*" + inner, pkgs, ok
+	case *ast.ArrayType:
+		if e.Len != nil {
+			return "", nil, false
+		}
+		inner, pkgs, ok := qualifiedType(e.Elt, pkgName, localTypes)
+		return "[]" + inner, pkgs, ok
+	case *ast.MapType:
+		key, keyPkgs, ok := qualifiedType(e.Key, pkgName, localTypes)
+		if !ok {
+			return "", nil, false
+		}
+		value, valuePkgs, ok := qualifiedType(e.Value, pkgName, localTypes)
+		return "map[" + key + "]" + value, append(keyPkgs, valuePkgs...), ok
+	}
+	return "", nil, false
+}
+
+// sampleValue 返回满足常见校验规则的示例值（Go 表达式），用于生成的测试
+func sampleValue(expr ast.Expr, binding string) string {
+	rules := map[string]string{}
+	for _, rule := range strings.Split(binding, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		rules[key] = value
+	}
+
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return sampleValue(e.X, binding)
+	case *ast.ArrayType:
+		return "[]interface{}{" + sampleValue(e.Elt, "") + "}"
+	case *ast.MapType:
+		return "map[string]interface{}{}"
+	case *ast.SelectorExpr:
+		switch e.Sel.Name {
+		case "Time":
+			return `"2024-01-01T00:00:00Z"`
+		case "UUID":
+			return `"123e4567-e89b-12d3-a456-426614174000"`
+		case "Duration":
+			return "1000000000"
+		case "Decimal":
+			return `"1.5"`
+		}
+		return `""`
+	case *ast.Ident:
+		if options, ok := rules["oneof"]; ok && options != "" {
+			first := strings.Fields(options)[0]
+			if isNumericType(e.Name) {
+				return first
+			}
+			return strconv.Quote(first)
+		}
+		switch {
+		case e.Name == "bool":
+			return "true"
+		case isNumericType(e.Name):
+			n := 1
+			for _, key := range []string{"min", "gte", "len"} {
+				if v, err := strconv.Atoi(rules[key]); err == nil && v > n {
+					n = v
+				}
+			}
+			if v, err := strconv.Atoi(rules["gt"]); err == nil && v >= n {
+				n = v + 1
+			}
+			return strconv.Itoa(n)
+		}
+		switch {
+		case hasRule(rules, "email"):
+			return `"test@example.com"`
+		case hasRule(rules, "url"), hasRule(rules, "uri"):
+			return `"https://example.com"`
+		case hasRule(rules, "uuid"), hasRule(rules, "uuid4"):
+			return `"123e4567-e89b-12d3-a456-426614174000"`
+		}
+		n := 4
+		for _, key := range []string{"min", "len"} {
+			if v, err := strconv.Atoi(rules[key]); err == nil && v > n {
+				n = v
+			}
+		}
+		if v, err := strconv.Atoi(rules["max"]); err == nil && v < n && v > 0 {
+			n = v
+		}
+		return strconv.Quote(strings.Repeat("a", n))
+	}
+	return "nil"
+}
+
+// varName 模型变量名，避免与关键字冲突
+func varName(typeName string) string {
+	name := lowerFirst(typeName)
+	if token.IsKeyword(name) {
+		name += "Model"
+	}
+	return name
+}
+
+func hasRule(rules map[string]string, name string) bool {
+	_, ok := rules[name]
+	return ok
+}
+
+func isBuiltinType(name string) bool {
+	switch name {
+	case "string", "bool", "byte", "rune", "float32", "float64":
+		return true
+	}
+	return isNumericType(name)
+}
+
+func isNumericType(name string) bool {
+	switch name {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "byte", "rune":
+		return true
+	}
+	return false
+}
+
+func isTimestampField(name string) bool {
+	return name == "CreatedAt" || name == "UpdatedAt" || name == "DeletedAt"
+}
+
+func identName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// readModulePath 读取 go.mod 中的模块路径
+func readModulePath(goMod string) (string, error) {
+	file, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s 中没有 module 声明", goMod)
+}
+
+// importPathFor 根据最近的 go.mod 计算目录的导入路径
+func importPathFor(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; root = filepath.Dir(root) {
+		module, err := readModulePath(filepath.Join(root, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return module, nil
+			}
+			return module + "/" + filepath.ToSlash(rel), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("找不到 %s 所在模块的 go.mod", dir)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"go/format"
 	"log"
 	"os"
 	"path/filepath"
@@ -233,11 +235,43 @@ func main() {
 `
 
 func main() {
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		// 兼容旧用法：codegen -package xxx
+		runModule(os.Args[1:])
+		return
+	}
+
+	switch os.Args[1] {
+	case "module":
+		runModule(os.Args[2:])
+	case "service":
+		runService(os.Args[2:])
+	case "crud":
+		runCRUD(os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "未知子命令: %s\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `用法:
+  codegen module  -package <name> [-output .]          生成共享库模块模板
+  codegen service -name <name> [-module <path>] [-output .]  生成微服务骨架
+  codegen crud    -model <file> -type <Model> [-output .]    根据模型生成仓储、服务、处理器和测试
+
+运行 codegen <子命令> -h 查看参数说明`)
+}
+
+// runModule 生成共享库模块模板
+func runModule(args []string) {
+	flags := flag.NewFlagSet("module", flag.ExitOnError)
 	var (
-		packageName = flag.String("package", "", "Package name (required)")
-		outputDir   = flag.String("output", ".", "Output directory")
+		packageName = flags.String("package", "", "Package name (required)")
+		outputDir   = flags.String("output", ".", "Output directory")
 	)
-	flag.Parse()
+	flags.Parse(args)
 
 	if *packageName == "" {
 		log.Fatal("Package name is required")
@@ -292,20 +326,31 @@ func main() {
 	fmt.Printf("5. 编辑 %s 添加使用示例\n", exampleFile)
 }
 
-func generateFile(filename, tmplContent string, data ModuleTemplate) error {
+func generateFile(filename, tmplContent string, data interface{}) error {
 	tmpl, err := template.New("file").Parse(tmplContent)
 	if err != nil {
 		return fmt.Errorf("解析模板失败: %w", err)
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("创建文件失败: %w", err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("执行模板失败: %w", err)
 	}
-	defer file.Close()
 
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("执行模板失败: %w", err)
+	content := buf.Bytes()
+	if strings.HasSuffix(filename, ".go") {
+		formatted, err := format.Source(content)
+		if err != nil {
+			return fmt.Errorf("格式化代码失败: %w", err)
+		}
+		content = formatted
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		return fmt.Errorf("创建文件失败: %w", err)
 	}
 
 	return nil
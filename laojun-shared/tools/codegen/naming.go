@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// exportedName 将 order-items、order_items 转换为 OrderItems
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '-' || r == '_' || r == ' ' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// snakeCase 将 OrderItem、APIKey 转换为 order_item、api_key
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// 单词边界：小写后的大写，或连续大写的最后一个（APIKey 的 K）
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// lowerFirst 返回首个单词小写的变量名，如 OrderItem -> orderItem、APIKey -> apiKey
+func lowerFirst(name string) string {
+	runes := []rune(name)
+	i := 0
+	for i < len(runes) && unicode.IsUpper(runes[i]) {
+		i++
+	}
+	if i == 0 {
+		return name
+	}
+	if i > 1 && i < len(runes) {
+		// 连续大写后跟小写时，最后一个大写字母属于下一个单词
+		i--
+	}
+	return strings.ToLower(string(runes[:i])) + string(runes[i:])
+}
+
+// pluralize 英文复数形式，只处理常见规则
+func pluralize(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
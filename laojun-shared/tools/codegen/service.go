@@ -0,0 +1,691 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ServiceTemplate 微服务骨架模板数据
+type ServiceTemplate struct {
+	Name   string // 服务名，如 orders
+	Title  string // 展示名，如 Orders
+	Module string // Go 模块路径
+	Binary string // 二进制和镜像名，如 laojun-orders
+	Port   int
+}
+
+// go.mod 模板，laojun-shared 通过 replace 指向同级目录
+var serviceGoModTemplate = `module {{.Module}}
+
+go 1.21
+
+require (
+	github.com/codetaoist/laojun-shared v0.0.0
+	github.com/gin-gonic/gin v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
+	gorm.io/driver/postgres v1.6.0
+	gorm.io/gorm v1.30.0
+)
+
+replace github.com/codetaoist/laojun-shared => ../laojun-shared
+`
+
+// 入口模板
+var serviceMainTemplate = `package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	stdlog "log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/health"
+	"github.com/codetaoist/laojun-shared/logger"
+	"github.com/codetaoist/laojun-shared/metrics"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"{{.Module}}/internal/config"
+	"{{.Module}}/internal/handlers"
+	"{{.Module}}/internal/services"
+)
+
+// 构建信息，通过 -ldflags 注入
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+func main() {
+	configFile := flag.String("config", "configs/config.yaml", "配置文件路径")
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		stdlog.Fatalf("加载配置失败: %v", err)
+	}
+	cfg.Log.Version = Version
+	cfg.Metrics.Version = Version
+
+	log := logger.New(cfg.Log)
+	m := metrics.New(cfg.Metrics)
+	hm := health.NewHealthManager(health.HealthConfig{
+		Enabled: true,
+		Timeout: 5 * time.Second,
+		Service: health.ServiceConfig{
+			Name:        cfg.Service,
+			Version:     Version,
+			Environment: cfg.Environment,
+		},
+	}).(*health.DefaultHealthManager)
+
+	db, err := openDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("连接数据库失败: %v", err)
+	}
+	if db != nil {
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("获取数据库连接失败: %v", err)
+		}
+		defer sqlDB.Close()
+		if err := hm.AddChecker(health.NewEnhancedDatabaseChecker("database", sqlDB)); err != nil {
+			log.Fatalf("注册数据库健康检查失败: %v", err)
+		}
+	}
+
+	router := handlers.NewRouter(handlers.Dependencies{
+		Config:  cfg,
+		Logger:  log,
+		Metrics: m,
+		Health:  hm,
+		DB:      db,
+		Service: services.New(log),
+	})
+
+	server := &http.Server{
+		Addr:         cfg.Server.Addr(),
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+	}
+
+	go func() {
+		log.Infow("服务启动",
+			logger.String("addr", server.Addr),
+			logger.String("version", Version),
+			logger.String("commit", Commit),
+			logger.String("build_time", BuildTime),
+		)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("服务启动失败: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Info("正在关闭服务...")
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Errorf("服务关闭失败: %v", err)
+	}
+	log.Info("服务已停止")
+}
+
+// openDatabase 连接数据库，未配置 DSN 时返回 nil
+func openDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	if cfg.DSN == "" {
+		return nil, nil
+	}
+
+	db, err := gorm.Open(postgres.Open(cfg.DSN), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	return db, nil
+}
+`
+
+// 配置模板
+var serviceConfigTemplate = `package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/codetaoist/laojun-shared/logger"
+	"github.com/codetaoist/laojun-shared/metrics"
+	"gopkg.in/yaml.v3"
+)
+
+// Config {{.Title}} 服务配置
+type Config struct {
+	Service     string         ` + "`" + `yaml:"service"` + "`" + `
+	Environment string         ` + "`" + `yaml:"environment"` + "`" + `
+	Server      ServerConfig   ` + "`" + `yaml:"server"` + "`" + `
+	Database    DatabaseConfig ` + "`" + `yaml:"database"` + "`" + `
+	Log         logger.Config  ` + "`" + `yaml:"log"` + "`" + `
+	Metrics     metrics.Config ` + "`" + `yaml:"metrics"` + "`" + `
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	Host            string        ` + "`" + `yaml:"host"` + "`" + `
+	Port            int           ` + "`" + `yaml:"port"` + "`" + `
+	Mode            string        ` + "`" + `yaml:"mode"` + "`" + `
+	ReadTimeout     time.Duration ` + "`" + `yaml:"read_timeout"` + "`" + `
+	WriteTimeout    time.Duration ` + "`" + `yaml:"write_timeout"` + "`" + `
+	ShutdownTimeout time.Duration ` + "`" + `yaml:"shutdown_timeout"` + "`" + `
+}
+
+// DatabaseConfig 数据库配置，DSN 为空时不连接数据库
+type DatabaseConfig struct {
+	DSN             string        ` + "`" + `yaml:"dsn"` + "`" + `
+	MaxOpenConns    int           ` + "`" + `yaml:"max_open_conns"` + "`" + `
+	MaxIdleConns    int           ` + "`" + `yaml:"max_idle_conns"` + "`" + `
+	ConnMaxLifetime time.Duration ` + "`" + `yaml:"conn_max_lifetime"` + "`" + `
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		Service:     "{{.Binary}}",
+		Environment: "development",
+		Server: ServerConfig{
+			Port:            {{.Port}},
+			Mode:            "debug",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 5 * time.Minute,
+		},
+		Log: logger.Config{
+			Level:  "info",
+			Format: "json",
+			Output: "stdout",
+		},
+		Metrics: metrics.Config{
+			Enabled:   true,
+			Path:      "/metrics",
+			Namespace: "laojun",
+		},
+	}
+}
+
+// Load 加载配置，优先级：环境变量 > 配置文件 > 默认值。配置文件不存在时忽略。
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := yaml.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	cfg.applyEnv()
+	cfg.Log.Service = cfg.Service
+	cfg.Log.Environment = cfg.Environment
+	cfg.Metrics.Service = cfg.Service
+	cfg.Metrics.Environment = cfg.Environment
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv 使用环境变量覆盖配置
+func (c *Config) applyEnv() {
+	if v := os.Getenv("ENVIRONMENT"); v != "" {
+		c.Environment = v
+	}
+	if v := os.Getenv("SERVER_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			c.Server.Port = port
+		}
+	}
+	if v := os.Getenv("GIN_MODE"); v != "" {
+		c.Server.Mode = v
+	}
+	if v := os.Getenv("DATABASE_DSN"); v != "" {
+		c.Database.DSN = v
+	}
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		c.Log.Level = v
+	}
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	if c.Service == "" {
+		return errors.New("service 不能为空")
+	}
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		return fmt.Errorf("server.port 无效: %d", c.Server.Port)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return errors.New("server.shutdown_timeout 必须大于 0")
+	}
+	return nil
+}
+
+// Addr 返回监听地址
+func (s ServerConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+`
+
+// 配置测试模板
+var serviceConfigTestTemplate = `package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{name: "default", modify: func(*Config) {}},
+		{name: "empty service", modify: func(c *Config) { c.Service = "" }, wantErr: true},
+		{name: "invalid port", modify: func(c *Config) { c.Server.Port = 0 }, wantErr: true},
+		{name: "no shutdown timeout", modify: func(c *Config) { c.Server.ShutdownTimeout = 0 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  port: 9000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOG_LEVEL", "debug")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Port != 9000 || cfg.Log.Level != "debug" || cfg.Log.Service != cfg.Service {
+		t.Errorf("Load() = %+v", cfg)
+	}
+}
+`
+
+// 路由模板，codegen crud 会在 codegen:routes 标记处注册生成的路由
+var serviceRouterTemplate = `package handlers
+
+import (
+	"github.com/codetaoist/laojun-shared/health"
+	"github.com/codetaoist/laojun-shared/logger"
+	"github.com/codetaoist/laojun-shared/metrics"
+	"github.com/codetaoist/laojun-shared/middleware"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"{{.Module}}/internal/config"
+	"{{.Module}}/internal/services"
+)
+
+// Dependencies 路由依赖
+type Dependencies struct {
+	Config  *config.Config
+	Logger  logger.Logger
+	Metrics metrics.Metrics
+	Health  *health.DefaultHealthManager
+	DB      *gorm.DB
+	Service *services.Service
+}
+
+// NewRouter 创建路由
+func NewRouter(deps Dependencies) *gin.Engine {
+	if deps.Config.Server.Mode == gin.ReleaseMode {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router := gin.New()
+	router.Use(
+		middleware.RequestID(),
+		middleware.Recovery(),
+		metrics.GinMiddleware(deps.Metrics),
+	)
+
+	// 探针和指标
+	deps.Health.RegisterGinProbes(router)
+	router.GET(deps.Config.Metrics.Path, gin.WrapH(deps.Metrics.Handler()))
+
+	api := router.Group("/api/v1")
+	NewPingHandler(deps.Service).Register(api)
+	// codegen:routes
+
+	return router
+}
+`
+
+// 示例处理器模板
+var serviceHandlerTemplate = `package handlers
+
+import (
+	"github.com/codetaoist/laojun-shared/utils"
+	"github.com/gin-gonic/gin"
+
+	"{{.Module}}/internal/services"
+)
+
+// PingHandler 连通性检查处理器
+type PingHandler struct {
+	service *services.Service
+}
+
+// NewPingHandler 创建处理器
+func NewPingHandler(service *services.Service) *PingHandler {
+	return &PingHandler{service: service}
+}
+
+// Register 注册路由
+func (h *PingHandler) Register(r *gin.RouterGroup) {
+	r.GET("/ping", h.Ping)
+}
+
+// Ping 返回服务状态
+func (h *PingHandler) Ping(c *gin.Context) {
+	utils.SuccessResponse(c, h.service.Ping(c.Request.Context()))
+}
+`
+
+// 业务服务模板
+var serviceServiceTemplate = `package services
+
+import (
+	"context"
+
+	"github.com/codetaoist/laojun-shared/logger"
+)
+
+// Service {{.Title}} 业务服务
+type Service struct {
+	log logger.Logger
+}
+
+// New 创建业务服务
+func New(log logger.Logger) *Service {
+	return &Service{log: log}
+}
+
+// Ping 返回服务状态
+func (s *Service) Ping(ctx context.Context) map[string]string {
+	s.log.WithContext(ctx).Debug("ping")
+	return map[string]string{"service": "{{.Binary}}", "status": "ok"}
+}
+`
+
+// 配置文件模板
+var serviceConfigYAMLTemplate = `service: {{.Binary}}
+environment: development
+
+server:
+  host: ""
+  port: {{.Port}}
+  mode: debug
+  read_timeout: 15s
+  write_timeout: 15s
+  shutdown_timeout: 10s
+
+database:
+  # 为空时不连接数据库，可通过 DATABASE_DSN 环境变量设置
+  dsn: ""
+  max_open_conns: 25
+  max_idle_conns: 5
+  conn_max_lifetime: 5m
+
+log:
+  level: info
+  format: json
+  output: stdout
+
+metrics:
+  enabled: true
+  path: /metrics
+  namespace: laojun
+`
+
+// Dockerfile 模板，构建上下文为仓库根目录以包含 laojun-shared
+var serviceDockerfileTemplate = `# 构建: 在仓库根目录执行 docker build -f {{.Binary}}/Dockerfile -t {{.Binary}} .
+
+# 多阶段构建 - 构建阶段
+FROM golang:1.21-alpine AS builder
+
+RUN apk add --no-cache git ca-certificates tzdata
+
+WORKDIR /src
+
+# 先复制 go mod 文件以利用缓存
+COPY laojun-shared/go.mod laojun-shared/go.sum ./laojun-shared/
+COPY {{.Binary}}/go.mod {{.Binary}}/go.sum ./{{.Binary}}/
+WORKDIR /src/{{.Binary}}
+RUN go mod download
+
+# 复制源代码
+WORKDIR /src
+COPY laojun-shared/ ./laojun-shared/
+COPY {{.Binary}}/ ./{{.Binary}}/
+
+WORKDIR /src/{{.Binary}}
+RUN CGO_ENABLED=0 GOOS=linux go build -ldflags='-w -s' -o /out/{{.Binary}} ./cmd
+
+# 运行阶段
+FROM alpine:latest
+
+RUN apk --no-cache add ca-certificates tzdata wget
+
+# 创建非root用户
+RUN addgroup -g 1001 -S app && adduser -u 1001 -S app -G app
+
+WORKDIR /app
+COPY --from=builder /out/{{.Binary}} .
+COPY --chown=app:app {{.Binary}}/configs/ ./configs/
+
+USER app
+
+EXPOSE {{.Port}}
+
+HEALTHCHECK --interval=30s --timeout=3s --start-period=5s --retries=3 \
+    CMD wget --no-verbose --tries=1 --spider http://localhost:{{.Port}}/livez || exit 1
+
+ENV GIN_MODE=release
+
+CMD ["./{{.Binary}}", "-config", "configs/config.yaml"]
+`
+
+var serviceDockerignoreTemplate = `.git
+*.md
+.env
+.env.*
+build/
+bin/
+`
+
+// Makefile 模板
+var serviceMakefileTemplate = `# {{.Binary}} Makefile
+VERSION ?= $(shell git describe --tags --always --dirty 2>/dev/null || echo "dev")
+COMMIT ?= $(shell git rev-parse --short HEAD 2>/dev/null || echo "unknown")
+BUILD_TIME ?= $(shell date -u '+%Y-%m-%d_%H:%M:%S')
+
+BINARY_NAME = {{.Binary}}
+MAIN_PATH = ./cmd
+BUILD_DIR = ./build
+DOCKER_IMAGE = {{.Binary}}
+DOCKER_TAG ?= latest
+
+LDFLAGS = -w -s \
+	-X 'main.Version=$(VERSION)' \
+	-X 'main.Commit=$(COMMIT)' \
+	-X 'main.BuildTime=$(BUILD_TIME)'
+
+.DEFAULT_GOAL := help
+
+.PHONY: help
+help: ## 显示帮助信息
+	@awk 'BEGIN {FS = ":.*?## "} /^[a-zA-Z_-]+:.*?## / {printf "  \033[36m%-15s\033[0m %s\n", $$1, $$2}' $(MAKEFILE_LIST)
+
+.PHONY: run
+run: ## 本地运行
+	go run $(MAIN_PATH) -config configs/config.yaml
+
+.PHONY: build
+build: ## 构建二进制文件
+	@mkdir -p $(BUILD_DIR)
+	CGO_ENABLED=0 go build -ldflags "$(LDFLAGS)" -o $(BUILD_DIR)/$(BINARY_NAME) $(MAIN_PATH)
+
+.PHONY: test
+test: ## 运行测试
+	go test -race ./...
+
+.PHONY: lint
+lint: ## 代码检查
+	go vet ./...
+	go run ../laojun-shared/tools/linter -dir .
+
+.PHONY: tidy
+tidy: ## 整理依赖
+	go mod tidy
+
+.PHONY: docker
+docker: ## 构建镜像（构建上下文为仓库根目录）
+	docker build -f Dockerfile -t $(DOCKER_IMAGE):$(DOCKER_TAG) ..
+
+.PHONY: clean
+clean: ## 清理构建文件
+	rm -rf $(BUILD_DIR)
+`
+
+// runService 生成微服务骨架
+func runService(args []string) {
+	flags := flag.NewFlagSet("service", flag.ExitOnError)
+	var (
+		name      = flags.String("name", "", "Service name, e.g. orders (required)")
+		module    = flags.String("module", "", "Go module path (default: github.com/codetaoist/laojun-<name>)")
+		outputDir = flags.String("output", ".", "Parent directory of the service")
+		port      = flags.Int("port", 8080, "HTTP port")
+		force     = flags.Bool("force", false, "Overwrite existing files")
+	)
+	flags.Parse(args)
+
+	if *name == "" {
+		log.Fatal("Service name is required")
+	}
+
+	data := newServiceTemplate(*name, *module, *port)
+	serviceDir := filepath.Join(*outputDir, data.Binary)
+
+	files := map[string]string{
+		"go.mod":                         serviceGoModTemplate,
+		"cmd/main.go":                    serviceMainTemplate,
+		"internal/config/config.go":      serviceConfigTemplate,
+		"internal/config/config_test.go": serviceConfigTestTemplate,
+		"internal/handlers/router.go":    serviceRouterTemplate,
+		"internal/handlers/ping.go":      serviceHandlerTemplate,
+		"internal/services/service.go":   serviceServiceTemplate,
+		"configs/config.yaml":            serviceConfigYAMLTemplate,
+		"Dockerfile":                     serviceDockerfileTemplate,
+		".dockerignore":                  serviceDockerignoreTemplate,
+		"Makefile":                       serviceMakefileTemplate,
+	}
+
+	if err := generateFiles(serviceDir, files, data, *force); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("\n✅ 成功生成 %s 服务骨架\n", data.Binary)
+	fmt.Println("\n下一步:")
+	fmt.Printf("1. cd %s && go mod tidy\n", serviceDir)
+	fmt.Println("2. make run 启动服务，访问 /api/v1/ping 和 /readyz")
+	fmt.Printf("3. codegen crud -model <模型文件> -type <模型名> -output %s 生成业务接口\n", serviceDir)
+}
+
+func newServiceTemplate(name, module string, port int) ServiceTemplate {
+	name = strings.TrimPrefix(strings.ToLower(name), "laojun-")
+	binary := "laojun-" + name
+	if module == "" {
+		module = "github.com/codetaoist/" + binary
+	}
+	return ServiceTemplate{
+		Name:   name,
+		Title:  exportedName(name),
+		Module: module,
+		Binary: binary,
+		Port:   port,
+	}
+}
+
+// generateFiles 按文件名顺序生成文件，已存在的文件除非 force 否则报错
+func generateFiles(dir string, files map[string]string, data interface{}, force bool) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !force {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("文件已存在: %s（使用 -force 覆盖）", path)
+			}
+		}
+	}
+
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := generateFile(path, files[name], data); err != nil {
+			return fmt.Errorf("生成文件 %s 失败: %w", path, err)
+		}
+		fmt.Printf("生成文件: %s\n", path)
+	}
+	return nil
+}
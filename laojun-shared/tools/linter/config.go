@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile 默认配置文件名，位于检查目录下
+const DefaultConfigFile = ".laojun-lint.yaml"
+
+// Config 检查器配置
+//
+//	exclude:
+//	  - "vendor/**"
+//	rules:
+//	  context-usage:
+//	    enabled: false
+//	  log-secrets:
+//	    severity: error
+//	overrides:
+//	  - paths: ["examples/**"]
+//	    rules:
+//	      log-secrets:
+//	        enabled: false
+//
+// 路径相对于检查目录，使用 / 分隔，支持 * 和 ** 通配符。
+type Config struct {
+	Exclude   []string              `yaml:"exclude"`
+	Rules     map[string]RuleConfig `yaml:"rules"`
+	Overrides []OverrideConfig      `yaml:"overrides"`
+}
+
+// RuleConfig 单条规则的配置，未设置的字段保持默认
+type RuleConfig struct {
+	Enabled  *bool  `yaml:"enabled"`
+	Severity string `yaml:"severity"`
+}
+
+// OverrideConfig 按路径覆盖规则配置，后面的覆盖优先
+type OverrideConfig struct {
+	Paths []string              `yaml:"paths"`
+	Rules map[string]RuleConfig `yaml:"rules"`
+}
+
+// LoadConfig 加载配置文件
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", filename, err)
+	}
+	return config, nil
+}
+
+// LoadConfigForDir 加载指定的配置文件；未指定时尝试检查目录下的默认配置，不存在则使用空配置
+func LoadConfigForDir(filename, dir string) (*Config, error) {
+	if filename != "" {
+		return LoadConfig(filename)
+	}
+	config, err := LoadConfig(filepath.Join(dir, DefaultConfigFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	return config, err
+}
+
+// Validate 校验配置中的规则名和严重级别
+func (c *Config) Validate(rules []Rule) error {
+	known := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		known[rule.Name] = true
+	}
+
+	check := func(settings map[string]RuleConfig) error {
+		for name, rule := range settings {
+			if !known[name] {
+				return fmt.Errorf("未知规则: %s", name)
+			}
+			switch rule.Severity {
+			case "", "error", "warning", "info":
+			default:
+				return fmt.Errorf("规则 %s 的严重级别无效: %s", name, rule.Severity)
+			}
+		}
+		return nil
+	}
+
+	if err := check(c.Rules); err != nil {
+		return err
+	}
+	for _, override := range c.Overrides {
+		if len(override.Paths) == 0 {
+			return errors.New("overrides 必须指定 paths")
+		}
+		if err := check(override.Rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// excluded 判断路径是否被排除
+func (c *Config) excluded(relPath string) bool {
+	if c == nil {
+		return false
+	}
+	for _, pattern := range c.Exclude {
+		if matchPath(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleSettings 返回规则在指定文件上是否启用以及覆盖后的严重级别（为空时使用规则默认值）
+func (c *Config) ruleSettings(name, relPath string) (bool, string) {
+	enabled, severity := true, ""
+	if c == nil {
+		return enabled, severity
+	}
+
+	apply := func(rule RuleConfig, ok bool) {
+		if !ok {
+			return
+		}
+		if rule.Enabled != nil {
+			enabled = *rule.Enabled
+		}
+		if rule.Severity != "" {
+			severity = rule.Severity
+		}
+	}
+
+	rule, ok := c.Rules[name]
+	apply(rule, ok)
+	for _, override := range c.Overrides {
+		for _, pattern := range override.Paths {
+			if matchPath(pattern, relPath) {
+				rule, ok := override.Rules[name]
+				apply(rule, ok)
+				break
+			}
+		}
+	}
+	return enabled, severity
+}
+
+// matchPath 匹配路径模式，** 匹配任意层目录；模式匹配目录时同样匹配其下所有文件
+func matchPath(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	name = strings.TrimPrefix(name, "./")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	// 模式已匹配完而路径还有剩余，说明模式匹配的是上层目录
+	return true
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Fix 问题的建议修复
+type Fix struct {
+	Message string     `json:"message"`
+	Edits   []TextEdit `json:"edits"`
+}
+
+// TextEdit 将文件中 [Start, End) 字节区间替换为 NewText，Start == End 时为插入
+type TextEdit struct {
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	NewText string `json:"newText"`
+}
+
+// insertAt 在指定位置插入文本
+func insertAt(fset *token.FileSet, pos token.Pos, text string) TextEdit {
+	offset := fset.Position(pos).Offset
+	return TextEdit{Start: offset, End: offset, NewText: text}
+}
+
+// replaceNode 替换节点对应的源码
+func replaceNode(fset *token.FileSet, node ast.Node, text string) TextEdit {
+	return TextEdit{
+		Start:   fset.Position(node.Pos()).Offset,
+		End:     fset.Position(node.End()).Offset,
+		NewText: text,
+	}
+}
+
+// ApplyFixes 将问题中的建议修复写回文件，返回每个文件应用的修复数。
+// 同一文件中互相重叠的修复只应用先出现的一个，其余留到下次运行。
+func ApplyFixes(issues []Issue) (map[string]int, error) {
+	fixesByFile := make(map[string][]*Fix)
+	var files []string
+	for _, issue := range issues {
+		if issue.Fix == nil || len(issue.Fix.Edits) == 0 {
+			continue
+		}
+		if _, ok := fixesByFile[issue.File]; !ok {
+			files = append(files, issue.File)
+		}
+		fixesByFile[issue.File] = append(fixesByFile[issue.File], issue.Fix)
+	}
+
+	applied := make(map[string]int)
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return applied, err
+		}
+
+		edits, count := selectEdits(fixesByFile[file])
+		fixed, err := applyEdits(src, edits)
+		if err != nil {
+			return applied, fmt.Errorf("%s: %w", file, err)
+		}
+		formatted, err := format.Source(fixed)
+		if err != nil {
+			return applied, fmt.Errorf("%s: 修复后的代码无法格式化: %w", file, err)
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return applied, err
+		}
+		if err := os.WriteFile(file, formatted, info.Mode()); err != nil {
+			return applied, err
+		}
+		applied[file] = count
+	}
+	return applied, nil
+}
+
+// selectEdits 挑选互不冲突的修复。完全相同的编辑（如多个修复都需要添加同一个 import）只保留一份。
+func selectEdits(fixes []*Fix) ([]TextEdit, int) {
+	var accepted []TextEdit
+	count := 0
+
+	for _, fix := range fixes {
+		var pending []TextEdit
+		conflict := false
+		for _, edit := range fix.Edits {
+			duplicate := false
+			for _, existing := range accepted {
+				if edit == existing {
+					duplicate = true
+					break
+				}
+				if editsOverlap(edit, existing) {
+					conflict = true
+					break
+				}
+			}
+			if conflict {
+				break
+			}
+			if !duplicate {
+				pending = append(pending, edit)
+			}
+		}
+		if conflict {
+			continue
+		}
+		accepted = append(accepted, pending...)
+		count++
+	}
+	return accepted, count
+}
+
+// editsOverlap 判断两个编辑是否冲突，同一位置的两个不同插入也视为冲突
+func editsOverlap(a, b TextEdit) bool {
+	if a.Start == b.Start {
+		return true
+	}
+	return a.Start < b.End && b.Start < a.End
+}
+
+// applyEdits 从后往前应用编辑，保证前面的偏移量不受影响
+func applyEdits(src []byte, edits []TextEdit) ([]byte, error) {
+	sorted := append([]TextEdit(nil), edits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start > sorted[j].Start
+	})
+
+	result := append([]byte(nil), src...)
+	for _, edit := range sorted {
+		if edit.Start < 0 || edit.End < edit.Start || edit.End > len(result) {
+			return nil, fmt.Errorf("编辑位置越界: %d-%d", edit.Start, edit.End)
+		}
+		tail := append([]byte(edit.NewText), result[edit.End:]...)
+		result = append(result[:edit.Start], tail...)
+	}
+	return result, nil
+}
+
+// importEdit 返回添加 import 的编辑，已经导入时返回 nil
+func importEdit(file *ast.File, fset *token.FileSet, importPath string) *TextEdit {
+	quoted := strconv.Quote(importPath)
+	for _, spec := range file.Imports {
+		if spec.Path.Value == quoted {
+			return nil
+		}
+	}
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		var edit TextEdit
+		if genDecl.Lparen.IsValid() {
+			// import 分组按 gofmt 规则在格式化时排序
+			edit = insertAt(fset, genDecl.Lparen+1, "\n\t"+quoted)
+		} else {
+			edit = insertAt(fset, genDecl.Pos(), "import "+quoted+"\n")
+		}
+		return &edit
+	}
+
+	edit := insertAt(fset, file.Name.End(), "\n\nimport "+quoted)
+	return &edit
+}
+
+// contextParamFix 为方法添加 ctx context.Context 作为第一个参数。
+// 调用方需要同步修改，因此只在显式使用 -fix 时应用。
+func contextParamFix(file *ast.File, fset *token.FileSet, funcDecl *ast.FuncDecl) *Fix {
+	params := funcDecl.Type.Params
+	if params == nil || !params.Opening.IsValid() {
+		return nil
+	}
+	for _, field := range params.List {
+		for _, name := range field.Names {
+			if name.Name == "ctx" {
+				return nil
+			}
+		}
+	}
+
+	text := "ctx context.Context"
+	if len(params.List) > 0 {
+		text += ", "
+	}
+	fix := &Fix{
+		Message: "添加 ctx context.Context 参数",
+		Edits:   []TextEdit{insertAt(fset, params.Opening+1, text)},
+	}
+	if edit := importEdit(file, fset, "context"); edit != nil {
+		fix.Edits = append(fix.Edits, *edit)
+	}
+	return fix
+}
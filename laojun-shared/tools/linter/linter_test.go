@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const handlerSource = `package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type Store struct{ db *sql.DB }
+
+func (s *Store) Load(id string) error {
+	rows, err := s.db.Query("SELECT 1 WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("query %s: %v", id, err)
+	}
+	for rows.Next() {
+	}
+	return nil
+}
+
+func Handle(ctx *gin.Context) {
+	password := ctx.Query("password")
+	logrus.WithField("user", "bob").Infof("login with %s", password)
+	if password == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "missing"})
+	}
+	ctx.JSON(http.StatusOK, gin.H{}) //nolint:gin-handler
+}
+`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func rulesOf(issues []Issue) map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		counts[issue.Rule]++
+	}
+	return counts
+}
+
+func TestNewRules(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "api/handler.go", handlerSource)
+
+	issues, err := NewLinter().CheckDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := rulesOf(issues)
+	// gin-handler: 参数命名 + 错误响应后缺少 return
+	want := map[string]int{"error-wrap": 1, "rows-close": 1, "log-secrets": 1, "gin-handler": 2, "context-usage": 1}
+	for rule, n := range want {
+		if counts[rule] != n {
+			t.Errorf("%s issues = %d, want %d (all: %v)", rule, counts[rule], n, counts)
+		}
+	}
+}
+
+func TestNolintAndConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "api/handler.go", handlerSource)
+	writeFile(t, dir, "vendor/lib/lib.go", handlerSource)
+	writeFile(t, dir, "internal/quiet.go", `package internal
+
+import "fmt"
+
+func wrap(err error) error {
+	//nolint:error-wrap // 有意丢弃错误链
+	return fmt.Errorf("failed: %v", err)
+}
+
+func wrapAll(err error) error {
+	return fmt.Errorf("failed: %v", err) //nolint
+}
+`)
+	writeFile(t, dir, DefaultConfigFile, `
+exclude: ["vendor/**"]
+rules:
+  context-usage:
+    enabled: false
+  error-wrap:
+    severity: error
+overrides:
+  - paths: ["api/**"]
+    rules:
+      log-secrets:
+        enabled: false
+`)
+
+	config, err := LoadConfigForDir("", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linter := NewLinterWithConfig(config)
+	if err := config.Validate(linter.rules); err != nil {
+		t.Fatal(err)
+	}
+	issues, err := linter.CheckDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, issue := range issues {
+		if strings.Contains(issue.File, "vendor") || strings.Contains(issue.File, "quiet.go") && issue.Rule == "error-wrap" {
+			t.Errorf("issue should be excluded: %+v", issue)
+		}
+		if issue.Rule == "error-wrap" && issue.Severity != "error" {
+			t.Errorf("error-wrap severity = %s", issue.Severity)
+		}
+	}
+	counts := rulesOf(issues)
+	if counts["context-usage"] != 0 || counts["log-secrets"] != 0 || counts["error-wrap"] != 1 {
+		t.Errorf("counts = %v", counts)
+	}
+
+	bad := &Config{Rules: map[string]RuleConfig{"no-such-rule": {}}}
+	if err := bad.Validate(linter.rules); err == nil {
+		t.Error("expected error for unknown rule")
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"vendor/**", "vendor/a/b.go", true},
+		{"**/*_gen.go", "api/v1/user_gen.go", true},
+		{"**/*_gen.go", "user_gen.go", true},
+		{"examples", "examples/cache_example.go", true},
+		{"api/*.go", "api/v1/user.go", false},
+		{"auth/**", "authz/jwt.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "api/handler.go", handlerSource)
+
+	linter := NewLinter()
+	issues, err := linter.CheckDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyFixes(issues); err != nil {
+		t.Fatal(err)
+	}
+
+	fixed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(fixed)
+	for _, want := range []string{
+		`"context"`,
+		"func (s *Store) Load(ctx context.Context, id string) error {",
+		`fmt.Errorf("query %s: %w", id, err)`,
+		"\t}\n\tdefer rows.Close()\n",
+		"func Handle(c *gin.Context) {",
+		"c.JSON(http.StatusBadRequest, gin.H{\"error\": \"missing\"})\n\t\treturn\n",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("fixed source missing %q:\n%s", want, src)
+		}
+	}
+
+	issues, err = linter.CheckDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		if issue.Fix != nil {
+			t.Errorf("fixable issue remains: %+v", issue)
+		}
+	}
+}
+
+func TestReports(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "api/handler.go", handlerSource)
+	linter := NewLinter()
+	issues, err := linter.CheckDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONReport(&buf, issues); err != nil {
+		t.Fatal(err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != len(issues) || report.Summary["error"] == 0 {
+		t.Errorf("json report = %+v", report.Summary)
+	}
+
+	buf.Reset()
+	if err := WriteSARIFReport(&buf, issues, linter.rules, dir); err != nil {
+		t.Fatal(err)
+	}
+	var sarif sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &sarif); err != nil {
+		t.Fatal(err)
+	}
+	run := sarif.Runs[0]
+	if sarif.Version != "2.1.0" || len(run.Tool.Driver.Rules) != len(linter.rules) || len(run.Results) != len(issues) {
+		t.Fatalf("sarif = %s", buf.String())
+	}
+	fixes := 0
+	for _, result := range run.Results {
+		loc := result.Locations[0].PhysicalLocation.ArtifactLocation
+		if loc.URI != "api/handler.go" || loc.URIBaseID != sarifRootID {
+			t.Errorf("artifact location = %+v", loc)
+		}
+		if run.Tool.Driver.Rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("rule index mismatch for %s", result.RuleID)
+		}
+		fixes += len(result.Fixes)
+	}
+	if fixes == 0 {
+		t.Error("expected fixes in SARIF results")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -21,22 +22,31 @@ type Rule struct {
 
 // Issue 检查发现的问题
 type Issue struct {
-	File        string
-	Line        int
-	Column      int
-	Rule        string
-	Message     string
-	Severity    string // error, warning, info
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"` // error, warning, info
+	Fix      *Fix   `json:"fix,omitempty"`
 }
 
 // Linter API规范检查器
 type Linter struct {
-	rules []Rule
+	rules  []Rule
+	config *Config
+	root   string
 }
 
 // NewLinter 创建新的检查器
 func NewLinter() *Linter {
+	return NewLinterWithConfig(&Config{})
+}
+
+// NewLinterWithConfig 使用配置创建检查器
+func NewLinterWithConfig(config *Config) *Linter {
 	return &Linter{
+		config: config,
 		rules: []Rule{
 			{
 				Name:        "interface-naming",
@@ -63,6 +73,26 @@ func NewLinter() *Linter {
 				Description: "Config结构体应该有Validate方法",
 				Check:       checkValidationMethod,
 			},
+			{
+				Name:        "error-wrap",
+				Description: "fmt.Errorf包装错误时应该使用%w",
+				Check:       checkErrorWrap,
+			},
+			{
+				Name:        "gin-handler",
+				Description: "gin处理函数的参数命名为c，错误响应后应该return",
+				Check:       checkGinHandler,
+			},
+			{
+				Name:        "rows-close",
+				Description: "查询返回的rows应该通过defer关闭",
+				Check:       checkRowsClose,
+			},
+			{
+				Name:        "log-secrets",
+				Description: "日志中不应该输出密码、令牌等敏感信息",
+				Check:       checkLogSecrets,
+			},
 		},
 	}
 }
@@ -70,12 +100,20 @@ func NewLinter() *Linter {
 // CheckDirectory 检查目录
 func (l *Linter) CheckDirectory(dir string) ([]Issue, error) {
 	var allIssues []Issue
+	l.root = dir
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		if l.config.excluded(l.relPath(path)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
 			return nil
 		}
@@ -100,19 +138,41 @@ func (l *Linter) CheckFile(filename string) ([]Issue, error) {
 		return nil, err
 	}
 
+	relPath := l.relPath(filename)
+	suppressed := nolintDirectives(node, fset)
+
 	var issues []Issue
 	for _, rule := range l.rules {
-		ruleIssues := rule.Check(node, fset)
-		for i := range ruleIssues {
-			ruleIssues[i].File = filename
-			ruleIssues[i].Rule = rule.Name
+		enabled, severity := l.config.ruleSettings(rule.Name, relPath)
+		if !enabled {
+			continue
+		}
+		for _, issue := range rule.Check(node, fset) {
+			if suppressed.covers(rule.Name, issue.Line) {
+				continue
+			}
+			issue.File = filename
+			issue.Rule = rule.Name
+			if severity != "" {
+				issue.Severity = severity
+			}
+			issues = append(issues, issue)
 		}
-		issues = append(issues, ruleIssues...)
 	}
 
 	return issues, nil
 }
 
+// relPath 返回相对于检查根目录的路径，用于匹配配置中的路径模式
+func (l *Linter) relPath(path string) string {
+	if l.root != "" {
+		if rel, err := filepath.Rel(l.root, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
 // 检查接口命名
 func checkInterfaceNaming(file *ast.File, fset *token.FileSet) []Issue {
 	var issues []Issue
@@ -232,6 +292,7 @@ func checkContextUsage(file *ast.File, fset *token.FileSet) []Issue {
 						Column:   pos.Column,
 						Message:  fmt.Sprintf("公共方法 '%s' 应该接受context.Context参数", funcDecl.Name.Name),
 						Severity: "info",
+						Fix:      contextParamFix(file, fset, funcDecl),
 					})
 				}
 			}
@@ -327,30 +388,84 @@ func isContextType(expr ast.Expr) bool {
 
 func main() {
 	var (
-		dir    = flag.String("dir", ".", "Directory to check")
-		format = flag.String("format", "text", "Output format: text, json")
+		dir        = flag.String("dir", ".", "Directory to check")
+		format     = flag.String("format", "text", "Output format: text, json, sarif")
+		configFile = flag.String("config", "", "Config file (default: <dir>/"+DefaultConfigFile+")")
+		fix        = flag.Bool("fix", false, "Apply suggested fixes")
+		output     = flag.String("o", "", "Write report to file instead of stdout")
 	)
 	flag.Parse()
 
-	linter := NewLinter()
+	config, err := LoadConfigForDir(*configFile, *dir)
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+	linter := NewLinterWithConfig(config)
+	if err := config.Validate(linter.rules); err != nil {
+		log.Fatalf("配置无效: %v", err)
+	}
+
 	issues, err := linter.CheckDirectory(*dir)
 	if err != nil {
 		log.Fatalf("检查失败: %v", err)
 	}
 
-	if len(issues) == 0 {
-		fmt.Println("✅ 没有发现API规范问题")
-		return
+	if *fix {
+		applied, err := ApplyFixes(issues)
+		if err != nil {
+			log.Fatalf("修复失败: %v", err)
+		}
+		total := 0
+		for _, count := range applied {
+			total += count
+		}
+		if total > 0 {
+			fmt.Fprintf(os.Stderr, "🔧 已修复 %d 个问题，涉及 %d 个文件\n", total, len(applied))
+			// 修复后重新检查，报告剩余的问题
+			if issues, err = linter.CheckDirectory(*dir); err != nil {
+				log.Fatalf("检查失败: %v", err)
+			}
+		}
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("创建报告文件失败: %v", err)
+		}
+		defer f.Close()
+		out = f
 	}
 
 	switch *format {
 	case "json":
-		// TODO: 实现JSON输出
-		fmt.Println("JSON格式输出暂未实现")
+		err = WriteJSONReport(out, issues)
+	case "sarif":
+		err = WriteSARIFReport(out, issues, linter.rules, *dir)
 	default:
-		printTextReport(issues)
+		err = writeTextReport(out, issues)
+	}
+	if err != nil {
+		log.Fatalf("输出报告失败: %v", err)
+	}
+
+	for _, issue := range issues {
+		if issue.Severity == "error" {
+			out.Close()
+			os.Exit(1)
+		}
+	}
+}
+
+func writeTextReport(w io.Writer, issues []Issue) error {
+	if len(issues) == 0 {
+		_, err := fmt.Fprintln(w, "✅ 没有发现API规范问题")
+		return err
 	}
 
+	printTextReport(w, issues)
+
 	// 统计
 	errorCount := 0
 	warningCount := 0
@@ -367,12 +482,13 @@ func main() {
 		}
 	}
 
-	fmt.Printf("\n📊 检查结果: %d 错误, %d 警告, %d 信息\n", errorCount, warningCount, infoCount)
+	_, err := fmt.Fprintf(w, "\n📊 检查结果: %d 错误, %d 警告, %d 信息\n", errorCount, warningCount, infoCount)
+	return err
 }
 
-func printTextReport(issues []Issue) {
-	fmt.Println("🔍 API规范检查报告:")
-	fmt.Println(strings.Repeat("=", 50))
+func printTextReport(w io.Writer, issues []Issue) {
+	fmt.Fprintln(w, "🔍 API规范检查报告:")
+	fmt.Fprintln(w, strings.Repeat("=", 50))
 
 	for _, issue := range issues {
 		severity := ""
@@ -385,13 +501,19 @@ func printTextReport(issues []Issue) {
 			severity = "ℹ️"
 		}
 
-		fmt.Printf("%s %s:%d:%d [%s] %s\n",
+		fix := ""
+		if issue.Fix != nil {
+			fix = " (可自动修复)"
+		}
+
+		fmt.Fprintf(w, "%s %s:%d:%d [%s] %s%s\n",
 			severity,
 			filepath.Base(issue.File),
 			issue.Line,
 			issue.Column,
 			issue.Rule,
 			issue.Message,
+			fix,
 		)
 	}
 }
\ No newline at end of file
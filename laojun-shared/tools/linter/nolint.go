@@ -0,0 +1,70 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// nolintSet 文件中的 //nolint 注释，按行记录被抑制的规则，空集合表示抑制全部规则
+type nolintSet map[int]map[string]bool
+
+// nolintDirectives 收集文件中的 //nolint 和 //nolint:rule1,rule2 注释。
+// 注释作用于所在行；单独成行时同时作用于下一行。
+func nolintDirectives(file *ast.File, fset *token.FileSet) nolintSet {
+	set := nolintSet{}
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			text := strings.TrimPrefix(comment.Text, "//")
+			if !strings.HasPrefix(text, "nolint") {
+				continue
+			}
+			text = strings.TrimPrefix(text, "nolint")
+			// 允许在规则后面写说明：//nolint:rows-close // 由调用方关闭
+			if i := strings.IndexAny(text, " \t"); i >= 0 {
+				text = text[:i]
+			}
+
+			var rules map[string]bool
+			if strings.HasPrefix(text, ":") {
+				rules = map[string]bool{}
+				for _, name := range strings.Split(text[1:], ",") {
+					if name = strings.TrimSpace(name); name != "" {
+						rules[name] = true
+					}
+				}
+			} else if text != "" {
+				// 如 //nolintfoo，不是指令
+				continue
+			}
+
+			line := fset.Position(comment.Pos()).Line
+			set.add(line, rules)
+			set.add(line+1, rules)
+		}
+	}
+	return set
+}
+
+func (s nolintSet) add(line int, rules map[string]bool) {
+	existing, ok := s[line]
+	if ok && len(existing) == 0 {
+		return
+	}
+	if !ok || len(rules) == 0 {
+		s[line] = rules
+		return
+	}
+	for name := range rules {
+		existing[name] = true
+	}
+}
+
+// covers 判断指定行上的规则是否被抑制
+func (s nolintSet) covers(rule string, line int) bool {
+	rules, ok := s[line]
+	if !ok {
+		return false
+	}
+	return len(rules) == 0 || rules[rule]
+}
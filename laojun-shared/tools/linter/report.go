@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+// jsonReport JSON 格式的检查报告
+type jsonReport struct {
+	Issues  []Issue        `json:"issues"`
+	Summary map[string]int `json:"summary"`
+}
+
+// WriteJSONReport 输出 JSON 格式的报告
+func WriteJSONReport(w io.Writer, issues []Issue) error {
+	report := jsonReport{
+		Issues:  issues,
+		Summary: map[string]int{"error": 0, "warning": 0, "info": 0},
+	}
+	if report.Issues == nil {
+		report.Issues = []Issue{}
+	}
+	for _, issue := range issues {
+		report.Summary[issue.Severity]++
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// SARIF 2.1.0 报告结构，只包含用到的字段
+// 规范见 https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifRootID  = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+type sarifByteRegion struct {
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifByteRegion `json:"deletedRegion"`
+	InsertedContent *sarifContent   `json:"insertedContent,omitempty"`
+}
+
+type sarifContent struct {
+	Text string `json:"text"`
+}
+
+// WriteSARIFReport 输出 SARIF 2.1.0 格式的报告，文件路径相对于检查目录
+func WriteSARIFReport(w io.Writer, issues []Issue, rules []Rule, root string) error {
+	driver := sarifDriver{Name: "laojun-linter"}
+	ruleIndex := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		ruleIndex[rule.Name] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               rule.Name,
+			ShortDescription: sarifMessage{Text: rule.Description},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, issue := range issues {
+		artifact := sarifArtifact(issue.File, root)
+		result := sarifResult{
+			RuleID:    issue.Rule,
+			RuleIndex: ruleIndex[issue.Rule],
+			Level:     sarifLevel(issue.Severity),
+			Message:   sarifMessage{Text: issue.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: artifact,
+					Region:           sarifRegion{StartLine: issue.Line, StartColumn: issue.Column},
+				},
+			}},
+		}
+
+		if issue.Fix != nil && len(issue.Fix.Edits) > 0 {
+			change := sarifArtifactChange{ArtifactLocation: artifact}
+			for _, edit := range issue.Fix.Edits {
+				replacement := sarifReplacement{
+					DeletedRegion: sarifByteRegion{ByteOffset: edit.Start, ByteLength: edit.End - edit.Start},
+				}
+				if edit.NewText != "" {
+					replacement.InsertedContent = &sarifContent{Text: edit.NewText}
+				}
+				change.Replacements = append(change.Replacements, replacement)
+			}
+			result.Fixes = []sarifFix{{
+				Description:     sarifMessage{Text: issue.Fix.Message},
+				ArtifactChanges: []sarifArtifactChange{change},
+			}}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+func sarifArtifact(file, root string) sarifArtifactLocation {
+	if rel, err := filepath.Rel(root, file); err == nil {
+		return sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: sarifRootID}
+	}
+	return sarifArtifactLocation{URI: filepath.ToSlash(file)}
+}
+
+// sarifLevel 将严重级别映射为 SARIF 的 level
+func sarifLevel(severity string) string {
+	switch severity {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	}
+	return "note"
+}
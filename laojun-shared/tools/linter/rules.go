@@ -0,0 +1,535 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 检查 fmt.Errorf 包装错误时是否使用 %w
+func checkErrorWrap(file *ast.File, fset *token.FileSet) []Issue {
+	var issues []Issue
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isFmtErrorf(call) || len(call.Args) < 2 {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		verbs, ok := formatVerbs(lit.Value)
+		if !ok {
+			return true
+		}
+		for _, verb := range verbs {
+			if verb.verb == 'w' {
+				return true
+			}
+		}
+
+		var edits []TextEdit
+		var names []string
+		for i, verb := range verbs {
+			if i+1 >= len(call.Args) || (verb.verb != 'v' && verb.verb != 's') {
+				continue
+			}
+			name, ok := errorExprName(call.Args[i+1])
+			if !ok {
+				continue
+			}
+			names = append(names, name)
+			offset := fset.Position(lit.Pos()).Offset + verb.offset
+			edits = append(edits, TextEdit{Start: offset, End: offset + verb.length, NewText: "%w"})
+		}
+		if len(edits) == 0 {
+			return true
+		}
+
+		pos := fset.Position(call.Pos())
+		issues = append(issues, Issue{
+			Line:     pos.Line,
+			Column:   pos.Column,
+			Message:  fmt.Sprintf("fmt.Errorf 应该使用 %%w 包装 %s，以便 errors.Is/As 判断", strings.Join(names, ", ")),
+			Severity: "warning",
+			Fix:      &Fix{Message: "将格式化动词替换为 %w", Edits: edits},
+		})
+		return true
+	})
+
+	return issues
+}
+
+// formatVerb 格式化字符串中的一个动词，offset 为相对字面量源码的偏移
+type formatVerb struct {
+	verb   byte
+	offset int
+	length int
+}
+
+// formatVerbs 解析格式化字符串中的动词。包含 * 或显式参数索引时无法对应参数，返回 false。
+func formatVerbs(literal string) ([]formatVerb, bool) {
+	var verbs []formatVerb
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		for i < len(literal) && strings.IndexByte("+-# 0123456789.", literal[i]) >= 0 {
+			i++
+		}
+		if i >= len(literal) {
+			break
+		}
+		switch literal[i] {
+		case '%':
+			continue
+		case '*', '[':
+			return nil, false
+		}
+		verbs = append(verbs, formatVerb{verb: literal[i], offset: start, length: i - start + 1})
+	}
+	return verbs, true
+}
+
+// errorExprName 判断表达式是否为错误变量，如 err、parseErr、e.err
+func errorExprName(expr ast.Expr) (string, bool) {
+	var name string
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	default:
+		return "", false
+	}
+	if name == "err" || strings.HasSuffix(name, "Err") || strings.HasSuffix(name, "Error") && name != "Error" {
+		return name, true
+	}
+	return "", false
+}
+
+// ginResponseMethods 输出响应的 gin.Context 方法，第一个参数为状态码
+var ginResponseMethods = map[string]bool{
+	"JSON":                true,
+	"IndentedJSON":        true,
+	"PureJSON":            true,
+	"SecureJSON":          true,
+	"XML":                 true,
+	"String":              true,
+	"Status":              true,
+	"AbortWithStatus":     true,
+	"AbortWithStatusJSON": true,
+}
+
+// nonErrorStatuses net/http 中 1xx-3xx 的状态码常量
+var nonErrorStatuses = map[string]bool{
+	"StatusContinue": true, "StatusSwitchingProtocols": true, "StatusProcessing": true, "StatusEarlyHints": true,
+	"StatusOK": true, "StatusCreated": true, "StatusAccepted": true, "StatusNonAuthoritativeInfo": true,
+	"StatusNoContent": true, "StatusResetContent": true, "StatusPartialContent": true, "StatusMultiStatus": true,
+	"StatusAlreadyReported": true, "StatusIMUsed": true, "StatusMultipleChoices": true, "StatusMovedPermanently": true,
+	"StatusFound": true, "StatusSeeOther": true, "StatusNotModified": true, "StatusUseProxy": true,
+	"StatusTemporaryRedirect": true, "StatusPermanentRedirect": true,
+}
+
+// 检查 gin 处理函数：*gin.Context 参数命名为 c，错误响应后应该 return
+func checkGinHandler(file *ast.File, fset *token.FileSet) []Issue {
+	var issues []Issue
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		var funcType *ast.FuncType
+		var body *ast.BlockStmt
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			funcType, body = fn.Type, fn.Body
+		case *ast.FuncLit:
+			funcType, body = fn.Type, fn.Body
+		default:
+			return true
+		}
+		if body == nil {
+			return true
+		}
+
+		param := ginContextParam(funcType)
+		if param == nil {
+			return true
+		}
+
+		if param.Name != "c" && param.Name != "_" {
+			pos := fset.Position(param.Pos())
+			issues = append(issues, Issue{
+				Line:     pos.Line,
+				Column:   pos.Column,
+				Message:  fmt.Sprintf("gin 处理函数的 *gin.Context 参数应该命名为 c，而不是 %s", param.Name),
+				Severity: "info",
+				Fix:      renameParamFix(fset, param, body, "c"),
+			})
+		}
+
+		// 只检查函数体顶层的 if：错误响应之后还有语句会继续执行
+		for i, stmt := range body.List {
+			ifStmt, ok := stmt.(*ast.IfStmt)
+			if !ok || i == len(body.List)-1 || len(ifStmt.Body.List) == 0 {
+				continue
+			}
+			last := ifStmt.Body.List[len(ifStmt.Body.List)-1]
+			if !isGinErrorResponse(last, param.Name) {
+				continue
+			}
+			pos := fset.Position(last.Pos())
+			issues = append(issues, Issue{
+				Line:     pos.Line,
+				Column:   pos.Column,
+				Message:  "返回错误响应后应该 return，否则处理函数会继续执行",
+				Severity: "error",
+				Fix: &Fix{
+					Message: "在错误响应后添加 return",
+					Edits:   []TextEdit{insertAt(fset, last.End(), "\nreturn")},
+				},
+			})
+		}
+		return true
+	})
+
+	return issues
+}
+
+// ginContextParam 返回函数的 *gin.Context 参数名
+func ginContextParam(funcType *ast.FuncType) *ast.Ident {
+	if funcType.Params == nil {
+		return nil
+	}
+	for _, field := range funcType.Params.List {
+		star, ok := field.Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := star.X.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Context" {
+			continue
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "gin" && len(field.Names) == 1 {
+			return field.Names[0]
+		}
+	}
+	return nil
+}
+
+// renameParamFix 重命名参数及其在函数体中的引用，函数体中已有同名标识符时不提供修复
+func renameParamFix(fset *token.FileSet, param *ast.Ident, body *ast.BlockStmt, newName string) *Fix {
+	if param.Obj == nil {
+		return nil
+	}
+	edits := []TextEdit{replaceNode(fset, param, newName)}
+	conflict := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if ident.Name == newName {
+			conflict = true
+		}
+		if ident.Obj == param.Obj {
+			edits = append(edits, replaceNode(fset, ident, newName))
+		}
+		return !conflict
+	})
+	if conflict {
+		return nil
+	}
+	return &Fix{Message: fmt.Sprintf("将 %s 重命名为 %s", param.Name, newName), Edits: edits}
+}
+
+// isGinErrorResponse 判断语句是否为状态码 >= 400 的响应，如 c.JSON(http.StatusBadRequest, ...)
+func isGinErrorResponse(stmt ast.Stmt, ctxName string) bool {
+	exprStmt, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !ginResponseMethods[sel.Sel.Name] {
+		return false
+	}
+	if recv, ok := sel.X.(*ast.Ident); !ok || recv.Name != ctxName {
+		return false
+	}
+
+	switch status := call.Args[0].(type) {
+	case *ast.BasicLit:
+		code, err := strconv.Atoi(status.Value)
+		return err == nil && code >= 400
+	case *ast.SelectorExpr:
+		pkg, ok := status.X.(*ast.Ident)
+		return ok && pkg.Name == "http" && strings.HasPrefix(status.Sel.Name, "Status") && !nonErrorStatuses[status.Sel.Name]
+	}
+	return false
+}
+
+// rowsQueryMethods 返回需要关闭的结果集的方法
+var rowsQueryMethods = map[string]bool{
+	"Query":         true,
+	"QueryContext":  true,
+	"Queryx":        true,
+	"QueryxContext": true,
+	"Rows":          true,
+}
+
+// 检查查询返回的 rows 是否通过 defer 关闭
+func checkRowsClose(file *ast.File, fset *token.FileSet) []Issue {
+	var issues []Issue
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		var body *ast.BlockStmt
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			body = fn.Body
+		case *ast.FuncLit:
+			body = fn.Body
+		default:
+			return true
+		}
+		if body == nil {
+			return true
+		}
+
+		ast.Inspect(body, func(n ast.Node) bool {
+			if _, ok := n.(*ast.FuncLit); ok {
+				// 闭包由外层 Inspect 单独检查
+				return false
+			}
+			block, ok := n.(*ast.BlockStmt)
+			if !ok {
+				return true
+			}
+			for i, stmt := range block.List {
+				rows := rowsAssignment(stmt)
+				if rows == nil || rowsClosedOrReturned(body, rows.Name) {
+					continue
+				}
+
+				// 在错误检查之后插入 defer rows.Close()
+				insertAfter := ast.Node(stmt)
+				if i+1 < len(block.List) {
+					if ifStmt, ok := block.List[i+1].(*ast.IfStmt); ok && ifStmt.Init == nil {
+						insertAfter = ifStmt
+					}
+				}
+
+				pos := fset.Position(stmt.Pos())
+				issues = append(issues, Issue{
+					Line:     pos.Line,
+					Column:   pos.Column,
+					Message:  fmt.Sprintf("%s 未关闭，应该在检查错误后 defer %s.Close()", rows.Name, rows.Name),
+					Severity: "error",
+					Fix: &Fix{
+						Message: fmt.Sprintf("添加 defer %s.Close()", rows.Name),
+						Edits:   []TextEdit{insertAt(fset, insertAfter.End(), fmt.Sprintf("\ndefer %s.Close()", rows.Name))},
+					},
+				})
+			}
+			return true
+		})
+		return true
+	})
+
+	return issues
+}
+
+// rowsAssignment 匹配 rows, err := x.Query(...) 形式的语句，返回 rows 变量
+func rowsAssignment(stmt ast.Stmt) *ast.Ident {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return nil
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !rowsQueryMethods[sel.Sel.Name] {
+		return nil
+	}
+	ident, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok || ident.Name == "_" {
+		return nil
+	}
+	return ident
+}
+
+// rowsClosedOrReturned 判断函数体中是否有 defer rows.Close()，或 rows 被返回给调用方
+func rowsClosedOrReturned(body *ast.BlockStmt, name string) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.DeferStmt:
+			ast.Inspect(stmt, func(n ast.Node) bool {
+				if isMethodCall(n, name, "Close") {
+					found = true
+				}
+				return !found
+			})
+		case *ast.ReturnStmt:
+			for _, result := range stmt.Results {
+				if ident, ok := result.(*ast.Ident); ok && ident.Name == name {
+					found = true
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+func isMethodCall(n ast.Node, recv, method string) bool {
+	call, ok := n.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != method {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == recv
+}
+
+// secretNamePattern 敏感字段名
+var secretNamePattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|private_?key|credential)s?$`)
+
+// logMethods 日志输出方法
+var logMethods = map[string]bool{
+	"Debug": true, "Debugf": true, "Debugw": true, "Debugln": true,
+	"Info": true, "Infof": true, "Infow": true, "Infoln": true,
+	"Warn": true, "Warnf": true, "Warnw": true, "Warnln": true, "Warning": true, "Warningf": true,
+	"Error": true, "Errorf": true, "Errorw": true, "Errorln": true,
+	"Fatal": true, "Fatalf": true, "Fatalw": true, "Fatalln": true,
+	"Panic": true, "Panicf": true, "Panicw": true,
+	"Print": true, "Printf": true, "Println": true,
+	"WithField": true, "WithFields": true, "With": true,
+}
+
+// fieldConstructors 以字段名为第一个参数的字段构造函数，如 logger.String("password", v)
+var fieldConstructors = map[string]bool{
+	"String": true, "Any": true, "Reflect": true, "Stringer": true, "ByteString": true, "Binary": true, "Strings": true,
+}
+
+// 检查日志中是否输出了密码、令牌等敏感信息
+func checkLogSecrets(file *ast.File, fset *token.FileSet) []Issue {
+	var issues []Issue
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !logMethods[sel.Sel.Name] {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok && (pkg.Name == "errors" || pkg.Name == "fmt" && sel.Sel.Name == "Errorf") {
+			return true
+		}
+
+		var name string
+		if sel.Sel.Name == "WithField" {
+			// WithField 的第一个参数是字段名
+			name = stringLiteralSecret(call.Args[0])
+		}
+		for _, arg := range call.Args {
+			if name != "" {
+				break
+			}
+			name = secretInExpr(arg)
+		}
+		if name == "" {
+			return true
+		}
+
+		pos := fset.Position(call.Pos())
+		issues = append(issues, Issue{
+			Line:     pos.Line,
+			Column:   pos.Column,
+			Message:  fmt.Sprintf("日志中不应该输出敏感信息 '%s'", name),
+			Severity: "warning",
+		})
+		return true
+	})
+
+	return issues
+}
+
+// secretInExpr 在日志参数中查找敏感的变量、字段或字段名
+func secretInExpr(expr ast.Expr) string {
+	var name string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if name != "" {
+			return false
+		}
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.Ident:
+			if secretNamePattern.MatchString(e.Name) {
+				name = e.Name
+			}
+		case *ast.SelectorExpr:
+			if secretNamePattern.MatchString(e.Sel.Name) {
+				name = e.Sel.Name
+			}
+			// 包名或接收者不是敏感信息
+			return false
+		case *ast.KeyValueExpr:
+			// logrus.Fields{"password": v}
+			if key := stringLiteralSecret(e.Key); key != "" {
+				name = key
+				return false
+			}
+			// 只检查值，结构体字段名和 map 键的标识符不是输出内容
+			name = secretInExpr(e.Value)
+			return false
+		case *ast.CallExpr:
+			if ident, ok := e.Fun.(*ast.Ident); ok && (ident.Name == "len" || ident.Name == "cap") {
+				return false
+			}
+			if sel, ok := e.Fun.(*ast.SelectorExpr); ok && fieldConstructors[sel.Sel.Name] && len(e.Args) > 0 {
+				if key := stringLiteralSecret(e.Args[0]); key != "" {
+					name = key
+					return false
+				}
+			}
+			// 只检查参数，调用的函数名不是输出内容
+			for _, arg := range e.Args {
+				if name == "" {
+					name = secretInExpr(arg)
+				}
+			}
+			return false
+		}
+		return true
+	})
+	return name
+}
+
+// stringLiteralSecret 字符串字面量是敏感字段名时返回该字段名
+func stringLiteralSecret(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil || !secretNamePattern.MatchString(value) {
+		return ""
+	}
+	return value
+}
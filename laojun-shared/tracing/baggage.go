@@ -0,0 +1,196 @@
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// W3C baggage limits.
+const (
+	maxBaggageMembers = 180
+	maxBaggageBytes   = 8192
+)
+
+// Baggage is the set of key/value pairs propagated with a trace across
+// process boundaries.
+type Baggage map[string]string
+
+// ContextWithBaggage returns ctx carrying a copy of baggage.
+func ContextWithBaggage(ctx context.Context, baggage Baggage) context.Context {
+	return context.WithValue(ctx, baggageKey{}, baggage.clone())
+}
+
+// ContextWithBaggageItem returns ctx with one baggage item added.
+func ContextWithBaggageItem(ctx context.Context, key, value string) context.Context {
+	baggage := BaggageFromContext(ctx)
+	if baggage == nil {
+		baggage = make(Baggage)
+	}
+	baggage[key] = value
+	return context.WithValue(ctx, baggageKey{}, baggage)
+}
+
+// BaggageFromContext returns a copy of the baggage in ctx, or nil.
+func BaggageFromContext(ctx context.Context) Baggage {
+	if baggage, ok := ctx.Value(baggageKey{}).(Baggage); ok {
+		return baggage.clone()
+	}
+	return nil
+}
+
+func (b Baggage) clone() Baggage {
+	if b == nil {
+		return nil
+	}
+	out := make(Baggage, len(b))
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
+
+// mergeBaggage adds items to the baggage in ctx.
+func mergeBaggage(ctx context.Context, items Baggage) context.Context {
+	if len(items) == 0 {
+		return ctx
+	}
+	baggage := BaggageFromContext(ctx)
+	if baggage == nil {
+		baggage = make(Baggage, len(items))
+	}
+	for k, v := range items {
+		baggage[k] = v
+	}
+	return context.WithValue(ctx, baggageKey{}, baggage)
+}
+
+// BaggagePropagator propagates baggage in the W3C "baggage" header.
+type BaggagePropagator struct{}
+
+const baggageHeader = "baggage"
+
+// Inject writes the baggage header.
+func (BaggagePropagator) Inject(ctx context.Context, carrier TextMapCarrier) {
+	baggage := injectionBaggage(ctx)
+	if len(baggage) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(baggage))
+	for k := range baggage {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	members := 0
+	for _, key := range keys {
+		if !validBaggageKey(key) {
+			continue
+		}
+		member := key + "=" + escapeBaggageValue(baggage[key])
+		if members >= maxBaggageMembers || b.Len()+len(member)+1 > maxBaggageBytes {
+			break
+		}
+		if members > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(member)
+		members++
+	}
+	if members > 0 {
+		carrier.Set(baggageHeader, b.String())
+	}
+}
+
+// Extract parses the baggage header. Member properties are ignored and
+// malformed members are skipped.
+func (BaggagePropagator) Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
+	header := carrier.Get(baggageHeader)
+	if header == "" || len(header) > maxBaggageBytes {
+		return ctx
+	}
+
+	items := make(Baggage)
+	for _, member := range strings.Split(header, ",") {
+		if idx := strings.IndexByte(member, ';'); idx >= 0 {
+			member = member[:idx]
+		}
+		idx := strings.IndexByte(member, '=')
+		if idx <= 0 {
+			continue
+		}
+		key := strings.TrimSpace(member[:idx])
+		value, err := url.PathUnescape(strings.TrimSpace(member[idx+1:]))
+		if err != nil || !validBaggageKey(key) {
+			continue
+		}
+		items[key] = value
+		if len(items) >= maxBaggageMembers {
+			break
+		}
+	}
+	return mergeBaggage(ctx, items)
+}
+
+// Fields returns the baggage header name.
+func (BaggagePropagator) Fields() []string {
+	return []string{baggageHeader}
+}
+
+// validBaggageKey checks the key is an RFC 7230 token.
+func validBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= 0x20 || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeBaggageValue percent-encodes bytes not allowed in baggage-octet.
+func escapeBaggageValue(value string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c > 0x20 && c < 0x7f && c != ',' && c != ';' && c != '\\' && c != '"' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+// injectPrefixedBaggage writes baggage as one header per item, as used by
+// the Jaeger (uberctx-) and OpenTracing (ot-baggage-) formats.
+func injectPrefixedBaggage(ctx context.Context, carrier TextMapCarrier, prefix string) {
+	for key, value := range injectionBaggage(ctx) {
+		carrier.Set(prefix+key, url.PathEscape(value))
+	}
+}
+
+// extractPrefixedBaggage reads per-item baggage headers with prefix.
+func extractPrefixedBaggage(ctx context.Context, carrier TextMapCarrier, prefix string) context.Context {
+	items := make(Baggage)
+	for _, key := range carrier.Keys() {
+		if len(key) <= len(prefix) || !strings.EqualFold(key[:len(prefix)], prefix) {
+			continue
+		}
+		value, err := url.PathUnescape(carrier.Get(key))
+		if err != nil {
+			continue
+		}
+		items[strings.ToLower(key[len(prefix):])] = value
+	}
+	return mergeBaggage(ctx, items)
+}
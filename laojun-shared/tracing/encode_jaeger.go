@@ -0,0 +1,204 @@
+package tracing
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// Thrift binary protocol type ids.
+const (
+	thriftStop   = 0
+	thriftBool   = 2
+	thriftDouble = 4
+	thriftI32    = 8
+	thriftI64    = 10
+	thriftString = 11
+	thriftStruct = 12
+	thriftList   = 15
+)
+
+// Jaeger TagType values from jaeger-idl/thrift/jaeger.thrift.
+const (
+	jaegerTagString = 0
+	jaegerTagDouble = 1
+	jaegerTagBool   = 2
+	jaegerTagLong   = 3
+)
+
+// jaegerTag is a key/value pair in jaeger.thrift form.
+type jaegerTag struct {
+	key   string
+	value interface{}
+}
+
+// thriftWriter writes the Thrift binary protocol.
+type thriftWriter struct {
+	buf []byte
+}
+
+func (w *thriftWriter) fieldHeader(typeID byte, id int16) {
+	w.buf = append(w.buf, typeID)
+	w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(id))
+}
+
+func (w *thriftWriter) stop() {
+	w.buf = append(w.buf, thriftStop)
+}
+
+func (w *thriftWriter) listHeader(elemType byte, size int) {
+	w.buf = append(w.buf, elemType)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(size))
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(thriftI32, id)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(v))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(thriftI64, id)
+	w.buf = binary.BigEndian.AppendUint64(w.buf, uint64(v))
+}
+
+func (w *thriftWriter) double(id int16, v float64) {
+	w.fieldHeader(thriftDouble, id)
+	w.buf = binary.BigEndian.AppendUint64(w.buf, math.Float64bits(v))
+}
+
+func (w *thriftWriter) boolean(id int16, v bool) {
+	w.fieldHeader(thriftBool, id)
+	if v {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.fieldHeader(thriftString, id)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// tags writes a list<Tag> field.
+func (w *thriftWriter) tags(id int16, tags []jaegerTag) {
+	w.fieldHeader(thriftList, id)
+	w.listHeader(thriftStruct, len(tags))
+	for _, tag := range tags {
+		w.str(1, tag.key)
+		switch v := normalizeValue(tag.value).(type) {
+		case bool:
+			w.i32(2, jaegerTagBool)
+			w.boolean(5, v)
+		case int64:
+			w.i32(2, jaegerTagLong)
+			w.i64(6, v)
+		case float64:
+			w.i32(2, jaegerTagDouble)
+			w.double(4, v)
+		case string:
+			w.i32(2, jaegerTagString)
+			w.str(3, v)
+		}
+		w.stop()
+	}
+}
+
+// encodeJaegerThrift encodes spans as a binary Thrift jaeger.Batch, the
+// payload accepted by the collector's /api/traces endpoint.
+func encodeJaegerThrift(resource Resource, spans []*spanData) []byte {
+	w := &thriftWriter{}
+
+	// 1: Process process
+	w.fieldHeader(thriftStruct, 1)
+	w.str(1, resource.ServiceName)
+	var processTags []jaegerTag
+	for _, key := range sortedStringKeys(resource.Attributes) {
+		if key == "service.name" {
+			continue
+		}
+		processTags = append(processTags, jaegerTag{key: key, value: resource.Attributes[key]})
+	}
+	if len(processTags) > 0 {
+		w.tags(2, processTags)
+	}
+	w.stop()
+
+	// 2: list<Span> spans
+	w.fieldHeader(thriftList, 2)
+	w.listHeader(thriftStruct, len(spans))
+	for _, span := range spans {
+		traceID := decodeID(span.traceID, 16)
+		w.i64(1, int64(binary.BigEndian.Uint64(traceID[8:])))
+		w.i64(2, int64(binary.BigEndian.Uint64(traceID[:8])))
+		w.i64(3, int64(binary.BigEndian.Uint64(decodeID(span.spanID, 8))))
+		w.i64(4, int64(binary.BigEndian.Uint64(decodeID(span.parentSpanID, 8))))
+		w.str(5, span.operationName)
+		w.i32(7, 1) // sampled
+		w.i64(8, span.startTime.UnixMicro())
+		w.i64(9, span.endTime.Sub(span.startTime).Microseconds())
+
+		tags := jaegerSpanTags(span)
+		if len(tags) > 0 {
+			w.tags(10, tags)
+		}
+
+		logs := jaegerLogs(span)
+		if len(logs) > 0 {
+			w.fieldHeader(thriftList, 11)
+			w.listHeader(thriftStruct, len(logs))
+			for _, log := range logs {
+				w.i64(1, log.timestamp)
+				w.tags(2, log.fields)
+				w.stop()
+			}
+		}
+		w.stop()
+	}
+
+	w.stop()
+	return w.buf
+}
+
+func jaegerSpanTags(span *spanData) []jaegerTag {
+	tags := make([]jaegerTag, 0, len(span.tags)+2)
+	for _, key := range sortedKeys(span.tags) {
+		tags = append(tags, jaegerTag{key: key, value: span.tags[key]})
+	}
+	if span.kind != "" {
+		tags = append(tags, jaegerTag{key: spanKindTag, value: span.kind})
+	}
+	if span.status == SpanStatusError {
+		if _, exists := span.tags["error"]; !exists {
+			tags = append(tags, jaegerTag{key: "error", value: true})
+		}
+	}
+	return tags
+}
+
+type jaegerLog struct {
+	timestamp int64
+	fields    []jaegerTag
+}
+
+// jaegerLogs converts events and log entries into Jaeger logs ordered by time.
+func jaegerLogs(span *spanData) []jaegerLog {
+	logs := make([]jaegerLog, 0, len(span.events)+len(span.logs))
+	for _, event := range span.events {
+		fields := []jaegerTag{{key: "event", value: event.Name}}
+		for _, attr := range event.Attributes {
+			fields = append(fields, jaegerTag{key: attr.Key, value: attr.Value})
+		}
+		logs = append(logs, jaegerLog{timestamp: event.Timestamp.UnixMicro(), fields: fields})
+	}
+	for _, entry := range span.logs {
+		fields := make([]jaegerTag, len(entry.Fields))
+		for i, field := range entry.Fields {
+			fields[i] = jaegerTag{key: field.Key, value: field.Value}
+		}
+		logs = append(logs, jaegerLog{timestamp: entry.Timestamp.UnixMicro(), fields: fields})
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].timestamp < logs[j].timestamp })
+	return logs
+}
@@ -0,0 +1,325 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// instrumentationScope is the OTLP instrumentation scope name of this package.
+const instrumentationScope = "github.com/codetaoist/laojun-shared/tracing"
+
+// OTLP protocols supported by the OTLP exporter.
+const (
+	OTLPProtocolProtobuf = "http/protobuf"
+	OTLPProtocolJSON     = "http/json"
+)
+
+// OTLP span kinds and status codes as defined in opentelemetry/proto/trace/v1.
+const (
+	otlpKindInternal = 1
+	otlpKindServer   = 2
+	otlpKindClient   = 3
+	otlpKindProducer = 4
+	otlpKindConsumer = 5
+
+	otlpStatusUnset = 0
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+)
+
+func otlpKind(kind string) int {
+	switch kind {
+	case "server":
+		return otlpKindServer
+	case "client":
+		return otlpKindClient
+	case "producer":
+		return otlpKindProducer
+	case "consumer":
+		return otlpKindConsumer
+	default:
+		return otlpKindInternal
+	}
+}
+
+func otlpStatus(status SpanStatus) int {
+	switch status {
+	case SpanStatusOK:
+		return otlpStatusOK
+	case SpanStatusError:
+		return otlpStatusError
+	default:
+		return otlpStatusUnset
+	}
+}
+
+// otlpEvents merges span events and logs; logs become events named "log".
+func otlpEvents(span *spanData) []Event {
+	events := append([]Event(nil), span.events...)
+	for _, entry := range span.logs {
+		attrs := make([]EventAttribute, len(entry.Fields))
+		for i, field := range entry.Fields {
+			attrs[i] = EventAttribute{Key: field.Key, Value: field.Value}
+		}
+		events = append(events, Event{Name: "log", Timestamp: entry.Timestamp, Attributes: attrs})
+	}
+	return events
+}
+
+// encodeOTLPProtobuf encodes an ExportTraceServiceRequest in protobuf wire format.
+func encodeOTLPProtobuf(resource Resource, spans []*spanData) []byte {
+	var resourceMsg []byte
+	for _, key := range sortedStringKeys(resource.Attributes) {
+		resourceMsg = appendMessage(resourceMsg, 1, pbKeyValue(key, resource.Attributes[key]))
+	}
+
+	var scopeMsg []byte
+	scopeMsg = protowire.AppendTag(scopeMsg, 1, protowire.BytesType)
+	scopeMsg = protowire.AppendString(scopeMsg, instrumentationScope)
+
+	var scopeSpans []byte
+	scopeSpans = appendMessage(scopeSpans, 1, scopeMsg)
+	for _, span := range spans {
+		scopeSpans = appendMessage(scopeSpans, 2, pbSpan(span))
+	}
+
+	var resourceSpans []byte
+	resourceSpans = appendMessage(resourceSpans, 1, resourceMsg)
+	resourceSpans = appendMessage(resourceSpans, 2, scopeSpans)
+
+	return appendMessage(nil, 1, resourceSpans)
+}
+
+func pbSpan(span *spanData) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, decodeID(span.traceID, 16))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, decodeID(span.spanID, 8))
+	if span.parentSpanID != "" {
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendBytes(b, decodeID(span.parentSpanID, 8))
+	}
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	b = protowire.AppendString(b, span.operationName)
+	b = protowire.AppendTag(b, 6, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(otlpKind(span.kind)))
+	b = protowire.AppendTag(b, 7, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(span.startTime.UnixNano()))
+	b = protowire.AppendTag(b, 8, protowire.Fixed64Type)
+	b = protowire.AppendFixed64(b, uint64(span.endTime.UnixNano()))
+	for _, key := range sortedKeys(span.tags) {
+		b = appendMessage(b, 9, pbKeyValue(key, span.tags[key]))
+	}
+	for _, event := range otlpEvents(span) {
+		var eventMsg []byte
+		eventMsg = protowire.AppendTag(eventMsg, 1, protowire.Fixed64Type)
+		eventMsg = protowire.AppendFixed64(eventMsg, uint64(event.Timestamp.UnixNano()))
+		eventMsg = protowire.AppendTag(eventMsg, 2, protowire.BytesType)
+		eventMsg = protowire.AppendString(eventMsg, event.Name)
+		for _, attr := range event.Attributes {
+			eventMsg = appendMessage(eventMsg, 3, pbKeyValue(attr.Key, attr.Value))
+		}
+		b = appendMessage(b, 11, eventMsg)
+	}
+	if code := otlpStatus(span.status); code != otlpStatusUnset {
+		var statusMsg []byte
+		if span.statusDesc != "" {
+			statusMsg = protowire.AppendTag(statusMsg, 2, protowire.BytesType)
+			statusMsg = protowire.AppendString(statusMsg, span.statusDesc)
+		}
+		statusMsg = protowire.AppendTag(statusMsg, 3, protowire.VarintType)
+		statusMsg = protowire.AppendVarint(statusMsg, uint64(code))
+		b = appendMessage(b, 15, statusMsg)
+	}
+	return b
+}
+
+func pbKeyValue(key string, value interface{}) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, key)
+	return appendMessage(b, 2, pbAnyValue(value))
+}
+
+func pbAnyValue(value interface{}) []byte {
+	var b []byte
+	switch v := normalizeValue(value).(type) {
+	case bool:
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeBool(v))
+	case int64:
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v))
+	case float64:
+		b = protowire.AppendTag(b, 4, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v))
+	case string:
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, v)
+	}
+	return b
+}
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+// normalizeValue maps attribute values onto the bool/int64/float64/string
+// types every export format can represent.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bool, int64, float64, string:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return float64(v)
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// OTLP/JSON payload types. IDs are hex encoded, enums are numbers and
+// 64-bit integers are strings, per the OTLP JSON mapping.
+type (
+	otlpJSONRequest struct {
+		ResourceSpans []otlpJSONResourceSpans `json:"resourceSpans"`
+	}
+	otlpJSONResourceSpans struct {
+		Resource   otlpJSONResource     `json:"resource"`
+		ScopeSpans []otlpJSONScopeSpans `json:"scopeSpans"`
+	}
+	otlpJSONResource struct {
+		Attributes []otlpJSONKeyValue `json:"attributes"`
+	}
+	otlpJSONScopeSpans struct {
+		Scope otlpJSONScope  `json:"scope"`
+		Spans []otlpJSONSpan `json:"spans"`
+	}
+	otlpJSONScope struct {
+		Name string `json:"name"`
+	}
+	otlpJSONSpan struct {
+		TraceID           string             `json:"traceId"`
+		SpanID            string             `json:"spanId"`
+		ParentSpanID      string             `json:"parentSpanId,omitempty"`
+		Name              string             `json:"name"`
+		Kind              int                `json:"kind"`
+		StartTimeUnixNano string             `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string             `json:"endTimeUnixNano"`
+		Attributes        []otlpJSONKeyValue `json:"attributes,omitempty"`
+		Events            []otlpJSONEvent    `json:"events,omitempty"`
+		Status            *otlpJSONStatus    `json:"status,omitempty"`
+	}
+	otlpJSONEvent struct {
+		TimeUnixNano string             `json:"timeUnixNano"`
+		Name         string             `json:"name"`
+		Attributes   []otlpJSONKeyValue `json:"attributes,omitempty"`
+	}
+	otlpJSONStatus struct {
+		Message string `json:"message,omitempty"`
+		Code    int    `json:"code"`
+	}
+	otlpJSONKeyValue struct {
+		Key   string           `json:"key"`
+		Value otlpJSONAnyValue `json:"value"`
+	}
+	otlpJSONAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// encodeOTLPJSON encodes an ExportTraceServiceRequest using the OTLP JSON mapping.
+func encodeOTLPJSON(resource Resource, spans []*spanData) ([]byte, error) {
+	resourceAttrs := make([]otlpJSONKeyValue, 0, len(resource.Attributes))
+	for _, key := range sortedStringKeys(resource.Attributes) {
+		resourceAttrs = append(resourceAttrs, jsonKeyValue(key, resource.Attributes[key]))
+	}
+
+	jsonSpans := make([]otlpJSONSpan, len(spans))
+	for i, span := range spans {
+		js := otlpJSONSpan{
+			TraceID:           paddedID(span.traceID, 16),
+			SpanID:            paddedID(span.spanID, 8),
+			Name:              span.operationName,
+			Kind:              otlpKind(span.kind),
+			StartTimeUnixNano: strconv.FormatInt(span.startTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.endTime.UnixNano(), 10),
+		}
+		if span.parentSpanID != "" {
+			js.ParentSpanID = paddedID(span.parentSpanID, 8)
+		}
+		for _, key := range sortedKeys(span.tags) {
+			js.Attributes = append(js.Attributes, jsonKeyValue(key, span.tags[key]))
+		}
+		for _, event := range otlpEvents(span) {
+			je := otlpJSONEvent{
+				TimeUnixNano: strconv.FormatInt(event.Timestamp.UnixNano(), 10),
+				Name:         event.Name,
+			}
+			for _, attr := range event.Attributes {
+				je.Attributes = append(je.Attributes, jsonKeyValue(attr.Key, attr.Value))
+			}
+			js.Events = append(js.Events, je)
+		}
+		if code := otlpStatus(span.status); code != otlpStatusUnset {
+			js.Status = &otlpJSONStatus{Message: span.statusDesc, Code: code}
+		}
+		jsonSpans[i] = js
+	}
+
+	return json.Marshal(otlpJSONRequest{
+		ResourceSpans: []otlpJSONResourceSpans{{
+			Resource: otlpJSONResource{Attributes: resourceAttrs},
+			ScopeSpans: []otlpJSONScopeSpans{{
+				Scope: otlpJSONScope{Name: instrumentationScope},
+				Spans: jsonSpans,
+			}},
+		}},
+	})
+}
+
+func jsonKeyValue(key string, value interface{}) otlpJSONKeyValue {
+	var any otlpJSONAnyValue
+	switch v := normalizeValue(value).(type) {
+	case bool:
+		any.BoolValue = &v
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		any.IntValue = &s
+	case float64:
+		any.DoubleValue = &v
+	case string:
+		any.StringValue = &v
+	}
+	return otlpJSONKeyValue{Key: key, Value: any}
+}
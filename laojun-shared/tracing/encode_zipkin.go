@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Zipkin v2 JSON payload types, see zipkin-api/zipkin2-api.yaml.
+type (
+	zipkinSpan struct {
+		TraceID       string             `json:"traceId"`
+		ID            string             `json:"id"`
+		ParentID      string             `json:"parentId,omitempty"`
+		Name          string             `json:"name"`
+		Kind          string             `json:"kind,omitempty"`
+		Timestamp     int64              `json:"timestamp"`
+		Duration      int64              `json:"duration"`
+		LocalEndpoint zipkinEndpoint     `json:"localEndpoint"`
+		Annotations   []zipkinAnnotation `json:"annotations,omitempty"`
+		Tags          map[string]string  `json:"tags,omitempty"`
+	}
+	zipkinEndpoint struct {
+		ServiceName string `json:"serviceName"`
+	}
+	zipkinAnnotation struct {
+		Timestamp int64  `json:"timestamp"`
+		Value     string `json:"value"`
+	}
+)
+
+// encodeZipkin encodes spans as a Zipkin v2 JSON span list.
+func encodeZipkin(resource Resource, spans []*spanData) ([]byte, error) {
+	out := make([]zipkinSpan, len(spans))
+	for i, span := range spans {
+		zs := zipkinSpan{
+			TraceID:       paddedID(span.traceID, 8),
+			ID:            paddedID(span.spanID, 8),
+			Name:          span.operationName,
+			Kind:          zipkinKind(span.kind),
+			Timestamp:     span.startTime.UnixMicro(),
+			Duration:      span.endTime.Sub(span.startTime).Microseconds(),
+			LocalEndpoint: zipkinEndpoint{ServiceName: resource.ServiceName},
+		}
+		if len(span.traceID) > 16 {
+			zs.TraceID = paddedID(span.traceID, 16)
+		}
+		if span.parentSpanID != "" {
+			zs.ParentID = paddedID(span.parentSpanID, 8)
+		}
+		// Zipkin rejects zero durations on finished spans
+		if zs.Duration < 1 {
+			zs.Duration = 1
+		}
+
+		tags := make(map[string]string, len(span.tags)+1)
+		for k, v := range span.tags {
+			tags[k] = fmt.Sprint(normalizeValue(v))
+		}
+		if span.status == SpanStatusError {
+			tags["error"] = span.statusDesc
+			if tags["error"] == "" {
+				tags["error"] = "true"
+			}
+		}
+		if len(tags) > 0 {
+			zs.Tags = tags
+		}
+
+		for _, event := range span.events {
+			zs.Annotations = append(zs.Annotations, zipkinAnnotation{
+				Timestamp: event.Timestamp.UnixMicro(),
+				Value:     event.Name,
+			})
+		}
+		for _, entry := range span.logs {
+			zs.Annotations = append(zs.Annotations, zipkinAnnotation{
+				Timestamp: entry.Timestamp.UnixMicro(),
+				Value:     logMessage(entry.Fields),
+			})
+		}
+		out[i] = zs
+	}
+	return json.Marshal(out)
+}
+
+func zipkinKind(kind string) string {
+	switch kind {
+	case "server", "client", "producer", "consumer":
+		return strings.ToUpper(kind)
+	default:
+		return ""
+	}
+}
@@ -25,6 +25,8 @@ var (
 	ErrExportTimeout      = errors.New("export operation timeout")
 	ErrInvalidExportFormat = errors.New("invalid export format")
 	ErrExporterNotConfigured = errors.New("exporter not configured")
+	ErrExporterShutdown    = errors.New("exporter already shut down")
+	ErrExportSpooled       = errors.New("export spooled to fallback file")
 	
 	// Sampling errors
 	ErrInvalidSamplingRate = errors.New("invalid sampling rate")
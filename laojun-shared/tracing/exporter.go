@@ -0,0 +1,441 @@
+package tracing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Exporter interface for exporting traces
+type Exporter interface {
+	Export(ctx context.Context, spans []*spanImpl) error
+	Shutdown(ctx context.Context) error
+}
+
+// RetryPolicy controls how failed export requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns the default retry policy.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+// HTTPExporterOptions configures an HTTP exporter.
+type HTTPExporterOptions struct {
+	Endpoint string
+	Headers  map[string]string
+	// Timeout bounds a single request attempt.
+	Timeout time.Duration
+	// Compression is "gzip" or "none".
+	Compression string
+	Username    string
+	Password    string
+	Retry       RetryPolicy
+	// Fallback spools requests that could not be delivered after all retries.
+	Fallback *FileFallback
+	Client   *http.Client
+}
+
+// ExportError describes a failed export request.
+type ExportError struct {
+	Exporter   string
+	StatusCode int
+	Retryable  bool
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *ExportError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s export failed with status %d: %v", e.Exporter, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s export failed: %v", e.Exporter, e.Err)
+}
+
+func (e *ExportError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports ExportError as ErrExportFailed.
+func (e *ExportError) Is(target error) bool {
+	return target == ErrExportFailed
+}
+
+// encodeFunc encodes a batch of span snapshots into a request body.
+type encodeFunc func(resource Resource, spans []*spanData) ([]byte, error)
+
+// HTTPExporter encodes spans and posts them to a collector, retrying
+// transient failures with exponential backoff and spooling undeliverable
+// batches to the configured file fallback.
+type HTTPExporter struct {
+	name        string
+	contentType string
+	encode      encodeFunc
+	resource    Resource
+	options     HTTPExporterOptions
+	client      *http.Client
+
+	mu       sync.Mutex
+	shutdown bool
+}
+
+// NewOTLPExporter creates an OTLP/HTTP exporter. protocol is
+// OTLPProtocolProtobuf (default) or OTLPProtocolJSON.
+func NewOTLPExporter(resource Resource, protocol string, opts HTTPExporterOptions) (*HTTPExporter, error) {
+	switch protocol {
+	case "", OTLPProtocolProtobuf:
+		return newHTTPExporter("otlp", "application/x-protobuf", func(r Resource, spans []*spanData) ([]byte, error) {
+			return encodeOTLPProtobuf(r, spans), nil
+		}, resource, opts)
+	case OTLPProtocolJSON:
+		return newHTTPExporter("otlp", "application/json", encodeOTLPJSON, resource, opts)
+	default:
+		return nil, fmt.Errorf("unsupported otlp protocol: %s", protocol)
+	}
+}
+
+// NewZipkinExporter creates a Zipkin v2 JSON exporter.
+func NewZipkinExporter(resource Resource, opts HTTPExporterOptions) (*HTTPExporter, error) {
+	return newHTTPExporter("zipkin", "application/json", encodeZipkin, resource, opts)
+}
+
+// NewJaegerExporter creates a Jaeger Thrift-over-HTTP exporter for the
+// collector's /api/traces endpoint.
+func NewJaegerExporter(resource Resource, opts HTTPExporterOptions) (*HTTPExporter, error) {
+	return newHTTPExporter("jaeger", "application/x-thrift", func(r Resource, spans []*spanData) ([]byte, error) {
+		return encodeJaegerThrift(r, spans), nil
+	}, resource, opts)
+}
+
+// NewJSONExporter creates an exporter posting spans in the tracer's own
+// JSON export format.
+func NewJSONExporter(resource Resource, opts HTTPExporterOptions) (*HTTPExporter, error) {
+	return newHTTPExporter("json", "application/json", encodeJSON, resource, opts)
+}
+
+func newHTTPExporter(name, contentType string, encode encodeFunc, resource Resource, opts HTTPExporterOptions) (*HTTPExporter, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("%s exporter: %w", name, ErrExporterNotConfigured)
+	}
+	if opts.Compression != "" && opts.Compression != "gzip" && opts.Compression != "none" {
+		return nil, fmt.Errorf("unsupported compression: %s", opts.Compression)
+	}
+	if opts.Retry.MaxAttempts <= 0 {
+		opts.Retry = DefaultRetryPolicy()
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: opts.Timeout}
+	}
+
+	return &HTTPExporter{
+		name:        name,
+		contentType: contentType,
+		encode:      encode,
+		resource:    resource,
+		options:     opts,
+		client:      client,
+	}, nil
+}
+
+// Export encodes and sends a batch of spans. Undeliverable batches are
+// written to the file fallback and reported with ErrExportSpooled.
+func (e *HTTPExporter) Export(ctx context.Context, spans []*spanImpl) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	shutdown := e.shutdown
+	e.mu.Unlock()
+	if shutdown {
+		return ErrExporterShutdown
+	}
+
+	body, err := e.encode(e.resource, snapshotSpans(spans))
+	if err != nil {
+		return &ExportError{Exporter: e.name, Err: fmt.Errorf("encode payload: %w", err)}
+	}
+
+	record := &fallbackRecord{ContentType: e.contentType, SpanCount: len(spans), Payload: body}
+	if e.options.Compression == "gzip" {
+		if record.Payload, err = gzipBytes(body); err != nil {
+			return &ExportError{Exporter: e.name, Err: err}
+		}
+		record.ContentEncoding = "gzip"
+	}
+
+	err = e.sendWithRetry(ctx, record)
+	if err == nil {
+		// the collector is reachable again, deliver what was spooled while it was down
+		if e.options.Fallback != nil && e.options.Fallback.Pending() {
+			e.options.Fallback.Replay(func(spooled *fallbackRecord) error {
+				return e.send(ctx, spooled)
+			})
+		}
+		return nil
+	}
+
+	var exportErr *ExportError
+	if e.options.Fallback == nil || !errors.As(err, &exportErr) || !exportErr.Retryable {
+		return err
+	}
+	if ferr := e.options.Fallback.Write(record); ferr != nil {
+		return fmt.Errorf("%w (fallback: %v)", err, ferr)
+	}
+	return fmt.Errorf("%w: %v", ErrExportSpooled, err)
+}
+
+// Shutdown stops the exporter. Spooled batches stay on disk and are
+// replayed by the next exporter using the same fallback file.
+func (e *HTTPExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.shutdown = true
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// sendWithRetry sends the request, retrying retryable failures with
+// exponential backoff and jitter, honouring Retry-After when present.
+func (e *HTTPExporter) sendWithRetry(ctx context.Context, record *fallbackRecord) error {
+	policy := e.options.Retry
+	backoff := policy.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := e.send(ctx, record)
+		if err == nil {
+			return nil
+		}
+
+		var exportErr *ExportError
+		if !errors.As(err, &exportErr) || !exportErr.Retryable || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if exportErr.RetryAfter > 0 {
+			wait = exportErr.RetryAfter
+		}
+		if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+			wait = policy.MaxBackoff
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+// send performs a single request attempt.
+func (e *HTTPExporter) send(ctx context.Context, record *fallbackRecord) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.options.Endpoint, bytes.NewReader(record.Payload))
+	if err != nil {
+		return &ExportError{Exporter: e.name, Err: err}
+	}
+	req.Header.Set("Content-Type", record.ContentType)
+	if record.ContentEncoding != "" {
+		req.Header.Set("Content-Encoding", record.ContentEncoding)
+	}
+	for k, v := range e.options.Headers {
+		req.Header.Set(k, v)
+	}
+	if e.options.Username != "" {
+		req.SetBasicAuth(e.options.Username, e.options.Password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return &ExportError{Exporter: e.name, Retryable: true, Err: fmt.Errorf("%w: %v", ErrConnectionFailed, err)}
+	}
+	defer resp.Body.Close()
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	exportErr := &ExportError{
+		Exporter:   e.name,
+		StatusCode: resp.StatusCode,
+		Err:        fmt.Errorf("%s", bytes.TrimSpace(message)),
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		exportErr.Retryable = true
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			exportErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+	return exportErr
+}
+
+// NewExporter creates the exporter selected by config.ExportFormat.
+func NewExporter(config *Config) (Exporter, error) {
+	opts, err := httpOptionsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	resource := resourceFromConfig(config)
+
+	switch config.ExportFormat {
+	case "otlp":
+		opts.Endpoint = firstNonEmpty(config.OTLPEndpoint, config.ExportEndpoint)
+		opts.Headers = config.OTLPHeaders
+		opts.Compression = config.OTLPCompression
+		return NewOTLPExporter(resource, config.OTLPProtocol, opts)
+	case "zipkin":
+		opts.Endpoint = firstNonEmpty(config.ZipkinEndpoint, config.ExportEndpoint)
+		return NewZipkinExporter(resource, opts)
+	case "json":
+		opts.Endpoint = config.ExportEndpoint
+		return NewJSONExporter(resource, opts)
+	case "jaeger", "":
+		opts.Endpoint = firstNonEmpty(config.JaegerCollectorURL, config.ExportEndpoint)
+		opts.Username = config.JaegerUser
+		opts.Password = config.JaegerPassword
+		return NewJaegerExporter(resource, opts)
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+}
+
+// httpOptionsFromConfig builds the format independent exporter options.
+func httpOptionsFromConfig(config *Config) (HTTPExporterOptions, error) {
+	opts := HTTPExporterOptions{
+		Retry: DefaultRetryPolicy(),
+	}
+	if config.ExportTimeout != "" {
+		timeout, err := time.ParseDuration(config.ExportTimeout)
+		if err != nil {
+			return opts, fmt.Errorf("invalid export_timeout: %w", err)
+		}
+		opts.Timeout = timeout
+	}
+	if config.ExportMaxRetries > 0 {
+		opts.Retry.MaxAttempts = config.ExportMaxRetries + 1
+	}
+
+	if config.ExportFallbackPath != "" {
+		fallback, err := NewFileFallback(config.ExportFallbackPath, config.ExportFallbackMaxBytes)
+		if err != nil {
+			return opts, err
+		}
+		opts.Fallback = fallback
+	}
+
+	if config.TLSEnabled {
+		tlsConfig, err := tlsConfigFromFiles(config.TLSCAFile, config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return opts, err
+		}
+		opts.Client = &http.Client{
+			Timeout:   opts.Timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}
+	}
+	return opts, nil
+}
+
+func tlsConfigFromFiles(caFile, certFile, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if certFile != "" && keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls key pair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// encodeJSON encodes spans in the same shape as Tracer.Export(ExportFormatJSON).
+func encodeJSON(resource Resource, spans []*spanData) ([]byte, error) {
+	data := make([]map[string]interface{}, len(spans))
+	for i, span := range spans {
+		data[i] = map[string]interface{}{
+			"traceID":       span.traceID,
+			"spanID":        span.spanID,
+			"parentSpanID":  span.parentSpanID,
+			"operationName": span.operationName,
+			"startTime":     span.startTime,
+			"endTime":       span.endTime,
+			"tags":          span.tags,
+			"logs":          span.logs,
+			"events":        span.events,
+			"status":        span.status,
+			"duration":      span.endTime.Sub(span.startTime),
+		}
+	}
+	return json.Marshal(map[string]interface{}{
+		"resource": resource.Attributes,
+		"spans":    data,
+	})
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
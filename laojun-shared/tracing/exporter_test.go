@@ -0,0 +1,416 @@
+package tracing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden payload files")
+
+// fakeCollector records export requests and replies with scripted status codes.
+type fakeCollector struct {
+	mu       sync.Mutex
+	requests []*collectedRequest
+	statuses []int
+}
+
+type collectedRequest struct {
+	contentType     string
+	contentEncoding string
+	authorization   string
+	body            []byte
+}
+
+func (c *fakeCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var reader io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reader = gz
+	}
+	body, _ := io.ReadAll(reader)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.requests, &collectedRequest{
+		contentType:     r.Header.Get("Content-Type"),
+		contentEncoding: r.Header.Get("Content-Encoding"),
+		authorization:   r.Header.Get("Authorization"),
+		body:            body,
+	})
+
+	status := http.StatusOK
+	if len(c.statuses) > 0 {
+		status = c.statuses[0]
+		c.statuses = c.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (c *fakeCollector) setStatuses(statuses ...int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statuses = statuses
+}
+
+func (c *fakeCollector) received() []*collectedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*collectedRequest(nil), c.requests...)
+}
+
+func testResource() Resource {
+	return Resource{
+		ServiceName: "marketplace-api",
+		Attributes: map[string]string{
+			"service.name":           "marketplace-api",
+			"service.version":        "1.2.3",
+			"deployment.environment": "test",
+		},
+	}
+}
+
+// testSpans returns a deterministic server span with a failing client child.
+func testSpans() []*spanImpl {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rootEnd := start.Add(150 * time.Millisecond)
+	childStart := start.Add(20 * time.Millisecond)
+	childEnd := childStart.Add(35 * time.Millisecond)
+
+	root := &spanImpl{
+		traceID:       "4bf92f3577b34da6a3ce929d0e0e4736",
+		spanID:        "00f067aa0ba902b7",
+		operationName: "GET /api/plugins",
+		startTime:     start,
+		endTime:       &rootEnd,
+		tags: map[string]interface{}{
+			"span.kind":        "server",
+			"http.method":      "GET",
+			"http.status_code": 200,
+			"cache.hit":        false,
+			"sample.ratio":     0.25,
+		},
+		events: []Event{{
+			Name:       "cache.miss",
+			Timestamp:  start.Add(10 * time.Millisecond),
+			Attributes: []EventAttribute{{Key: "cache.key", Value: "plugins:list"}},
+		}},
+		status:   SpanStatusOK,
+		finished: true,
+	}
+	child := &spanImpl{
+		traceID:       root.traceID,
+		spanID:        "b7ad6b7169203331",
+		parentSpanID:  root.spanID,
+		operationName: "SELECT plugins",
+		startTime:     childStart,
+		endTime:       &childEnd,
+		tags: map[string]interface{}{
+			"span.kind": "client",
+			"db.system": "postgresql",
+		},
+		logs: []LogEntry{{
+			Timestamp: childStart.Add(5 * time.Millisecond),
+			Fields:    []LogField{{Key: "event", Value: "retry"}, {Key: "attempt", Value: 2}},
+		}},
+		status:     SpanStatusError,
+		statusDesc: "connection reset",
+		finished:   true,
+	}
+	return []*spanImpl{root, child}
+}
+
+func testOptions(endpoint string) HTTPExporterOptions {
+	return HTTPExporterOptions{
+		Endpoint: endpoint,
+		Retry:    RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("payload does not match %s\ngot:  %q\nwant: %q", path, got, want)
+	}
+}
+
+func TestExporterGoldenPayloads(t *testing.T) {
+	tests := []struct {
+		name        string
+		golden      string
+		contentType string
+		newExporter func(opts HTTPExporterOptions) (*HTTPExporter, error)
+	}{
+		{
+			name:        "otlp protobuf",
+			golden:      "otlp.pb.golden",
+			contentType: "application/x-protobuf",
+			newExporter: func(opts HTTPExporterOptions) (*HTTPExporter, error) {
+				return NewOTLPExporter(testResource(), OTLPProtocolProtobuf, opts)
+			},
+		},
+		{
+			name:        "otlp json",
+			golden:      "otlp.json.golden",
+			contentType: "application/json",
+			newExporter: func(opts HTTPExporterOptions) (*HTTPExporter, error) {
+				return NewOTLPExporter(testResource(), OTLPProtocolJSON, opts)
+			},
+		},
+		{
+			name:        "zipkin",
+			golden:      "zipkin.json.golden",
+			contentType: "application/json",
+			newExporter: func(opts HTTPExporterOptions) (*HTTPExporter, error) {
+				return NewZipkinExporter(testResource(), opts)
+			},
+		},
+		{
+			name:        "jaeger",
+			golden:      "jaeger.thrift.golden",
+			contentType: "application/x-thrift",
+			newExporter: func(opts HTTPExporterOptions) (*HTTPExporter, error) {
+				return NewJaegerExporter(testResource(), opts)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &fakeCollector{}
+			server := httptest.NewServer(collector)
+			defer server.Close()
+
+			exporter, err := tt.newExporter(testOptions(server.URL))
+			if err != nil {
+				t.Fatalf("failed to create exporter: %v", err)
+			}
+			if err := exporter.Export(context.Background(), testSpans()); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			requests := collector.received()
+			if len(requests) != 1 {
+				t.Fatalf("expected 1 request, got %d", len(requests))
+			}
+			if requests[0].contentType != tt.contentType {
+				t.Errorf("expected content type %s, got %s", tt.contentType, requests[0].contentType)
+			}
+			assertGolden(t, tt.golden, requests[0].body)
+		})
+	}
+}
+
+func TestExporterGzipAndBasicAuth(t *testing.T) {
+	collector := &fakeCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	opts := testOptions(server.URL)
+	opts.Compression = "gzip"
+	opts.Username = "jaeger"
+	opts.Password = "secret"
+	exporter, err := NewOTLPExporter(testResource(), OTLPProtocolProtobuf, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Export(context.Background(), testSpans()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	request := collector.received()[0]
+	if request.contentEncoding != "gzip" {
+		t.Errorf("expected gzip content encoding, got %q", request.contentEncoding)
+	}
+	if request.authorization == "" {
+		t.Error("expected basic auth header")
+	}
+	assertGolden(t, "otlp.pb.golden", request.body)
+}
+
+func TestExporterRetry(t *testing.T) {
+	collector := &fakeCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	exporter, err := NewZipkinExporter(testResource(), testOptions(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// transient failures are retried
+	collector.setStatuses(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	if err := exporter.Export(context.Background(), testSpans()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if n := len(collector.received()); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+
+	// rejected payloads are not retried
+	collector.setStatuses(http.StatusBadRequest)
+	err = exporter.Export(context.Background(), testSpans())
+	var exportErr *ExportError
+	if !errors.As(err, &exportErr) || exportErr.StatusCode != http.StatusBadRequest || exportErr.Retryable {
+		t.Fatalf("expected permanent ExportError, got %v", err)
+	}
+	if !IsExportFailed(err) {
+		t.Error("expected ExportError to match ErrExportFailed")
+	}
+	if n := len(collector.received()); n != 4 {
+		t.Fatalf("expected no retry for 400, got %d requests", n)
+	}
+}
+
+func TestExporterFileFallback(t *testing.T) {
+	collector := &fakeCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	fallback, err := NewFileFallback(filepath.Join(t.TempDir(), "spool", "spans.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := testOptions(server.URL)
+	opts.Fallback = fallback
+	exporter, err := NewJaegerExporter(testResource(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// collector down: all attempts fail and the batch is spooled
+	collector.setStatuses(http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	err = exporter.Export(context.Background(), testSpans())
+	if !errors.Is(err, ErrExportSpooled) {
+		t.Fatalf("expected ErrExportSpooled, got %v", err)
+	}
+	if !fallback.Pending() {
+		t.Fatal("expected spooled batch in fallback file")
+	}
+
+	// collector back: the new batch is sent and the spooled one replayed
+	if err := exporter.Export(context.Background(), testSpans()[:1]); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	requests := collector.received()
+	if len(requests) != 5 {
+		t.Fatalf("expected 5 requests, got %d", len(requests))
+	}
+	assertGolden(t, "jaeger.thrift.golden", requests[4].body)
+	if fallback.Pending() {
+		t.Error("expected fallback file to be drained")
+	}
+}
+
+// blockingExporter blocks every export until released.
+type blockingExporter struct {
+	started  chan struct{}
+	release  chan struct{}
+	mu       sync.Mutex
+	exported int
+}
+
+func (e *blockingExporter) Export(ctx context.Context, spans []*spanImpl) error {
+	e.started <- struct{}{}
+	<-e.release
+	e.mu.Lock()
+	e.exported += len(spans)
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *blockingExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestBatchSpanProcessorDropsWhenQueueFull(t *testing.T) {
+	exporter := &blockingExporter{started: make(chan struct{}, 10), release: make(chan struct{})}
+	processor := NewBatchSpanProcessor(exporter, BatchProcessorOptions{
+		MaxQueueSize: 2,
+		MaxBatchSize: 1,
+		BatchTimeout: time.Hour,
+	})
+
+	spans := testSpans()
+	processor.OnEnd(spans[0])
+	<-exporter.started
+
+	// the exporter is busy, so the queue holds two spans and drops the rest
+	processor.OnEnd(spans[1])
+	processor.OnEnd(spans[0])
+	processor.OnEnd(spans[1])
+
+	close(exporter.release)
+	if err := processor.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	processor.OnEnd(spans[0])
+
+	stats := processor.Stats()
+	want := ProcessorStats{Queued: 3, Exported: 3, Dropped: 2}
+	if stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestTracerExportsFinishedSpans(t *testing.T) {
+	collector := &fakeCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ServiceName = "marketplace-api"
+	config.SamplingEnabled = false
+	config.ExportFormat = "zipkin"
+	config.ZipkinEndpoint = server.URL
+	config.ExportInterval = "1h"
+
+	tracer, err := NewTracer(&config)
+	if err != nil {
+		t.Fatalf("NewTracer() error = %v", err)
+	}
+	defer tracer.Close()
+
+	span, _ := tracer.StartSpan(context.Background(), "GET /health")
+	span.Finish()
+
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if n := len(collector.received()); n != 1 {
+		t.Fatalf("expected 1 export request, got %d", n)
+	}
+	if stats := tracer.(*TracerImpl).ExportStats(); stats.Exported != 1 {
+		t.Errorf("expected 1 exported span, got %+v", stats)
+	}
+}
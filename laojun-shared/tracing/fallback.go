@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fallbackRecord is one spooled export request, stored as a JSON line.
+type fallbackRecord struct {
+	ContentType     string `json:"content_type"`
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	SpanCount       int    `json:"span_count"`
+	Payload         []byte `json:"payload"`
+}
+
+// FileFallback spools encoded export requests to a local file while the
+// collector is unreachable and replays them once it recovers.
+type FileFallback struct {
+	path     string
+	maxBytes int64
+	mu       sync.Mutex
+}
+
+// NewFileFallback creates a file fallback. maxBytes <= 0 means unlimited.
+func NewFileFallback(path string, maxBytes int64) (*FileFallback, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fallback directory: %w", err)
+	}
+	return &FileFallback{path: path, maxBytes: maxBytes}, nil
+}
+
+// Path returns the spool file path.
+func (f *FileFallback) Path() string {
+	return f.path
+}
+
+// Write appends a request to the spool file. It returns ErrBufferFull when
+// the file has reached its size limit.
+func (f *FileFallback) Write(record *fallbackRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxBytes > 0 {
+		if info, err := os.Stat(f.path); err == nil && info.Size()+int64(len(line)) > f.maxBytes {
+			return ErrBufferFull
+		}
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(line)
+	return err
+}
+
+// Replay sends spooled requests in order and removes the ones that were
+// delivered. Replay stops at the first failure and keeps the rest.
+// It returns the number of spans delivered.
+func (f *FileFallback) Replay(send func(record *fallbackRecord) error) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	records, err := f.read()
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+
+	delivered := 0
+	for i, record := range records {
+		if err := send(record); err != nil {
+			return delivered, f.rewrite(records[i:])
+		}
+		delivered += record.SpanCount
+	}
+	return delivered, os.Remove(f.path)
+}
+
+// Pending reports whether spooled requests are waiting for replay.
+func (f *FileFallback) Pending() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	return err == nil && info.Size() > 0
+}
+
+func (f *FileFallback) read() ([]*fallbackRecord, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []*fallbackRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var record fallbackRecord
+		// skip lines truncated by a crash mid-write
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, &record)
+	}
+	return records, scanner.Err()
+}
+
+// rewrite atomically replaces the spool file with the remaining records.
+func (f *FileFallback) rewrite(records []*fallbackRecord) error {
+	tmp := f.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
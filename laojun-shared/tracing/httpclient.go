@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Transport is an http.RoundTripper that starts a client span per request
+// and injects the trace context into the outgoing headers.
+type Transport struct {
+	// Base is the underlying transport, http.DefaultTransport if nil.
+	Base http.RoundTripper
+	// Tracer starts the client spans, the global tracer if nil.
+	Tracer Tracer
+	// Propagator overrides the tracer's propagator.
+	Propagator Propagator
+}
+
+// NewTransport wraps base with trace context propagation. Both arguments
+// may be nil to use the global tracer and http.DefaultTransport.
+func NewTransport(tracer Tracer, base http.RoundTripper) *Transport {
+	return &Transport{Base: base, Tracer: tracer}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	tracer := t.Tracer
+	if tracer == nil {
+		tracer = GlobalTracer()
+	}
+	propagator := t.Propagator
+	if propagator == nil {
+		propagator = PropagatorFor(tracer)
+	}
+
+	ctx := req.Context()
+	var span Span
+	if tracer != nil {
+		url := *req.URL
+		url.RawQuery = ""
+		url.User = nil
+		span, ctx = tracer.StartSpan(ctx, "HTTP "+req.Method,
+			WithSpanKind(SpanKindClient),
+			WithTag("http.method", req.Method),
+			WithTag("http.url", url.String()),
+		)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(ctx)
+	propagator.Inject(ctx, HeaderCarrier(req.Header))
+
+	resp, err := base.RoundTrip(req)
+	if span == nil {
+		return resp, err
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(SpanStatusError, err.Error())
+	} else {
+		span.SetTag("http.status_code", resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(SpanStatusError, fmt.Sprintf("HTTP %d", resp.StatusCode))
+		}
+	}
+	span.Finish()
+	return resp, err
+}
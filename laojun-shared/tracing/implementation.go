@@ -17,12 +17,13 @@ type TracerImpl struct {
 	config    *Config
 	spans     map[string]*spanImpl
 	traces    map[string]*traceImpl
-	sampler   Sampler
-	exporter  Exporter
+	sampler    Sampler
+	propagator Propagator
+	processor  spanProcessor
+	batch      *BatchSpanProcessor
+	tail       *TailSamplingProcessor
 	mu        sync.RWMutex
 	closed    bool
-	buffer    []*spanImpl
-	bufferMu  sync.Mutex
 }
 
 // spanImpl implements the Span interface
@@ -39,7 +40,10 @@ type spanImpl struct {
 	status        SpanStatus
 	statusDesc    string
 	baggage       map[string]string
+	sampled       bool
+	traceState    TraceState
 	finished      bool
+	processor     spanProcessor
 	mu            sync.RWMutex
 }
 
@@ -59,12 +63,15 @@ type spanContextImpl struct {
 	traceID    string
 	spanID     string
 	sampled    bool
+	debug      bool
 	traceFlags byte
 	traceState TraceState
 }
 
-// traceStateImpl implements the TraceState interface
+// traceStateImpl implements the TraceState interface.
+// Keys are kept in W3C order, most recently updated first.
 type traceStateImpl struct {
+	keys    []string
 	entries map[string]string
 	mu      sync.RWMutex
 }
@@ -82,12 +89,6 @@ type Event struct {
 	Attributes []EventAttribute
 }
 
-// Exporter interface for exporting traces
-type Exporter interface {
-	Export(ctx context.Context, spans []*spanImpl) error
-	Shutdown(ctx context.Context) error
-}
-
 // NewTracer creates a new tracer instance
 func NewTracer(config *Config) (Tracer, error) {
 	if config == nil {
@@ -103,13 +104,21 @@ func NewTracer(config *Config) (Tracer, error) {
 		config: config,
 		spans:  make(map[string]*spanImpl),
 		traces: make(map[string]*traceImpl),
-		buffer: make([]*spanImpl, 0, config.BufferSize),
 	}
 	
+	// Initialize propagator
+	propagator, err := NewPropagator(config.PropagationFormats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create propagator: %w", err)
+	}
+	tracer.propagator = propagator
+	
 	// Initialize sampler
-	if config.SamplingEnabled {
-		tracer.sampler = NewProbabilisticSampler(config.SamplingRate)
+	sampler, err := NewSampler(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sampler: %w", err)
 	}
+	tracer.sampler = sampler
 	
 	// Initialize exporter
 	if config.ExportEnabled {
@@ -117,7 +126,13 @@ func NewTracer(config *Config) (Tracer, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create exporter: %w", err)
 		}
-		tracer.exporter = exporter
+		tracer.batch = NewBatchSpanProcessor(exporter, batchOptionsFromConfig(config))
+		tracer.processor = tracer.batch
+		
+		if config.TailSamplingEnabled {
+			tracer.tail = NewTailSamplingProcessor(tracer.batch, tailOptionsFromConfig(config))
+			tracer.processor = tracer.tail
+		}
 	}
 	
 	return tracer, nil
@@ -152,22 +167,57 @@ func (t *TracerImpl) StartSpan(ctx context.Context, operationName string, opts .
 		status:        SpanStatusUnset,
 	}
 	
-	// Set trace ID and parent
+	// Set trace ID and parent: explicit parent, local span, then remote context
+	var parent SpanContext
 	if config.Parent != nil {
-		span.traceID = config.Parent.TraceID()
-		span.parentSpanID = config.Parent.SpanID()
+		parent = config.Parent.Context()
 	} else if parentSpan := t.SpanFromContext(ctx); parentSpan != nil {
-		span.traceID = parentSpan.TraceID()
-		span.parentSpanID = parentSpan.SpanID()
+		parent = parentSpan.Context()
+	} else {
+		parent = remoteSpanContext(ctx)
+	}
+	
+	if parent != nil {
+		span.traceID = parent.TraceID()
+		span.parentSpanID = parent.SpanID()
+		span.traceState = parent.TraceState()
 	} else {
 		span.traceID = generateID()
 	}
 	
+	// Inherit baggage from the context and the parent span
+	for k, v := range BaggageFromContext(ctx) {
+		span.baggage[k] = v
+	}
+	if parentSpan, ok := config.Parent.(*spanImpl); ok {
+		parentSpan.mu.RLock()
+		for k, v := range parentSpan.baggage {
+			span.baggage[k] = v
+		}
+		parentSpan.mu.RUnlock()
+	} else if parentSpan, ok := t.SpanFromContext(ctx).(*spanImpl); ok && parentSpan != nil {
+		parentSpan.mu.RLock()
+		for k, v := range parentSpan.baggage {
+			span.baggage[k] = v
+		}
+		parentSpan.mu.RUnlock()
+	}
+	
 	// Check sampling
-	if t.sampler != nil && !t.ShouldSample(ctx, span.traceID, operationName) {
-		return span, ctx
+	result := t.sample(ctx, span.traceID, operationName, parent)
+	span.sampled = result.Decision == SamplingDecisionRecordAndSample
+	if result.TraceState != nil {
+		span.traceState = result.TraceState
+	}
+	for k, v := range result.Attributes {
+		span.tags[k] = v
+	}
+	if !span.sampled {
+		// unsampled spans still carry the decision to children and downstream services
+		return span, t.ContextWithSpan(ctx, span)
 	}
 	
+	span.processor = t.processor
 	t.spans[span.spanID] = span
 	
 	// Add to trace
@@ -222,70 +272,67 @@ func (t *TracerImpl) GetActiveTrace(ctx context.Context) Trace {
 	return nil
 }
 
-// Inject injects trace context into carrier
+// Inject injects trace context into carrier using the configured propagators
 func (t *TracerImpl) Inject(ctx context.Context, format Format, carrier interface{}) error {
-	span := t.SpanFromContext(ctx)
-	if span == nil {
-		return ErrSpanNotActive
+	textMap, err := carrierFor(format, carrier)
+	if err != nil {
+		return err
 	}
 	
-	switch format {
-	case FormatHTTPHeaders:
-		if headers, ok := carrier.(http.Header); ok {
-			headers.Set("traceparent", fmt.Sprintf("00-%s-%s-01", span.TraceID(), span.SpanID()))
-			return nil
-		}
-		return ErrInvalidCarrier
-	case FormatTextMap:
-		if textMap, ok := carrier.(map[string]string); ok {
-			textMap["traceparent"] = fmt.Sprintf("00-%s-%s-01", span.TraceID(), span.SpanID())
-			return nil
-		}
-		return ErrInvalidCarrier
-	default:
-		return ErrUnsupportedFormat
+	if SpanContextFromContext(ctx) == nil && len(injectionBaggage(ctx)) == 0 {
+		return ErrSpanNotActive
 	}
+	
+	t.propagator.Inject(ctx, textMap)
+	return nil
 }
 
-// Extract extracts trace context from carrier
+// Extract extracts trace context from carrier using the configured propagators.
+// Spans started from the returned context continue the remote trace.
 func (t *TracerImpl) Extract(ctx context.Context, format Format, carrier interface{}) (context.Context, error) {
-	var traceparent string
+	textMap, err := carrierFor(format, carrier)
+	if err != nil {
+		return ctx, err
+	}
+	
+	return t.propagator.Extract(ctx, textMap), nil
+}
+
+// Propagator returns the propagator configured by Config.PropagationFormats
+func (t *TracerImpl) Propagator() Propagator {
+	return t.propagator
+}
+
+// carrierFor adapts a carrier to TextMapCarrier
+func carrierFor(format Format, carrier interface{}) (TextMapCarrier, error) {
+	if textMap, ok := carrier.(TextMapCarrier); ok {
+		return textMap, nil
+	}
 	
 	switch format {
-	case FormatHTTPHeaders:
-		if headers, ok := carrier.(http.Header); ok {
-			traceparent = headers.Get("traceparent")
-		} else {
-			return ctx, ErrInvalidCarrier
-		}
-	case FormatTextMap:
-		if textMap, ok := carrier.(map[string]string); ok {
-			traceparent = textMap["traceparent"]
-		} else {
-			return ctx, ErrInvalidCarrier
+	case FormatHTTPHeaders, FormatTextMap:
+		switch c := carrier.(type) {
+		case http.Header:
+			return HeaderCarrier(c), nil
+		case map[string]string:
+			return MapCarrier(c), nil
 		}
+		return nil, ErrInvalidCarrier
 	default:
-		return ctx, ErrUnsupportedFormat
-	}
-	
-	if traceparent == "" {
-		return ctx, nil
+		return nil, ErrUnsupportedFormat
 	}
-	
-	// Parse traceparent header (simplified)
-	parts := strings.Split(traceparent, "-")
-	if len(parts) != 4 {
-		return ctx, ErrInvalidSpanContext
+}
+
+// sample makes the head sampling decision. Without a sampler the parent
+// decision is followed and new traces are sampled.
+func (t *TracerImpl) sample(ctx context.Context, traceID, spanName string, parent SpanContext) SamplingResult {
+	if t.sampler != nil {
+		return t.sampler.ShouldSample(ctx, traceID, spanName, parent)
 	}
-	
-	spanContext := &spanContextImpl{
-		traceID:    parts[1],
-		spanID:     parts[2],
-		sampled:    parts[3] == "01",
-		traceFlags: 1,
+	if parent != nil && !parent.IsSampled() {
+		return SamplingResult{Decision: SamplingDecisionDrop}
 	}
-	
-	return context.WithValue(ctx, "spanContext", spanContext), nil
+	return SamplingResult{Decision: SamplingDecisionRecordAndSample}
 }
 
 // ShouldSample determines if a trace should be sampled
@@ -321,21 +368,28 @@ func (t *TracerImpl) Export(ctx context.Context, format ExportFormat) ([]byte, e
 
 // Flush flushes all pending traces
 func (t *TracerImpl) Flush(ctx context.Context) error {
-	if t.exporter == nil {
+	if t.processor == nil {
 		return nil
 	}
-	
-	t.bufferMu.Lock()
-	spans := make([]*spanImpl, len(t.buffer))
-	copy(spans, t.buffer)
-	t.buffer = t.buffer[:0]
-	t.bufferMu.Unlock()
-	
-	if len(spans) > 0 {
-		return t.exporter.Export(ctx, spans)
+	return t.processor.ForceFlush(ctx)
+}
+
+// ExportStats returns the span export accounting, or zero stats when
+// exporting is disabled.
+func (t *TracerImpl) ExportStats() ProcessorStats {
+	if t.batch == nil {
+		return ProcessorStats{}
 	}
-	
-	return nil
+	return t.batch.Stats()
+}
+
+// TailSamplingStats returns the tail sampling accounting, or zero stats
+// when tail sampling is disabled.
+func (t *TracerImpl) TailSamplingStats() TailSamplingStats {
+	if t.tail == nil {
+		return TailSamplingStats{}
+	}
+	return t.tail.Stats()
 }
 
 // IsHealthy checks if the tracer is healthy
@@ -356,10 +410,10 @@ func (t *TracerImpl) Close() error {
 	
 	t.closed = true
 	
-	if t.exporter != nil {
+	if t.processor != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		return t.exporter.Shutdown(ctx)
+		return t.processor.Shutdown(ctx)
 	}
 	
 	return nil
@@ -385,14 +439,17 @@ func (s *spanImpl) Finish() {
 
 func (s *spanImpl) FinishWithOptions(opts FinishOptions) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	
 	if s.finished {
+		s.mu.Unlock()
 		return
 	}
-	
 	s.finished = true
 	s.endTime = &opts.FinishTime
+	s.mu.Unlock()
+	
+	if s.processor != nil {
+		s.processor.OnEnd(s)
+	}
 }
 
 func (s *spanImpl) IsFinished() bool {
@@ -489,12 +546,16 @@ func (s *spanImpl) RecordError(err error, opts ...ErrorOption) Span {
 }
 
 func (s *spanImpl) Context() SpanContext {
-	return &spanContextImpl{
+	sc := &spanContextImpl{
 		traceID:    s.traceID,
 		spanID:     s.spanID,
-		sampled:    true,
-		traceFlags: 1,
+		sampled:    s.sampled,
+		traceState: s.traceState,
+	}
+	if s.sampled {
+		sc.traceFlags = traceFlagSampled
 	}
+	return sc
 }
 
 func (s *spanImpl) StartTime() time.Time {
@@ -563,57 +624,38 @@ func (t *TracerImpl) exportJaeger(spans []*spanImpl) ([]byte, error) {
 	return json.Marshal(jaegerTrace)
 }
 
-// Sampler implementations
-
-type ProbabilisticSampler struct {
-	rate float64
-}
-
-func NewProbabilisticSampler(rate float64) *ProbabilisticSampler {
-	return &ProbabilisticSampler{rate: rate}
-}
-
-func (s *ProbabilisticSampler) ShouldSample(ctx context.Context, traceID string, spanName string, parentContext SpanContext) SamplingResult {
-	// Simple hash-based sampling
-	hash := 0
-	for _, b := range traceID {
-		hash = hash*31 + int(b)
+// tailOptionsFromConfig maps the tracing config onto tail sampling options.
+func tailOptionsFromConfig(config *Config) TailSamplingOptions {
+	opts := TailSamplingOptions{
+		MaxTraces:        config.TailSamplingMaxTraces,
+		MaxSpansPerTrace: config.TailSamplingMaxSpansPerTrace,
+		KeepErrors:       config.TailSamplingKeepErrors,
+		Attributes:       config.TailSamplingAttributes,
+		BaselineRate:     config.TailSamplingBaselineRate,
 	}
-	
-	decision := SamplingDecisionDrop
-	if float64(hash%100)/100.0 < s.rate {
-		decision = SamplingDecisionRecordAndSample
+	if wait, err := time.ParseDuration(config.TailSamplingDecisionWait); err == nil {
+		opts.DecisionWait = wait
 	}
-	
-	return SamplingResult{
-		Decision:   decision,
-		Attributes: make(map[string]interface{}),
+	if threshold, err := time.ParseDuration(config.TailSamplingLatencyThreshold); err == nil {
+		opts.LatencyThreshold = threshold
 	}
+	return opts
 }
 
-// Exporter implementations
-
-type JSONExporter struct {
-	endpoint string
-}
-
-func NewExporter(config *Config) (Exporter, error) {
-	switch config.ExportFormat {
-	case "json":
-		return &JSONExporter{endpoint: config.ExportEndpoint}, nil
-	default:
-		return &JSONExporter{endpoint: config.ExportEndpoint}, nil
+// batchOptionsFromConfig maps the tracing config onto batch processor options.
+func batchOptionsFromConfig(config *Config) BatchProcessorOptions {
+	opts := BatchProcessorOptions{
+		MaxQueueSize: config.MaxQueueSize,
+		MaxBatchSize: config.ExportBatchSize,
 	}
-}
-
-func (e *JSONExporter) Export(ctx context.Context, spans []*spanImpl) error {
-	// This is a simplified implementation
-	// In a real implementation, you would send the spans to the configured endpoint
-	return nil
-}
-
-func (e *JSONExporter) Shutdown(ctx context.Context) error {
-	return nil
+	if interval, err := time.ParseDuration(config.ExportInterval); err == nil {
+		opts.BatchTimeout = interval
+	}
+	if timeout, err := time.ParseDuration(config.ExportTimeout); err == nil {
+		// one export call covers all retry attempts
+		opts.ExportTimeout = timeout * time.Duration(config.ExportMaxRetries+1)
+	}
+	return opts
 }
 
 // SpanContext implementation
@@ -640,29 +682,80 @@ func (sc *spanContextImpl) TraceState() TraceState {
 
 // TraceState implementation
 
+// maxTraceStateMembers is the W3C limit of tracestate list members
+const maxTraceStateMembers = 32
+
+// NewTraceState creates an empty trace state
+func NewTraceState() TraceState {
+	return &traceStateImpl{entries: make(map[string]string)}
+}
+
+// parseTraceState parses a W3C tracestate header. Duplicate keys or
+// malformed members invalidate the whole header as required by the spec.
+func parseTraceState(header string) (*traceStateImpl, error) {
+	ts := &traceStateImpl{entries: make(map[string]string)}
+	for _, member := range strings.Split(header, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		idx := strings.IndexByte(member, '=')
+		if idx <= 0 || idx == len(member)-1 || strings.ContainsAny(member, " \t") {
+			return nil, ErrInvalidSpanContext
+		}
+		key, value := member[:idx], member[idx+1:]
+		if _, exists := ts.entries[key]; exists || len(ts.keys) >= maxTraceStateMembers {
+			return nil, ErrInvalidSpanContext
+		}
+		ts.keys = append(ts.keys, key)
+		ts.entries[key] = value
+	}
+	return ts, nil
+}
+
 func (ts *traceStateImpl) Get(key string) string {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 	return ts.entries[key]
 }
 
+// Set adds or updates a key and moves it to the front
 func (ts *traceStateImpl) Set(key, value string) TraceState {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 	if ts.entries == nil {
 		ts.entries = make(map[string]string)
 	}
+	ts.removeKey(key)
+	ts.keys = append([]string{key}, ts.keys...)
 	ts.entries[key] = value
+	
+	// drop the oldest members beyond the W3C limit
+	for len(ts.keys) > maxTraceStateMembers {
+		last := ts.keys[len(ts.keys)-1]
+		ts.keys = ts.keys[:len(ts.keys)-1]
+		delete(ts.entries, last)
+	}
 	return ts
 }
 
 func (ts *traceStateImpl) Delete(key string) TraceState {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
+	ts.removeKey(key)
 	delete(ts.entries, key)
 	return ts
 }
 
+func (ts *traceStateImpl) removeKey(key string) {
+	for i, k := range ts.keys {
+		if k == key {
+			ts.keys = append(ts.keys[:i], ts.keys[i+1:]...)
+			return
+		}
+	}
+}
+
 func (ts *traceStateImpl) Len() int {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
@@ -673,9 +766,9 @@ func (ts *traceStateImpl) String() string {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 	
-	var parts []string
-	for k, v := range ts.entries {
-		parts = append(parts, fmt.Sprintf("%s=%s", k, v))
+	parts := make([]string, 0, len(ts.keys))
+	for _, k := range ts.keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, ts.entries[k]))
 	}
 	return strings.Join(parts, ",")
 }
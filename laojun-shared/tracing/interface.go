@@ -233,6 +233,17 @@ type Config struct {
 	SamplingRate       float64 `json:"sampling_rate" yaml:"sampling_rate" env:"TRACING_SAMPLING_RATE"`
 	SamplingType       string  `json:"sampling_type" yaml:"sampling_type" env:"TRACING_SAMPLING_TYPE"`
 	MaxTracesPerSecond int     `json:"max_traces_per_second" yaml:"max_traces_per_second" env:"TRACING_MAX_TRACES_PER_SECOND"`
+	SamplingRules      []SamplingRule `json:"sampling_rules" yaml:"sampling_rules"`
+	
+	// Tail sampling configuration, applied to exported spans
+	TailSamplingEnabled          bool              `json:"tail_sampling_enabled" yaml:"tail_sampling_enabled" env:"TRACING_TAIL_SAMPLING_ENABLED"`
+	TailSamplingDecisionWait     string            `json:"tail_sampling_decision_wait" yaml:"tail_sampling_decision_wait" env:"TRACING_TAIL_SAMPLING_DECISION_WAIT"`
+	TailSamplingMaxTraces        int               `json:"tail_sampling_max_traces" yaml:"tail_sampling_max_traces" env:"TRACING_TAIL_SAMPLING_MAX_TRACES"`
+	TailSamplingMaxSpansPerTrace int               `json:"tail_sampling_max_spans_per_trace" yaml:"tail_sampling_max_spans_per_trace" env:"TRACING_TAIL_SAMPLING_MAX_SPANS_PER_TRACE"`
+	TailSamplingKeepErrors       bool              `json:"tail_sampling_keep_errors" yaml:"tail_sampling_keep_errors" env:"TRACING_TAIL_SAMPLING_KEEP_ERRORS"`
+	TailSamplingLatencyThreshold string            `json:"tail_sampling_latency_threshold" yaml:"tail_sampling_latency_threshold" env:"TRACING_TAIL_SAMPLING_LATENCY_THRESHOLD"`
+	TailSamplingAttributes       map[string]string `json:"tail_sampling_attributes" yaml:"tail_sampling_attributes"`
+	TailSamplingBaselineRate     float64           `json:"tail_sampling_baseline_rate" yaml:"tail_sampling_baseline_rate" env:"TRACING_TAIL_SAMPLING_BASELINE_RATE"`
 	
 	// Export configuration
 	ExportEnabled  bool   `json:"export_enabled" yaml:"export_enabled" env:"TRACING_EXPORT_ENABLED"`
@@ -241,6 +252,9 @@ type Config struct {
 	ExportTimeout  string `json:"export_timeout" yaml:"export_timeout" env:"TRACING_EXPORT_TIMEOUT"`
 	ExportBatchSize int   `json:"export_batch_size" yaml:"export_batch_size" env:"TRACING_EXPORT_BATCH_SIZE"`
 	ExportInterval  string `json:"export_interval" yaml:"export_interval" env:"TRACING_EXPORT_INTERVAL"`
+	ExportMaxRetries       int    `json:"export_max_retries" yaml:"export_max_retries" env:"TRACING_EXPORT_MAX_RETRIES"`
+	ExportFallbackPath     string `json:"export_fallback_path" yaml:"export_fallback_path" env:"TRACING_EXPORT_FALLBACK_PATH"`
+	ExportFallbackMaxBytes int64  `json:"export_fallback_max_bytes" yaml:"export_fallback_max_bytes" env:"TRACING_EXPORT_FALLBACK_MAX_BYTES"`
 	
 	// Jaeger specific configuration
 	JaegerAgentHost     string `json:"jaeger_agent_host" yaml:"jaeger_agent_host" env:"TRACING_JAEGER_AGENT_HOST"`
@@ -254,6 +268,7 @@ type Config struct {
 	
 	// OTLP specific configuration
 	OTLPEndpoint    string            `json:"otlp_endpoint" yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	OTLPProtocol    string            `json:"otlp_protocol" yaml:"otlp_protocol" env:"TRACING_OTLP_PROTOCOL"`
 	OTLPHeaders     map[string]string `json:"otlp_headers" yaml:"otlp_headers"`
 	OTLPCompression string            `json:"otlp_compression" yaml:"otlp_compression" env:"TRACING_OTLP_COMPRESSION"`
 	OTLPInsecure    bool              `json:"otlp_insecure" yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE"`
@@ -308,6 +323,39 @@ func (c *Config) Validate() error {
 		if c.MaxTracesPerSecond < 0 {
 			return fmt.Errorf("max_traces_per_second must be non-negative")
 		}
+		
+		if (c.SamplingType == "rate_limiting" || c.SamplingType == "adaptive") && c.MaxTracesPerSecond == 0 {
+			return fmt.Errorf("max_traces_per_second is required for sampling_type %s", c.SamplingType)
+		}
+		
+		for _, rule := range c.SamplingRules {
+			if err := rule.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	
+	// Validate tail sampling configuration
+	if c.TailSamplingEnabled {
+		for name, value := range map[string]string{
+			"tail_sampling_decision_wait":     c.TailSamplingDecisionWait,
+			"tail_sampling_latency_threshold": c.TailSamplingLatencyThreshold,
+		} {
+			if value == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(value); err != nil {
+				return fmt.Errorf("invalid %s format: %v", name, err)
+			}
+		}
+		
+		if c.TailSamplingMaxTraces < 0 || c.TailSamplingMaxSpansPerTrace < 0 {
+			return fmt.Errorf("tail sampling limits must be non-negative")
+		}
+		
+		if c.TailSamplingBaselineRate < 0 || c.TailSamplingBaselineRate > 1 {
+			return fmt.Errorf("tail_sampling_baseline_rate must be between 0 and 1")
+		}
 	}
 	
 	// Validate export configuration
@@ -341,6 +389,14 @@ func (c *Config) Validate() error {
 		if c.ExportBatchSize < 0 {
 			return fmt.Errorf("export_batch_size must be non-negative")
 		}
+		
+		if c.ExportMaxRetries < 0 {
+			return fmt.Errorf("export_max_retries must be non-negative")
+		}
+		
+		if c.ExportFallbackMaxBytes < 0 {
+			return fmt.Errorf("export_fallback_max_bytes must be non-negative")
+		}
 	}
 	
 	// Validate Jaeger configuration
@@ -355,6 +411,9 @@ func (c *Config) Validate() error {
 		if c.OTLPCompression != "" && c.OTLPCompression != "gzip" && c.OTLPCompression != "none" {
 			return fmt.Errorf("invalid otlp_compression: %s", c.OTLPCompression)
 		}
+		if c.OTLPProtocol != "" && c.OTLPProtocol != OTLPProtocolProtobuf && c.OTLPProtocol != OTLPProtocolJSON {
+			return fmt.Errorf("invalid otlp_protocol: %s", c.OTLPProtocol)
+		}
 	}
 	
 	// Validate buffer and performance settings
@@ -378,7 +437,7 @@ func (c *Config) Validate() error {
 	
 	// Validate propagation formats
 	for _, format := range c.PropagationFormats {
-		if format != "tracecontext" && format != "baggage" && format != "b3" && format != "jaeger" && format != "ottrace" {
+		if format != "tracecontext" && format != "baggage" && format != "b3" && format != "b3multi" && format != "jaeger" && format != "ottrace" {
 			return fmt.Errorf("invalid propagation format: %s", format)
 		}
 	}
@@ -405,6 +464,15 @@ func DefaultConfig() Config {
 		SamplingType:       "probabilistic",
 		MaxTracesPerSecond: 100,
 		
+		// Tail sampling configuration
+		TailSamplingEnabled:          false,
+		TailSamplingDecisionWait:     "10s",
+		TailSamplingMaxTraces:        10000,
+		TailSamplingMaxSpansPerTrace: 1000,
+		TailSamplingKeepErrors:       true,
+		TailSamplingLatencyThreshold: "2s",
+		TailSamplingBaselineRate:     0.01,
+		
 		// Export configuration
 		ExportEnabled:   true,
 		ExportFormat:    "jaeger",
@@ -412,6 +480,9 @@ func DefaultConfig() Config {
 		ExportTimeout:   "10s",
 		ExportBatchSize: 100,
 		ExportInterval:  "5s",
+		ExportMaxRetries:       3,
+		ExportFallbackPath:     "",
+		ExportFallbackMaxBytes: 64 << 20,
 		
 		// Jaeger specific configuration
 		JaegerAgentHost:    "localhost",
@@ -423,6 +494,7 @@ func DefaultConfig() Config {
 		
 		// OTLP specific configuration
 		OTLPEndpoint:    "http://localhost:4318/v1/traces",
+		OTLPProtocol:    OTLPProtocolProtobuf,
 		OTLPHeaders:     make(map[string]string),
 		OTLPCompression: "gzip",
 		OTLPInsecure:    true,
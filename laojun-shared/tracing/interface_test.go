@@ -1,17 +1,20 @@
 package tracing
 
 import (
-	"context"
 	"testing"
-	"time"
 )
 
 func TestNew(t *testing.T) {
 	config := DefaultConfig()
 	
-	impl := New(config)
+	config.ExportEnabled = false
+	
+	impl, err := NewTracer(&config)
+	if err != nil {
+		t.Fatalf("NewTracer() error = %v", err)
+	}
 	if impl == nil {
-		t.Fatal("New() returned nil")
+		t.Fatal("NewTracer() returned nil")
 	}
 	
 	// TODO: Add more tests
@@ -32,7 +35,7 @@ func TestConfig_Validate(t *testing.T) {
 			name: "invalid timeout",
 			config: Config{
 				Enabled: true,
-				Timeout: -1,
+				Timeout: "-1",
 			},
 			wantErr: true,
 		},
@@ -0,0 +1,216 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchProcessorOptions configures a BatchSpanProcessor.
+type BatchProcessorOptions struct {
+	// MaxQueueSize bounds the number of finished spans waiting for export.
+	// Spans finished while the queue is full are dropped.
+	MaxQueueSize int
+	// MaxBatchSize is the maximum number of spans per export call.
+	MaxBatchSize int
+	// BatchTimeout is the maximum delay before a partial batch is exported.
+	BatchTimeout time.Duration
+	// ExportTimeout bounds one export call including its retries.
+	ExportTimeout time.Duration
+}
+
+// ProcessorStats reports span accounting of a BatchSpanProcessor.
+type ProcessorStats struct {
+	// Queued is the number of spans accepted into the queue.
+	Queued uint64
+	// Exported is the number of spans delivered to the collector.
+	Exported uint64
+	// Spooled is the number of spans written to the file fallback.
+	Spooled uint64
+	// Dropped is the number of spans lost because the queue was full, the
+	// processor was shut down, or the export failed permanently.
+	Dropped uint64
+}
+
+// BatchSpanProcessor collects finished spans in a bounded queue and
+// exports them in batches from a single background goroutine.
+type BatchSpanProcessor struct {
+	exporter Exporter
+	options  BatchProcessorOptions
+	queue    chan *spanImpl
+	flushCh  chan chan struct{}
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+
+	queued   uint64
+	exported uint64
+	spooled  uint64
+	dropped  uint64
+}
+
+// NewBatchSpanProcessor creates and starts a batch span processor.
+func NewBatchSpanProcessor(exporter Exporter, opts BatchProcessorOptions) *BatchSpanProcessor {
+	if opts.MaxQueueSize <= 0 {
+		opts.MaxQueueSize = 2048
+	}
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = 512
+	}
+	if opts.MaxBatchSize > opts.MaxQueueSize {
+		opts.MaxBatchSize = opts.MaxQueueSize
+	}
+	if opts.BatchTimeout <= 0 {
+		opts.BatchTimeout = 5 * time.Second
+	}
+	if opts.ExportTimeout <= 0 {
+		opts.ExportTimeout = 30 * time.Second
+	}
+
+	p := &BatchSpanProcessor{
+		exporter: exporter,
+		options:  opts,
+		queue:    make(chan *spanImpl, opts.MaxQueueSize),
+		flushCh:  make(chan chan struct{}),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// OnEnd enqueues a finished span without blocking.
+func (p *BatchSpanProcessor) OnEnd(span *spanImpl) {
+	select {
+	case <-p.stopCh:
+		atomic.AddUint64(&p.dropped, 1)
+		return
+	default:
+	}
+
+	select {
+	case p.queue <- span:
+		atomic.AddUint64(&p.queued, 1)
+	default:
+		atomic.AddUint64(&p.dropped, 1)
+	}
+}
+
+// ForceFlush exports all queued spans and waits until the export finished.
+func (p *BatchSpanProcessor) ForceFlush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case p.flushCh <- done:
+	case <-p.doneCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports the remaining spans and shuts down the exporter.
+func (p *BatchSpanProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+
+	select {
+	case <-p.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.exporter.Shutdown(ctx)
+}
+
+// Stats returns a snapshot of the span accounting.
+func (p *BatchSpanProcessor) Stats() ProcessorStats {
+	return ProcessorStats{
+		Queued:   atomic.LoadUint64(&p.queued),
+		Exported: atomic.LoadUint64(&p.exported),
+		Spooled:  atomic.LoadUint64(&p.spooled),
+		Dropped:  atomic.LoadUint64(&p.dropped),
+	}
+}
+
+func (p *BatchSpanProcessor) run() {
+	defer close(p.doneCh)
+
+	ticker := time.NewTicker(p.options.BatchTimeout)
+	defer ticker.Stop()
+
+	batch := make([]*spanImpl, 0, p.options.MaxBatchSize)
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			if len(batch) >= p.options.MaxBatchSize {
+				p.export(batch)
+				batch = batch[:0]
+				ticker.Reset(p.options.BatchTimeout)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				p.export(batch)
+				batch = batch[:0]
+			}
+		case done := <-p.flushCh:
+			batch = p.drain(batch)
+			close(done)
+		case <-p.stopCh:
+			p.drain(batch)
+			return
+		}
+	}
+}
+
+// drain exports the current batch and everything still queued.
+func (p *BatchSpanProcessor) drain(batch []*spanImpl) []*spanImpl {
+	for {
+		select {
+		case span := <-p.queue:
+			batch = append(batch, span)
+			if len(batch) >= p.options.MaxBatchSize {
+				p.export(batch)
+				batch = batch[:0]
+			}
+		default:
+			if len(batch) > 0 {
+				p.export(batch)
+			}
+			return batch[:0]
+		}
+	}
+}
+
+func (p *BatchSpanProcessor) export(batch []*spanImpl) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.options.ExportTimeout)
+	defer cancel()
+
+	count := uint64(len(batch))
+	err := p.exporter.Export(ctx, batch)
+	switch {
+	case err == nil:
+		atomic.AddUint64(&p.exported, count)
+	case errors.Is(err, ErrExportSpooled):
+		atomic.AddUint64(&p.spooled, count)
+	default:
+		atomic.AddUint64(&p.dropped, count)
+	}
+}
+
+// spanProcessor receives finished spans. BatchSpanProcessor exports them,
+// TailSamplingProcessor filters complete traces in front of it.
+type spanProcessor interface {
+	OnEnd(span *spanImpl)
+	ForceFlush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
@@ -0,0 +1,244 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TextMapCarrier is the key/value storage a Propagator reads and writes.
+type TextMapCarrier interface {
+	Get(key string) string
+	Set(key, value string)
+	Keys() []string
+}
+
+// HeaderCarrier adapts http.Header to TextMapCarrier.
+type HeaderCarrier http.Header
+
+// Get returns the first value of the header.
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+// Set replaces the header value.
+func (c HeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+// Keys returns the header names.
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// MapCarrier adapts map[string]string to TextMapCarrier. Keys are matched
+// case-insensitively and written in lower case.
+type MapCarrier map[string]string
+
+// Get returns the value for key.
+func (c MapCarrier) Get(key string) string {
+	if v, ok := c[key]; ok {
+		return v
+	}
+	lower := strings.ToLower(key)
+	for k, v := range c {
+		if strings.ToLower(k) == lower {
+			return v
+		}
+	}
+	return ""
+}
+
+// Set sets the value for key.
+func (c MapCarrier) Set(key, value string) {
+	c[strings.ToLower(key)] = value
+}
+
+// Keys returns the map keys.
+func (c MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Propagator injects and extracts cross-process context in one wire format.
+type Propagator interface {
+	// Inject writes the span context and baggage found in ctx into carrier.
+	Inject(ctx context.Context, carrier TextMapCarrier)
+	// Extract reads carrier and returns ctx with the remote span context
+	// and baggage attached. Invalid or missing headers leave ctx unchanged.
+	Extract(ctx context.Context, carrier TextMapCarrier) context.Context
+	// Fields returns the header names the propagator uses.
+	Fields() []string
+}
+
+// CompositePropagator runs several propagators. All of them inject; on
+// extract the first propagator yielding a span context wins while baggage
+// from every propagator is merged.
+type CompositePropagator struct {
+	propagators []Propagator
+}
+
+// NewCompositePropagator creates a composite propagator.
+func NewCompositePropagator(propagators ...Propagator) *CompositePropagator {
+	return &CompositePropagator{propagators: propagators}
+}
+
+// Inject injects with every propagator.
+func (c *CompositePropagator) Inject(ctx context.Context, carrier TextMapCarrier) {
+	for _, p := range c.propagators {
+		p.Inject(ctx, carrier)
+	}
+}
+
+// Extract extracts with every propagator, keeping the first span context.
+func (c *CompositePropagator) Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
+	for _, p := range c.propagators {
+		found := remoteSpanContext(ctx)
+		ctx = p.Extract(ctx, carrier)
+		if found != nil {
+			ctx = context.WithValue(ctx, remoteSpanContextKey{}, found)
+		}
+	}
+	return ctx
+}
+
+// Fields returns the union of all propagator fields.
+func (c *CompositePropagator) Fields() []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, p := range c.propagators {
+		for _, f := range p.Fields() {
+			if !seen[f] {
+				seen[f] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// NewPropagator builds a composite propagator from format names as used in
+// Config.PropagationFormats: tracecontext, baggage, b3, b3multi, jaeger, ottrace.
+func NewPropagator(formats []string) (Propagator, error) {
+	if len(formats) == 0 {
+		formats = []string{"tracecontext", "baggage"}
+	}
+
+	propagators := make([]Propagator, 0, len(formats))
+	for _, format := range formats {
+		switch format {
+		case "tracecontext":
+			propagators = append(propagators, TraceContextPropagator{})
+		case "baggage":
+			propagators = append(propagators, BaggagePropagator{})
+		case "b3":
+			propagators = append(propagators, B3Propagator{SingleHeader: true})
+		case "b3multi":
+			propagators = append(propagators, B3Propagator{})
+		case "jaeger":
+			propagators = append(propagators, JaegerPropagator{})
+		case "ottrace":
+			propagators = append(propagators, OTTracePropagator{})
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+		}
+	}
+	return NewCompositePropagator(propagators...), nil
+}
+
+// Context keys for propagated state.
+type (
+	remoteSpanContextKey struct{}
+	baggageKey           struct{}
+)
+
+// ContextWithRemoteSpanContext attaches a span context received from another
+// process. Spans started from the returned context become its children.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteSpanContextKey{}, sc)
+}
+
+func remoteSpanContext(ctx context.Context) SpanContext {
+	if sc, ok := ctx.Value(remoteSpanContextKey{}).(SpanContext); ok {
+		return sc
+	}
+	return nil
+}
+
+// SpanContextFromContext returns the span context to propagate: the active
+// local span if any, otherwise the extracted remote span context.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span, ok := ctx.Value("span").(Span); ok && span != nil {
+		return span.Context()
+	}
+	return remoteSpanContext(ctx)
+}
+
+// injectionBaggage merges context baggage with the active span's baggage.
+func injectionBaggage(ctx context.Context) Baggage {
+	baggage := BaggageFromContext(ctx)
+	if span, ok := ctx.Value("span").(*spanImpl); ok && span != nil {
+		span.mu.RLock()
+		for k, v := range span.baggage {
+			if baggage == nil {
+				baggage = make(Baggage)
+			}
+			baggage[k] = v
+		}
+		span.mu.RUnlock()
+	}
+	return baggage
+}
+
+var (
+	globalMu         sync.RWMutex
+	globalTracer     Tracer
+	globalPropagator Propagator = NewCompositePropagator(TraceContextPropagator{}, BaggagePropagator{})
+)
+
+// SetGlobalTracer sets the tracer used by helpers created without one.
+func SetGlobalTracer(tracer Tracer) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalTracer = tracer
+}
+
+// GlobalTracer returns the global tracer, or nil if none was set.
+func GlobalTracer() Tracer {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalTracer
+}
+
+// SetGlobalPropagator sets the propagator used by helpers created without
+// a tracer. The default is W3C tracecontext plus baggage.
+func SetGlobalPropagator(propagator Propagator) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalPropagator = propagator
+}
+
+// GlobalPropagator returns the global propagator.
+func GlobalPropagator() Propagator {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return globalPropagator
+}
+
+// PropagatorFor returns the tracer's propagator, falling back to the
+// global propagator for nil or foreign tracers.
+func PropagatorFor(tracer Tracer) Propagator {
+	if p, ok := tracer.(interface{ Propagator() Propagator }); ok {
+		return p.Propagator()
+	}
+	return GlobalPropagator()
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+)
+
+// B3 header names, see https://github.com/openzipkin/b3-propagation.
+const (
+	b3SingleHeader       = "b3"
+	b3TraceIDHeader      = "X-B3-TraceId"
+	b3SpanIDHeader       = "X-B3-SpanId"
+	b3ParentSpanIDHeader = "X-B3-ParentSpanId"
+	b3SampledHeader      = "X-B3-Sampled"
+	b3FlagsHeader        = "X-B3-Flags"
+)
+
+// B3Propagator propagates Zipkin B3 headers, either the single "b3" header
+// or the X-B3-* multi-header form. Extraction accepts both forms.
+type B3Propagator struct {
+	// SingleHeader injects the single "b3" header instead of X-B3-* headers.
+	SingleHeader bool
+}
+
+// Inject writes B3 headers.
+func (p B3Propagator) Inject(ctx context.Context, carrier TextMapCarrier) {
+	sc := SpanContextFromContext(ctx)
+	if sc == nil || !validID(sc.TraceID(), 32) || !validID(sc.SpanID(), 16) {
+		return
+	}
+
+	traceID := b3TraceID(sc.TraceID())
+	spanID := paddedID(sc.SpanID(), 8)
+	debug := isDebug(sc)
+
+	if p.SingleHeader {
+		sampling := "0"
+		if debug {
+			sampling = "d"
+		} else if sc.IsSampled() {
+			sampling = "1"
+		}
+		carrier.Set(b3SingleHeader, traceID+"-"+spanID+"-"+sampling)
+		return
+	}
+
+	carrier.Set(b3TraceIDHeader, traceID)
+	carrier.Set(b3SpanIDHeader, spanID)
+	if debug {
+		// debug implies sampled, X-B3-Sampled must not be sent with it
+		carrier.Set(b3FlagsHeader, "1")
+	} else if sc.IsSampled() {
+		carrier.Set(b3SampledHeader, "1")
+	} else {
+		carrier.Set(b3SampledHeader, "0")
+	}
+}
+
+// Extract reads the single header first and falls back to X-B3-* headers.
+func (p B3Propagator) Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
+	if sc, ok := parseB3Single(strings.TrimSpace(carrier.Get(b3SingleHeader))); ok {
+		return ContextWithRemoteSpanContext(ctx, sc)
+	}
+
+	traceID := strings.ToLower(strings.TrimSpace(carrier.Get(b3TraceIDHeader)))
+	spanID := strings.ToLower(strings.TrimSpace(carrier.Get(b3SpanIDHeader)))
+	if !validB3TraceID(traceID) || len(spanID) != 16 || !validID(spanID, 16) {
+		return ctx
+	}
+
+	sc := &spanContextImpl{traceID: traceID, spanID: spanID}
+	switch strings.ToLower(carrier.Get(b3SampledHeader)) {
+	case "1", "true":
+		sc.sampled = true
+	}
+	if carrier.Get(b3FlagsHeader) == "1" {
+		sc.debug = true
+		sc.sampled = true
+	}
+	if sc.sampled {
+		sc.traceFlags = traceFlagSampled
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the B3 header names.
+func (p B3Propagator) Fields() []string {
+	if p.SingleHeader {
+		return []string{b3SingleHeader}
+	}
+	return []string{b3TraceIDHeader, b3SpanIDHeader, b3ParentSpanIDHeader, b3SampledHeader, b3FlagsHeader}
+}
+
+// parseB3Single parses "{TraceId}-{SpanId}[-{SamplingState}[-{ParentSpanId}]]".
+// A bare sampling state carries no span context and is ignored.
+func parseB3Single(header string) (*spanContextImpl, bool) {
+	parts := strings.Split(strings.ToLower(header), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return nil, false
+	}
+	if !validB3TraceID(parts[0]) || len(parts[1]) != 16 || !validID(parts[1], 16) {
+		return nil, false
+	}
+
+	sc := &spanContextImpl{traceID: parts[0], spanID: parts[1]}
+	if len(parts) >= 3 {
+		switch parts[2] {
+		case "1":
+			sc.sampled = true
+		case "d":
+			sc.sampled = true
+			sc.debug = true
+		case "0":
+		default:
+			return nil, false
+		}
+	}
+	if len(parts) == 4 && (len(parts[3]) != 16 || !validID(parts[3], 16)) {
+		return nil, false
+	}
+	if sc.sampled {
+		sc.traceFlags = traceFlagSampled
+	}
+	return sc, true
+}
+
+func validB3TraceID(id string) bool {
+	return (len(id) == 16 || len(id) == 32) && isLowerHex(id) && !allZero(id)
+}
+
+// b3TraceID formats a trace ID as 16 or 32 hex digits.
+func b3TraceID(id string) string {
+	if len(id) <= 16 {
+		return paddedID(id, 8)
+	}
+	return paddedID(id, 16)
+}
+
+func isDebug(sc SpanContext) bool {
+	impl, ok := sc.(*spanContextImpl)
+	return ok && impl.debug
+}
@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	jaegerHeader        = "uber-trace-id"
+	jaegerBaggagePrefix = "uberctx-"
+
+	jaegerFlagSampled = 0x01
+	jaegerFlagDebug   = 0x02
+
+	otTraceIDHeader     = "ot-tracer-traceid"
+	otSpanIDHeader      = "ot-tracer-spanid"
+	otSampledHeader     = "ot-tracer-sampled"
+	otBaggageHeaderPref = "ot-baggage-"
+)
+
+// JaegerPropagator propagates the Jaeger "uber-trace-id" header and
+// "uberctx-" baggage headers.
+type JaegerPropagator struct{}
+
+// Inject writes uber-trace-id as {trace-id}:{span-id}:0:{flags}.
+func (JaegerPropagator) Inject(ctx context.Context, carrier TextMapCarrier) {
+	if sc := SpanContextFromContext(ctx); sc != nil && validID(sc.TraceID(), 32) && validID(sc.SpanID(), 16) {
+		flags := 0
+		if sc.IsSampled() {
+			flags |= jaegerFlagSampled
+		}
+		if isDebug(sc) {
+			flags |= jaegerFlagDebug
+		}
+		carrier.Set(jaegerHeader, b3TraceID(sc.TraceID())+":"+paddedID(sc.SpanID(), 8)+":0:"+strconv.FormatInt(int64(flags), 16))
+	}
+	injectPrefixedBaggage(ctx, carrier, jaegerBaggagePrefix)
+}
+
+// Extract parses uber-trace-id and uberctx- baggage. Jaeger clients may
+// drop leading zeros and URL-encode the header, both are accepted.
+func (JaegerPropagator) Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
+	ctx = extractPrefixedBaggage(ctx, carrier, jaegerBaggagePrefix)
+
+	header, err := url.QueryUnescape(carrier.Get(jaegerHeader))
+	if err != nil || header == "" {
+		return ctx
+	}
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(header)), ":")
+	if len(parts) != 4 || !validID(parts[0], 32) || !validID(parts[1], 16) {
+		return ctx
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return ctx
+	}
+
+	traceID := parts[0]
+	if len(traceID) <= 16 {
+		traceID = paddedID(traceID, 8)
+	} else {
+		traceID = paddedID(traceID, 16)
+	}
+	sc := &spanContextImpl{
+		traceID: traceID,
+		spanID:  paddedID(parts[1], 8),
+		sampled: flags&jaegerFlagSampled != 0,
+		debug:   flags&jaegerFlagDebug != 0,
+	}
+	if sc.sampled {
+		sc.traceFlags = traceFlagSampled
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the Jaeger header name.
+func (JaegerPropagator) Fields() []string {
+	return []string{jaegerHeader}
+}
+
+// OTTracePropagator propagates the OpenTracing "ot-tracer-*" headers used by
+// basictracer based clients.
+type OTTracePropagator struct{}
+
+// Inject writes ot-tracer-* and ot-baggage-* headers.
+func (OTTracePropagator) Inject(ctx context.Context, carrier TextMapCarrier) {
+	if sc := SpanContextFromContext(ctx); sc != nil && validID(sc.TraceID(), 32) && validID(sc.SpanID(), 16) {
+		carrier.Set(otTraceIDHeader, b3TraceID(sc.TraceID()))
+		carrier.Set(otSpanIDHeader, paddedID(sc.SpanID(), 8))
+		carrier.Set(otSampledHeader, strconv.FormatBool(sc.IsSampled()))
+	}
+	injectPrefixedBaggage(ctx, carrier, otBaggageHeaderPref)
+}
+
+// Extract parses ot-tracer-* and ot-baggage-* headers.
+func (OTTracePropagator) Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
+	ctx = extractPrefixedBaggage(ctx, carrier, otBaggageHeaderPref)
+
+	traceID := strings.ToLower(strings.TrimSpace(carrier.Get(otTraceIDHeader)))
+	spanID := strings.ToLower(strings.TrimSpace(carrier.Get(otSpanIDHeader)))
+	if !validB3TraceID(traceID) || len(spanID) != 16 || !validID(spanID, 16) {
+		return ctx
+	}
+
+	sampled, _ := strconv.ParseBool(carrier.Get(otSampledHeader))
+	sc := &spanContextImpl{traceID: traceID, spanID: spanID, sampled: sampled}
+	if sampled {
+		sc.traceFlags = traceFlagSampled
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the OpenTracing header names.
+func (OTTracePropagator) Fields() []string {
+	return []string{otTraceIDHeader, otSpanIDHeader, otSampledHeader}
+}
@@ -0,0 +1,205 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newPropagationTracer(t *testing.T, formats ...string) Tracer {
+	t.Helper()
+	config := DefaultConfig()
+	config.ServiceName = "gateway"
+	config.SamplingEnabled = false
+	config.ExportEnabled = false
+	config.PropagationFormats = formats
+
+	tracer, err := NewTracer(&config)
+	if err != nil {
+		t.Fatalf("NewTracer() error = %v", err)
+	}
+	t.Cleanup(func() { tracer.Close() })
+	return tracer
+}
+
+func TestPropagatorRoundTrip(t *testing.T) {
+	remote := &spanContextImpl{
+		traceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		spanID:     "00f067aa0ba902b7",
+		sampled:    true,
+		traceFlags: traceFlagSampled,
+	}
+
+	tests := []struct {
+		name       string
+		propagator Propagator
+		header     string
+	}{
+		{"tracecontext", TraceContextPropagator{}, "traceparent"},
+		{"b3 single", B3Propagator{SingleHeader: true}, "b3"},
+		{"b3 multi", B3Propagator{}, "X-B3-TraceId"},
+		{"jaeger", JaegerPropagator{}, "uber-trace-id"},
+		{"ottrace", OTTracePropagator{}, "ot-tracer-traceid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ContextWithRemoteSpanContext(context.Background(), remote)
+			ctx = ContextWithBaggageItem(ctx, "tenant", "acme corp")
+
+			header := http.Header{}
+			tt.propagator.Inject(ctx, HeaderCarrier(header))
+			if header.Get(tt.header) == "" {
+				t.Fatalf("expected %s header, got %v", tt.header, header)
+			}
+
+			sc := SpanContextFromContext(tt.propagator.Extract(context.Background(), HeaderCarrier(header)))
+			if sc == nil {
+				t.Fatalf("expected span context from %v", header)
+			}
+			if sc.TraceID() != remote.traceID || sc.SpanID() != remote.spanID || !sc.IsSampled() {
+				t.Errorf("got %s/%s sampled=%v", sc.TraceID(), sc.SpanID(), sc.IsSampled())
+			}
+		})
+	}
+}
+
+func TestCompositePropagatorConvertsB3ToW3C(t *testing.T) {
+	tracer := newPropagationTracer(t, "tracecontext", "baggage", "b3multi")
+
+	incoming := http.Header{}
+	incoming.Set("X-B3-TraceId", "463ac35c9f6413ad")
+	incoming.Set("X-B3-SpanId", "a2fb4a1d1a96d312")
+	incoming.Set("X-B3-Sampled", "1")
+
+	ctx, err := tracer.Extract(context.Background(), FormatHTTPHeaders, incoming)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	span, ctx := tracer.StartSpan(ctx, "proxy")
+	defer span.Finish()
+
+	if span.TraceID() != "463ac35c9f6413ad" {
+		t.Errorf("expected trace to continue, got %s", span.TraceID())
+	}
+
+	outgoing := http.Header{}
+	if err := tracer.Inject(ctx, FormatHTTPHeaders, outgoing); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	want := "00-0000000000000000463ac35c9f6413ad-" + span.SpanID() + "-01"
+	if got := outgoing.Get("traceparent"); got != want {
+		t.Errorf("traceparent = %q, want %q", got, want)
+	}
+	if got := outgoing.Get("X-B3-TraceId"); got != "463ac35c9f6413ad" {
+		t.Errorf("X-B3-TraceId = %q", got)
+	}
+}
+
+func TestUnsampledParentIsPropagated(t *testing.T) {
+	tracer := newPropagationTracer(t)
+
+	incoming := http.Header{}
+	incoming.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+	ctx, _ := tracer.Extract(context.Background(), FormatHTTPHeaders, incoming)
+	span, ctx := tracer.StartSpan(ctx, "handler")
+	defer span.Finish()
+
+	outgoing := http.Header{}
+	if err := tracer.Inject(ctx, FormatHTTPHeaders, outgoing); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + span.SpanID() + "-00"
+	if got := outgoing.Get("traceparent"); got != want {
+		t.Errorf("traceparent = %q, want %q", got, want)
+	}
+}
+
+func TestTraceStateOrdering(t *testing.T) {
+	state, err := parseTraceState("rojo=00f067aa0ba902b7, congo=t61rcWkgMzE")
+	if err != nil {
+		t.Fatalf("parseTraceState() error = %v", err)
+	}
+	state.Set("congo", "updated")
+	if got := state.String(); got != "congo=updated,rojo=00f067aa0ba902b7" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if _, err := parseTraceState("rojo=1,rojo=2"); err == nil {
+		t.Error("expected error for duplicate keys")
+	}
+	if _, err := parseTraceState("rojo"); err == nil {
+		t.Error("expected error for member without value")
+	}
+}
+
+func TestBaggageEscaping(t *testing.T) {
+	ctx := ContextWithBaggage(context.Background(), Baggage{"user": "a,b;c d%"})
+
+	carrier := MapCarrier{}
+	BaggagePropagator{}.Inject(ctx, carrier)
+	if got := carrier.Get("baggage"); got != "user=a%2Cb%3Bc%20d%25" {
+		t.Errorf("baggage = %q", got)
+	}
+
+	baggage := BaggageFromContext(BaggagePropagator{}.Extract(context.Background(), carrier))
+	if baggage["user"] != "a,b;c d%" {
+		t.Errorf("extracted baggage = %v", baggage)
+	}
+}
+
+func TestJaegerExtractURLEncodedHeader(t *testing.T) {
+	carrier := MapCarrier{
+		"uber-trace-id":  "463ac35c9f6413ad%3Aa2fb4a1d1a96d312%3A0%3A3",
+		"uberctx-Tenant": "acme",
+	}
+	ctx := JaegerPropagator{}.Extract(context.Background(), carrier)
+
+	sc := SpanContextFromContext(ctx)
+	if sc == nil {
+		t.Fatal("expected span context")
+	}
+	if sc.TraceID() != "463ac35c9f6413ad" || sc.SpanID() != "a2fb4a1d1a96d312" || !sc.IsSampled() || !isDebug(sc) {
+		t.Errorf("got %s/%s sampled=%v debug=%v", sc.TraceID(), sc.SpanID(), sc.IsSampled(), isDebug(sc))
+	}
+	if got := BaggageFromContext(ctx)["tenant"]; got != "acme" {
+		t.Errorf("baggage tenant = %q", got)
+	}
+}
+
+func TestTransportInjectsContext(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	tracer := newPropagationTracer(t, "tracecontext", "b3")
+	parent, ctx := tracer.StartSpan(context.Background(), "caller")
+	defer parent.Finish()
+
+	client := &http.Client{Transport: NewTransport(tracer, nil)}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/configs?env=prod", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	sc, ok := parseTraceparent(received.Get("traceparent"))
+	if !ok {
+		t.Fatalf("invalid traceparent %q", received.Get("traceparent"))
+	}
+	if sc.traceID != paddedID(parent.TraceID(), 16) || sc.spanID == parent.SpanID() {
+		t.Errorf("expected a child span of %s, got %s/%s", parent.SpanID(), sc.traceID, sc.spanID)
+	}
+	if received.Get("b3") == "" {
+		t.Error("expected b3 header")
+	}
+	if req.Header.Get("traceparent") != "" {
+		t.Error("caller request must not be modified")
+	}
+}
@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	traceparentHeader = "traceparent"
+	tracestateHeader  = "tracestate"
+
+	// traceFlagSampled is the W3C sampled trace flag.
+	traceFlagSampled byte = 0x01
+)
+
+// TraceContextPropagator propagates the W3C traceparent and tracestate headers.
+type TraceContextPropagator struct{}
+
+// Inject writes traceparent and tracestate.
+func (TraceContextPropagator) Inject(ctx context.Context, carrier TextMapCarrier) {
+	sc := SpanContextFromContext(ctx)
+	if sc == nil || !validID(sc.TraceID(), 32) || !validID(sc.SpanID(), 16) {
+		return
+	}
+
+	flags := sc.TraceFlags() &^ traceFlagSampled
+	if sc.IsSampled() {
+		flags |= traceFlagSampled
+	}
+	carrier.Set(traceparentHeader, fmt.Sprintf("00-%s-%s-%02x",
+		paddedID(sc.TraceID(), 16), paddedID(sc.SpanID(), 8), flags))
+
+	if state := sc.TraceState(); state != nil && state.Len() > 0 {
+		carrier.Set(tracestateHeader, state.String())
+	}
+}
+
+// Extract parses traceparent and tracestate.
+func (TraceContextPropagator) Extract(ctx context.Context, carrier TextMapCarrier) context.Context {
+	sc, ok := parseTraceparent(strings.TrimSpace(carrier.Get(traceparentHeader)))
+	if !ok {
+		return ctx
+	}
+	// an invalid tracestate is dropped without invalidating traceparent
+	if state, err := parseTraceState(carrier.Get(tracestateHeader)); err == nil {
+		sc.traceState = state
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Fields returns the W3C trace context header names.
+func (TraceContextPropagator) Fields() []string {
+	return []string{traceparentHeader, tracestateHeader}
+}
+
+// parseTraceparent parses version-format-version 00 traceparent headers and
+// the version 00 prefix of future versions.
+func parseTraceparent(header string) (*spanContextImpl, bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || !isLowerHex(parts[0]) || parts[0] == "ff" {
+		return nil, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return nil, false
+	}
+
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if len(traceID) != 32 || !isLowerHex(traceID) || allZero(traceID) {
+		return nil, false
+	}
+	if len(spanID) != 16 || !isLowerHex(spanID) || allZero(spanID) {
+		return nil, false
+	}
+	if len(flags) != 2 || !isLowerHex(flags) {
+		return nil, false
+	}
+
+	value, _ := strconv.ParseUint(flags, 16, 8)
+	return &spanContextImpl{
+		traceID:    traceID,
+		spanID:     spanID,
+		sampled:    byte(value)&traceFlagSampled != 0,
+		traceFlags: byte(value),
+	}, true
+}
+
+// validID reports whether id is a non-zero hex ID of at most maxLen digits.
+func validID(id string, maxLen int) bool {
+	if id == "" || len(id) > maxLen || allZero(id) {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func allZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	redisv8 "github.com/go-redis/redis/v8"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook is a go-redis v9 hook that records a client span per command
+// and per pipeline.
+//
+//	client.AddHook(tracing.NewRedisHook(tracer))
+type RedisHook struct {
+	tracer Tracer
+}
+
+// NewRedisHook creates a go-redis v9 hook. A nil tracer uses the global tracer.
+func NewRedisHook(tracer Tracer) *RedisHook {
+	return &RedisHook{tracer: tracer}
+}
+
+// DialHook passes dials through untraced.
+func (h *RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook traces single commands.
+func (h *RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		span, ctx := startRedisSpan(ctx, h.tracer, cmd.Name(), 1)
+		err := next(ctx, cmd)
+		finishRedisSpan(span, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook traces pipelines as one span.
+func (h *RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		span, ctx := startRedisSpan(ctx, h.tracer, pipelineOperation(cmds, redis.Cmder.Name), len(cmds))
+		err := next(ctx, cmds)
+		if err == nil {
+			err = firstCmdError(cmds, redis.Cmder.Err)
+		}
+		finishRedisSpan(span, err)
+		return err
+	}
+}
+
+// RedisV8Hook is the go-redis v8 equivalent of RedisHook.
+type RedisV8Hook struct {
+	tracer Tracer
+}
+
+type redisSpanKey struct{}
+
+// NewRedisV8Hook creates a go-redis v8 hook. A nil tracer uses the global tracer.
+func NewRedisV8Hook(tracer Tracer) *RedisV8Hook {
+	return &RedisV8Hook{tracer: tracer}
+}
+
+// BeforeProcess starts the command span.
+func (h *RedisV8Hook) BeforeProcess(ctx context.Context, cmd redisv8.Cmder) (context.Context, error) {
+	span, ctx := startRedisSpan(ctx, h.tracer, cmd.Name(), 1)
+	return context.WithValue(ctx, redisSpanKey{}, span), nil
+}
+
+// AfterProcess finishes the command span.
+func (h *RedisV8Hook) AfterProcess(ctx context.Context, cmd redisv8.Cmder) error {
+	span, _ := ctx.Value(redisSpanKey{}).(Span)
+	finishRedisSpan(span, cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline starts the pipeline span.
+func (h *RedisV8Hook) BeforeProcessPipeline(ctx context.Context, cmds []redisv8.Cmder) (context.Context, error) {
+	span, ctx := startRedisSpan(ctx, h.tracer, pipelineOperation(cmds, redisv8.Cmder.Name), len(cmds))
+	return context.WithValue(ctx, redisSpanKey{}, span), nil
+}
+
+// AfterProcessPipeline finishes the pipeline span.
+func (h *RedisV8Hook) AfterProcessPipeline(ctx context.Context, cmds []redisv8.Cmder) error {
+	span, _ := ctx.Value(redisSpanKey{}).(Span)
+	finishRedisSpan(span, firstCmdError(cmds, redisv8.Cmder.Err))
+	return nil
+}
+
+func startRedisSpan(ctx context.Context, tracer Tracer, operation string, count int) (Span, context.Context) {
+	if tracer == nil {
+		tracer = GlobalTracer()
+	}
+	if tracer == nil {
+		return nil, ctx
+	}
+	opts := []SpanOption{
+		WithSpanKind(SpanKindClient),
+		WithTag("db.system", "redis"),
+		WithTag("db.operation", operation),
+	}
+	if count > 1 {
+		opts = append(opts, WithTag("db.redis.pipeline_length", count))
+	}
+	return tracer.StartSpan(ctx, "redis "+operation, opts...)
+}
+
+// finishRedisSpan ends span, treating a missing key as success.
+func finishRedisSpan(span Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil && !errors.Is(err, redis.Nil) && !errors.Is(err, redisv8.Nil) {
+		span.RecordError(err)
+		span.SetStatus(SpanStatusError, err.Error())
+	}
+	span.Finish()
+}
+
+// pipelineOperation names a pipeline after its distinct commands.
+func pipelineOperation[C any](cmds []C, name func(C) string) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, cmd := range cmds {
+		n := name(cmd)
+		if !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	return "pipeline " + strings.Join(names, " ")
+}
+
+func firstCmdError[C any](cmds []C, errOf func(C) error) error {
+	for _, cmd := range cmds {
+		if err := errOf(cmd); err != nil && !errors.Is(err, redis.Nil) && !errors.Is(err, redisv8.Nil) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Tags recorded on root spans describing the sampling decision.
+const (
+	samplerTypeTag  = "sampler.type"
+	samplerParamTag = "sampler.param"
+	samplerRuleTag  = "sampler.rule"
+)
+
+// Sampling types accepted in Config.SamplingType.
+const (
+	SamplingTypeProbabilistic = "probabilistic"
+	SamplingTypeRateLimiting  = "rate_limiting"
+	SamplingTypeAdaptive      = "adaptive"
+)
+
+func sampled(attributes map[string]interface{}) SamplingResult {
+	return SamplingResult{Decision: SamplingDecisionRecordAndSample, Attributes: attributes}
+}
+
+func dropped() SamplingResult {
+	return SamplingResult{Decision: SamplingDecisionDrop}
+}
+
+// AlwaysOnSampler samples every trace.
+type AlwaysOnSampler struct{}
+
+// ShouldSample always samples.
+func (AlwaysOnSampler) ShouldSample(ctx context.Context, traceID string, spanName string, parentContext SpanContext) SamplingResult {
+	return sampled(nil)
+}
+
+// AlwaysOffSampler drops every trace.
+type AlwaysOffSampler struct{}
+
+// ShouldSample always drops.
+func (AlwaysOffSampler) ShouldSample(ctx context.Context, traceID string, spanName string, parentContext SpanContext) SamplingResult {
+	return dropped()
+}
+
+// ProbabilisticSampler samples a fixed ratio of traces. The decision is a
+// function of the trace ID so every service sampling at the same rate makes
+// the same decision for a trace.
+type ProbabilisticSampler struct {
+	rate       float64
+	upperBound uint64
+}
+
+// NewProbabilisticSampler creates a ratio sampler, rate is clamped to [0, 1].
+func NewProbabilisticSampler(rate float64) *ProbabilisticSampler {
+	rate = math.Max(0, math.Min(1, rate))
+	return &ProbabilisticSampler{
+		rate:       rate,
+		upperBound: uint64(rate * (1 << 63)),
+	}
+}
+
+// ShouldSample compares the low 63 bits of the trace ID with the rate.
+func (s *ProbabilisticSampler) ShouldSample(ctx context.Context, traceID string, spanName string, parentContext SpanContext) SamplingResult {
+	if s.rate >= 1 || traceIDValue(traceID)>>1 < s.upperBound {
+		return sampled(map[string]interface{}{
+			samplerTypeTag:  SamplingTypeProbabilistic,
+			samplerParamTag: s.rate,
+		})
+	}
+	return dropped()
+}
+
+// traceIDValue returns the low 64 bits of a hex trace ID, hashing IDs that
+// are not hex.
+func traceIDValue(traceID string) uint64 {
+	low := traceID
+	if len(low) > 16 {
+		low = low[len(low)-16:]
+	}
+	if v, err := strconv.ParseUint(low, 16, 64); err == nil {
+		return v
+	}
+	h := fnv.New64a()
+	h.Write([]byte(traceID))
+	return h.Sum64()
+}
+
+// RateLimitingSampler samples at most a fixed number of traces per second.
+type RateLimitingSampler struct {
+	maxPerSecond float64
+	limiter      *rate.Limiter
+	now          func() time.Time
+}
+
+// NewRateLimitingSampler creates a sampler admitting maxPerSecond traces per
+// second with a burst of one second's worth of traces.
+func NewRateLimitingSampler(maxPerSecond float64) *RateLimitingSampler {
+	burst := int(math.Ceil(maxPerSecond))
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitingSampler{
+		maxPerSecond: maxPerSecond,
+		limiter:      rate.NewLimiter(rate.Limit(maxPerSecond), burst),
+		now:          time.Now,
+	}
+}
+
+// ShouldSample samples while tokens are available.
+func (s *RateLimitingSampler) ShouldSample(ctx context.Context, traceID string, spanName string, parentContext SpanContext) SamplingResult {
+	if s.maxPerSecond > 0 && s.limiter.AllowN(s.now(), 1) {
+		return sampled(map[string]interface{}{
+			samplerTypeTag:  SamplingTypeRateLimiting,
+			samplerParamTag: s.maxPerSecond,
+		})
+	}
+	return dropped()
+}
+
+// AdaptiveSampler samples a ratio of traces capped at a number of traces
+// per second, so traffic spikes do not overload the collector.
+type AdaptiveSampler struct {
+	ratio   *ProbabilisticSampler
+	limiter *RateLimitingSampler
+}
+
+// NewAdaptiveSampler creates a ratio sampler with a per second ceiling.
+func NewAdaptiveSampler(rate, maxPerSecond float64) *AdaptiveSampler {
+	return &AdaptiveSampler{
+		ratio:   NewProbabilisticSampler(rate),
+		limiter: NewRateLimitingSampler(maxPerSecond),
+	}
+}
+
+// ShouldSample applies the ratio first and the rate limit second.
+func (s *AdaptiveSampler) ShouldSample(ctx context.Context, traceID string, spanName string, parentContext SpanContext) SamplingResult {
+	result := s.ratio.ShouldSample(ctx, traceID, spanName, parentContext)
+	if result.Decision != SamplingDecisionRecordAndSample {
+		return result
+	}
+	if s.limiter.ShouldSample(ctx, traceID, spanName, parentContext).Decision != SamplingDecisionRecordAndSample {
+		return dropped()
+	}
+	result.Attributes[samplerTypeTag] = SamplingTypeAdaptive
+	return result
+}
+
+// ParentBasedSampler follows the decision of the parent span and only asks
+// the root sampler for new traces, so a trace is never partially sampled.
+type ParentBasedSampler struct {
+	root Sampler
+}
+
+// NewParentBasedSampler wraps root with parent based sampling.
+func NewParentBasedSampler(root Sampler) *ParentBasedSampler {
+	return &ParentBasedSampler{root: root}
+}
+
+// ShouldSample honours the parent decision when there is a parent.
+func (s *ParentBasedSampler) ShouldSample(ctx context.Context, traceID string, spanName string, parentContext SpanContext) SamplingResult {
+	if parentContext != nil {
+		if parentContext.IsSampled() {
+			return sampled(nil)
+		}
+		return dropped()
+	}
+	return s.root.ShouldSample(ctx, traceID, spanName, parentContext)
+}
+
+// SamplingRule overrides the sampling of operations matching a pattern.
+// Server spans are named "METHOD /route", so routes are matched with
+// patterns like "GET /api/v1/users/*" or "* /health".
+type SamplingRule struct {
+	// Name identifies the rule in the sampler.rule tag.
+	Name string `json:"name" yaml:"name"`
+	// Operation is the span name pattern, "*" matches any characters.
+	Operation string `json:"operation" yaml:"operation"`
+	// Rate is the ratio of matching traces to sample.
+	Rate float64 `json:"rate" yaml:"rate"`
+	// MaxPerSecond caps matching traces per second when positive.
+	MaxPerSecond float64 `json:"max_per_second" yaml:"max_per_second"`
+}
+
+// Validate checks the rule.
+func (r SamplingRule) Validate() error {
+	if r.Operation == "" {
+		return fmt.Errorf("sampling rule %q: operation is required", r.Name)
+	}
+	if r.Rate < 0 || r.Rate > 1 {
+		return fmt.Errorf("sampling rule %q: rate must be between 0 and 1", r.Name)
+	}
+	if r.MaxPerSecond < 0 {
+		return fmt.Errorf("sampling rule %q: max_per_second must be non-negative", r.Name)
+	}
+	return nil
+}
+
+// sampler builds the sampler implementing the rule.
+func (r SamplingRule) sampler() Sampler {
+	if r.MaxPerSecond > 0 {
+		if r.Rate >= 1 {
+			return NewRateLimitingSampler(r.MaxPerSecond)
+		}
+		return NewAdaptiveSampler(r.Rate, r.MaxPerSecond)
+	}
+	return NewProbabilisticSampler(r.Rate)
+}
+
+type compiledRule struct {
+	rule    SamplingRule
+	sampler Sampler
+}
+
+// RuleBasedSampler samples with the first rule whose pattern matches the
+// span name and falls back to a default sampler.
+type RuleBasedSampler struct {
+	rules    []compiledRule
+	fallback Sampler
+}
+
+// NewRuleBasedSampler creates a rule based sampler. Rules are evaluated in
+// order; fallback handles spans matching no rule.
+func NewRuleBasedSampler(rules []SamplingRule, fallback Sampler) (*RuleBasedSampler, error) {
+	if fallback == nil {
+		fallback = AlwaysOnSampler{}
+	}
+	s := &RuleBasedSampler{fallback: fallback}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		s.rules = append(s.rules, compiledRule{rule: rule, sampler: rule.sampler()})
+	}
+	return s, nil
+}
+
+// ShouldSample evaluates the rules in order.
+func (s *RuleBasedSampler) ShouldSample(ctx context.Context, traceID string, spanName string, parentContext SpanContext) SamplingResult {
+	for _, r := range s.rules {
+		if !matchOperation(r.rule.Operation, spanName) {
+			continue
+		}
+		result := r.sampler.ShouldSample(ctx, traceID, spanName, parentContext)
+		if result.Decision == SamplingDecisionRecordAndSample && r.rule.Name != "" {
+			if result.Attributes == nil {
+				result.Attributes = make(map[string]interface{})
+			}
+			result.Attributes[samplerRuleTag] = r.rule.Name
+		}
+		return result
+	}
+	return s.fallback.ShouldSample(ctx, traceID, spanName, parentContext)
+}
+
+// matchOperation matches name against a pattern where "*" matches any
+// sequence of characters, including "/".
+func matchOperation(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(name, part)
+		if idx < 0 {
+			return false
+		}
+		name = name[idx+len(part):]
+	}
+	return strings.HasSuffix(name, parts[len(parts)-1])
+}
+
+// NewSampler builds the head sampler described by config: the configured
+// sampling type, overridden by SamplingRules, wrapped in parent based
+// sampling. It returns nil when sampling is disabled.
+func NewSampler(config *Config) (Sampler, error) {
+	if !config.SamplingEnabled {
+		return nil, nil
+	}
+
+	var root Sampler
+	switch config.SamplingType {
+	case "", SamplingTypeProbabilistic:
+		root = NewProbabilisticSampler(config.SamplingRate)
+	case SamplingTypeRateLimiting:
+		root = NewRateLimitingSampler(float64(config.MaxTracesPerSecond))
+	case SamplingTypeAdaptive:
+		root = NewAdaptiveSampler(config.SamplingRate, float64(config.MaxTracesPerSecond))
+	default:
+		return nil, fmt.Errorf("invalid sampling_type: %s", config.SamplingType)
+	}
+
+	if len(config.SamplingRules) > 0 {
+		rules, err := NewRuleBasedSampler(config.SamplingRules, root)
+		if err != nil {
+			return nil, err
+		}
+		root = rules
+	}
+	return NewParentBasedSampler(root), nil
+}
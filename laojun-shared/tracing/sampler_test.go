@@ -0,0 +1,254 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProbabilisticSamplerRatio(t *testing.T) {
+	sampler := NewProbabilisticSampler(0.25)
+
+	kept := 0
+	for i := 0; i < 10000; i++ {
+		traceID := generateID()
+		first := sampler.ShouldSample(context.Background(), traceID, "op", nil).Decision
+		second := sampler.ShouldSample(context.Background(), traceID, "op", nil).Decision
+		if first != second {
+			t.Fatalf("decision for %s is not deterministic", traceID)
+		}
+		if first == SamplingDecisionRecordAndSample {
+			kept++
+		}
+	}
+	if kept < 2200 || kept > 2800 {
+		t.Errorf("expected about 2500 sampled traces, got %d", kept)
+	}
+
+	if NewProbabilisticSampler(0).ShouldSample(context.Background(), "ffffffffffffffff", "op", nil).Decision != SamplingDecisionDrop {
+		t.Error("rate 0 must drop")
+	}
+	if NewProbabilisticSampler(1).ShouldSample(context.Background(), "ffffffffffffffff", "op", nil).Decision != SamplingDecisionRecordAndSample {
+		t.Error("rate 1 must sample")
+	}
+}
+
+func TestParentBasedSampler(t *testing.T) {
+	sampler := NewParentBasedSampler(AlwaysOffSampler{})
+	ctx := context.Background()
+
+	if got := sampler.ShouldSample(ctx, "a", "op", nil).Decision; got != SamplingDecisionDrop {
+		t.Errorf("root span: got %v, want drop", got)
+	}
+	parent := &spanContextImpl{traceID: "a", spanID: "b", sampled: true}
+	if got := sampler.ShouldSample(ctx, "a", "op", parent).Decision; got != SamplingDecisionRecordAndSample {
+		t.Errorf("sampled parent: got %v, want sample", got)
+	}
+
+	sampler = NewParentBasedSampler(AlwaysOnSampler{})
+	parent.sampled = false
+	if got := sampler.ShouldSample(ctx, "a", "op", parent).Decision; got != SamplingDecisionDrop {
+		t.Errorf("unsampled parent: got %v, want drop", got)
+	}
+}
+
+func TestRuleBasedSampler(t *testing.T) {
+	sampler, err := NewRuleBasedSampler([]SamplingRule{
+		{Name: "health", Operation: "* /health", Rate: 0},
+		{Name: "checkout", Operation: "POST /api/v1/orders/*", Rate: 1},
+	}, AlwaysOffSampler{})
+	if err != nil {
+		t.Fatalf("NewRuleBasedSampler() error = %v", err)
+	}
+
+	tests := []struct {
+		operation string
+		want      SamplingDecision
+	}{
+		{"GET /health", SamplingDecisionDrop},
+		{"POST /api/v1/orders/:id/pay", SamplingDecisionRecordAndSample},
+		{"GET /api/v1/orders/:id", SamplingDecisionDrop},
+	}
+	for _, tt := range tests {
+		result := sampler.ShouldSample(context.Background(), generateID(), tt.operation, nil)
+		if result.Decision != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.operation, result.Decision, tt.want)
+		}
+	}
+
+	result := sampler.ShouldSample(context.Background(), generateID(), "POST /api/v1/orders/1", nil)
+	if result.Attributes[samplerRuleTag] != "checkout" {
+		t.Errorf("expected sampler.rule tag, got %v", result.Attributes)
+	}
+
+	if _, err := NewRuleBasedSampler([]SamplingRule{{Operation: "*", Rate: 2}}, nil); err == nil {
+		t.Error("expected error for invalid rate")
+	}
+}
+
+func TestRateLimitingSampler(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	sampler := NewRateLimitingSampler(2)
+	sampler.now = func() time.Time { return now }
+
+	decisions := func(n int) int {
+		kept := 0
+		for i := 0; i < n; i++ {
+			if sampler.ShouldSample(context.Background(), generateID(), "op", nil).Decision == SamplingDecisionRecordAndSample {
+				kept++
+			}
+		}
+		return kept
+	}
+
+	if kept := decisions(10); kept != 2 {
+		t.Errorf("expected burst of 2, got %d", kept)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if kept := decisions(10); kept != 1 {
+		t.Errorf("expected 1 trace after 500ms, got %d", kept)
+	}
+}
+
+func TestNewSamplerFollowsRemoteParent(t *testing.T) {
+	config := DefaultConfig()
+	config.ExportEnabled = false
+	config.SamplingRate = 0
+	tracer, err := NewTracer(&config)
+	if err != nil {
+		t.Fatalf("NewTracer() error = %v", err)
+	}
+	defer tracer.Close()
+
+	root, _ := tracer.StartSpan(context.Background(), "root")
+	if root.Context().IsSampled() {
+		t.Error("rate 0 root span must not be sampled")
+	}
+
+	remote := &spanContextImpl{traceID: "4bf92f3577b34da6a3ce929d0e0e4736", spanID: "00f067aa0ba902b7", sampled: true}
+	child, _ := tracer.StartSpan(ContextWithRemoteSpanContext(context.Background(), remote), "child")
+	if !child.Context().IsSampled() {
+		t.Error("child of a sampled remote parent must be sampled")
+	}
+}
+
+// recordingProcessor collects spans forwarded by the tail sampler.
+type recordingProcessor struct {
+	mu    sync.Mutex
+	spans []*spanImpl
+}
+
+func (r *recordingProcessor) OnEnd(span *spanImpl) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+func (r *recordingProcessor) ForceFlush(ctx context.Context) error { return nil }
+
+func (r *recordingProcessor) Shutdown(ctx context.Context) error { return nil }
+
+func (r *recordingProcessor) traceIDs() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make(map[string]int)
+	for _, span := range r.spans {
+		ids[span.traceID]++
+	}
+	return ids
+}
+
+func finishedSpan(traceID string, start time.Time, duration time.Duration, tags map[string]interface{}) *spanImpl {
+	end := start.Add(duration)
+	if tags == nil {
+		tags = make(map[string]interface{})
+	}
+	return &spanImpl{
+		traceID:   traceID,
+		spanID:    generateID(),
+		startTime: start,
+		endTime:   &end,
+		tags:      tags,
+	}
+}
+
+func TestTailSamplingPolicies(t *testing.T) {
+	next := &recordingProcessor{}
+	p := newTailSamplingProcessor(next, TailSamplingOptions{
+		DecisionWait:     time.Second,
+		KeepErrors:       true,
+		LatencyThreshold: 500 * time.Millisecond,
+		Attributes:       map[string]string{"tenant": "vip"},
+	})
+	now := time.Unix(1700000000, 0)
+	p.now = func() time.Time { return now }
+
+	errored := finishedSpan("error", now, time.Millisecond, nil)
+	errored.status = SpanStatusError
+	p.OnEnd(finishedSpan("error", now, time.Millisecond, nil))
+	p.OnEnd(errored)
+	p.OnEnd(finishedSpan("slow", now, 600*time.Millisecond, nil))
+	p.OnEnd(finishedSpan("vip", now, time.Millisecond, map[string]interface{}{"tenant": "vip"}))
+	p.OnEnd(finishedSpan("boring", now, time.Millisecond, map[string]interface{}{"tenant": "free"}))
+
+	p.decideExpired(now.Add(500 * time.Millisecond))
+	if n := len(next.traceIDs()); n != 0 {
+		t.Fatalf("traces decided before the window passed: %d", n)
+	}
+
+	p.decideExpired(now.Add(time.Second))
+	got := next.traceIDs()
+	want := map[string]int{"error": 2, "slow": 1, "vip": 1}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("kept traces = %v, want %v", got, want)
+	}
+
+	// late spans follow the decision of their trace
+	p.OnEnd(finishedSpan("error", now, time.Millisecond, nil))
+	p.OnEnd(finishedSpan("boring", now, time.Millisecond, nil))
+
+	stats := p.Stats()
+	if stats.TracesKept != 3 || stats.TracesDropped != 1 || stats.SpansKept != 5 || stats.SpansDropped != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if stats.KeptByReason[TailKeepError] != 1 || stats.KeptByReason[TailKeepLatency] != 1 || stats.KeptByReason[TailKeepAttribute] != 1 {
+		t.Errorf("unexpected reasons %v", stats.KeptByReason)
+	}
+}
+
+func TestTailSamplingMemoryBounds(t *testing.T) {
+	next := &recordingProcessor{}
+	p := newTailSamplingProcessor(next, TailSamplingOptions{
+		DecisionWait:     time.Hour,
+		MaxTraces:        2,
+		MaxSpansPerTrace: 2,
+		KeepErrors:       true,
+	})
+	now := time.Unix(1700000000, 0)
+
+	for i := 0; i < 3; i++ {
+		span := finishedSpan("first", now, time.Millisecond, nil)
+		span.status = SpanStatusError
+		p.OnEnd(span)
+	}
+	p.OnEnd(finishedSpan("second", now, time.Millisecond, nil))
+	p.OnEnd(finishedSpan("third", now, time.Millisecond, nil))
+
+	stats := p.Stats()
+	if stats.TracesEvicted != 1 || stats.BufferedTraces != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if got := next.traceIDs()["first"]; got != 2 {
+		t.Errorf("expected the evicted trace with 2 spans to be kept, got %d", got)
+	}
+
+	go p.run()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if stats := p.Stats(); stats.BufferedTraces != 0 || stats.TracesDropped != 2 {
+		t.Errorf("shutdown must decide all buffered traces, got %+v", stats)
+	}
+}
@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// spanData is an immutable snapshot of a finished span taken for export.
+// Exporters encode snapshots so that late SetTag calls cannot race with
+// an in-flight export.
+type spanData struct {
+	traceID       string
+	spanID        string
+	parentSpanID  string
+	operationName string
+	kind          string
+	startTime     time.Time
+	endTime       time.Time
+	tags          map[string]interface{}
+	logs          []LogEntry
+	events        []Event
+	status        SpanStatus
+	statusDesc    string
+}
+
+// snapshot copies the exportable state of the span.
+func (s *spanImpl) snapshot() *spanData {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data := &spanData{
+		traceID:       s.traceID,
+		spanID:        s.spanID,
+		parentSpanID:  s.parentSpanID,
+		operationName: s.operationName,
+		startTime:     s.startTime,
+		endTime:       s.startTime,
+		tags:          make(map[string]interface{}, len(s.tags)),
+		logs:          append([]LogEntry(nil), s.logs...),
+		events:        append([]Event(nil), s.events...),
+		status:        s.status,
+		statusDesc:    s.statusDesc,
+	}
+	if s.endTime != nil {
+		data.endTime = *s.endTime
+	}
+	for k, v := range s.tags {
+		if k == spanKindTag {
+			data.kind = fmt.Sprint(v)
+			continue
+		}
+		data.tags[k] = v
+	}
+	return data
+}
+
+// snapshotSpans snapshots a batch of spans.
+func snapshotSpans(spans []*spanImpl) []*spanData {
+	data := make([]*spanData, len(spans))
+	for i, span := range spans {
+		data[i] = span.snapshot()
+	}
+	return data
+}
+
+// spanKindTag is the OpenTracing tag carrying the span kind.
+const spanKindTag = "span.kind"
+
+// Resource describes the entity producing spans.
+type Resource struct {
+	ServiceName string
+	Attributes  map[string]string
+}
+
+// resourceFromConfig builds the export resource from the tracing config.
+func resourceFromConfig(config *Config) Resource {
+	attrs := make(map[string]string, len(config.ResourceAttributes)+3)
+	for k, v := range config.ResourceAttributes {
+		attrs[k] = v
+	}
+	if config.ServiceName != "" {
+		attrs["service.name"] = config.ServiceName
+	}
+	if config.ServiceVersion != "" {
+		attrs["service.version"] = config.ServiceVersion
+	}
+	if config.Environment != "" {
+		attrs["deployment.environment"] = config.Environment
+	}
+	return Resource{ServiceName: attrs["service.name"], Attributes: attrs}
+}
+
+// sortedKeys returns map keys in a stable order so payloads are deterministic.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodeID decodes a hex ID into exactly size bytes, left-padding shorter
+// IDs with zeros (64-bit trace IDs become valid 128-bit IDs).
+func decodeID(id string, size int) []byte {
+	out := make([]byte, size)
+	if id == "" {
+		return out
+	}
+	if len(id)%2 == 1 {
+		id = "0" + id
+	}
+	raw, err := hex.DecodeString(id)
+	if err != nil {
+		return out
+	}
+	if len(raw) > size {
+		raw = raw[len(raw)-size:]
+	}
+	copy(out[size-len(raw):], raw)
+	return out
+}
+
+// paddedID returns the lowercase hex form of decodeID.
+func paddedID(id string, size int) string {
+	return hex.EncodeToString(decodeID(id, size))
+}
+
+// logMessage flattens log fields into a single "k=v" annotation string.
+func logMessage(fields []LogField) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = fmt.Sprintf("%s=%v", field.Key, field.Value)
+	}
+	return strings.Join(parts, " ")
+}
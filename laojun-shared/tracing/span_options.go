@@ -0,0 +1,45 @@
+package tracing
+
+import "time"
+
+// Span kinds recorded in the "span.kind" tag.
+const (
+	SpanKindServer   = "server"
+	SpanKindClient   = "client"
+	SpanKindProducer = "producer"
+	SpanKindConsumer = "consumer"
+	SpanKindInternal = "internal"
+)
+
+// spanOptionFunc adapts a function to SpanOption.
+type spanOptionFunc func(*SpanConfig)
+
+func (f spanOptionFunc) Apply(config *SpanConfig) {
+	f(config)
+}
+
+// WithTag sets a tag on the new span.
+func WithTag(key string, value interface{}) SpanOption {
+	return spanOptionFunc(func(config *SpanConfig) {
+		config.Tags[key] = value
+	})
+}
+
+// WithSpanKind sets the span kind, e.g. SpanKindServer.
+func WithSpanKind(kind string) SpanOption {
+	return WithTag(spanKindTag, kind)
+}
+
+// WithStartTime overrides the span start time.
+func WithStartTime(t time.Time) SpanOption {
+	return spanOptionFunc(func(config *SpanConfig) {
+		config.StartTime = t
+	})
+}
+
+// WithParent makes the span a child of parent regardless of the context.
+func WithParent(parent Span) SpanOption {
+	return spanOptionFunc(func(config *SpanConfig) {
+		config.Parent = parent
+	})
+}
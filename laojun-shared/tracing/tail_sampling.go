@@ -0,0 +1,342 @@
+package tracing
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Reasons a trace is kept by the tail sampler, used as TailSamplingStats keys.
+const (
+	TailKeepError     = "error"
+	TailKeepLatency   = "latency"
+	TailKeepAttribute = "attribute"
+	TailKeepBaseline  = "baseline"
+)
+
+// TailSamplingOptions configures a TailSamplingProcessor.
+type TailSamplingOptions struct {
+	// DecisionWait is how long spans of a trace are buffered after its
+	// first span finished before the trace is evaluated.
+	DecisionWait time.Duration
+	// MaxTraces bounds the number of buffered traces. When full the oldest
+	// trace is evaluated early.
+	MaxTraces int
+	// MaxSpansPerTrace bounds the spans buffered per trace, later spans
+	// are dropped.
+	MaxSpansPerTrace int
+	// KeepErrors keeps traces containing a span with error status.
+	KeepErrors bool
+	// LatencyThreshold keeps traces spanning at least this duration when positive.
+	LatencyThreshold time.Duration
+	// Attributes keeps traces with a span tag matching key=value, an empty
+	// value matches any value of the key.
+	Attributes map[string]string
+	// BaselineRate is the ratio of remaining traces to keep.
+	BaselineRate float64
+}
+
+// TailSamplingStats reports trace accounting of a TailSamplingProcessor.
+type TailSamplingStats struct {
+	// BufferedTraces is the number of traces waiting for a decision.
+	BufferedTraces int
+	TracesKept     uint64
+	TracesDropped  uint64
+	SpansKept      uint64
+	// SpansDropped includes spans of dropped traces and spans over the
+	// per trace limit.
+	SpansDropped uint64
+	// TracesEvicted counts traces evaluated before DecisionWait because
+	// MaxTraces was reached.
+	TracesEvicted uint64
+	// KeptByReason counts kept traces by the first matching TailKeep* reason.
+	KeptByReason map[string]uint64
+}
+
+// bufferedTrace accumulates the spans and policy inputs of one trace.
+type bufferedTrace struct {
+	traceID   string
+	deadline  time.Time
+	spans     []*spanImpl
+	hasError  bool
+	attrMatch bool
+	start     time.Time
+	end       time.Time
+}
+
+// TailSamplingProcessor buffers finished spans per trace for a decision
+// window and forwards only traces with errors, high latency, matching
+// attributes, or picked by the baseline rate. Decisions are local to the
+// process, so every service should sample heads at 100% and apply the same
+// tail policy for complete traces.
+type TailSamplingProcessor struct {
+	next     spanProcessor
+	options  TailSamplingOptions
+	baseline *ProbabilisticSampler
+	now      func() time.Time
+
+	mu      sync.Mutex
+	traces  map[string]*list.Element
+	pending *list.List
+	// decided remembers recent decisions so late spans follow their trace
+	decided      map[string]bool
+	decidedOrder *list.List
+	stats        TailSamplingStats
+
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTailSamplingProcessor creates and starts a tail sampling processor
+// forwarding kept traces to next.
+func NewTailSamplingProcessor(next *BatchSpanProcessor, opts TailSamplingOptions) *TailSamplingProcessor {
+	p := newTailSamplingProcessor(next, opts)
+	go p.run()
+	return p
+}
+
+func newTailSamplingProcessor(next spanProcessor, opts TailSamplingOptions) *TailSamplingProcessor {
+	if opts.DecisionWait <= 0 {
+		opts.DecisionWait = 10 * time.Second
+	}
+	if opts.MaxTraces <= 0 {
+		opts.MaxTraces = 10000
+	}
+	if opts.MaxSpansPerTrace <= 0 {
+		opts.MaxSpansPerTrace = 1000
+	}
+
+	return &TailSamplingProcessor{
+		next:         next,
+		options:      opts,
+		baseline:     NewProbabilisticSampler(opts.BaselineRate),
+		now:          time.Now,
+		traces:       make(map[string]*list.Element),
+		pending:      list.New(),
+		decided:      make(map[string]bool),
+		decidedOrder: list.New(),
+		stats:        TailSamplingStats{KeptByReason: make(map[string]uint64)},
+		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
+	}
+}
+
+// OnEnd buffers a finished span until its trace is decided.
+func (p *TailSamplingProcessor) OnEnd(span *spanImpl) {
+	p.mu.Lock()
+
+	select {
+	case <-p.stopCh:
+		p.stats.SpansDropped++
+		p.mu.Unlock()
+		return
+	default:
+	}
+
+	if keep, ok := p.decided[span.traceID]; ok {
+		// late span of an already decided trace
+		if keep {
+			p.stats.SpansKept++
+		} else {
+			p.stats.SpansDropped++
+		}
+		p.mu.Unlock()
+		if keep {
+			p.next.OnEnd(span)
+		}
+		return
+	}
+
+	var forward []*spanImpl
+	elem, ok := p.traces[span.traceID]
+	if !ok {
+		if p.pending.Len() >= p.options.MaxTraces {
+			p.stats.TracesEvicted++
+			forward = p.decideLocked(p.pending.Front())
+		}
+		elem = p.pending.PushBack(&bufferedTrace{
+			traceID:  span.traceID,
+			deadline: p.now().Add(p.options.DecisionWait),
+		})
+		p.traces[span.traceID] = elem
+	}
+
+	trace := elem.Value.(*bufferedTrace)
+	if len(trace.spans) >= p.options.MaxSpansPerTrace {
+		p.stats.SpansDropped++
+	} else {
+		trace.spans = append(trace.spans, span)
+		p.observe(trace, span)
+	}
+	p.mu.Unlock()
+
+	p.forward(forward)
+}
+
+// observe updates the policy inputs of trace with span.
+func (p *TailSamplingProcessor) observe(trace *bufferedTrace, span *spanImpl) {
+	span.mu.RLock()
+	defer span.mu.RUnlock()
+
+	if span.status == SpanStatusError || span.tags["error"] == true {
+		trace.hasError = true
+	}
+	if trace.start.IsZero() || span.startTime.Before(trace.start) {
+		trace.start = span.startTime
+	}
+	if span.endTime != nil && span.endTime.After(trace.end) {
+		trace.end = *span.endTime
+	}
+	if !trace.attrMatch {
+		for key, want := range p.options.Attributes {
+			value, ok := span.tags[key]
+			if ok && (want == "" || fmt.Sprint(value) == want) {
+				trace.attrMatch = true
+				break
+			}
+		}
+	}
+}
+
+// keepReason evaluates the policies in order, returning "" to drop.
+func (p *TailSamplingProcessor) keepReason(trace *bufferedTrace) string {
+	switch {
+	case p.options.KeepErrors && trace.hasError:
+		return TailKeepError
+	case p.options.LatencyThreshold > 0 && trace.end.Sub(trace.start) >= p.options.LatencyThreshold:
+		return TailKeepLatency
+	case trace.attrMatch:
+		return TailKeepAttribute
+	case p.options.BaselineRate > 0 &&
+		p.baseline.ShouldSample(context.Background(), trace.traceID, "", nil).Decision == SamplingDecisionRecordAndSample:
+		return TailKeepBaseline
+	}
+	return ""
+}
+
+// decideLocked removes the trace in elem from the buffer and returns its
+// spans if kept. The caller must hold p.mu.
+func (p *TailSamplingProcessor) decideLocked(elem *list.Element) []*spanImpl {
+	trace := p.pending.Remove(elem).(*bufferedTrace)
+	delete(p.traces, trace.traceID)
+
+	reason := p.keepReason(trace)
+	keep := reason != ""
+	p.remember(trace.traceID, keep)
+
+	count := uint64(len(trace.spans))
+	if !keep {
+		p.stats.TracesDropped++
+		p.stats.SpansDropped += count
+		return nil
+	}
+	p.stats.TracesKept++
+	p.stats.SpansKept += count
+	p.stats.KeptByReason[reason]++
+	return trace.spans
+}
+
+// remember records a decision, bounding the cache to MaxTraces entries.
+func (p *TailSamplingProcessor) remember(traceID string, keep bool) {
+	p.decided[traceID] = keep
+	p.decidedOrder.PushBack(traceID)
+	for p.decidedOrder.Len() > p.options.MaxTraces {
+		oldest := p.decidedOrder.Remove(p.decidedOrder.Front()).(string)
+		delete(p.decided, oldest)
+	}
+}
+
+// decideExpired evaluates all traces whose decision window has passed.
+func (p *TailSamplingProcessor) decideExpired(now time.Time) {
+	var forward []*spanImpl
+
+	p.mu.Lock()
+	for elem := p.pending.Front(); elem != nil; elem = p.pending.Front() {
+		if elem.Value.(*bufferedTrace).deadline.After(now) {
+			break
+		}
+		forward = append(forward, p.decideLocked(elem)...)
+	}
+	p.mu.Unlock()
+
+	p.forward(forward)
+}
+
+// decideAll evaluates every buffered trace regardless of its window.
+func (p *TailSamplingProcessor) decideAll() {
+	var forward []*spanImpl
+
+	p.mu.Lock()
+	for elem := p.pending.Front(); elem != nil; elem = p.pending.Front() {
+		forward = append(forward, p.decideLocked(elem)...)
+	}
+	p.mu.Unlock()
+
+	p.forward(forward)
+}
+
+func (p *TailSamplingProcessor) forward(spans []*spanImpl) {
+	for _, span := range spans {
+		p.next.OnEnd(span)
+	}
+}
+
+// ForceFlush flushes the traces already kept. Buffered traces still wait
+// for their decision window so that flushing does not split traces.
+func (p *TailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// Shutdown decides all buffered traces and shuts down the next processor.
+func (p *TailSamplingProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+
+	select {
+	case <-p.doneCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	p.decideAll()
+	return p.next.Shutdown(ctx)
+}
+
+// Stats returns a snapshot of the trace accounting.
+func (p *TailSamplingProcessor) Stats() TailSamplingStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.BufferedTraces = p.pending.Len()
+	stats.KeptByReason = make(map[string]uint64, len(p.stats.KeptByReason))
+	for k, v := range p.stats.KeptByReason {
+		stats.KeptByReason[k] = v
+	}
+	return stats
+}
+
+func (p *TailSamplingProcessor) run() {
+	defer close(p.doneCh)
+
+	interval := p.options.DecisionWait / 10
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	} else if interval > time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.decideExpired(p.now())
+		case <-p.stopCh:
+			return
+		}
+	}
+}